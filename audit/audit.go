@@ -0,0 +1,303 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Audited actions
+const (
+	ActionBotLaunch    = "bot.launch"
+	ActionBotTeardown  = "bot.teardown"
+	ActionBotStop      = "bot.stop"
+	ActionAdminRequest = "admin.request"
+	ActionConfigReload = "config.reload"
+)
+
+const (
+	defaultMaxSizeMB = 10
+	maxLineSize      = 1024 * 1024
+)
+
+// ErrTampered is returned when the entries do not follow the hash chain.
+var ErrTampered = errors.New("audit log is tampered")
+
+// Entry is a line of the audit log. The hash of an entry covers the entry with an empty hash,
+// including the hash of the previous entry, so that changing or removing an entry breaks the chain.
+type Entry struct {
+	Seq      uint64            `json:"seq"`
+	Time     time.Time         `json:"time"`
+	Action   string            `json:"action"`
+	BotID    string            `json:"botId,omitempty"`
+	Details  map[string]string `json:"details,omitempty"`
+	PrevHash string            `json:"prevHash"`
+	Hash     string            `json:"hash"`
+}
+
+// ComputeHash computes the hash of the entry.
+func (entry Entry) ComputeHash() string {
+	entry.Hash = ""
+	b, _ := json.Marshal(entry)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Log is an append-only audit log of JSON lines. The file is moved to a backup when it reaches
+// the max size and the backups are named as <path>.1 (newest) to <path>.N (oldest). The hash chain
+// continues from the backups to the current file.
+type Log struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file     *os.File
+	size     int64
+	lastSeq  uint64
+	lastHash string
+	mu       sync.Mutex
+}
+
+// Open opens the audit log and continues the hash chain from the last entry. The backups are
+// never removed if the max backups is zero.
+func Open(filePath string, maxSizeMB, maxBackups int) (*Log, error) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultMaxSizeMB
+	}
+	if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the audit log dir: %v", err)
+	}
+	l := &Log{
+		path:       filePath,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	// the current file is empty right after the rotation
+	for _, filePath := range []string{l.path, backupPath(l.path, 1)} {
+		last, err := readLastEntry(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			l.lastSeq = last.Seq
+			l.lastHash = last.Hash
+			break
+		}
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open the audit log: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat the audit log: %v", err)
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// Record appends an entry to the audit log. It is a no-op if the log is nil so that the audit
+// log can be disabled.
+func (l *Log) Record(action, botID string, details map[string]string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := Entry{
+		Seq:      l.lastSeq + 1,
+		Time:     time.Now().UTC(),
+		Action:   action,
+		BotID:    botID,
+		Details:  details,
+		PrevHash: l.lastHash,
+	}
+	entry.Hash = entry.ComputeHash()
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode the audit log entry: %v", err)
+	}
+	b = append(b, '\n')
+
+	if l.size > 0 && l.size+int64(len(b)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(b)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write the audit log entry: %v", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync the audit log: %v", err)
+	}
+	l.lastSeq = entry.Seq
+	l.lastHash = entry.Hash
+	return nil
+}
+
+// Close closes the audit log file.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close the audit log: %v", err)
+	}
+	backups := countBackups(l.path)
+	// the oldest backup is overwritten only if the backups are limited
+	if l.maxBackups > 0 && backups >= l.maxBackups {
+		backups = l.maxBackups - 1
+	}
+	for i := backups; i > 0; i-- {
+		err := os.Rename(backupPath(l.path, i), backupPath(l.path, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to move the audit log backup: %v", err)
+		}
+	}
+	if err := os.Rename(l.path, backupPath(l.path, 1)); err != nil {
+		return fmt.Errorf("failed to move the audit log: %v", err)
+	}
+	return l.open()
+}
+
+func backupPath(filePath string, i int) string {
+	return fmt.Sprintf("%s.%d", filePath, i)
+}
+
+// countBackups returns the highest backup number so that a removed backup in the middle
+// is noticed as a gap in the chain.
+func countBackups(filePath string) int {
+	matches, _ := filepath.Glob(filePath + ".*")
+	var n int
+	for _, match := range matches {
+		i, err := strconv.Atoi(strings.TrimPrefix(match, filePath+"."))
+		if err == nil && i > n {
+			n = i
+		}
+	}
+	return n
+}
+
+// files returns the backups from the oldest to the newest and then the current file.
+func files(filePath string) []string {
+	var paths []string
+	for i := countBackups(filePath); i > 0; i-- {
+		paths = append(paths, backupPath(filePath, i))
+	}
+	return append(paths, filePath)
+}
+
+func readLastEntry(filePath string) (*Entry, error) {
+	var last *Entry
+	err := readEntries(filePath, func(entry *Entry) error {
+		last = entry
+		return nil
+	})
+	return last, err
+}
+
+func readEntries(filePath string, handler func(entry *Entry) error) error {
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open the audit log: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("%w: invalid entry in %s: %v", ErrTampered, filePath, err)
+		}
+		if err := handler(&entry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read the audit log: %v", err)
+	}
+	return nil
+}
+
+// Export verifies the hash chain over the backups and the current file and writes the entries
+// to the writer in order. The first entry can follow an entry from a removed backup.
+func Export(filePath string, w io.Writer) (count int, err error) {
+	enc := json.NewEncoder(w)
+	var prev *Entry
+	for _, filePath := range files(filePath) {
+		err := readEntries(filePath, func(entry *Entry) error {
+			if err := verify(prev, entry); err != nil {
+				return err
+			}
+			prev = entry
+			count++
+			if w == nil {
+				return nil
+			}
+			if err := enc.Encode(entry); err != nil {
+				return fmt.Errorf("failed to write the audit log entry: %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// Verify verifies the hash chain and returns the number of entries.
+func Verify(filePath string) (int, error) {
+	return Export(filePath, nil)
+}
+
+func verify(prev, entry *Entry) error {
+	if entry.Hash != entry.ComputeHash() {
+		return fmt.Errorf("%w: hash mismatch at entry %d", ErrTampered, entry.Seq)
+	}
+	if prev == nil {
+		return nil
+	}
+	if entry.Seq != prev.Seq+1 || entry.PrevHash != prev.Hash {
+		return fmt.Errorf("%w: entry %d does not follow entry %d", ErrTampered, entry.Seq, prev.Seq)
+	}
+	return nil
+}
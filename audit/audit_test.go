@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	r := require.New(t)
+
+	logPath := path.Join(t.TempDir(), "audit.log")
+	l, err := Open(logPath, 1, 0)
+	r.NoError(err)
+	// rotate after every few entries
+	l.maxSize = 1024
+
+	details := map[string]string{"image": "disco.forta.network/bafybei@sha256:abc"}
+	for i := 0; i < 20; i++ {
+		r.NoError(l.Record(ActionBotLaunch, "0xbot", details))
+	}
+	r.NoError(l.Close())
+	r.Greater(countBackups(logPath), 1)
+
+	// the chain continues after opening again
+	l, err = Open(logPath, 1, 0)
+	r.NoError(err)
+	r.EqualValues(20, l.lastSeq)
+	r.NoError(l.Record(ActionConfigReload, "", nil))
+	r.NoError(l.Close())
+
+	var buf bytes.Buffer
+	count, err := Export(logPath, &buf)
+	r.NoError(err)
+	r.Equal(21, count)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	r.Len(lines, 21)
+	var last Entry
+	r.NoError(json.Unmarshal([]byte(lines[20]), &last))
+	r.EqualValues(21, last.Seq)
+	r.Equal(ActionConfigReload, last.Action)
+
+	// changing an entry breaks the chain
+	b, err := ioutil.ReadFile(logPath)
+	r.NoError(err)
+	r.NoError(ioutil.WriteFile(logPath, bytes.Replace(b, []byte("config.reload"), []byte("bot.stop"), 1), 0600))
+	_, err = Verify(logPath)
+	r.True(errors.Is(err, ErrTampered))
+
+	// removing an entry breaks the chain
+	r.NoError(ioutil.WriteFile(logPath, b, 0600))
+	r.NoError(os.Remove(backupPath(logPath, 2)))
+	_, err = Verify(logPath)
+	r.True(errors.Is(err, ErrTampered))
+}
+
+func TestLog_MaxBackups(t *testing.T) {
+	r := require.New(t)
+
+	logPath := path.Join(t.TempDir(), "audit.log")
+	l, err := Open(logPath, 1, 2)
+	r.NoError(err)
+	l.maxSize = 256
+
+	for i := 0; i < 20; i++ {
+		r.NoError(l.Record(ActionBotTeardown, "0xbot", nil))
+	}
+	r.NoError(l.Close())
+	r.Equal(2, countBackups(logPath))
+
+	// the oldest entries are gone but the rest is still verifiable
+	count, err := Verify(logPath)
+	r.NoError(err)
+	r.Less(count, 20)
+}
+
+func TestLog_Nil(t *testing.T) {
+	var l *Log
+	require.NoError(t, l.Record(ActionBotStop, "0xbot", nil))
+	require.NoError(t, l.Close())
+}
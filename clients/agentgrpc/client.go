@@ -2,18 +2,35 @@ package agentgrpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/faults"
 	"github.com/forta-network/forta-node/config"
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip compressor
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
-const defaultAgentResponseMaxByteCount = 250000 // 250K
+const (
+	defaultAgentResponseMaxByteCount = 250000 // 250K
+	defaultReconnectMaxBackoff       = time.Second * 30
+
+	headerAcceptEncoding = "grpc-accept-encoding"
+)
 
 // Method is gRPC method type.
 type Method string
@@ -29,20 +46,54 @@ const (
 // Client makes the gRPC requests to evaluate block and txs and receive results.
 type Client interface {
 	DialWithRetry(config.AgentConfig) error
+	ConnState() connectivity.State
+	WatchConnState(ctx context.Context, handler func(connectivity.State))
 	Invoke(ctx context.Context, method Method, in, out interface{}, opts ...grpc.CallOption) error
+	CheckHealth(ctx context.Context) error
+	SupportsPayloadRefs() bool
 	protocol.AgentClient
 	io.Closer
 }
 
 // client allows us to communicate with an agent.
 type client struct {
+	cfg  config.AgentGrpcConfig
 	conn *grpc.ClientConn
 	protocol.AgentClient
+
+	compression string
+	payloadRefs bool
+	mu          sync.RWMutex
+
+	streamCtx         context.Context
+	streamCtxCancel   func()
+	streams           map[Method]*Stream
+	streamUnsupported bool
+	streamMu          sync.Mutex
 }
 
 // NewClient creates a new client.
-func NewClient() *client {
-	return &client{}
+func NewClient(cfg config.AgentGrpcConfig) *client {
+	streamCtx, streamCtxCancel := context.WithCancel(context.Background())
+	return &client{
+		cfg:             cfg,
+		streamCtx:       streamCtx,
+		streamCtxCancel: streamCtxCancel,
+		streams:         make(map[Method]*Stream),
+	}
+}
+
+func (client *client) callOptions() []grpc.CallOption {
+	maxRecvMsgSize := client.cfg.MaxRecvMessageSize
+	if maxRecvMsgSize == 0 {
+		maxRecvMsgSize = defaultAgentResponseMaxByteCount
+	}
+	// the requests wait for the connection to recover instead of failing right away
+	opts := []grpc.CallOption{grpc.MaxCallRecvMsgSize(maxRecvMsgSize), grpc.WaitForReady(true)}
+	if client.cfg.MaxSendMessageSize > 0 {
+		opts = append(opts, grpc.MaxCallSendMsgSize(client.cfg.MaxSendMessageSize))
+	}
+	return opts
 }
 
 // DialWithRetry dials an agent using the config.
@@ -51,14 +102,33 @@ func (client *client) DialWithRetry(cfg config.AgentConfig) error {
 		conn *grpc.ClientConn
 		err  error
 	)
+	dialOpts := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithTimeout(10 * time.Second),
+		grpc.WithDefaultCallOptions(client.callOptions()...),
+	}
+	if keepaliveInterval := client.keepaliveInterval(); keepaliveInterval > 0 {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                keepaliveInterval,
+			Timeout:             keepaliveInterval / 3,
+			PermitWithoutStream: !client.cfg.Streaming,
+		}))
+	}
+	// the broken connections are recovered in the background
+	reconnectMaxBackoff := defaultReconnectMaxBackoff
+	if client.cfg.ReconnectMaxBackoffSeconds > 0 {
+		reconnectMaxBackoff = time.Duration(client.cfg.ReconnectMaxBackoffSeconds) * time.Second
+	}
+	backoffCfg := backoff.DefaultConfig
+	backoffCfg.MaxDelay = reconnectMaxBackoff
+	dialOpts = append(dialOpts, grpc.WithConnectParams(grpc.ConnectParams{
+		Backoff:           backoffCfg,
+		MinConnectTimeout: 10 * time.Second,
+	}))
+	dialOpts = append(dialOpts, faults.DialOptions()...)
 	for i := 0; i < 10; i++ {
-		conn, err = grpc.Dial(
-			fmt.Sprintf("%s:%s", cfg.ContainerName(), cfg.GrpcPort()),
-			grpc.WithInsecure(),
-			grpc.WithBlock(),
-			grpc.WithTimeout(10*time.Second),
-			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(defaultAgentResponseMaxByteCount)),
-		)
+		conn, err = grpc.Dial(cfg.GrpcAddress(), dialOpts...)
 		if err == nil {
 			break
 		}
@@ -75,19 +145,174 @@ func (client *client) DialWithRetry(cfg config.AgentConfig) error {
 	return nil
 }
 
+// keepaliveInterval returns the interval of the pings. The streams have their own interval
+// because they are long-lived.
+func (client *client) keepaliveInterval() time.Duration {
+	if client.cfg.Streaming && client.cfg.StreamKeepaliveSeconds > 0 {
+		return time.Duration(client.cfg.StreamKeepaliveSeconds) * time.Second
+	}
+	return time.Duration(client.cfg.KeepaliveSeconds) * time.Second
+}
+
+// ConnState returns the state of the connection to the agent.
+func (client *client) ConnState() connectivity.State {
+	if client.conn == nil {
+		return connectivity.Shutdown
+	}
+	return client.conn.GetState()
+}
+
+// WatchConnState calls the handler with the new states of the connection until the context
+// is done or the connection is closed.
+func (client *client) WatchConnState(ctx context.Context, handler func(connectivity.State)) {
+	if client.conn == nil {
+		return
+	}
+	state := client.conn.GetState()
+	for state != connectivity.Shutdown && client.conn.WaitForStateChange(ctx, state) {
+		state = client.conn.GetState()
+		handler(state)
+	}
+}
+
+// CheckHealth checks the bot by using the standard gRPC health service and returns an error
+// if the bot is not serving.
+func (client *client) CheckHealth(ctx context.Context) error {
+	if client.conn == nil {
+		return errors.New("not connected")
+	}
+	resp, err := grpc_health_v1.NewHealthClient(client.conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("bot is not serving: %s", resp.Status.String())
+	}
+	return nil
+}
+
 // WithConn sets the client conn.
 func (client *client) WithConn(conn *grpc.ClientConn) {
 	client.conn = conn
 	client.AgentClient = protocol.NewAgentClient(conn)
 }
 
-// Invoke is a generalization of client methods.
+// Initialize initializes the agent and negotiates the compression by using the encodings
+// which the agent accepts. The payload references are offered to the agent if they are enabled.
+func (client *client) Initialize(ctx context.Context, in *protocol.InitializeRequest, opts ...grpc.CallOption) (*protocol.InitializeResponse, error) {
+	if client.cfg.PayloadRefs.Enable {
+		ctx = metadata.AppendToOutgoingContext(ctx, headerPayloadRefs, "true")
+	}
+	var header metadata.MD
+	resp, err := client.AgentClient.Initialize(ctx, in, append(opts, grpc.Header(&header))...)
+	if err != nil {
+		return nil, err
+	}
+	client.negotiateCompression(header)
+	client.negotiatePayloadRefs(header)
+	return resp, nil
+}
+
+func (client *client) negotiatePayloadRefs(header metadata.MD) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	values := header.Get(headerPayloadRefs)
+	client.payloadRefs = client.cfg.PayloadRefs.Enable && len(values) > 0 && values[0] == "true"
+}
+
+// SupportsPayloadRefs tells if the agent accepted the payload references.
+func (client *client) SupportsPayloadRefs() bool {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	return client.payloadRefs
+}
+
+func (client *client) negotiateCompression(header metadata.MD) {
+	if len(client.cfg.Compression) == 0 {
+		return
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.compression = ""
+	for _, value := range header.Get(headerAcceptEncoding) {
+		for _, encoding := range strings.Split(value, ",") {
+			if strings.TrimSpace(encoding) == client.cfg.Compression {
+				client.compression = client.cfg.Compression
+				return
+			}
+		}
+	}
+}
+
+// Compression returns the compression negotiated with the agent.
+func (client *client) Compression() string {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	return client.compression
+}
+
+// Invoke is a generalization of client methods. The requests are sent over the streams
+// if streaming is enabled and the agent supports it.
 func (client *client) Invoke(ctx context.Context, method Method, in, out interface{}, opts ...grpc.CallOption) error {
+	if compression := client.Compression(); len(compression) > 0 {
+		opts = append(opts, grpc.UseCompressor(compression))
+	}
+	// the references are sent as call headers which the streamed requests do not have
+	var stream *Stream
+	if !hasPayloadRef(ctx) {
+		var err error
+		stream, err = client.getStream(method, opts...)
+		if err != nil {
+			return err
+		}
+	}
+	if stream != nil {
+		err := stream.Invoke(ctx, in, out)
+		if status.Code(err) != codes.Unimplemented {
+			return err
+		}
+		log.WithField("method", method).Info("agent does not support streaming - falling back to unary calls")
+		client.streamMu.Lock()
+		client.streamUnsupported = true
+		client.streamMu.Unlock()
+	}
 	return client.conn.Invoke(ctx, string(method), in, out, opts...)
 }
 
+// getStream returns the stream of the method and opens a new one if the previous one is broken.
+// It returns nil if the requests should not be streamed.
+func (client *client) getStream(method Method, opts ...grpc.CallOption) (*Stream, error) {
+	streamMethod, ok := streamMethods[method]
+	if !client.cfg.Streaming || !ok {
+		return nil, nil
+	}
+
+	client.streamMu.Lock()
+	defer client.streamMu.Unlock()
+	if client.streamUnsupported {
+		return nil, nil
+	}
+	stream, ok := client.streams[method]
+	if ok && stream.Err() == nil {
+		return stream, nil
+	}
+	stream, err := newStream(client.streamCtx, client.conn, streamMethod, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the stream: %v", err)
+	}
+	client.streams[method] = stream
+	return stream, nil
+}
+
 // Close implements io.Closer.
 func (client *client) Close() error {
+	client.streamMu.Lock()
+	for _, stream := range client.streams {
+		_ = stream.Close()
+	}
+	client.streamMu.Unlock()
+	client.streamCtxCancel()
+
 	if client.conn != nil {
 		return client.conn.Close()
 	}
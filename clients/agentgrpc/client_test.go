@@ -0,0 +1,72 @@
+package agentgrpc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestClient_NegotiateCompression(t *testing.T) {
+	r := require.New(t)
+
+	c := NewClient(config.AgentGrpcConfig{Compression: "gzip"})
+	c.negotiateCompression(metadata.Pairs(headerAcceptEncoding, "identity, deflate, gzip"))
+	r.Equal("gzip", c.Compression())
+
+	// the bot does not accept the compression
+	c.negotiateCompression(metadata.Pairs(headerAcceptEncoding, "identity,deflate"))
+	r.Empty(c.Compression())
+
+	// the bot does not tell the encodings it accepts
+	c.negotiateCompression(metadata.MD{})
+	r.Empty(c.Compression())
+
+	// compression is disabled
+	c = NewClient(config.AgentGrpcConfig{})
+	c.negotiateCompression(metadata.Pairs(headerAcceptEncoding, "identity,gzip,zstd"))
+	r.Empty(c.Compression())
+}
+
+func TestClient_NegotiatePayloadRefs(t *testing.T) {
+	r := require.New(t)
+
+	c := NewClient(config.AgentGrpcConfig{PayloadRefs: config.BotPayloadRefsConfig{Enable: true}})
+	c.negotiatePayloadRefs(metadata.Pairs(headerPayloadRefs, "true"))
+	r.True(c.SupportsPayloadRefs())
+
+	// the bot does not accept the references
+	c.negotiatePayloadRefs(metadata.MD{})
+	r.False(c.SupportsPayloadRefs())
+
+	// the references are disabled
+	c = NewClient(config.AgentGrpcConfig{})
+	c.negotiatePayloadRefs(metadata.Pairs(headerPayloadRefs, "true"))
+	r.False(c.SupportsPayloadRefs())
+}
+
+func TestZstdCompressor(t *testing.T) {
+	r := require.New(t)
+
+	data := bytes.Repeat([]byte("forta"), 1000)
+	compressor := &zstdCompressor{}
+
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		w, err := compressor.Compress(&buf)
+		r.NoError(err)
+		_, err = w.Write(data)
+		r.NoError(err)
+		r.NoError(w.Close())
+		r.Less(buf.Len(), len(data))
+
+		dec, err := compressor.Decompress(&buf)
+		r.NoError(err)
+		b, err := ioutil.ReadAll(dec)
+		r.NoError(err)
+		r.Equal(data, b)
+	}
+}
@@ -0,0 +1,124 @@
+package agentgrpc
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecProto is the name of the default gRPC codec which this package replaces.
+const CodecProto = "proto"
+
+// maxPooledBufferSize is the size limit for the marshaling buffers which are put back
+// to the pool so that a rare huge payload does not stay in the memory.
+const maxPooledBufferSize = 4 << 20 // 4MB
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 64<<10)
+		return &b
+	},
+}
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// codec is the default protobuf codec which sends the cached bytes of the encoded messages
+// instead of marshaling them again for every bot.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	switch vv := v.(type) {
+	case *EncodedMessage:
+		return vv.Bytes()
+	case proto.Message:
+		return proto.Marshal(vv)
+	default:
+		return nil, fmt.Errorf("failed to marshal, message is %T, want proto.Message", v)
+	}
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	vv, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal, message is %T, want proto.Message", v)
+	}
+	return proto.Unmarshal(data, vv)
+}
+
+func (codec) Name() string {
+	return CodecProto
+}
+
+// EncodedMessage is a request which is marshaled once and sent to many bots. The message
+// is marshaled on the first send to a pooled buffer.
+//
+// The buffer goes back to the pool when all references are released. The references of
+// the failed sends should not be released, because the gRPC transport may still be writing
+// the bytes, and the buffer is left to the garbage collector in that case.
+type EncodedMessage struct {
+	msg  proto.Message
+	refs int32
+
+	once sync.Once
+	buf  *[]byte
+	b    []byte
+	err  error
+}
+
+// NewEncodedMessage creates a new encoded message with a single reference which belongs
+// to the caller.
+func NewEncodedMessage(msg proto.Message) *EncodedMessage {
+	return &EncodedMessage{msg: msg, refs: 1}
+}
+
+// Message returns the original message.
+func (em *EncodedMessage) Message() proto.Message {
+	return em.msg
+}
+
+// Bytes returns the marshaled message.
+func (em *EncodedMessage) Bytes() ([]byte, error) {
+	em.once.Do(func() {
+		buf := bufferPool.Get().(*[]byte)
+		pb := proto.NewBuffer((*buf)[:0])
+		if err := pb.Marshal(em.msg); err != nil {
+			bufferPool.Put(buf)
+			em.err = err
+			return
+		}
+		*buf = pb.Bytes()
+		em.buf = buf
+		em.b = *buf
+	})
+	return em.b, em.err
+}
+
+// Retain adds a reference.
+func (em *EncodedMessage) Retain() {
+	if em == nil {
+		return
+	}
+	atomic.AddInt32(&em.refs, 1)
+}
+
+// Release removes a reference and puts the buffer back to the pool after the last one.
+func (em *EncodedMessage) Release() {
+	if em == nil {
+		return
+	}
+	if atomic.AddInt32(&em.refs, -1) != 0 {
+		return
+	}
+	// all senders are done at this point and none of them can be marshaling
+	buf := em.buf
+	em.buf = nil
+	em.b = nil
+	if buf != nil && cap(*buf) <= maxPooledBufferSize {
+		bufferPool.Put(buf)
+	}
+}
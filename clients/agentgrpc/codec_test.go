@@ -0,0 +1,58 @@
+package agentgrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodedMessage(t *testing.T) {
+	r := require.New(t)
+
+	req := &protocol.EvaluateTxRequest{RequestId: "1"}
+	expected, err := proto.Marshal(req)
+	r.NoError(err)
+
+	em := NewEncodedMessage(req)
+	b1, err := em.Bytes()
+	r.NoError(err)
+	r.Equal(expected, b1)
+
+	// the message is marshaled only once
+	b2, err := codec{}.Marshal(em)
+	r.NoError(err)
+	r.Equal(&b1[0], &b2[0])
+
+	// the buffer is kept until the last reference is released
+	em.Retain()
+	em.Release()
+	r.NotNil(em.buf)
+	em.Release()
+	r.Nil(em.buf)
+
+	var nilMessage *EncodedMessage
+	nilMessage.Retain()
+	nilMessage.Release()
+}
+
+func TestCodec_EncodedMessage(t *testing.T) {
+	r := require.New(t)
+
+	conn := startTestAgent(t, true)
+	em := NewEncodedMessage(&protocol.EvaluateTxRequest{RequestId: "1"})
+	defer em.Release()
+
+	for i := 0; i < 2; i++ {
+		resp := new(protocol.EvaluateTxResponse)
+		r.NoError(conn.Invoke(context.Background(), string(MethodEvaluateTx), em, resp))
+		r.Equal("1", resp.Metadata["requestId"])
+	}
+
+	// the other messages are marshaled as usual
+	resp := new(protocol.EvaluateTxResponse)
+	r.NoError(conn.Invoke(context.Background(), string(MethodEvaluateTx), &protocol.EvaluateTxRequest{RequestId: "2"}, resp))
+	r.Equal("2", resp.Metadata["requestId"])
+}
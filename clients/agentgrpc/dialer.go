@@ -9,15 +9,17 @@ type BotDialer interface {
 	DialBot(ac config.AgentConfig) (Client, error)
 }
 
-type botDialer struct{}
+type botDialer struct {
+	cfg config.AgentGrpcConfig
+}
 
 // NewBotDialer creates a new bot dialer.
-func NewBotDialer() BotDialer {
-	return &botDialer{}
+func NewBotDialer(cfg config.AgentGrpcConfig) BotDialer {
+	return &botDialer{cfg: cfg}
 }
 
 func (bd *botDialer) DialBot(ac config.AgentConfig) (Client, error) {
-	client := NewClient()
+	client := NewClient(bd.cfg)
 	err := client.DialWithRetry(ac)
 	if err != nil {
 		return nil, err
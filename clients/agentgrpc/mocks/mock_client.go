@@ -13,6 +13,7 @@ import (
 	config "github.com/forta-network/forta-node/config"
 	gomock "github.com/golang/mock/gomock"
 	grpc "google.golang.org/grpc"
+	connectivity "google.golang.org/grpc/connectivity"
 )
 
 // MockClient is a mock of Client interface.
@@ -38,6 +39,20 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// CheckHealth mocks base method.
+func (m *MockClient) CheckHealth(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckHealth", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckHealth indicates an expected call of CheckHealth.
+func (mr *MockClientMockRecorder) CheckHealth(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckHealth", reflect.TypeOf((*MockClient)(nil).CheckHealth), ctx)
+}
+
 // Close mocks base method.
 func (m *MockClient) Close() error {
 	m.ctrl.T.Helper()
@@ -52,6 +67,20 @@ func (mr *MockClientMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClient)(nil).Close))
 }
 
+// ConnState mocks base method.
+func (m *MockClient) ConnState() connectivity.State {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnState")
+	ret0, _ := ret[0].(connectivity.State)
+	return ret0
+}
+
+// ConnState indicates an expected call of ConnState.
+func (mr *MockClientMockRecorder) ConnState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnState", reflect.TypeOf((*MockClient)(nil).ConnState))
+}
+
 // DialWithRetry mocks base method.
 func (m *MockClient) DialWithRetry(arg0 config.AgentConfig) error {
 	m.ctrl.T.Helper()
@@ -164,3 +193,29 @@ func (mr *MockClientMockRecorder) Invoke(ctx, method, in, out interface{}, opts
 	varargs := append([]interface{}{ctx, method, in, out}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invoke", reflect.TypeOf((*MockClient)(nil).Invoke), varargs...)
 }
+
+// SupportsPayloadRefs mocks base method.
+func (m *MockClient) SupportsPayloadRefs() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SupportsPayloadRefs")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// SupportsPayloadRefs indicates an expected call of SupportsPayloadRefs.
+func (mr *MockClientMockRecorder) SupportsPayloadRefs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SupportsPayloadRefs", reflect.TypeOf((*MockClient)(nil).SupportsPayloadRefs))
+}
+
+// WatchConnState mocks base method.
+func (m *MockClient) WatchConnState(ctx context.Context, handler func(connectivity.State)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "WatchConnState", ctx, handler)
+}
+
+// WatchConnState indicates an expected call of WatchConnState.
+func (mr *MockClientMockRecorder) WatchConnState(ctx, handler interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchConnState", reflect.TypeOf((*MockClient)(nil).WatchConnState), ctx, handler)
+}
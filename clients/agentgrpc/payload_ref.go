@@ -0,0 +1,37 @@
+package agentgrpc
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/metadata"
+)
+
+// The headers of the payload reference negotiation and the referenced requests. The node offers the
+// references in the initialization request if they are enabled and the bot accepts them by sending
+// the same header back. The bot then receives the large requests with the reference headers and
+// fetches the full request from the URL.
+const (
+	headerPayloadRefs      = "forta-payload-refs"
+	headerPayloadRefURL    = "forta-payload-ref-url"
+	headerPayloadRefSHA256 = "forta-payload-ref-sha256"
+)
+
+// PayloadRef refers to a marshaled request in the node-local payload store.
+type PayloadRef struct {
+	URL    string
+	SHA256 string
+	// Request is the request without the large fields which is sent with the reference.
+	Request proto.Message
+}
+
+// WithPayloadRef adds the reference headers to the call context.
+func WithPayloadRef(ctx context.Context, ref *PayloadRef) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, headerPayloadRefURL, ref.URL, headerPayloadRefSHA256, ref.SHA256)
+}
+
+// hasPayloadRef tells if the call sends a reference.
+func hasPayloadRef(ctx context.Context) bool {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	return ok && len(md.Get(headerPayloadRefURL)) > 0
+}
@@ -0,0 +1,141 @@
+package agentgrpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// Agent gRPC streaming methods
+const (
+	MethodEvaluateTxStream    Method = "/network.forta.Agent/EvaluateTxStream"
+	MethodEvaluateBlockStream Method = "/network.forta.Agent/EvaluateBlockStream"
+	MethodEvaluateAlertStream Method = "/network.forta.Agent/EvaluateAlertStream"
+)
+
+// streamMethods are the streaming counterparts of the unary methods.
+var streamMethods = map[Method]Method{
+	MethodEvaluateTx:    MethodEvaluateTxStream,
+	MethodEvaluateBlock: MethodEvaluateBlockStream,
+	MethodEvaluateAlert: MethodEvaluateAlertStream,
+}
+
+const maxPendingResponses = 256
+
+var errStreamClosed = errors.New("stream is closed")
+
+type pendingResponse struct {
+	out   interface{}
+	errCh chan error
+}
+
+// Stream pipelines the requests of an evaluation method over a bidirectional stream.
+// The bot receives the requests and sends the responses in the same order, so
+// the responses are matched with the requests in the order they were sent.
+type Stream struct {
+	stream  grpc.ClientStream
+	pending chan *pendingResponse
+	sendMu  sync.Mutex
+
+	err   error
+	errMu sync.RWMutex
+}
+
+func newStream(ctx context.Context, conn *grpc.ClientConn, method Method, opts ...grpc.CallOption) (*Stream, error) {
+	desc := &grpc.StreamDesc{
+		StreamName:    string(method),
+		ClientStreams: true,
+		ServerStreams: true,
+	}
+	cs, err := conn.NewStream(ctx, desc, string(method), opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &Stream{
+		stream:  cs,
+		pending: make(chan *pendingResponse, maxPendingResponses),
+	}
+	go s.receive()
+	return s, nil
+}
+
+// Invoke sends the request and waits for the response without blocking the other
+// requests which are sent in the meantime.
+func (s *Stream) Invoke(ctx context.Context, in, out interface{}) error {
+	pr := &pendingResponse{out: out, errCh: make(chan error, 1)}
+
+	s.sendMu.Lock()
+	if err := s.Err(); err != nil {
+		s.sendMu.Unlock()
+		return err
+	}
+	select {
+	case s.pending <- pr:
+	case <-ctx.Done():
+		s.sendMu.Unlock()
+		return ctx.Err()
+	}
+	// a send error is received from the stream by the receiver
+	_ = s.stream.SendMsg(in)
+	s.sendMu.Unlock()
+
+	select {
+	case err := <-pr.errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Err returns the error which broke the stream.
+func (s *Stream) Err() error {
+	s.errMu.RLock()
+	defer s.errMu.RUnlock()
+	return s.err
+}
+
+func (s *Stream) receive() {
+	for pr := range s.pending {
+		err := s.stream.RecvMsg(pr.out)
+		pr.errCh <- err
+		if err != nil {
+			s.fail(err)
+			return
+		}
+	}
+}
+
+// fail stops accepting new requests and fails the ones which are waiting for a response.
+func (s *Stream) fail(err error) {
+	s.errMu.Lock()
+	s.err = err
+	s.errMu.Unlock()
+	for {
+		select {
+		case pr, ok := <-s.pending:
+			if !ok {
+				return
+			}
+			pr.errCh <- err
+		default:
+			return
+		}
+	}
+}
+
+// Close closes the sending side of the stream. The requests which were already sent
+// still receive their responses.
+func (s *Stream) Close() error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+	if s.err != nil {
+		return nil
+	}
+	s.err = errStreamClosed
+	close(s.pending)
+	return s.stream.CloseSend()
+}
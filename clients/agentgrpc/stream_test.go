@@ -0,0 +1,90 @@
+package agentgrpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startTestAgent starts an agent which echoes the request ids in the response metadata.
+func startTestAgent(t *testing.T, supportsStreaming bool) *grpc.ClientConn {
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		isStream := strings.HasSuffix(method, "Stream")
+		if isStream && !supportsStreaming {
+			return status.Error(codes.Unimplemented, "unknown method")
+		}
+		for {
+			req := new(protocol.EvaluateTxRequest)
+			if err := stream.RecvMsg(req); err != nil {
+				return nil
+			}
+			if err := stream.SendMsg(&protocol.EvaluateTxResponse{
+				Metadata: map[string]string{"requestId": req.RequestId, "method": method},
+			}); err != nil {
+				return err
+			}
+			if !isStream {
+				return nil
+			}
+		}
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.UnknownServiceHandler(handler))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	return conn
+}
+
+func TestClient_Stream(t *testing.T) {
+	r := require.New(t)
+
+	c := NewClient(config.AgentGrpcConfig{Streaming: true, StreamPipelineDepth: 4})
+	c.WithConn(startTestAgent(t, true))
+	defer c.Close()
+
+	// the pipelined requests receive their own responses
+	var wg sync.WaitGroup
+	for _, requestID := range []string{"1", "2", "3", "4", "5", "6", "7", "8"} {
+		wg.Add(1)
+		go func(requestID string) {
+			defer wg.Done()
+			resp := new(protocol.EvaluateTxResponse)
+			err := c.Invoke(context.Background(), MethodEvaluateTx, &protocol.EvaluateTxRequest{RequestId: requestID}, resp)
+			r.NoError(err)
+			r.Equal(requestID, resp.Metadata["requestId"])
+			r.Equal(string(MethodEvaluateTxStream), resp.Metadata["method"])
+		}(requestID)
+	}
+	wg.Wait()
+}
+
+func TestClient_StreamFallback(t *testing.T) {
+	r := require.New(t)
+
+	c := NewClient(config.AgentGrpcConfig{Streaming: true, StreamPipelineDepth: 1})
+	c.WithConn(startTestAgent(t, false))
+	defer c.Close()
+
+	// the unary method is used when the agent does not implement streaming
+	for i := 0; i < 2; i++ {
+		resp := new(protocol.EvaluateTxResponse)
+		err := c.Invoke(context.Background(), MethodEvaluateTx, &protocol.EvaluateTxRequest{RequestId: "1"}, resp)
+		r.NoError(err)
+		r.Equal(string(MethodEvaluateTx), resp.Metadata["method"])
+	}
+}
@@ -0,0 +1,61 @@
+package agentgrpc
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+// CompressionZstd is the name of the zstd compressor.
+const CompressionZstd = "zstd"
+
+func init() {
+	encoding.RegisterCompressor(&zstdCompressor{})
+}
+
+// zstdCompressor implements the gRPC compressor interface with zstd. The encoders
+// are reused because they are expensive to create.
+type zstdCompressor struct {
+	encoders sync.Pool
+}
+
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+// Close flushes the compressed data and puts the encoder back to the pool.
+func (zw *zstdWriter) Close() error {
+	err := zw.Encoder.Close()
+	zw.pool.Put(zw.Encoder)
+	return err
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	enc, ok := c.encoders.Get().(*zstd.Encoder)
+	if !ok {
+		var err error
+		enc, err = zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		enc.Reset(w)
+	}
+	return &zstdWriter{Encoder: enc, pool: &c.encoders}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	// a single-threaded decoder does not start any goroutines and does not need to be closed
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return dec, nil
+}
+
+func (c *zstdCompressor) Name() string {
+	return CompressionZstd
+}
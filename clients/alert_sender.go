@@ -2,13 +2,15 @@ package clients
 
 import (
 	"context"
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol"
-	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/store"
+	"github.com/forta-network/forta-node/tracing"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // AgentRoundTrip contains
@@ -20,6 +22,9 @@ type AgentRoundTrip struct {
 	EvalTxResponse    *protocol.EvaluateTxResponse
 	EvalAlertRequest  *protocol.EvaluateAlertRequest
 	EvalAlertResponse *protocol.EvaluateAlertResponse
+	SpanContext       trace.SpanContext
+	// EvaluationID is the deterministic ID of the evaluation which produced the alerts.
+	EvaluationID string
 }
 
 type AlertSender interface {
@@ -39,8 +44,8 @@ type alertSender struct {
 }
 
 type AlertSenderConfig struct {
-	Key *keystore.Key
-	DS  store.DeduplicationStore
+	Signer signer.Signer
+	DS     store.DeduplicationStore
 }
 
 func (a *alertSender) SignAlertAndNotify(rt *AgentRoundTrip, alert *protocol.Alert, chainID, blockNumber string, ts *domain.TrackingTimestamps) error {
@@ -59,17 +64,24 @@ func (a *alertSender) SignAlertAndNotify(rt *AgentRoundTrip, alert *protocol.Ale
 		}
 	}
 	alert.Scanner = &protocol.ScannerInfo{
-		Address: a.cfg.Key.Address.Hex(),
+		Address: a.cfg.Signer.Address().Hex(),
 	}
-	signedAlert, err := security.SignAlert(a.cfg.Key, alert)
+	// the signature covers the attestation
+	attestAlert(alert, rt, a.cfg.Signer.Address().Hex(), chainID, blockNumber)
+	signedAlert, err := signer.SignAlert(a.cfg.Signer, alert)
 	if err != nil {
 		logger.Errorf("could not sign alert (id=%s), skipping", alert.Id)
 		return err
 	}
 	signedAlert.ChainId = chainID
 	signedAlert.BlockNumber = blockNumber
+
+	ctx, span := a.startPublishSpan(rt)
+	span.SetAttributes(attribute.String("alert.id", alert.Id))
+	defer span.End()
+
 	_, err = a.pClient.Notify(
-		a.ctx, &protocol.NotifyRequest{
+		ctx, &protocol.NotifyRequest{
 			SignedAlert:       signedAlert,
 			EvalBlockRequest:  rt.EvalBlockRequest,
 			EvalBlockResponse: rt.EvalBlockResponse,
@@ -81,12 +93,16 @@ func (a *alertSender) SignAlertAndNotify(rt *AgentRoundTrip, alert *protocol.Ale
 			Timestamps:        ts.ToMessage(),
 		},
 	)
+	tracing.SetError(span, err)
 	return err
 }
 
 func (a *alertSender) NotifyWithoutAlert(rt *AgentRoundTrip, ts *domain.TrackingTimestamps) error {
+	ctx, span := a.startPublishSpan(rt)
+	defer span.End()
+
 	_, err := a.pClient.Notify(
-		a.ctx, &protocol.NotifyRequest{
+		ctx, &protocol.NotifyRequest{
 			EvalBlockRequest:  rt.EvalBlockRequest,
 			EvalBlockResponse: rt.EvalBlockResponse,
 			EvalAlertRequest:  rt.EvalAlertRequest,
@@ -97,9 +113,18 @@ func (a *alertSender) NotifyWithoutAlert(rt *AgentRoundTrip, ts *domain.Tracking
 			Timestamps:        ts.ToMessage(),
 		},
 	)
+	tracing.SetError(span, err)
 	return err
 }
 
+// startPublishSpan continues the trace of the bot request which produced the round trip.
+func (a *alertSender) startPublishSpan(rt *AgentRoundTrip) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(
+		trace.ContextWithSpanContext(a.ctx, rt.SpanContext), tracing.SpanPublish,
+		trace.WithAttributes(attribute.String("bot.id", rt.AgentConfig.ID)),
+	)
+}
+
 func NewAlertSender(ctx context.Context, publisher PublishClient, cfg AlertSenderConfig) (AlertSender, error) {
 	return &alertSender{
 		ctx:     ctx,
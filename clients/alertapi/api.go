@@ -2,6 +2,7 @@ package alertapi
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,11 +10,20 @@ import (
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/utils/httpclient"
 	"github.com/goccy/go-json"
+	"github.com/klauspost/compress/zstd"
 	log "github.com/sirupsen/logrus"
 )
 
+// Compression codecs
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
 type client struct {
-	apiUrl string
+	apiUrl      string
+	compression string
 }
 
 func (c *client) post(path string, body interface{}, headers map[string]string, target interface{}) error {
@@ -21,13 +31,20 @@ func (c *client) post(path string, body interface{}, headers map[string]string,
 	if err != nil {
 		return err
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.apiUrl, path), bytes.NewBuffer(jsonVal))
+	reqBody, err := c.compress(jsonVal)
+	if err != nil {
+		return fmt.Errorf("failed to compress the request: %v", err)
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.apiUrl, path), reqBody)
 	if err != nil {
 		return err
 	}
 	for n, v := range headers {
 		req.Header[n] = []string{v}
 	}
+	if c.compressed() {
+		req.Header.Set("Content-Encoding", c.compression)
+	}
 	resp, err := httpclient.Default.Do(req)
 	if err != nil {
 		return err
@@ -63,6 +80,35 @@ func (c *client) PostBatch(batch *domain.AlertBatchRequest, token string) (*doma
 	return &resp, nil
 }
 
-func NewClient(apiUrl string) *client {
-	return &client{apiUrl: apiUrl}
+func (c *client) compressed() bool {
+	return c.compression == CompressionGzip || c.compression == CompressionZstd
+}
+
+func (c *client) compress(b []byte) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch c.compression {
+	case CompressionGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(&buf, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		w = enc
+	default:
+		return bytes.NewBuffer(b), nil
+	}
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// NewClient creates a new alerts API client which encodes the requests with the compression codec.
+func NewClient(apiUrl string, compression string) *client {
+	return &client{apiUrl: apiUrl, compression: compression}
 }
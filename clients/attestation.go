@@ -0,0 +1,127 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/security"
+)
+
+// Attestation metadata keys. The alert signature covers the alert metadata so the consumers
+// can verify which bot image on which node evaluated which block to produce the alert.
+const (
+	AttestationScanner     = "attestation.scanner"
+	AttestationBotID       = "attestation.botId"
+	AttestationImageDigest = "attestation.imageDigest"
+	AttestationShardID     = "attestation.shardId"
+	AttestationShards      = "attestation.shards"
+	AttestationChainID     = "attestation.chainId"
+	AttestationBlockNumber = "attestation.blockNumber"
+	AttestationBlockHash   = "attestation.blockHash"
+	AttestationTxHash      = "attestation.txHash"
+	AttestationAlertHash   = "attestation.alertHash"
+)
+
+// AlertEvaluationID is the alert metadata key of the deterministic evaluation ID. The evaluation ID and
+// the alert ID together are the publish key of the alert which the duplicates of the alert share.
+const AlertEvaluationID = "evaluationId"
+
+// attestAlert adds the attestation of the evaluated input, the bot and the node to the alert
+// metadata before the alert is signed.
+func attestAlert(alert *protocol.Alert, rt *AgentRoundTrip, scanner, chainID, blockNumber string) {
+	if alert.Metadata == nil {
+		alert.Metadata = make(map[string]string)
+	}
+	alert.Metadata[AttestationScanner] = scanner
+	alert.Metadata[AttestationBotID] = rt.AgentConfig.ID
+	alert.Metadata[AttestationImageDigest] = rt.AgentConfig.ImageHash()
+	if rt.AgentConfig.ShardConfig != nil {
+		alert.Metadata[AttestationShardID] = strconv.FormatUint(uint64(rt.AgentConfig.ShardConfig.ShardID), 10)
+		alert.Metadata[AttestationShards] = strconv.FormatUint(uint64(rt.AgentConfig.ShardConfig.Shards), 10)
+	}
+	alert.Metadata[AttestationChainID] = chainID
+	alert.Metadata[AttestationBlockNumber] = blockNumber
+
+	blockHash, txHash, alertHash := evaluatedInput(rt.EvalBlockRequest, rt.EvalTxRequest, rt.EvalAlertRequest)
+	if len(blockHash) > 0 {
+		alert.Metadata[AttestationBlockHash] = blockHash
+	}
+	if len(txHash) > 0 {
+		alert.Metadata[AttestationTxHash] = txHash
+	}
+	if len(alertHash) > 0 {
+		alert.Metadata[AttestationAlertHash] = alertHash
+	}
+	if len(rt.EvaluationID) > 0 {
+		alert.Metadata[AlertEvaluationID] = rt.EvaluationID
+	}
+}
+
+// AlertPublishKey returns the key which is the same for the duplicates of the alert. It is empty
+// if the alert does not have an evaluation ID.
+func AlertPublishKey(alert *protocol.Alert) string {
+	evaluationID := alert.Metadata[AlertEvaluationID]
+	if len(evaluationID) == 0 {
+		return ""
+	}
+	return evaluationID + "/" + alert.Id
+}
+
+// evaluatedInput returns the hashes of the block, the tx and the alert which the bot evaluated.
+func evaluatedInput(
+	blockReq *protocol.EvaluateBlockRequest, txReq *protocol.EvaluateTxRequest, alertReq *protocol.EvaluateAlertRequest,
+) (blockHash, txHash, alertHash string) {
+	switch {
+	case blockReq != nil && blockReq.Event != nil:
+		return blockReq.Event.BlockHash, "", ""
+
+	case txReq != nil && txReq.Event != nil && txReq.Event.Block != nil && txReq.Event.Transaction != nil:
+		return txReq.Event.Block.BlockHash, txReq.Event.Transaction.Hash, ""
+
+	case alertReq != nil && alertReq.Event != nil && alertReq.Event.Alert != nil:
+		alert := alertReq.Event.Alert
+		if alert.Source != nil && alert.Source.Block != nil {
+			blockHash = alert.Source.Block.Hash
+		}
+		return blockHash, "", alert.Hash
+	}
+	return "", "", ""
+}
+
+// VerifyAttestation checks that the signed alert of the notification is attested by the signer
+// and that the attestation matches the evaluated input and the bot.
+func VerifyAttestation(notif *protocol.NotifyRequest) error {
+	sa := notif.SignedAlert
+	if sa == nil || sa.Alert == nil {
+		return errors.New("no signed alert")
+	}
+	if err := security.VerifyAlertSignature(sa); err != nil {
+		return fmt.Errorf("invalid alert signature: %v", err)
+	}
+	metadata := sa.Alert.Metadata
+	if !strings.EqualFold(metadata[AttestationScanner], sa.Signature.Signer) {
+		return fmt.Errorf("attested scanner '%s' is not the signer", metadata[AttestationScanner])
+	}
+	if notif.AgentInfo != nil {
+		if !strings.EqualFold(metadata[AttestationBotID], notif.AgentInfo.Id) {
+			return fmt.Errorf("attested bot '%s' does not match", metadata[AttestationBotID])
+		}
+		if metadata[AttestationImageDigest] != notif.AgentInfo.ImageHash {
+			return fmt.Errorf("attested image digest '%s' does not match", metadata[AttestationImageDigest])
+		}
+	}
+	blockHash, txHash, alertHash := evaluatedInput(notif.EvalBlockRequest, notif.EvalTxRequest, notif.EvalAlertRequest)
+	if metadata[AttestationBlockHash] != blockHash {
+		return fmt.Errorf("attested block hash '%s' does not match", metadata[AttestationBlockHash])
+	}
+	if metadata[AttestationTxHash] != txHash {
+		return fmt.Errorf("attested tx hash '%s' does not match", metadata[AttestationTxHash])
+	}
+	if metadata[AttestationAlertHash] != alertHash {
+		return fmt.Errorf("attested alert hash '%s' does not match", metadata[AttestationAlertHash])
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package clients
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+type testPublishClient struct {
+	notif *protocol.NotifyRequest
+}
+
+func (pc *testPublishClient) Notify(ctx context.Context, req *protocol.NotifyRequest) (*protocol.NotifyResponse, error) {
+	pc.notif = req
+	return &protocol.NotifyResponse{}, nil
+}
+
+func TestAlertSender_Attestation(t *testing.T) {
+	r := require.New(t)
+
+	digest := strings.Repeat("a", 64)
+	key := testKey(t)
+	pc := &testPublishClient{}
+	as, err := NewAlertSender(context.Background(), pc, AlertSenderConfig{Signer: signer.NewKeySigner(key)})
+	r.NoError(err)
+
+	rt := &AgentRoundTrip{
+		AgentConfig: config.AgentConfig{
+			ID:          "0xbot",
+			Image:       "bafybeibot@sha256:" + digest,
+			ShardConfig: &config.ShardConfig{ShardID: 1, Shards: 2},
+		},
+		EvalTxRequest: &protocol.EvaluateTxRequest{
+			Event: &protocol.TransactionEvent{
+				Block:       &protocol.TransactionEvent_EthBlock{BlockHash: "0xblock"},
+				Transaction: &protocol.TransactionEvent_EthTransaction{Hash: "0xtx"},
+			},
+		},
+		EvaluationID: "0xeval",
+	}
+	alert := &protocol.Alert{Id: "0xalert", Finding: &protocol.Finding{}}
+	r.NoError(as.SignAlertAndNotify(rt, alert, "0x1", "0x2", &domain.TrackingTimestamps{}))
+
+	metadata := pc.notif.SignedAlert.Alert.Metadata
+	r.Equal(key.Address.Hex(), metadata[AttestationScanner])
+	r.Equal(digest, metadata[AttestationImageDigest])
+	r.Equal("1", metadata[AttestationShardID])
+	r.Equal("2", metadata[AttestationShards])
+	r.Equal("0xblock", metadata[AttestationBlockHash])
+	r.Equal("0xtx", metadata[AttestationTxHash])
+	r.Equal("0xeval/0xalert", AlertPublishKey(pc.notif.SignedAlert.Alert))
+	r.NoError(VerifyAttestation(pc.notif))
+
+	// the attestation cannot be changed without invalidating the signature
+	metadata[AttestationImageDigest] = "other"
+	r.Error(VerifyAttestation(pc.notif))
+
+	// the attestation should match the evaluated input
+	metadata[AttestationImageDigest] = digest
+	pc.notif.EvalTxRequest.Event.Block.BlockHash = "0xother"
+	r.Error(VerifyAttestation(pc.notif))
+}
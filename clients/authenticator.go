@@ -2,9 +2,13 @@ package clients
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"sync"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/config"
@@ -15,6 +19,10 @@ type ipAuthenticator struct {
 	ctx          context.Context
 	dockerClient DockerClient
 	msgClient    MessageClient
+	authCfg      config.BotAuthConfig
+	nodeAddress  common.Address
+	// audience is the audience of the scoped tokens which the services accept.
+	audience string
 
 	agentConfigs  []config.AgentConfig
 	agentConfigMu sync.RWMutex
@@ -40,6 +48,45 @@ func (p *ipAuthenticator) FindAgentFromRemoteAddr(hostPort string) (*config.Agen
 	return p.FindAgentByContainerName(containerName)
 }
 
+// FindAgentFromRequest finds the bot from the token in the request and falls back to
+// the remote address if allowed. The token header is removed so that it is not proxied.
+func (p *ipAuthenticator) FindAgentFromRequest(req *http.Request) (*config.AgentConfig, error) {
+	token := req.Header.Get(BotTokenHeader)
+	req.Header.Del(BotTokenHeader)
+
+	if !p.authCfg.Enable {
+		return p.FindAgentFromRemoteAddr(req.RemoteAddr)
+	}
+	if len(token) == 0 {
+		if p.authCfg.DisableIPFallback {
+			return nil, errors.New("bot token is required")
+		}
+		return p.FindAgentFromRemoteAddr(req.RemoteAddr)
+	}
+
+	claims, err := p.verifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	agentConfig, err := p.FindAgentByContainerName(claims.ContainerName)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(agentConfig.ID, claims.BotID) {
+		return nil, errors.New("bot token does not belong to the bot container")
+	}
+	return agentConfig, nil
+}
+
+// verifyToken accepts only the short-lived tokens for the audience of the services if the
+// tokens are scoped.
+func (p *ipAuthenticator) verifyToken(token string) (*BotTokenClaims, error) {
+	if p.authCfg.ScopedTokens {
+		return VerifyScopedBotToken(token, p.nodeAddress, p.audience)
+	}
+	return VerifyBotToken(token, p.nodeAddress)
+}
+
 func (p *ipAuthenticator) FindAgentByContainerName(containerName string) (*config.AgentConfig, error) {
 	p.agentConfigMu.RLock()
 	defer p.agentConfigMu.RUnlock()
@@ -88,17 +135,30 @@ func (p *ipAuthenticator) handleAgentStatusRunning(payload messaging.AgentPayloa
 	return nil
 }
 
-func NewBotAuthenticator(ctx context.Context) (IPAuthenticator, error) {
+// NewBotAuthenticator creates an authenticator for the services of the bot token audience.
+func NewBotAuthenticator(ctx context.Context, cfg config.Config, audience string) (IPAuthenticator, error) {
+	var nodeAddress common.Address
+	if cfg.BotAuth.Enable {
+		addr, err := config.LoadAddressInContainer(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the node address: %v", err)
+		}
+		nodeAddress = addr
+	}
+
 	globalClient, err := docker.NewDockerClient("")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the global docker client: %v", err)
 	}
-	msgClient := messaging.NewClient("bot-auth", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
+	msgClient := messaging.NewClient("bot-auth", cfg.NatsURL())
 
 	b := &ipAuthenticator{
 		ctx:          ctx,
 		dockerClient: globalClient,
 		msgClient:    msgClient,
+		authCfg:      cfg.BotAuth,
+		nodeAddress:  nodeAddress,
+		audience:     audience,
 	}
 
 	msgClient.Subscribe(messaging.SubjectAgentsStatusRunning, messaging.AgentsHandler(b.handleAgentStatusRunning))
@@ -0,0 +1,121 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testBotRemoteAddr = "1.1.1.1:1111"
+
+func testKey(t *testing.T) *keystore.Key {
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return &keystore.Key{
+		PrivateKey: privKey,
+		Address:    crypto.PubkeyToAddress(privKey.PublicKey),
+	}
+}
+
+func TestIPAuthenticator_FindAgentFromRequest(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	dockerClient := mock_clients.NewMockDockerClient(ctrl)
+
+	key := testKey(t)
+	botConfig := config.AgentConfig{ID: "0xbot", IsLocal: true}
+	p := &ipAuthenticator{
+		ctx:          context.Background(),
+		dockerClient: dockerClient,
+		authCfg:      config.BotAuthConfig{Enable: true},
+		nodeAddress:  key.Address,
+		agentConfigs: []config.AgentConfig{botConfig},
+	}
+
+	// the bot is found from the token and the token is not left in the request
+	token, err := CreateBotToken(signer.NewKeySigner(key), botConfig)
+	r.NoError(err)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.RemoteAddr = testBotRemoteAddr
+	req.Header.Set(BotTokenHeader, token)
+	agentConfig, err := p.FindAgentFromRequest(req)
+	r.NoError(err)
+	r.Equal(botConfig.ID, agentConfig.ID)
+	r.False(HasBotToken(req))
+
+	// the tokens which are not signed by the node are rejected
+	token, err = CreateBotToken(signer.NewKeySigner(testKey(t)), botConfig)
+	r.NoError(err)
+	req.Header.Set(BotTokenHeader, token)
+	_, err = p.FindAgentFromRequest(req)
+	r.Error(err)
+
+	// the requests without a token are authenticated by the remote address
+	dockerClient.EXPECT().GetContainerFromRemoteAddr(gomock.Any(), testBotRemoteAddr).
+		Return(&types.Container{Names: []string{"/" + botConfig.ContainerName()}}, nil)
+	agentConfig, err = p.FindAgentFromRequest(req)
+	r.NoError(err)
+	r.Equal(botConfig.ID, agentConfig.ID)
+
+	// unless the fallback is disabled
+	p.authCfg.DisableIPFallback = true
+	_, err = p.FindAgentFromRequest(req)
+	r.Error(err)
+}
+
+func TestIPAuthenticator_ScopedTokens(t *testing.T) {
+	r := require.New(t)
+
+	key := testKey(t)
+	nodeSigner := signer.NewKeySigner(key)
+	botConfig := config.AgentConfig{ID: "0xbot", IsLocal: true}
+	p := &ipAuthenticator{
+		ctx:          context.Background(),
+		authCfg:      config.BotAuthConfig{Enable: true, ScopedTokens: true, DisableIPFallback: true},
+		nodeAddress:  key.Address,
+		audience:     BotTokenAudienceJSONRPC,
+		agentConfigs: []config.AgentConfig{botConfig},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.RemoteAddr = testBotRemoteAddr
+
+	// the token injected to the bot container is not accepted
+	token, err := CreateBotToken(nodeSigner, botConfig)
+	r.NoError(err)
+	req.Header.Set(BotTokenHeader, token)
+	_, err = p.FindAgentFromRequest(req)
+	r.Error(err)
+
+	// the tokens for the other services are not accepted
+	expiresAt := time.Now().Add(time.Minute)
+	token, err = CreateScopedBotToken(nodeSigner, botConfig.ID, botConfig.ContainerName(), BotTokenAudiencePublicAPI, expiresAt)
+	r.NoError(err)
+	req.Header.Set(BotTokenHeader, token)
+	_, err = p.FindAgentFromRequest(req)
+	r.Error(err)
+
+	// the expired tokens are not accepted
+	token, err = CreateScopedBotToken(nodeSigner, botConfig.ID, botConfig.ContainerName(), BotTokenAudienceJSONRPC, time.Now().Add(-time.Minute))
+	r.NoError(err)
+	req.Header.Set(BotTokenHeader, token)
+	_, err = p.FindAgentFromRequest(req)
+	r.Error(err)
+
+	token, err = CreateScopedBotToken(nodeSigner, botConfig.ID, botConfig.ContainerName(), BotTokenAudienceJSONRPC, expiresAt)
+	r.NoError(err)
+	req.Header.Set(BotTokenHeader, token)
+	agentConfig, err := p.FindAgentFromRequest(req)
+	r.NoError(err)
+	r.Equal(botConfig.ID, agentConfig.ID)
+}
@@ -0,0 +1,123 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// BotTokenHeader is the header which the bots send their tokens in.
+const BotTokenHeader = "X-Forta-Bot-Token"
+
+const (
+	claimKeyBotID        = "bot-id"
+	claimKeyBotContainer = "bot-container"
+	claimKeyAudience     = "aud"
+	claimKeyExpiresAt    = "exp"
+)
+
+// The audiences of the bot tokens
+const (
+	// BotTokenAudienceJWTProvider is the audience of the token injected to the bot containers.
+	BotTokenAudienceJWTProvider = "jwt-provider"
+	BotTokenAudienceJSONRPC     = "json-rpc"
+	BotTokenAudiencePublicAPI   = "public-api"
+)
+
+// IsBotTokenAudience tells if the audience is one of the services which the bots can get scoped tokens for.
+func IsBotTokenAudience(audience string) bool {
+	return audience == BotTokenAudienceJSONRPC || audience == BotTokenAudiencePublicAPI
+}
+
+// BotTokenClaims are the claims of a verified bot token.
+type BotTokenClaims struct {
+	BotID         string
+	ContainerName string
+	// Audience is empty for the tokens which were created before the tokens were scoped.
+	Audience string
+	// ExpiresAt is zero for the tokens which do not expire.
+	ExpiresAt time.Time
+}
+
+// CreateBotToken creates a token signed by the node key which identifies the bot container.
+// The token does not expire and is only valid while the bot container is running.
+func CreateBotToken(nodeSigner signer.Signer, botConfig config.AgentConfig) (string, error) {
+	return signer.CreateScannerJWT(nodeSigner, map[string]interface{}{
+		claimKeyBotID:        botConfig.ID,
+		claimKeyBotContainer: botConfig.ContainerName(),
+		claimKeyAudience:     BotTokenAudienceJWTProvider,
+		claimKeyExpiresAt:    0,
+	})
+}
+
+// CreateScopedBotToken creates a short-lived token which identifies the bot container to the
+// services of the audience.
+func CreateScopedBotToken(
+	nodeSigner signer.Signer, botID, containerName, audience string, expiresAt time.Time,
+) (string, error) {
+	return signer.CreateScannerJWT(nodeSigner, map[string]interface{}{
+		claimKeyBotID:        botID,
+		claimKeyBotContainer: containerName,
+		claimKeyAudience:     audience,
+		claimKeyExpiresAt:    expiresAt.Unix(),
+	})
+}
+
+// VerifyBotToken verifies that the token was signed by the node and returns the claims.
+func VerifyBotToken(tokenStr string, nodeAddress common.Address) (*BotTokenClaims, error) {
+	scannerToken, err := security.VerifyScannerJWT(tokenStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bot token: %v", err)
+	}
+	if !strings.EqualFold(scannerToken.Scanner, nodeAddress.Hex()) {
+		return nil, errors.New("bot token is not signed by the node")
+	}
+	claims, ok := scannerToken.Token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid bot token claims")
+	}
+	botID, _ := claims[claimKeyBotID].(string)
+	containerName, _ := claims[claimKeyBotContainer].(string)
+	if len(botID) == 0 || len(containerName) == 0 {
+		return nil, errors.New("bot token is missing the bot claims")
+	}
+	audience, _ := claims[claimKeyAudience].(string)
+	var expiresAt time.Time
+	if exp, _ := claims[claimKeyExpiresAt].(float64); exp > 0 {
+		expiresAt = time.Unix(int64(exp), 0)
+	}
+	return &BotTokenClaims{
+		BotID:         botID,
+		ContainerName: containerName,
+		Audience:      audience,
+		ExpiresAt:     expiresAt,
+	}, nil
+}
+
+// VerifyScopedBotToken verifies that the token was issued by the node for the audience and expires.
+func VerifyScopedBotToken(tokenStr string, nodeAddress common.Address, audience string) (*BotTokenClaims, error) {
+	claims, err := VerifyBotToken(tokenStr, nodeAddress)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Audience != audience {
+		return nil, fmt.Errorf("bot token is not for %s", audience)
+	}
+	if claims.ExpiresAt.IsZero() {
+		return nil, errors.New("bot token does not expire")
+	}
+	return claims, nil
+}
+
+// HasBotToken tells if the request carries a bot token.
+func HasBotToken(req *http.Request) bool {
+	return len(req.Header.Get(BotTokenHeader)) > 0
+}
@@ -19,8 +19,10 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
+	units "github.com/docker/go-units"
 	"github.com/forta-network/forta-core-go/utils/workers"
 	"github.com/forta-network/forta-node/clients/cooldown"
+	"github.com/forta-network/forta-node/clients/faults"
 	"github.com/forta-network/forta-node/config"
 	log "github.com/sirupsen/logrus"
 )
@@ -31,6 +33,8 @@ const (
 	LabelFortaSupervisorStrategyVersion = "network.forta.supervisor.strategy-version"
 	LabelFortaIsBot                     = "network.forta.is-bot"
 	LabelFortaBotID                     = "network.forta.bot-id"
+	LabelFortaBotDependencyOf           = "network.forta.bot-dependency-of"
+	LabelFortaNodeInstance              = "network.forta.node-instance"
 
 	LabelFortaSettingsAgentLogsEnable = "network.forta.settings.agent-logs.enable"
 )
@@ -71,10 +75,20 @@ type ContainerConfig struct {
 	MaxLogSize      string
 	MaxLogFiles     int
 	CPUQuota        int64
+	CPUWeight       int64 // cgroup cpu.weight (1-10000) which decides the CPU time share when the CPUs are busy
 	Memory          int64
 	Cmd             []string
 	DialHost        bool
 	Labels          map[string]string
+	Ulimits         []Ulimit
+	Tmpfs           map[string]string // container path to the tmpfs mount options
+}
+
+// Ulimit is the soft and the hard limit of a resource like "nofile" or "nproc".
+type Ulimit struct {
+	Name string
+	Soft int64
+	Hard int64
 }
 
 // ContainerList contains the full container data.
@@ -119,6 +133,8 @@ type dockerClient struct {
 	password              string
 	labels                []dockerLabel
 	imageDownloadCooldown cooldown.Cooldown
+	imagePullCfg          config.ImagePullConfig
+	imagePullHandler      ImagePullProgressHandler
 }
 
 func (cfg ContainerConfig) envVars() []string {
@@ -140,12 +156,28 @@ func registryAuthValue(username, password string) string {
 	return base64.StdEncoding.EncodeToString(jsonBytes)
 }
 
-// PullImage pulls an image using the given ref.
+// PullImage pulls an image using the given ref. The pulls which make no progress are retried.
 func (d *dockerClient) PullImage(ctx context.Context, refStr string) error {
 	if d.imageDownloadCooldown != nil && d.imageDownloadCooldown.ShouldCoolDown(refStr) {
 		return fmt.Errorf("too many pull attempts - cooling down: %s", refStr)
 	}
 
+	for attempt := 0; ; attempt++ {
+		err := d.pullImage(ctx, refStr)
+		if !errors.Is(err, ErrImagePullStalled) || attempt >= d.imagePullCfg.StallRetries || ctx.Err() != nil {
+			return err
+		}
+		log.WithFields(log.Fields{
+			"image":   refStr,
+			"attempt": attempt + 1,
+		}).Warn("retrying the stalled image pull")
+	}
+}
+
+func (d *dockerClient) pullImage(ctx context.Context, refStr string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	r, err := d.cli.ImagePull(ctx, refStr, types.ImagePullOptions{
 		RegistryAuth: registryAuthValue(d.username, d.password),
 	})
@@ -153,15 +185,7 @@ func (d *dockerClient) PullImage(ctx context.Context, refStr string) error {
 		return err
 	}
 	defer r.Close()
-	b, err := io.ReadAll(r)
-	if err != nil {
-		return err
-	}
-	respStr := strings.ToLower(string(b))
-	if strings.Contains(respStr, "downloaded") || strings.Contains(respStr, "up to date") {
-		return nil
-	}
-	return fmt.Errorf("unexpected image pull response: %s", string(b))
+	return d.readPull(refStr, r, cancel)
 }
 
 func (d *dockerClient) Prune(ctx context.Context) error {
@@ -380,6 +404,125 @@ func (d *dockerClient) InspectContainer(ctx context.Context, id string) (*types.
 	return &info, nil
 }
 
+// cpuWeightToShares converts the cgroup cpu.weight to the CPU shares which the Docker daemon
+// converts back to the same cpu.weight on the cgroup v2 hosts.
+func cpuWeightToShares(weight int64) int64 {
+	if weight <= 0 {
+		return 0
+	}
+	if weight > 10000 {
+		weight = 10000
+	}
+	// round up so that the daemon does not round it down to a lower weight
+	return 2 + ((weight-1)*262142+9998)/9999
+}
+
+// ContainerExit is how a container exited or how long it has been running.
+type ContainerExit struct {
+	Running   bool
+	ExitCode  int
+	OOMKilled bool
+	// Signal killed the container process if it is not zero.
+	Signal      int
+	RunDuration time.Duration
+	Error       string
+}
+
+// NewContainerExit gets the exit details from the container state.
+func NewContainerExit(state *types.ContainerState) *ContainerExit {
+	exit := &ContainerExit{
+		Running:   state.Running,
+		ExitCode:  state.ExitCode,
+		OOMKilled: state.OOMKilled,
+		Error:     state.Error,
+	}
+	// the shells and the runtimes exit with 128+n after signal n
+	if !state.Running && state.ExitCode > 128 {
+		exit.Signal = state.ExitCode - 128
+	}
+	startedAt, err := time.Parse(time.RFC3339Nano, state.StartedAt)
+	if err != nil || startedAt.IsZero() {
+		return exit
+	}
+	finishedAt := time.Now()
+	if !state.Running {
+		finishedAt, err = time.Parse(time.RFC3339Nano, state.FinishedAt)
+		if err != nil || finishedAt.Before(startedAt) {
+			return exit
+		}
+	}
+	exit.RunDuration = finishedAt.Sub(startedAt)
+	return exit
+}
+
+// ContainerResources is the resource usage of a container.
+type ContainerResources struct {
+	CPUPercent  float64
+	MemoryBytes uint64
+	MemoryLimit uint64
+}
+
+// GetContainerResources gets the current resource usage of a container.
+func (d *dockerClient) GetContainerResources(ctx context.Context, id string) (*ContainerResources, error) {
+	resp, err := d.cli.ContainerStats(ctx, id, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container stats: %v", err)
+	}
+	defer resp.Body.Close()
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("failed to decode container stats: %v", err)
+	}
+
+	resources := &ContainerResources{
+		MemoryBytes: stats.MemoryStats.Usage,
+		MemoryLimit: stats.MemoryStats.Limit,
+	}
+	// same calculation with the docker cli
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		resources.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100
+	}
+	return resources, nil
+}
+
+// HostResources is the capacity of the Docker host.
+type HostResources struct {
+	CPUs        float64
+	MemoryBytes int64
+}
+
+// GetHostResources gets the CPUs and the memory of the Docker host.
+func (d *dockerClient) GetHostResources(ctx context.Context) (*HostResources, error) {
+	info, err := d.cli.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the docker info: %v", err)
+	}
+	return &HostResources{
+		CPUs:        float64(info.NCPU),
+		MemoryBytes: info.MemTotal,
+	}, nil
+}
+
+// UpdateContainerResources updates the CPU quota and the memory limit of a running container.
+func (d *dockerClient) UpdateContainerResources(ctx context.Context, id string, cpuQuota, memory int64) error {
+	resources := container.Resources{
+		CPUQuota: cpuQuota,
+		Memory:   memory,
+	}
+	// same with the default of the docker daemon so that the new memory limit is never above it
+	if memory > 0 {
+		resources.MemorySwap = memory * 2
+	}
+	_, err := d.cli.ContainerUpdate(ctx, id, container.UpdateConfig{Resources: resources})
+	return err
+}
+
 // Nuke makes sure that all running Forta containers are stopped and pruned, quickly enough.
 func (d *dockerClient) Nuke(ctx context.Context) error {
 	var err error
@@ -538,9 +681,18 @@ func (d *dockerClient) StartContainer(ctx context.Context, config ContainerConfi
 			Type: "json-file",
 		},
 		Resources: container.Resources{
-			CPUQuota: config.CPUQuota,
-			Memory:   config.Memory,
+			CPUQuota:  config.CPUQuota,
+			CPUShares: cpuWeightToShares(config.CPUWeight),
+			Memory:    config.Memory,
 		},
+		Tmpfs: config.Tmpfs,
+	}
+	for _, ulimit := range config.Ulimits {
+		hostCfg.Ulimits = append(hostCfg.Ulimits, &units.Ulimit{
+			Name: ulimit.Name,
+			Soft: ulimit.Soft,
+			Hard: ulimit.Hard,
+		})
 	}
 
 	if config.DialHost {
@@ -622,6 +774,12 @@ func (d *dockerClient) RemoveContainer(ctx context.Context, containerID string)
 	})
 }
 
+// RenameContainer renames a container. Containers on user-defined networks become
+// resolvable by the new name right after renaming.
+func (d *dockerClient) RenameContainer(ctx context.Context, containerID, newName string) error {
+	return d.cli.ContainerRename(ctx, containerID, newName)
+}
+
 func isNoSuchContainerErr(err error) bool {
 	return strings.Contains(strings.ToLower(err.Error()), "no such container")
 }
@@ -730,6 +888,15 @@ func (d *dockerClient) HasLocalImage(ctx context.Context, ref string) (bool, err
 	return true, nil
 }
 
+// GetImageSize returns the size of a local image in bytes.
+func (d *dockerClient) GetImageSize(ctx context.Context, ref string) (int64, error) {
+	inspect, _, err := d.cli.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return 0, err
+	}
+	return inspect.Size, nil
+}
+
 // EnsureLocalImage ensures that we have the image locally.
 func (d *dockerClient) EnsureLocalImage(ctx context.Context, name, ref string) error {
 	logger := log.WithFields(log.Fields{
@@ -756,6 +923,33 @@ func (d *dockerClient) EnsureLocalImage(ctx context.Context, name, ref string) e
 	return nil
 }
 
+// TagImage tags the source image with the target ref.
+func (d *dockerClient) TagImage(ctx context.Context, source, target string) error {
+	return d.cli.ImageTag(ctx, source, target)
+}
+
+// SaveImages writes the images to the writer as a tar archive.
+func (d *dockerClient) SaveImages(ctx context.Context, refs []string, w io.Writer) error {
+	r, err := d.cli.ImageSave(ctx, refs)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// LoadImages loads the images from a tar archive.
+func (d *dockerClient) LoadImages(ctx context.Context, r io.Reader) error {
+	resp, err := d.cli.ImageLoad(ctx, r, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
+
 // ImagePull data about an image to pull.
 type ImagePull struct {
 	Name string
@@ -780,8 +974,17 @@ func (d *dockerClient) EnsureLocalImages(ctx context.Context, timeoutPerPull tim
 
 // GetContainerLogs gets the container logs.
 func (d *dockerClient) GetContainerLogs(ctx context.Context, containerID, tail string, truncate int) (string, error) {
+	return d.getContainerLogs(ctx, containerID, tail, truncate, true)
+}
+
+// GetContainerStderrLogs gets only the stderr logs of the container.
+func (d *dockerClient) GetContainerStderrLogs(ctx context.Context, containerID, tail string, truncate int) (string, error) {
+	return d.getContainerLogs(ctx, containerID, tail, truncate, false)
+}
+
+func (d *dockerClient) getContainerLogs(ctx context.Context, containerID, tail string, truncate int, showStdout bool) (string, error) {
 	r, err := d.cli.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{
-		ShowStdout: true,
+		ShowStdout: showStdout,
 		ShowStderr: true,
 		Timestamps: true,
 		Tail:       tail,
@@ -877,9 +1080,36 @@ func (d *dockerClient) SetImagePullCooldown(threshold int, cooldownDuration time
 	d.imageDownloadCooldown = cooldown.New(threshold, cooldownDuration)
 }
 
+// dockerHost overrides the default Docker daemon address of the platform when set.
+var dockerHost string
+
+// SetHost sets the Docker daemon address for the clients created after it, e.g. the named pipe
+// of Docker Desktop. It should be called before any clients are created.
+func SetHost(host string) {
+	dockerHost = host
+}
+
+// withHost connects the client to the configured Docker daemon.
+func withHost(cli *client.Client) error {
+	if len(dockerHost) == 0 {
+		return nil
+	}
+	return client.WithHost(dockerHost)(cli)
+}
+
+// withFaults makes the client time out when the docker daemon timeouts are injected.
+func withFaults(cli *client.Client) error {
+	if !faults.Enabled(faults.DockerTimeout) {
+		return nil
+	}
+	httpClient := cli.HTTPClient()
+	httpClient.Transport = faults.Transport(faults.DockerTimeout, httpClient.Transport)
+	return client.WithHTTPClient(httpClient)(cli)
+}
+
 // NewDockerClient creates a new docker client
 func NewDockerClient(name string) (*dockerClient, error) {
-	cli, err := client.NewClientWithOpts()
+	cli, err := client.NewClientWithOpts(withHost, withFaults)
 	if err != nil {
 		return nil, err
 	}
@@ -895,7 +1125,7 @@ func NewAuthDockerClient(name string, username, password string) (*dockerClient,
 	if len(username) == 0 && len(password) == 0 {
 		return NewDockerClient(name)
 	}
-	cli, err := client.NewClientWithOpts()
+	cli, err := client.NewClientWithOpts(withHost, withFaults)
 	if err != nil {
 		return nil, err
 	}
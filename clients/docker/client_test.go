@@ -0,0 +1,42 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContainerExit(t *testing.T) {
+	r := require.New(t)
+
+	exit := NewContainerExit(&types.ContainerState{
+		ExitCode:   137,
+		OOMKilled:  true,
+		StartedAt:  "2023-01-01T00:00:00.000000001Z",
+		FinishedAt: "2023-01-01T00:01:00.000000001Z",
+	})
+	r.Equal(&ContainerExit{
+		ExitCode:    137,
+		OOMKilled:   true,
+		Signal:      9,
+		RunDuration: time.Minute,
+	}, exit)
+
+	// the containers which never finished have only the exit code
+	exit = NewContainerExit(&types.ContainerState{
+		ExitCode:   1,
+		StartedAt:  "2023-01-01T00:00:00Z",
+		FinishedAt: "0001-01-01T00:00:00Z",
+	})
+	r.Equal(&ContainerExit{ExitCode: 1}, exit)
+
+	exit = NewContainerExit(&types.ContainerState{
+		Running:   true,
+		StartedAt: time.Now().Add(-time.Hour).Format(time.RFC3339Nano),
+	})
+	r.True(exit.Running)
+	r.Zero(exit.Signal)
+	r.InDelta(time.Hour, exit.RunDuration, float64(time.Minute))
+}
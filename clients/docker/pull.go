@@ -0,0 +1,228 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrImagePullStalled is returned when an image pull makes no progress for too long.
+var ErrImagePullStalled = errors.New("image pull is stalled")
+
+// pullWatchInterval is how often the pulls are checked for the progress reports and the stalls.
+var pullWatchInterval = time.Second
+
+// ImagePullProgress is the progress of an image pull.
+type ImagePullProgress struct {
+	Ref string
+	// Layers are the download percentages of the image layers by the layer IDs.
+	Layers map[string]float64
+	// Stalled tells if the pull is aborted because it made no progress.
+	Stalled bool
+}
+
+// Percent returns the download percentage of all layers.
+func (progress ImagePullProgress) Percent() float64 {
+	if len(progress.Layers) == 0 {
+		return 0
+	}
+	var total float64
+	for _, percent := range progress.Layers {
+		total += percent
+	}
+	return total / float64(len(progress.Layers))
+}
+
+// String formats the layer percentages.
+func (progress ImagePullProgress) String() string {
+	var layers []string
+	for layerID, percent := range progress.Layers {
+		layers = append(layers, fmt.Sprintf("%s=%.0f%%", layerID, percent))
+	}
+	sort.Strings(layers)
+	return strings.Join(layers, " ")
+}
+
+// ImagePullProgressHandler receives the periodic progress reports of the image pulls.
+type ImagePullProgressHandler func(progress ImagePullProgress)
+
+// pullMessage is a JSON message from the image pull stream of the Docker API.
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	Error string `json:"error"`
+}
+
+type pullTracker struct {
+	ref          string
+	layers       map[string]float64
+	lastProgress time.Time
+	stalled      bool
+	mu           sync.Mutex
+}
+
+func newPullTracker(ref string) *pullTracker {
+	return &pullTracker{
+		ref:          ref,
+		layers:       make(map[string]float64),
+		lastProgress: time.Now(),
+	}
+}
+
+func (pt *pullTracker) update(msg *pullMessage) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	pt.lastProgress = time.Now()
+	if len(msg.ID) == 0 {
+		return
+	}
+	switch msg.Status {
+	case "Pulling fs layer", "Waiting":
+		pt.layers[msg.ID] = 0
+	case "Downloading":
+		if msg.ProgressDetail.Total > 0 {
+			pt.layers[msg.ID] = float64(msg.ProgressDetail.Current) * 100 / float64(msg.ProgressDetail.Total)
+		}
+	case "Download complete", "Pull complete", "Already exists":
+		pt.layers[msg.ID] = 100
+	}
+}
+
+func (pt *pullTracker) progress() ImagePullProgress {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	layers := make(map[string]float64)
+	for layerID, percent := range pt.layers {
+		layers[layerID] = percent
+	}
+	return ImagePullProgress{
+		Ref:     pt.ref,
+		Layers:  layers,
+		Stalled: pt.stalled,
+	}
+}
+
+// checkStalled marks the pull as stalled if it made no progress since the timeout.
+func (pt *pullTracker) checkStalled(timeout time.Duration) bool {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if time.Since(pt.lastProgress) >= timeout {
+		pt.stalled = true
+	}
+	return pt.stalled
+}
+
+func (pt *pullTracker) isStalled() bool {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.stalled
+}
+
+// readPull reads the image pull stream until the pull ends. The progress is reported periodically
+// and the pull is aborted if it makes no progress until the stall timeout.
+func (d *dockerClient) readPull(refStr string, r io.Reader, abort func()) error {
+	tracker := newPullTracker(refStr)
+	done := make(chan struct{})
+	defer close(done)
+	go d.watchPull(tracker, abort, done)
+
+	var (
+		lastStatus string
+		pulled     bool
+	)
+	dec := json.NewDecoder(r)
+	for {
+		var msg pullMessage
+		err := dec.Decode(&msg)
+		if tracker.isStalled() {
+			return ErrImagePullStalled
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(msg.Error) > 0 {
+			return errors.New(msg.Error)
+		}
+		tracker.update(&msg)
+		lastStatus = msg.Status
+		status := strings.ToLower(msg.Status)
+		if strings.Contains(status, "downloaded") || strings.Contains(status, "up to date") {
+			pulled = true
+		}
+	}
+	if !pulled {
+		return fmt.Errorf("unexpected image pull response: %s", lastStatus)
+	}
+	return nil
+}
+
+func (d *dockerClient) watchPull(tracker *pullTracker, abort func(), done <-chan struct{}) {
+	cfg := d.imagePullCfg
+	if cfg.ProgressIntervalSeconds == 0 && cfg.StallTimeoutSeconds == 0 {
+		return
+	}
+	reportInterval := time.Duration(cfg.ProgressIntervalSeconds) * time.Second
+	stallTimeout := time.Duration(cfg.StallTimeoutSeconds) * time.Second
+	lastReport := time.Now()
+
+	ticker := time.NewTicker(pullWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		if stallTimeout > 0 && tracker.checkStalled(stallTimeout) {
+			d.reportPull(tracker.progress())
+			abort()
+			return
+		}
+		if reportInterval > 0 && time.Since(lastReport) >= reportInterval {
+			d.reportPull(tracker.progress())
+			lastReport = time.Now()
+		}
+	}
+}
+
+func (d *dockerClient) reportPull(progress ImagePullProgress) {
+	logger := log.WithFields(log.Fields{
+		"image":   progress.Ref,
+		"percent": fmt.Sprintf("%.1f", progress.Percent()),
+		"layers":  progress.String(),
+	})
+	if progress.Stalled {
+		logger.Warn("image pull made no progress - aborting")
+	} else {
+		logger.Info("pulling image")
+	}
+	if d.imagePullHandler != nil {
+		d.imagePullHandler(progress)
+	}
+}
+
+// SetImagePullProgress sets how the progress of the image pulls is reported and when the
+// stalled pulls are retried.
+func (d *dockerClient) SetImagePullProgress(cfg config.ImagePullConfig, handler ImagePullProgressHandler) {
+	d.imagePullCfg = cfg
+	d.imagePullHandler = handler
+}
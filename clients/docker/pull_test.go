@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+const testPullResponse = `{"status":"Pulling from forta/bot","id":"latest"}
+{"status":"Pulling fs layer","id":"layer1"}
+{"status":"Already exists","id":"layer2"}
+{"status":"Downloading","progressDetail":{"current":50,"total":200},"id":"layer1"}
+{"status":"Download complete","id":"layer1"}
+{"status":"Extracting","progressDetail":{"current":200,"total":200},"id":"layer1"}
+{"status":"Pull complete","id":"layer1"}
+{"status":"Status: Downloaded newer image for forta/bot:latest"}
+`
+
+func TestReadPull(t *testing.T) {
+	r := require.New(t)
+
+	d := &dockerClient{}
+	r.NoError(d.readPull("forta/bot", strings.NewReader(testPullResponse), func() {}))
+
+	r.EqualError(d.readPull("forta/bot", strings.NewReader(`{"error":"pull access denied"}`), func() {}), "pull access denied")
+	r.Error(d.readPull("forta/bot", strings.NewReader(`{"status":"Pulling from forta/bot","id":"latest"}`), func() {}))
+}
+
+func TestPullTracker(t *testing.T) {
+	r := require.New(t)
+
+	tracker := newPullTracker("forta/bot")
+	tracker.update(&pullMessage{ID: "layer1", Status: "Pulling fs layer"})
+	tracker.update(&pullMessage{ID: "layer2", Status: "Already exists"})
+	msg := &pullMessage{ID: "layer1", Status: "Downloading"}
+	msg.ProgressDetail.Current = 50
+	msg.ProgressDetail.Total = 200
+	tracker.update(msg)
+
+	progress := tracker.progress()
+	r.Equal(map[string]float64{"layer1": 25, "layer2": 100}, progress.Layers)
+	r.Equal(62.5, progress.Percent())
+	r.Equal("layer1=25% layer2=100%", progress.String())
+	r.False(tracker.checkStalled(time.Minute))
+}
+
+func TestReadPull_Stalled(t *testing.T) {
+	r := require.New(t)
+
+	pullWatchInterval = time.Millisecond * 10
+	defer func() { pullWatchInterval = time.Second }()
+
+	var reports []ImagePullProgress
+	d := &dockerClient{}
+	d.SetImagePullProgress(config.ImagePullConfig{StallTimeoutSeconds: 1}, func(progress ImagePullProgress) {
+		reports = append(reports, progress)
+	})
+
+	// the pull makes some progress and then hangs until it is aborted
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte(`{"status":"Pulling fs layer","id":"layer1"}` + "\n"))
+	}()
+	err := d.readPull("forta/bot", pr, func() {
+		pr.CloseWithError(io.ErrUnexpectedEOF)
+	})
+	r.ErrorIs(err, ErrImagePullStalled)
+	r.Len(reports, 1)
+	r.True(reports[0].Stalled)
+	r.Equal(map[string]float64{"layer1": 0}, reports[0].Layers)
+}
@@ -0,0 +1,108 @@
+package faults
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Fault is a failure which the clients can inject.
+type Fault string
+
+// Faults
+const (
+	DockerTimeout Fault = "docker-timeout"
+	IPFSNotFound  Fault = "ipfs-not-found"
+	RPCRateLimit  Fault = "rpc-rate-limit"
+	GRPCReset     Fault = "grpc-reset"
+)
+
+var injector = struct {
+	faults map[Fault]config.FaultConfig
+	rand   *rand.Rand
+	mu     sync.Mutex
+}{}
+
+// Configure sets the faults which the clients should inject. It should be called before
+// any clients are created since the clients are not wrapped if the faults are disabled.
+func Configure(cfg config.FaultInjectionConfig) {
+	injector.mu.Lock()
+	defer injector.mu.Unlock()
+
+	injector.faults = nil
+	if !cfg.Enable {
+		return
+	}
+	injector.faults = map[Fault]config.FaultConfig{
+		DockerTimeout: cfg.DockerTimeout,
+		IPFSNotFound:  cfg.IPFSNotFound,
+		RPCRateLimit:  cfg.RPCRateLimit,
+		GRPCReset:     cfg.GRPCReset,
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	injector.rand = rand.New(rand.NewSource(seed))
+	log.WithField("seed", seed).Warn("fault injection is enabled - this should never be used in production")
+}
+
+// Enabled tells if the fault can be injected.
+func Enabled(fault Fault) bool {
+	injector.mu.Lock()
+	defer injector.mu.Unlock()
+	return injector.faults[fault].Probability > 0
+}
+
+// Inject tells if the fault should be injected now.
+func Inject(fault Fault) bool {
+	injector.mu.Lock()
+	defer injector.mu.Unlock()
+	probability := injector.faults[fault].Probability
+	if probability <= 0 || injector.rand.Float64() >= probability {
+		return false
+	}
+	log.WithField("fault", fault).Debug("injecting fault")
+	return true
+}
+
+// wait waits for the delay of the fault before the client fails.
+func wait(ctx context.Context, fault Fault) error {
+	injector.mu.Lock()
+	delay := time.Duration(injector.faults[fault].DelayMs) * time.Millisecond
+	injector.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// Error is an injected fault.
+type Error struct {
+	Fault Fault
+}
+
+// Error implements the error interface.
+func (err *Error) Error() string {
+	return fmt.Sprintf("injected fault: %s", err.Fault)
+}
+
+// Timeout implements the net.Error interface.
+func (err *Error) Timeout() bool {
+	return err.Fault == DockerTimeout
+}
+
+// Temporary implements the net.Error interface.
+func (err *Error) Temporary() bool {
+	return true
+}
@@ -0,0 +1,98 @@
+package faults
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTransport(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	Configure(config.FaultInjectionConfig{
+		Enable:        true,
+		Seed:          1,
+		DockerTimeout: config.FaultConfig{Probability: 1},
+		IPFSNotFound:  config.FaultConfig{Probability: 1},
+		RPCRateLimit:  config.FaultConfig{Probability: 1},
+	})
+	defer Configure(config.FaultInjectionConfig{})
+
+	// the disabled faults do not wrap the transport
+	r.Equal(http.DefaultTransport, Transport(GRPCReset, http.DefaultTransport))
+
+	resp, err := (&http.Client{Transport: Transport(IPFSNotFound, nil)}).Get(server.URL)
+	r.NoError(err)
+	resp.Body.Close()
+	r.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp, err = (&http.Client{Transport: Transport(RPCRateLimit, nil)}).Get(server.URL)
+	r.NoError(err)
+	resp.Body.Close()
+	r.Equal(http.StatusTooManyRequests, resp.StatusCode)
+
+	_, err = (&http.Client{Transport: Transport(DockerTimeout, nil)}).Get(server.URL)
+	var netErr net.Error
+	r.ErrorAs(err, &netErr)
+	r.True(netErr.Timeout())
+}
+
+func TestInject(t *testing.T) {
+	r := require.New(t)
+
+	Configure(config.FaultInjectionConfig{
+		Enable:       true,
+		Seed:         1,
+		IPFSNotFound: config.FaultConfig{Probability: 0.5},
+	})
+	defer Configure(config.FaultInjectionConfig{})
+
+	var injected int
+	for i := 0; i < 1000; i++ {
+		if Inject(IPFSNotFound) {
+			injected++
+		}
+	}
+	r.InDelta(500, injected, 100)
+	r.False(Inject(RPCRateLimit))
+
+	// nothing is injected when the fault injection is disabled
+	Configure(config.FaultInjectionConfig{IPFSNotFound: config.FaultConfig{Probability: 1}})
+	r.False(Enabled(IPFSNotFound))
+	r.False(Inject(IPFSNotFound))
+}
+
+func TestGRPCReset(t *testing.T) {
+	r := require.New(t)
+
+	r.Empty(DialOptions())
+
+	Configure(config.FaultInjectionConfig{
+		Enable:    true,
+		GRPCReset: config.FaultConfig{Probability: 1},
+	})
+	defer Configure(config.FaultInjectionConfig{})
+
+	r.Len(DialOptions(), 2)
+	err := unaryClientInterceptor(context.Background(), "/method", nil, nil, nil, nil)
+	r.Equal(codes.Unavailable, status.Code(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	Configure(config.FaultInjectionConfig{
+		Enable:    true,
+		GRPCReset: config.FaultConfig{Probability: 1, DelayMs: 1000},
+	})
+	_, err = streamClientInterceptor(ctx, nil, nil, "/method", nil)
+	r.Equal(codes.Canceled, status.Code(err))
+}
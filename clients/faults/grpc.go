@@ -0,0 +1,52 @@
+package faults
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DialOptions returns the interceptors which reset the gRPC calls. It returns nothing if
+// the fault is disabled.
+func DialOptions() []grpc.DialOption {
+	if !Enabled(GRPCReset) {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(unaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(streamClientInterceptor),
+	}
+}
+
+func unaryClientInterceptor(
+	ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+) error {
+	if err := resetCall(ctx); err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func streamClientInterceptor(
+	ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	if err := resetCall(ctx); err != nil {
+		return nil, err
+	}
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+// resetCall returns the error which the client gets when the connection is reset.
+func resetCall(ctx context.Context) error {
+	if !Inject(GRPCReset) {
+		return nil
+	}
+	if err := wait(ctx, GRPCReset); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Unavailable, "connection reset by peer (injected fault)")
+}
@@ -0,0 +1,66 @@
+package faults
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+type transport struct {
+	fault Fault
+	next  http.RoundTripper
+}
+
+// Transport wraps the HTTP transport to inject the fault. It returns the given transport
+// if the fault is disabled.
+func Transport(fault Fault, next http.RoundTripper) http.RoundTripper {
+	if !Enabled(fault) {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{fault: fault, next: next}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !Inject(t.fault) {
+		return t.next.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if err := wait(req.Context(), t.fault); err != nil {
+		return nil, err
+	}
+	switch t.fault {
+	case IPFSNotFound:
+		return newResponse(req, http.StatusNotFound), nil
+	case RPCRateLimit:
+		resp := newResponse(req, http.StatusTooManyRequests)
+		resp.Header.Set("Retry-After", "1")
+		return resp, nil
+	default:
+		return nil, &Error{Fault: t.fault}
+	}
+}
+
+func newResponse(req *http.Request, statusCode int) *http.Response {
+	body := []byte(http.StatusText(statusCode) + " (injected fault)")
+	header := make(http.Header)
+	header.Set("Content-Type", "text/plain")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -2,6 +2,8 @@ package clients
 
 import (
 	"context"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/forta-network/forta-core-go/domain"
@@ -28,23 +30,33 @@ type DockerClient interface {
 	GetContainerByName(ctx context.Context, name string) (*types.Container, error)
 	GetContainerByID(ctx context.Context, id string) (*types.Container, error)
 	InspectContainer(ctx context.Context, id string) (*types.ContainerJSON, error)
+	GetContainerResources(ctx context.Context, id string) (*docker.ContainerResources, error)
+	GetHostResources(ctx context.Context) (*docker.HostResources, error)
+	UpdateContainerResources(ctx context.Context, id string, cpuQuota, memory int64) error
 	StartContainerWithID(ctx context.Context, containerID string) error
 	StartContainer(ctx context.Context, config docker.ContainerConfig) (*docker.Container, error)
 	StopContainer(ctx context.Context, id string) error
 	InterruptContainer(ctx context.Context, id string) error
 	TerminateContainer(ctx context.Context, id string) error
 	RemoveContainer(ctx context.Context, containerID string) error
+	RenameContainer(ctx context.Context, containerID, newName string) error
 	WaitContainerExit(ctx context.Context, id string) error
 	WaitContainerStart(ctx context.Context, id string) error
 	Prune(ctx context.Context) error
 	WaitContainerPrune(ctx context.Context, id string) error
 	Nuke(ctx context.Context) error
 	HasLocalImage(ctx context.Context, ref string) (bool, error)
+	GetImageSize(ctx context.Context, ref string) (int64, error)
 	EnsureLocalImage(ctx context.Context, name, ref string) error
 	EnsureLocalImages(ctx context.Context, timeoutPerPull time.Duration, imagePulls []docker.ImagePull) []error
+	TagImage(ctx context.Context, source, target string) error
+	SaveImages(ctx context.Context, refs []string, w io.Writer) error
+	LoadImages(ctx context.Context, r io.Reader) error
 	GetContainerLogs(ctx context.Context, containerID, tail string, truncate int) (string, error)
+	GetContainerStderrLogs(ctx context.Context, containerID, tail string, truncate int) (string, error)
 	GetContainerFromRemoteAddr(ctx context.Context, hostPort string) (*types.Container, error)
 	SetImagePullCooldown(threshold int, cooldownDuration time.Duration)
+	SetImagePullProgress(cfg config.ImagePullConfig, handler docker.ImagePullProgressHandler)
 }
 
 // MessageClient receives and publishes messages.
@@ -62,6 +74,7 @@ type AlertAPIClient interface {
 type IPAuthenticator interface {
 	Authenticate(ctx context.Context, hostPort string) error
 	FindAgentFromRemoteAddr(hostPort string) (*config.AgentConfig, error)
+	FindAgentFromRequest(req *http.Request) (*config.AgentConfig, error)
 	FindContainerNameFromRemoteAddr(ctx context.Context, hostPort string) (string, error)
 	FindAgentByContainerName(containerName string) (*config.AgentConfig, error)
 }
@@ -39,3 +39,26 @@ func toFiles(path string) ipfsapi.AddOpts {
 		return nil
 	}
 }
+
+// CatWithContext is the same as Cat but it can be cancelled.
+func (client *Client) CatWithContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	resp, err := client.Request("cat", path).Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		resp.Close()
+		return nil, resp.Error
+	}
+	return resp.Output, nil
+}
+
+// PinWithContext is the same as Pin but it can be cancelled.
+func (client *Client) PinWithContext(ctx context.Context, path string) error {
+	return client.Request("pin/add", path).Option("recursive", true).Exec(ctx, nil)
+}
+
+// UnpinWithContext is the same as Unpin but it can be cancelled.
+func (client *Client) UnpinWithContext(ctx context.Context, path string) error {
+	return client.Request("pin/rm", path).Option("recursive", true).Exec(ctx, nil)
+}
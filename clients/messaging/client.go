@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
 	"github.com/goccy/go-json"
 	"github.com/golang/protobuf/proto"
 	"github.com/nats-io/nats.go"
@@ -55,6 +56,8 @@ type SubscriptionHandler func(SubscriptionPayload) error
 type AgentMetricHandler func(*protocol.AgentMetricList) error
 type InspectionResultsHandler func(results *protocol.InspectionResults) error
 type ScannerHandler func(ScannerPayload) error
+type LogLevelHandler func(LogLevelPayload) error
+type ConfigReloadHandler func(*config.ReloadReport) error
 
 // Subscribe subscribes the consumer to this client.
 func (client *Client) Subscribe(subject string, handler interface{}) {
@@ -104,6 +107,22 @@ func (client *Client) Subscribe(subject string, handler interface{}) {
 			}
 			err = h(payload)
 
+		case LogLevelHandler:
+			var payload LogLevelPayload
+			err = json.Unmarshal(m.Data, &payload)
+			if err != nil {
+				break
+			}
+			err = h(payload)
+
+		case ConfigReloadHandler:
+			var payload config.ReloadReport
+			err = json.Unmarshal(m.Data, &payload)
+			if err != nil {
+				break
+			}
+			err = h(&payload)
+
 		default:
 			logger.Panicf("no handler found")
 		}
@@ -19,6 +19,8 @@ const (
 	SubjectScannerBlock           = "scanner.block"
 	SubjectScannerAlert           = "scanner.alert"
 	SubjectInspectionDone         = "inspection.done"
+	SubjectLogLevel               = "log.level"
+	SubjectConfigReload           = "config.reload"
 )
 
 // AgentPayload is the message payload.
@@ -34,3 +36,9 @@ type SubscriptionPayload []*domain.CombinerBotSubscription
 type ScannerPayload struct {
 	LatestBlockInput uint64 `json:"latestBlockInput"`
 }
+
+// LogLevelPayload is the message payload for changing the log level of a component.
+type LogLevelPayload struct {
+	Component string `json:"component"`
+	Level     string `json:"level"`
+}
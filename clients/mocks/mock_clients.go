@@ -6,6 +6,8 @@ package mock_clients
 
 import (
 	context "context"
+	io "io"
+	http "net/http"
 	reflect "reflect"
 	time "time"
 
@@ -186,6 +188,36 @@ func (mr *MockDockerClientMockRecorder) GetContainerLogs(ctx, containerID, tail,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerLogs", reflect.TypeOf((*MockDockerClient)(nil).GetContainerLogs), ctx, containerID, tail, truncate)
 }
 
+// GetContainerResources mocks base method.
+func (m *MockDockerClient) GetContainerResources(ctx context.Context, id string) (*docker.ContainerResources, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerResources", ctx, id)
+	ret0, _ := ret[0].(*docker.ContainerResources)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContainerResources indicates an expected call of GetContainerResources.
+func (mr *MockDockerClientMockRecorder) GetContainerResources(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerResources", reflect.TypeOf((*MockDockerClient)(nil).GetContainerResources), ctx, id)
+}
+
+// GetContainerStderrLogs mocks base method.
+func (m *MockDockerClient) GetContainerStderrLogs(ctx context.Context, containerID, tail string, truncate int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerStderrLogs", ctx, containerID, tail, truncate)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContainerStderrLogs indicates an expected call of GetContainerStderrLogs.
+func (mr *MockDockerClientMockRecorder) GetContainerStderrLogs(ctx, containerID, tail, truncate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerStderrLogs", reflect.TypeOf((*MockDockerClient)(nil).GetContainerStderrLogs), ctx, containerID, tail, truncate)
+}
+
 // GetContainers mocks base method.
 func (m *MockDockerClient) GetContainers(ctx context.Context) (docker.ContainerList, error) {
 	m.ctrl.T.Helper()
@@ -231,6 +263,36 @@ func (mr *MockDockerClientMockRecorder) GetFortaServiceContainers(ctx interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFortaServiceContainers", reflect.TypeOf((*MockDockerClient)(nil).GetFortaServiceContainers), ctx)
 }
 
+// GetHostResources mocks base method.
+func (m *MockDockerClient) GetHostResources(ctx context.Context) (*docker.HostResources, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHostResources", ctx)
+	ret0, _ := ret[0].(*docker.HostResources)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHostResources indicates an expected call of GetHostResources.
+func (mr *MockDockerClientMockRecorder) GetHostResources(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHostResources", reflect.TypeOf((*MockDockerClient)(nil).GetHostResources), ctx)
+}
+
+// GetImageSize mocks base method.
+func (m *MockDockerClient) GetImageSize(ctx context.Context, ref string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageSize", ctx, ref)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageSize indicates an expected call of GetImageSize.
+func (mr *MockDockerClientMockRecorder) GetImageSize(ctx, ref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageSize", reflect.TypeOf((*MockDockerClient)(nil).GetImageSize), ctx, ref)
+}
+
 // HasLocalImage mocks base method.
 func (m *MockDockerClient) HasLocalImage(ctx context.Context, ref string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -275,6 +337,20 @@ func (mr *MockDockerClientMockRecorder) InterruptContainer(ctx, id interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InterruptContainer", reflect.TypeOf((*MockDockerClient)(nil).InterruptContainer), ctx, id)
 }
 
+// LoadImages mocks base method.
+func (m *MockDockerClient) LoadImages(ctx context.Context, r io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadImages", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadImages indicates an expected call of LoadImages.
+func (mr *MockDockerClientMockRecorder) LoadImages(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadImages", reflect.TypeOf((*MockDockerClient)(nil).LoadImages), ctx, r)
+}
+
 // Nuke mocks base method.
 func (m *MockDockerClient) Nuke(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -359,6 +435,34 @@ func (mr *MockDockerClientMockRecorder) RemoveNetworkByName(ctx, networkName int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveNetworkByName", reflect.TypeOf((*MockDockerClient)(nil).RemoveNetworkByName), ctx, networkName)
 }
 
+// RenameContainer mocks base method.
+func (m *MockDockerClient) RenameContainer(ctx context.Context, containerID, newName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameContainer", ctx, containerID, newName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameContainer indicates an expected call of RenameContainer.
+func (mr *MockDockerClientMockRecorder) RenameContainer(ctx, containerID, newName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameContainer", reflect.TypeOf((*MockDockerClient)(nil).RenameContainer), ctx, containerID, newName)
+}
+
+// SaveImages mocks base method.
+func (m *MockDockerClient) SaveImages(ctx context.Context, refs []string, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveImages", ctx, refs, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveImages indicates an expected call of SaveImages.
+func (mr *MockDockerClientMockRecorder) SaveImages(ctx, refs, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveImages", reflect.TypeOf((*MockDockerClient)(nil).SaveImages), ctx, refs, w)
+}
+
 // SetImagePullCooldown mocks base method.
 func (m *MockDockerClient) SetImagePullCooldown(threshold int, cooldownDuration time.Duration) {
 	m.ctrl.T.Helper()
@@ -371,6 +475,18 @@ func (mr *MockDockerClientMockRecorder) SetImagePullCooldown(threshold, cooldown
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetImagePullCooldown", reflect.TypeOf((*MockDockerClient)(nil).SetImagePullCooldown), threshold, cooldownDuration)
 }
 
+// SetImagePullProgress mocks base method.
+func (m *MockDockerClient) SetImagePullProgress(cfg config.ImagePullConfig, handler docker.ImagePullProgressHandler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetImagePullProgress", cfg, handler)
+}
+
+// SetImagePullProgress indicates an expected call of SetImagePullProgress.
+func (mr *MockDockerClientMockRecorder) SetImagePullProgress(cfg, handler interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetImagePullProgress", reflect.TypeOf((*MockDockerClient)(nil).SetImagePullProgress), cfg, handler)
+}
+
 // StartContainer mocks base method.
 func (m *MockDockerClient) StartContainer(ctx context.Context, config docker.ContainerConfig) (*docker.Container, error) {
 	m.ctrl.T.Helper()
@@ -414,6 +530,20 @@ func (mr *MockDockerClientMockRecorder) StopContainer(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopContainer", reflect.TypeOf((*MockDockerClient)(nil).StopContainer), ctx, id)
 }
 
+// TagImage mocks base method.
+func (m *MockDockerClient) TagImage(ctx context.Context, source, target string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagImage", ctx, source, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TagImage indicates an expected call of TagImage.
+func (mr *MockDockerClientMockRecorder) TagImage(ctx, source, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagImage", reflect.TypeOf((*MockDockerClient)(nil).TagImage), ctx, source, target)
+}
+
 // TerminateContainer mocks base method.
 func (m *MockDockerClient) TerminateContainer(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -428,6 +558,20 @@ func (mr *MockDockerClientMockRecorder) TerminateContainer(ctx, id interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TerminateContainer", reflect.TypeOf((*MockDockerClient)(nil).TerminateContainer), ctx, id)
 }
 
+// UpdateContainerResources mocks base method.
+func (m *MockDockerClient) UpdateContainerResources(ctx context.Context, id string, cpuQuota, memory int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateContainerResources", ctx, id, cpuQuota, memory)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateContainerResources indicates an expected call of UpdateContainerResources.
+func (mr *MockDockerClientMockRecorder) UpdateContainerResources(ctx, id, cpuQuota, memory interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContainerResources", reflect.TypeOf((*MockDockerClient)(nil).UpdateContainerResources), ctx, id, cpuQuota, memory)
+}
+
 // WaitContainerExit mocks base method.
 func (m *MockDockerClient) WaitContainerExit(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -634,6 +778,21 @@ func (mr *MockIPAuthenticatorMockRecorder) FindAgentFromRemoteAddr(hostPort inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAgentFromRemoteAddr", reflect.TypeOf((*MockIPAuthenticator)(nil).FindAgentFromRemoteAddr), hostPort)
 }
 
+// FindAgentFromRequest mocks base method.
+func (m *MockIPAuthenticator) FindAgentFromRequest(req *http.Request) (*config.AgentConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAgentFromRequest", req)
+	ret0, _ := ret[0].(*config.AgentConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAgentFromRequest indicates an expected call of FindAgentFromRequest.
+func (mr *MockIPAuthenticatorMockRecorder) FindAgentFromRequest(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAgentFromRequest", reflect.TypeOf((*MockIPAuthenticator)(nil).FindAgentFromRequest), req)
+}
+
 // FindContainerNameFromRemoteAddr mocks base method.
 func (m *MockIPAuthenticator) FindContainerNameFromRemoteAddr(ctx context.Context, hostPort string) (string, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,133 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/utils/httpclient"
+	"github.com/forta-network/forta-node/config"
+)
+
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
+// Notification is an operator notification fired by an alerting rule.
+type Notification struct {
+	Rule      string    `json:"rule"`
+	Firing    bool      `json:"firing"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Text returns the notification as a single line of text.
+func (n *Notification) Text() string {
+	state := "FIRING"
+	if !n.Firing {
+		state = "RESOLVED"
+	}
+	return fmt.Sprintf("[forta-node] %s %s: %s", state, n.Rule, n.Message)
+}
+
+// Notifier sends the notifications to the operator.
+type Notifier interface {
+	Notify(ctx context.Context, notification *Notification) error
+	Name() string
+}
+
+// FromConfig creates the notifiers which are configured.
+func FromConfig(cfg config.AlertingConfig) []Notifier {
+	var notifiers []Notifier
+	if cfg.Webhook != nil {
+		notifiers = append(notifiers, &webhook{cfg: *cfg.Webhook})
+	}
+	if cfg.Email != nil {
+		notifiers = append(notifiers, &email{cfg: *cfg.Email})
+	}
+	if cfg.Telegram != nil {
+		notifiers = append(notifiers, &telegram{cfg: *cfg.Telegram, apiURL: defaultTelegramAPIURL})
+	}
+	return notifiers
+}
+
+type webhook struct {
+	cfg config.WebhookNotifierConfig
+}
+
+func (wh *webhook) Notify(ctx context.Context, notification *Notification) error {
+	return postJSON(ctx, wh.cfg.URL, wh.cfg.Headers, notification)
+}
+
+func (wh *webhook) Name() string {
+	return "webhook"
+}
+
+type email struct {
+	cfg config.EmailNotifierConfig
+}
+
+func (e *email) Notify(ctx context.Context, notification *Notification) error {
+	var auth smtp.Auth
+	if len(e.cfg.Username) > 0 {
+		host := strings.Split(e.cfg.SMTPAddr, ":")[0]
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, host)
+	}
+	msg := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n",
+		e.cfg.From, strings.Join(e.cfg.To, ", "), notification.Text(), notification.Message,
+	)
+	if err := smtp.SendMail(e.cfg.SMTPAddr, auth, e.cfg.From, e.cfg.To, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send the email: %v", err)
+	}
+	return nil
+}
+
+func (e *email) Name() string {
+	return "email"
+}
+
+type telegram struct {
+	cfg    config.TelegramNotifierConfig
+	apiURL string
+}
+
+func (tg *telegram) Notify(ctx context.Context, notification *Notification) error {
+	return postJSON(ctx, fmt.Sprintf("%s/bot%s/sendMessage", tg.apiURL, tg.cfg.BotToken), nil, map[string]string{
+		"chat_id": tg.cfg.ChatID,
+		"text":    notification.Text(),
+	})
+}
+
+func (tg *telegram) Name() string {
+	return "telegram"
+}
+
+func postJSON(ctx context.Context, url string, headers map[string]string, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := httpclient.Default.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%d error: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
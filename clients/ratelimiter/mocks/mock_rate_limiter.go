@@ -46,3 +46,41 @@ func (mr *MockRateLimiterMockRecorder) ExceedsLimit(clientID interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExceedsLimit", reflect.TypeOf((*MockRateLimiter)(nil).ExceedsLimit), clientID)
 }
+
+// ExceedsLimitN mocks base method.
+func (m *MockRateLimiter) ExceedsLimitN(clientID string, n int) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExceedsLimitN", clientID, n)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ExceedsLimitN indicates an expected call of ExceedsLimitN.
+func (mr *MockRateLimiterMockRecorder) ExceedsLimitN(clientID, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExceedsLimitN", reflect.TypeOf((*MockRateLimiter)(nil).ExceedsLimitN), clientID, n)
+}
+
+// SetClientLimit mocks base method.
+func (m *MockRateLimiter) SetClientLimit(clientID string, rateN float64, burst int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetClientLimit", clientID, rateN, burst)
+}
+
+// SetClientLimit indicates an expected call of SetClientLimit.
+func (mr *MockRateLimiterMockRecorder) SetClientLimit(clientID, rateN, burst interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetClientLimit", reflect.TypeOf((*MockRateLimiter)(nil).SetClientLimit), clientID, rateN, burst)
+}
+
+// SetDefaultLimit mocks base method.
+func (m *MockRateLimiter) SetDefaultLimit(rateN float64, burst int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDefaultLimit", rateN, burst)
+}
+
+// SetDefaultLimit indicates an expected call of SetDefaultLimit.
+func (mr *MockRateLimiterMockRecorder) SetDefaultLimit(rateN, burst interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultLimit", reflect.TypeOf((*MockRateLimiter)(nil).SetDefaultLimit), rateN, burst)
+}
@@ -10,6 +10,9 @@ import (
 
 type RateLimiter interface {
 	ExceedsLimit(clientID string) bool
+	ExceedsLimitN(clientID string, n int) bool
+	SetClientLimit(clientID string, rateN float64, burst int)
+	SetDefaultLimit(rateN float64, burst int)
 }
 
 // rateLimiter rate limits requests.
@@ -17,6 +20,7 @@ type rateLimiter struct {
 	rate           float64
 	burst          int
 	clientLimiters map[string]*clientLimiter
+	clientLimits   map[string]clientLimit
 	mu             sync.Mutex
 }
 
@@ -27,6 +31,12 @@ type clientLimiter struct {
 	*rate.Limiter
 }
 
+type clientLimit struct {
+	rate      float64
+	burst     int
+	updatedAt time.Time
+}
+
 // NewRateLimiter creates a new rate limiter.
 func NewRateLimiter(rateN float64, burst int) *rateLimiter {
 	if rateN <= 0 {
@@ -36,6 +46,7 @@ func NewRateLimiter(rateN float64, burst int) *rateLimiter {
 		rate:           rateN,
 		burst:          burst,
 		clientLimiters: make(map[string]*clientLimiter),
+		clientLimits:   make(map[string]clientLimit),
 	}
 	go rl.autoCleanup()
 	return rl
@@ -44,15 +55,63 @@ func NewRateLimiter(rateN float64, burst int) *rateLimiter {
 // ExceedsLimit tries adding a request to the limiting channel and returns boolean to signal
 // if we hit the rate limit.
 func (rl *rateLimiter) ExceedsLimit(clientID string) bool {
+	return rl.ExceedsLimitN(clientID, 1)
+}
+
+// ExceedsLimitN is like ExceedsLimit but consumes n tokens at once. This is useful
+// for weighting the expensive requests higher. A cost larger than the burst consumes
+// the whole burst since it could never be allowed otherwise.
+func (rl *rateLimiter) ExceedsLimitN(clientID string, n int) bool {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 	limiter := rl.clientLimiters[clientID]
 	if limiter == nil {
-		limiter = &clientLimiter{Limiter: rate.NewLimiter(rate.Limit(rl.rate), rl.burst)}
+		limit, ok := rl.clientLimits[clientID]
+		if !ok {
+			limit = clientLimit{rate: rl.rate, burst: rl.burst}
+		}
+		limiter = &clientLimiter{Limiter: rate.NewLimiter(rate.Limit(limit.rate), limit.burst)}
 		rl.clientLimiters[clientID] = limiter
 	}
+	if burst := limiter.Burst(); n > burst {
+		n = burst
+	}
 	limiter.lastReservation = time.Now()
-	return !limiter.Allow()
+	return !limiter.AllowN(limiter.lastReservation, n)
+}
+
+// SetClientLimit overrides the default rate and burst for a client.
+func (rl *rateLimiter) SetClientLimit(clientID string, rateN float64, burst int) {
+	if rateN <= 0 {
+		log.WithField("client", clientID).Warn("ignoring non-positive client rate limit")
+		return
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	limit, ok := rl.clientLimits[clientID]
+	rl.clientLimits[clientID] = clientLimit{rate: rateN, burst: burst, updatedAt: time.Now()}
+	if ok && limit.rate == rateN && limit.burst == burst {
+		return
+	}
+	if limiter := rl.clientLimiters[clientID]; limiter != nil {
+		limiter.SetLimit(rate.Limit(rateN))
+		limiter.SetBurst(burst)
+	}
+}
+
+// SetDefaultLimit changes the default rate and burst. The client overrides are cleared
+// so that they can be set again from the latest config.
+func (rl *rateLimiter) SetDefaultLimit(rateN float64, burst int) {
+	if rateN <= 0 {
+		log.Warn("ignoring non-positive default rate limit")
+		return
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.rate = rateN
+	rl.burst = burst
+	rl.clientLimits = make(map[string]clientLimit)
+	rl.clientLimiters = make(map[string]*clientLimiter)
 }
 
 // deallocate inactive limiters
@@ -70,5 +129,11 @@ func (rl *rateLimiter) doCleanup() {
 			delete(rl.clientLimiters, clientID)
 		}
 	}
+	// the client limits go away with the limiters unless they were set recently
+	for clientID, limit := range rl.clientLimits {
+		if rl.clientLimiters[clientID] == nil && time.Since(limit.updatedAt) > time.Minute*10 {
+			delete(rl.clientLimits, clientID)
+		}
+	}
 	rl.mu.Unlock()
 }
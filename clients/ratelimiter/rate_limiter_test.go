@@ -15,6 +15,7 @@ func TestRateLimiting(t *testing.T) {
 		rate:           0.5,
 		burst:          1,
 		clientLimiters: make(map[string]*clientLimiter),
+		clientLimits:   make(map[string]clientLimit),
 	} // replenish every 2s (1/0.5)
 	reachedLimit := rateLimiter.ExceedsLimit(testClientID)
 	r.False(reachedLimit)
@@ -28,3 +29,50 @@ func TestRateLimiting(t *testing.T) {
 	rateLimiter.doCleanup()
 	r.Len(rateLimiter.clientLimiters, 1)
 }
+
+func TestRateLimiting_CostAndClientLimit(t *testing.T) {
+	r := require.New(t)
+	rateLimiter := &rateLimiter{
+		rate:           0.1,
+		burst:          5,
+		clientLimiters: make(map[string]*clientLimiter),
+		clientLimits:   make(map[string]clientLimit),
+	}
+
+	r.False(rateLimiter.ExceedsLimitN(testClientID, 4))
+	r.True(rateLimiter.ExceedsLimitN(testClientID, 4))
+	r.False(rateLimiter.ExceedsLimitN(testClientID, 1))
+
+	rateLimiter.SetClientLimit("2", 0.1, 10)
+	r.False(rateLimiter.ExceedsLimitN("2", 10))
+	r.True(rateLimiter.ExceedsLimit("2"))
+
+	// the cost is capped at the burst
+	r.False(rateLimiter.ExceedsLimitN("3", 100))
+	r.True(rateLimiter.ExceedsLimit("3"))
+}
+
+func TestRateLimiting_Cleanup(t *testing.T) {
+	r := require.New(t)
+	rateLimiter := &rateLimiter{
+		rate:           1,
+		burst:          1,
+		clientLimiters: make(map[string]*clientLimiter),
+		clientLimits:   make(map[string]clientLimit),
+	}
+
+	rateLimiter.SetClientLimit("active", 2, 2)
+	rateLimiter.SetClientLimit("inactive", 2, 2)
+	rateLimiter.SetClientLimit("idle", 2, 2)
+	r.False(rateLimiter.ExceedsLimit("active"))
+	r.False(rateLimiter.ExceedsLimit("inactive"))
+	rateLimiter.clientLimiters["inactive"].lastReservation = time.Now().Add(-time.Hour)
+	rateLimiter.clientLimits["inactive"] = clientLimit{rate: 2, burst: 2, updatedAt: time.Now().Add(-time.Hour)}
+
+	rateLimiter.doCleanup()
+	r.Len(rateLimiter.clientLimiters, 1)
+	r.Contains(rateLimiter.clientLimits, "active")
+	r.NotContains(rateLimiter.clientLimits, "inactive")
+	// the limits which are set recently are kept until the client shows up
+	r.Contains(rateLimiter.clientLimits, "idle")
+}
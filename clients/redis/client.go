@@ -11,6 +11,8 @@ import (
 
 type Client interface {
 	SetNX(key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Get(key string) *redis.StringCmd
 	Ping() *redis.StatusCmd
 }
 
@@ -0,0 +1,115 @@
+package signer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/encoding"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+)
+
+// The functions below produce the same signatures as the ones from the security package
+// of forta-core-go so that the receivers can keep verifying them in the same way.
+
+// SignBytes signs the Keccak256 hash of the bytes.
+func SignBytes(signer Signer, b []byte) (*protocol.Signature, error) {
+	sig, err := signer.SignHash(crypto.Keccak256(b))
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.Signature{
+		Signature: fmt.Sprintf("0x%s", hex.EncodeToString(sig)),
+		Algorithm: "ECDSA",
+		Signer:    signer.Address().Hex(),
+	}, nil
+}
+
+func alertHash(alert *protocol.Alert) common.Hash {
+	metadata := utils.MapToList(alert.Metadata)
+	alertStr := fmt.Sprintf("%s%s%s", alert.Id, strings.Join(metadata, ""), alert.Timestamp)
+	return crypto.Keccak256Hash([]byte(alertStr))
+}
+
+// SignAlert signs the alert using the alert ID, the metadata and the timestamp.
+func SignAlert(signer Signer, alert *protocol.Alert) (*protocol.SignedAlert, error) {
+	signature, err := SignBytes(signer, alertHash(alert).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.SignedAlert{
+		Alert:     alert,
+		Signature: signature,
+	}, nil
+}
+
+func signPayload(signer Signer, payloadType protocol.SignedPayload_PayloadType, msg proto.Message) (*protocol.SignedPayload, error) {
+	encoded, err := encoding.EncodeGzippedProto(msg)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := SignBytes(signer, []byte(encoded))
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.SignedPayload{
+		Type:      payloadType,
+		Encoded:   encoded,
+		Signature: signature,
+	}, nil
+}
+
+// SignBatch signs an alert batch.
+func SignBatch(signer Signer, payload *protocol.AlertBatch) (*protocol.SignedPayload, error) {
+	return signPayload(signer, protocol.SignedPayload_BATCH, payload)
+}
+
+// SignBatchSummary signs an alert batch summary.
+func SignBatchSummary(signer Signer, payload *protocol.BatchSummary) (*protocol.SignedPayload, error) {
+	return signPayload(signer, protocol.SignedPayload_BATCH_SUMMARY, payload)
+}
+
+// signingMethod is the same as the "ETH" method which is registered by the security package
+// but it signs with a signer instead of a private key.
+type signingMethod struct{}
+
+// Verify is not supported: the tokens are verified by using the security package.
+func (sm signingMethod) Verify(signingString, signature string, key interface{}) error {
+	return jwt.ErrSignatureInvalid
+}
+
+func (sm signingMethod) Sign(signingString string, key interface{}) (string, error) {
+	sig, err := key.(Signer).SignHash(crypto.Keccak256([]byte(signingString)))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func (sm signingMethod) Alg() string {
+	return "ETH"
+}
+
+// CreateScannerJWT creates a short-lived token signed by the scanner.
+func CreateScannerJWT(signer Signer, claims map[string]interface{}) (string, error) {
+	now := time.Now().UTC()
+	mapClaims := map[string]interface{}{
+		"jti": uuid.Must(uuid.NewUUID()).String(),
+		"sub": signer.Address().Hex(),
+		"iat": now.Unix(),
+		"nbf": now.Add(-30 * time.Second).Unix(),
+		"exp": now.Add(30 * time.Second).Unix(),
+	}
+	for k, v := range claims {
+		mapClaims[k] = v
+	}
+	return jwt.NewWithClaims(signingMethod{}, jwt.MapClaims(mapClaims)).SignedString(signer)
+}
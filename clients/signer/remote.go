@@ -0,0 +1,126 @@
+package signer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Remote signer API paths
+const (
+	PathAddress = "/address"
+	PathSign    = "/sign"
+)
+
+const defaultRemoteSignerTimeout = time.Second * 10
+
+// AddressResponse is the response of the remote signer to an address request.
+type AddressResponse struct {
+	Address string `json:"address"`
+}
+
+// SignRequest asks the remote signer to sign the hash with the key of the address.
+type SignRequest struct {
+	Address string `json:"address"`
+	Hash    string `json:"hash"`
+}
+
+// SignResponse contains the [R || S || V] signature from the remote signer.
+type SignResponse struct {
+	Signature string `json:"signature"`
+}
+
+type remoteSigner struct {
+	url        string
+	authToken  string
+	address    common.Address
+	httpClient *http.Client
+}
+
+// NewRemoteSigner creates a signer which asks a signing service (e.g. one in front of a cloud
+// KMS) to sign the hashes. The service decides which key to use based on the auth token.
+func NewRemoteSigner(url, authToken string) (Signer, error) {
+	rs := &remoteSigner{
+		url:        strings.TrimSuffix(url, "/"),
+		authToken:  authToken,
+		httpClient: &http.Client{Timeout: defaultRemoteSignerTimeout},
+	}
+	var resp AddressResponse
+	if err := rs.do(http.MethodGet, PathAddress, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get the remote signer address: %v", err)
+	}
+	if !common.IsHexAddress(resp.Address) {
+		return nil, fmt.Errorf("invalid remote signer address: %s", resp.Address)
+	}
+	rs.address = common.HexToAddress(resp.Address)
+	return rs, nil
+}
+
+// Address returns the address of the remote key.
+func (rs *remoteSigner) Address() common.Address {
+	return rs.address
+}
+
+// SignHash sends the hash to the remote signer.
+func (rs *remoteSigner) SignHash(hash []byte) ([]byte, error) {
+	var resp SignResponse
+	err := rs.do(http.MethodPost, PathSign, &SignRequest{
+		Address: rs.address.Hex(),
+		Hash:    hexutil.Encode(hash),
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer failed: %v", err)
+	}
+	sig, err := hexutil.Decode(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote signature: %v", err)
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("invalid remote signature length: %d", len(sig))
+	}
+	// the signing services usually return the Ethereum style V
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	if sig[64] > 1 {
+		return nil, errors.New("invalid remote signature recovery id")
+	}
+	return sig, nil
+}
+
+func (rs *remoteSigner) do(method, path string, reqBody, respBody interface{}) error {
+	var body io.Reader
+	if reqBody != nil {
+		b, err := json.Marshal(reqBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, rs.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(rs.authToken) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", rs.authToken))
+	}
+	resp, err := rs.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(b))
+	}
+	return json.NewDecoder(resp.Body).Decode(respBody)
+}
@@ -0,0 +1,33 @@
+package signer
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer signs the messages of the scanner so that the callers do not need to hold the private key.
+type Signer interface {
+	Address() common.Address
+	// SignHash returns the [R || S || V] signature of the hash where V is 0 or 1.
+	SignHash(hash []byte) ([]byte, error)
+}
+
+type keySigner struct {
+	key *keystore.Key
+}
+
+// NewKeySigner creates a new signer which signs with the decrypted key.
+func NewKeySigner(key *keystore.Key) Signer {
+	return &keySigner{key: key}
+}
+
+// Address returns the address of the key.
+func (ks *keySigner) Address() common.Address {
+	return ks.key.Address
+}
+
+// SignHash signs the hash with the private key.
+func (ks *keySigner) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, ks.key.PrivateKey)
+}
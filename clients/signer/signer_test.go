@@ -0,0 +1,84 @@
+package signer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(t *testing.T) *keystore.Key {
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return &keystore.Key{
+		PrivateKey: privKey,
+		Address:    crypto.PubkeyToAddress(privKey.PublicKey),
+	}
+}
+
+func TestKeySigner(t *testing.T) {
+	r := require.New(t)
+
+	key := testKey(t)
+	signer := NewKeySigner(key)
+	alert := &protocol.Alert{Id: "1", Timestamp: "2023-01-01T00:00:00Z", Metadata: map[string]string{"a": "b"}}
+
+	// same as the security package
+	signedAlert, err := SignAlert(signer, alert)
+	r.NoError(err)
+	expected, err := security.SignAlert(key, alert)
+	r.NoError(err)
+	r.Equal(expected.Signature, signedAlert.Signature)
+	r.NoError(security.VerifyAlertSignature(signedAlert))
+
+	signedBatch, err := SignBatch(signer, &protocol.AlertBatch{BlockStart: 1})
+	r.NoError(err)
+	r.NoError(security.VerifySignedPayload(signedBatch))
+
+	token, err := CreateScannerJWT(signer, map[string]interface{}{"access": "test"})
+	r.NoError(err)
+	scannerToken, err := security.VerifyScannerJWT(token)
+	r.NoError(err)
+	r.Equal(key.Address.Hex(), scannerToken.Scanner)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	r := require.New(t)
+
+	key := testKey(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch req.URL.Path {
+		case PathAddress:
+			json.NewEncoder(w).Encode(&AddressResponse{Address: key.Address.Hex()})
+		case PathSign:
+			var signReq SignRequest
+			json.NewDecoder(req.Body).Decode(&signReq)
+			sig, _ := crypto.Sign(hexutil.MustDecode(signReq.Hash), key.PrivateKey)
+			sig[64] += 27
+			json.NewEncoder(w).Encode(&SignResponse{Signature: hexutil.Encode(sig)})
+		}
+	}))
+	defer server.Close()
+
+	_, err := NewRemoteSigner(server.URL, "bad-token")
+	r.Error(err)
+
+	signer, err := NewRemoteSigner(server.URL+"/", "token")
+	r.NoError(err)
+	r.Equal(key.Address, signer.Address())
+
+	signedAlert, err := SignAlert(signer, &protocol.Alert{Id: "1"})
+	r.NoError(err)
+	r.NoError(security.VerifyAlertSignature(signedAlert))
+}
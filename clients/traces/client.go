@@ -0,0 +1,200 @@
+package traces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Trace providers
+const (
+	ProviderAuto   = "auto"
+	ProviderParity = "parity"
+	ProviderGeth   = "geth"
+)
+
+var errNotSupported = errors.New("trace api is not supported")
+
+type rpcCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+type provider interface {
+	Name() string
+	TraceBlock(ctx context.Context, number *big.Int) ([]domain.Trace, error)
+}
+
+// Client gets the block traces from the configured trace API or the first one which the
+// API supports in auto mode. The blocks are scanned without the traces if no trace API is
+// supported. The traces of the recent blocks are cached.
+type Client struct {
+	ethereum.Client
+	rpcClient *rpc.Client
+
+	providers []provider
+	active    provider
+	disabled  bool
+
+	cacheSize int
+	cache     map[string][]domain.Trace
+	cacheKeys []string
+
+	lastErr health.ErrorTracker
+	mu      sync.Mutex
+}
+
+// NewClient wraps the trace client.
+func NewClient(ctx context.Context, traceClient ethereum.Client, url string, cfg config.TraceConfig) (*Client, error) {
+	rpcClient, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial the trace api: %v", err)
+	}
+	for k, v := range cfg.JsonRpc.Headers {
+		rpcClient.SetHeader(k, v)
+	}
+	client := newClient(traceClient, rpcClient, cfg)
+	client.rpcClient = rpcClient
+	return client, nil
+}
+
+func newClient(traceClient ethereum.Client, caller rpcCaller, cfg config.TraceConfig) *Client {
+	parity := &parityProvider{client: traceClient}
+	geth := &gethProvider{
+		client:  traceClient,
+		rpc:     caller,
+		tracer:  cfg.Tracer,
+		timeout: cfg.TracerTimeoutSeconds,
+	}
+	client := &Client{
+		Client:    traceClient,
+		cacheSize: cfg.CacheSize,
+		cache:     make(map[string][]domain.Trace),
+	}
+	switch cfg.Provider {
+	case ProviderParity:
+		client.providers = []provider{parity}
+	case ProviderGeth:
+		client.providers = []provider{geth}
+	default:
+		client.providers = []provider{parity, geth}
+	}
+	return client
+}
+
+// Close closes the clients.
+func (c *Client) Close() {
+	c.Client.Close()
+	if c.rpcClient != nil {
+		c.rpcClient.Close()
+	}
+}
+
+// TraceBlock returns the traces of the block in the trace_block format.
+func (c *Client) TraceBlock(ctx context.Context, number *big.Int) ([]domain.Trace, error) {
+	key := number.String()
+	c.mu.Lock()
+	traces, ok := c.cache[key]
+	active := c.active
+	disabled := c.disabled
+	c.mu.Unlock()
+	if ok {
+		return traces, nil
+	}
+	if disabled {
+		return nil, nil
+	}
+
+	if active != nil {
+		traces, err := active.TraceBlock(ctx, number)
+		c.lastErr.Set(err)
+		if err != nil {
+			return nil, err
+		}
+		c.put(key, traces)
+		return traces, nil
+	}
+
+	for _, p := range c.providers {
+		traces, err := p.TraceBlock(ctx, number)
+		if errors.Is(err, errNotSupported) {
+			log.WithError(err).WithField("provider", p.Name()).Info("trace provider is not supported by the api")
+			continue
+		}
+		c.lastErr.Set(err)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.active = p
+		c.mu.Unlock()
+		log.WithField("provider", p.Name()).Info("using trace provider")
+		c.put(key, traces)
+		return traces, nil
+	}
+
+	// continue scanning without the traces
+	log.Warn("no trace provider is supported by the api - scanning the blocks without the traces")
+	c.mu.Lock()
+	c.disabled = true
+	c.mu.Unlock()
+	return nil, nil
+}
+
+func (c *Client) put(key string, traces []domain.Trace) {
+	if c.cacheSize <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.cache[key]; !ok {
+		c.cacheKeys = append(c.cacheKeys, key)
+	}
+	c.cache[key] = traces
+	for len(c.cacheKeys) > c.cacheSize {
+		delete(c.cache, c.cacheKeys[0])
+		c.cacheKeys = c.cacheKeys[1:]
+	}
+}
+
+// Health implements the health.Reporter interface.
+func (c *Client) Health() health.Reports {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	provider := &health.Report{
+		Name:    "trace.provider",
+		Status:  health.StatusInfo,
+		Details: "detecting",
+	}
+	switch {
+	case c.disabled:
+		provider.Status = health.StatusFailing
+		provider.Details = "unsupported"
+	case c.active != nil:
+		provider.Details = c.active.Name()
+	}
+	return append(c.Client.Health(), provider, c.lastErr.GetReport("trace.provider.error"))
+}
+
+// notSupported wraps the errors of the missing API methods.
+func notSupported(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"method not found", "does not exist", "not available", "not supported", "unsupported"} {
+		if strings.Contains(msg, s) {
+			return fmt.Errorf("%w: %v", errNotSupported, err)
+		}
+	}
+	return err
+}
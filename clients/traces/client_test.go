@@ -0,0 +1,97 @@
+package traces
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testCallTrace = `[{"result":{
+	"type":"CALL","from":"0xa","to":"0xb","value":"0x1","gas":"0x100","gasUsed":"0x50","input":"0x12","output":"0x",
+	"calls":[
+		{"type":"DELEGATECALL","from":"0xb","to":"0xc","gas":"0x80","gasUsed":"0x10","input":"0x34","output":"0x56"},
+		{"type":"CREATE2","from":"0xb","to":"0xd","value":"0x0","gas":"0x70","gasUsed":"0x20","input":"0x60","output":"0x61","error":"out of gas"}
+	]
+}}]`
+
+type testRPCCaller struct {
+	method string
+	result string
+	err    error
+}
+
+func (c *testRPCCaller) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.method = method
+	if c.err != nil {
+		return c.err
+	}
+	return json.Unmarshal([]byte(c.result), result)
+}
+
+func TestClient_GethFallback(t *testing.T) {
+	r := require.New(t)
+
+	ethClient := mock_ethereum.NewMockClient(gomock.NewController(t))
+	caller := &testRPCCaller{result: testCallTrace}
+	client := newClient(ethClient, caller, config.TraceConfig{Tracer: tracerCall, CacheSize: 1})
+
+	block := &domain.Block{Hash: "0xblock", Number: "0x1", Transactions: []domain.Transaction{{Hash: "0xtx"}}}
+	ethClient.EXPECT().TraceBlock(gomock.Any(), big.NewInt(1)).Return(nil, errors.New("the method trace_block does not exist/is not available"))
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), big.NewInt(1)).Return(block, nil)
+
+	traces, err := client.TraceBlock(context.Background(), big.NewInt(1))
+	r.NoError(err)
+	r.Equal("debug_traceBlockByHash", caller.method)
+	r.Equal(ProviderGeth, client.active.Name())
+	r.Len(traces, 3)
+
+	r.Equal("call", traces[0].Type)
+	r.Equal("call", *traces[0].Action.CallType)
+	r.Equal(2, traces[0].Subtraces)
+	r.Equal([]int{}, traces[0].TraceAddress)
+	r.Equal("0xblock", *traces[0].BlockHash)
+	r.Equal("0xtx", *traces[0].TransactionHash)
+
+	r.Equal("delegatecall", *traces[1].Action.CallType)
+	r.Equal("0x0", *traces[1].Action.Value)
+	r.Equal([]int{0}, traces[1].TraceAddress)
+
+	r.Equal("create", traces[2].Type)
+	r.Equal("0x60", *traces[2].Action.Init)
+	r.Equal("out of gas", *traces[2].Error)
+	r.Nil(traces[2].Result)
+	r.Equal([]int{1}, traces[2].TraceAddress)
+
+	// cached
+	traces, err = client.TraceBlock(context.Background(), big.NewInt(1))
+	r.NoError(err)
+	r.Len(traces, 3)
+}
+
+func TestClient_Unsupported(t *testing.T) {
+	r := require.New(t)
+
+	ethClient := mock_ethereum.NewMockClient(gomock.NewController(t))
+	caller := &testRPCCaller{err: errors.New("method not found")}
+	client := newClient(ethClient, caller, config.TraceConfig{})
+
+	ethClient.EXPECT().TraceBlock(gomock.Any(), gomock.Any()).Return(nil, errors.New("method not found"))
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).Return(&domain.Block{Hash: "0xblock"}, nil)
+
+	// the blocks are scanned without the traces
+	traces, err := client.TraceBlock(context.Background(), big.NewInt(1))
+	r.NoError(err)
+	r.Nil(traces)
+	traces, err = client.TraceBlock(context.Background(), big.NewInt(2))
+	r.NoError(err)
+	r.Nil(traces)
+	r.True(client.disabled)
+}
@@ -0,0 +1,203 @@
+package traces
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/ethereum"
+)
+
+const (
+	tracerCall     = "callTracer"
+	tracerFlatCall = "flatCallTracer"
+)
+
+// parityProvider uses trace_block.
+type parityProvider struct {
+	client ethereum.Client
+}
+
+func (p *parityProvider) Name() string {
+	return ProviderParity
+}
+
+func (p *parityProvider) TraceBlock(ctx context.Context, number *big.Int) ([]domain.Trace, error) {
+	traces, err := p.client.TraceBlock(ctx, number)
+	return traces, notSupported(err)
+}
+
+// gethProvider uses debug_traceBlockByHash and converts the results to the trace_block format.
+type gethProvider struct {
+	client  ethereum.Client
+	rpc     rpcCaller
+	tracer  string
+	timeout int
+}
+
+type gethTxTraceResult struct {
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// gethCallFrame is a frame of the callTracer result.
+type gethCallFrame struct {
+	Type    string          `json:"type"`
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Value   *string         `json:"value"`
+	Gas     string          `json:"gas"`
+	GasUsed string          `json:"gasUsed"`
+	Input   string          `json:"input"`
+	Output  string          `json:"output"`
+	Error   string          `json:"error"`
+	Calls   []gethCallFrame `json:"calls"`
+}
+
+func (p *gethProvider) Name() string {
+	return ProviderGeth
+}
+
+func (p *gethProvider) TraceBlock(ctx context.Context, number *big.Int) ([]domain.Trace, error) {
+	// the tx hashes are not in the results
+	block, err := p.client.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the block: %v", err)
+	}
+
+	tracer := p.tracer
+	if len(tracer) == 0 {
+		tracer = tracerCall
+	}
+	tracerCfg := map[string]interface{}{"tracer": tracer}
+	if p.timeout > 0 {
+		tracerCfg["timeout"] = fmt.Sprintf("%ds", p.timeout)
+	}
+	var results []*gethTxTraceResult
+	if err := p.rpc.CallContext(ctx, &results, "debug_traceBlockByHash", block.Hash, tracerCfg); err != nil {
+		return nil, notSupported(err)
+	}
+	if len(results) != len(block.Transactions) {
+		return nil, fmt.Errorf("got %d tx traces for %d txs", len(results), len(block.Transactions))
+	}
+
+	blockNumber := int(number.Int64())
+	var traces []domain.Trace
+	for i, result := range results {
+		if len(result.Error) > 0 {
+			return nil, fmt.Errorf("failed to trace tx %s: %s", block.Transactions[i].Hash, result.Error)
+		}
+		txCtx := &txContext{
+			blockHash:   block.Hash,
+			blockNumber: blockNumber,
+			txHash:      block.Transactions[i].Hash,
+			txPosition:  i,
+		}
+		switch tracer {
+		case tracerFlatCall:
+			var txTraces []domain.Trace
+			if err := json.Unmarshal(result.Result, &txTraces); err != nil {
+				return nil, fmt.Errorf("failed to decode the flat call traces: %v", err)
+			}
+			for _, trace := range txTraces {
+				traces = append(traces, txCtx.fill(trace))
+			}
+
+		default:
+			var frame gethCallFrame
+			if err := json.Unmarshal(result.Result, &frame); err != nil {
+				return nil, fmt.Errorf("failed to decode the call traces: %v", err)
+			}
+			traces = flattenCallFrame(traces, txCtx, &frame, []int{})
+		}
+	}
+	return traces, nil
+}
+
+type txContext struct {
+	blockHash   string
+	blockNumber int
+	txHash      string
+	txPosition  int
+}
+
+func (txCtx *txContext) fill(trace domain.Trace) domain.Trace {
+	trace.BlockHash = &txCtx.blockHash
+	trace.BlockNumber = &txCtx.blockNumber
+	trace.TransactionHash = &txCtx.txHash
+	trace.TransactionPosition = &txCtx.txPosition
+	if trace.TraceAddress == nil {
+		trace.TraceAddress = []int{}
+	}
+	return trace
+}
+
+// flattenCallFrame converts the call frames to the trace_block format in depth-first order.
+func flattenCallFrame(traces []domain.Trace, txCtx *txContext, frame *gethCallFrame, traceAddress []int) []domain.Trace {
+	trace := domain.Trace{
+		Subtraces:    len(frame.Calls),
+		TraceAddress: traceAddress,
+	}
+	value := frame.Value
+	if value == nil {
+		zero := "0x0"
+		value = &zero
+	}
+	frameType := strings.ToLower(frame.Type)
+	switch frameType {
+	case "create", "create2":
+		trace.Type = "create"
+		trace.Action = domain.TraceAction{
+			From:  strPtr(frame.From),
+			Gas:   strPtr(frame.Gas),
+			Value: value,
+			Init:  strPtr(frame.Input),
+		}
+		trace.Result = &domain.TraceResult{
+			GasUsed: strPtr(frame.GasUsed),
+			Address: strPtr(frame.To),
+			Code:    strPtr(frame.Output),
+		}
+
+	case "selfdestruct":
+		trace.Type = "suicide"
+		trace.Action = domain.TraceAction{
+			Address:       strPtr(frame.From),
+			RefundAddress: strPtr(frame.To),
+			Balance:       value,
+		}
+
+	default:
+		trace.Type = "call"
+		trace.Action = domain.TraceAction{
+			CallType: strPtr(frameType),
+			From:     strPtr(frame.From),
+			To:       strPtr(frame.To),
+			Gas:      strPtr(frame.Gas),
+			Input:    strPtr(frame.Input),
+			Value:    value,
+		}
+		trace.Result = &domain.TraceResult{
+			GasUsed: strPtr(frame.GasUsed),
+			Output:  strPtr(frame.Output),
+		}
+	}
+	if len(frame.Error) > 0 {
+		trace.Error = strPtr(frame.Error)
+		trace.Result = nil
+	}
+
+	traces = append(traces, txCtx.fill(trace))
+	for i := range frame.Calls {
+		childAddress := append(append([]int{}, traceAddress...), i)
+		traces = flattenCallFrame(traces, txCtx, &frame.Calls[i], childAddress)
+	}
+	return traces
+}
+
+func strPtr(s string) *string {
+	return &s
+}
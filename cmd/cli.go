@@ -14,6 +14,7 @@ import (
 	"github.com/creasty/defaults"
 	"github.com/sirupsen/logrus"
 
+	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/config"
 	"gopkg.in/yaml.v3"
 
@@ -33,8 +34,10 @@ var (
 	cfg config.Config
 
 	parsedArgs struct {
-		Version uint64
-		NoCheck bool
+		Version       uint64
+		NoCheck       bool
+		NoConfigCheck bool
+		Dev           bool
 	}
 
 	cmdForta = &cobra.Command{
@@ -60,6 +63,20 @@ publishes alerts about them`,
 		RunE:  withInitialized(withValidConfig(handleFortaRun)),
 	}
 
+	cmdFortaConfig = &cobra.Command{
+		Use:   "config",
+		Short: "config management",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaConfigValidate = &cobra.Command{
+		Use:   "validate",
+		Short: "check the config and the environment and show the problems with hints",
+		RunE:  withInitialized(handleFortaConfigValidate),
+	}
+
 	cmdFortaAccount = &cobra.Command{
 		Use:   "account",
 		Short: "account management",
@@ -81,6 +98,18 @@ publishes alerts about them`,
 		Hidden: true,
 	}
 
+	cmdFortaAccountRotate = &cobra.Command{
+		Use:   "rotate",
+		Short: "create the next scanner key and switch to it with --complete after registering it",
+		RunE:  withInitialized(handleFortaAccountRotate),
+	}
+
+	cmdFortaAccountMigrate = &cobra.Command{
+		Use:   "migrate",
+		Short: "check that a remote signer signs as the scanner and show the config to use it",
+		RunE:  withInitialized(handleFortaAccountMigrate),
+	}
+
 	cmdFortaImages = &cobra.Command{
 		Use:   "images",
 		Short: "list the Forta node container images",
@@ -117,6 +146,24 @@ publishes alerts about them`,
 			if err != nil {
 				return err
 			}
+			asJSON, err := cmd.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return handleFortaStatusJSON(cmd)
+			}
+			history, err := cmd.Flags().GetBool("history")
+			if err != nil {
+				return err
+			}
+			if history {
+				since, err := cmd.Flags().GetString("since")
+				if err != nil {
+					return err
+				}
+				return handleFortaStatusHistory(cmd, format, since, noColor)
+			}
 			return handleFortaStatus(cmd, format, show, noColor)
 		},
 	}
@@ -133,6 +180,163 @@ publishes alerts about them`,
 		},
 	}
 
+	cmdFortaHealth = &cobra.Command{
+		Use:   "health",
+		Short: "check node liveness (or readiness) and exit with non-zero code on failure",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ready, err := cmd.Flags().GetBool("ready")
+			if err != nil {
+				return err
+			}
+			return handleFortaHealth(cmd, ready)
+		},
+	}
+
+	cmdFortaReplay = &cobra.Command{
+		Use:   "replay",
+		Short: "run a historical block or tx through a running bot and print the findings",
+		RunE:  handleFortaReplay,
+	}
+
+	cmdFortaRecordings = &cobra.Command{
+		Use:   "recordings",
+		Short: "replay the requests recorded from the bots",
+	}
+
+	cmdFortaRecordingsReplay = &cobra.Command{
+		Use:   "replay",
+		Short: "send the requests in a recording file to a bot image running locally and print the responses",
+		RunE:  handleFortaRecordingsReplay,
+	}
+
+	cmdFortaBackfill = &cobra.Command{
+		Use:   "backfill",
+		Short: "scan a historical block range through the running bots",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaBackfillStart = &cobra.Command{
+		Use:   "start",
+		Short: "start scanning a historical block range at a limited rate",
+		RunE:  handleFortaBackfillStart,
+	}
+
+	cmdFortaBackfillStatus = &cobra.Command{
+		Use:   "status",
+		Short: "show the progress of the backfill",
+		RunE:  handleFortaBackfillStatus,
+	}
+
+	cmdFortaBackfillCancel = &cobra.Command{
+		Use:   "cancel",
+		Short: "cancel the running backfill",
+		RunE:  handleFortaBackfillCancel,
+	}
+
+	cmdFortaDoctor = &cobra.Command{
+		Use:   "doctor",
+		Short: "check if this host is ready to run the node and write a report for support requests",
+		RunE:  withInitialized(handleFortaDoctor),
+	}
+
+	cmdFortaInstall = &cobra.Command{
+		Use:   "install",
+		Short: "install a systemd unit which runs the node",
+		RunE:  withInitialized(handleFortaInstall),
+	}
+
+	cmdFortaBots = &cobra.Command{
+		Use:   "bots",
+		Short: "manage the bots running on the node",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaBotsList = &cobra.Command{
+		Use:   "list",
+		Short: "list the running bots with their state and resource usage",
+		RunE:  handleFortaBotsList,
+	}
+
+	cmdFortaBotsInspect = &cobra.Command{
+		Use:   "inspect",
+		Short: "show the details, the errors and the stats of a running bot",
+		RunE:  handleFortaBotsInspect,
+	}
+
+	cmdFortaBotsRestart = &cobra.Command{
+		Use:   "restart",
+		Short: "restart a bot",
+		RunE:  handleFortaBotsRestart,
+	}
+
+	cmdFortaBotsStop = &cobra.Command{
+		Use:   "stop",
+		Short: "stop a bot until it is restarted or the node is restarted",
+		RunE:  handleFortaBotsStop,
+	}
+
+	cmdFortaBotsLogs = &cobra.Command{
+		Use:   "logs",
+		Short: "print the latest logs of a bot",
+		RunE:  handleFortaBotsLogs,
+	}
+
+	cmdFortaSnapshot = &cobra.Command{
+		Use:   "snapshot",
+		Short: "export or import a registry snapshot to run the node offline, or move the node state to a new host",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaSnapshotExport = &cobra.Command{
+		Use:   "export",
+		Short: "export the assigned bots, their manifests and images to a bundle dir",
+		RunE:  withInitialized(withValidConfig(handleFortaSnapshotExport)),
+	}
+
+	cmdFortaSnapshotImport = &cobra.Command{
+		Use:   "import",
+		Short: "load the images from a bundle dir and replace the snapshot used in snapshot mode",
+		RunE:  withInitialized(withValidConfig(handleFortaSnapshotImport)),
+	}
+
+	cmdFortaSnapshotCreate = &cobra.Command{
+		Use:   "create",
+		Short: "bundle the config, the keys, the cached manifests, the assignments and the local queues to move the node to a new host",
+		RunE:  withInitialized(handleFortaSnapshotCreate),
+	}
+
+	cmdFortaSnapshotRestore = &cobra.Command{
+		Use:   "restore",
+		Short: "restore the node state from a bundle created with 'forta snapshot create'",
+		RunE:  handleFortaSnapshotRestore,
+	}
+
+	cmdFortaAudit = &cobra.Command{
+		Use:   "audit",
+		Short: "verify or export the audit log of the bot lifecycle, admin and config reload actions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaAuditVerify = &cobra.Command{
+		Use:   "verify",
+		Short: "verify the hash chain of the audit log",
+		RunE:  withInitialized(handleFortaAuditVerify),
+	}
+
+	cmdFortaAuditExport = &cobra.Command{
+		Use:   "export",
+		Short: "verify the audit log and export all entries as json lines",
+		RunE:  withInitialized(handleFortaAuditExport),
+	}
+
 	cmdFortaAuthorize = &cobra.Command{
 		Use:   "authorize",
 		Short: "generate a signature for a specific action",
@@ -159,9 +363,14 @@ func init() {
 	cmdForta.AddCommand(cmdFortaInit)
 	cmdForta.AddCommand(cmdFortaRun)
 
+	cmdForta.AddCommand(cmdFortaConfig)
+	cmdFortaConfig.AddCommand(cmdFortaConfigValidate)
+
 	cmdForta.AddCommand(cmdFortaAccount)
 	cmdFortaAccount.AddCommand(cmdFortaAccountAddress)
 	cmdFortaAccount.AddCommand(cmdFortaAccountImport)
+	cmdFortaAccount.AddCommand(cmdFortaAccountRotate)
+	cmdFortaAccount.AddCommand(cmdFortaAccountMigrate)
 
 	cmdForta.AddCommand(cmdFortaImages)
 
@@ -172,6 +381,38 @@ func init() {
 	cmdForta.AddCommand(cmdFortaStatus)
 	cmdFortaStatus.AddCommand(cmdFortaStatusAll)
 
+	cmdForta.AddCommand(cmdFortaHealth)
+
+	cmdForta.AddCommand(cmdFortaReplay)
+	cmdForta.AddCommand(cmdFortaRecordings)
+	cmdFortaRecordings.AddCommand(cmdFortaRecordingsReplay)
+
+	cmdForta.AddCommand(cmdFortaBackfill)
+	cmdFortaBackfill.AddCommand(cmdFortaBackfillStart)
+	cmdFortaBackfill.AddCommand(cmdFortaBackfillStatus)
+	cmdFortaBackfill.AddCommand(cmdFortaBackfillCancel)
+
+	cmdForta.AddCommand(cmdFortaDoctor)
+
+	cmdForta.AddCommand(cmdFortaInstall)
+
+	cmdForta.AddCommand(cmdFortaBots)
+	cmdFortaBots.AddCommand(cmdFortaBotsList)
+	cmdFortaBots.AddCommand(cmdFortaBotsInspect)
+	cmdFortaBots.AddCommand(cmdFortaBotsRestart)
+	cmdFortaBots.AddCommand(cmdFortaBotsStop)
+	cmdFortaBots.AddCommand(cmdFortaBotsLogs)
+
+	cmdForta.AddCommand(cmdFortaSnapshot)
+	cmdFortaSnapshot.AddCommand(cmdFortaSnapshotExport)
+	cmdFortaSnapshot.AddCommand(cmdFortaSnapshotImport)
+	cmdFortaSnapshot.AddCommand(cmdFortaSnapshotCreate)
+	cmdFortaSnapshot.AddCommand(cmdFortaSnapshotRestore)
+
+	cmdForta.AddCommand(cmdFortaAudit)
+	cmdFortaAudit.AddCommand(cmdFortaAuditVerify)
+	cmdFortaAudit.AddCommand(cmdFortaAuditExport)
+
 	cmdForta.AddCommand(cmdFortaAuthorize)
 	cmdFortaAuthorize.AddCommand(cmdFortaAuthorizePool)
 
@@ -193,13 +434,101 @@ func init() {
 	cmdFortaAccountImport.Flags().String("file", "", "path to a file that contains a private key hex")
 	cmdFortaAccountImport.MarkFlagRequired("file")
 
+	// forta account rotate
+	cmdFortaAccountRotate.Flags().Bool("complete", false, "archive the current key and switch to the next key")
+	cmdFortaAccountRotate.Flags().Bool("no-check", false, "do not check if the next scanner is registered and enabled")
+
+	// forta account migrate
+	cmdFortaAccountMigrate.Flags().String("signer-url", "", "url of the remote signer")
+	cmdFortaAccountMigrate.MarkFlagRequired("signer-url")
+	cmdFortaAccountMigrate.Flags().String("auth-token", "", "token to authenticate with the remote signer")
+
 	// forta run
 	cmdFortaRun.Flags().BoolVar(&parsedArgs.NoCheck, "no-check", false, "disable scanner registry check and just run")
+	cmdFortaRun.Flags().BoolVar(&parsedArgs.NoConfigCheck, "no-config-check", false, "disable checking the config and the environment before running")
+	cmdFortaRun.Flags().BoolVar(&parsedArgs.Dev, "dev", false, "run the scanner, the proxy and the bots from the dev config in a single process")
+
+	// forta config validate
+	cmdFortaConfigValidate.Flags().Bool("json", false, "output the diagnostics as json")
 
 	// forta status
 	cmdFortaStatus.Flags().String("format", StatusFormatPretty, "output formatting/encoding: pretty (default), oneline, json, csv")
 	cmdFortaStatus.Flags().Bool("no-color", false, "disable colors")
 	cmdFortaStatus.Flags().String("show", StatusShowSummary, "filter statuses to show: summary (default), important, all")
+	cmdFortaStatus.Flags().Bool("json", false, "output the aggregated node status (liveness, readiness and key metrics) as json")
+	cmdFortaStatus.Flags().Bool("history", false, "display the recorded health status transitions instead of the current statuses")
+	cmdFortaStatus.Flags().String("since", "24h", "show the transitions after this duration ago or RFC3339 time (used with --history)")
+
+	// forta health
+	cmdFortaHealth.Flags().Bool("ready", false, "check readiness instead of liveness")
+
+	// forta replay
+	cmdFortaReplay.Flags().String("bot", "", "id of the running bot to send the block or the tx to")
+	cmdFortaReplay.MarkFlagRequired("bot")
+	cmdFortaReplay.Flags().String("tx", "", "hash of the tx to replay")
+	cmdFortaReplay.Flags().Uint64("block", 0, "number of the block to replay")
+
+	// forta recordings replay
+	cmdFortaRecordingsReplay.Flags().String("file", "", "recording file to replay")
+	cmdFortaRecordingsReplay.MarkFlagRequired("file")
+	cmdFortaRecordingsReplay.Flags().String("image", "", "bot image to run (default: the recorded image)")
+	cmdFortaRecordingsReplay.Flags().String("address", "", "gRPC address of a bot which is already running instead of an image")
+	cmdFortaRecordingsReplay.Flags().String("port", config.AgentGrpcPort, "local port to publish the gRPC port of the bot on")
+	cmdFortaRecordingsReplay.Flags().StringSlice("env", nil, "env vars for the bot container as KEY=VALUE")
+
+	// forta backfill start
+	cmdFortaBackfillStart.Flags().Uint64("start", 0, "first block of the range")
+	cmdFortaBackfillStart.MarkFlagRequired("start")
+	cmdFortaBackfillStart.Flags().Uint64("end", 0, "last block of the range")
+	cmdFortaBackfillStart.MarkFlagRequired("end")
+	cmdFortaBackfillStart.Flags().Float64("rate", 0, "max blocks per second (default 2)")
+	cmdFortaBackfillStart.Flags().Bool("no-publish", false, "keep the findings local instead of publishing them to the network")
+	cmdFortaBackfillStart.Flags().Bool("wait", false, "report the progress until the backfill finishes")
+
+	// forta doctor
+	cmdFortaDoctor.Flags().Bool("json", false, "output the report as json")
+	cmdFortaDoctor.Flags().String("output", "", "file to write the json report to")
+
+	// forta install
+	cmdFortaInstall.Flags().String("output", defaultSystemdUnitPath, "path to write the systemd unit to")
+	cmdFortaInstall.Flags().String("user", "", "user to run the node as (needs access to docker)")
+	cmdFortaInstall.Flags().String("env-file", "/etc/default/forta", "file with the environment variables like FORTA_PASSPHRASE (empty to skip)")
+	cmdFortaInstall.Flags().Int("watchdog-sec", 120, "restart the node if it is not live for this long")
+	cmdFortaInstall.Flags().Bool("print", false, "print the unit instead of writing it")
+
+	// forta bots
+	cmdFortaBotsList.Flags().Bool("json", false, "output the bots as json")
+	cmdFortaBotsInspect.Flags().String("bot", "", "id of the bot to inspect")
+	cmdFortaBotsInspect.MarkFlagRequired("bot")
+	cmdFortaBotsRestart.Flags().String("bot", "", "id of the bot to restart")
+	cmdFortaBotsRestart.MarkFlagRequired("bot")
+	cmdFortaBotsStop.Flags().String("bot", "", "id of the bot to stop")
+	cmdFortaBotsStop.MarkFlagRequired("bot")
+	cmdFortaBotsLogs.Flags().String("bot", "", "id of the bot to print the logs of")
+	cmdFortaBotsLogs.MarkFlagRequired("bot")
+	cmdFortaBotsLogs.Flags().Int("tail", 100, "number of the latest log lines to print")
+
+	// forta snapshot export
+	cmdFortaSnapshotExport.Flags().String("output", "", "dir to write the snapshot bundle to")
+	cmdFortaSnapshotExport.MarkFlagRequired("output")
+
+	// forta snapshot import
+	cmdFortaSnapshotImport.Flags().String("input", "", "dir to read the snapshot bundle from")
+	cmdFortaSnapshotImport.MarkFlagRequired("input")
+
+	// forta snapshot create
+	cmdFortaSnapshotCreate.Flags().String("output", "", "file to write the node state bundle to")
+	cmdFortaSnapshotCreate.MarkFlagRequired("output")
+	cmdFortaSnapshotCreate.Flags().Bool("no-keys", false, "do not include the scanner keys")
+	cmdFortaSnapshotCreate.Flags().String("new-passphrase", "", "re-encrypt the scanner keys with a new passphrase")
+
+	// forta snapshot restore
+	cmdFortaSnapshotRestore.Flags().String("input", "", "node state bundle to restore")
+	cmdFortaSnapshotRestore.MarkFlagRequired("input")
+	cmdFortaSnapshotRestore.Flags().BoolP("force", "f", false, "replace the existing files in the Forta dir")
+
+	// forta audit export
+	cmdFortaAuditExport.Flags().String("output", "", "file to write the entries to (default: stdout)")
 
 	// forta status all
 	cmdFortaStatusAll.Flags().Bool("no-color", false, "disable colors")
@@ -246,8 +575,30 @@ func initConfig() {
 	cfg.Development = viper.GetBool(keyFortaDevelopment)
 	cfg.Passphrase = viper.GetString(keyFortaPassphrase)
 
+	passphrase, err := config.ResolveSecret(cfg.Passphrase, cfg.FortaDir)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to resolve the passphrase")
+	}
+	cfg.Passphrase = passphrase
+	if err := config.ApplyOverrides(&cfg); err != nil {
+		yellowBold("Please check the config overrides in the env vars and the secret references in your config file.\n")
+		logrus.WithError(err).Fatal("failed to apply config overrides")
+	}
+
 	viper.ReadConfig(bytes.NewBuffer(configBytes))
 	config.InitLogLevel(cfg)
+
+	if cfg.Dev.DockerDesktop.Enable {
+		docker.SetHost(cfg.Dev.DockerDesktop.DockerHost())
+	}
+}
+
+// dockerSocketPath returns the Docker daemon address for the environment checks.
+func dockerSocketPath() string {
+	if cfg.Dev.DockerDesktop.Enable {
+		return cfg.Dev.DockerDesktop.DockerHost()
+	}
+	return config.DefaultDockerSocketPath
 }
 
 var configEnvVarRegexp = regexp.MustCompile(`\$[A-Z0-9_]+`)
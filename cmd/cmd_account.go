@@ -5,10 +5,16 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
 	"github.com/spf13/cobra"
 )
 
@@ -62,3 +68,146 @@ func handleFortaAccountImport(cmd *cobra.Command, args []string) error {
 	fmt.Println(account.Address.Hex())
 	return nil
 }
+
+func handleFortaAccountRotate(cmd *cobra.Command, args []string) error {
+	if cfg.Signer.IsRemote() {
+		redBold("The scanner key is managed by the remote signer. Please rotate it in the signing service and update signer.address in %s/config.yml.\n", cfg.FortaDir)
+		return errors.New("remote signer")
+	}
+	if len(cfg.Passphrase) == 0 {
+		redBold("Your passphrase is not set. Please set it with FORTA_PASSPHRASE environment variable or provide it with the --passphrase flag.\n")
+		return errors.New("empty passhphrase")
+	}
+	currentKey, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to load the current scanner key: %v", err)
+	}
+
+	complete, _ := cmd.Flags().GetBool("complete")
+	nextKeyDir := path.Join(cfg.FortaDir, config.DefaultNextKeysDirName)
+	if !complete {
+		nextAddress, err := createNextKey(nextKeyDir, cfg.Passphrase)
+		if err != nil {
+			return err
+		}
+		whiteBold("Current scanner address: %s\n", currentKey.Address.Hex())
+		greenBold("Next scanner address: %s\n", nextAddress.Hex())
+		fmt.Println("The node keeps running with the current key until the rotation is completed.")
+		fmt.Println("Please register the next scanner address to your scanner pool and enable it, then run 'forta account rotate --complete'.")
+		return nil
+	}
+
+	nextKey, err := security.LoadKeyWithPassphrase(nextKeyDir, cfg.Passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to load the next scanner key (did you run 'forta account rotate' first?): %v", err)
+	}
+	noCheck, _ := cmd.Flags().GetBool("no-check")
+	if !cfg.LocalModeConfig.Enable && !noCheck {
+		scanner, err := getRegisteredScanner(nextKey.Address.Hex())
+		if err != nil {
+			return err
+		}
+		if scanner == nil || !scanner.Enabled {
+			yellowBold("The next scanner %s is not registered or not enabled yet - please register and enable it first.\n", nextKey.Address.Hex())
+			toStderr("You can disable this behaviour with --no-check flag.\n")
+			return errors.New("next scanner is not ready")
+		}
+	}
+	archivedKeyDir, err := promoteNextKey(cfg.KeyDirPath, nextKeyDir, path.Join(cfg.FortaDir, config.DefaultOldKeysDirName), currentKey.Address)
+	if err != nil {
+		return err
+	}
+	greenBold("The scanner key is rotated to %s\n", nextKey.Address.Hex())
+	fmt.Printf("The old key is archived to %s. Please restart the node to start using the new key.\n", archivedKeyDir)
+	return nil
+}
+
+// createNextKey creates the next scanner key in the given dir unless it already exists.
+func createNextKey(nextKeyDir, passphrase string) (common.Address, error) {
+	ks := keystore.NewKeyStore(nextKeyDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	accounts := ks.Accounts()
+	if len(accounts) > 1 {
+		return common.Address{}, fmt.Errorf("multiple keys found in %s", nextKeyDir)
+	}
+	if len(accounts) == 1 {
+		return accounts[0].Address, nil
+	}
+	account, err := ks.NewAccount(passphrase)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to create the next key: %v", err)
+	}
+	return account.Address, nil
+}
+
+// promoteNextKey moves the current keys dir to the archive and replaces it with the next keys dir.
+func promoteNextKey(keyDir, nextKeyDir, oldKeysDir string, currentAddress common.Address) (string, error) {
+	if err := os.MkdirAll(oldKeysDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create the old keys dir: %v", err)
+	}
+	archivedKeyDir := path.Join(oldKeysDir, fmt.Sprintf("%s-%d", currentAddress.Hex(), time.Now().Unix()))
+	if err := os.Rename(keyDir, archivedKeyDir); err != nil {
+		return "", fmt.Errorf("failed to archive the current key: %v", err)
+	}
+	if err := os.Rename(nextKeyDir, keyDir); err != nil {
+		// put the current key back so that the node can still run
+		if restoreErr := os.Rename(archivedKeyDir, keyDir); restoreErr != nil {
+			return "", fmt.Errorf("failed to promote the next key: %v (and failed to restore the current key: %v)", err, restoreErr)
+		}
+		return "", fmt.Errorf("failed to promote the next key: %v", err)
+	}
+	return archivedKeyDir, nil
+}
+
+func handleFortaAccountMigrate(cmd *cobra.Command, args []string) error {
+	signerURL, _ := cmd.Flags().GetString("signer-url")
+	authToken, _ := cmd.Flags().GetString("auth-token")
+
+	currentAddress, err := getAccountAddress(cfg.KeyDirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the current scanner address: %v", err)
+	}
+	remoteSigner, err := signer.NewRemoteSigner(signerURL, authToken)
+	if err != nil {
+		return err
+	}
+	if remoteSigner.Address() != currentAddress {
+		redBold("The remote signer address %s does not match the scanner address %s. Please import the scanner key to the signing service first.\n", remoteSigner.Address().Hex(), currentAddress.Hex())
+		return errors.New("address mismatch")
+	}
+	if err := checkRemoteSigner(remoteSigner); err != nil {
+		return err
+	}
+
+	greenBold("The remote signer works!\n")
+	fmt.Printf("Please add this to %s/config.yml and restart the node:\n\n", cfg.FortaDir)
+	fmt.Printf("signer:\n  remoteUrl: %s\n  authToken: <auth token>\n  address: \"%s\"\n\n", signerURL, currentAddress.Hex())
+	fmt.Println("The node does not decrypt the local key after this. Keep a backup of the key before removing it from the host.")
+	return nil
+}
+
+// checkRemoteSigner signs a test token with the remote signer and verifies it.
+func checkRemoteSigner(remoteSigner signer.Signer) error {
+	token, err := signer.CreateScannerJWT(remoteSigner, map[string]interface{}{
+		"access": "signer-check",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign with the remote signer: %v", err)
+	}
+	scannerToken, err := security.VerifyScannerJWT(token)
+	if err != nil {
+		return fmt.Errorf("failed to verify the remote signer signature: %v", err)
+	}
+	if !strings.EqualFold(scannerToken.Scanner, remoteSigner.Address().Hex()) {
+		return fmt.Errorf("remote signer signed as %s instead of %s", scannerToken.Scanner, remoteSigner.Address().Hex())
+	}
+	return nil
+}
+
+func getAccountAddress(keyDir string) (common.Address, error) {
+	ks := keystore.NewKeyStore(keyDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	accounts := ks.Accounts()
+	if len(accounts) != 1 {
+		return common.Address{}, fmt.Errorf("expected one key in %s but found %d", keyDir, len(accounts))
+	}
+	return accounts[0].Address, nil
+}
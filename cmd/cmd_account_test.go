@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"os"
+	"path"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/stretchr/testify/require"
 )
 
@@ -45,3 +49,48 @@ func TestShowAccounts_MultipleAccounts(t *testing.T) {
 
 	r.Error(handleFortaAccountAddress(nil, nil))
 }
+
+func TestRotateKey(t *testing.T) {
+	r := require.New(t)
+
+	fortaDir := t.TempDir()
+	keyDir := path.Join(fortaDir, ".keys")
+	nextKeyDir := path.Join(fortaDir, ".keys-next")
+	oldKeysDir := path.Join(fortaDir, ".keys-old")
+
+	currentAccount, err := keystore.NewKeyStore(keyDir, keystore.StandardScryptN, keystore.StandardScryptP).NewAccount("Forta123")
+	r.NoError(err)
+
+	nextAddress, err := createNextKey(nextKeyDir, "Forta123")
+	r.NoError(err)
+	r.NotEqual(currentAccount.Address, nextAddress)
+
+	// does not create another key if there is one already
+	sameAddress, err := createNextKey(nextKeyDir, "Forta123")
+	r.NoError(err)
+	r.Equal(nextAddress, sameAddress)
+
+	archivedKeyDir, err := promoteNextKey(keyDir, nextKeyDir, oldKeysDir, currentAccount.Address)
+	r.NoError(err)
+
+	key, err := security.LoadKeyWithPassphrase(keyDir, "Forta123")
+	r.NoError(err)
+	r.Equal(nextAddress, key.Address)
+	oldKey, err := security.LoadKeyWithPassphrase(archivedKeyDir, "Forta123")
+	r.NoError(err)
+	r.Equal(currentAccount.Address, oldKey.Address)
+	_, err = os.Stat(nextKeyDir)
+	r.True(os.IsNotExist(err))
+}
+
+func TestCheckRemoteSigner(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	_, err := keystore.NewKeyStore(dir, keystore.StandardScryptN, keystore.StandardScryptP).NewAccount("Forta123")
+	r.NoError(err)
+	key, err := security.LoadKeyWithPassphrase(dir, "Forta123")
+	r.NoError(err)
+
+	r.NoError(checkRemoteSigner(signer.NewKeySigner(key)))
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/forta-network/forta-node/audit"
+	"github.com/spf13/cobra"
+)
+
+func handleFortaAuditVerify(cmd *cobra.Command, args []string) error {
+	count, err := audit.Verify(cfg.AuditLog.FilePath(cfg.FortaDir))
+	if err != nil {
+		redBold("Verified %d entries before the failure: %v\n", count, err)
+		return err
+	}
+	greenBold("Verified %d entries\n", count)
+	return nil
+}
+
+func handleFortaAuditExport(cmd *cobra.Command, args []string) error {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	var w io.Writer = cmd.OutOrStdout()
+	if len(output) > 0 {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create the output file: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	count, err := audit.Export(cfg.AuditLog.FilePath(cfg.FortaDir), w)
+	if err != nil {
+		return fmt.Errorf("failed to export the audit log after %d entries: %v", count, err)
+	}
+	if len(output) > 0 {
+		greenBold("Exported %d entries to %s\n", count, output)
+	}
+	return nil
+}
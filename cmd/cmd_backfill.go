@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/scanner"
+	"github.com/spf13/cobra"
+)
+
+const (
+	backfillRequestTimeout = time.Second * 30
+	backfillPollInterval   = time.Second * 5
+)
+
+func handleFortaBackfillStart(cmd *cobra.Command, args []string) error {
+	startBlock, err := cmd.Flags().GetUint64("start")
+	if err != nil {
+		return err
+	}
+	endBlock, err := cmd.Flags().GetUint64("end")
+	if err != nil {
+		return err
+	}
+	rate, err := cmd.Flags().GetFloat64("rate")
+	if err != nil {
+		return err
+	}
+	noPublish, err := cmd.Flags().GetBool("no-publish")
+	if err != nil {
+		return err
+	}
+	wait, err := cmd.Flags().GetBool("wait")
+	if err != nil {
+		return err
+	}
+	req := &scanner.BackfillRequest{
+		StartBlock:      startBlock,
+		EndBlock:        endBlock,
+		BlocksPerSecond: rate,
+		NoPublish:       noPublish,
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	b, _ := json.Marshal(req)
+	status, err := sendBackfillRequest(http.MethodPost, b)
+	if err != nil {
+		return err
+	}
+	if !wait {
+		return printBackfillStatus(cmd, status)
+	}
+
+	for status.State == scanner.BackfillStateRunning {
+		cmd.Printf("backfill %s: %d/%d blocks (current: %d), %d txs, %d findings\n",
+			status.ID, status.ProcessedBlocks, status.TotalBlocks, status.CurrentBlock, status.Transactions, status.Findings)
+		time.Sleep(backfillPollInterval)
+		status, err = sendBackfillRequest(http.MethodGet, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return printBackfillStatus(cmd, status)
+}
+
+func handleFortaBackfillStatus(cmd *cobra.Command, args []string) error {
+	status, err := sendBackfillRequest(http.MethodGet, nil)
+	if err != nil {
+		return err
+	}
+	return printBackfillStatus(cmd, status)
+}
+
+func handleFortaBackfillCancel(cmd *cobra.Command, args []string) error {
+	status, err := sendBackfillRequest(http.MethodDelete, nil)
+	if err != nil {
+		return err
+	}
+	return printBackfillStatus(cmd, status)
+}
+
+// sendBackfillRequest calls the runner health server on localhost which forwards to the scanner
+// through the supervisor.
+func sendBackfillRequest(method string, body []byte) (*scanner.BackfillStatus, error) {
+	req, err := http.NewRequest(
+		method, fmt.Sprintf("http://localhost:%s%s", config.DefaultHealthPort, scanner.PathBackfill),
+		bytes.NewBuffer(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := &http.Client{Timeout: backfillRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send the backfill request: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the backfill response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("backfill request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var status scanner.BackfillStatus
+	if err := json.Unmarshal(respBody, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode the backfill response: %v", err)
+	}
+	return &status, nil
+}
+
+func printBackfillStatus(cmd *cobra.Command, status *scanner.BackfillStatus) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(status)
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/supervisor"
+	"github.com/spf13/cobra"
+)
+
+const botsRequestTimeout = time.Minute * 2
+
+func handleFortaBotsList(cmd *cobra.Command, args []string) error {
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return err
+	}
+	respBody, err := sendBotsRequest(http.MethodGet, supervisor.PathBots, nil, nil)
+	if err != nil {
+		return err
+	}
+	var bots []*supervisor.BotInfo
+	if err := json.Unmarshal(respBody, &bots); err != nil {
+		return fmt.Errorf("failed to decode the bots response: %v", err)
+	}
+	if asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(bots)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tDIGEST\tSHARD\tSTATE\tUPTIME\tRESTARTS\tCPU\tMEMORY\tLAST ERROR")
+	for _, bot := range bots {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%.1f%%\t%s\t%s\n",
+			bot.ID, orDash(bot.Name), shortDigest(bot.ImageDigest), botShard(bot), bot.State, orDash(bot.Uptime),
+			bot.Restarts, bot.CPUPercent, formatBytes(bot.MemoryBytes), lastBotError(bot),
+		)
+	}
+	return w.Flush()
+}
+
+func handleFortaBotsInspect(cmd *cobra.Command, args []string) error {
+	botID, err := cmd.Flags().GetString("bot")
+	if err != nil {
+		return err
+	}
+	respBody, err := sendBotsRequest(http.MethodGet, supervisor.PathBotInspect, url.Values{"botId": {botID}}, nil)
+	if err != nil {
+		return err
+	}
+	var bot supervisor.BotInfo
+	if err := json.Unmarshal(respBody, &bot); err != nil {
+		return fmt.Errorf("failed to decode the bot response: %v", err)
+	}
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(&bot)
+}
+
+func handleFortaBotsRestart(cmd *cobra.Command, args []string) error {
+	return sendBotAction(cmd, supervisor.PathBotRestart, "restarted")
+}
+
+func handleFortaBotsStop(cmd *cobra.Command, args []string) error {
+	return sendBotAction(cmd, supervisor.PathBotStop, "stopped")
+}
+
+func sendBotAction(cmd *cobra.Command, path, done string) error {
+	botID, err := cmd.Flags().GetString("bot")
+	if err != nil {
+		return err
+	}
+	b, _ := json.Marshal(&supervisor.BotActionRequest{BotID: botID})
+	if _, err := sendBotsRequest(http.MethodPost, path, nil, b); err != nil {
+		return err
+	}
+	cmd.Printf("bot %s %s\n", botID, done)
+	return nil
+}
+
+func handleFortaBotsLogs(cmd *cobra.Command, args []string) error {
+	botID, err := cmd.Flags().GetString("bot")
+	if err != nil {
+		return err
+	}
+	tail, err := cmd.Flags().GetInt("tail")
+	if err != nil {
+		return err
+	}
+	query := url.Values{"botId": {botID}, "tail": {strconv.Itoa(tail)}}
+	respBody, err := sendBotsRequest(http.MethodGet, supervisor.PathBotLogs, query, nil)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.OutOrStdout().Write(respBody)
+	return err
+}
+
+// sendBotsRequest calls the runner health server on localhost which forwards to the supervisor.
+func sendBotsRequest(method, path string, query url.Values, body []byte) ([]byte, error) {
+	reqURL := fmt.Sprintf("http://localhost:%s%s", config.DefaultHealthPort, path)
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, reqURL, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := &http.Client{Timeout: botsRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send the bots request: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the bots response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bots request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
+
+func orDash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
+
+func shortDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return orDash(digest)
+}
+
+func botShard(bot *supervisor.BotInfo) string {
+	if bot.ShardID == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d", *bot.ShardID, bot.Shards)
+}
+
+func lastBotError(bot *supervisor.BotInfo) string {
+	if bot.LastError == nil {
+		return "-"
+	}
+	return bot.LastError.Name
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/spf13/cobra"
+)
+
+func handleFortaConfigValidate(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	diagnoser := config.NewDiagnoser()
+	diagnoser.DockerSocketPath = dockerSocketPath()
+	diagnostics := diagnoser.Diagnose(cfg)
+	if asJSON {
+		if diagnostics == nil {
+			diagnostics = config.Diagnostics{}
+		}
+		b, _ := json.MarshalIndent(diagnostics, "", "  ")
+		fmt.Println(string(b))
+	} else {
+		printDiagnostics(diagnostics)
+	}
+	if diagnostics.HasErrors() {
+		return errors.New("invalid config")
+	}
+	if !asJSON {
+		greenBold("The config is valid.\n")
+	}
+	return nil
+}
+
+// checkConfigOnStartup prints the config diagnostics and fails if there are any errors.
+func checkConfigOnStartup() error {
+	diagnoser := config.NewDiagnoser()
+	diagnoser.DockerSocketPath = dockerSocketPath()
+	diagnostics := diagnoser.Diagnose(cfg)
+	printDiagnostics(diagnostics)
+	if diagnostics.HasErrors() {
+		toStderr("Please fix the errors above. You can check again with 'forta config validate'.\n")
+		return errors.New("invalid config")
+	}
+	return nil
+}
+
+func printDiagnostics(diagnostics config.Diagnostics) {
+	for _, diagnostic := range diagnostics {
+		label := fmt.Sprintf("[%s]", diagnostic.Severity)
+		if diagnostic.Severity == config.SeverityError {
+			redBold("%-9s ", label)
+		} else {
+			yellowBold("%-9s ", label)
+		}
+		if len(diagnostic.Field) > 0 {
+			fmt.Fprintf(os.Stderr, "%s: ", diagnostic.Field)
+		}
+		fmt.Fprintln(os.Stderr, diagnostic.Message)
+		if len(diagnostic.Hint) > 0 {
+			fmt.Fprintf(os.Stderr, "          hint: %s\n", diagnostic.Hint)
+		}
+	}
+}
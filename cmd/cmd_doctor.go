@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/forta-network/forta-node/config"
+	"github.com/spf13/cobra"
+)
+
+func handleFortaDoctor(cmd *cobra.Command, args []string) error {
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return err
+	}
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+
+	doctor := config.NewDoctor()
+	doctor.DockerSocketPath = dockerSocketPath()
+	report := doctor.Examine(cfg)
+	b, _ := json.MarshalIndent(report, "", "  ")
+	if len(output) > 0 {
+		if err := ioutil.WriteFile(output, b, 0644); err != nil {
+			return fmt.Errorf("failed to write the report: %v", err)
+		}
+	}
+	if asJSON {
+		fmt.Println(string(b))
+	} else {
+		printDoctorReport(report)
+		if len(output) > 0 {
+			fmt.Printf("\nThe report is written to %s. You can attach it to your support request.\n", output)
+		}
+	}
+	if report.Failed() {
+		return errors.New("some checks failed")
+	}
+	return nil
+}
+
+func printDoctorReport(report *config.DoctorReport) {
+	for _, check := range report.Checks {
+		label := fmt.Sprintf("[%s]", check.Result)
+		switch check.Result {
+		case config.CheckPass:
+			color.New(color.Bold, color.FgGreen).Printf("%-7s ", label)
+		case config.CheckWarn:
+			color.New(color.Bold, color.FgYellow).Printf("%-7s ", label)
+		default:
+			color.New(color.Bold, color.FgRed).Printf("%-7s ", label)
+		}
+		fmt.Fprintf(os.Stdout, "%s: %s\n", check.Name, check.Message)
+		if len(check.Hint) > 0 {
+			fmt.Fprintf(os.Stdout, "        hint: %s\n", check.Hint)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/spf13/cobra"
+)
+
+func handleFortaHealth(cmd *cobra.Command, ready bool) error {
+	status := healthutils.NodeStatusFromReports(health.NewClient().CheckHealth("forta", config.DefaultHealthPort))
+	switch {
+	case !status.Live:
+		return fmt.Errorf("node is not live: %s", strings.Join(status.NotReadyReasons, ", "))
+	case ready && !status.Ready:
+		return fmt.Errorf("node is not ready: %s", strings.Join(status.NotReadyReasons, ", "))
+	case ready:
+		cmd.Println("ready")
+	default:
+		cmd.Println("live")
+	}
+	return nil
+}
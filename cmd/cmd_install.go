@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+const defaultSystemdUnitPath = "/etc/systemd/system/forta.service"
+
+// the node notifies systemd when it is ready and keeps pinging the watchdog while it is live
+var systemdUnitTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description=Forta node
+Documentation=https://docs.forta.network
+Requires=docker.service
+After=docker.service network-online.target
+Wants=network-online.target
+
+[Service]
+Type=notify
+NotifyAccess=main
+{{- if .User }}
+User={{ .User }}
+{{- end }}
+Environment=FORTA_DIR={{ .FortaDir }}
+{{- if .EnvFile }}
+EnvironmentFile={{ .EnvFile }}
+{{- end }}
+ExecStart={{ .Executable }} run
+Restart=always
+RestartSec=15
+TimeoutStartSec=30min
+WatchdogSec={{ .WatchdogSeconds }}
+# SIGINT tears down the bots while SIGTERM keeps them for the next start
+KillSignal=SIGINT
+TimeoutStopSec=5min
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+type systemdUnit struct {
+	User            string
+	FortaDir        string
+	EnvFile         string
+	Executable      string
+	WatchdogSeconds int
+}
+
+func (unit *systemdUnit) render() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := systemdUnitTemplate.Execute(&buf, unit); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func handleFortaInstall(cmd *cobra.Command, args []string) error {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	user, err := cmd.Flags().GetString("user")
+	if err != nil {
+		return err
+	}
+	envFile, err := cmd.Flags().GetString("env-file")
+	if err != nil {
+		return err
+	}
+	watchdogSeconds, err := cmd.Flags().GetInt("watchdog-sec")
+	if err != nil {
+		return err
+	}
+	printOnly, err := cmd.Flags().GetBool("print")
+	if err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the forta executable: %v", err)
+	}
+	unit := &systemdUnit{
+		User:            user,
+		FortaDir:        cfg.FortaDir,
+		EnvFile:         envFile,
+		Executable:      executable,
+		WatchdogSeconds: watchdogSeconds,
+	}
+	b, err := unit.render()
+	if err != nil {
+		return fmt.Errorf("failed to generate the systemd unit: %v", err)
+	}
+
+	if printOnly {
+		fmt.Print(string(b))
+		return nil
+	}
+	if err := ioutil.WriteFile(output, b, 0644); err != nil {
+		return fmt.Errorf("failed to write the systemd unit: %v", err)
+	}
+	greenBold("Installed the systemd unit to %s\n", output)
+	if len(envFile) > 0 {
+		fmt.Printf("Put FORTA_PASSPHRASE=<passphrase> to %s and make it readable only by the node user.\n", envFile)
+	}
+	fmt.Println("Enable and start the node with: systemctl daemon-reload && systemctl enable --now forta")
+	return nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	r := require.New(t)
+
+	unit := &systemdUnit{
+		User:            "forta",
+		FortaDir:        "/home/forta/.forta",
+		EnvFile:         "/etc/default/forta",
+		Executable:      "/usr/bin/forta",
+		WatchdogSeconds: 120,
+	}
+	b, err := unit.render()
+	r.NoError(err)
+	lines := strings.Split(string(b), "\n")
+	r.Contains(lines, "Type=notify")
+	r.Contains(lines, "User=forta")
+	r.Contains(lines, "Environment=FORTA_DIR=/home/forta/.forta")
+	r.Contains(lines, "EnvironmentFile=/etc/default/forta")
+	r.Contains(lines, "ExecStart=/usr/bin/forta run")
+	r.Contains(lines, "WatchdogSec=120")
+
+	unit.User = ""
+	unit.EnvFile = ""
+	b, err = unit.render()
+	r.NoError(err)
+	r.NotContains(string(b), "User=")
+	r.NotContains(string(b), "EnvironmentFile=")
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	replayBotContainerName = "forta-replay-bot"
+	replayDialTimeout      = time.Minute
+	replayInvokeTimeout    = time.Minute
+)
+
+// replayedRequest is the output of a replayed request.
+type replayedRequest struct {
+	Method      agentgrpc.Method    `json:"method"`
+	BlockNumber uint64              `json:"blockNumber"`
+	RequestID   string              `json:"requestId"`
+	DurationMs  int64               `json:"durationMs"`
+	Status      string              `json:"status,omitempty"`
+	Findings    []*protocol.Finding `json:"findings,omitempty"`
+	Error       string              `json:"error,omitempty"`
+}
+
+func handleFortaRecordingsReplay(cmd *cobra.Command, args []string) error {
+	filePath, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return err
+	}
+	image, err := cmd.Flags().GetString("image")
+	if err != nil {
+		return err
+	}
+	address, err := cmd.Flags().GetString("address")
+	if err != nil {
+		return err
+	}
+	port, err := cmd.Flags().GetString("port")
+	if err != nil {
+		return err
+	}
+	envVars, err := cmd.Flags().GetStringSlice("env")
+	if err != nil {
+		return err
+	}
+	if len(image) > 0 && len(address) > 0 {
+		return errors.New("either the image or the address should be provided")
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open the recording file: %v", err)
+	}
+	defer file.Close()
+	var recordings []*botio.Recording
+	if err := botio.ReadRecordings(file, func(recording *botio.Recording) error {
+		recordings = append(recordings, recording)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(recordings) == 0 {
+		return errors.New("no recordings found in the file")
+	}
+
+	ctx := context.Background()
+	if len(address) == 0 {
+		if len(image) == 0 {
+			image = recordings[0].Image
+		}
+		env := make(map[string]string)
+		for _, envVar := range envVars {
+			parts := strings.SplitN(envVar, "=", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("invalid env var '%s': should be KEY=VALUE", envVar)
+			}
+			env[parts[0]] = parts[1]
+		}
+		stopBot, err := startReplayBot(ctx, image, port, env)
+		if err != nil {
+			return err
+		}
+		defer stopBot()
+		address = "127.0.0.1:" + port
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, replayDialTimeout)
+	defer cancel()
+	conn, err := grpc.DialContext(dialCtx, address, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("failed to connect to the bot: %v", err)
+	}
+	defer conn.Close()
+	botClient := protocol.NewAgentClient(conn)
+
+	_, err = botClient.Initialize(ctx, &protocol.InitializeRequest{
+		AgentId:   recordings[0].BotID,
+		ProxyHost: config.DockerJSONRPCProxyContainerName,
+	})
+	if err != nil && status.Code(err) != codes.Unimplemented {
+		return fmt.Errorf("failed to initialize the bot: %v", err)
+	}
+
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	for _, recording := range recordings {
+		replayed, err := replayRecording(ctx, botClient, recording)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(replayed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startReplayBot runs the bot image locally and returns the function which removes the bot.
+func startReplayBot(ctx context.Context, image, port string, env map[string]string) (func(), error) {
+	dockerClient, err := docker.NewDockerClient("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the docker client: %v", err)
+	}
+	if err := dockerClient.EnsureLocalImage(ctx, "replayed bot", image); err != nil {
+		return nil, err
+	}
+	// remove the leftover from a previous replay
+	if container, err := dockerClient.GetContainerByName(ctx, replayBotContainerName); err == nil {
+		if err := dockerClient.RemoveContainer(ctx, container.ID); err != nil {
+			return nil, fmt.Errorf("failed to remove the previous replay bot: %v", err)
+		}
+	}
+	container, err := dockerClient.StartContainer(ctx, docker.ContainerConfig{
+		Name:  replayBotContainerName,
+		Image: image,
+		Env:   env,
+		Ports: map[string]string{
+			"127.0.0.1:" + port: config.AgentGrpcPort,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the bot: %v", err)
+	}
+	return func() {
+		if err := dockerClient.RemoveContainer(context.Background(), container.ID); err != nil {
+			yellowBold("failed to remove the replay bot container: %v\n", err)
+		}
+	}, nil
+}
+
+// replayRecording sends the recorded request to the bot. The errors from the bot are a part of
+// the output.
+func replayRecording(
+	ctx context.Context, botClient protocol.AgentClient, recording *botio.Recording,
+) (*replayedRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, replayInvokeTimeout)
+	defer cancel()
+
+	replayed := &replayedRequest{
+		Method:      recording.Method,
+		BlockNumber: recording.BlockNumber,
+	}
+	startTime := time.Now()
+	var err error
+	switch recording.Method {
+	case agentgrpc.MethodEvaluateTx:
+		var req protocol.EvaluateTxRequest
+		if err := proto.Unmarshal(recording.Request, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode the recorded tx request: %v", err)
+		}
+		replayed.RequestID = req.RequestId
+		var resp *protocol.EvaluateTxResponse
+		resp, err = botClient.EvaluateTx(ctx, &req)
+		if err == nil {
+			replayed.Status = resp.Status.String()
+			replayed.Findings = resp.Findings
+		}
+
+	case agentgrpc.MethodEvaluateBlock:
+		var req protocol.EvaluateBlockRequest
+		if err := proto.Unmarshal(recording.Request, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode the recorded block request: %v", err)
+		}
+		replayed.RequestID = req.RequestId
+		var resp *protocol.EvaluateBlockResponse
+		resp, err = botClient.EvaluateBlock(ctx, &req)
+		if err == nil {
+			replayed.Status = resp.Status.String()
+			replayed.Findings = resp.Findings
+		}
+
+	case agentgrpc.MethodEvaluateAlert:
+		var req protocol.EvaluateAlertRequest
+		if err := proto.Unmarshal(recording.Request, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode the recorded alert request: %v", err)
+		}
+		replayed.RequestID = req.RequestId
+		var resp *protocol.EvaluateAlertResponse
+		resp, err = botClient.EvaluateAlert(ctx, &req)
+		if err == nil {
+			replayed.Status = resp.Status.String()
+			replayed.Findings = resp.Findings
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown recorded method: %s", recording.Method)
+	}
+	replayed.DurationMs = time.Since(startTime).Milliseconds()
+	if err != nil {
+		replayed.Error = err.Error()
+	}
+	return replayed, nil
+}
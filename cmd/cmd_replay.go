@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/supervisor"
+	"github.com/spf13/cobra"
+)
+
+const replayRequestTimeout = time.Minute * 3
+
+func handleFortaReplay(cmd *cobra.Command, args []string) error {
+	botID, err := cmd.Flags().GetString("bot")
+	if err != nil {
+		return err
+	}
+	txHash, err := cmd.Flags().GetString("tx")
+	if err != nil {
+		return err
+	}
+	blockNumber, err := cmd.Flags().GetUint64("block")
+	if err != nil {
+		return err
+	}
+	req := &supervisor.ReplayRequest{
+		BotID:       botID,
+		TxHash:      txHash,
+		BlockNumber: blockNumber,
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	// call the runner health server on localhost which forwards to the supervisor
+	b, _ := json.Marshal(req)
+	client := &http.Client{Timeout: replayRequestTimeout}
+	resp, err := client.Post(
+		fmt.Sprintf("http://localhost:%s%s", config.DefaultHealthPort, supervisor.PathReplay),
+		"application/json", bytes.NewBuffer(b),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to send the replay request: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the replay response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("replay failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var replayResp supervisor.ReplayResponse
+	if err := json.Unmarshal(respBody, &replayResp); err != nil {
+		return fmt.Errorf("failed to decode the replay response: %v", err)
+	}
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(&replayResp)
+}
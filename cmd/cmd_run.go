@@ -6,8 +6,9 @@ import (
 	"fmt"
 
 	"github.com/forta-network/forta-core-go/registry"
-	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/cmd/dev"
 	"github.com/forta-network/forta-node/cmd/runner"
+	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/store"
 	"github.com/spf13/cobra"
 )
@@ -18,6 +19,17 @@ var (
 )
 
 func handleFortaRun(cmd *cobra.Command, args []string) error {
+	if parsedArgs.Dev {
+		cfg.Dev.Enable = true
+	}
+	if cfg.Dev.Enable {
+		return handleFortaRunDev()
+	}
+	if !parsedArgs.NoConfigCheck {
+		if err := checkConfigOnStartup(); err != nil {
+			return err
+		}
+	}
 	if err := checkScannerState(); err != nil {
 		return err
 	}
@@ -33,34 +45,49 @@ func handleFortaRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func handleFortaRunDev() error {
+	if _, err := config.LoadSigner(cfg); err != nil {
+		return fmt.Errorf("failed to load scanner signer: %v", err)
+	}
+	if len(cfg.Dev.Bots) == 0 {
+		yellowBold("No bots in the dev config! Add them to dev.bots in %s/config.yml\n", cfg.FortaDir)
+	}
+	// the development mode implies the local mode
+	cfg.LocalModeConfig.Enable = true
+	whiteBold("Running in development mode...\n")
+	if len(cfg.LocalModeConfig.WebhookURL) > 0 {
+		yellowBold("Sending alerts to %s\n", cfg.LocalModeConfig.WebhookURL)
+	} else {
+		// the container logs dir is not available on the host
+		cfg.LocalModeConfig.LogToStdout = true
+		yellowBold("No webhook URL specified! Logging alerts to stdout\n")
+	}
+	dev.Run(cfg)
+	return nil
+}
+
 func checkScannerState() error {
-	scannerKey, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
+	scannerSigner, err := config.LoadSigner(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to load scanner key: %v", err)
+		return fmt.Errorf("failed to load scanner signer: %v", err)
 	}
 
 	// disable registration and staking check in local mode
 	if cfg.LocalModeConfig.Enable {
 		return nil
 	}
+	// the registry is not reachable in snapshot mode
+	if cfg.Registry.SnapshotMode {
+		return nil
+	}
 	// disable if flag was provided
 	if parsedArgs.NoCheck {
 		return nil
 	}
 
-	scannerAddressStr := scannerKey.Address.Hex()
-
-	registry, err := store.GetRegistryClient(context.Background(), cfg, registry.ClientConfig{
-		JsonRpcUrl: cfg.Registry.JsonRpc.Url,
-		ENSAddress: cfg.ENSConfig.ContractAddress,
-		Name:       "registry-client",
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create registry client: %v", err)
-	}
-	scanner, err := registry.GetScanner(scannerAddressStr)
+	scanner, err := getRegisteredScanner(scannerSigner.Address().Hex())
 	if err != nil {
-		return fmt.Errorf("failed to check scanner state: %v", err)
+		return err
 	}
 
 	// treat reverts the same as non-registered
@@ -74,3 +101,19 @@ func checkScannerState() error {
 	}
 	return nil
 }
+
+func getRegisteredScanner(scannerAddress string) (*registry.Scanner, error) {
+	registryClient, err := store.GetRegistryClient(context.Background(), cfg, registry.ClientConfig{
+		JsonRpcUrl: cfg.Registry.JsonRpc.Url,
+		ENSAddress: cfg.ENSConfig.ContractAddress,
+		Name:       "registry-client",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry client: %v", err)
+	}
+	scanner, err := registryClient.GetScanner(scannerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check scanner state: %v", err)
+	}
+	return scanner, nil
+}
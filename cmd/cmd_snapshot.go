@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/spf13/cobra"
+)
+
+func handleFortaSnapshotExport(cmd *cobra.Command, args []string) error {
+	outputDir, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	scannerKey, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to load scanner key: %v", err)
+	}
+
+	// the manifests are fetched into the bundle through the manifest cache
+	exportCfg := cfg
+	exportCfg.Registry.ManifestCacheDir = path.Join(outputDir, store.SnapshotManifestsDirName)
+	if err := os.MkdirAll(exportCfg.Registry.ManifestCacheDir, 0700); err != nil {
+		return fmt.Errorf("failed to create the output dir: %v", err)
+	}
+
+	ctx := context.Background()
+	regStore, err := store.NewRegistryStore(ctx, exportCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create the registry store: %v", err)
+	}
+	bots, _, err := regStore.GetAgentsIfChanged(scannerKey.Address.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to get the assigned bots: %v", err)
+	}
+	cmd.Printf("Found %d assigned bots\n", len(bots))
+
+	dockerClient, err := docker.NewAuthDockerClient("", cfg.Registry.Username, cfg.Registry.Password)
+	if err != nil {
+		return fmt.Errorf("failed to create the docker client: %v", err)
+	}
+	var imageRefs []string
+	exportImage := func(name, ref string) (string, error) {
+		if err := dockerClient.EnsureLocalImage(ctx, name, ref); err != nil {
+			return "", err
+		}
+		snapshotRef := store.SnapshotImageRef(ref)
+		if err := dockerClient.TagImage(ctx, ref, snapshotRef); err != nil {
+			return "", fmt.Errorf("failed to tag image %s: %v", ref, err)
+		}
+		imageRefs = append(imageRefs, snapshotRef)
+		return snapshotRef, nil
+	}
+	for i, bot := range bots {
+		if bots[i].Image, err = exportImage(bot.ID, bot.Image); err != nil {
+			return err
+		}
+		for j, dep := range bot.Dependencies {
+			if bots[i].Dependencies[j].Image, err = exportImage(dep.Name, dep.Image); err != nil {
+				return err
+			}
+		}
+	}
+
+	imagesFile, err := os.Create(path.Join(outputDir, store.SnapshotImagesFileName))
+	if err != nil {
+		return fmt.Errorf("failed to create the images file: %v", err)
+	}
+	defer imagesFile.Close()
+	if err := dockerClient.SaveImages(ctx, imageRefs, imagesFile); err != nil {
+		return fmt.Errorf("failed to save the images: %v", err)
+	}
+
+	if err := store.WriteSnapshot(outputDir, &store.Snapshot{
+		ScannerAddress: scannerKey.Address.Hex(),
+		CreatedAt:      time.Now().UTC(),
+		Bots:           bots,
+	}); err != nil {
+		return err
+	}
+	cmd.Printf("Exported the registry snapshot to %s\n", outputDir)
+	return nil
+}
+
+func handleFortaSnapshotImport(cmd *cobra.Command, args []string) error {
+	inputDir, err := cmd.Flags().GetString("input")
+	if err != nil {
+		return err
+	}
+	snapshot, err := store.ReadSnapshot(inputDir)
+	if err != nil {
+		return err
+	}
+
+	dockerClient, err := docker.NewDockerClient("")
+	if err != nil {
+		return fmt.Errorf("failed to create the docker client: %v", err)
+	}
+	imagesFile, err := os.Open(path.Join(inputDir, store.SnapshotImagesFileName))
+	if err != nil {
+		return fmt.Errorf("failed to open the images file: %v", err)
+	}
+	defer imagesFile.Close()
+	if err := dockerClient.LoadImages(context.Background(), imagesFile); err != nil {
+		return fmt.Errorf("failed to load the images: %v", err)
+	}
+
+	if err := copyFiles(
+		path.Join(inputDir, store.SnapshotManifestsDirName),
+		path.Join(cfg.FortaDir, config.DefaultManifestCacheDirName),
+	); err != nil {
+		return fmt.Errorf("failed to copy the manifests: %v", err)
+	}
+
+	// the running node picks up the new snapshot when the assignments file is replaced
+	snapshotDir := path.Join(cfg.FortaDir, config.DefaultSnapshotDirName)
+	if err := os.MkdirAll(snapshotDir, 0700); err != nil {
+		return fmt.Errorf("failed to create the snapshot dir: %v", err)
+	}
+	if err := store.WriteSnapshot(snapshotDir, snapshot); err != nil {
+		return err
+	}
+
+	cmd.Printf("Imported the registry snapshot with %d bots (created at %s)\n", len(snapshot.Bots), snapshot.CreatedAt.Format(time.RFC3339))
+	if !cfg.Registry.SnapshotMode {
+		yellowBold("Please set registry.snapshotMode to true in your config to run from the snapshot.\n")
+	}
+	return nil
+}
+
+// copyFiles copies the regular files from one dir to another.
+func copyFiles(srcDir, dstDir string) error {
+	entries, err := ioutil.ReadDir(srcDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dstDir, 0700); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+		b, err := ioutil.ReadFile(path.Join(srcDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path.Join(dstDir, entry.Name()), b, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handleFortaSnapshotCreate(cmd *cobra.Command, args []string) error {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	noKeys, _ := cmd.Flags().GetBool("no-keys")
+	newPassphrase, _ := cmd.Flags().GetString("new-passphrase")
+	if newPassphrase, err = config.ResolveSecret(newPassphrase, cfg.FortaDir); err != nil {
+		return fmt.Errorf("failed to resolve the new passphrase: %v", err)
+	}
+
+	opts := store.NodeSnapshotOptions{
+		Paths:         nodeStatePaths(),
+		Passphrase:    cfg.Passphrase,
+		NewPassphrase: newPassphrase,
+	}
+	if !noKeys {
+		if cfg.Signer.IsRemote() {
+			yellowBold("The scanner key is managed by the remote signer and it is not included.\n")
+		} else {
+			opts.KeyDirs = []string{config.DefaultKeysDirName, config.DefaultNextKeysDirName}
+		}
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create the output file: %v", err)
+	}
+	defer file.Close()
+	manifest, err := store.CreateNodeSnapshot(cfg.FortaDir, file, opts)
+	if err != nil {
+		os.Remove(output)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to write the output file: %v", err)
+	}
+
+	greenBold("Created the node snapshot with %d files at %s\n", len(manifest.Files), output)
+	if len(manifest.ScannerAddress) > 0 {
+		fmt.Printf("Scanner address: %s\n", manifest.ScannerAddress)
+	}
+	fmt.Println("Please stop this node before starting the restored node on the new host so that the scanner does not run twice.")
+	return nil
+}
+
+func handleFortaSnapshotRestore(cmd *cobra.Command, args []string) error {
+	input, err := cmd.Flags().GetString("input")
+	if err != nil {
+		return err
+	}
+	force, _ := cmd.Flags().GetBool("force")
+
+	file, err := os.Open(input)
+	if err != nil {
+		return fmt.Errorf("failed to open the node snapshot: %v", err)
+	}
+	defer file.Close()
+	if err := os.MkdirAll(cfg.FortaDir, 0700); err != nil {
+		return fmt.Errorf("failed to create the Forta dir: %v", err)
+	}
+	manifest, err := store.RestoreNodeSnapshot(cfg.FortaDir, file, force)
+	if err != nil {
+		if !force {
+			toStderr("You can replace the existing files with --force flag.\n")
+		}
+		return err
+	}
+
+	greenBold("Restored %d files from the node snapshot created at %s\n", len(manifest.Files), manifest.CreatedAt.Format(time.RFC3339))
+	if len(manifest.ScannerAddress) > 0 {
+		fmt.Printf("Scanner address: %s\n", manifest.ScannerAddress)
+	}
+	if !manifest.KeysIncluded {
+		yellowBold("The snapshot has no keys. Please import your scanner key with 'forta account import'.\n")
+	}
+	fmt.Println("Please make sure that the node on the old host is stopped and then start this node with 'forta run'.")
+	return nil
+}
+
+// nodeStatePaths returns the node state to move to a new host. The paths are relative to the Forta dir.
+func nodeStatePaths() []string {
+	paths := []string{
+		config.DefaultConfigFileName,
+		config.DefaultWrappedConfigFileName,
+		"ens-override.json",
+		config.DefaultManifestCacheDirName,
+		config.DefaultSnapshotDirName,
+		config.DefaultCheckpointFileName,
+		config.DefaultCombinerCacheFileName,
+		config.DefaultHealthHistoryFileName,
+		config.DefaultBatchQueueDirName,
+		config.DefaultMetricsBufferFileName,
+		config.DefaultLastBatchFileName,
+		config.DefaultLastReceiptFileName,
+		config.DefaultPublishKeysFileName,
+	}
+	for _, optionalPath := range []string{cfg.BotPolicy.File, cfg.JsonRpcProxy.Quota.Path} {
+		if len(optionalPath) > 0 && !path.IsAbs(optionalPath) {
+			paths = append(paths, optionalPath)
+		}
+	}
+	// the audit log is moved with the rotated files so that the hash chain stays verifiable
+	if auditPath := cfg.AuditLog.Path; len(auditPath) > 0 && !path.IsAbs(auditPath) {
+		paths = append(paths, auditPath)
+		backups, _ := filepath.Glob(path.Join(cfg.FortaDir, auditPath) + ".*")
+		for _, backup := range backups {
+			if relPath, err := filepath.Rel(cfg.FortaDir, backup); err == nil {
+				paths = append(paths, relPath)
+			}
+		}
+	}
+	return paths
+}
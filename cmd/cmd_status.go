@@ -6,13 +6,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
 	"github.com/spf13/cobra"
 )
 
@@ -82,6 +86,82 @@ func handleFortaStatus(cmd *cobra.Command, format, show string, noColor bool) er
 	return nil
 }
 
+func handleFortaStatusJSON(cmd *cobra.Command) error {
+	allReports := health.NewClient().CheckHealth("forta", config.DefaultHealthPort)
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(healthutils.NodeStatusFromReports(allReports))
+}
+
+func handleFortaStatusHistory(cmd *cobra.Command, format, since string, noColor bool) error {
+	if noColor {
+		color.NoColor = true
+		ballPrefix = ""
+	}
+
+	resp, err := http.Get(fmt.Sprintf(
+		"http://localhost:%s%s?since=%s", config.DefaultHealthPort, healthutils.PathHistory, url.QueryEscape(since),
+	))
+	if err != nil {
+		return fmt.Errorf("failed to get the health history: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to get the health history: %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	var transitions []*healthutils.Transition
+	if err := json.NewDecoder(resp.Body).Decode(&transitions); err != nil {
+		return fmt.Errorf("failed to decode the health history: %v", err)
+	}
+
+	switch format {
+	case StatusFormatPretty, StatusFormatOneline:
+		w := new(bytes.Buffer)
+		for _, transition := range transitions {
+			fmt.Fprintf(w, "%s ", transition.Timestamp.Local().Format(time.RFC3339))
+			writeStatusBall(w, transition.To)
+			writeStatus(w, fmt.Sprintf("%s -> %s", transitionStatus(transition.From), transition.To))
+			fmt.Fprint(w, " | ")
+			writeName(w, transition.Name)
+			if len(transition.Details) > 0 {
+				fmt.Fprint(w, " | ")
+				writeDetails(w, transition.To, transition.Details)
+			}
+			fmt.Fprint(w, "\n")
+		}
+		fmt.Fprint(os.Stdout, w.String())
+
+	case StatusFormatJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(transitions)
+
+	case StatusFormatCSV:
+		w := csv.NewWriter(os.Stdout)
+		defer w.Flush()
+		for _, transition := range transitions {
+			if err := w.Write([]string{
+				transition.Timestamp.Format(time.RFC3339), transition.Name,
+				string(transition.From), string(transition.To), transition.Details,
+			}); err != nil {
+				return fmt.Errorf("failed to write csv record: %v", err)
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown format: %v", format)
+	}
+	return nil
+}
+
+func transitionStatus(status health.Status) string {
+	if len(status) == 0 {
+		return "new"
+	}
+	return string(status)
+}
+
 func formatReportsPretty(reports health.Reports) {
 	w := new(bytes.Buffer)
 	for _, report := range reports {
@@ -0,0 +1,77 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/docker"
+	json_rpc "github.com/forta-network/forta-node/cmd/json-rpc"
+	"github.com/forta-network/forta-node/cmd/scanner"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/services/devmode"
+	log "github.com/sirupsen/logrus"
+)
+
+func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
+	dockerClient, err := docker.NewDockerClient("dev")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the docker client: %v", err)
+	}
+
+	proxySvcs, proxyReporters, err := json_rpc.InitProxyServices(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the proxy: %v", err)
+	}
+	scannerSvcs, scannerReporters, err := scanner.InitScannerServices(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the scanner: %v", err)
+	}
+
+	// a single health server serves the reports of all services
+	svcs := []services.Service{
+		health.NewService(ctx, "", healthutils.DefaultHealthServerErrHandler, health.CheckerFrom(
+			nil, append(proxyReporters, scannerReporters...)...,
+		)),
+		devmode.NewBotRunner(ctx, cfg, dockerClient),
+	}
+	svcs = append(svcs, proxySvcs...)
+	svcs = append(svcs, scannerSvcs...)
+	return svcs, nil
+}
+
+// Run runs the scanner, the JSON-RPC proxy and the development bots in a single process.
+func Run(cfg config.Config) {
+	ctx, cancel := services.InitMainContext(cfg.Shutdown)
+	defer cancel()
+
+	logger := log.WithField("process", "dev")
+	logger.Info("starting")
+	defer logger.Info("exiting")
+
+	// the services connect to nats while they are initialized
+	natsServer, err := devmode.StartNatsServer(cfg.Dev.NatsPort)
+	if err != nil {
+		logger.WithError(err).Error("could not start the nats server")
+		return
+	}
+	defer natsServer.Shutdown()
+
+	serviceList, err := initServices(ctx, cfg)
+	if err != nil {
+		logger.WithError(err).Error("could not initialize services")
+		return
+	}
+
+	err = services.StartServices(ctx, cancel, log.NewEntry(log.StandardLogger()), serviceList)
+	if err == services.ErrExitTriggered {
+		logger.Info("exiting successfully after internal trigger")
+		os.Exit(0)
+	}
+	if err != nil {
+		logger.WithError(err).Error("error running services")
+	}
+}
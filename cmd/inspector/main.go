@@ -9,7 +9,6 @@ import (
 	"github.com/forta-network/forta-core-go/inspect"
 	"github.com/forta-network/forta-core-go/inspect/scorecalc"
 	"github.com/forta-network/forta-core-go/protocol/settings"
-	"github.com/forta-network/forta-core-go/security"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/healthutils"
 	"github.com/forta-network/forta-node/services"
@@ -21,7 +20,7 @@ var nodeConfig config.Config
 func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
 	nodeConfig = cfg
 
-	key, err := security.LoadKey(config.DefaultContainerKeyDirPath)
+	scannerAddress, err := config.LoadAddressInContainer(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +29,7 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		Config:         cfg,
 		ProxyHost:      config.DockerJSONRPCProxyContainerName,
 		ProxyPort:      config.DefaultJSONRPCProxyPort,
-		ScannerAddress: key.Address.String(),
+		ScannerAddress: scannerAddress.String(),
 	})
 	if err != nil {
 		return nil, err
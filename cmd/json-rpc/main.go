@@ -2,36 +2,148 @@ package json_rpc
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/healthutils"
+	"github.com/forta-network/forta-node/logging"
 	"github.com/forta-network/forta-node/services"
+	bot_gateway "github.com/forta-network/forta-node/services/bot-gateway"
+	"github.com/forta-network/forta-node/services/egress"
 	jrp "github.com/forta-network/forta-node/services/json-rpc"
+	protocol_proxy "github.com/forta-network/forta-node/services/protocol-proxy"
 )
 
-func initJsonRpcProxy(ctx context.Context, cfg config.Config) (*jrp.JsonRpcProxy, error) {
-	return jrp.NewJsonRpcProxy(ctx, cfg)
+func initProxies(ctx context.Context, cfg config.Config) ([]*jrp.JsonRpcProxy, []*protocol_proxy.ProtocolProxy, *egress.Proxy, *bot_gateway.Gateway, error) {
+	msgClient := messaging.NewClient("json-rpc", cfg.NatsURL())
+	msgClient.Subscribe(messaging.SubjectLogLevel, messaging.LogLevelHandler(logging.HandleLevelChange))
+
+	botAuthenticator, err := clients.NewBotAuthenticator(ctx, cfg, clients.BotTokenAudienceJSONRPC)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	jsonRpcProxies, err := jrp.NewJsonRpcProxies(ctx, cfg, botAuthenticator, msgClient)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	msgClient.Subscribe(messaging.SubjectConfigReload, messaging.ConfigReloadHandler(reloadProxies(jsonRpcProxies)))
+
+	protocolProxies, err := protocol_proxy.NewProtocolProxies(ctx, cfg.JsonRpcProxy.ProtocolProxies, botAuthenticator, msgClient)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// the bots reach the approved external hosts through this container when their networks are internal
+	var egressProxy *egress.Proxy
+	if cfg.BotEgress.Enable {
+		egressProxy = egress.NewProxy(ctx, cfg.BotEgress, botAuthenticator)
+	}
+
+	var botGateway *bot_gateway.Gateway
+	if cfg.BotEgress.Gateway.Enable {
+		botGateway = bot_gateway.NewGateway(ctx, cfg.BotEgress, botAuthenticator, msgClient)
+	}
+
+	return jsonRpcProxies, protocolProxies, egressProxy, botGateway, nil
 }
 
-func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
+// reloadProxies applies the reloaded config to the proxies.
+func reloadProxies(proxies []*jrp.JsonRpcProxy) messaging.ConfigReloadHandler {
+	return func(report *config.ReloadReport) error {
+		cfg, err := config.GetConfigForContainer()
+		if err != nil {
+			return fmt.Errorf("failed to read the reloaded config: %v", err)
+		}
+		prepareConfig(&cfg)
+		for _, proxy := range proxies {
+			if err := proxy.Reload(cfg, report); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// prepareConfig makes the urls reachable from the container.
+func prepareConfig(cfg *config.Config) {
+	// the services run on the host in the development mode
+	if cfg.Dev.Enable {
+		return
+	}
 	// can't dial localhost - need to dial host gateway from container
 	cfg.Scan.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
 	cfg.JsonRpcProxy.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.JsonRpcProxy.JsonRpc.Url)
+	for i := range cfg.JsonRpcProxy.Upstreams {
+		cfg.JsonRpcProxy.Upstreams[i].Url = utils.ConvertToDockerHostURL(cfg.JsonRpcProxy.Upstreams[i].Url)
+	}
+	if len(cfg.JsonRpcProxy.WebsocketUrl) > 0 {
+		cfg.JsonRpcProxy.WebsocketUrl = utils.ConvertToDockerHostURL(cfg.JsonRpcProxy.WebsocketUrl)
+	}
+	for i, instance := range cfg.JsonRpcProxy.Instances {
+		cfg.JsonRpcProxy.Instances[i].JsonRpc.Url = utils.ConvertToDockerHostURL(instance.JsonRpc.Url)
+		for j := range instance.Upstreams {
+			instance.Upstreams[j].Url = utils.ConvertToDockerHostURL(instance.Upstreams[j].Url)
+		}
+		if len(instance.WebsocketUrl) > 0 {
+			cfg.JsonRpcProxy.Instances[i].WebsocketUrl = utils.ConvertToDockerHostURL(instance.WebsocketUrl)
+		}
+	}
 
-	proxy, err := initJsonRpcProxy(ctx, cfg)
+	for i, protocolProxy := range cfg.JsonRpcProxy.ProtocolProxies {
+		cfg.JsonRpcProxy.ProtocolProxies[i].Url = utils.ConvertToDockerHostURL(protocolProxy.Url)
+	}
+}
+
+func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
+	prepareConfig(&cfg)
+
+	svcs, reporters, err := InitProxyServices(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
-
-	return []services.Service{
+	return append([]services.Service{
 		health.NewService(
 			ctx, "", healthutils.DefaultHealthServerErrHandler,
-			health.CheckerFrom(summarizeReports, proxy),
+			health.CheckerFrom(summarizeReports, reporters...),
 		),
-		proxy,
-	}, nil
+	}, svcs...), nil
+}
+
+// InitProxyServices creates the proxy services and their health reporters.
+func InitProxyServices(ctx context.Context, cfg config.Config) ([]services.Service, []health.Reporter, error) {
+	// the bots use the proxies until their evaluations are drained
+	proxies, protocolProxies, egressProxy, botGateway, err := initProxies(services.StageContext(ctx, services.StageProxy), cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		svcs      []services.Service
+		reporters []health.Reporter
+	)
+	for _, proxy := range proxies {
+		svcs = append(svcs, proxy)
+		reporters = append(reporters, proxy)
+	}
+	for _, protocolProxy := range protocolProxies {
+		svcs = append(svcs, protocolProxy)
+		reporters = append(reporters, protocolProxy)
+	}
+	if egressProxy != nil {
+		svcs = append(svcs, egressProxy)
+		reporters = append(reporters, egressProxy)
+	}
+	if botGateway != nil {
+		svcs = append(svcs, botGateway)
+		reporters = append(reporters, botGateway)
+	}
+	return svcs, reporters, nil
 }
 
 func summarizeReports(reports health.Reports) *health.Report {
@@ -42,6 +154,33 @@ func summarizeReports(reports health.Reports) *health.Report {
 		summary.Addf("last time the api failed with error '%s'.", apiErr.Details)
 	}
 
+	// lagging is reported first so that the failures override it
+	for _, report := range reports {
+		if strings.Contains(report.Name, "service.json-rpc-proxy.probe.") && report.Status == health.StatusLagging {
+			summary.Addf("%s is %s.", report.Name[strings.LastIndex(report.Name, "probe."):], report.Details)
+			summary.Status(health.StatusLagging)
+		}
+	}
+
+	circuit, ok := reports.NameContains("service.json-rpc-proxy.circuit")
+	if ok && circuit.Status != health.StatusOK {
+		summary.Addf("upstream circuit is %s.", circuit.Details)
+		summary.Status(health.StatusFailing)
+	}
+
+	for _, report := range reports {
+		if strings.Contains(report.Name, "service.json-rpc-proxy.upstream.") && len(report.Details) > 0 {
+			summary.Addf("%s is failing with error '%s'.", report.Name[strings.LastIndex(report.Name, "upstream."):], report.Details)
+			summary.Status(health.StatusFailing)
+		}
+	}
+
+	for _, report := range reports {
+		if strings.Contains(report.Name, "service.protocol-proxy-") && strings.HasSuffix(report.Name, ".api") && len(report.Details) > 0 {
+			summary.Addf("last time %s failed with error '%s'.", strings.TrimSuffix(strings.TrimPrefix(report.Name, "service."), ".api"), report.Details)
+		}
+	}
+
 	return summary.Finish()
 }
 
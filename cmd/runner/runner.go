@@ -39,7 +39,7 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 
 // Run runs the runner.
 func Run(cfg config.Config) {
-	ctx, cancel := services.InitMainContext()
+	ctx, cancel := services.InitMainContext(cfg.Shutdown)
 	defer cancel()
 
 	logger := log.WithField("process", "runner")
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -16,8 +17,8 @@ import (
 	"github.com/forta-network/forta-node/services/publisher"
 	log "github.com/sirupsen/logrus"
 
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	gethlog "github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/ethereum"
@@ -25,18 +26,28 @@ import (
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/clients/traces"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/healthutils"
+	"github.com/forta-network/forta-node/logging"
 	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/scanner"
+	"github.com/forta-network/forta-node/tracing"
 )
 
 func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, cfg config.Config) (*scanner.TxStreamService, feeds.BlockFeed, error) {
-	cfg.Scan.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
-	cfg.JsonRpcProxy.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
-	cfg.Registry.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Registry.JsonRpc.Url)
-	cfg.Registry.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.APIURL)
-	cfg.Registry.IPFS.GatewayURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.GatewayURL)
+	if !cfg.Dev.Enable {
+		cfg.Scan.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
+		cfg.JsonRpcProxy.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
+		cfg.Registry.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Registry.JsonRpc.Url)
+		cfg.Registry.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.APIURL)
+		cfg.Registry.IPFS.GatewayURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.GatewayURL)
+		cfg.Registry.IPFS.LocalNodeAPIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.LocalNodeAPIURL)
+		for i, gatewayURL := range cfg.Registry.IPFS.GatewayURLs {
+			cfg.Registry.IPFS.GatewayURLs[i] = utils.ConvertToDockerHostURL(gatewayURL)
+		}
+	}
 
 	url := cfg.Scan.JsonRpc.Url
 	chainID := config.ParseBigInt(cfg.ChainID)
@@ -54,9 +65,10 @@ func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, c
 	}
 
 	var maxAgePtr *time.Duration
-	// support scanning old block ranges in local mode
+	// support scanning old block ranges in local mode and backfilling
 	hasLocalModeBlockRange := cfg.LocalModeConfig.Enable && cfg.LocalModeConfig.RuntimeLimits.StopBlock != nil
-	if !hasLocalModeBlockRange && cfg.Scan.BlockMaxAgeSeconds > 0 {
+	isBackfilling := !cfg.LocalModeConfig.Enable && cfg.Scan.StartBlock != nil
+	if !hasLocalModeBlockRange && !isBackfilling && cfg.Scan.BlockMaxAgeSeconds > 0 {
 		maxAge := time.Duration(cfg.Scan.BlockMaxAgeSeconds) * time.Second
 		maxAgePtr = &maxAge
 	}
@@ -74,6 +86,9 @@ func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, c
 			stopBlock = big.NewInt(0).SetUint64(*runtimeLimits.StopBlock)
 		}
 	}
+	if isBackfilling {
+		startBlock = big.NewInt(0).SetUint64(*cfg.Scan.StartBlock)
+	}
 
 	if startBlock != nil && stopBlock != nil && !(stopBlock.Cmp(startBlock) > 0) {
 		log.Fatal("stop block is not greater than the start block - please check the runtime limits")
@@ -81,7 +96,7 @@ func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, c
 
 	ethClient.SetRetryInterval(time.Second * time.Duration(cfg.Scan.RetryIntervalSeconds))
 
-	blockFeed, err := feeds.NewBlockFeed(ctx, ethClient, traceClient, feeds.BlockFeedConfig{
+	blockFeedCfg := feeds.BlockFeedConfig{
 		ChainID:             chainID,
 		Tracing:             cfg.Trace.Enabled,
 		RateLimit:           rateLimit,
@@ -89,7 +104,25 @@ func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, c
 		Offset:              getBlockOffset(cfg),
 		Start:               startBlock,
 		End:                 stopBlock,
-	})
+	}
+	var (
+		blockFeed feeds.BlockFeed
+		err       error
+	)
+	if cfg.Scan.Enrichment.IsEnabled() {
+		// enrich the blocks concurrently and get the receipts with batched calls
+		var batcher *rpc.Client
+		if cfg.Scan.Enrichment.ReceiptBatchSize > 0 {
+			batcher, err = ethereum.NewRpcClient(ctx, url)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create the receipt batching client: %v", err)
+			}
+			batcher.SetHeader("Content-Type", "application/json")
+		}
+		blockFeed, err = scanner.NewConcurrentBlockFeed(ctx, ethClient, traceClient, batcher, blockFeedCfg, cfg.Scan.Enrichment)
+	} else {
+		blockFeed, err = feeds.NewBlockFeed(ctx, ethClient, traceClient, blockFeedCfg)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -132,9 +165,13 @@ func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, c
 	return txStream, blockFeed, nil
 }
 
-// getBlockOffset either returns the default offset configured for the chain or
-// the safe offset if required.
+// getBlockOffset returns the configured confirmations, the default offset configured
+// for the chain or the safe offset if required.
 func getBlockOffset(cfg config.Config) int {
+	if cfg.Scan.Confirmations != nil {
+		return *cfg.Scan.Confirmations
+	}
+
 	chainSettings := settings.GetChainSettings(cfg.ChainID)
 
 	if cfg.AdvancedConfig.SafeOffset {
@@ -166,8 +203,10 @@ func initCombinationStream(ctx context.Context, msgClient clients.MessageClient,
 
 	combinerStream, err := scanner.NewCombinerAlertStreamService(
 		ctx, combinerFeed, msgClient, scanner.CombinerAlertStreamServiceConfig{
-			Start: cfg.LocalModeConfig.RuntimeLimits.StartCombiner,
-			End:   cfg.LocalModeConfig.RuntimeLimits.StopCombiner,
+			Start:         cfg.LocalModeConfig.RuntimeLimits.StartCombiner,
+			End:           cfg.LocalModeConfig.RuntimeLimits.StopCombiner,
+			RateLimit:     cfg.CombinerConfig.AlertRateLimit,
+			BotRateLimits: cfg.CombinerConfig.BotAlertRateLimits,
 		},
 	)
 	if err != nil {
@@ -211,11 +250,13 @@ func initTxAnalyzer(
 	ctx context.Context, cfg config.Config,
 	as clients.AlertSender, stream *scanner.TxStreamService,
 	botProcessingComponents components.BotProcessing, msgClient clients.MessageClient,
+	backfill *scanner.BackfillService,
 ) (*scanner.TxAnalyzerService, error) {
 	return scanner.NewTxAnalyzerService(ctx, scanner.TxAnalyzerServiceConfig{
 		TxChannel:     stream.ReadOnlyTxStream(),
 		AlertSender:   as,
 		MsgClient:     msgClient,
+		Backfill:      backfill,
 		BotProcessing: botProcessingComponents,
 	})
 }
@@ -224,11 +265,13 @@ func initBlockAnalyzer(
 	ctx context.Context, cfg config.Config,
 	as clients.AlertSender, stream *scanner.TxStreamService,
 	botProcessingComponents components.BotProcessing, msgClient clients.MessageClient,
+	backfill *scanner.BackfillService,
 ) (*scanner.BlockAnalyzerService, error) {
 	return scanner.NewBlockAnalyzerService(ctx, scanner.BlockAnalyzerServiceConfig{
 		BlockChannel:  stream.ReadOnlyBlockStream(),
 		AlertSender:   as,
 		MsgClient:     msgClient,
+		Backfill:      backfill,
 		BotProcessing: botProcessingComponents,
 	})
 }
@@ -249,60 +292,99 @@ func initCombinerAlertAnalyzer(
 	)
 }
 
-func initAlertSender(ctx context.Context, key *keystore.Key, pubClient clients.PublishClient, cfg config.Config) (clients.AlertSender, error) {
+func initAlertSender(ctx context.Context, scannerSigner signer.Signer, pubClient clients.PublishClient, cfg config.Config) (clients.AlertSender, error) {
 	ds, err := store.NewDeduplicationStore(cfg)
 	if err != nil {
 		return nil, err
 	}
 	return clients.NewAlertSender(ctx, pubClient, clients.AlertSenderConfig{
-		Key: key,
-		DS:  ds,
+		Signer: scannerSigner,
+		DS:     ds,
 	})
 }
 
-func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
+// prepareConfig makes the urls reachable from the container.
+func prepareConfig(cfg *config.Config) {
+	// the services run on the host in the development mode
+	if cfg.Dev.Enable {
+		return
+	}
 	// can't dial localhost - need to dial host gateway from container
 	cfg.Scan.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
 	cfg.Trace.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Trace.JsonRpc.Url)
 	cfg.Registry.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Registry.JsonRpc.Url)
 	cfg.Registry.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.APIURL)
 	cfg.Registry.IPFS.GatewayURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.GatewayURL)
+	cfg.Registry.IPFS.LocalNodeAPIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.LocalNodeAPIURL)
+	for i, gatewayURL := range cfg.Registry.IPFS.GatewayURLs {
+		cfg.Registry.IPFS.GatewayURLs[i] = utils.ConvertToDockerHostURL(gatewayURL)
+	}
 	cfg.Publish.APIURL = utils.ConvertToDockerHostURL(cfg.Publish.APIURL)
 	cfg.Publish.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Publish.IPFS.APIURL)
 	cfg.Publish.IPFS.GatewayURL = utils.ConvertToDockerHostURL(cfg.Publish.IPFS.GatewayURL)
 	cfg.LocalModeConfig.WebhookURL = utils.ConvertToDockerHostURL(cfg.LocalModeConfig.WebhookURL)
 	cfg.CombinerConfig.AlertAPIURL = utils.ConvertToDockerHostURL(cfg.CombinerConfig.AlertAPIURL)
 	cfg.PublicAPIProxy.Url = utils.ConvertToDockerHostURL(cfg.PublicAPIProxy.Url)
-	msgClient := messaging.NewClient("scanner", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
+	cfg.Scan.Mempool.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.Mempool.JsonRpc.Url)
+}
 
-	key, err := config.LoadKeyInContainer(cfg)
+func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
+	prepareConfig(&cfg)
+	svcs, healthReporters, err := InitScannerServices(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
+	return append([]services.Service{
+		health.NewService(ctx, "", healthutils.DefaultHealthServerErrHandler, health.CheckerFrom(
+			summarizeReports, healthReporters...,
+		)),
+	}, svcs...), nil
+}
+
+// InitScannerServices creates the scanner services and their health reporters.
+func InitScannerServices(ctx context.Context, cfg config.Config) ([]services.Service, []health.Reporter, error) {
+	msgClient := messaging.NewClient("scanner", cfg.NatsURL())
+	msgClient.Subscribe(messaging.SubjectLogLevel, messaging.LogLevelHandler(logging.HandleLevelChange))
+
+	scannerSigner, err := config.LoadSignerInContainer(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	publisherSvc, err := publisher.NewPublisher(ctx, cfg)
+	// the publisher publishes the findings of the drained evaluations after the intake stops
+	publisherSvc, err := publisher.NewPublisher(services.StageContext(ctx, services.StagePublish), cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create publisher: %v", err)
+		return nil, nil, fmt.Errorf("failed to create publisher: %v", err)
 	}
 
-	alertSender, err := initAlertSender(ctx, key, publisherSvc, cfg)
+	alertSender, err := initAlertSender(ctx, scannerSigner, publisherSvc, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize alert sender: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialize alert sender: %v", err)
 	}
+	// suppress the duplicate and the excessive alerts before signing them
+	alertFilter := scanner.NewAlertFilter(alertSender, msgClient, cfg.Publish.AlertFilter)
 
 	ethClient, err := ethereum.NewStreamEthClient(ctx, "chain", cfg.Scan.JsonRpc.Url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stream eth client: %v", err)
+		return nil, nil, fmt.Errorf("failed to create stream eth client: %v", err)
 	}
 
-	traceClient, err := ethereum.NewStreamEthClient(ctx, "trace", cfg.Trace.JsonRpc.Url)
+	streamTraceClient, err := ethereum.NewStreamEthClient(ctx, "trace", cfg.Trace.JsonRpc.Url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create trace stream eth client: %v", err)
+		return nil, nil, fmt.Errorf("failed to create trace stream eth client: %v", err)
+	}
+	var traceClient ethereum.Client = streamTraceClient
+	if cfg.Trace.Enabled {
+		// get the traces from the supported trace api
+		traceClient, err = traces.NewClient(ctx, streamTraceClient, cfg.Trace.JsonRpc.Url, cfg.Trace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create trace client: %v", err)
+		}
 	}
 
 	txStream, blockFeed, err := initTxStream(ctx, ethClient, traceClient, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create tx stream: %v", err)
+		return nil, nil, fmt.Errorf("failed to create tx stream: %v", err)
 	}
 
 	var waitBots int
@@ -317,20 +399,30 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		}
 	}
 
-	botProcessingComponents, err := components.GetBotProcessingComponents(ctx, components.BotProcessingConfig{
+	// the bots finish the evaluations of the received events after the intake stops
+	botProcessingComponents, err := components.GetBotProcessingComponents(services.StageContext(ctx, services.StageEvaluations), components.BotProcessingConfig{
 		Config:        cfg,
 		MessageClient: msgClient,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create bot processing components: %v", err)
-	}
-	txAnalyzer, err := initTxAnalyzer(ctx, cfg, alertSender, txStream, botProcessingComponents, msgClient)
+		return nil, nil, fmt.Errorf("failed to create bot processing components: %v", err)
+	}
+	// scan the historical block ranges through the running bots on request
+	backfill := scanner.NewBackfillService(ctx, scanner.BackfillServiceConfig{
+		EthClient:   ethClient,
+		TraceClient: traceClient,
+		Sender:      botProcessingComponents.RequestSender,
+		ChainID:     config.ParseBigInt(cfg.ChainID),
+		Tracing:     cfg.Trace.Enabled,
+	})
+
+	txAnalyzer, err := initTxAnalyzer(ctx, cfg, alertFilter, txStream, botProcessingComponents, msgClient, backfill)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize tx analyzer: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialize tx analyzer: %v", err)
 	}
-	blockAnalyzer, err := initBlockAnalyzer(ctx, cfg, alertSender, txStream, botProcessingComponents, msgClient)
+	blockAnalyzer, err := initBlockAnalyzer(ctx, cfg, alertFilter, txStream, botProcessingComponents, msgClient, backfill)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize block analyzer: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialize block analyzer: %v", err)
 	}
 
 	// Start the main block feed so all transaction feeds can start consuming.
@@ -340,31 +432,95 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 
 	combinationStream, combinationFeed, err := initCombinationStream(ctx, msgClient, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize combiner stream: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialize combiner stream: %v", err)
 	}
 
-	combinationAnalyzer, err := initCombinerAlertAnalyzer(ctx, cfg, alertSender, combinationStream, botProcessingComponents, msgClient)
+	combinationAnalyzer, err := initCombinerAlertAnalyzer(ctx, cfg, alertFilter, combinationStream, botProcessingComponents, msgClient)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize combiner analyzer: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialize combiner analyzer: %v", err)
+	}
+
+	healthReporters := []health.Reporter{
+		ethClient, traceClient, combinationFeed, blockFeed, txStream,
+		txAnalyzer, blockAnalyzer, combinationAnalyzer,
+		botProcessingComponents.RequestSender,
+		publisherSvc, alertFilter,
+	}
+
+	// send the reorg events to the bots which opt in
+	var reorgTracker *scanner.ReorgTracker
+	if cfg.Scan.MaxReorgDepth > 0 {
+		reorgTracker = scanner.NewReorgTracker(ctx, blockFeed, ethClient, botProcessingComponents.RequestSender, scanner.ReorgTrackerConfig{
+			ChainID:  config.ParseBigInt(cfg.ChainID),
+			MaxDepth: cfg.Scan.MaxReorgDepth,
+		})
+		healthReporters = append(healthReporters, reorgTracker)
+	}
+
+	// send the pending txs to the bots which opt in
+	var mempoolFeed *scanner.MempoolFeed
+	if cfg.Scan.Mempool.Enable {
+		mempoolCfg := cfg.Scan.Mempool
+		if len(mempoolCfg.JsonRpc.Url) == 0 {
+			mempoolCfg.JsonRpc = cfg.Scan.JsonRpc
+		}
+		mempoolFeed = scanner.NewMempoolFeed(ctx, blockFeed, botProcessingComponents.RequestSender, scanner.MempoolFeedConfig{
+			ChainID:       config.ParseBigInt(cfg.ChainID),
+			MempoolConfig: mempoolCfg,
+		})
+		healthReporters = append(healthReporters, mempoolFeed)
+	}
+
+	// persist the last scanned block and backfill the missed blocks
+	var checkpointer *scanner.Checkpointer
+	if !cfg.LocalModeConfig.Enable && cfg.Scan.MaxGapBlocks > 0 {
+		checkpointer = scanner.NewCheckpointer(ctx, blockFeed, backfill, msgClient, scanner.CheckpointerConfig{
+			ChainID:      cfg.ChainID,
+			Path:         path.Join(cfg.FortaDir, config.DefaultCheckpointFileName),
+			MaxGapBlocks: cfg.Scan.MaxGapBlocks,
+		})
+		healthReporters = append(healthReporters, checkpointer)
+	}
+
+	if botProcessingComponents.PayloadStore != nil {
+		healthReporters = append(healthReporters, botProcessingComponents.PayloadStore)
 	}
 
 	svcs := []services.Service{
-		health.NewService(ctx, "", healthutils.DefaultHealthServerErrHandler, health.CheckerFrom(
-			summarizeReports,
-			ethClient, traceClient, combinationFeed, blockFeed, txStream,
-			txAnalyzer, blockAnalyzer, combinationAnalyzer,
-			botProcessingComponents.RequestSender,
-			publisherSvc,
-		)),
+		// start before the services which create spans
+		tracing.NewService(ctx, cfg.Tracing, "scanner"),
 		txStream,
 		txAnalyzer,
 		blockAnalyzer,
 		combinationStream,
 		combinationAnalyzer,
 		publisherSvc,
+		backfill,
+	}
+
+	if reorgTracker != nil {
+		svcs = append(svcs, reorgTracker)
+	}
+
+	if mempoolFeed != nil {
+		svcs = append(svcs, mempoolFeed)
+	}
+
+	if checkpointer != nil {
+		svcs = append(svcs, checkpointer)
+	}
+
+	// serve the large requests to the bots which fetch them by reference
+	if botProcessingComponents.PayloadStore != nil {
+		svcs = append(svcs, botProcessingComponents.PayloadStore)
+	}
+
+	// serve the recently scanned blocks to the json-rpc proxy
+	if cfg.JsonRpcProxy.LocalData.Enable {
+		svcs = append(svcs, scanner.NewBlockDataService(ctx, blockFeed, cfg.JsonRpcProxy.LocalData))
 	}
 
-	return svcs, nil
+	return svcs, healthReporters, nil
 }
 
 func summarizeReports(reports health.Reports) *health.Report {
@@ -10,6 +10,7 @@ import (
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/healthutils"
+	"github.com/forta-network/forta-node/profiling"
 	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components"
 	"github.com/forta-network/forta-node/services/components/registry"
@@ -20,29 +21,34 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 	cfg.Registry.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Registry.JsonRpc.Url)
 	cfg.Registry.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.APIURL)
 	cfg.Registry.IPFS.GatewayURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.GatewayURL)
+	cfg.Registry.IPFS.LocalNodeAPIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.LocalNodeAPIURL)
+	for i, gatewayURL := range cfg.Registry.IPFS.GatewayURLs {
+		cfg.Registry.IPFS.GatewayURLs[i] = utils.ConvertToDockerHostURL(gatewayURL)
+	}
 	cfg.AgentLogsConfig.URL = utils.ConvertToDockerHostURL(cfg.AgentLogsConfig.URL)
 
 	passphrase, err := security.ReadPassphrase()
 	if err != nil {
 		return nil, err
 	}
-	key, err := security.LoadKey(config.DefaultContainerKeyDirPath)
+	scannerSigner, err := config.LoadSignerInContainer(cfg)
 	if err != nil {
 		return nil, err
 	}
-	botRegistry, err := registry.New(cfg, key.Address)
+	botRegistry, err := registry.New(cfg, scannerSigner.Address())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the bot registry: %v", err)
 	}
 	botLifecycleConfig := components.BotLifecycleConfig{
 		Config:         cfg,
-		ScannerAddress: key.Address,
+		ScannerAddress: scannerSigner.Address(),
+		Signer:         scannerSigner,
 		BotRegistry:    botRegistry,
 	}
 	svc, err := supervisor.NewSupervisorService(ctx, supervisor.SupervisorServiceConfig{
 		Config:             cfg,
 		Passphrase:         passphrase,
-		Key:                key,
+		Signer:             scannerSigner,
 		BotLifecycleConfig: botLifecycleConfig,
 	})
 	if err != nil {
@@ -54,6 +60,7 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 			health.CheckerFrom(summarizeReports, svc, botRegistry),
 		),
 		svc,
+		profiling.NewCapturer(ctx, cfg),
 	}, nil
 }
 
@@ -71,6 +78,35 @@ func summarizeReports(reports health.Reports) *health.Report {
 		}
 	}
 
+	stake, ok := reports.NameContains("stake.allocated")
+	if ok {
+		summary.Addf("allocated stake is %s.", stake.Details)
+	}
+	assignedBots, ok := reports.NameContains("stake.assigned-bots")
+	if ok {
+		summary.Addf("%s bots are assigned.", assignedBots.Details)
+	}
+	slaScore, ok := reports.NameContains("sla.score")
+	if ok {
+		summary.Addf("sla score is %s.", slaScore.Details)
+	}
+	readinessScore, ok := reports.NameContains("readiness.score")
+	if ok {
+		summary.Addf("readiness score is %s.", readinessScore.Details)
+	}
+	notRegistered, ok := reports.NameContains("stake.registered")
+	if ok {
+		summary.Addf("%s.", notRegistered.Details)
+	}
+
+	manageErr, ok := reports.NameContains("bot-management.error")
+	if ok && len(manageErr.Details) > 0 {
+		summary.Addf("failed to manage the bots: %s.", manageErr.Details)
+		if manageErr.Status == health.StatusFailing {
+			summary.Status(health.StatusFailing)
+		}
+	}
+
 	telemetryErr, ok := reports.NameContains("telemetry-sync.error")
 	if ok && len(telemetryErr.Details) > 0 {
 		summary.Addf("telemetry sync is failing with error '%s' (non-critical).", telemetryErr.Details)
@@ -20,13 +20,12 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-const minUpdateInterval = 1 * time.Minute
-const maxUpdateInterval = 24 * time.Hour
-
-func generateIntervalMs(addr string) int64 {
-	interval := big.NewInt(0)
-	interval.Mod(utils.ScannerIDHexToBigInt(addr), big.NewInt((maxUpdateInterval).Milliseconds()))
-	return interval.Int64() + minUpdateInterval.Milliseconds()
+// generateRolloutBucket places the node in one of the 100 buckets of the staged rollout
+// so that all scanners don't update simultaneously.
+func generateRolloutBucket(addr string) int {
+	bucket := big.NewInt(0)
+	bucket.Mod(utils.ScannerIDHexToBigInt(addr), big.NewInt(100))
+	return int(bucket.Int64())
 }
 
 type keyAddress struct {
@@ -70,11 +69,18 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		return nil, err
 	}
 
-	intervalMs := generateIntervalMs(address)
-	updateDelay := int(intervalMs / 1000)
+	// the staged rollout spreads the updates unless a delay is configured
+	updateDelay := 0
+	rolloutBucket := generateRolloutBucket(address)
 	if cfg.AutoUpdate.UpdateDelay != nil {
 		updateDelay = *cfg.AutoUpdate.UpdateDelay
+		rolloutBucket = -1
 	}
+	log.WithFields(log.Fields{
+		"channel":       cfg.AutoUpdate.Channel,
+		"pinnedVersion": cfg.AutoUpdate.PinnedVersion,
+		"rolloutBucket": rolloutBucket,
+	}).Info("auto-update settings")
 
 	srs, err := store.NewScannerReleaseStore(ctx, cfg)
 	if err != nil {
@@ -83,6 +89,7 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 
 	updaterService := updater.NewUpdaterService(
 		ctx, srs, config.DefaultContainerPort, updateDelay, cfg.AutoUpdate.CheckIntervalSeconds,
+		cfg.AutoUpdate.PinnedVersion, rolloutBucket,
 	)
 
 	return []services.Service{
@@ -21,9 +21,113 @@ type AgentConfig struct {
 	StartBlock   *uint64 `yaml:"startBlock" json:"startBlock,omitempty"`
 	StopBlock    *uint64 `yaml:"stopBlock" json:"stopBlock,omitempty"`
 	Owner        string  `yaml:"owner" json:"owner"`
+	// Name is provisioned from the bot manifest.
+	Name string `yaml:"name" json:"name,omitempty"`
+	// Address is dialed instead of the bot container in the development mode.
+	Address string `yaml:"address" json:"address,omitempty"`
+
+	ChainID      int
+	ShardConfig  *ShardConfig
+	Dependencies []BotDependency `yaml:"dependencies" json:"dependencies,omitempty"`
+	// ChainIDs are the chains declared in the bot manifest. The bot supports all chains if empty.
+	ChainIDs []int `yaml:"chainIds" json:"chainIds,omitempty"`
+	// JsonRpcRateLimit is provisioned from the bot manifest and can be overridden by the node config.
+	JsonRpcRateLimit *RateLimitConfig `yaml:"jsonRpcRateLimit" json:"jsonRpcRateLimit,omitempty"`
+	// RequestLimits is provisioned from the bot manifest and can be overridden by the node config.
+	RequestLimits *BotRequestLimits `yaml:"requestLimits" json:"requestLimits,omitempty"`
+	// TraceContext is provisioned from the bot manifest and enables sending the trace context to the bot.
+	TraceContext bool `yaml:"traceContext" json:"traceContext,omitempty"`
+	// ReorgEvents is provisioned from the bot manifest and enables sending the reorg events to the bot.
+	ReorgEvents bool `yaml:"reorgEvents" json:"reorgEvents,omitempty"`
+	// MempoolEvents is provisioned from the bot manifest and enables sending the pending txs to the bot.
+	MempoolEvents bool `yaml:"mempoolEvents" json:"mempoolEvents,omitempty"`
+	// TxFilter is provisioned from the bot manifest and selects the txs which are sent to the bot.
+	TxFilter *BotTxFilter `yaml:"txFilter" json:"txFilter,omitempty"`
+	// BestEffort is provisioned from the bot manifest and makes the bot shed load first when the node is overloaded.
+	BestEffort bool `yaml:"bestEffort" json:"bestEffort,omitempty"`
+	// EgressHosts is provisioned from the bot manifest and lists the external hosts which the bot connects to.
+	// The bot can reach only the ones approved by the operator if the egress policy is enabled.
+	EgressHosts []string `yaml:"egressHosts" json:"egressHosts,omitempty"`
+	// Capabilities is provisioned from the bot manifest. It is nil if the bot does not declare any.
+	Capabilities *BotCapabilities `yaml:"capabilities" json:"capabilities,omitempty"`
+}
+
+// BotCapabilities are the capability flags which the bot declares in its manifest.
+type BotCapabilities struct {
+	// Traces tells if the bot needs the trace API.
+	Traces bool `yaml:"traces" json:"traces,omitempty"`
+	// Mempool tells if the bot needs the pending txs.
+	Mempool bool `yaml:"mempool" json:"mempool,omitempty"`
+	// HealthChecks tells if the bot serves the gRPC health checks.
+	HealthChecks bool `yaml:"healthChecks" json:"healthChecks,omitempty"`
+	// Sharding tells if the bot can process a shard of the blocks.
+	Sharding bool `yaml:"sharding" json:"sharding,omitempty"`
+	// MaxBlockLag is how many blocks behind the latest block the bot still wants to process.
+	// The bot receives all blocks if zero.
+	MaxBlockLag uint64 `yaml:"maxBlockLag" json:"maxBlockLag,omitempty"`
+	// PayloadRefs tells if the bot can fetch the large requests from the node-local store when
+	// it receives a reference instead of the full request.
+	PayloadRefs bool `yaml:"payloadRefs" json:"payloadRefs,omitempty"`
+}
+
+// NeedsTraces tells if the bot declares that it needs the trace API.
+func (ac *AgentConfig) NeedsTraces() bool {
+	return ac.Capabilities != nil && ac.Capabilities.Traces
+}
+
+// SupportsHealthChecks tells if the bot declares that it serves the gRPC health checks.
+func (ac *AgentConfig) SupportsHealthChecks() bool {
+	return ac.Capabilities != nil && ac.Capabilities.HealthChecks
+}
+
+// SupportsSharding tells if the bot can be sharded. The bots which do not declare their capabilities
+// are assumed to support it as before.
+func (ac *AgentConfig) SupportsSharding() bool {
+	return ac.Capabilities == nil || ac.Capabilities.Sharding
+}
+
+// SupportsPayloadRefs tells if the bot declares that it can fetch the large requests by reference.
+func (ac *AgentConfig) SupportsPayloadRefs() bool {
+	return ac.Capabilities != nil && ac.Capabilities.PayloadRefs
+}
+
+// MaxBlockLag returns the block lag tolerated by the bot. It is zero if the bot tolerates any lag.
+func (ac *AgentConfig) MaxBlockLag() uint64 {
+	if ac.Capabilities == nil {
+		return 0
+	}
+	return ac.Capabilities.MaxBlockLag
+}
+
+// BotTxFilter selects the txs which involve any of the addresses or emit a log with any
+// of the topics. The bot receives all txs if both are empty.
+type BotTxFilter struct {
+	Addresses []string `yaml:"addresses" json:"addresses,omitempty"`
+	Topics    []string `yaml:"topics" json:"topics,omitempty"`
+}
+
+// BotRequestLimits limit the evaluation requests sent to a bot. The zero values are
+// inherited from the node defaults.
+type BotRequestLimits struct {
+	// TimeoutSeconds is how long to wait for a response.
+	TimeoutSeconds int `yaml:"timeoutSeconds" json:"timeoutSeconds,omitempty" validate:"min=0"`
+	// MaxInFlight is how many requests of the same kind can be waiting for a response.
+	MaxInFlight int `yaml:"maxInFlight" json:"maxInFlight,omitempty" validate:"min=0"`
+	// QueueDepth is how many requests of the same kind can be queued before dropping the new ones.
+	QueueDepth int `yaml:"queueDepth" json:"queueDepth,omitempty" validate:"min=0"`
+}
+
+// BotDependency is an auxiliary container which is started before the bot
+// and is reachable only from the bot's network.
+type BotDependency struct {
+	Name  string            `yaml:"name" json:"name"`
+	Image string            `yaml:"image" json:"image"`
+	Env   map[string]string `yaml:"env" json:"env,omitempty"`
+}
 
-	ChainID     int
-	ShardConfig *ShardConfig
+// EnvHostName returns the name of the env var which tells the bot the host of the dependency.
+func (dep BotDependency) EnvHostName() string {
+	return fmt.Sprintf(EnvFortaBotDependencyHostFmt, strings.ToUpper(strings.ReplaceAll(dep.Name, "-", "_")))
 }
 
 type ShardConfig struct {
@@ -61,6 +165,19 @@ func (ac AgentConfig) Equal(b AgentConfig) bool {
 	return sameShardID && sameShardCount
 }
 
+// SupportsChain tells if the bot can run on the given chain.
+func (ac *AgentConfig) SupportsChain(chainID int) bool {
+	if len(ac.ChainIDs) == 0 {
+		return true
+	}
+	for _, botChainID := range ac.ChainIDs {
+		if botChainID == chainID {
+			return true
+		}
+	}
+	return false
+}
+
 // IsSharded tells if this is a sharded bot.
 func (ac *AgentConfig) IsSharded() bool {
 	return ac.ShardConfig != nil && ac.ShardConfig.Shards > 1
@@ -86,15 +203,40 @@ func (ac AgentConfig) ContainerName() string {
 		// the container is already running - don't mess with the name
 		return ac.ID
 	}
-	if ac.IsLocal {
-		return fmt.Sprintf("%s-agent-%s", ContainerNamePrefix, utils.ShortenString(ac.ID, 8))
+	data := BotContainerNameData{
+		Prefix:   ContainerNamePrefix,
+		Instance: botContainerNaming.instance,
+		BotID:    utils.ShortenString(ac.ID, 8),
+		Sharded:  ac.IsSharded(),
 	}
-	_, digest := utils.SplitImageRef(ac.Image)
-	return fmt.Sprintf(
-		"%s-agent-%s-%s", ContainerNamePrefix, utils.ShortenString(ac.ID, 8), utils.ShortenString(digest, 4),
-	)
+	if !ac.IsLocal {
+		_, digest := utils.SplitImageRef(ac.Image)
+		data.Digest = utils.ShortenString(digest, 4)
+	}
+	if ac.ShardConfig != nil {
+		data.ShardID = ac.ShardConfig.ShardID
+	}
+	name, err := execBotContainerName(botContainerNaming.tmpl, data)
+	if err != nil {
+		// the template is checked when it is set so this should not happen
+		name, _ = execBotContainerName(defaultBotContainerNameTmpl, data)
+	}
+	return name
+}
+
+// DependencyContainerName returns the container name for a dependency of this bot.
+func (ac AgentConfig) DependencyContainerName(dep BotDependency) string {
+	return fmt.Sprintf("%s-%s", ac.ContainerName(), dep.Name)
 }
 
 func (ac AgentConfig) GrpcPort() string {
 	return AgentGrpcPort
 }
+
+// GrpcAddress returns the address to dial the bot.
+func (ac AgentConfig) GrpcAddress() string {
+	if len(ac.Address) > 0 {
+		return ac.Address
+	}
+	return fmt.Sprintf("%s:%s", ac.ContainerName(), ac.GrpcPort())
+}
@@ -14,6 +14,32 @@ func TestAgentConfig_ContainerName(t *testing.T) {
 	assert.Equal(t, "forta-agent-0x04f65c-de86", cfg.ContainerName())
 }
 
+func TestAgentConfig_ContainerNameTemplate(t *testing.T) {
+	defer SetContainerNaming(ContainerNamingConfig{})
+
+	cfg := AgentConfig{
+		ID:          "0x04f65c638f234548104790d7c692c9273d41f82d784b174ff2fdc3e8e5bf1636",
+		Image:       "bafybeibvkqkf7i3c5ouehviwjb2dzbukgqied3cg36axl7gzm23r6ielnu@sha256:de866feeb97cba4cad6343c4137cb48bc798be0136015bec16d97c8ef28852b9",
+		ShardConfig: &ShardConfig{ShardID: 2, Shards: 3},
+	}
+
+	assert.NoError(t, SetContainerNaming(ContainerNamingConfig{Instance: "node2"}))
+	assert.Equal(t, "forta-agent-node2-0x04f65c-de86", cfg.ContainerName())
+	assert.Equal(t, "node2", ContainerNamingInstance())
+
+	assert.NoError(t, SetContainerNaming(ContainerNamingConfig{
+		Instance:    "node2",
+		BotTemplate: "{{.Instance}}-bot-{{.BotID}}{{if .Sharded}}-s{{.ShardID}}{{end}}",
+	}))
+	assert.Equal(t, "node2-bot-0x04f65c-s2", cfg.ContainerName())
+
+	assert.Error(t, SetContainerNaming(ContainerNamingConfig{BotTemplate: "{{.Unknown}}"}))
+	assert.Error(t, SetContainerNaming(ContainerNamingConfig{BotTemplate: "bot/{{.BotID}}"}))
+	assert.Error(t, SetContainerNaming(ContainerNamingConfig{BotTemplate: "{{.BotID"}))
+	// the failed ones are not applied
+	assert.Equal(t, "node2-bot-0x04f65c-s2", cfg.ContainerName())
+}
+
 func TestAgentConfig_Equal(t *testing.T) {
 	tests := []struct {
 		name string
@@ -141,3 +167,15 @@ func TestAgentConfig_Equal(t *testing.T) {
 		)
 	}
 }
+
+func TestServiceContainerNames(t *testing.T) {
+	defer SetContainerNaming(ContainerNamingConfig{})
+
+	assert.Equal(t, "forta-scanner", ServiceContainerName("", "scanner"))
+	assert.NoError(t, SetContainerNaming(ContainerNamingConfig{Instance: "node2"}))
+	assert.Equal(t, "forta-node2-scanner", DockerScannerContainerName)
+	assert.Equal(t, "forta-node2-supervisor", DockerSupervisorContainerName)
+	assert.Equal(t, DockerScannerContainerName, DockerNetworkName)
+	assert.NoError(t, SetContainerNaming(ContainerNamingConfig{}))
+	assert.Equal(t, "forta-scanner", DockerScannerContainerName)
+}
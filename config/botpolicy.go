@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBotPolicyFileName is the operator policy file in the Forta dir.
+const DefaultBotPolicyFileName = "bot-policy.yml"
+
+// BotPolicyConfig lets the operator decline the assigned bots regardless of the assignments. The rules
+// in the config are combined with the rules in the policy file and both are reloaded when they change.
+type BotPolicyConfig struct {
+	BotPolicy `yaml:",inline"`
+	// File is the policy file. It is relative to the Forta dir and it is optional.
+	File string `yaml:"file" json:"file" default:"bot-policy.yml"`
+}
+
+// FilePath returns the path of the policy file.
+func (cfg BotPolicyConfig) FilePath(fortaDir string) string {
+	if len(cfg.File) == 0 || path.IsAbs(cfg.File) {
+		return cfg.File
+	}
+	return path.Join(fortaDir, cfg.File)
+}
+
+// BotPolicy denies the bots which match any of the deny rules. The allow rules which are not empty
+// limit the bots to the matching ones.
+type BotPolicy struct {
+	Deny  BotPolicyRules `yaml:"deny" json:"deny"`
+	Allow BotPolicyRules `yaml:"allow" json:"allow"`
+}
+
+// BotPolicyRules match the bots by their IDs, developer addresses or image registries.
+type BotPolicyRules struct {
+	BotIDs     []string `yaml:"botIds" json:"botIds,omitempty"`
+	Developers []string `yaml:"developers" json:"developers,omitempty" validate:"dive,eth_addr"`
+	Registries []string `yaml:"registries" json:"registries,omitempty" validate:"dive,hostname_port|hostname_rfc1123"`
+}
+
+// LoadBotPolicyFile reads the policy file. A missing file is an empty policy.
+func LoadBotPolicyFile(policyPath string) (policy BotPolicy, err error) {
+	if len(policyPath) == 0 {
+		return
+	}
+	b, err := ioutil.ReadFile(policyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return BotPolicy{}, nil
+	}
+	if err != nil {
+		return BotPolicy{}, fmt.Errorf("failed to read the bot policy file: %v", err)
+	}
+	if err := yaml.Unmarshal(b, &policy); err != nil {
+		return BotPolicy{}, fmt.Errorf("failed to parse the bot policy file: %v", err)
+	}
+	if err := validator.New().Struct(&policy); err != nil {
+		return BotPolicy{}, fmt.Errorf("invalid bot policy file: %v", err)
+	}
+	return policy, nil
+}
+
+// Merge combines the rules of the policies.
+func (policy BotPolicy) Merge(other BotPolicy) BotPolicy {
+	return BotPolicy{
+		Deny:  policy.Deny.merge(other.Deny),
+		Allow: policy.Allow.merge(other.Allow),
+	}
+}
+
+func (rules BotPolicyRules) merge(other BotPolicyRules) BotPolicyRules {
+	return BotPolicyRules{
+		BotIDs:     append(append([]string{}, rules.BotIDs...), other.BotIDs...),
+		Developers: append(append([]string{}, rules.Developers...), other.Developers...),
+		Registries: append(append([]string{}, rules.Registries...), other.Registries...),
+	}
+}
+
+// Decline tells why the policy does not let the bot run. It returns an empty string if the bot is not declined.
+func (policy BotPolicy) Decline(botConfig AgentConfig) string {
+	registry := ImageRegistry(botConfig.Image)
+	switch {
+	case containsFold(policy.Deny.BotIDs, botConfig.ID):
+		return "bot is denied"
+	case containsFold(policy.Deny.Developers, botConfig.Owner):
+		return "developer is denied"
+	case containsFold(policy.Deny.Registries, registry):
+		return "image registry is denied"
+	case len(policy.Allow.BotIDs) > 0 && !containsFold(policy.Allow.BotIDs, botConfig.ID):
+		return "bot is not allowed"
+	case len(policy.Allow.Developers) > 0 && !containsFold(policy.Allow.Developers, botConfig.Owner):
+		return "developer is not allowed"
+	case len(policy.Allow.Registries) > 0 && !containsFold(policy.Allow.Registries, registry):
+		return "image registry is not allowed"
+	}
+	return ""
+}
+
+// ImageRegistry returns the registry host of the image reference. The references without
+// a registry host are from Docker Hub.
+func ImageRegistry(image string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return strings.ToLower(parts[0])
+	}
+	return "docker.io"
+}
+
+func containsFold(values []string, value string) bool {
+	if len(value) == 0 {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
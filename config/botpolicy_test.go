@@ -0,0 +1,60 @@
+package config
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotPolicy(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	cfg := BotPolicyConfig{
+		BotPolicy: BotPolicy{Deny: BotPolicyRules{BotIDs: []string{"0xBOT1"}}},
+		File:      DefaultBotPolicyFileName,
+	}
+	policyPath := cfg.FilePath(dir)
+	r.Equal(path.Join(dir, DefaultBotPolicyFileName), policyPath)
+
+	// no policy file yet
+	filePolicy, err := LoadBotPolicyFile(policyPath)
+	r.NoError(err)
+	r.Equal(BotPolicy{}, filePolicy)
+
+	r.NoError(ioutil.WriteFile(policyPath, []byte(`
+deny:
+  developers:
+    - "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+allow:
+  registries:
+    - disco.forta.network
+    - localhost:5000
+`), 0644))
+	filePolicy, err = LoadBotPolicyFile(policyPath)
+	r.NoError(err)
+	policy := cfg.BotPolicy.Merge(filePolicy)
+
+	disco := "disco.forta.network/bafybeibvkqkf7i3eiw45raymzcvxyu6nbwpgzdxeflsiggvhcdz3e4amq4@sha256:8e8e8b8d4a4a2c5b3e2dcf6c7c4ac2bdd6dc3d4a0bb0fb4a7f52d8a0e4c1e5f2"
+	r.Equal("bot is denied", policy.Decline(AgentConfig{ID: "0xbot1", Image: disco}))
+	r.Equal("developer is denied", policy.Decline(AgentConfig{ID: "0xbot2", Owner: "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", Image: disco}))
+	r.Equal("image registry is not allowed", policy.Decline(AgentConfig{ID: "0xbot2", Image: "forta-network/bot:latest"}))
+	r.Empty(policy.Decline(AgentConfig{ID: "0xbot2", Image: disco}))
+	r.Empty(policy.Decline(AgentConfig{ID: "0xbot2", Image: "localhost:5000/bot"}))
+
+	r.NoError(ioutil.WriteFile(policyPath, []byte("deny:\n  developers: [\"0x123\"]\n"), 0644))
+	_, err = LoadBotPolicyFile(policyPath)
+	r.Error(err)
+}
+
+func TestImageRegistry(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("docker.io", ImageRegistry("nginx"))
+	r.Equal("docker.io", ImageRegistry("forta-network/forta-node:latest"))
+	r.Equal("disco.forta.network", ImageRegistry("Disco.Forta.Network/bafy@sha256:abcd"))
+	r.Equal("localhost", ImageRegistry("localhost/bot"))
+	r.Equal("localhost:5000", ImageRegistry("localhost:5000/bot"))
+}
@@ -3,21 +3,68 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/creasty/defaults"
 	"github.com/forta-network/forta-core-go/protocol/settings"
 )
 
 type PublicAPIProxyConfig struct {
-	Url             string            `yaml:"url" json:"url" validate:"omitempty,url" default:"https://api.forta.network"`
-	Headers         map[string]string `yaml:"headers" json:"headers"`
-	RateLimitConfig *RateLimitConfig  `yaml:"rateLimit" json:"rateLimit"`
+	Url             string               `yaml:"url" json:"url" validate:"omitempty,url" default:"https://api.forta.network"`
+	Headers         map[string]string    `yaml:"headers" json:"headers"`
+	RateLimitConfig *RateLimitConfig     `yaml:"rateLimit" json:"rateLimit"`
+	Cache           PublicAPICacheConfig `yaml:"cache" json:"cache"`
+	Quota           PublicAPIQuotaConfig `yaml:"quota" json:"quota"`
+}
+
+// PublicAPICacheConfig configures caching of the public API responses. The responses are cached
+// per bot since the requests are authorized with the token of the bot.
+type PublicAPICacheConfig struct {
+	Enable     bool `yaml:"enable" json:"enable"`
+	TTLSeconds int  `yaml:"ttlSeconds" json:"ttlSeconds" default:"60" validate:"min=1"`
+	MaxEntries int  `yaml:"maxEntries" json:"maxEntries" default:"1000"`
+	// MaxResponseBytes is the size of the largest response which is cached.
+	MaxResponseBytes int `yaml:"maxResponseBytes" json:"maxResponseBytes" default:"1048576"`
+}
+
+// PublicAPIQuotaConfig bounds the number of the bot requests which reach the public API.
+// The responses served from the cache are not counted.
+type PublicAPIQuotaConfig struct {
+	Enable  bool           `yaml:"enable" json:"enable"`
+	Default PublicAPIQuota `yaml:"default" json:"default"`
+	// BotQuotas override the default quota for specific bots.
+	BotQuotas map[string]PublicAPIQuota `yaml:"botQuotas" json:"botQuotas"`
+}
+
+// PublicAPIQuota limits the requests of a bot per hour and per day. Zero means no limit.
+type PublicAPIQuota struct {
+	HourlyRequests int64 `yaml:"hourlyRequests" json:"hourlyRequests"`
+	DailyRequests  int64 `yaml:"dailyRequests" json:"dailyRequests"`
 }
 type JsonRpcConfig struct {
 	Url     string            `yaml:"url" json:"url" validate:"omitempty,url"`
 	Headers map[string]string `yaml:"headers" json:"headers"`
+	// TLS is used by the JSON-RPC proxy when connecting to the API.
+	TLS *TLSClientConfig `yaml:"tls" json:"tls,omitempty"`
+}
+
+// TLSClientConfig contains the custom CA bundle and the client certificate to authenticate to a server.
+// The relative file paths are resolved from the Forta directory.
+type TLSClientConfig struct {
+	CACertFile     string `yaml:"caCertFile" json:"caCertFile"`
+	ClientCertFile string `yaml:"clientCertFile" json:"clientCertFile" validate:"required_with=ClientKeyFile"`
+	ClientKeyFile  string `yaml:"clientKeyFile" json:"clientKeyFile" validate:"required_with=ClientCertFile"`
+}
+
+// TLSServerConfig contains the certificate to serve with. The relative file paths are resolved from the Forta directory.
+type TLSServerConfig struct {
+	CertFile string `yaml:"certFile" json:"certFile" validate:"required"`
+	KeyFile  string `yaml:"keyFile" json:"keyFile" validate:"required"`
 }
 
 type ScannerConfig struct {
@@ -27,11 +74,55 @@ type ScannerConfig struct {
 	BlockMaxAgeSeconds   int64         `yaml:"blockMaxAgeSeconds" json:"blockMaxAgeSeconds" default:"600"`
 	RetryIntervalSeconds int64         `yaml:"retryIntervalSeconds" json:"retryIntervalSeconds" default:"8"`
 	AlertAPIURL          string        `yaml:"apiUrl" json:"apiUrl" default:"https://api.forta.network/graphql" validate:"url"`
+	// Confirmations is how many blocks to wait before dispatching a block to the bots. The chain default is used if not set.
+	Confirmations *int `yaml:"confirmations" json:"confirmations" validate:"omitempty,min=0"`
+	// MaxReorgDepth is how many recent blocks are tracked for detecting the reorgs. Zero disables the reorg detection.
+	MaxReorgDepth int `yaml:"maxReorgDepth" json:"maxReorgDepth" default:"64" validate:"min=0"`
+	// StartBlock is the block to start scanning from for backfilling. The scanning starts from the latest block if not set.
+	StartBlock *uint64 `yaml:"startBlock" json:"startBlock"`
+	// MaxGapBlocks is how many missed blocks are backfilled after a restart or a skip. The older ones
+	// are skipped. Zero disables the checkpoints and the gap recovery.
+	MaxGapBlocks int `yaml:"maxGapBlocks" json:"maxGapBlocks" default:"1000" validate:"min=0"`
+	// Mempool configures the pending tx feed for the bots which opt in.
+	Mempool MempoolConfig `yaml:"mempool" json:"mempool"`
+	// Enrichment configures getting the blocks ready for the bots ahead of the dispatch.
+	Enrichment BlockEnrichmentConfig `yaml:"enrichment" json:"enrichment"`
+}
+
+// BlockEnrichmentConfig configures getting the blocks with their logs and traces concurrently so that
+// the node can catch up with the head on the high throughput chains. The blocks are still dispatched in order.
+type BlockEnrichmentConfig struct {
+	// Window is how many of the next blocks are enriched concurrently when the node is behind the head.
+	Window int `yaml:"window" json:"window" default:"1" validate:"min=1,max=64"`
+	// ReceiptBatchSize is how many receipts are fetched in a batched call to get the logs of a block.
+	// The logs are fetched with eth_getLogs if it is zero.
+	ReceiptBatchSize int `yaml:"receiptBatchSize" json:"receiptBatchSize" validate:"min=0,max=1000"`
+}
+
+// IsEnabled tells if the default block feed should be replaced.
+func (cfg BlockEnrichmentConfig) IsEnabled() bool {
+	return cfg.Window > 1 || cfg.ReceiptBatchSize > 0
+}
+
+// MempoolConfig configures the pending tx feed.
+type MempoolConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// JsonRpc is the API to get the pending txs from. The scan API is used if not set.
+	JsonRpc             JsonRpcConfig `yaml:"jsonRpc" json:"jsonRpc"`
+	MaxTxsPerSecond     int           `yaml:"maxTxsPerSecond" json:"maxTxsPerSecond" default:"50" validate:"min=1"`
+	PollIntervalSeconds int           `yaml:"pollIntervalSeconds" json:"pollIntervalSeconds" default:"2" validate:"min=1"`
 }
 
 type TraceConfig struct {
 	JsonRpc JsonRpcConfig `yaml:"jsonRpc" json:"jsonRpc"`
 	Enabled bool          `yaml:"enabled" json:"enabled"`
+	// Provider is the trace API: parity (trace_block), geth (debug_traceBlockByHash) or auto to detect it.
+	Provider string `yaml:"provider" json:"provider" default:"auto" validate:"omitempty,oneof=auto parity geth"`
+	// Tracer is the Geth tracer which is used with debug_traceBlockByHash.
+	Tracer               string `yaml:"tracer" json:"tracer" default:"callTracer" validate:"omitempty,oneof=callTracer flatCallTracer"`
+	TracerTimeoutSeconds int    `yaml:"tracerTimeoutSeconds" json:"tracerTimeoutSeconds" default:"30" validate:"min=0"`
+	// CacheSize is how many recently traced blocks are kept for the backfills and the retries.
+	CacheSize int `yaml:"cacheSize" json:"cacheSize" default:"32" validate:"min=0"`
 }
 
 type RateLimitConfig struct {
@@ -40,14 +131,282 @@ type RateLimitConfig struct {
 }
 
 type JsonRpcProxyConfig struct {
-	JsonRpc         JsonRpcConfig    `yaml:"jsonRpc" json:"jsonRpc"`
-	RateLimitConfig *RateLimitConfig `yaml:"rateLimit" json:"rateLimit"`
+	// ListenAddr is the address the proxy listens on for the bot requests.
+	ListenAddr      string                  `yaml:"listenAddr" json:"listenAddr" default:":8545"`
+	JsonRpc         JsonRpcConfig           `yaml:"jsonRpc" json:"jsonRpc"`
+	Upstreams       []JsonRpcUpstreamConfig `yaml:"upstreams" json:"upstreams" validate:"dive"`
+	RateLimitConfig *RateLimitConfig        `yaml:"rateLimit" json:"rateLimit"`
+	Cache           JsonRpcCacheConfig      `yaml:"cache" json:"cache"`
+	MethodPolicy    JsonRpcMethodPolicy     `yaml:"methodPolicy" json:"methodPolicy"`
+	// BotMethodPolicies override the method policy for specific bots.
+	BotMethodPolicies map[string]JsonRpcMethodPolicy `yaml:"botMethodPolicies" json:"botMethodPolicies"`
+	// MethodCosts tells how many rate limit tokens a method consumes. A method name can end with '*' to match a prefix.
+	MethodCosts map[string]int `yaml:"methodCosts" json:"methodCosts"`
+	// BotRateLimits override the rate limit for specific bots.
+	BotRateLimits map[string]RateLimitConfig `yaml:"botRateLimits" json:"botRateLimits" validate:"dive"`
+	// MaxBatchSize is the max number of requests allowed in a batch.
+	MaxBatchSize int `yaml:"maxBatchSize" json:"maxBatchSize" default:"100" validate:"min=1"`
+	// BatchSplitSize is the max number of requests sent to the upstream in one batch.
+	BatchSplitSize int `yaml:"batchSplitSize" json:"batchSplitSize" default:"20" validate:"min=1"`
+	// WebsocketUrl is the upstream websocket API for the subscriptions. It is derived from the JSON-RPC URL if not set.
+	WebsocketUrl string `yaml:"websocketUrl" json:"websocketUrl" validate:"omitempty,url"`
+	// MaxSubscriptions is the max number of subscriptions a bot can have on a websocket connection.
+	MaxSubscriptions int `yaml:"maxSubscriptions" json:"maxSubscriptions" default:"10"`
+	// MaxResponseBytes is the max size of an upstream response which is streamed to a bot. Zero means no limit.
+	MaxResponseBytes int `yaml:"maxResponseBytes" json:"maxResponseBytes" validate:"min=0"`
+	// MethodMaxResponseBytes override the max response size for specific methods. A method name can end with '*' to match a prefix.
+	MethodMaxResponseBytes map[string]int `yaml:"methodMaxResponseBytes" json:"methodMaxResponseBytes"`
+	// DisableCoalescing stops sending the identical concurrent requests of the bots to the upstream only once.
+	DisableCoalescing bool `yaml:"disableCoalescing" json:"disableCoalescing"`
+
+	CircuitBreaker JsonRpcCircuitBreakerConfig `yaml:"circuitBreaker" json:"circuitBreaker"`
+	Retry          JsonRpcRetryConfig          `yaml:"retry" json:"retry"`
+	Transport      JsonRpcTransportConfig      `yaml:"transport" json:"transport"`
+	AccessLog      JsonRpcAccessLogConfig      `yaml:"accessLog" json:"accessLog"`
+	LocalData      JsonRpcLocalDataConfig      `yaml:"localData" json:"localData"`
+	Quota          JsonRpcQuotaConfig          `yaml:"quota" json:"quota"`
+	HealthCheck    JsonRpcHealthCheckConfig    `yaml:"healthCheck" json:"healthCheck"`
+	// ServerTLS enables serving the proxy over TLS on a separate port while the bots continue using plain HTTP.
+	ServerTLS *TLSServerConfig `yaml:"serverTls" json:"serverTls,omitempty"`
+	// Instances are the additional proxies which serve the other chains from the same container.
+	Instances []JsonRpcProxyInstanceConfig `yaml:"instances" json:"instances" validate:"dive"`
+	// ProtocolProxies expose the non-EVM APIs to the bots from the same container.
+	ProtocolProxies []ProtocolProxyConfig `yaml:"protocolProxies" json:"protocolProxies" validate:"dive"`
+}
+
+// ProtocolProxyConfig is an authenticated reverse proxy for an API which is not EVM JSON-RPC
+// (e.g. Solana JSON-RPC, Cosmos REST, Bitcoin RPC). The bots find the port of the proxy
+// from the FORTA_PROXY_<NAME>_PORT env var.
+type ProtocolProxyConfig struct {
+	Name            string            `yaml:"name" json:"name" validate:"required,alphanum"`
+	ListenAddr      string            `yaml:"listenAddr" json:"listenAddr" validate:"required"`
+	Url             string            `yaml:"url" json:"url" validate:"required,url"`
+	Headers         map[string]string `yaml:"headers" json:"headers"`
+	RateLimitConfig *RateLimitConfig  `yaml:"rateLimit" json:"rateLimit"`
+}
+
+// Port returns the port of the protocol proxy.
+func (cfg ProtocolProxyConfig) Port() string {
+	return listenPort(cfg.ListenAddr, "")
+}
+
+// EnvPortName returns the name of the env var which tells the bots the port of the protocol proxy.
+func (cfg ProtocolProxyConfig) EnvPortName() string {
+	return fmt.Sprintf(EnvProtocolProxyPortFmt, strings.ToUpper(cfg.Name))
+}
+
+// JsonRpcProxyInstanceConfig is an additional proxy for another chain. The rest of the
+// settings are inherited from the main proxy. The bots can only read from the other chains.
+type JsonRpcProxyInstanceConfig struct {
+	// Name lets the bots find the port of the proxy from the JSON_RPC_PORT_<NAME> env var too.
+	Name         string                  `yaml:"name" json:"name" validate:"omitempty,alphanum"`
+	ChainID      int                     `yaml:"chainId" json:"chainId" validate:"required"`
+	ListenAddr   string                  `yaml:"listenAddr" json:"listenAddr" validate:"required"`
+	JsonRpc      JsonRpcConfig           `yaml:"jsonRpc" json:"jsonRpc"`
+	Upstreams    []JsonRpcUpstreamConfig `yaml:"upstreams" json:"upstreams" validate:"dive"`
+	WebsocketUrl string                  `yaml:"websocketUrl" json:"websocketUrl" validate:"omitempty,url"`
+	// DataSource exposes the proxy to all bots, including the ones which do not declare the chain.
+	DataSource bool `yaml:"dataSource" json:"dataSource"`
+}
+
+// Port returns the port of the proxy.
+func (cfg JsonRpcProxyConfig) Port() string {
+	return listenPort(cfg.ListenAddr, DefaultJSONRPCProxyPort)
+}
+
+// validateMethodCosts checks that no method costs more than the burst of the rate limits since
+// the requests to it could never be allowed.
+func (cfg JsonRpcProxyConfig) validateMethodCosts(chainID int) error {
+	rateLimit := cfg.RateLimitConfig
+	if rateLimit == nil {
+		rateLimit = (*RateLimitConfig)(settings.GetChainSettings(chainID).JsonRpcRateLimiting)
+	}
+	bursts := map[string]int{"jsonRpcProxy.rateLimit": rateLimit.Burst}
+	for botID, botRateLimit := range cfg.BotRateLimits {
+		bursts["jsonRpcProxy.botRateLimits."+botID] = botRateLimit.Burst
+	}
+	methods := make([]string, 0, len(cfg.MethodCosts))
+	for method := range cfg.MethodCosts {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		for field, burst := range bursts {
+			if cost := cfg.MethodCosts[method]; cost > burst {
+				return fmt.Errorf("the cost %d of method '%s' is larger than the burst %d of %s", cost, method, burst, field)
+			}
+		}
+	}
+	return nil
+}
+
+// Port returns the port of the proxy instance.
+func (cfg JsonRpcProxyInstanceConfig) Port() string {
+	return listenPort(cfg.ListenAddr, "")
+}
+
+// EnvPortNames returns the names of the env vars which tell the bots the port of the proxy.
+func (cfg JsonRpcProxyInstanceConfig) EnvPortNames() []string {
+	names := []string{fmt.Sprintf(EnvJsonRpcChainPortFmt, cfg.ChainID)}
+	if len(cfg.Name) > 0 {
+		names = append(names, fmt.Sprintf(EnvJsonRpcNamedPortFmt, strings.ToUpper(cfg.Name)))
+	}
+	return names
+}
+
+func listenPort(listenAddr, defaultPort string) string {
+	_, port, err := net.SplitHostPort(listenAddr)
+	if err != nil || len(port) == 0 {
+		return defaultPort
+	}
+	return port
+}
+
+// JsonRpcAccessLogConfig enables logging the bot requests as JSON lines.
+type JsonRpcAccessLogConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// Path is the file to append the logs to. The logs are written to stdout if it is empty.
+	Path string `yaml:"path" json:"path"`
+	// SampleRate is the ratio of the requests to log.
+	SampleRate float64 `yaml:"sampleRate" json:"sampleRate" default:"1" validate:"min=0,max=1"`
+}
+
+// JsonRpcLocalDataConfig enables answering the log queries from the recently scanned blocks
+// kept by the scanner. Only the cache misses are forwarded to the upstream.
+type JsonRpcLocalDataConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// MaxBlocks is the number of the recent blocks the scanner keeps in memory.
+	MaxBlocks int `yaml:"maxBlocks" json:"maxBlocks" default:"64" validate:"min=1"`
+}
+
+// JsonRpcQuotaConfig bounds the cumulative usage of the bots. The compute units of a request
+// are the method costs which are used for rate limiting.
+type JsonRpcQuotaConfig struct {
+	Enable  bool         `yaml:"enable" json:"enable"`
+	Default JsonRpcQuota `yaml:"default" json:"default"`
+	// BotQuotas override the default quota for specific bots.
+	BotQuotas map[string]JsonRpcQuota `yaml:"botQuotas" json:"botQuotas"`
+	// Enforcement is either "reject" or "throttle". When throttling, the bots which exceed
+	// their quota can still send requests at the throttle rate.
+	Enforcement  string  `yaml:"enforcement" json:"enforcement" default:"reject" validate:"oneof=reject throttle"`
+	ThrottleRate float64 `yaml:"throttleRate" json:"throttleRate" default:"1"`
+	// Path is the file which keeps the counters across the restarts. It is relative to the Forta dir.
+	Path string `yaml:"path" json:"path" default:".json-rpc-quotas.json"`
+}
+
+// JsonRpcQuota limits the requests and the compute units of a bot per hour and per day. Zero means no limit.
+type JsonRpcQuota struct {
+	HourlyRequests     int64 `yaml:"hourlyRequests" json:"hourlyRequests"`
+	DailyRequests      int64 `yaml:"dailyRequests" json:"dailyRequests"`
+	HourlyComputeUnits int64 `yaml:"hourlyComputeUnits" json:"hourlyComputeUnits"`
+	DailyComputeUnits  int64 `yaml:"dailyComputeUnits" json:"dailyComputeUnits"`
+}
+
+// JsonRpcHealthCheckConfig controls probing the upstreams for their latency and head block.
+type JsonRpcHealthCheckConfig struct {
+	IntervalSeconds int `yaml:"intervalSeconds" json:"intervalSeconds" default:"30" validate:"min=1"`
+	// MaxBlockLag is the number of blocks an upstream can fall behind the best upstream before it is taken out of rotation.
+	MaxBlockLag uint64 `yaml:"maxBlockLag" json:"maxBlockLag" default:"5"`
+	// MaxLatencyMs is the probe latency above which an upstream is reported as lagging.
+	MaxLatencyMs int64 `yaml:"maxLatencyMs" json:"maxLatencyMs" default:"2000"`
+}
+
+// JsonRpcRetryConfig controls retrying the transient upstream failures with exponential backoff.
+type JsonRpcRetryConfig struct {
+	MaxRetries int `yaml:"maxRetries" json:"maxRetries" default:"2" validate:"min=0"`
+	// MethodMaxRetries overrides the max retries per method. A method name can end with '*' to match a prefix.
+	MethodMaxRetries map[string]int `yaml:"methodMaxRetries" json:"methodMaxRetries"`
+	InitialBackoffMs int            `yaml:"initialBackoffMs" json:"initialBackoffMs" default:"100"`
+	MaxBackoffMs     int            `yaml:"maxBackoffMs" json:"maxBackoffMs" default:"2000"`
+}
+
+// JsonRpcTransportConfig tunes the connections to the upstream APIs to avoid the connection churn
+// and the latency spikes under many concurrent bot requests.
+type JsonRpcTransportConfig struct {
+	MaxIdleConnsPerHost    int `yaml:"maxIdleConnsPerHost" json:"maxIdleConnsPerHost" default:"64" validate:"min=1"`
+	IdleConnTimeoutSeconds int `yaml:"idleConnTimeoutSeconds" json:"idleConnTimeoutSeconds" default:"90" validate:"min=1"`
+	// TLSSessionCacheSize is how many TLS sessions are kept to resume. Zero disables the session resumption.
+	TLSSessionCacheSize int `yaml:"tlsSessionCacheSize" json:"tlsSessionCacheSize" default:"64" validate:"min=0"`
+	// DisableHTTP2 makes the proxy use HTTP/1.1 with the upstreams which support HTTP/2.
+	DisableHTTP2 bool `yaml:"disableHttp2" json:"disableHttp2"`
+	// DNSRefreshSeconds is how often the idle connections are closed so that the new connections resolve the
+	// upstream hosts again. Zero keeps the connections until they time out.
+	DNSRefreshSeconds int `yaml:"dnsRefreshSeconds" json:"dnsRefreshSeconds" default:"300" validate:"min=0"`
+}
+
+// JsonRpcCircuitBreakerConfig controls when the proxy stops sending requests to the upstreams.
+type JsonRpcCircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures which opens the circuit.
+	FailureThreshold int `yaml:"failureThreshold" json:"failureThreshold" default:"5"`
+	// CooldownSeconds is how long the circuit stays open before letting a trial request through.
+	CooldownSeconds int `yaml:"cooldownSeconds" json:"cooldownSeconds" default:"30"`
+	// UpstreamTimeoutSeconds is the max duration of an upstream request.
+	UpstreamTimeoutSeconds int `yaml:"upstreamTimeoutSeconds" json:"upstreamTimeoutSeconds" default:"30"`
+	// MaxInFlight is the max number of concurrent upstream requests.
+	MaxInFlight int `yaml:"maxInFlight" json:"maxInFlight" default:"200"`
+}
+
+// JsonRpcMethodPolicy restricts the methods which the bots can call. A method name can end
+// with '*' to match a prefix. When the allow list is not empty, only the allowed methods pass.
+type JsonRpcMethodPolicy struct {
+	Allow []string `yaml:"allow" json:"allow"`
+	Deny  []string `yaml:"deny" json:"deny" default:"[\"eth_sendRawTransaction\",\"eth_sendTransaction\",\"eth_sign*\",\"debug_*\",\"admin_*\",\"personal_*\",\"miner_*\"]"`
+}
+
+// JsonRpcUpstreamConfig is an upstream JSON-RPC API which the proxy can balance the requests to.
+type JsonRpcUpstreamConfig struct {
+	JsonRpcConfig `yaml:",inline" json:",inline"`
+	Weight        int              `yaml:"weight" json:"weight" default:"1" validate:"min=1"`
+	RateLimit     *RateLimitConfig `yaml:"rateLimit" json:"rateLimit"`
+}
+
+// JsonRpcCacheConfig configures caching of the immutable JSON-RPC responses.
+type JsonRpcCacheConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// MethodTTLSeconds overrides the default cache durations per method. Zero disables caching for a method.
+	MethodTTLSeconds map[string]int `yaml:"methodTtlSeconds" json:"methodTtlSeconds"`
+	// FinalityDepth is the number of blocks after which a block is considered final.
+	FinalityDepth uint64       `yaml:"finalityDepth" json:"finalityDepth" default:"64"`
+	MaxEntries    int          `yaml:"maxEntries" json:"maxEntries" default:"10000"`
+	Redis         *RedisConfig `yaml:"redis" json:"redis"`
 }
 
 type LogConfig struct {
 	Level       string `yaml:"level" json:"level" default:"info" `
 	MaxLogSize  string `yaml:"maxLogSize" json:"maxLogSize" default:"50m" `
 	MaxLogFiles int    `yaml:"maxLogFiles" json:"maxLogFiles" default:"10" `
+	Format      string `yaml:"format" json:"format" default:"json" validate:"omitempty,oneof=json text"`
+	// Components overrides the level and the format per component and enables writing to a file.
+	// The components are supervisor, scanner, json-rpc and lifecycle.
+	Components map[string]ComponentLogConfig `yaml:"components" json:"components,omitempty" validate:"dive"`
+}
+
+// ComponentLogConfig configures the logging of a component.
+type ComponentLogConfig struct {
+	Level  string        `yaml:"level" json:"level,omitempty" validate:"omitempty,oneof=trace debug info warn warning error fatal panic"`
+	Format string        `yaml:"format" json:"format,omitempty" validate:"omitempty,oneof=json text"`
+	File   LogFileConfig `yaml:"file" json:"file"`
+}
+
+// LogFileConfig enables writing the logs to a file which is rotated when it reaches the max size.
+type LogFileConfig struct {
+	// Path is relative to the Forta dir unless it is absolute.
+	Path       string `yaml:"path" json:"path,omitempty"`
+	MaxSizeMB  int    `yaml:"maxSizeMb" json:"maxSizeMb,omitempty" validate:"omitempty,min=1"`
+	MaxBackups int    `yaml:"maxBackups" json:"maxBackups,omitempty" validate:"omitempty,min=0"`
+}
+
+// ComponentConfig returns the log config of a component. The level and the format
+// are inherited from the top level config if they are not set for the component.
+func (lc LogConfig) ComponentConfig(component string) ComponentLogConfig {
+	componentCfg := lc.Components[component]
+	if len(componentCfg.Level) == 0 {
+		componentCfg.Level = lc.Level
+	}
+	if len(componentCfg.Format) == 0 {
+		componentCfg.Format = lc.Format
+	}
+	return componentCfg
 }
 
 type RegistryConfig struct {
@@ -58,8 +417,27 @@ type RegistryConfig struct {
 	Username               string        `yaml:"username" json:"username"`
 	Password               string        `yaml:"password" json:"password"`
 	Disable                bool          `yaml:"disable" json:"disable"` // for testing situations
-	CheckIntervalSeconds   int           `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"15"`
+	CheckIntervalSeconds   int           `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"60"`
 	ReleaseDistributionUrl string        `yaml:"releaseDistributionUrl" json:"releaseDistributionUrl" default:"https://dist.forta.network/manifests/releases"`
+	ManifestCacheDir       string        `yaml:"-" json:"_manifestCacheDir"`
+	// VerifyManifestSignatures rejects the bots with manifests not signed by the bot owner.
+	VerifyManifestSignatures bool `yaml:"verifyManifestSignatures" json:"verifyManifestSignatures" default:"true"`
+	// CheckJitterSeconds is the max random delay added to the check interval so that the nodes
+	// do not hit the registry at the same time.
+	CheckJitterSeconds int `yaml:"checkJitterSeconds" json:"checkJitterSeconds" default:"10"`
+	// FullSyncIntervalSeconds is how often the assignments are reloaded even if the assignment hash has not changed.
+	FullSyncIntervalSeconds int `yaml:"fullSyncIntervalSeconds" json:"fullSyncIntervalSeconds" default:"300"`
+	// AssignmentEvents enables applying the assignment changes as soon as the dispatch contract events are seen.
+	AssignmentEvents bool `yaml:"assignmentEvents" json:"assignmentEvents" default:"true"`
+	// SnapshotMode runs the bots from the imported registry snapshot without contacting the registry,
+	// IPFS or the container registry. The snapshot is refreshed manually by importing a new one.
+	SnapshotMode bool   `yaml:"snapshotMode" json:"snapshotMode"`
+	SnapshotDir  string `yaml:"-" json:"_snapshotDir"`
+	// LoadRetries is how many more times the assignments are loaded when the registry fails.
+	LoadRetries int `yaml:"loadRetries" json:"loadRetries" default:"2" validate:"min=0"`
+	// MaxStaleSeconds is how long the last assignments are used while the registry is unavailable.
+	// The registry failures are reported as errors after that.
+	MaxStaleSeconds int `yaml:"maxStaleSeconds" json:"maxStaleSeconds" default:"900" validate:"min=0"`
 }
 
 type IPFSConfig struct {
@@ -67,6 +445,19 @@ type IPFSConfig struct {
 	APIURL     string `yaml:"apiUrl" json:"apiUrl" validate:"url" default:"https://ipfs.forta.network" `
 	Username   string `yaml:"username" json:"username"`
 	Password   string `yaml:"password" json:"password"`
+	// GatewayURLs are tried after the gateway URL when fetching the bot manifests.
+	GatewayURLs []string `yaml:"gatewayUrls" json:"gatewayUrls" validate:"dive,url"`
+	// GatewayOrder is the order which the gateways are tried in.
+	GatewayOrder string `yaml:"gatewayOrder" json:"gatewayOrder" default:"sequential" validate:"omitempty,oneof=sequential random"`
+	// PublicGatewayFailover enables falling back to the public gateways.
+	PublicGatewayFailover bool `yaml:"publicGatewayFailover" json:"publicGatewayFailover" default:"true"`
+	GatewayTimeoutSeconds int  `yaml:"gatewayTimeoutSeconds" json:"gatewayTimeoutSeconds" default:"10" validate:"min=1"`
+	// GatewayRetries is how many more times all of the gateways are retried with exponential backoff.
+	GatewayRetries int `yaml:"gatewayRetries" json:"gatewayRetries" default:"3" validate:"min=0"`
+	// LocalNodeAPIURL is the API address of a local IPFS daemon which is tried before the gateways.
+	LocalNodeAPIURL string `yaml:"localNodeApiUrl" json:"localNodeApiUrl" validate:"omitempty,url"`
+	// PinManifests pins the manifests of the assigned bots on the local IPFS daemon.
+	PinManifests bool `yaml:"pinManifests" json:"pinManifests" default:"true"`
 }
 
 type BatchConfig struct {
@@ -74,20 +465,141 @@ type BatchConfig struct {
 	IntervalSeconds              *int `yaml:"intervalSeconds" json:"intervalSeconds" default:"15"`
 	MetricsBucketIntervalSeconds *int `yaml:"metricsBucketIntervalSeconds" json:"metricsBucketIntervalSeconds" default:"60"`
 	MaxAlerts                    *int `yaml:"maxAlerts" json:"maxAlerts" default:"1000" `
+	// MaxBytes flushes the batch early when the estimated size of the alerts exceeds it. Zero disables it.
+	MaxBytes int `yaml:"maxBytes" json:"maxBytes" default:"4000000" validate:"min=0"`
+	// Compression is the encoding of the batch requests sent to the alerts API.
+	Compression string `yaml:"compression" json:"compression" default:"none" validate:"oneof=none gzip zstd"`
+}
+
+// MetricsBufferConfig configures retaining the metrics on disk when the batches cannot be published.
+type MetricsBufferConfig struct {
+	Disable bool `yaml:"disable" json:"disable"`
+	// MaxBuckets is the max number of per-bot metric buckets to retain.
+	MaxBuckets    int `yaml:"maxBuckets" json:"maxBuckets" default:"10000" validate:"min=1"`
+	MaxAgeMinutes int `yaml:"maxAgeMinutes" json:"maxAgeMinutes" default:"1440" validate:"min=1"`
+	// FlushBuckets is the max number of buffered buckets to add to a batch.
+	FlushBuckets int `yaml:"flushBuckets" json:"flushBuckets" default:"500" validate:"min=1"`
+	// DropPolicy decides which buckets are dropped when the buffer is full.
+	DropPolicy string `yaml:"dropPolicy" json:"dropPolicy" default:"oldest" validate:"oneof=oldest newest"`
+}
+
+// BatchQueueConfig configures queueing the batches on disk until they are published.
+type BatchQueueConfig struct {
+	Disable bool `yaml:"disable" json:"disable"`
+	// MaxBatches is the max number of batches to retain. The oldest batches are dropped first.
+	MaxBatches    int `yaml:"maxBatches" json:"maxBatches" default:"1000" validate:"min=1"`
+	MaxAgeMinutes int `yaml:"maxAgeMinutes" json:"maxAgeMinutes" default:"1440" validate:"min=1"`
+	// MaxBackoffSeconds is the max delay between the retries when the API is unreachable.
+	MaxBackoffSeconds int `yaml:"maxBackoffSeconds" json:"maxBackoffSeconds" default:"300" validate:"min=1"`
+}
+
+// AlertFilterConfig configures suppressing the duplicate and the excessive alerts of the bots
+// before they are published.
+type AlertFilterConfig struct {
+	Dedup AlertDedupConfig `yaml:"dedup" json:"dedup"`
+	// Quota limits how many alerts per second each bot can emit.
+	Quota *RateLimitConfig `yaml:"quota" json:"quota"`
+	// BotQuotas override the quota for specific bots.
+	BotQuotas map[string]RateLimitConfig `yaml:"botQuotas" json:"botQuotas" validate:"dive"`
+}
+
+// AlertDedupConfig configures dropping the alerts which have the same key within a time window.
+type AlertDedupConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// KeyFields are the alert fields which make up the dedup key. The entity is derived from
+	// the finding labels or the addresses.
+	KeyFields     []string `yaml:"keyFields" json:"keyFields" default:"[\"bot\",\"alertId\",\"entity\"]" validate:"min=1,dive,oneof=bot alertId entity name severity"`
+	WindowSeconds int      `yaml:"windowSeconds" json:"windowSeconds" default:"60" validate:"min=1"`
 }
 
 type PublisherConfig struct {
-	SkipPublish   bool        `yaml:"skipPublish" json:"skipPublish" default:"false"`
-	AlwaysPublish bool        `yaml:"alwaysPublish" json:"alwaysPublish" default:"false"`
-	APIURL        string      `yaml:"apiUrl" json:"apiUrl" default:"https://alerts.forta.network" validate:"url"`
-	IPFS          IPFSConfig  `yaml:"ipfs" json:"ipfs" validate:"required_unless=SkipPublish true"`
-	Batch         BatchConfig `yaml:"batch" json:"batch"`
+	SkipPublish   bool                `yaml:"skipPublish" json:"skipPublish" default:"false"`
+	AlwaysPublish bool                `yaml:"alwaysPublish" json:"alwaysPublish" default:"false"`
+	APIURL        string              `yaml:"apiUrl" json:"apiUrl" default:"https://alerts.forta.network" validate:"url"`
+	IPFS          IPFSConfig          `yaml:"ipfs" json:"ipfs" validate:"required_unless=SkipPublish true"`
+	Batch         BatchConfig         `yaml:"batch" json:"batch"`
+	MetricsBuffer MetricsBufferConfig `yaml:"metricsBuffer" json:"metricsBuffer"`
+	Queue         BatchQueueConfig    `yaml:"queue" json:"queue"`
+	AlertFilter   AlertFilterConfig   `yaml:"alertFilter" json:"alertFilter"`
+	PublishKeys   PublishKeysConfig   `yaml:"publishKeys" json:"publishKeys"`
+}
+
+// PublishKeysConfig configures remembering the publish keys of the alerts so that the evaluations
+// which are retried or repeated after a restart do not publish the same alerts again.
+type PublishKeysConfig struct {
+	Disable bool `yaml:"disable" json:"disable"`
+	// MaxKeys is how many of the latest publish keys are remembered.
+	MaxKeys int `yaml:"maxKeys" json:"maxKeys" default:"100000" validate:"min=1"`
 }
 
 type ResourcesConfig struct {
 	DisableAgentLimits bool    `yaml:"disableAgentLimits" json:"disableAgentLimits" default:"false" `
 	AgentMaxMemoryMiB  int     `yaml:"agentMaxMemoryMib" json:"agentMaxMemoryMib" validate:"omitempty,min=100"`
 	AgentMaxCPUs       float64 `yaml:"agentMaxCpus" json:"agentMaxCpus" validate:"omitempty,gt=0"`
+	// CPUFairness configures how the CPU time is shared between the bots.
+	CPUFairness BotCPUFairnessConfig `yaml:"cpuFairness" json:"cpuFairness"`
+	// Reservation keeps a slice of the host for the node services and the supervisor.
+	Reservation ResourceReservationConfig `yaml:"reservation" json:"reservation"`
+	// BotContainers configures the ulimits and the tmpfs mounts of the bot containers.
+	BotContainers BotContainersConfig `yaml:"botContainers" json:"botContainers"`
+}
+
+// BotContainersConfig configures the ulimits and the tmpfs mounts of the bot containers and their
+// dependencies so that the bots which open many sockets or need fast scratch space do not depend on
+// the host defaults. The changes apply when the containers are created again.
+type BotContainersConfig struct {
+	BotContainerConfig `yaml:",inline" json:",inline"`
+	// Bots override the ulimits and the tmpfs mounts of specific bots.
+	Bots map[string]BotContainerConfig `yaml:"bots" json:"bots" validate:"dive"`
+}
+
+// BotContainerConfig contains the ulimits and the tmpfs mounts of a bot container.
+type BotContainerConfig struct {
+	Ulimits UlimitsConfig      `yaml:"ulimits" json:"ulimits"`
+	Tmpfs   []TmpfsMountConfig `yaml:"tmpfs" json:"tmpfs" validate:"dive"`
+}
+
+// UlimitsConfig contains the ulimits which are not left to the Docker daemon defaults.
+type UlimitsConfig struct {
+	NoFile *UlimitConfig `yaml:"nofile" json:"nofile,omitempty"`
+	NProc  *UlimitConfig `yaml:"nproc" json:"nproc,omitempty"`
+}
+
+// UlimitConfig is a soft and a hard limit. The hard limit is the soft limit if not set.
+type UlimitConfig struct {
+	Soft int64 `yaml:"soft" json:"soft" validate:"min=1"`
+	Hard int64 `yaml:"hard" json:"hard" validate:"omitempty,gtefield=Soft"`
+}
+
+// TmpfsMountConfig is an in-memory file system mounted to the container. Its usage counts towards
+// the memory limit of the container.
+type TmpfsMountConfig struct {
+	Target  string `yaml:"target" json:"target" validate:"required,startswith=/"`
+	SizeMiB int    `yaml:"sizeMib" json:"sizeMib" default:"64" validate:"min=1"`
+	// Mode is the octal file mode of the mount like 1777.
+	Mode string `yaml:"mode" json:"mode" validate:"omitempty,numeric,max=4"`
+}
+
+// ResourceReservationConfig configures the CPUs and the memory which the bots can never use so that
+// the scanner, the proxies, the publisher and the supervisor keep up with the chain. The limits of
+// the bot containers are capped so that they add up to the host capacity minus the reservation.
+type ResourceReservationConfig struct {
+	Enable    bool    `yaml:"enable" json:"enable"`
+	CPUs      float64 `yaml:"cpus" json:"cpus" default:"1" validate:"gt=0"`
+	MemoryMiB int     `yaml:"memoryMib" json:"memoryMib" default:"2048" validate:"min=1"`
+}
+
+// BotCPUFairnessConfig configures the CPU weights of the bots which decide how the CPU time is shared
+// when the CPUs are busy, and reporting the bots which use more than their share.
+type BotCPUFairnessConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// BotPriorities override the priorities of specific bots. The bots which declare best effort in the
+	// manifest have the lowest priority and the rest have the default priority.
+	BotPriorities map[string]int `yaml:"botPriorities" json:"botPriorities" validate:"dive,min=1,max=10"`
+	// OverShareRatio is how many times its share a bot can use before it is reported.
+	OverShareRatio float64 `yaml:"overShareRatio" json:"overShareRatio" default:"2" validate:"gt=1"`
+	// OverShareChecks is how many resource checks in a row a bot should be over its share to be reported.
+	OverShareChecks int `yaml:"overShareChecks" json:"overShareChecks" default:"5" validate:"min=1"`
 }
 
 type ENSConfig struct {
@@ -102,11 +614,58 @@ type TelemetryConfig struct {
 	Disable   bool   `yaml:"disable" json:"disable"`
 }
 
+// BotStatsConfig configures the rolling bot statistics served by the admin API and the metrics endpoint.
+type BotStatsConfig struct {
+	WindowsMinutes []int `yaml:"windowsMinutes" json:"windowsMinutes" default:"[5,60,1440]" validate:"min=1,dive,min=1"`
+}
+
+// Windows returns the windows as durations.
+func (bsc BotStatsConfig) Windows() []time.Duration {
+	var windows []time.Duration
+	for _, minutes := range bsc.WindowsMinutes {
+		windows = append(windows, time.Duration(minutes)*time.Minute)
+	}
+	return windows
+}
+
+// SignerConfig makes the node sign with a remote signer (e.g. a local signing service or one in
+// front of a cloud KMS) instead of the key in the keys dir.
+type SignerConfig struct {
+	RemoteURL string `yaml:"remoteUrl" json:"remoteUrl" validate:"omitempty,url"`
+	AuthToken string `yaml:"authToken" json:"authToken"`
+	// Address is checked against the address of the remote signer if it is specified.
+	Address string `yaml:"address" json:"address" validate:"omitempty,eth_addr"`
+}
+
+// IsRemote tells if a remote signer is configured.
+func (sc SignerConfig) IsRemote() bool {
+	return len(sc.RemoteURL) > 0
+}
+
+// Auto-update channels
+const (
+	UpdateChannelStable = "stable"
+	UpdateChannelRC     = "rc"
+)
+
 type AutoUpdateConfig struct {
 	Disable              bool `yaml:"disable" json:"disable"`
 	UpdateDelay          *int `yaml:"updateDelay" json:"updateDelay"`
 	TrackPrereleases     bool `yaml:"trackPrereleases" json:"trackPrereleases"`
 	CheckIntervalSeconds int  `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"60"` // 1m
+	// Channel selects the releases to track. The rc channel is the same as tracking the prereleases.
+	Channel string `yaml:"channel" json:"channel" default:"stable" validate:"omitempty,oneof=stable rc"`
+	// PinnedVersion makes the node ignore the releases with a different version.
+	PinnedVersion string `yaml:"pinnedVersion" json:"pinnedVersion"`
+	// RollbackWindowSeconds is how long the node has to become ready after an update
+	// before the previous version is restored.
+	RollbackWindowSeconds int  `yaml:"rollbackWindowSeconds" json:"rollbackWindowSeconds" default:"600" validate:"min=0"` // 10m
+	DisableRollback       bool `yaml:"disableRollback" json:"disableRollback"`
+}
+
+// TracksPrereleases tells if the prerelease versions should be tracked.
+func (auc AutoUpdateConfig) TracksPrereleases() bool {
+	return auc.TrackPrereleases || auc.Channel == UpdateChannelRC
 }
 
 type AgentLogsConfig struct {
@@ -115,6 +674,135 @@ type AgentLogsConfig struct {
 	SendIntervalSeconds int    `yaml:"sendIntervalSeconds" json:"sendIntervalSeconds" default:"60"`
 }
 
+// AgentGrpcConfig configures the gRPC connections to the bots.
+type AgentGrpcConfig struct {
+	// Compression is used with the bots which accept it and is negotiated per bot.
+	Compression        string `yaml:"compression" json:"compression" validate:"omitempty,oneof=gzip zstd"`
+	MaxSendMessageSize int    `yaml:"maxSendMessageSize" json:"maxSendMessageSize" validate:"min=0"`
+	MaxRecvMessageSize int    `yaml:"maxRecvMessageSize" json:"maxRecvMessageSize" default:"250000" validate:"min=0"`
+	// Streaming sends the requests over a bidirectional stream per bot and falls back to
+	// the unary calls if the bot does not implement the streaming methods.
+	Streaming bool `yaml:"streaming" json:"streaming"`
+	// StreamPipelineDepth is how many requests of the same kind can be waiting for a response.
+	// The responses can be handled out of the request order if this is larger than one.
+	StreamPipelineDepth int `yaml:"streamPipelineDepth" json:"streamPipelineDepth" default:"1" validate:"min=1"`
+	// StreamKeepaliveSeconds is the interval of the pings which detect the unresponsive bots.
+	StreamKeepaliveSeconds int `yaml:"streamKeepaliveSeconds" json:"streamKeepaliveSeconds" default:"300" validate:"min=10"`
+	// KeepaliveSeconds is the interval of the pings which detect the broken connections when the
+	// bots are not streaming. The bots need to allow the pings as often as this. Zero disables the pings.
+	KeepaliveSeconds int `yaml:"keepaliveSeconds" json:"keepaliveSeconds" validate:"omitempty,min=10"`
+	// ReconnectMaxBackoffSeconds is the longest wait between the attempts to reconnect to a bot.
+	ReconnectMaxBackoffSeconds int `yaml:"reconnectMaxBackoffSeconds" json:"reconnectMaxBackoffSeconds" default:"30" validate:"min=1"`
+	// RequestLimits are the default request limits of the bots.
+	RequestLimits BotRequestLimits `yaml:"requestLimits" json:"requestLimits"`
+	// BotRequestLimits override the request limits for specific bots.
+	BotRequestLimits map[string]BotRequestLimits `yaml:"botRequestLimits" json:"botRequestLimits" validate:"dive"`
+	// MaxRequestLimits cap the request limits which the bots ask for in their manifests.
+	MaxRequestLimits BotMaxRequestLimits `yaml:"maxRequestLimits" json:"maxRequestLimits"`
+	// Overload configures how the requests are shed when the bots are slower than the chain.
+	Overload BotOverloadConfig `yaml:"overload" json:"overload"`
+	// PayloadRefs sends the large requests as references to the bots which support it.
+	PayloadRefs BotPayloadRefsConfig `yaml:"payloadRefs" json:"payloadRefs"`
+}
+
+// BotPayloadRefsConfig configures the fan-out of the large requests through a content-addressed store
+// in the scanner. A large request is written to the store once and the bots which declare the payloadRefs
+// capability and confirm it on initialization receive only a reference to fetch it from the store.
+type BotPayloadRefsConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// MinSizeKB is the size of the smallest request which is sent as a reference.
+	MinSizeKB int `yaml:"minSizeKb" json:"minSizeKb" default:"256" validate:"min=1"`
+	// MaxStoreSizeMB limits the memory used by the stored requests. The oldest ones are evicted first.
+	MaxStoreSizeMB int `yaml:"maxStoreSizeMb" json:"maxStoreSizeMb" default:"256" validate:"min=1"`
+	// TTLSeconds is how long the bots can fetch a stored request.
+	TTLSeconds int `yaml:"ttlSeconds" json:"ttlSeconds" default:"60" validate:"min=1"`
+}
+
+// BotMaxRequestLimits are the largest request limits which a bot manifest can set. The node config
+// overrides are not capped.
+type BotMaxRequestLimits struct {
+	TimeoutSeconds int `yaml:"timeoutSeconds" json:"timeoutSeconds" default:"300" validate:"min=1"`
+	MaxInFlight    int `yaml:"maxInFlight" json:"maxInFlight" default:"32" validate:"min=1"`
+	QueueDepth     int `yaml:"queueDepth" json:"queueDepth" default:"10000" validate:"min=1"`
+}
+
+// BotOverloadConfig configures the adaptive load shedding. The oldest queued requests of a bot are
+// dropped when the estimated time to evaluate them is too long compared to the block interval so
+// that the bots keep up with the newest blocks.
+type BotOverloadConfig struct {
+	Disable bool `yaml:"disable" json:"disable"`
+	// MaxBacklogBlocks is how many block intervals of queued requests a bot can have.
+	MaxBacklogBlocks int `yaml:"maxBacklogBlocks" json:"maxBacklogBlocks" default:"5" validate:"min=1"`
+	// BestEffortBots are shed first when the bots are behind on average, in addition to the bots
+	// which declare it in the manifest.
+	BestEffortBots []string `yaml:"bestEffortBots" json:"bestEffortBots"`
+}
+
+// BotAuthConfig configures how the node services authenticate the requests of the bots.
+type BotAuthConfig struct {
+	// Enable makes the supervisor inject signed tokens to the bots and the services validate them.
+	Enable bool `yaml:"enable" json:"enable"`
+	// DisableIPFallback rejects the bot requests without a token instead of authenticating by remote IP.
+	DisableIPFallback bool `yaml:"disableIpFallback" json:"disableIpFallback"`
+	// ScopedTokens makes the services accept only the short-lived tokens which the JWT provider issues
+	// for their audience. The token injected to the bot containers can then only be exchanged at the
+	// JWT provider from the bot container it was issued to.
+	ScopedTokens bool `yaml:"scopedTokens" json:"scopedTokens"`
+	// TokenTTLSeconds is how long the scoped tokens are valid for.
+	TokenTTLSeconds int `yaml:"tokenTtlSeconds" json:"tokenTtlSeconds" default:"900" validate:"min=60"`
+}
+
+// BotAttestationConfig configures the endpoint of the JWT provider where the bots get signed
+// statements of the scanner address, the chain ID and the latest block number to prove the
+// provenance of their findings.
+type BotAttestationConfig struct {
+	Disable         bool             `yaml:"disable" json:"disable"`
+	RateLimitConfig *RateLimitConfig `yaml:"rateLimit" json:"rateLimit"`
+	// BotRateLimits override the rate limit for specific bots.
+	BotRateLimits map[string]RateLimitConfig `yaml:"botRateLimits" json:"botRateLimits" validate:"dive"`
+	// TTLSeconds is how long the attestations are valid for.
+	TTLSeconds int `yaml:"ttlSeconds" json:"ttlSeconds" default:"300" validate:"min=30"`
+}
+
+// Bot teardown policies
+const (
+	BotTeardownAuto   = "auto"
+	BotTeardownAlways = "always"
+	BotTeardownNever  = "never"
+)
+
+// ShutdownConfig sets the timeouts of the shutdown stages. The services stop taking new blocks
+// first and then wait for the bots to finish their evaluations and for the publisher to publish
+// the findings before the proxies and the rest of the services are stopped.
+type ShutdownConfig struct {
+	IntakeTimeoutSeconds      int `yaml:"intakeTimeoutSeconds" json:"intakeTimeoutSeconds" default:"5" validate:"min=1"`
+	EvaluationsTimeoutSeconds int `yaml:"evaluationsTimeoutSeconds" json:"evaluationsTimeoutSeconds" default:"30" validate:"min=1"`
+	PublishTimeoutSeconds     int `yaml:"publishTimeoutSeconds" json:"publishTimeoutSeconds" default:"20" validate:"min=1"`
+	ProxyTimeoutSeconds       int `yaml:"proxyTimeoutSeconds" json:"proxyTimeoutSeconds" default:"10" validate:"min=1"`
+	TeardownTimeoutSeconds    int `yaml:"teardownTimeoutSeconds" json:"teardownTimeoutSeconds" default:"30" validate:"min=1"`
+	// BotTeardown tells if the supervisor removes the bots when it stops. The bots are kept for
+	// the next supervisor after a graceful shutdown if it is "auto".
+	BotTeardown string `yaml:"botTeardown" json:"botTeardown" default:"auto" validate:"oneof=auto always never"`
+}
+
+// DrainTimeout is how long the scanner can take to drain the evaluations and to publish the
+// findings after it is interrupted.
+func (cfg ShutdownConfig) DrainTimeout() time.Duration {
+	return time.Duration(cfg.IntakeTimeoutSeconds+cfg.EvaluationsTimeoutSeconds+cfg.PublishTimeoutSeconds) * time.Second
+}
+
+// ShouldTearDownBots tells if the bots should be removed according to the policy.
+func (cfg ShutdownConfig) ShouldTearDownBots(graceful bool) bool {
+	switch cfg.BotTeardown {
+	case BotTeardownAlways:
+		return true
+	case BotTeardownNever:
+		return false
+	default:
+		return !graceful
+	}
+}
+
 type ContainerRegistryConfig struct {
 	Username string `yaml:"username" json:"username"`
 	Password string `yaml:"password" json:"password"`
@@ -197,12 +885,247 @@ type CombinerConfig struct {
 	AlertAPIURL       string `yaml:"alertApiUrl" json:"alertApiUrl" default:"http://forta-public-api:8535" validate:"url"`
 	CombinerCachePath string `yaml:"alertCachePath" json:"alertCachePath"`
 	QueryInterval     uint64 `yaml:"queryInterval" json:"queryInterval"`
+	// AlertRateLimit limits the alerts delivered to each combiner bot. The alerts are not limited if this is not set.
+	AlertRateLimit *RateLimitConfig `yaml:"alertRateLimit" json:"alertRateLimit"`
+	// BotAlertRateLimits override the alert rate limit for specific bots.
+	BotAlertRateLimits map[string]RateLimitConfig `yaml:"botAlertRateLimits" json:"botAlertRateLimits" validate:"dive"`
 }
 
 type AdvancedConfig struct {
 	SafeOffset       bool   `yaml:"safeOffset" json:"safeOffset"`
 	IPFSExperiment   bool   `yaml:"ipfsExperiment" json:"ipfsExperiment"`
 	MulticallAddress string `yaml:"multicallAddress" json:"multicallAddress"`
+
+	BlueGreenUpgrades             bool `yaml:"blueGreenUpgrades" json:"blueGreenUpgrades"`
+	BlueGreenHealthTimeoutSeconds int  `yaml:"blueGreenHealthTimeoutSeconds" json:"blueGreenHealthTimeoutSeconds" default:"120"`
+}
+
+// TracingConfig configures exporting the spans of the request path to an OpenTelemetry collector.
+type TracingConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// OTLPEndpoint is the host:port of the OTLP gRPC receiver.
+	OTLPEndpoint string            `yaml:"otlpEndpoint" json:"otlpEndpoint" validate:"omitempty,hostname_port"`
+	Insecure     bool              `yaml:"insecure" json:"insecure"`
+	Headers      map[string]string `yaml:"headers" json:"headers"`
+	// SampleRatio is the ratio of the blocks and the txs to trace.
+	SampleRatio float64 `yaml:"sampleRatio" json:"sampleRatio" default:"1" validate:"min=0,max=1"`
+}
+
+// ProfilingConfig configures capturing the profiles of the supervisor automatically when it is
+// under memory or goroutine pressure.
+type ProfilingConfig struct {
+	AutoCapture bool `yaml:"autoCapture" json:"autoCapture"`
+	// Dir is where the profiles are written, relative to the Forta dir.
+	Dir                  string `yaml:"dir" json:"dir" default:"profiles"`
+	HeapThresholdMB      int    `yaml:"heapThresholdMb" json:"heapThresholdMb" default:"2048" validate:"min=1"`
+	GoroutineThreshold   int    `yaml:"goroutineThreshold" json:"goroutineThreshold" default:"10000" validate:"min=1"`
+	CheckIntervalSeconds int    `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"15" validate:"min=1"`
+	CPUProfileSeconds    int    `yaml:"cpuProfileSeconds" json:"cpuProfileSeconds" default:"10" validate:"min=0"`
+	CooldownMinutes      int    `yaml:"cooldownMinutes" json:"cooldownMinutes" default:"30" validate:"min=0"`
+	// MaxCaptures is how many of the latest captures are kept on disk.
+	MaxCaptures int `yaml:"maxCaptures" json:"maxCaptures" default:"10" validate:"min=1"`
+}
+
+// AlertingConfig configures the node-local rules which notify the operator about the
+// operational problems.
+type AlertingConfig struct {
+	Enable               bool `yaml:"enable" json:"enable"`
+	CheckIntervalSeconds int  `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"60" validate:"min=1"`
+	// RepeatIntervalMinutes is how often the operator is notified again while a rule keeps firing.
+	RepeatIntervalMinutes int `yaml:"repeatIntervalMinutes" json:"repeatIntervalMinutes" default:"60" validate:"min=1"`
+
+	Rules    AlertingRulesConfig     `yaml:"rules" json:"rules"`
+	Webhook  *WebhookNotifierConfig  `yaml:"webhook" json:"webhook"`
+	Email    *EmailNotifierConfig    `yaml:"email" json:"email"`
+	Telegram *TelegramNotifierConfig `yaml:"telegram" json:"telegram"`
+}
+
+// AlertingRulesConfig contains the thresholds of the alerting rules.
+type AlertingRulesConfig struct {
+	// Disabled contains the names of the rules to skip.
+	Disabled []string `yaml:"disabled" json:"disabled" validate:"dive,oneof=bot-crash-loop registry-sync-stale proxy-error-rate disk-space-low"`
+
+	BotRestarts             int     `yaml:"botRestarts" json:"botRestarts" default:"5" validate:"min=1"`
+	BotRestartWindowMinutes int     `yaml:"botRestartWindowMinutes" json:"botRestartWindowMinutes" default:"15" validate:"min=1"`
+	RegistryStaleMinutes    int     `yaml:"registryStaleMinutes" json:"registryStaleMinutes" default:"30" validate:"min=1"`
+	ProxyErrorRatePercent   float64 `yaml:"proxyErrorRatePercent" json:"proxyErrorRatePercent" default:"10" validate:"gt=0,max=100"`
+	// ProxyMinRequests is the minimum number of requests in a check interval to evaluate the proxy error rate.
+	ProxyMinRequests int     `yaml:"proxyMinRequests" json:"proxyMinRequests" default:"100" validate:"min=1"`
+	MinDiskSpaceGB   float64 `yaml:"minDiskSpaceGb" json:"minDiskSpaceGb" default:"5" validate:"gt=0"`
+}
+
+type WebhookNotifierConfig struct {
+	URL     string            `yaml:"url" json:"url" validate:"url"`
+	Headers map[string]string `yaml:"headers" json:"headers"`
+}
+
+// FindingSinkConfig configures sending the findings of the node to a webhook or a local file
+// of the operator in addition to publishing them.
+type FindingSinkConfig struct {
+	Enable     bool   `yaml:"enable" json:"enable"`
+	WebhookURL string `yaml:"webhookUrl" json:"webhookUrl" validate:"omitempty,url"`
+	// LogFileName is the name of the line-delimited JSON file in the logs dir. The findings are
+	// written to a file if no webhook is configured.
+	LogFileName string `yaml:"logFileName" json:"logFileName"`
+
+	BotIDs      []string `yaml:"botIds" json:"botIds"`
+	ChainIDs    []int    `yaml:"chainIds" json:"chainIds"`
+	MinSeverity string   `yaml:"minSeverity" json:"minSeverity" default:"UNKNOWN" validate:"oneof=UNKNOWN INFO LOW MEDIUM HIGH CRITICAL"`
+
+	// IntervalSeconds is how often the collected findings are sent.
+	IntervalSeconds int `yaml:"intervalSeconds" json:"intervalSeconds" default:"5" validate:"min=1"`
+}
+
+type EmailNotifierConfig struct {
+	// SMTPAddr is the host:port of the SMTP server.
+	SMTPAddr string   `yaml:"smtpAddr" json:"smtpAddr" validate:"hostname_port"`
+	Username string   `yaml:"username" json:"username"`
+	Password string   `yaml:"password" json:"password"`
+	From     string   `yaml:"from" json:"from" validate:"email"`
+	To       []string `yaml:"to" json:"to" validate:"min=1,dive,email"`
+}
+
+type TelegramNotifierConfig struct {
+	BotToken string `yaml:"botToken" json:"botToken" validate:"required"`
+	ChatID   string `yaml:"chatId" json:"chatId" validate:"required"`
+}
+
+// HealthHistoryConfig configures recording the health transitions of the node.
+type HealthHistoryConfig struct {
+	CheckIntervalSeconds int `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"30" validate:"min=1"`
+	MaxTransitions       int `yaml:"maxTransitions" json:"maxTransitions" default:"1000" validate:"min=1"`
+}
+
+// AuditLogConfig configures the local audit log of the bot lifecycle, admin API and config reload
+// actions. The entries are hash chained so that the changes to the log can be detected.
+type AuditLogConfig struct {
+	Disable bool `yaml:"disable" json:"disable"`
+	// Path is relative to the Forta dir unless it is absolute.
+	Path      string `yaml:"path" json:"path" default:"audit.log"`
+	MaxSizeMB int    `yaml:"maxSizeMb" json:"maxSizeMb" default:"10" validate:"min=1"`
+	// MaxBackups is the number of rotated files to keep. The rotated files are never removed if it is zero.
+	MaxBackups int `yaml:"maxBackups" json:"maxBackups" default:"0" validate:"min=0"`
+}
+
+// FilePath returns the path of the audit log.
+func (cfg AuditLogConfig) FilePath(fortaDir string) string {
+	if path.IsAbs(cfg.Path) {
+		return cfg.Path
+	}
+	return path.Join(fortaDir, cfg.Path)
+}
+
+// StakeInfoConfig configures checking the stake, the assigned bots, the rewards and the SLA score
+// of the node so that the operators can see what limits the assignments.
+type StakeInfoConfig struct {
+	Disable              bool `yaml:"disable" json:"disable"`
+	CheckIntervalSeconds int  `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"600" validate:"min=1"`
+	// SLAAPIURL serves the SLA score of the scanners at <url>/<scanner address>.
+	SLAAPIURL   string  `yaml:"slaApiUrl" json:"slaApiUrl" default:"https://api.forta.network/stats/sla/scanner" validate:"omitempty,url"`
+	MinSLAScore float64 `yaml:"minSlaScore" json:"minSlaScore" default:"0.75" validate:"min=0,max=1"`
+}
+
+// CrashReportConfig configures the reports which are written to the disk when a service panics
+// or exits with a fatal error. The secrets are redacted from the reports and they are uploaded
+// only if an upload URL is set.
+type CrashReportConfig struct {
+	Disable   bool   `yaml:"disable" json:"disable"`
+	UploadURL string `yaml:"uploadUrl" json:"uploadUrl" validate:"omitempty,url"`
+	// MaxReports is the number of reports to keep on the disk.
+	MaxReports int `yaml:"maxReports" json:"maxReports" default:"20" validate:"min=1"`
+	// MaxHealthTransitions is the number of the latest health transitions included in a report.
+	MaxHealthTransitions int `yaml:"maxHealthTransitions" json:"maxHealthTransitions" default:"50" validate:"min=0"`
+}
+
+// ReadinessConfig configures the periodic self-inspection which scores how ready the node is to
+// meet the SLA so that the problems are noticed before they cost rewards.
+type ReadinessConfig struct {
+	Disable              bool `yaml:"disable" json:"disable"`
+	CheckIntervalSeconds int  `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"60" validate:"min=1"`
+	// MinScore is the score below which the readiness is reported as lagging.
+	MinScore float64 `yaml:"minScore" json:"minScore" default:"0.8" validate:"min=0,max=1"`
+	// MinDiskSpaceGB and MinMemoryPercent are the free disk space and the available memory
+	// below which the headroom scores start to drop.
+	MinDiskSpaceGB   float64 `yaml:"minDiskSpaceGb" json:"minDiskSpaceGb" default:"10" validate:"gt=0"`
+	MinMemoryPercent float64 `yaml:"minMemoryPercent" json:"minMemoryPercent" default:"20" validate:"gt=0,max=100"`
+	// ConnectivityTargets are dialed in addition to the registry, the publisher and the IPFS hosts
+	// to check the outbound connectivity.
+	ConnectivityTargets []string `yaml:"connectivityTargets" json:"connectivityTargets" validate:"dive,hostname_port"`
+}
+
+// BotPrefetchConfig configures pulling the bot images before the bots are assigned so that
+// activating a bot takes about as long as starting its container.
+type BotPrefetchConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// Images are prefetched in addition to the upcoming bots of the registry.
+	Images []string `yaml:"images" json:"images"`
+	// MaxDiskMB limits the total size of the prefetched images which are not used by any running bots yet.
+	MaxDiskMB       int `yaml:"maxDiskMb" json:"maxDiskMb" default:"5120" validate:"min=1"`
+	IntervalSeconds int `yaml:"intervalSeconds" json:"intervalSeconds" default:"300" validate:"min=10"`
+}
+
+// ImagePullConfig configures the progress reports and the stall detection of the bot image pulls.
+type ImagePullConfig struct {
+	ProgressIntervalSeconds int `yaml:"progressIntervalSeconds" json:"progressIntervalSeconds" default:"30" validate:"min=1"`
+	// StallTimeoutSeconds is how long a pull can make no progress before it is aborted and retried.
+	StallTimeoutSeconds int `yaml:"stallTimeoutSeconds" json:"stallTimeoutSeconds" default:"120" validate:"min=10"`
+	StallRetries        int `yaml:"stallRetries" json:"stallRetries" default:"2" validate:"min=0"`
+}
+
+// ContainerNamingConfig configures the names of the bot containers so that multiple nodes can
+// run on the same host.
+type ContainerNamingConfig struct {
+	// Instance tells apart the bot containers of the nodes on the same host.
+	Instance string `yaml:"instance" json:"instance" validate:"omitempty,alphanum,max=16"`
+	// BotTemplate is the Go template of the bot container names. The template can use .Prefix,
+	// .Instance, .BotID, .Digest, .ShardID and .Sharded.
+	BotTemplate string `yaml:"botTemplate" json:"botTemplate"`
+}
+
+// ScannerInstanceConfig is an additional scanner which the supervisor runs on the same host with
+// its own key, config, networks and bots. The scanners share the Docker daemon and the images.
+type ScannerInstanceConfig struct {
+	// Instance is added to the names of the containers and the networks of the scanner.
+	Instance string `yaml:"instance" json:"instance" validate:"required,alphanum,max=16"`
+	// FortaDir is the host dir which contains the config and the keys of the scanner.
+	FortaDir string `yaml:"fortaDir" json:"fortaDir" validate:"required"`
+	// Passphrase decrypts the key of the scanner and can be a secret reference.
+	Passphrase string `yaml:"passphrase" json:"-" validate:"required"`
+}
+
+// BotRecordingConfig records the requests which are sent to the selected bots to files so that
+// the requests can be replayed to the bot images to reproduce the issues.
+type BotRecordingConfig struct {
+	Enable bool     `yaml:"enable" json:"enable"`
+	BotIDs []string `yaml:"botIds" json:"botIds"`
+	// SampleRate is the share of the requests which are recorded.
+	SampleRate float64 `yaml:"sampleRate" json:"sampleRate" default:"1" validate:"min=0,max=1"`
+	// FromBlock and ToBlock limit the recording to a block range when they are not zero.
+	FromBlock     uint64 `yaml:"fromBlock" json:"fromBlock"`
+	ToBlock       uint64 `yaml:"toBlock" json:"toBlock"`
+	MaxFileSizeMB int    `yaml:"maxFileSizeMb" json:"maxFileSizeMb" default:"100" validate:"min=1"`
+	// MaxFiles is how many files are kept for each bot. The oldest files are removed.
+	MaxFiles int `yaml:"maxFiles" json:"maxFiles" default:"10" validate:"min=1"`
+}
+
+// FaultInjectionConfig makes the clients fail at the given probabilities so that the resilience
+// of the node can be tested in staging. It should never be enabled in production.
+type FaultInjectionConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// Seed makes the injected faults repeatable when it is not zero.
+	Seed int64 `yaml:"seed" json:"seed"`
+
+	DockerTimeout FaultConfig `yaml:"dockerTimeout" json:"dockerTimeout"`
+	IPFSNotFound  FaultConfig `yaml:"ipfsNotFound" json:"ipfsNotFound"`
+	RPCRateLimit  FaultConfig `yaml:"rpcRateLimit" json:"rpcRateLimit"`
+	GRPCReset     FaultConfig `yaml:"grpcReset" json:"grpcReset"`
+}
+
+// FaultConfig configures an injected fault.
+type FaultConfig struct {
+	Probability float64 `yaml:"probability" json:"probability" validate:"min=0,max=1"`
+	// DelayMs is how long the client waits before it fails.
+	DelayMs int `yaml:"delayMs" json:"delayMs" validate:"min=0"`
 }
 
 type Config struct {
@@ -212,6 +1135,9 @@ type Config struct {
 	FortaDir    string `yaml:"-" json:"_fortaDir"`
 	KeyDirPath  string `yaml:"-" json:"_keyDirPath"`
 	Passphrase  string `yaml:"-" json:"_passphrase"`
+	// ForwardedEnv are the env vars which the containers need to apply the same overrides and
+	// resolve the same secrets.
+	ForwardedEnv map[string]string `yaml:"-" json:"-"`
 
 	// yaml config values
 
@@ -220,21 +1146,47 @@ type Config struct {
 	Scan  ScannerConfig `yaml:"scan" json:"scan"`
 	Trace TraceConfig   `yaml:"trace" json:"trace"`
 
-	Registry         RegistryConfig       `yaml:"registry" json:"registry"`
-	Publish          PublisherConfig      `yaml:"publish" json:"publish"`
-	JsonRpcProxy     JsonRpcProxyConfig   `yaml:"jsonRpcProxy" json:"jsonRpcProxy"`
-	PublicAPIProxy   PublicAPIProxyConfig `yaml:"publicApiProxy" json:"publicApiProxy"`
-	Log              LogConfig            `yaml:"log" json:"log"`
-	ResourcesConfig  ResourcesConfig      `yaml:"resources" json:"resources"`
-	ENSConfig        ENSConfig            `yaml:"ens" json:"ens"`
-	TelemetryConfig  TelemetryConfig      `yaml:"telemetry" json:"telemetry"`
-	AutoUpdate       AutoUpdateConfig     `yaml:"autoUpdate" json:"autoUpdate"`
-	AgentLogsConfig  AgentLogsConfig      `yaml:"agentLogs" json:"agentLogs"`
-	LocalModeConfig  LocalModeConfig      `yaml:"localMode" json:"localMode"`
-	InspectionConfig InspectionConfig     `yaml:"inspection" json:"inspection"`
-	StorageConfig    StorageConfig        `yaml:"storage" json:"storage"`
-	CombinerConfig   CombinerConfig       `yaml:"combiner" json:"combiner"`
-	AdvancedConfig   AdvancedConfig       `yaml:"advanced" json:"advanced"`
+	Registry         RegistryConfig          `yaml:"registry" json:"registry"`
+	Publish          PublisherConfig         `yaml:"publish" json:"publish"`
+	JsonRpcProxy     JsonRpcProxyConfig      `yaml:"jsonRpcProxy" json:"jsonRpcProxy"`
+	PublicAPIProxy   PublicAPIProxyConfig    `yaml:"publicApiProxy" json:"publicApiProxy"`
+	Log              LogConfig               `yaml:"log" json:"log"`
+	ResourcesConfig  ResourcesConfig         `yaml:"resources" json:"resources"`
+	ENSConfig        ENSConfig               `yaml:"ens" json:"ens"`
+	TelemetryConfig  TelemetryConfig         `yaml:"telemetry" json:"telemetry"`
+	BotStats         BotStatsConfig          `yaml:"botStats" json:"botStats"`
+	AutoUpdate       AutoUpdateConfig        `yaml:"autoUpdate" json:"autoUpdate"`
+	AgentLogsConfig  AgentLogsConfig         `yaml:"agentLogs" json:"agentLogs"`
+	BotAuth          BotAuthConfig           `yaml:"botAuth" json:"botAuth"`
+	AgentGrpc        AgentGrpcConfig         `yaml:"agentGrpc" json:"agentGrpc"`
+	LocalModeConfig  LocalModeConfig         `yaml:"localMode" json:"localMode"`
+	InspectionConfig InspectionConfig        `yaml:"inspection" json:"inspection"`
+	StorageConfig    StorageConfig           `yaml:"storage" json:"storage"`
+	CombinerConfig   CombinerConfig          `yaml:"combiner" json:"combiner"`
+	AdvancedConfig   AdvancedConfig          `yaml:"advanced" json:"advanced"`
+	Tracing          TracingConfig           `yaml:"tracing" json:"tracing"`
+	Profiling        ProfilingConfig         `yaml:"profiling" json:"profiling"`
+	Alerting         AlertingConfig          `yaml:"alerting" json:"alerting"`
+	HealthHistory    HealthHistoryConfig     `yaml:"healthHistory" json:"healthHistory"`
+	FindingSink      FindingSinkConfig       `yaml:"findingSink" json:"findingSink"`
+	Dev              DevModeConfig           `yaml:"dev" json:"dev"`
+	Orchestrator     OrchestratorConfig      `yaml:"orchestrator" json:"orchestrator"`
+	Signer           SignerConfig            `yaml:"signer" json:"signer"`
+	StakeInfo        StakeInfoConfig         `yaml:"stakeInfo" json:"stakeInfo"`
+	Readiness        ReadinessConfig         `yaml:"readiness" json:"readiness"`
+	BotEgress        BotEgressConfig         `yaml:"botEgress" json:"botEgress"`
+	BotSecrets       BotSecretsConfig        `yaml:"botSecrets" json:"-" validate:"dive,dive"`
+	BotPrefetch      BotPrefetchConfig       `yaml:"botPrefetch" json:"botPrefetch"`
+	ImagePull        ImagePullConfig         `yaml:"imagePull" json:"imagePull"`
+	ContainerNaming  ContainerNamingConfig   `yaml:"containerNaming" json:"containerNaming"`
+	Scanners         []ScannerInstanceConfig `yaml:"scanners" json:"scanners" validate:"dive"`
+	BotPolicy        BotPolicyConfig         `yaml:"botPolicy" json:"botPolicy"`
+	AuditLog         AuditLogConfig          `yaml:"auditLog" json:"auditLog"`
+	BotRecording     BotRecordingConfig      `yaml:"botRecording" json:"botRecording"`
+	FaultInjection   FaultInjectionConfig    `yaml:"faultInjection" json:"faultInjection"`
+	CrashReport      CrashReportConfig       `yaml:"crashReport" json:"crashReport"`
+	BotAttestation   BotAttestationConfig    `yaml:"botAttestation" json:"botAttestation"`
+	Shutdown         ShutdownConfig          `yaml:"shutdown" json:"shutdown"`
 }
 
 func (cfg *Config) ConfigFilePath() string {
@@ -248,6 +1200,9 @@ func GetConfigForContainer() (Config, error) {
 		return Config{}, err
 	}
 	applyContextDefaults(&cfg)
+	if err := ApplyOverrides(&cfg); err != nil {
+		return Config{}, err
+	}
 
 	// initialize combiner cache dump path if cache is persistent
 	if cfg.CombinerConfig.CombinerCachePath != "" {
@@ -266,8 +1221,29 @@ func GetConfigForContainer() (Config, error) {
 	return cfg, nil
 }
 
+// ChainIDs returns the chain the node scans followed by the other chains served by the JSON-RPC proxy.
+func (cfg *Config) ChainIDs() []int {
+	chainIDs := []int{cfg.ChainID}
+	for _, instance := range cfg.JsonRpcProxy.Instances {
+		var found bool
+		for _, chainID := range chainIDs {
+			if chainID == instance.ChainID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			chainIDs = append(chainIDs, instance.ChainID)
+		}
+	}
+	return chainIDs
+}
+
 // BotsToWait returns the count of the bots to wait.
 func (cfg *Config) BotsToWait() (waitBots int) {
+	if cfg.Dev.Enable {
+		return len(cfg.Dev.Bots)
+	}
 	if !cfg.LocalModeConfig.Enable {
 		return
 	}
@@ -294,6 +1270,11 @@ func applyContextDefaults(cfg *Config) {
 	cfg.FortaDir = DefaultContainerFortaDirPath
 	cfg.KeyDirPath = path.Join(cfg.FortaDir, DefaultKeysDirName)
 	cfg.CombinerConfig.CombinerCachePath = path.Join(cfg.FortaDir, DefaultCombinerCacheFileName)
+	cfg.Registry.ManifestCacheDir = path.Join(cfg.FortaDir, DefaultManifestCacheDirName)
+	cfg.Registry.SnapshotDir = path.Join(cfg.FortaDir, DefaultSnapshotDirName)
+	if cfg.Dev.DockerDesktop.Enable {
+		applyDockerDesktop(cfg)
+	}
 }
 
 func getConfigFromFile() (cfg Config, err error) {
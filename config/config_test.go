@@ -3,6 +3,7 @@ package config
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -26,4 +27,51 @@ func TestApplyContextDefaults(t *testing.T) {
 	r.Equal(DefaultContainerFortaDirPath, cfg.FortaDir)
 	r.Equal(path.Join(cfg.FortaDir, DefaultKeysDirName), cfg.KeyDirPath)
 	r.Equal(path.Join(cfg.FortaDir, DefaultCombinerCacheFileName), cfg.CombinerConfig.CombinerCachePath)
+	r.Equal(path.Join(cfg.FortaDir, DefaultManifestCacheDirName), cfg.Registry.ManifestCacheDir)
+	r.Equal(path.Join(cfg.FortaDir, DefaultSnapshotDirName), cfg.Registry.SnapshotDir)
+}
+
+func TestJsonRpcProxyPort(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(DefaultJSONRPCProxyPort, JsonRpcProxyConfig{}.Port())
+	r.Equal("9545", JsonRpcProxyConfig{ListenAddr: ":9545"}.Port())
+	r.Equal("8547", JsonRpcProxyInstanceConfig{ChainID: 137, ListenAddr: "0.0.0.0:8547"}.Port())
+	r.Equal([]string{"JSON_RPC_PORT_137"}, JsonRpcProxyInstanceConfig{ChainID: 137}.EnvPortNames())
+	r.Equal(
+		[]string{"JSON_RPC_PORT_137", "JSON_RPC_PORT_POLYGON"},
+		JsonRpcProxyInstanceConfig{ChainID: 137, Name: "polygon"}.EnvPortNames(),
+	)
+}
+
+func TestValidateMethodCosts(t *testing.T) {
+	r := require.New(t)
+
+	cfg := JsonRpcProxyConfig{
+		RateLimitConfig: &RateLimitConfig{Rate: 10, Burst: 20},
+		MethodCosts:     map[string]int{"trace_*": 20},
+		BotRateLimits:   map[string]RateLimitConfig{"0xbot": {Rate: 100, Burst: 100}},
+	}
+	r.NoError(cfg.validateMethodCosts(1))
+
+	cfg.BotRateLimits["0xslowbot"] = RateLimitConfig{Rate: 1, Burst: 10}
+	r.Error(cfg.validateMethodCosts(1))
+
+	cfg.BotRateLimits = nil
+	cfg.MethodCosts["debug_*"] = 21
+	r.Error(cfg.validateMethodCosts(1))
+}
+
+func TestShutdownConfig(t *testing.T) {
+	r := require.New(t)
+
+	cfg := ShutdownConfig{IntakeTimeoutSeconds: 5, EvaluationsTimeoutSeconds: 30, PublishTimeoutSeconds: 20}
+	r.Equal(time.Second*55, cfg.DrainTimeout())
+
+	r.True(cfg.ShouldTearDownBots(false))
+	r.False(cfg.ShouldTearDownBots(true))
+	cfg.BotTeardown = BotTeardownAlways
+	r.True(cfg.ShouldTearDownBots(true))
+	cfg.BotTeardown = BotTeardownNever
+	r.False(cfg.ShouldTearDownBots(false))
 }
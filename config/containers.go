@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"path"
+	"regexp"
+	"strings"
+	"text/template"
 )
 
 const ContainerNamePrefix = "forta"
@@ -32,3 +35,95 @@ var (
 	DefaultContainerWrappedConfigPath = path.Join(DefaultContainerFortaDirPath, DefaultWrappedConfigFileName)
 	DefaultContainerKeyDirPath        = path.Join(DefaultContainerFortaDirPath, DefaultKeysDirName)
 )
+
+// EnvContainerNamingInstance sets the instance of the scanners which are run by another supervisor.
+const EnvContainerNamingInstance = EnvConfigOverridePrefix + "CONTAINER_NAMING_INSTANCE"
+
+// ServiceContainerName returns the name of a service container of the node instance.
+func ServiceContainerName(instance, service string) string {
+	if len(instance) == 0 {
+		return fmt.Sprintf("%s-%s", ContainerNamePrefix, service)
+	}
+	return fmt.Sprintf("%s-%s-%s", ContainerNamePrefix, instance, service)
+}
+
+// setServiceContainerNames sets the names of the service containers and the node network so that
+// the nodes on the same host do not use the same ones.
+func setServiceContainerNames(instance string) {
+	DockerUpdaterContainerName = ServiceContainerName(instance, "updater")
+	DockerSupervisorContainerName = ServiceContainerName(instance, "supervisor")
+	DockerNatsContainerName = ServiceContainerName(instance, "nats")
+	DockerIpfsContainerName = ServiceContainerName(instance, "ipfs")
+	DockerScannerContainerName = ServiceContainerName(instance, "scanner")
+	DockerInspectorContainerName = ServiceContainerName(instance, "inspector")
+	DockerJSONRPCProxyContainerName = ServiceContainerName(instance, "json-rpc")
+	DockerPublicAPIProxyContainerName = ServiceContainerName(instance, "public-api")
+	DockerJWTProviderContainerName = ServiceContainerName(instance, "jwt-provider")
+	DockerStorageContainerName = ServiceContainerName(instance, "storage")
+	DockerNetworkName = DockerScannerContainerName
+}
+
+// DefaultBotContainerNameTemplate keeps the bot container names of a single node as they were.
+const DefaultBotContainerNameTemplate = "{{.Prefix}}-agent{{if .Instance}}-{{.Instance}}{{end}}-{{.BotID}}{{if .Digest}}-{{.Digest}}{{end}}"
+
+// BotContainerNameData is the data of the bot container name template.
+type BotContainerNameData struct {
+	Prefix   string
+	Instance string
+	// BotID and Digest are the short versions of the bot ID and the image digest.
+	BotID   string
+	Digest  string
+	ShardID uint
+	Sharded bool
+}
+
+var validContainerName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+var defaultBotContainerNameTmpl = template.Must(template.New("bot-container-name").Parse(DefaultBotContainerNameTemplate))
+
+var botContainerNaming = struct {
+	instance string
+	tmpl     *template.Template
+}{
+	tmpl: defaultBotContainerNameTmpl,
+}
+
+// SetContainerNaming sets the naming scheme of the bot and the service containers. It should be
+// called before any containers are managed.
+func SetContainerNaming(cfg ContainerNamingConfig) error {
+	botTemplate := cfg.BotTemplate
+	if len(botTemplate) == 0 {
+		botTemplate = DefaultBotContainerNameTemplate
+	}
+	tmpl, err := template.New("bot-container-name").Parse(botTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse the bot container name template: %v", err)
+	}
+	// make sure that the template produces valid names
+	name, err := execBotContainerName(tmpl, BotContainerNameData{
+		Prefix: ContainerNamePrefix, Instance: cfg.Instance, BotID: "0x123456", Digest: "abcd", ShardID: 1, Sharded: true,
+	})
+	if err != nil {
+		return err
+	}
+	if !validContainerName.MatchString(name) {
+		return fmt.Errorf("the bot container name template produces invalid names like '%s'", name)
+	}
+	botContainerNaming.instance = cfg.Instance
+	botContainerNaming.tmpl = tmpl
+	setServiceContainerNames(cfg.Instance)
+	return nil
+}
+
+// ContainerNamingInstance returns the instance which the bot containers of this node belong to.
+func ContainerNamingInstance() string {
+	return botContainerNaming.instance
+}
+
+func execBotContainerName(tmpl *template.Template, data BotContainerNameData) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute the bot container name template: %v", err)
+	}
+	return buf.String(), nil
+}
@@ -1,17 +1,38 @@
 package config
 
 const (
-	DefaultKeysDirName           = ".keys"
-	DefaultCombinerCacheFileName = ".combiner_cache.json"
-	DefaultConfigFileName        = "config.yml"
-	DefaultWrappedConfigFileName = "wrapped-config.yml"
-	DefaultConfigWrapperKey      = "x-forta-config"
-	DefaultNatsPort              = "4222"
-	DefaultContainerPort         = "8089"
-	DefaultHealthPort            = "8090"
-	DefaultJWTProviderPort       = "8515"
-	DefaultStoragePort           = "8525"
-	DefaultPublicAPIProxyPort    = "8535"
-	DefaultJSONRPCProxyPort      = "8545"
-	DefaultFortaNodeBinaryPath   = "/forta-node" // the path for the common binary in the container image
+	DefaultKeysDirName            = ".keys"
+	DefaultNextKeysDirName        = ".keys-next"
+	DefaultOldKeysDirName         = ".keys-old"
+	DefaultCombinerCacheFileName  = ".combiner_cache.json"
+	DefaultManifestCacheDirName   = ".manifests"
+	DefaultSnapshotDirName        = ".snapshot"
+	DefaultHealthHistoryFileName  = ".health-history"
+	DefaultCheckpointFileName     = ".scanner-checkpoint.json"
+	DefaultBatchQueueDirName      = ".batch-queue"
+	DefaultMetricsBufferFileName  = ".metrics-buffer"
+	DefaultLastBatchFileName      = ".last-batch"
+	DefaultLastReceiptFileName    = ".last-receipt"
+	DefaultPublishKeysFileName    = ".publish-keys"
+	DefaultUpgradeHandoffFileName = ".upgrade-handoff"
+	DefaultRecordingsDirName      = "recordings"
+	DefaultCrashReportsDirName    = "crash-reports"
+	DefaultConfigFileName         = "config.yml"
+	DefaultWrappedConfigFileName  = "wrapped-config.yml"
+	DefaultConfigWrapperKey       = "x-forta-config"
+	DefaultNatsPort               = "4222"
+	DefaultContainerPort          = "8089"
+	DefaultHealthPort             = "8090"
+	DefaultSupervisorAdminPort    = "8095"
+	DefaultJWTProviderPort        = "8515"
+	DefaultStoragePort            = "8525"
+	DefaultPublicAPIProxyPort     = "8535"
+	DefaultJSONRPCProxyPort       = "8545"
+	DefaultJSONRPCProxyTLSPort    = "8546"
+	DefaultBlockDataPort          = "8555"
+	DefaultScannerAdminPort       = "8565"
+	DefaultEgressProxyPort        = "8575"
+	DefaultBotGatewayPort         = "8585"
+	DefaultPayloadStorePort       = "8595"
+	DefaultFortaNodeBinaryPath    = "/forta-node" // the path for the common binary in the container image
 )
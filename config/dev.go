@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Docker Desktop defaults
+const (
+	DefaultDockerDesktopPipe = "npipe:////./pipe/docker_engine"
+	DefaultDockerUnixHost    = "unix:///var/run/docker.sock"
+	// dockerDesktopHostMount is where Docker Desktop mounts the Windows drives in its VM.
+	dockerDesktopHostMount = "/run/desktop/mnt/host"
+	dockerDesktopHostName  = "host.docker.internal"
+)
+
+var windowsPathRegexp = regexp.MustCompile(`^([a-zA-Z]):[\\/]`)
+
+// DevModeConfig runs the scanner, the JSON-RPC proxy and the bots on the host in a single process,
+// without the supervisor and the updater. It is meant for the bot developers and the CI. The
+// development mode implies the local mode so the alerts are sent to the local mode webhook or logged.
+type DevModeConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// NatsPort is the port of the embedded NATS server.
+	NatsPort string `yaml:"natsPort" json:"natsPort" default:"4222"`
+	// Bots are run as local processes or plain containers, or dialed if they are already running.
+	Bots []DevBotConfig `yaml:"bots" json:"bots" validate:"dive"`
+	// DockerDesktop is used when the node runs with the supervisor, regardless of the dev mode.
+	DockerDesktop DockerDesktopConfig `yaml:"dockerDesktop" json:"dockerDesktop"`
+}
+
+// DockerDesktopConfig runs a reduced node with the supervisor on Docker Desktop for macOS and
+// Windows. The node containers run in the Linux VM of Docker Desktop so the host paths and the
+// loopback addresses in the config are translated for them.
+type DockerDesktopConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// Host is the address of the Docker daemon. It defaults to $DOCKER_HOST or the default
+	// socket or named pipe of Docker Desktop on this platform.
+	Host string `yaml:"host" json:"host" validate:"omitempty,startswith=unix://|startswith=npipe://|startswith=tcp://"`
+}
+
+// DockerHost returns the address of the Docker daemon which the CLI connects to.
+func (ddc DockerDesktopConfig) DockerHost() string {
+	if len(ddc.Host) > 0 {
+		return ddc.Host
+	}
+	if dockerHost := os.Getenv("DOCKER_HOST"); len(dockerHost) > 0 {
+		return dockerHost
+	}
+	homeDir, _ := os.UserHomeDir()
+	return defaultDockerDesktopHost(runtime.GOOS, homeDir)
+}
+
+// defaultDockerDesktopHost returns the named pipe on Windows. The newer versions of Docker Desktop
+// for macOS create the socket in the home dir and link it to the default path only if allowed.
+func defaultDockerDesktopHost(goos, homeDir string) string {
+	switch goos {
+	case "windows":
+		return DefaultDockerDesktopPipe
+	case "darwin":
+		userSocket := path.Join(homeDir, ".docker", "run", "docker.sock")
+		if _, err := os.Stat(userSocket); err == nil {
+			return "unix://" + userSocket
+		}
+	}
+	return DefaultDockerUnixHost
+}
+
+// HostPath translates a Windows path on the host to the path in the Docker Desktop VM so that it
+// can be mounted to the containers. The other paths are not changed.
+func (ddc DockerDesktopConfig) HostPath(hostPath string) string {
+	if !ddc.Enable {
+		return hostPath
+	}
+	match := windowsPathRegexp.FindStringSubmatch(hostPath)
+	if match == nil {
+		return hostPath
+	}
+	rest := strings.ReplaceAll(hostPath[len(match[0]):], "\\", "/")
+	return path.Join(dockerDesktopHostMount, strings.ToLower(match[1]), rest)
+}
+
+// ContainerURL makes the APIs which listen on the loopback interface of the host reachable from
+// the containers because Docker Desktop does not support the host network.
+func (ddc DockerDesktopConfig) ContainerURL(rawURL string) string {
+	if !ddc.Enable {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+	default:
+		return rawURL
+	}
+	port := u.Port()
+	u.Host = dockerDesktopHostName
+	if len(port) > 0 {
+		u.Host = net.JoinHostPort(dockerDesktopHostName, port)
+	}
+	return u.String()
+}
+
+// applyDockerDesktop translates the JSON-RPC API URLs for the containers.
+func applyDockerDesktop(cfg *Config) {
+	ddc := cfg.Dev.DockerDesktop
+	rawURLs := []*string{
+		&cfg.Scan.JsonRpc.Url, &cfg.Scan.Mempool.JsonRpc.Url, &cfg.Trace.JsonRpc.Url,
+		&cfg.JsonRpcProxy.JsonRpc.Url, &cfg.Registry.JsonRpc.Url,
+	}
+	rawURLs = append(rawURLs, upstreamURLs(cfg.JsonRpcProxy.Upstreams)...)
+	for i := range cfg.JsonRpcProxy.Instances {
+		instance := &cfg.JsonRpcProxy.Instances[i]
+		rawURLs = append(rawURLs, &instance.JsonRpc.Url)
+		rawURLs = append(rawURLs, upstreamURLs(instance.Upstreams)...)
+	}
+	for _, rawURL := range rawURLs {
+		*rawURL = ddc.ContainerURL(*rawURL)
+	}
+}
+
+func upstreamURLs(upstreams []JsonRpcUpstreamConfig) (rawURLs []*string) {
+	for i := range upstreams {
+		rawURLs = append(rawURLs, &upstreams[i].Url)
+	}
+	return
+}
+
+// DevBotConfig is a bot which runs in the development mode.
+type DevBotConfig struct {
+	ID string `yaml:"id" json:"id" validate:"required"`
+	// Address is the gRPC address of a bot which is already running.
+	Address string `yaml:"address" json:"address" validate:"omitempty,hostname_port"`
+	// Command runs the bot as a local process.
+	Command []string `yaml:"command" json:"command"`
+	// Image runs the bot as a plain container.
+	Image string `yaml:"image" json:"image"`
+	// Port is the host port which the bot serves gRPC on if the bot is started by the node.
+	Port string `yaml:"port" json:"port" validate:"required_without=Address"`
+	// Env is added to the environment of the bot process or the container.
+	Env map[string]string `yaml:"env" json:"env"`
+}
+
+// GrpcAddress returns the address to dial the bot.
+func (bot DevBotConfig) GrpcAddress() string {
+	if len(bot.Address) > 0 {
+		return bot.Address
+	}
+	return fmt.Sprintf("localhost:%s", bot.Port)
+}
+
+// AgentConfigs returns the configs of the development bots.
+func (dmc DevModeConfig) AgentConfigs(chainID int) (agentConfigs []AgentConfig) {
+	for _, bot := range dmc.Bots {
+		agentConfigs = append(agentConfigs, AgentConfig{
+			ID:      bot.ID,
+			Image:   bot.Image,
+			ChainID: chainID,
+			Address: bot.GrpcAddress(),
+		})
+	}
+	return
+}
+
+// NatsURL returns the address of the NATS server which the services connect to.
+func (cfg Config) NatsURL() string {
+	if cfg.Dev.Enable {
+		natsPort := cfg.Dev.NatsPort
+		if len(natsPort) == 0 {
+			natsPort = DefaultNatsPort
+		}
+		return fmt.Sprintf("localhost:%s", natsPort)
+	}
+	if cfg.Orchestrator.Enable && len(cfg.Orchestrator.NatsURL) > 0 {
+		return cfg.Orchestrator.NatsURL
+	}
+	return fmt.Sprintf("%s:%s", DockerNatsContainerName, DefaultNatsPort)
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevModeAgentConfigs(t *testing.T) {
+	r := require.New(t)
+
+	dmc := DevModeConfig{
+		Bots: []DevBotConfig{
+			{ID: "0x1", Port: "50061", Command: []string{"npm", "start"}},
+			{ID: "0x2", Address: "10.0.0.2:50051"},
+		},
+	}
+
+	agentConfigs := dmc.AgentConfigs(137)
+	r.Len(agentConfigs, 2)
+	r.Equal("localhost:50061", agentConfigs[0].GrpcAddress())
+	r.Equal("10.0.0.2:50051", agentConfigs[1].GrpcAddress())
+	r.Equal(137, agentConfigs[1].ChainID)
+}
+
+func TestNatsURL(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.Equal(DockerNatsContainerName+":4222", cfg.NatsURL())
+
+	cfg.Dev.Enable = true
+	r.Equal("localhost:4222", cfg.NatsURL())
+	cfg.Dev.NatsPort = "4333"
+	r.Equal("localhost:4333", cfg.NatsURL())
+}
+
+func TestDockerDesktop(t *testing.T) {
+	r := require.New(t)
+
+	homeDir := t.TempDir()
+	r.Equal(DefaultDockerDesktopPipe, defaultDockerDesktopHost("windows", homeDir))
+	r.Equal(DefaultDockerUnixHost, defaultDockerDesktopHost("darwin", homeDir))
+	r.NoError(os.MkdirAll(path.Join(homeDir, ".docker", "run"), 0755))
+	r.NoError(os.WriteFile(path.Join(homeDir, ".docker", "run", "docker.sock"), nil, 0644))
+	r.Equal("unix://"+homeDir+"/.docker/run/docker.sock", defaultDockerDesktopHost("darwin", homeDir))
+	r.Equal(DefaultDockerUnixHost, defaultDockerDesktopHost("linux", homeDir))
+
+	ddc := DockerDesktopConfig{Host: "tcp://localhost:2375"}
+	r.Equal("tcp://localhost:2375", ddc.DockerHost())
+	r.Equal(`C:\Users\dev\.forta`, ddc.HostPath(`C:\Users\dev\.forta`))
+	r.Equal("http://localhost:8545", ddc.ContainerURL("http://localhost:8545"))
+
+	ddc.Enable = true
+	r.Equal("/run/desktop/mnt/host/c/Users/dev/.forta", ddc.HostPath(`C:\Users\dev\.forta`))
+	r.Equal("/Users/dev/.forta", ddc.HostPath("/Users/dev/.forta"))
+	r.Equal("http://host.docker.internal:8545/rpc", ddc.ContainerURL("http://localhost:8545/rpc"))
+	r.Equal("ws://host.docker.internal:8546", ddc.ContainerURL("ws://127.0.0.1:8546"))
+	r.Equal("http://host.docker.internal", ddc.ContainerURL("http://[::1]"))
+	r.Equal("https://polygon-rpc.com", ddc.ContainerURL("https://polygon-rpc.com"))
+}
+
+func TestApplyDockerDesktop(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	cfg.Dev.DockerDesktop.Enable = true
+	cfg.Scan.JsonRpc.Url = "http://localhost:8545"
+	cfg.Trace.JsonRpc.Url = "https://trace.example.com"
+	cfg.JsonRpcProxy.Upstreams = []JsonRpcUpstreamConfig{{JsonRpcConfig: JsonRpcConfig{Url: "http://127.0.0.1:8547"}}}
+	cfg.JsonRpcProxy.Instances = []JsonRpcProxyInstanceConfig{
+		{JsonRpc: JsonRpcConfig{Url: "http://localhost:8548"}},
+	}
+	applyContextDefaults(&cfg)
+
+	r.Equal("http://host.docker.internal:8545", cfg.Scan.JsonRpc.Url)
+	r.Equal("https://trace.example.com", cfg.Trace.JsonRpc.Url)
+	r.Equal("http://host.docker.internal:8547", cfg.JsonRpcProxy.Upstreams[0].Url)
+	r.Equal("http://host.docker.internal:8548", cfg.JsonRpcProxy.Instances[0].JsonRpc.Url)
+	r.Empty(cfg.Registry.JsonRpc.Url)
+}
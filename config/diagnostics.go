@@ -0,0 +1,406 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-connections/sockets"
+	"github.com/go-playground/validator/v10"
+)
+
+// DefaultDockerSocketPath is the path of the Docker socket which the node containers use.
+const DefaultDockerSocketPath = "/var/run/docker.sock"
+
+// Diagnostic severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is a problem found in the config with a hint about how to fix it.
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+	Hint     string `json:"hint,omitempty"`
+}
+
+// Diagnostics are the problems found in the config.
+type Diagnostics []*Diagnostic
+
+// HasErrors tells if any of the diagnostics is an error.
+func (diagnostics Diagnostics) HasErrors() bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Diagnoser checks the config beyond the validation tags.
+type Diagnoser struct {
+	// DockerSocketPath is a socket path or a unix://, npipe:// or tcp:// Docker daemon address.
+	DockerSocketPath string
+	HTTPClient       *http.Client
+}
+
+// NewDiagnoser creates a new diagnoser.
+func NewDiagnoser() *Diagnoser {
+	return &Diagnoser{
+		DockerSocketPath: DefaultDockerSocketPath,
+		HTTPClient:       &http.Client{Timeout: time.Second * 10},
+	}
+}
+
+// Diagnose checks the field validations, the JSON-RPC method costs, the JSON-RPC APIs and their chain IDs, the key file
+// permissions, the port conflicts, the additional scanners and the Docker socket access.
+func (d *Diagnoser) Diagnose(cfg Config) (diagnostics Diagnostics) {
+	diagnostics = append(diagnostics, d.checkFields(cfg)...)
+	diagnostics = append(diagnostics, d.checkMethodCosts(cfg)...)
+	diagnostics = append(diagnostics, d.checkJsonRpcAPIs(cfg)...)
+	diagnostics = append(diagnostics, d.checkKeyFiles(cfg)...)
+	diagnostics = append(diagnostics, d.checkPorts(cfg)...)
+	diagnostics = append(diagnostics, d.checkScanners(cfg)...)
+	diagnostics = append(diagnostics, d.checkDockerSocket()...)
+	return
+}
+
+func (d *Diagnoser) checkFields(cfg Config) (diagnostics Diagnostics) {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(yamlName)
+	err := validate.Struct(&cfg)
+	if err == nil {
+		return nil
+	}
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return Diagnostics{{Severity: SeverityError, Message: err.Error()}}
+	}
+	for _, validationErr := range validationErrs {
+		diagnostics = append(diagnostics, &Diagnostic{
+			Severity: SeverityError,
+			Field:    strings.TrimPrefix(validationErr.Namespace(), "Config."),
+			Message:  fmt.Sprintf("failed the '%s' validation with value '%v'", validationErr.Tag(), validationErr.Value()),
+			Hint:     validationHint(validationErr.Tag()),
+		})
+	}
+	return
+}
+
+func validationHint(tag string) string {
+	switch tag {
+	case "required":
+		return "set a value for this field"
+	case "url":
+		return "use a full URL like https://example.com"
+	case "min", "gte", "gt":
+		return "increase the value"
+	case "max", "lte", "lt":
+		return "decrease the value"
+	default:
+		return ""
+	}
+}
+
+func (d *Diagnoser) checkMethodCosts(cfg Config) (diagnostics Diagnostics) {
+	if err := cfg.JsonRpcProxy.validateMethodCosts(cfg.ChainID); err != nil {
+		diagnostics = append(diagnostics, &Diagnostic{
+			Severity: SeverityError,
+			Field:    "jsonRpcProxy.methodCosts",
+			Message:  err.Error(),
+			Hint:     "decrease the method cost or increase the burst",
+		})
+	}
+	return
+}
+
+type jsonRpcAPI struct {
+	field   string
+	url     string
+	headers map[string]string
+	chainID int
+}
+
+func jsonRpcAPIs(cfg Config) []*jsonRpcAPI {
+	apis := []*jsonRpcAPI{
+		{field: "scan.jsonRpc.url", url: cfg.Scan.JsonRpc.Url, headers: cfg.Scan.JsonRpc.Headers, chainID: cfg.ChainID},
+		{field: "jsonRpcProxy.jsonRpc.url", url: cfg.JsonRpcProxy.JsonRpc.Url, headers: cfg.JsonRpcProxy.JsonRpc.Headers, chainID: cfg.ChainID},
+	}
+	if cfg.Trace.Enabled {
+		apis = append(apis, &jsonRpcAPI{field: "trace.jsonRpc.url", url: cfg.Trace.JsonRpc.Url, headers: cfg.Trace.JsonRpc.Headers, chainID: cfg.ChainID})
+	}
+	for i, upstream := range cfg.JsonRpcProxy.Upstreams {
+		apis = append(apis, &jsonRpcAPI{
+			field: fmt.Sprintf("jsonRpcProxy.upstreams[%d].url", i), url: upstream.Url, headers: upstream.Headers, chainID: cfg.ChainID,
+		})
+	}
+	for i, instance := range cfg.JsonRpcProxy.Instances {
+		apis = append(apis, &jsonRpcAPI{
+			field: fmt.Sprintf("jsonRpcProxy.instances[%d].jsonRpc.url", i), url: instance.JsonRpc.Url, headers: instance.JsonRpc.Headers, chainID: instance.ChainID,
+		})
+	}
+	if !cfg.Registry.Disable && !cfg.Registry.SnapshotMode && !cfg.LocalModeConfig.Enable {
+		apis = append(apis, &jsonRpcAPI{
+			field: "registry.jsonRpc.url", url: cfg.Registry.JsonRpc.Url, headers: cfg.Registry.JsonRpc.Headers, chainID: int(cfg.Registry.ChainID),
+		})
+	}
+	return apis
+}
+
+func (d *Diagnoser) checkJsonRpcAPIs(cfg Config) (diagnostics Diagnostics) {
+	if len(cfg.Scan.JsonRpc.Url) == 0 {
+		diagnostics = append(diagnostics, &Diagnostic{
+			Severity: SeverityError,
+			Field:    "scan.jsonRpc.url",
+			Message:  "the JSON-RPC API to scan is not set",
+			Hint:     fmt.Sprintf("set the URL of a JSON-RPC API for chain %d", cfg.ChainID),
+		})
+	}
+	for _, api := range jsonRpcAPIs(cfg) {
+		if len(api.url) == 0 {
+			continue
+		}
+		chainID, err := d.getChainID(api)
+		if err != nil {
+			diagnostics = append(diagnostics, &Diagnostic{
+				Severity: SeverityWarning,
+				Field:    api.field,
+				Message:  fmt.Sprintf("the JSON-RPC API is not reachable: %v", err),
+				Hint:     "check the URL, the headers and the network access from this host",
+			})
+			continue
+		}
+		if chainID != api.chainID {
+			diagnostics = append(diagnostics, &Diagnostic{
+				Severity: SeverityError,
+				Field:    api.field,
+				Message:  fmt.Sprintf("the JSON-RPC API serves chain %d but chain %d is expected", chainID, api.chainID),
+				Hint:     "use an API for the expected chain or fix the chain ID",
+			})
+		}
+	}
+	return
+}
+
+func (d *Diagnoser) getChainID(api *jsonRpcAPI) (int, error) {
+	var result string
+	if err := d.callJsonRpc(api, "eth_chainId", &result); err != nil {
+		return 0, err
+	}
+	chainID, err := strconv.ParseUint(strings.TrimPrefix(result, "0x"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chain ID '%s': %v", result, err)
+	}
+	return int(chainID), nil
+}
+
+// callJsonRpc calls the method of the JSON-RPC API and decodes the result.
+func (d *Diagnoser) callJsonRpc(api *jsonRpcAPI, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	req, err := http.NewRequest(http.MethodPost, api.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range api.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var respBody struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+		return fmt.Errorf("failed to decode the response: %v", err)
+	}
+	if respBody.Error != nil {
+		return fmt.Errorf("%s failed: %s", method, respBody.Error.Message)
+	}
+	if err := json.Unmarshal(respBody.Result, result); err != nil {
+		return fmt.Errorf("failed to decode the %s result: %v", method, err)
+	}
+	return nil
+}
+
+func (d *Diagnoser) checkKeyFiles(cfg Config) (diagnostics Diagnostics) {
+	entries, err := ioutil.ReadDir(cfg.KeyDirPath)
+	if err != nil && !os.IsNotExist(err) {
+		return Diagnostics{{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("failed to read the key dir %s: %v", cfg.KeyDirPath, err),
+			Hint:     "make sure that the key dir is readable by this user",
+		}}
+	}
+	var keyCount int
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		keyCount++
+		if entry.Mode().Perm()&0077 != 0 {
+			keyPath := path.Join(cfg.KeyDirPath, entry.Name())
+			diagnostics = append(diagnostics, &Diagnostic{
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("the key file %s is accessible by other users (%s)", keyPath, entry.Mode().Perm()),
+				Hint:     fmt.Sprintf("chmod 600 %s", keyPath),
+			})
+		}
+	}
+	if keyCount == 0 {
+		diagnostics = append(diagnostics, &Diagnostic{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("no scanner key found in %s", cfg.KeyDirPath),
+			Hint:     "run 'forta init' or 'forta account import'",
+		})
+	}
+	return
+}
+
+// checkScanners makes sure that the additional scanners do not share the instance or the
+// Forta dir with this node or with each other.
+func (d *Diagnoser) checkScanners(cfg Config) (diagnostics Diagnostics) {
+	instances := map[string]bool{cfg.ContainerNaming.Instance: true}
+	fortaDirs := map[string]bool{path.Clean(cfg.FortaDir): true}
+	for i, scanner := range cfg.Scanners {
+		if instances[scanner.Instance] {
+			diagnostics = append(diagnostics, &Diagnostic{
+				Severity: SeverityError,
+				Field:    fmt.Sprintf("scanners[%d].instance", i),
+				Message:  fmt.Sprintf("instance '%s' is used by another scanner", scanner.Instance),
+				Hint:     "use a different instance for each scanner",
+			})
+		}
+		instances[scanner.Instance] = true
+
+		fortaDir := path.Clean(scanner.FortaDir)
+		if fortaDirs[fortaDir] {
+			diagnostics = append(diagnostics, &Diagnostic{
+				Severity: SeverityError,
+				Field:    fmt.Sprintf("scanners[%d].fortaDir", i),
+				Message:  fmt.Sprintf("%s is the Forta dir of another scanner", scanner.FortaDir),
+				Hint:     "use a different Forta dir for each scanner",
+			})
+			continue
+		}
+		fortaDirs[fortaDir] = true
+		if err := checkIfConfigFileExists(path.Join(fortaDir, DefaultConfigFileName)); err != nil {
+			diagnostics = append(diagnostics, &Diagnostic{
+				Severity: SeverityError,
+				Field:    fmt.Sprintf("scanners[%d].fortaDir", i),
+				Message:  fmt.Sprintf("no config file in %s: %v", scanner.FortaDir, err),
+				Hint:     fmt.Sprintf("run 'forta init --dir %s'", scanner.FortaDir),
+			})
+		}
+	}
+	return
+}
+
+type listenPortField struct {
+	field string
+	port  string
+}
+
+func (d *Diagnoser) checkPorts(cfg Config) (diagnostics Diagnostics) {
+	// the proxies share the container with the health server
+	ports := []*listenPortField{
+		{field: "health", port: DefaultHealthPort},
+		{field: "jsonRpcProxy.listenAddr", port: cfg.JsonRpcProxy.Port()},
+	}
+	if cfg.JsonRpcProxy.ServerTLS != nil {
+		ports = append(ports, &listenPortField{field: "jsonRpcProxy.serverTls", port: DefaultJSONRPCProxyTLSPort})
+	}
+	for i, instance := range cfg.JsonRpcProxy.Instances {
+		ports = append(ports, &listenPortField{field: fmt.Sprintf("jsonRpcProxy.instances[%d].listenAddr", i), port: instance.Port()})
+	}
+	for i, protocolProxy := range cfg.JsonRpcProxy.ProtocolProxies {
+		ports = append(ports, &listenPortField{field: fmt.Sprintf("jsonRpcProxy.protocolProxies[%d].listenAddr", i), port: protocolProxy.Port()})
+	}
+
+	fieldsByPort := make(map[string][]string)
+	for _, port := range ports {
+		if len(port.port) == 0 {
+			diagnostics = append(diagnostics, &Diagnostic{
+				Severity: SeverityError,
+				Field:    port.field,
+				Message:  "the listen address has no port",
+				Hint:     "use an address like :8547 or 0.0.0.0:8547",
+			})
+			continue
+		}
+		fieldsByPort[port.port] = append(fieldsByPort[port.port], port.field)
+	}
+	var conflictingPorts []string
+	for port, fields := range fieldsByPort {
+		if len(fields) > 1 {
+			conflictingPorts = append(conflictingPorts, port)
+		}
+	}
+	sort.Strings(conflictingPorts)
+	for _, port := range conflictingPorts {
+		fields := fieldsByPort[port]
+		diagnostics = append(diagnostics, &Diagnostic{
+			Severity: SeverityError,
+			Field:    fields[1],
+			Message:  fmt.Sprintf("port %s is used by %s", port, strings.Join(fields, ", ")),
+			Hint:     "use a different port for each listen address",
+		})
+	}
+	return
+}
+
+func (d *Diagnoser) checkDockerSocket() Diagnostics {
+	conn, err := dialDocker(context.Background(), d.DockerSocketPath, time.Second*5)
+	if err != nil {
+		return Diagnostics{{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("cannot access the Docker socket %s: %v", d.DockerSocketPath, err),
+			Hint:     "make sure that Docker is running and this user is in the docker group",
+		}}
+	}
+	conn.Close()
+	return nil
+}
+
+// dialDocker dials the Docker daemon at the socket path or the address. The named pipes are
+// available only on Windows.
+func dialDocker(ctx context.Context, socketPath string, timeout time.Duration) (net.Conn, error) {
+	switch {
+	case strings.HasPrefix(socketPath, "npipe://"):
+		return sockets.DialPipe(strings.TrimPrefix(socketPath, "npipe://"), timeout)
+	case strings.HasPrefix(socketPath, "tcp://"):
+		return (&net.Dialer{Timeout: timeout}).DialContext(ctx, "tcp", strings.TrimPrefix(socketPath, "tcp://"))
+	default:
+		return (&net.Dialer{Timeout: timeout}).DialContext(ctx, "unix", strings.TrimPrefix(socketPath, "unix://"))
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnose(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x89"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	socketPath := path.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	r.NoError(err)
+	defer listener.Close()
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.ChainID = 137
+	cfg.KeyDirPath = path.Join(dir, "keys")
+	cfg.Scan.JsonRpc.Url = server.URL
+	cfg.Registry.Disable = true
+
+	d := NewDiagnoser()
+	d.DockerSocketPath = socketPath
+
+	findField := func(diagnostics Diagnostics, field string) *Diagnostic {
+		for _, diagnostic := range diagnostics {
+			if diagnostic.Field == field {
+				return diagnostic
+			}
+		}
+		return nil
+	}
+
+	// no keys yet
+	diagnostics := d.checkKeyFiles(cfg)
+	r.True(diagnostics.HasErrors())
+
+	keyPath := path.Join(cfg.KeyDirPath, "UTC--key")
+	r.NoError(os.MkdirAll(cfg.KeyDirPath, 0700))
+	r.NoError(ioutil.WriteFile(keyPath, []byte("{}"), 0600))
+	r.NoError(os.Chmod(keyPath, 0644))
+	diagnostics = d.checkKeyFiles(cfg)
+	r.Len(diagnostics, 1)
+	r.Equal(SeverityWarning, diagnostics[0].Severity)
+	r.Contains(diagnostics[0].Hint, "chmod 600")
+
+	r.Empty(d.checkJsonRpcAPIs(cfg))
+	r.Empty(d.checkPorts(cfg))
+	r.Empty(d.checkScanners(cfg))
+	r.Empty(d.checkDockerSocket())
+	// the Docker host address works too
+	d.DockerSocketPath = "unix://" + socketPath
+	r.Empty(d.checkDockerSocket())
+	d.DockerSocketPath = socketPath
+
+	// the additional scanners need their own instances and Forta dirs with the config files
+	cfg.FortaDir = dir
+	scannerDir := path.Join(dir, "scanner2")
+	r.NoError(os.MkdirAll(scannerDir, 0700))
+	r.NoError(ioutil.WriteFile(path.Join(scannerDir, DefaultConfigFileName), []byte("chainId: 1"), 0600))
+	cfg.Scanners = []ScannerInstanceConfig{
+		{Instance: "scanner2", FortaDir: scannerDir},
+	}
+	r.Empty(d.checkScanners(cfg))
+	cfg.Scanners = append(cfg.Scanners,
+		ScannerInstanceConfig{Instance: "scanner2", FortaDir: path.Join(dir, "scanner3")},
+		ScannerInstanceConfig{Instance: "scanner4", FortaDir: dir + "/"},
+	)
+	diagnostics = d.checkScanners(cfg)
+	r.Len(diagnostics, 3)
+	r.Equal("scanners[1].instance", diagnostics[0].Field)
+	r.Equal("scanners[1].fortaDir", diagnostics[1].Field)
+	r.Contains(diagnostics[1].Message, "no config file")
+	r.Equal("scanners[2].fortaDir", diagnostics[2].Field)
+	r.Contains(diagnostics[2].Message, "Forta dir of another scanner")
+	cfg.Scanners = nil
+
+	// chain ID mismatch, unreachable API, port conflict, invalid field and no Docker socket
+	cfg.JsonRpcProxy.Instances = []JsonRpcProxyInstanceConfig{
+		{ChainID: 1, ListenAddr: ":8545", JsonRpc: JsonRpcConfig{Url: server.URL}},
+	}
+	cfg.Trace = TraceConfig{Enabled: true, JsonRpc: JsonRpcConfig{Url: "http://127.0.0.1:1"}}
+	cfg.JsonRpcProxy.MaxBatchSize = 0
+	d.DockerSocketPath = path.Join(dir, "missing.sock")
+
+	diagnostics = d.Diagnose(cfg)
+	r.True(diagnostics.HasErrors())
+	mismatch := findField(diagnostics, "jsonRpcProxy.instances[0].jsonRpc.url")
+	r.NotNil(mismatch)
+	r.Equal(SeverityError, mismatch.Severity)
+	r.Contains(mismatch.Message, "serves chain 137 but chain 1 is expected")
+	unreachable := findField(diagnostics, "trace.jsonRpc.url")
+	r.NotNil(unreachable)
+	r.Equal(SeverityWarning, unreachable.Severity)
+	conflict := findField(diagnostics, "jsonRpcProxy.instances[0].listenAddr")
+	r.NotNil(conflict)
+	r.Contains(conflict.Message, "port 8545")
+	invalid := findField(diagnostics, "jsonRpcProxy.maxBatchSize")
+	r.NotNil(invalid)
+	r.Contains(invalid.Message, "'min'")
+	r.Len(d.checkDockerSocket(), 1)
+}
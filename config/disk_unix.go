@@ -0,0 +1,14 @@
+//go:build !windows
+
+package config
+
+import "syscall"
+
+// diskFree returns the available space of the file system which contains the path.
+func diskFree(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
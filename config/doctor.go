@@ -0,0 +1,393 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Doctor check results
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+// Doctor defaults
+const (
+	DefaultMinDockerVersion  = "20.10.0"
+	DefaultMinDiskFree       = 2 << 30  // 2 GiB
+	DefaultWarnDiskFree      = 10 << 30 // 10 GiB
+	DefaultMaxClockSkew      = time.Second * 5
+	DefaultCriticalClockSkew = time.Second * 30
+)
+
+// DoctorCheck is the result of a preflight check.
+type DoctorCheck struct {
+	Name    string `json:"name"`
+	Result  string `json:"result"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// DoctorReport is the machine-readable result of all preflight checks.
+type DoctorReport struct {
+	Timestamp string         `json:"timestamp"`
+	OS        string         `json:"os"`
+	Arch      string         `json:"arch"`
+	ChainID   int            `json:"chainId"`
+	Result    string         `json:"result"`
+	Checks    []*DoctorCheck `json:"checks"`
+}
+
+// Failed tells if any of the checks failed.
+func (report *DoctorReport) Failed() bool {
+	return report.Result == CheckFail
+}
+
+// Doctor checks if the environment is ready to run the node.
+type Doctor struct {
+	*Diagnoser
+	MinDockerVersion  string
+	MinDiskFree       uint64
+	WarnDiskFree      uint64
+	MaxClockSkew      time.Duration
+	CriticalClockSkew time.Duration
+}
+
+// NewDoctor creates a new doctor.
+func NewDoctor() *Doctor {
+	return &Doctor{
+		Diagnoser:         NewDiagnoser(),
+		MinDockerVersion:  DefaultMinDockerVersion,
+		MinDiskFree:       DefaultMinDiskFree,
+		WarnDiskFree:      DefaultWarnDiskFree,
+		MaxClockSkew:      DefaultMaxClockSkew,
+		CriticalClockSkew: DefaultCriticalClockSkew,
+	}
+}
+
+// Examine checks the Docker daemon, the ports, the JSON-RPC APIs, the disk space, the clock,
+// the registry and the scanner key.
+func (doc *Doctor) Examine(cfg Config) *DoctorReport {
+	report := &DoctorReport{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		ChainID:   cfg.ChainID,
+		Result:    CheckPass,
+	}
+	report.Checks = append(report.Checks, doc.checkDocker())
+	report.Checks = append(report.Checks, doc.checkOpenPorts(cfg))
+	report.Checks = append(report.Checks, doc.checkChainID(cfg))
+	report.Checks = append(report.Checks, doc.checkTraceSupport(cfg))
+	report.Checks = append(report.Checks, doc.checkDiskSpace(cfg))
+	report.Checks = append(report.Checks, doc.checkClockSkew(cfg))
+	report.Checks = append(report.Checks, doc.checkRegistry(cfg)...)
+	report.Checks = append(report.Checks, doc.checkKey(cfg))
+	for _, check := range report.Checks {
+		switch {
+		case check.Result == CheckFail:
+			report.Result = CheckFail
+		case check.Result == CheckWarn && report.Result == CheckPass:
+			report.Result = CheckWarn
+		}
+	}
+	return report
+}
+
+func (doc *Doctor) checkDocker() *DoctorCheck {
+	check := &DoctorCheck{Name: "docker"}
+	conn, err := dialDocker(context.Background(), doc.DockerSocketPath, time.Second*5)
+	if err != nil {
+		check.Result = CheckFail
+		check.Message = fmt.Sprintf("cannot access the Docker socket %s: %v", doc.DockerSocketPath, err)
+		if errors.Is(err, os.ErrPermission) {
+			check.Hint = "add this user to the docker group and log in again"
+		} else {
+			check.Hint = "make sure that Docker is installed and running"
+		}
+		return check
+	}
+	conn.Close()
+
+	client := &http.Client{
+		Timeout: doc.HTTPClient.Timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialDocker(ctx, doc.DockerSocketPath, doc.HTTPClient.Timeout)
+			},
+		},
+	}
+	resp, err := client.Get("http://docker/version")
+	if err != nil {
+		check.Result = CheckFail
+		check.Message = fmt.Sprintf("failed to get the Docker version: %v", err)
+		check.Hint = "make sure that the Docker daemon is healthy"
+		return check
+	}
+	defer resp.Body.Close()
+	var version struct {
+		Version    string `json:"Version"`
+		APIVersion string `json:"ApiVersion"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		check.Result = CheckFail
+		check.Message = fmt.Sprintf("failed to decode the Docker version: %v", err)
+		return check
+	}
+	if compareVersions(version.Version, doc.MinDockerVersion) < 0 {
+		check.Result = CheckWarn
+		check.Message = fmt.Sprintf("Docker %s is older than %s", version.Version, doc.MinDockerVersion)
+		check.Hint = "upgrade Docker"
+		return check
+	}
+	check.Result = CheckPass
+	check.Message = fmt.Sprintf("Docker %s (API %s)", version.Version, version.APIVersion)
+	return check
+}
+
+// compareVersions compares the numeric parts of two dotted versions.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bParts := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aNum, bNum int
+		if i < len(aParts) {
+			aNum, _ = strconv.Atoi(strings.SplitN(aParts[i], "-", 2)[0])
+		}
+		if i < len(bParts) {
+			bNum, _ = strconv.Atoi(strings.SplitN(bParts[i], "-", 2)[0])
+		}
+		if aNum != bNum {
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkOpenPorts checks that the ports which the node publishes on the host are free.
+func (doc *Doctor) checkOpenPorts(cfg Config) *DoctorCheck {
+	check := &DoctorCheck{Name: "ports"}
+	ports := []string{DefaultHealthPort}
+	if cfg.JsonRpcProxy.ServerTLS != nil {
+		ports = append(ports, DefaultJSONRPCProxyTLSPort)
+	}
+	var busyPorts []string
+	for _, port := range ports {
+		listener, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			busyPorts = append(busyPorts, port)
+			continue
+		}
+		listener.Close()
+	}
+	if len(busyPorts) > 0 {
+		check.Result = CheckWarn
+		check.Message = fmt.Sprintf("ports already in use: %s", strings.Join(busyPorts, ", "))
+		check.Hint = "ignore this if the node is already running, otherwise stop the process using the ports"
+		return check
+	}
+	check.Result = CheckPass
+	check.Message = fmt.Sprintf("ports are free: %s", strings.Join(ports, ", "))
+	return check
+}
+
+func (doc *Doctor) checkChainID(cfg Config) *DoctorCheck {
+	check := &DoctorCheck{Name: "rpc.chainId"}
+	if len(cfg.Scan.JsonRpc.Url) == 0 {
+		check.Result = CheckFail
+		check.Message = "the JSON-RPC API to scan is not set"
+		check.Hint = "set scan.jsonRpc.url in the config"
+		return check
+	}
+	chainID, err := doc.getChainID(&jsonRpcAPI{url: cfg.Scan.JsonRpc.Url, headers: cfg.Scan.JsonRpc.Headers})
+	if err != nil {
+		check.Result = CheckFail
+		check.Message = fmt.Sprintf("the JSON-RPC API is not reachable: %v", err)
+		check.Hint = "check the URL, the headers and the network access from this host"
+		return check
+	}
+	if chainID != cfg.ChainID {
+		check.Result = CheckFail
+		check.Message = fmt.Sprintf("the JSON-RPC API serves chain %d but chain %d is expected", chainID, cfg.ChainID)
+		check.Hint = "use an API for the expected chain or fix the chain ID"
+		return check
+	}
+	check.Result = CheckPass
+	check.Message = fmt.Sprintf("the JSON-RPC API serves chain %d", chainID)
+	return check
+}
+
+func (doc *Doctor) checkTraceSupport(cfg Config) *DoctorCheck {
+	check := &DoctorCheck{Name: "rpc.trace"}
+	if !cfg.Trace.Enabled {
+		check.Result = CheckPass
+		check.Message = "tracing is disabled"
+		return check
+	}
+	api := &jsonRpcAPI{url: cfg.Trace.JsonRpc.Url, headers: cfg.Trace.JsonRpc.Headers}
+	var traces []json.RawMessage
+	if err := doc.callJsonRpc(api, "trace_block", &traces, "latest"); err != nil {
+		check.Result = CheckFail
+		check.Message = fmt.Sprintf("the trace API does not support trace_block: %v", err)
+		check.Hint = "use a trace API or disable tracing for this chain"
+		return check
+	}
+	check.Result = CheckPass
+	check.Message = "the trace API supports trace_block"
+	return check
+}
+
+func (doc *Doctor) checkDiskSpace(cfg Config) *DoctorCheck {
+	check := &DoctorCheck{Name: "disk"}
+	free, err := diskFree(cfg.FortaDir)
+	if err != nil {
+		check.Result = CheckFail
+		check.Message = fmt.Sprintf("failed to check the free disk space of %s: %v", cfg.FortaDir, err)
+		return check
+	}
+	message := fmt.Sprintf("%.1f GiB free in %s", float64(free)/(1<<30), cfg.FortaDir)
+	switch {
+	case free < doc.MinDiskFree:
+		check.Result = CheckFail
+		check.Message = message
+		check.Hint = "free up disk space for the bot images and the node data"
+	case free < doc.WarnDiskFree:
+		check.Result = CheckWarn
+		check.Message = message
+		check.Hint = "the disk may fill up as the bot images are pulled"
+	default:
+		check.Result = CheckPass
+		check.Message = message
+	}
+	return check
+}
+
+// checkClockSkew compares the local time with the date header of the release distribution server.
+func (doc *Doctor) checkClockSkew(cfg Config) *DoctorCheck {
+	check := &DoctorCheck{Name: "clock"}
+	start := time.Now()
+	resp, err := doc.HTTPClient.Head(cfg.Registry.ReleaseDistributionUrl)
+	if err != nil {
+		check.Result = CheckWarn
+		check.Message = fmt.Sprintf("failed to get the remote time: %v", err)
+		return check
+	}
+	resp.Body.Close()
+	remoteTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		check.Result = CheckWarn
+		check.Message = "the remote server did not send a valid date"
+		return check
+	}
+	// assume that the remote time was taken halfway through the request
+	localTime := start.Add(time.Since(start) / 2)
+	skew := localTime.Sub(remoteTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	// the date header has a resolution of a second
+	skew = skew.Truncate(time.Second)
+	message := fmt.Sprintf("the clock is off by %s", skew)
+	switch {
+	case skew > doc.CriticalClockSkew:
+		check.Result = CheckFail
+		check.Message = message
+		check.Hint = "enable time synchronization (NTP) on this host"
+	case skew > doc.MaxClockSkew:
+		check.Result = CheckWarn
+		check.Message = message
+		check.Hint = "enable time synchronization (NTP) on this host"
+	default:
+		check.Result = CheckPass
+		check.Message = message
+	}
+	return check
+}
+
+func (doc *Doctor) checkRegistry(cfg Config) []*DoctorCheck {
+	if cfg.Registry.Disable || cfg.Registry.SnapshotMode || cfg.LocalModeConfig.Enable {
+		return []*DoctorCheck{{Name: "registry", Result: CheckPass, Message: "the registry is not used"}}
+	}
+
+	checks := []*DoctorCheck{{Name: "registry.jsonRpc"}}
+	chainID, err := doc.getChainID(&jsonRpcAPI{url: cfg.Registry.JsonRpc.Url, headers: cfg.Registry.JsonRpc.Headers})
+	switch {
+	case err != nil:
+		checks[0].Result = CheckFail
+		checks[0].Message = fmt.Sprintf("the registry JSON-RPC API is not reachable: %v", err)
+		checks[0].Hint = "check registry.jsonRpc.url and the network access from this host"
+	case chainID != int(cfg.Registry.ChainID):
+		checks[0].Result = CheckFail
+		checks[0].Message = fmt.Sprintf("the registry JSON-RPC API serves chain %d but chain %d is expected", chainID, cfg.Registry.ChainID)
+		checks[0].Hint = "use an API for the registry chain"
+	default:
+		checks[0].Result = CheckPass
+		checks[0].Message = fmt.Sprintf("the registry JSON-RPC API serves chain %d", chainID)
+	}
+
+	checks = append(checks,
+		doc.checkReachable("registry.containers", fmt.Sprintf("https://%s/v2/", cfg.Registry.ContainerRegistry)),
+		doc.checkReachable("registry.ipfs", cfg.Registry.IPFS.GatewayURL),
+	)
+	return checks
+}
+
+// checkReachable checks that the server responds without a server error.
+func (doc *Doctor) checkReachable(name, url string) *DoctorCheck {
+	check := &DoctorCheck{Name: name}
+	resp, err := doc.HTTPClient.Get(url)
+	if err != nil {
+		check.Result = CheckFail
+		check.Message = fmt.Sprintf("%s is not reachable: %v", url, err)
+		check.Hint = "check the network access from this host"
+		return check
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		check.Result = CheckWarn
+		check.Message = fmt.Sprintf("%s responded with status %d", url, resp.StatusCode)
+		return check
+	}
+	check.Result = CheckPass
+	check.Message = fmt.Sprintf("%s is reachable", url)
+	return check
+}
+
+func (doc *Doctor) checkKey(cfg Config) *DoctorCheck {
+	check := &DoctorCheck{Name: "key"}
+	scannerSigner, err := LoadSigner(cfg)
+	if err != nil {
+		check.Result = CheckFail
+		check.Message = fmt.Sprintf("failed to load the scanner key: %v", err)
+		check.Hint = "check the passphrase or run 'forta init' or 'forta account import'"
+		if cfg.Signer.IsRemote() {
+			check.Hint = "check the remote signer url and the auth token"
+		}
+		return check
+	}
+	check.Result = CheckPass
+	check.Message = fmt.Sprintf("scanner address %s", scannerSigner.Address().Hex())
+	if cfg.Signer.IsRemote() {
+		check.Message = fmt.Sprintf("scanner address %s (remote signer)", scannerSigner.Address().Hex())
+		return check
+	}
+	for _, diagnostic := range doc.checkKeyFiles(cfg) {
+		check.Result = CheckWarn
+		check.Message = diagnostic.Message
+		check.Hint = diagnostic.Hint
+	}
+	return check
+}
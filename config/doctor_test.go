@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/creasty/defaults"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(0, compareVersions("20.10.0", "20.10"))
+	r.Equal(1, compareVersions("24.0.2", "20.10.0"))
+	r.Equal(-1, compareVersions("19.03.12", "20.10.0"))
+	r.Equal(-1, compareVersions("20.10.0-rc1", "20.10.1"))
+}
+
+func TestDoctor(t *testing.T) {
+	r := require.New(t)
+
+	var serverOffset int64 // read by the server goroutine
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, httpReq *http.Request) {
+		serverDate := time.Now().Add(time.Duration(atomic.LoadInt64(&serverOffset)))
+		w.Header().Set("Date", serverDate.UTC().Format(http.TimeFormat))
+		if httpReq.Method != http.MethodPost {
+			return
+		}
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.NewDecoder(httpReq.Body).Decode(&req)
+		switch req.Method {
+		case "eth_chainId":
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x89"}`))
+		default:
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	socketPath := path.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	r.NoError(err)
+	go http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, httpReq *http.Request) {
+		w.Write([]byte(`{"Version":"19.03.12","ApiVersion":"1.40"}`))
+	}))
+	defer listener.Close()
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.ChainID = 137
+	cfg.FortaDir = dir
+	cfg.Scan.JsonRpc.Url = server.URL
+	cfg.Registry.ReleaseDistributionUrl = server.URL
+
+	doc := NewDoctor()
+	doc.DockerSocketPath = socketPath
+
+	docker := doc.checkDocker()
+	r.Equal(CheckWarn, docker.Result)
+	r.Contains(docker.Message, "older than")
+
+	r.Equal(CheckPass, doc.checkChainID(cfg).Result)
+	cfg.ChainID = 1
+	r.Equal(CheckFail, doc.checkChainID(cfg).Result)
+
+	r.Equal(CheckPass, doc.checkTraceSupport(cfg).Result)
+	cfg.Trace = TraceConfig{Enabled: true, JsonRpc: JsonRpcConfig{Url: server.URL}}
+	r.Equal(CheckFail, doc.checkTraceSupport(cfg).Result)
+
+	r.Equal(CheckPass, doc.checkClockSkew(cfg).Result)
+	atomic.StoreInt64(&serverOffset, int64(-time.Minute))
+	r.Equal(CheckFail, doc.checkClockSkew(cfg).Result)
+
+	doc.MinDiskFree = 0
+	doc.WarnDiskFree = 0
+	r.Equal(CheckPass, doc.checkDiskSpace(cfg).Result)
+
+	// no key and failed checks fail the report
+	cfg.KeyDirPath = path.Join(dir, "keys")
+	r.Equal(CheckFail, doc.checkKey(cfg).Result)
+	cfg.Registry.Disable = true
+	report := doc.Examine(cfg)
+	r.True(report.Failed())
+}
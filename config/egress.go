@@ -0,0 +1,99 @@
+package config
+
+import (
+	"net"
+	"strings"
+)
+
+// BotEgressConfig restricts the outgoing connections of the bot containers. When it is enabled, the bots
+// are attached to internal networks which can reach only the node services. The external hosts are then
+// reached through the egress proxy if they are declared in the bot manifest and approved by the operator.
+type BotEgressConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// ListenAddr is the address of the egress proxy which runs in the JSON-RPC proxy container.
+	ListenAddr string `yaml:"listenAddr" json:"listenAddr" default:":8575"`
+	// ApprovedHosts can be reached by all bots which declare them. A host can start with '*.' to match the subdomains.
+	ApprovedHosts []string `yaml:"approvedHosts" json:"approvedHosts"`
+	// BotApprovedHosts can be reached by specific bots which declare them.
+	BotApprovedHosts map[string][]string `yaml:"botApprovedHosts" json:"botApprovedHosts"`
+	// Gateway makes the HTTPS requests of the bots to the allowed hosts.
+	Gateway BotGatewayConfig `yaml:"gateway" json:"gateway"`
+}
+
+// BotGatewayConfig configures the bot gateway which runs in the JSON-RPC proxy container. The bots send
+// their requests to the gateway as http://<gateway>/<host>/<path> and the gateway makes the HTTPS request
+// to the host if it is allowed by the egress policy.
+type BotGatewayConfig struct {
+	Enable     bool   `yaml:"enable" json:"enable"`
+	ListenAddr string `yaml:"listenAddr" json:"listenAddr" default:":8585"`
+	// RateLimitConfig limits the request rate of each bot.
+	RateLimitConfig *RateLimitConfig `yaml:"rateLimit" json:"rateLimit"`
+	// HourlyRequests and DailyRequests are the request quotas of each bot. Zero means unlimited.
+	HourlyRequests int `yaml:"hourlyRequests" json:"hourlyRequests" validate:"min=0"`
+	DailyRequests  int `yaml:"dailyRequests" json:"dailyRequests" validate:"min=0"`
+	// CacheTTLSeconds is how long the successful GET responses are served from the cache. Zero disables the cache.
+	CacheTTLSeconds  int `yaml:"cacheTtlSeconds" json:"cacheTtlSeconds" default:"60" validate:"min=0"`
+	MaxCacheEntries  int `yaml:"maxCacheEntries" json:"maxCacheEntries" default:"1000" validate:"min=0"`
+	MaxResponseBytes int `yaml:"maxResponseBytes" json:"maxResponseBytes" default:"10485760" validate:"min=0"`
+}
+
+// Port returns the port of the bot gateway.
+func (cfg BotGatewayConfig) Port() string {
+	return listenPort(cfg.ListenAddr, DefaultBotGatewayPort)
+}
+
+// Port returns the port of the egress proxy.
+func (cfg BotEgressConfig) Port() string {
+	return listenPort(cfg.ListenAddr, DefaultEgressProxyPort)
+}
+
+// AllowedHosts returns the hosts which are declared by the bot and approved by the operator. The operator
+// can approve the subdomains with a wildcard but the wildcards of the bot need to be approved as they are.
+func (cfg BotEgressConfig) AllowedHosts(botConfig AgentConfig) (allowed []string) {
+	var approved []string
+	approved = append(approved, cfg.ApprovedHosts...)
+	approved = append(approved, cfg.botApprovedHosts(botConfig.ID)...)
+	for _, host := range botConfig.EgressHosts {
+		for _, approvedHost := range approved {
+			if strings.EqualFold(host, approvedHost) || (!strings.HasPrefix(host, "*.") && MatchesEgressHost(approvedHost, host)) {
+				allowed = append(allowed, host)
+				break
+			}
+		}
+	}
+	return
+}
+
+func (cfg BotEgressConfig) botApprovedHosts(botID string) []string {
+	for id, hosts := range cfg.BotApprovedHosts {
+		if strings.EqualFold(id, botID) {
+			return hosts
+		}
+	}
+	return nil
+}
+
+// IsAllowed tells if the bot can connect to the given host or host:port.
+func (cfg BotEgressConfig) IsAllowed(botConfig AgentConfig, hostPort string) bool {
+	host := hostPort
+	if h, _, err := net.SplitHostPort(hostPort); err == nil {
+		host = h
+	}
+	for _, pattern := range cfg.AllowedHosts(botConfig) {
+		if MatchesEgressHost(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesEgressHost tells if the host matches the pattern. The patterns which start with '*.'
+// match the subdomains but not the domain itself.
+func MatchesEgressHost(pattern, host string) bool {
+	pattern = strings.ToLower(pattern)
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:]) && len(host) > len(pattern)-1
+	}
+	return host == pattern
+}
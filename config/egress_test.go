@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesEgressHost(t *testing.T) {
+	r := require.New(t)
+
+	r.True(MatchesEgressHost("api.example.com", "api.example.com"))
+	r.True(MatchesEgressHost("api.example.com", "API.example.com."))
+	r.False(MatchesEgressHost("api.example.com", "example.com"))
+	r.True(MatchesEgressHost("*.example.com", "api.example.com"))
+	r.True(MatchesEgressHost("*.example.com", "a.b.example.com"))
+	r.False(MatchesEgressHost("*.example.com", "example.com"))
+	r.False(MatchesEgressHost("*.example.com", "badexample.com"))
+}
+
+func TestBotEgressAllowedHosts(t *testing.T) {
+	r := require.New(t)
+
+	cfg := BotEgressConfig{
+		ApprovedHosts: []string{"*.coingecko.com", "api.etherscan.io"},
+		BotApprovedHosts: map[string][]string{
+			"0xbot1": {"*.example.com"},
+		},
+	}
+	bot1 := AgentConfig{ID: "0xBOT1", EgressHosts: []string{"api.coingecko.com", "*.example.com", "evil.com", "*.etherscan.io"}}
+	bot2 := AgentConfig{ID: "0xbot2", EgressHosts: []string{"api.etherscan.io", "*.example.com"}}
+
+	r.Equal([]string{"api.coingecko.com", "*.example.com"}, cfg.AllowedHosts(bot1))
+	r.Equal([]string{"api.etherscan.io"}, cfg.AllowedHosts(bot2))
+	r.Empty(cfg.AllowedHosts(AgentConfig{ID: "0xbot3"}))
+
+	r.True(cfg.IsAllowed(bot1, "api.coingecko.com:443"))
+	r.True(cfg.IsAllowed(bot1, "sub.example.com:8080"))
+	r.False(cfg.IsAllowed(bot1, "evil.com:443"))
+	r.False(cfg.IsAllowed(bot1, "pro-api.coingecko.com:443"))
+	r.True(cfg.IsAllowed(bot2, "api.etherscan.io"))
+	r.False(cfg.IsAllowed(bot2, "sub.example.com:443"))
+
+	r.Equal(DefaultEgressProxyPort, cfg.Port())
+}
@@ -16,6 +16,16 @@ const (
 	EnvFortaBotID         = "FORTA_BOT_ID"
 	EnvFortaBotOwner      = "FORTA_BOT_OWNER"
 	EnvFortaChainID       = "FORTA_CHAIN_ID"
+	EnvFortaChainIDs      = "FORTA_CHAIN_IDS"
+	EnvFortaDataChainIDs  = "FORTA_DATA_CHAIN_IDS"
+	EnvFortaBotToken      = "FORTA_BOT_TOKEN"
+	EnvBotGatewayURL      = "FORTA_BOT_GATEWAY_URL"
+	EnvBotSecretsDir      = "FORTA_BOT_SECRETS_DIR"
+
+	EnvFortaBotDependencyHostFmt = "FORTA_DEPENDENCY_%s_HOST"
+	EnvJsonRpcChainPortFmt       = "JSON_RPC_PORT_%d"
+	EnvJsonRpcNamedPortFmt       = "JSON_RPC_PORT_%s"
+	EnvProtocolProxyPortFmt      = "FORTA_PROXY_%s_PORT"
 )
 
 // EnvDefaults contain default values for one env.
@@ -1,11 +1,18 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients/signer"
 )
 
 // LoadKeyInContainer loads the key in the service container depending on the config.
@@ -21,5 +28,93 @@ func LoadKeyInContainer(cfg Config) (*keystore.Key, error) {
 			Address:    publicAddr,
 		}, nil
 	}
+	// the services run on the host in the development mode
+	if cfg.Dev.Enable {
+		return security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
+	}
 	return security.LoadKey(DefaultContainerKeyDirPath)
 }
+
+// LoadSignerInContainer returns the remote signer if it is configured or a signer
+// with the key loaded in the service container.
+func LoadSignerInContainer(cfg Config) (signer.Signer, error) {
+	if cfg.Signer.IsRemote() {
+		signerURL := cfg.Signer.RemoteURL
+		if !cfg.Dev.Enable {
+			signerURL = utils.ConvertToDockerHostURL(signerURL)
+		}
+		return newRemoteSigner(cfg, signerURL)
+	}
+	key, err := LoadKeyInContainer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return signer.NewKeySigner(key), nil
+}
+
+// LoadSigner returns the remote signer if it is configured or a signer with the key
+// in the keys dir. It is used on the host.
+func LoadSigner(cfg Config) (signer.Signer, error) {
+	if cfg.Signer.IsRemote() {
+		return newRemoteSigner(cfg, cfg.Signer.RemoteURL)
+	}
+	key, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return signer.NewKeySigner(key), nil
+}
+
+func newRemoteSigner(cfg Config, signerURL string) (signer.Signer, error) {
+	remoteSigner, err := signer.NewRemoteSigner(signerURL, cfg.Signer.AuthToken)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Signer.Address) > 0 && remoteSigner.Address() != common.HexToAddress(cfg.Signer.Address) {
+		return nil, fmt.Errorf(
+			"remote signer address %s does not match the configured address %s",
+			remoteSigner.Address().Hex(), cfg.Signer.Address,
+		)
+	}
+	return remoteSigner, nil
+}
+
+// LoadAddressInContainer reads the node address in the service container without decrypting the key.
+func LoadAddressInContainer(cfg Config) (common.Address, error) {
+	if cfg.Signer.IsRemote() {
+		if len(cfg.Signer.Address) > 0 {
+			return common.HexToAddress(cfg.Signer.Address), nil
+		}
+		remoteSigner, err := LoadSignerInContainer(cfg)
+		if err != nil {
+			return common.Address{}, err
+		}
+		return remoteSigner.Address(), nil
+	}
+	if len(cfg.LocalModeConfig.PrivateKeyHex) > 0 || cfg.Dev.Enable {
+		key, err := LoadKeyInContainer(cfg)
+		if err != nil {
+			return common.Address{}, err
+		}
+		return key.Address, nil
+	}
+
+	files, err := os.ReadDir(DefaultContainerKeyDirPath)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(files) != 1 {
+		return common.Address{}, errors.New("there must be only one key in key directory")
+	}
+	b, err := os.ReadFile(path.Join(DefaultContainerKeyDirPath, files[0].Name()))
+	if err != nil {
+		return common.Address{}, err
+	}
+	var keyFile struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(b, &keyFile); err != nil {
+		return common.Address{}, fmt.Errorf("failed to decode the key file: %v", err)
+	}
+	return common.HexToAddress(keyFile.Address), nil
+}
@@ -0,0 +1,37 @@
+package config
+
+// OrchestratorConfig is for running the node services (NATS, the proxies, the inspector, the scanner
+// and the JWT provider) as pods or containers managed by an external orchestrator like Kubernetes.
+// The supervisor then only manages the bot containers and the bots join the bot network, which
+// the scanner should be able to reach them on.
+type OrchestratorConfig struct {
+	Enable bool `yaml:"enable" json:"enable"`
+	// NatsURL is the address of the NATS server run by the orchestrator.
+	NatsURL string `yaml:"natsUrl" json:"natsUrl" validate:"omitempty,hostname_port"`
+	// BotNetwork is the Docker network which all of the bots join.
+	BotNetwork string `yaml:"botNetwork" json:"botNetwork" default:"forta-bots"`
+	// The hosts of the services which the bots talk to. They default to the container names.
+	JsonRpcProxyHost   string `yaml:"jsonRpcProxyHost" json:"jsonRpcProxyHost" validate:"omitempty,hostname|ip"`
+	JWTProviderHost    string `yaml:"jwtProviderHost" json:"jwtProviderHost" validate:"omitempty,hostname|ip"`
+	PublicAPIProxyHost string `yaml:"publicApiProxyHost" json:"publicApiProxyHost" validate:"omitempty,hostname|ip"`
+	ScannerHost        string `yaml:"scannerHost" json:"scannerHost" validate:"omitempty,hostname|ip"`
+}
+
+// ServiceHost returns the host of the service which the bots talk to.
+func (oc OrchestratorConfig) ServiceHost(containerName string) string {
+	var host string
+	switch containerName {
+	case DockerJSONRPCProxyContainerName:
+		host = oc.JsonRpcProxyHost
+	case DockerJWTProviderContainerName:
+		host = oc.JWTProviderHost
+	case DockerPublicAPIProxyContainerName:
+		host = oc.PublicAPIProxyHost
+	case DockerScannerContainerName:
+		host = oc.ScannerHost
+	}
+	if !oc.Enable || len(host) == 0 {
+		return containerName
+	}
+	return host
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrchestratorServiceHost(t *testing.T) {
+	r := require.New(t)
+
+	oc := OrchestratorConfig{JsonRpcProxyHost: "json-rpc.forta.svc"}
+	r.Equal(DockerJSONRPCProxyContainerName, oc.ServiceHost(DockerJSONRPCProxyContainerName))
+
+	oc.Enable = true
+	r.Equal("json-rpc.forta.svc", oc.ServiceHost(DockerJSONRPCProxyContainerName))
+	r.Equal(DockerJWTProviderContainerName, oc.ServiceHost(DockerJWTProviderContainerName))
+	oc.ScannerHost = "scanner.forta.svc"
+	r.Equal("scanner.forta.svc", oc.ServiceHost(DockerScannerContainerName))
+
+	cfg := Config{Orchestrator: OrchestratorConfig{Enable: true, NatsURL: "nats.forta.svc:4222"}}
+	r.Equal("nats.forta.svc:4222", cfg.NatsURL())
+}
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigOverridePrefix is the prefix of the env vars which override the config fields. The rest of
+// the name is the YAML path of the field in upper snake case and the slice indices are used as
+// the path segments, e.g. FORTA_CONFIG_SCAN_JSON_RPC_URL and FORTA_CONFIG_JSON_RPC_PROXY_UPSTREAMS_0_URL.
+const EnvConfigOverridePrefix = "FORTA_CONFIG_"
+
+// Secret reference schemes
+const (
+	SecretSchemeFile  = "file://"
+	SecretSchemeEnv   = "env://"
+	SecretSchemeVault = "vault://"
+)
+
+// Vault env vars
+const (
+	EnvVaultAddr  = "VAULT_ADDR"
+	EnvVaultToken = "VAULT_TOKEN"
+)
+
+// ApplyOverrides overrides the config fields from the env vars, resolves the secret references
+// in the string values and collects the env vars which the containers need to do the same.
+func ApplyOverrides(cfg *Config) error {
+	forwardedEnv := make(map[string]string)
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if strings.HasPrefix(parts[0], EnvConfigOverridePrefix) {
+			forwardedEnv[parts[0]] = parts[1]
+		}
+	}
+
+	if _, err := applyEnvOverrides(strings.TrimSuffix(EnvConfigOverridePrefix, "_"), reflect.ValueOf(cfg).Elem()); err != nil {
+		return err
+	}
+
+	r := &secretResolver{fortaDir: cfg.FortaDir, envVars: make(map[string]bool)}
+	if err := r.resolveValue("", reflect.ValueOf(cfg).Elem()); err != nil {
+		return err
+	}
+	for envVar := range r.envVars {
+		forwardedEnv[envVar] = os.Getenv(envVar)
+	}
+	cfg.ForwardedEnv = forwardedEnv
+	return nil
+}
+
+// ContainerEnv adds the forwarded env vars to the env of a container.
+func (cfg *Config) ContainerEnv(env map[string]string) map[string]string {
+	if env == nil {
+		env = make(map[string]string)
+	}
+	for k, v := range cfg.ForwardedEnv {
+		if _, ok := env[k]; !ok {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// ResolveSecret resolves the value if it is a secret reference. The relative file paths are resolved
+// from the Forta dir.
+func ResolveSecret(value, fortaDir string) (string, error) {
+	r := &secretResolver{fortaDir: fortaDir, envVars: make(map[string]bool)}
+	return r.resolve(value)
+}
+
+func applyEnvOverrides(prefix string, v reflect.Value) (applied bool, err error) {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		var (
+			ok  bool
+			err error
+		)
+		switch {
+		case field.Anonymous && field.Type.Kind() == reflect.Struct:
+			// the inline fields
+			ok, err = applyEnvOverrides(prefix, v.Field(i))
+
+		case !field.IsExported() || field.Tag.Get("yaml") == "-":
+			continue
+
+		default:
+			name := yamlName(field)
+			if len(name) == 0 {
+				name = strings.ToLower(field.Name)
+			}
+			ok, err = applyEnvOverride(prefix+"_"+toUpperSnakeCase(name), v.Field(i))
+		}
+		if err != nil {
+			return false, err
+		}
+		applied = applied || ok
+	}
+	return
+}
+
+func applyEnvOverride(envName string, v reflect.Value) (bool, error) {
+	if envValue, ok := os.LookupEnv(envName); ok {
+		if err := setFromEnv(v, envValue); err != nil {
+			return false, fmt.Errorf("invalid value in %s: %v", envName, err)
+		}
+		return true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return applyEnvOverrides(envName, v)
+
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			return false, nil
+		}
+		// set the pointer only if any of the fields are overridden
+		elem := reflect.New(v.Type().Elem())
+		if !v.IsNil() {
+			elem.Elem().Set(v.Elem())
+		}
+		applied, err := applyEnvOverrides(envName, elem.Elem())
+		if applied {
+			v.Set(elem)
+		}
+		return applied, err
+
+	case reflect.Slice:
+		var applied bool
+		for i := 0; i < v.Len(); i++ {
+			ok, err := applyEnvOverride(envName+"_"+strconv.Itoa(i), v.Index(i))
+			if err != nil {
+				return false, err
+			}
+			applied = applied || ok
+		}
+		return applied, nil
+	}
+	return false, nil
+}
+
+// setFromEnv sets the strings as they are and decodes the other values as YAML.
+func setFromEnv(v reflect.Value, envValue string) error {
+	if v.Kind() == reflect.String {
+		v.SetString(envValue)
+		return nil
+	}
+	newVal := reflect.New(v.Type())
+	if err := yaml.Unmarshal([]byte(envValue), newVal.Interface()); err != nil {
+		return err
+	}
+	v.Set(newVal.Elem())
+	return nil
+}
+
+func toUpperSnakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+type secretResolver struct {
+	fortaDir string
+	// envVars are the env vars used by the secret references
+	envVars map[string]bool
+}
+
+func (r *secretResolver) resolveValue(fieldPath string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.String:
+		resolved, err := r.resolve(v.String())
+		if err != nil {
+			return fmt.Errorf("failed to resolve the secret in %s: %v", fieldPath, err)
+		}
+		v.SetString(resolved)
+
+	case reflect.Struct:
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() || field.Tag.Get("yaml") == "-" {
+				continue
+			}
+			name := yamlName(field)
+			switch {
+			case field.Anonymous:
+				// the inline fields
+				name = fieldPath
+			case len(name) == 0:
+				name = strings.ToLower(field.Name)
+			}
+			if len(fieldPath) > 0 && !field.Anonymous {
+				name = fieldPath + "." + name
+			}
+			if err := r.resolveValue(name, v.Field(i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return r.resolveValue(fieldPath, v.Elem())
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := r.resolveValue(fmt.Sprintf("%s[%d]", fieldPath, i), v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// copy to make it settable
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			if err := r.resolveValue(fmt.Sprintf("%s.%v", fieldPath, key), elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	}
+	return nil
+}
+
+func (r *secretResolver) resolve(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, SecretSchemeFile):
+		filePath := strings.TrimPrefix(value, SecretSchemeFile)
+		if !path.IsAbs(filePath) {
+			filePath = path.Join(r.fortaDir, filePath)
+		}
+		b, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+
+	case strings.HasPrefix(value, SecretSchemeEnv):
+		envVar := strings.TrimPrefix(value, SecretSchemeEnv)
+		envValue, ok := os.LookupEnv(envVar)
+		if !ok {
+			return "", fmt.Errorf("env var %s is not set", envVar)
+		}
+		r.envVars[envVar] = true
+		return envValue, nil
+
+	case strings.HasPrefix(value, SecretSchemeVault):
+		r.envVars[EnvVaultAddr] = true
+		r.envVars[EnvVaultToken] = true
+		return getVaultSecret(strings.TrimPrefix(value, SecretSchemeVault))
+
+	default:
+		return value, nil
+	}
+}
+
+// getVaultSecret reads a key of a secret from the Vault KV secrets engine. The reference looks
+// like "secret/data/forta#rpcApiKey".
+func getVaultSecret(ref string) (string, error) {
+	parts := strings.SplitN(ref, "#", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", fmt.Errorf("vault reference should look like vault://<path>#<key>")
+	}
+	secretPath, key := parts[0], parts[1]
+	vaultAddr := os.Getenv(EnvVaultAddr)
+	if len(vaultAddr) == 0 {
+		return "", fmt.Errorf("%s is not set", EnvVaultAddr)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(vaultAddr, "/"), secretPath), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv(EnvVaultToken))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from vault: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to read from vault: status code %d", resp.StatusCode)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("failed to decode the vault response: %v", err)
+	}
+	// the KV v2 engine nests the secret data
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in vault secret %s", key, secretPath)
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	r := require.New(t)
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/secret/data/forta" || req.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"apiKey":"vault-key"}}}`))
+	}))
+	defer vault.Close()
+
+	fortaDir := t.TempDir()
+	r.NoError(ioutil.WriteFile(path.Join(fortaDir, "rpc-key"), []byte("file-key\n"), 0600))
+
+	t.Setenv("FORTA_CONFIG_CHAIN_ID", "137")
+	t.Setenv("FORTA_CONFIG_SCAN_JSON_RPC_URL", "https://polygon-rpc.com")
+	t.Setenv("FORTA_CONFIG_JSON_RPC_PROXY_UPSTREAMS_0_URL", "https://polygon-rpc-2.com")
+	t.Setenv("FORTA_CONFIG_JSON_RPC_PROXY_RATE_LIMIT_BURST", "10")
+	t.Setenv("FORTA_CONFIG_JSON_RPC_PROXY_JSON_RPC_HEADERS", `{"X-Api-Key": "env://RPC_API_KEY"}`)
+	t.Setenv("RPC_API_KEY", "env-key")
+	t.Setenv(EnvVaultAddr, vault.URL)
+	t.Setenv(EnvVaultToken, "token")
+
+	cfg := &Config{
+		ChainID:  1,
+		FortaDir: fortaDir,
+		Scan: ScannerConfig{
+			JsonRpc: JsonRpcConfig{Headers: map[string]string{"X-Api-Key": "file://rpc-key"}},
+		},
+		Trace: TraceConfig{
+			JsonRpc: JsonRpcConfig{Headers: map[string]string{"X-Api-Key": "vault://secret/data/forta#apiKey"}},
+		},
+		JsonRpcProxy: JsonRpcProxyConfig{
+			Upstreams: []JsonRpcUpstreamConfig{{Weight: 1}},
+		},
+		BotSecrets: BotSecretsConfig{
+			"0xbot": {{Name: "API_KEY", Value: "env://RPC_API_KEY"}},
+		},
+	}
+	r.NoError(ApplyOverrides(cfg))
+
+	r.Equal(137, cfg.ChainID)
+	r.Equal("https://polygon-rpc.com", cfg.Scan.JsonRpc.Url)
+	r.Equal("https://polygon-rpc-2.com", cfg.JsonRpcProxy.Upstreams[0].Url)
+	r.NotNil(cfg.JsonRpcProxy.RateLimitConfig)
+	r.Equal(10, cfg.JsonRpcProxy.RateLimitConfig.Burst)
+	r.Equal("env-key", cfg.JsonRpcProxy.JsonRpc.Headers["X-Api-Key"])
+	r.Equal("env-key", cfg.BotSecrets.Secrets("0xBOT")[0].Value)
+	r.Equal("file-key", cfg.Scan.JsonRpc.Headers["X-Api-Key"])
+	r.Equal("vault-key", cfg.Trace.JsonRpc.Headers["X-Api-Key"])
+
+	// the containers get the overrides and the referenced env vars
+	env := cfg.ContainerEnv(map[string]string{EnvReleaseInfo: "info"})
+	r.Equal("info", env[EnvReleaseInfo])
+	r.Equal("137", env["FORTA_CONFIG_CHAIN_ID"])
+	r.Equal("env-key", env["RPC_API_KEY"])
+	r.Equal("token", env[EnvVaultToken])
+
+	t.Setenv("FORTA_CONFIG_CHAIN_ID", "not-a-number")
+	r.Error(ApplyOverrides(&Config{}))
+
+	_, err := ResolveSecret("env://UNSET_SECRET_VAR", fortaDir)
+	r.Error(err)
+}
+
+func TestToUpperSnakeCase(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("JSON_RPC_PROXY", toUpperSnakeCase("jsonRpcProxy"))
+	r.Equal("CHAIN_ID", toUpperSnakeCase("chainId"))
+	r.Equal("API_URL", toUpperSnakeCase("apiUrl"))
+	r.Equal("SERVER_TLS", toUpperSnakeCase("serverTls"))
+	r.Equal("IPFS", toUpperSnakeCase("ipfs"))
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// reloadableFields are the config fields which are applied at runtime. The nested fields
+// of a listed field are reloadable as well and "*" matches any map key.
+var reloadableFields = []string{
+	"log.level",
+	"log.components.*.level",
+	"jsonRpcProxy.jsonRpc",
+	"jsonRpcProxy.upstreams",
+	"jsonRpcProxy.rateLimit",
+	"jsonRpcProxy.botRateLimits",
+	"registry.checkIntervalSeconds",
+	"registry.checkJitterSeconds",
+	"botPolicy",
+}
+
+// ReloadReport tells which of the changed config fields were applied at runtime and which
+// ones need a restart.
+type ReloadReport struct {
+	Time            time.Time `json:"time"`
+	Reloaded        []string  `json:"reloaded,omitempty"`
+	RequiresRestart []string  `json:"requiresRestart,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// HasChanges tells if any field has changed.
+func (report *ReloadReport) HasChanges() bool {
+	return len(report.Reloaded) > 0 || len(report.RequiresRestart) > 0
+}
+
+// IsReloaded tells if the field or any of its nested fields was reloaded.
+func (report *ReloadReport) IsReloaded(field string) bool {
+	for _, reloaded := range report.Reloaded {
+		if reloaded == field || strings.HasPrefix(reloaded, field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckReload compares the configs and reports the changed fields by their YAML paths.
+func CheckReload(oldCfg, newCfg Config) *ReloadReport {
+	report := &ReloadReport{Time: time.Now().UTC()}
+	for _, field := range diffFields("", reflect.ValueOf(oldCfg), reflect.ValueOf(newCfg)) {
+		if isReloadable(field) {
+			report.Reloaded = append(report.Reloaded, field)
+		} else {
+			report.RequiresRestart = append(report.RequiresRestart, field)
+		}
+	}
+	return report
+}
+
+func isReloadable(field string) bool {
+	fieldParts := strings.Split(field, ".")
+	for _, reloadable := range reloadableFields {
+		reloadableParts := strings.Split(reloadable, ".")
+		if len(fieldParts) < len(reloadableParts) {
+			continue
+		}
+		matches := true
+		for i, part := range reloadableParts {
+			if part != "*" && part != fieldParts[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+var configPkgPath = reflect.TypeOf(Config{}).PkgPath()
+
+// diffFields finds the paths of the different fields by descending into the config structs
+// and the maps of them. The other kinds of values are compared as a whole.
+func diffFields(prefix string, oldVal, newVal reflect.Value) (fields []string) {
+	typ := oldVal.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		isConfigStruct := field.Type.Kind() == reflect.Struct && field.Type.PkgPath() == configPkgPath
+		if field.Anonymous && isConfigStruct {
+			fields = append(fields, diffFields(prefix, oldVal.Field(i), newVal.Field(i))...)
+			continue
+		}
+		if !field.IsExported() || field.Tag.Get("yaml") == "-" {
+			continue
+		}
+		name := yamlName(field)
+		if len(name) == 0 {
+			// the yaml default
+			name = strings.ToLower(field.Name)
+		}
+		if len(prefix) > 0 {
+			name = prefix + "." + name
+		}
+		oldField, newField := oldVal.Field(i), newVal.Field(i)
+		if isConfigStruct {
+			fields = append(fields, diffFields(name, oldField, newField)...)
+			continue
+		}
+		if isConfigStructMap(field.Type) {
+			fields = append(fields, diffMaps(name, oldField, newField)...)
+			continue
+		}
+		if !reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			fields = append(fields, name)
+		}
+	}
+	return
+}
+
+func isConfigStructMap(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String &&
+		typ.Elem().Kind() == reflect.Struct && typ.Elem().PkgPath() == configPkgPath
+}
+
+func diffMaps(prefix string, oldMap, newMap reflect.Value) (fields []string) {
+	keys := make(map[string]bool)
+	for _, key := range append(oldMap.MapKeys(), newMap.MapKeys()...) {
+		keys[key.String()] = true
+	}
+	var sortedKeys []string
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+	for _, key := range sortedKeys {
+		keyVal := reflect.ValueOf(key).Convert(oldMap.Type().Key())
+		oldElem, newElem := oldMap.MapIndex(keyVal), newMap.MapIndex(keyVal)
+		name := prefix + "." + key
+		if !oldElem.IsValid() || !newElem.IsValid() {
+			fields = append(fields, name)
+			continue
+		}
+		fields = append(fields, diffFields(name, oldElem, newElem)...)
+	}
+	return
+}
+
+func yamlName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// Validate checks the config with the validation tags and reports the invalid fields by their YAML paths.
+func Validate(cfg Config) error {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(yamlName)
+	err := validate.Struct(&cfg)
+	if err == nil {
+		return cfg.JsonRpcProxy.validateMethodCosts(cfg.ChainID)
+	}
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+	var fields []string
+	for _, validationErr := range validationErrs {
+		// trim the "Config." prefix
+		fields = append(fields, strings.TrimPrefix(validationErr.Namespace(), "Config."))
+	}
+	return fmt.Errorf("invalid config fields: %s", strings.Join(fields, ", "))
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReload(t *testing.T) {
+	r := require.New(t)
+
+	oldCfg := Config{FortaDir: "/old"}
+	oldCfg.Log.Components = map[string]ComponentLogConfig{"lifecycle": {Level: "info"}}
+	oldCfg.JsonRpcProxy.BotRateLimits = map[string]RateLimitConfig{"0xbot": {Rate: 1, Burst: 1}}
+	oldCfg.JsonRpcProxy.Upstreams = []JsonRpcUpstreamConfig{{JsonRpcConfig: JsonRpcConfig{Url: "http://a"}}}
+
+	newCfg := oldCfg
+	newCfg.FortaDir = "/new"
+	newCfg.Log.Components = map[string]ComponentLogConfig{"lifecycle": {Level: "debug", Format: "text"}}
+	newCfg.JsonRpcProxy.BotRateLimits = map[string]RateLimitConfig{"0xbot": {Rate: 2, Burst: 1}}
+	newCfg.JsonRpcProxy.Upstreams = []JsonRpcUpstreamConfig{{JsonRpcConfig: JsonRpcConfig{Url: "http://b"}}}
+	newCfg.Registry.CheckIntervalSeconds = 30
+	newCfg.ChainID = 137
+
+	report := CheckReload(oldCfg, newCfg)
+	r.ElementsMatch([]string{
+		"log.components.lifecycle.level",
+		"jsonRpcProxy.upstreams",
+		"jsonRpcProxy.botRateLimits.0xbot.rate",
+		"registry.checkIntervalSeconds",
+	}, report.Reloaded)
+	r.ElementsMatch([]string{"chainId", "log.components.lifecycle.format"}, report.RequiresRestart)
+	r.True(report.IsReloaded("jsonRpcProxy.botRateLimits"))
+	r.False(report.IsReloaded("jsonRpcProxy.rateLimit"))
+
+	r.False(CheckReload(oldCfg, oldCfg).HasChanges())
+}
+
+func TestValidate(t *testing.T) {
+	r := require.New(t)
+
+	cfg := Config{}
+	cfg.BotStats.WindowsMinutes = []int{0}
+	err := Validate(cfg)
+	r.Error(err)
+	r.Contains(err.Error(), "botStats.windowsMinutes[0]")
+}
@@ -1,5 +1,20 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+)
+
+// The bot priorities which the CPU weights are derived from
+const (
+	MinBotPriority     = 1
+	DefaultBotPriority = 5
+	MaxBotPriority     = 10
+
+	// botCPUWeightPerPriority gives the default cgroup cpu.weight (100) to the default priority.
+	botCPUWeightPerPriority = 20
+)
+
 // BotResourceLimits contain the agent resource limits data.
 type BotResourceLimits struct {
 	CPUQuota int64 // in microseconds
@@ -47,3 +62,107 @@ func getDefaultCPUQuotaPerAgent() int64 {
 func getDefaultMemoryPerAgent() int64 {
 	return MiBToBytes(10000)
 }
+
+// GetBotPriority returns the priority of the bot. The best effort bots have the lowest priority
+// unless the operator overrides it.
+func GetBotPriority(resourcesCfg ResourcesConfig, botConfig AgentConfig) int {
+	for botID, priority := range resourcesCfg.CPUFairness.BotPriorities {
+		if strings.EqualFold(botID, botConfig.ID) {
+			return priority
+		}
+	}
+	if botConfig.BestEffort {
+		return MinBotPriority
+	}
+	return DefaultBotPriority
+}
+
+// GetBotCPUWeight returns the cgroup cpu.weight of the bot containers from the bot priority.
+// Zero means that the CPU fairness is disabled.
+func GetBotCPUWeight(resourcesCfg ResourcesConfig, botConfig AgentConfig) int64 {
+	if !resourcesCfg.CPUFairness.Enable {
+		return 0
+	}
+	return int64(GetBotPriority(resourcesCfg, botConfig)) * botCPUWeightPerPriority
+}
+
+// CapBotResourceLimits caps the limits of each bot container so that the given number of bot
+// containers can not use more than the host capacity minus the reservation.
+func CapBotResourceLimits(resourcesCfg ResourcesConfig, hostCPUs float64, hostMemory int64, containerCount int) (*BotResourceLimits, error) {
+	limits := GetAgentResourceLimits(resourcesCfg)
+	reservation := resourcesCfg.Reservation
+	if !reservation.Enable || containerCount == 0 {
+		return limits, nil
+	}
+
+	budgetCPUs := hostCPUs - reservation.CPUs
+	// the reservation is compared with the host memory in real bytes
+	budgetMemory := hostMemory - int64(reservation.MemoryMiB)*1024*1024
+	if budgetCPUs <= 0 || budgetMemory <= 0 {
+		return nil, fmt.Errorf(
+			"resource reservation (cpus=%.2f, memoryMib=%d) leaves nothing to the bots on the host (cpus=%.2f, memoryMib=%d)",
+			reservation.CPUs, reservation.MemoryMiB, hostCPUs, hostMemory/1024/1024,
+		)
+	}
+
+	cpuQuota := CPUsToMicroseconds(budgetCPUs / float64(containerCount))
+	if limits.CPUQuota == 0 || cpuQuota < limits.CPUQuota {
+		limits.CPUQuota = cpuQuota
+	}
+	memory := budgetMemory / int64(containerCount)
+	if limits.Memory == 0 || memory < limits.Memory {
+		limits.Memory = memory
+	}
+	return limits, nil
+}
+
+// GetBotContainerConfig returns the ulimits and the tmpfs mounts of the bot containers. The ulimits
+// of the bot override the defaults one by one and the tmpfs mounts of the bot override the default
+// mounts with the same target.
+func GetBotContainerConfig(resourcesCfg ResourcesConfig, botConfig AgentConfig) BotContainerConfig {
+	botContainerCfg := BotContainerConfig{
+		Ulimits: resourcesCfg.BotContainers.Ulimits,
+		Tmpfs:   append([]TmpfsMountConfig{}, resourcesCfg.BotContainers.Tmpfs...),
+	}
+	for botID, override := range resourcesCfg.BotContainers.Bots {
+		if !strings.EqualFold(botID, botConfig.ID) {
+			continue
+		}
+		if override.Ulimits.NoFile != nil {
+			botContainerCfg.Ulimits.NoFile = override.Ulimits.NoFile
+		}
+		if override.Ulimits.NProc != nil {
+			botContainerCfg.Ulimits.NProc = override.Ulimits.NProc
+		}
+		for _, mount := range override.Tmpfs {
+			botContainerCfg.Tmpfs = append(removeTmpfsMount(botContainerCfg.Tmpfs, mount.Target), mount)
+		}
+	}
+	return botContainerCfg
+}
+
+func removeTmpfsMount(mounts []TmpfsMountConfig, target string) (result []TmpfsMountConfig) {
+	for _, mount := range mounts {
+		if mount.Target != target {
+			result = append(result, mount)
+		}
+	}
+	return
+}
+
+// HardLimit returns the hard limit or the soft limit if the hard limit is not set.
+func (uc UlimitConfig) HardLimit() int64 {
+	if uc.Hard == 0 {
+		return uc.Soft
+	}
+	return uc.Hard
+}
+
+// Options returns the Docker tmpfs mount options.
+func (tmc TmpfsMountConfig) Options() string {
+	options := fmt.Sprintf("rw,nosuid,nodev,size=%dm", tmc.SizeMiB)
+	if len(tmc.Mode) > 0 {
+		options += ",mode=" + tmc.Mode
+	}
+	return options
+}
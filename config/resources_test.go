@@ -24,3 +24,87 @@ func TestGetAgentResourceLimits_CustomValues(t *testing.T) {
 	r.Equal(CPUsToMicroseconds(0.1), limits.CPUQuota)
 	r.Equal(MiBToBytes(12), limits.Memory)
 }
+
+func TestGetBotCPUWeight(t *testing.T) {
+	r := require.New(t)
+
+	botConfig := AgentConfig{ID: "0xABCD"}
+	r.Zero(GetBotCPUWeight(ResourcesConfig{}, botConfig))
+
+	resourcesCfg := ResourcesConfig{
+		CPUFairness: BotCPUFairnessConfig{
+			Enable:        true,
+			BotPriorities: map[string]int{"0xabcd": 10},
+		},
+	}
+	r.Equal(int64(200), GetBotCPUWeight(resourcesCfg, botConfig))
+	r.Equal(int64(100), GetBotCPUWeight(resourcesCfg, AgentConfig{ID: "0x1234"}))
+	r.Equal(int64(20), GetBotCPUWeight(resourcesCfg, AgentConfig{ID: "0x1234", BestEffort: true}))
+}
+
+func TestCapBotResourceLimits(t *testing.T) {
+	r := require.New(t)
+
+	resourcesCfg := ResourcesConfig{
+		Reservation: ResourceReservationConfig{
+			Enable:    true,
+			CPUs:      1,
+			MemoryMiB: 1024,
+		},
+	}
+	const gib = 1024 * 1024 * 1024
+
+	// the default limits fit in the budget
+	limits, err := CapBotResourceLimits(resourcesCfg, 4, 16*gib, 10)
+	r.NoError(err)
+	r.Equal(GetAgentResourceLimits(resourcesCfg), limits)
+
+	// the limits are capped when there are too many bots
+	limits, err = CapBotResourceLimits(resourcesCfg, 4, 16*gib, 30)
+	r.NoError(err)
+	r.Equal(CPUsToMicroseconds(0.1), limits.CPUQuota)
+	r.Equal(int64(15*gib/30), limits.Memory)
+
+	// the bots without limits are capped too
+	resourcesCfg.DisableAgentLimits = true
+	limits, err = CapBotResourceLimits(resourcesCfg, 4, 16*gib, 3)
+	r.NoError(err)
+	r.Equal(CPUsToMicroseconds(1), limits.CPUQuota)
+	r.Equal(int64(5*gib), limits.Memory)
+
+	_, err = CapBotResourceLimits(resourcesCfg, 1, 16*gib, 3)
+	r.Error(err)
+}
+
+func TestGetBotContainerConfig(t *testing.T) {
+	r := require.New(t)
+
+	var resourcesCfg ResourcesConfig
+	resourcesCfg.BotContainers.Ulimits.NoFile = &UlimitConfig{Soft: 4096}
+	resourcesCfg.BotContainers.Tmpfs = []TmpfsMountConfig{{Target: "/tmp", SizeMiB: 64}}
+	resourcesCfg.BotContainers.Bots = map[string]BotContainerConfig{
+		"0xAB": {
+			Ulimits: UlimitsConfig{NProc: &UlimitConfig{Soft: 512, Hard: 1024}},
+			Tmpfs: []TmpfsMountConfig{
+				{Target: "/tmp", SizeMiB: 256, Mode: "1777"},
+				{Target: "/scratch", SizeMiB: 128},
+			},
+		},
+	}
+
+	botContainerCfg := GetBotContainerConfig(resourcesCfg, AgentConfig{ID: "0x1"})
+	r.Equal(int64(4096), botContainerCfg.Ulimits.NoFile.HardLimit())
+	r.Nil(botContainerCfg.Ulimits.NProc)
+	r.Len(botContainerCfg.Tmpfs, 1)
+	r.Equal("rw,nosuid,nodev,size=64m", botContainerCfg.Tmpfs[0].Options())
+
+	botContainerCfg = GetBotContainerConfig(resourcesCfg, AgentConfig{ID: "0xab"})
+	r.Equal(int64(4096), botContainerCfg.Ulimits.NoFile.Soft)
+	r.Equal(int64(1024), botContainerCfg.Ulimits.NProc.HardLimit())
+	r.Len(botContainerCfg.Tmpfs, 2)
+	r.Equal("rw,nosuid,nodev,size=256m,mode=1777", botContainerCfg.Tmpfs[0].Options())
+	r.Equal("/scratch", botContainerCfg.Tmpfs[1].Target)
+
+	// the defaults are not changed by the overrides
+	r.Equal(64, resourcesCfg.BotContainers.Tmpfs[0].SizeMiB)
+}
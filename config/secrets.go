@@ -0,0 +1,27 @@
+package config
+
+import "strings"
+
+// BotSecretConfig is a secret which the supervisor delivers to a bot container when it is created.
+// The value is usually a secret reference (file://, env:// or vault://) so that it is read from the
+// secret store of the node.
+type BotSecretConfig struct {
+	// Name is the name of the env var or the file.
+	Name  string `yaml:"name" json:"name" validate:"required"`
+	Value string `yaml:"value" json:"value" validate:"required"`
+	// File delivers the secret as a read-only file in the secrets dir of the bot instead of an env var.
+	File bool `yaml:"file" json:"file"`
+}
+
+// BotSecretsConfig maps the bot IDs to their secrets.
+type BotSecretsConfig map[string][]BotSecretConfig
+
+// Secrets returns the secrets of the bot.
+func (cfg BotSecretsConfig) Secrets(botID string) []BotSecretConfig {
+	for id, secrets := range cfg {
+		if strings.EqualFold(id, botID) {
+			return secrets
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package crashreport
+
+import (
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+const redacted = "<redacted>"
+
+// minSecretLength avoids redacting the short values like "true" everywhere in the text.
+const minSecretLength = 6
+
+var (
+	urlRegexp        = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.\-]*://[^\s"'<>]+`)
+	authHeaderRegexp = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[a-zA-Z0-9._~+/=\-]+`)
+	// private keys and other long hex strings
+	hexRegexp = regexp.MustCompile(`\b(0x)?[0-9a-fA-F]{64,}\b`)
+)
+
+// sensitiveFields are the parts of the config field names which contain secrets. All values
+// under a sensitive field are secrets.
+var sensitiveFields = []string{"password", "passphrase", "token", "secret", "headers", "key", "env"}
+
+// ConfigShape returns the config with the YAML field names where all of the non-empty strings are
+// redacted and the maps are turned into lists. The numbers and the booleans are kept as they are.
+func ConfigShape(cfg config.Config) interface{} {
+	return shapeOf(reflect.ValueOf(cfg))
+}
+
+func shapeOf(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return shapeOf(v.Elem())
+
+	case reflect.Struct:
+		shape := make(map[string]interface{})
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if !field.IsExported() || name == "-" || len(name) == 0 {
+				continue
+			}
+			shape[name] = shapeOf(v.Field(i))
+		}
+		return shape
+
+	case reflect.Map:
+		// the keys are dropped because they can be sensitive too like the bot IDs
+		shape := make([]interface{}, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			shape = append(shape, shapeOf(iter.Value()))
+		}
+		return shape
+
+	case reflect.Slice, reflect.Array:
+		shape := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			shape = append(shape, shapeOf(v.Index(i)))
+		}
+		return shape
+
+	case reflect.String:
+		if v.Len() == 0 {
+			return ""
+		}
+		return redacted
+
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v.Interface()
+
+	default:
+		return nil
+	}
+}
+
+// secretValues collects the values of the sensitive config fields to redact them from the
+// crash messages and the stacks. The longest values are redacted first.
+func secretValues(cfg config.Config) []string {
+	seen := make(map[string]bool)
+	collectSecrets(reflect.ValueOf(cfg), false, seen)
+	var secrets []string
+	for secret := range seen {
+		secrets = append(secrets, secret)
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+	return secrets
+}
+
+func collectSecrets(v reflect.Value, sensitive bool, secrets map[string]bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			collectSecrets(v.Elem(), sensitive, secrets)
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			collectSecrets(v.Field(i), sensitive || isSensitiveField(field.Name), secrets)
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			collectSecrets(iter.Value(), sensitive, secrets)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectSecrets(v.Index(i), sensitive, secrets)
+		}
+
+	case reflect.String:
+		if sensitive && v.Len() >= minSecretLength {
+			secrets[v.String()] = true
+		}
+	}
+}
+
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitiveField := range sensitiveFields {
+		if strings.Contains(name, sensitiveField) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactText removes the secrets, the URL paths and credentials, the auth headers and the long
+// hex strings from the text.
+func redactText(text string, secrets []string) string {
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, redacted)
+	}
+	text = urlRegexp.ReplaceAllStringFunc(text, redactURL)
+	text = authHeaderRegexp.ReplaceAllString(text, "$1 "+redacted)
+	return hexRegexp.ReplaceAllString(text, redacted)
+}
+
+// redactURL keeps only the scheme and the host of the URL because the API keys are usually in
+// the user info, the path or the query.
+func redactURL(rawURL string) string {
+	// keep the punctuation after the URL in the text
+	trimmedURL := strings.TrimRight(rawURL, ".,:;)")
+	suffix := rawURL[len(trimmedURL):]
+	u, err := url.Parse(trimmedURL)
+	if err != nil || len(u.Host) == 0 {
+		return redacted + suffix
+	}
+	redactedURL := u.Scheme + "://" + u.Host
+	if (len(u.Path) > 0 && u.Path != "/") || len(u.RawQuery) > 0 || len(u.Fragment) > 0 {
+		redactedURL += "/" + redacted
+	}
+	return redactedURL + suffix
+}
@@ -0,0 +1,257 @@
+package crashreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// Crash reasons
+const (
+	ReasonPanic = "panic"
+	ReasonFatal = "fatal"
+)
+
+const (
+	reportFileExt     = ".json"
+	reportTimeFormat  = "20060102-150405.000"
+	uploadTimeout     = time.Second * 10
+	maxStackSize      = 1024 * 1024
+	maxStderrLength   = 64 * 1024
+	defaultStderrTail = "500"
+)
+
+// Report is a redacted crash report.
+type Report struct {
+	ID                string                    `json:"id"`
+	Timestamp         time.Time                 `json:"timestamp"`
+	Service           string                    `json:"service"`
+	Reason            string                    `json:"reason"`
+	Message           string                    `json:"message"`
+	Stack             string                    `json:"stack"`
+	Version           string                    `json:"version"`
+	Commit            string                    `json:"commit"`
+	GoVersion         string                    `json:"goVersion"`
+	Platform          string                    `json:"platform"`
+	Config            interface{}               `json:"config"`
+	HealthTransitions []*healthutils.Transition `json:"healthTransitions,omitempty"`
+}
+
+// Reporter writes the crash reports to the disk and uploads them if configured. A nil
+// reporter reports nothing.
+type Reporter struct {
+	cfg     config.Config
+	dir     string
+	secrets []string
+	client  *http.Client
+}
+
+// NewReporter creates a new reporter. It returns nil if the crash reports are disabled.
+func NewReporter(cfg config.Config) *Reporter {
+	if cfg.CrashReport.Disable {
+		return nil
+	}
+	return &Reporter{
+		cfg:     cfg,
+		dir:     path.Join(cfg.FortaDir, config.DefaultCrashReportsDirName),
+		secrets: secretValues(cfg),
+		client:  &http.Client{Timeout: uploadTimeout},
+	}
+}
+
+// Capture creates a report for the crash of the service, writes it to the disk and uploads it.
+func (rep *Reporter) Capture(service, reason, message, stack string) *Report {
+	if rep == nil {
+		return nil
+	}
+	releaseInfo := config.GetBuildReleaseInfo()
+	report := &Report{
+		ID:                uuid.Must(uuid.NewUUID()).String(),
+		Timestamp:         time.Now().UTC(),
+		Service:           service,
+		Reason:            reason,
+		Message:           redactText(message, rep.secrets),
+		Stack:             redactText(stack, rep.secrets),
+		Version:           releaseInfo.Manifest.Release.Version,
+		Commit:            releaseInfo.Manifest.Release.Commit,
+		GoVersion:         runtime.Version(),
+		Platform:          fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		Config:            ConfigShape(rep.cfg),
+		HealthTransitions: rep.healthTransitions(),
+	}
+
+	logger := log.WithFields(log.Fields{
+		"service": service,
+		"reason":  reason,
+	})
+	if err := rep.write(report); err != nil {
+		logger.WithError(err).Warn("failed to write the crash report")
+	}
+	if len(rep.cfg.CrashReport.UploadURL) > 0 {
+		if err := rep.upload(report); err != nil {
+			logger.WithError(err).Warn("failed to upload the crash report")
+		}
+	}
+	return report
+}
+
+// StderrLogsGetter gets the stderr logs of a container.
+type StderrLogsGetter interface {
+	GetContainerStderrLogs(ctx context.Context, containerID, tail string, truncate int) (string, error)
+}
+
+// CaptureContainer checks the stderr logs of an exited container and reports the crash if the
+// logs contain a panic or a fatal error. The logs before the Docker start time of the container
+// are skipped so that the crashes before the last restart are not reported again.
+func (rep *Reporter) CaptureContainer(
+	ctx context.Context, client StderrLogsGetter, containerName, containerID, startedAt string,
+) *Report {
+	if rep == nil {
+		return nil
+	}
+	logs, err := client.GetContainerStderrLogs(ctx, containerID, defaultStderrTail, maxStderrLength)
+	if err != nil {
+		log.WithError(err).WithField("container", containerName).Warn("failed to get the logs of the exited container")
+		return nil
+	}
+	since, _ := time.Parse(time.RFC3339Nano, startedAt)
+	message, stack, ok := FromLogs(logs, since)
+	if !ok {
+		return nil
+	}
+	return rep.Capture(containerName, ReasonPanic, message, stack)
+}
+
+func (rep *Reporter) healthTransitions() []*healthutils.Transition {
+	maxTransitions := rep.cfg.CrashReport.MaxHealthTransitions
+	if maxTransitions == 0 {
+		return nil
+	}
+	filePath := path.Join(rep.cfg.FortaDir, config.DefaultHealthHistoryFileName)
+	if _, err := os.Stat(filePath); err != nil {
+		return nil
+	}
+	transitions := healthutils.NewHistory(filePath, maxTransitions).Transitions(time.Time{})
+	if len(transitions) > maxTransitions {
+		transitions = transitions[len(transitions)-maxTransitions:]
+	}
+	for _, transition := range transitions {
+		transition.Details = redactText(transition.Details, rep.secrets)
+	}
+	return transitions
+}
+
+// write writes the report to a new file and removes the oldest reports.
+func (rep *Reporter) write(report *Report) error {
+	if err := os.MkdirAll(rep.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create the crash reports dir: %v", err)
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the crash report: %v", err)
+	}
+	name := fmt.Sprintf("%s-%s%s", report.Timestamp.Format(reportTimeFormat), report.Service, reportFileExt)
+	if err := os.WriteFile(path.Join(rep.dir, name), b, 0600); err != nil {
+		return fmt.Errorf("failed to write the crash report: %v", err)
+	}
+
+	entries, err := os.ReadDir(rep.dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), reportFileExt) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > rep.cfg.CrashReport.MaxReports {
+		if err := os.Remove(path.Join(rep.dir, names[0])); err != nil {
+			log.WithError(err).Warn("failed to remove the old crash report")
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+func (rep *Reporter) upload(report *Report) error {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode the crash report: %v", err)
+	}
+	resp, err := rep.client.Post(rep.cfg.CrashReport.UploadURL, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("crash report endpoint responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FromLogs finds the panic or the fatal error of the Go runtime in the timestamped logs after
+// the given time. It returns the first line as the message and the rest as the stack.
+func FromLogs(logs string, since time.Time) (message, stack string, ok bool) {
+	var lines []string
+	for _, line := range strings.Split(logs, "\n") {
+		// the empty lines may have only the timestamp
+		parts := strings.SplitN(line, " ", 2)
+		timestamp, err := time.Parse(time.RFC3339Nano, parts[0])
+		if err != nil || timestamp.Before(since) {
+			continue
+		}
+		if len(parts) == 2 {
+			lines = append(lines, parts[1])
+		} else {
+			lines = append(lines, "")
+		}
+	}
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+			return line, strings.TrimSpace(strings.Join(lines[i+1:], "\n")), true
+		}
+	}
+	return "", "", false
+}
+
+// FatalHook returns the hook which reports the crash when the service logs a fatal error.
+func (rep *Reporter) FatalHook(service string) log.Hook {
+	return &fatalHook{reporter: rep, service: service}
+}
+
+type fatalHook struct {
+	reporter *Reporter
+	service  string
+}
+
+// Levels implements log.Hook.
+func (hook *fatalHook) Levels() []log.Level {
+	return []log.Level{log.FatalLevel}
+}
+
+// Fire implements log.Hook.
+func (hook *fatalHook) Fire(entry *log.Entry) error {
+	message := entry.Message
+	if err, ok := entry.Data[log.ErrorKey]; ok {
+		message = fmt.Sprintf("%s: %v", message, err)
+	}
+	stack := make([]byte, maxStackSize)
+	stack = stack[:runtime.Stack(stack, true)]
+	hook.reporter.Capture(hook.service, ReasonFatal, message, string(stack))
+	return nil
+}
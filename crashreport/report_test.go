@@ -0,0 +1,132 @@
+package crashreport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(t *testing.T) config.Config {
+	var cfg config.Config
+	cfg.FortaDir = t.TempDir()
+	cfg.Passphrase = "very-secret-passphrase"
+	cfg.CrashReport.MaxReports = 2
+	cfg.CrashReport.MaxHealthTransitions = 1
+	return cfg
+}
+
+func TestReporter_Capture(t *testing.T) {
+	r := require.New(t)
+
+	var nilReporter *Reporter
+	r.Nil(nilReporter.Capture("forta-scanner", ReasonPanic, "", ""))
+
+	var uploaded *Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		uploaded = &Report{}
+		r.NoError(json.NewDecoder(req.Body).Decode(uploaded))
+	}))
+	defer server.Close()
+
+	cfg := testConfig(t)
+	cfg.CrashReport.UploadURL = server.URL
+	history := healthutils.NewHistory(path.Join(cfg.FortaDir, config.DefaultHealthHistoryFileName), 10)
+	history.Record(health.Reports{{Name: "a", Status: health.StatusFailing}, {Name: "b", Status: health.StatusDown}})
+
+	rep := NewReporter(cfg)
+	report := rep.Capture("forta-scanner", ReasonPanic, "panic: failed with very-secret-passphrase", "goroutine 1 [running]:")
+	r.Equal("panic: failed with <redacted>", report.Message)
+	r.Equal("forta-scanner", report.Service)
+	r.Len(report.HealthTransitions, 1)
+	r.NotNil(uploaded)
+	r.Equal(report.ID, uploaded.ID)
+
+	// the oldest reports are removed
+	for i := 0; i < 2; i++ {
+		time.Sleep(time.Millisecond * 2)
+		rep.Capture("forta-scanner", ReasonPanic, "", "")
+	}
+	entries, err := os.ReadDir(path.Join(cfg.FortaDir, config.DefaultCrashReportsDirName))
+	r.NoError(err)
+	r.Len(entries, 2)
+
+	r.Nil(NewReporter(config.Config{CrashReport: config.CrashReportConfig{Disable: true}}))
+}
+
+func TestFromLogs(t *testing.T) {
+	r := require.New(t)
+
+	logs := `2023-06-13T10:00:00.000000000Z panic: old crash
+2023-06-13T10:00:00.000000001Z goroutine 1 [running]:
+2023-06-13T11:00:00.000000000Z time="2023-06-13T11:00:00Z" level=info msg="starting"
+2023-06-13T11:00:01.000000000Z panic: runtime error: invalid memory address or nil pointer dereference
+2023-06-13T11:00:01.000000001Z [signal SIGSEGV: segmentation violation]
+2023-06-13T11:00:01.000000002Z
+2023-06-13T11:00:01.000000003Z goroutine 12 [running]:
+`
+	since, _ := time.Parse(time.RFC3339, "2023-06-13T11:00:00Z")
+	message, stack, ok := FromLogs(logs, since)
+	r.True(ok)
+	r.Equal("panic: runtime error: invalid memory address or nil pointer dereference", message)
+	r.Equal("[signal SIGSEGV: segmentation violation]\n\ngoroutine 12 [running]:", stack)
+
+	_, _, ok = FromLogs(`2023-06-13T11:00:00.000000000Z level=info msg="stopping"`, since)
+	r.False(ok)
+}
+
+type testLogsGetter struct {
+	logs string
+}
+
+func (getter *testLogsGetter) GetContainerStderrLogs(ctx context.Context, containerID, tail string, truncate int) (string, error) {
+	return getter.logs, nil
+}
+
+func TestReporter_CaptureContainer(t *testing.T) {
+	r := require.New(t)
+
+	rep := NewReporter(testConfig(t))
+	getter := &testLogsGetter{logs: "2023-06-13T11:00:01.000000000Z fatal error: concurrent map writes\n"}
+	report := rep.CaptureContainer(context.Background(), getter, "forta-json-rpc", "container-id", "2023-06-13T11:00:00.123456789Z")
+	r.NotNil(report)
+	r.Equal("forta-json-rpc", report.Service)
+	r.Equal("fatal error: concurrent map writes", report.Message)
+
+	// the crash was before the container started again
+	report = rep.CaptureContainer(context.Background(), getter, "forta-json-rpc", "container-id", "2023-06-13T12:00:00Z")
+	r.Nil(report)
+}
+
+func TestFatalHook(t *testing.T) {
+	r := require.New(t)
+
+	cfg := testConfig(t)
+	hook := NewReporter(cfg).FatalHook("forta-publisher")
+	r.Equal([]log.Level{log.FatalLevel}, hook.Levels())
+
+	entry := log.WithError(os.ErrNotExist)
+	entry.Message = "failed to start"
+	r.NoError(hook.Fire(entry))
+
+	entries, err := os.ReadDir(path.Join(cfg.FortaDir, config.DefaultCrashReportsDirName))
+	r.NoError(err)
+	r.Len(entries, 1)
+	b, err := os.ReadFile(path.Join(cfg.FortaDir, config.DefaultCrashReportsDirName, entries[0].Name()))
+	r.NoError(err)
+	var report Report
+	r.NoError(json.Unmarshal(b, &report))
+	r.Equal(ReasonFatal, report.Reason)
+	r.Equal("failed to start: file does not exist", report.Message)
+	r.Contains(report.Stack, "TestFatalHook")
+}
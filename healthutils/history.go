@@ -0,0 +1,157 @@
+package healthutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	log "github.com/sirupsen/logrus"
+)
+
+// PathHistory is the endpoint which serves the health transitions.
+const PathHistory = "/health/history"
+
+// Transition is a status change of a health report.
+type Transition struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Name      string        `json:"name"`
+	From      health.Status `json:"from,omitempty"`
+	To        health.Status `json:"to"`
+	Details   string        `json:"details,omitempty"`
+}
+
+// History retains the last transitions of the health reports in a ring buffer which is
+// persisted to disk.
+type History struct {
+	path           string
+	maxTransitions int
+
+	transitions []*Transition
+	statuses    map[string]health.Status
+	mu          sync.Mutex
+}
+
+// NewHistory creates a new history and loads the transitions from the file if it exists.
+func NewHistory(filePath string, maxTransitions int) *History {
+	h := &History{
+		path:           filePath,
+		maxTransitions: maxTransitions,
+		statuses:       make(map[string]health.Status),
+	}
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		log.WithError(err).Warn("failed to read the health history")
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &h.transitions); err != nil {
+			log.WithError(err).Warn("failed to decode the health history - discarding")
+		}
+	}
+	return h
+}
+
+// Record compares the reports with the last seen statuses and retains the transitions.
+// The info reports are ignored. The first status of a report is recorded only if it is
+// not healthy.
+func (h *History) Record(reports health.Reports) []*Transition {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now().UTC()
+	var transitions []*Transition
+	for _, report := range reports {
+		if report.Status == health.StatusInfo {
+			continue
+		}
+		prevStatus, ok := h.statuses[report.Name]
+		h.statuses[report.Name] = report.Status
+		if prevStatus == report.Status || (!ok && report.Status == health.StatusOK) {
+			continue
+		}
+		transitions = append(transitions, &Transition{
+			Timestamp: now,
+			Name:      report.Name,
+			From:      prevStatus,
+			To:        report.Status,
+			Details:   report.Details,
+		})
+	}
+	if len(transitions) == 0 {
+		return nil
+	}
+
+	h.transitions = append(h.transitions, transitions...)
+	if overflow := len(h.transitions) - h.maxTransitions; overflow > 0 {
+		h.transitions = h.transitions[overflow:]
+	}
+	if err := h.write(); err != nil {
+		log.WithError(err).Warn("failed to persist the health history")
+	}
+	return transitions
+}
+
+// Transitions returns the transitions after the given time.
+func (h *History) Transitions(since time.Time) []*Transition {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var transitions []*Transition
+	for _, transition := range h.transitions {
+		if transition.Timestamp.After(since) {
+			transitions = append(transitions, transition)
+		}
+	}
+	return transitions
+}
+
+func (h *History) write() error {
+	b, err := json.Marshal(h.transitions)
+	if err != nil {
+		return fmt.Errorf("failed to encode: %v", err)
+	}
+	tmpPath := h.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, h.path)
+}
+
+// HandleHistory serves the transitions. The "since" query parameter accepts a time or a
+// duration like "12h".
+func HandleHistory(mux *http.ServeMux, history *History) {
+	mux.HandleFunc(PathHistory, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		since, err := ParseSince(r.URL.Query().Get("since"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(history.Transitions(since)); err != nil {
+			log.WithError(err).Warn("failed to encode health history")
+		}
+	})
+}
+
+// ParseSince parses an RFC3339 time or a duration before now. It is zero if empty.
+func ParseSince(s string) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("since should be a duration or an RFC3339 time: %s", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,62 @@
+package healthutils
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	r := require.New(t)
+
+	filePath := path.Join(t.TempDir(), ".health-history")
+	h := NewHistory(filePath, 3)
+
+	r.Len(h.Record(health.Reports{
+		{Name: "forta.version", Status: health.StatusInfo, Details: "v1.2.3"},
+		{Name: "forta.container.forta-scanner", Status: health.StatusOK},
+		{Name: "forta.container.forta-json-rpc", Status: health.StatusDown, Details: "exited"},
+	}), 1)
+	r.Empty(h.Record(health.Reports{
+		{Name: "forta.container.forta-scanner", Status: health.StatusOK},
+		{Name: "forta.container.forta-json-rpc", Status: health.StatusDown, Details: "exited"},
+	}))
+	transitions := h.Record(health.Reports{
+		{Name: "forta.container.forta-scanner", Status: health.StatusFailing, Details: "failed to connect"},
+		{Name: "forta.container.forta-json-rpc", Status: health.StatusOK},
+	})
+	r.Len(transitions, 2)
+	r.Equal(health.StatusOK, transitions[0].From)
+	r.Equal(health.StatusFailing, transitions[0].To)
+	r.Equal("failed to connect", transitions[0].Details)
+	r.Len(h.Record(health.Reports{
+		{Name: "forta.container.forta-scanner", Status: health.StatusOK},
+	}), 1)
+
+	// reloaded after a restart with the oldest one dropped
+	h = NewHistory(filePath, 3)
+	all := h.Transitions(time.Time{})
+	r.Len(all, 3)
+	r.Equal("forta.container.forta-scanner", all[0].Name)
+	r.Equal(health.StatusOK, all[2].To)
+
+	r.Empty(h.Transitions(time.Now().Add(time.Minute)))
+}
+
+func TestParseSince(t *testing.T) {
+	r := require.New(t)
+
+	since, err := ParseSince("1h")
+	r.NoError(err)
+	r.WithinDuration(time.Now().Add(-time.Hour), since, time.Second)
+
+	since, err = ParseSince("2023-01-02T03:04:05Z")
+	r.NoError(err)
+	r.Equal(2023, since.Year())
+
+	_, err = ParseSince("yesterday")
+	r.Error(err)
+}
@@ -0,0 +1,160 @@
+package healthutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Node status endpoints
+const (
+	PathLiveness  = "/health/live"
+	PathReadiness = "/health/ready"
+	PathStatus    = "/status"
+)
+
+// NodeStatus is a machine-readable summary of the node health reports. The assignment limits
+// are the reasons why the node may not receive (more) bots.
+type NodeStatus struct {
+	Live                   bool           `json:"live"`
+	Ready                  bool           `json:"ready"`
+	NotReadyReasons        []string       `json:"notReadyReasons,omitempty"`
+	Version                string         `json:"version,omitempty"`
+	Bots                   *int           `json:"bots,omitempty"`
+	LastBlock              *uint64        `json:"lastBlock,omitempty"`
+	ProxyAPI               health.Status  `json:"proxyApi"`
+	RegistrySyncAgeSeconds *int64         `json:"registrySyncAgeSeconds,omitempty"`
+	Stake                  string         `json:"stake,omitempty"`
+	AssignedBots           *int           `json:"assignedBots,omitempty"`
+	SLAScore               *float64       `json:"slaScore,omitempty"`
+	ReadinessScore         *float64       `json:"readinessScore,omitempty"`
+	AssignmentLimits       []string       `json:"assignmentLimits,omitempty"`
+	Reports                health.Reports `json:"reports"`
+}
+
+// NodeStatusFromReports aggregates the reports collected from the runner health server.
+// The node is live if the runner and docker are reachable. It is ready if all of
+// the service containers are running and none of them report being down.
+func NodeStatusFromReports(reports health.Reports) *NodeStatus {
+	status := &NodeStatus{
+		Live:     true,
+		Ready:    true,
+		ProxyAPI: health.StatusUnknown,
+		Reports:  reports,
+	}
+
+	notReady := func(reason string) {
+		status.Ready = false
+		status.NotReadyReasons = append(status.NotReadyReasons, reason)
+	}
+
+	// the proxy instances of the other chains and the protocol proxies report their apis from the same container
+	proxyAPIReportName := fmt.Sprintf("forta.container.%s.service.json-rpc-proxy.api", config.DockerJSONRPCProxyContainerName)
+
+	for _, report := range reports {
+		switch {
+		case report.Name == "health-api" || report.Name == "docker":
+			// the runner is not reachable or can't talk to docker
+			status.Live = false
+			notReady(fmt.Sprintf("%s: %s", report.Name, report.Details))
+
+		case report.Name == "forta.version":
+			status.Version = report.Details
+
+		case strings.HasSuffix(report.Name, "agents.total"):
+			if count, err := strconv.Atoi(report.Details); err == nil {
+				status.Bots = &count
+			}
+
+		case strings.HasSuffix(report.Name, "block-feed.last-block"):
+			if blockNum, err := strconv.ParseUint(report.Details, 10, 64); err == nil {
+				status.LastBlock = &blockNum
+			}
+
+		case report.Name == proxyAPIReportName:
+			status.ProxyAPI = health.StatusOK
+			if len(report.Details) > 0 {
+				status.ProxyAPI = health.StatusFailing
+				notReady(fmt.Sprintf("json-rpc proxy api: %s", report.Details))
+			}
+
+		case strings.HasSuffix(report.Name, "registry.sync.age"):
+			if age, err := time.ParseDuration(report.Details); err == nil {
+				ageSeconds := int64(age.Seconds())
+				status.RegistrySyncAgeSeconds = &ageSeconds
+			}
+
+		case strings.HasSuffix(report.Name, "stake.registered"), strings.HasSuffix(report.Name, "stake.enabled"):
+			if report.Status == health.StatusLagging {
+				status.AssignmentLimits = append(status.AssignmentLimits, fmt.Sprintf("%s: %s", report.Name, report.Details))
+			}
+
+		case strings.HasSuffix(report.Name, "stake.allocated"):
+			status.Stake = report.Details
+			if report.Status == health.StatusLagging {
+				status.AssignmentLimits = append(status.AssignmentLimits, fmt.Sprintf("low stake: %s", report.Details))
+			}
+
+		case strings.HasSuffix(report.Name, "stake.assigned-bots"):
+			if count, err := strconv.Atoi(report.Details); err == nil {
+				status.AssignedBots = &count
+			}
+
+		case strings.HasSuffix(report.Name, "sla.score"):
+			var score float64
+			if _, err := fmt.Sscan(report.Details, &score); err == nil {
+				status.SLAScore = &score
+			}
+			if report.Status == health.StatusLagging {
+				status.AssignmentLimits = append(status.AssignmentLimits, fmt.Sprintf("poor sla: %s", report.Details))
+			}
+
+		case strings.HasSuffix(report.Name, "readiness.score"):
+			var score float64
+			if _, err := fmt.Sscan(report.Details, &score); err == nil {
+				status.ReadinessScore = &score
+			}
+
+		case report.Status == health.StatusDown:
+			notReady(fmt.Sprintf("%s is down", report.Name))
+		}
+	}
+
+	if len(reports) == 0 {
+		status.Live = false
+		notReady("no health reports")
+	}
+
+	return status
+}
+
+// HandleNodeStatus registers the liveness, readiness and status handlers.
+func HandleNodeStatus(mux *http.ServeMux, healthChecker health.HealthChecker) {
+	mux.HandleFunc(PathLiveness, func(w http.ResponseWriter, r *http.Request) {
+		writeNodeStatus(w, healthChecker, func(status *NodeStatus) bool { return status.Live })
+	})
+	mux.HandleFunc(PathReadiness, func(w http.ResponseWriter, r *http.Request) {
+		writeNodeStatus(w, healthChecker, func(status *NodeStatus) bool { return status.Ready })
+	})
+	mux.HandleFunc(PathStatus, func(w http.ResponseWriter, r *http.Request) {
+		writeNodeStatus(w, healthChecker, func(status *NodeStatus) bool { return true })
+	})
+}
+
+func writeNodeStatus(w http.ResponseWriter, healthChecker health.HealthChecker, isOK func(*NodeStatus) bool) {
+	status := NodeStatusFromReports(healthChecker())
+	w.Header().Set("Content-Type", "application/json")
+	if !isOK(status) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.WithError(err).Warn("failed to encode node status")
+	}
+}
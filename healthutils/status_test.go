@@ -0,0 +1,70 @@
+package healthutils
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeStatusFromReports(t *testing.T) {
+	r := require.New(t)
+
+	status := NodeStatusFromReports(health.Reports{
+		{Name: "forta.version", Status: health.StatusInfo, Details: "v1.2.3"},
+		{Name: "forta.container.forta-scanner", Status: health.StatusOK, Details: "running"},
+		{Name: "forta.container.forta-scanner.service.sender.agents.total", Status: health.StatusInfo, Details: "5"},
+		{Name: "forta.container.forta-scanner.service.block-feed.last-block", Status: health.StatusInfo, Details: "123"},
+		{Name: "forta.container.forta-supervisor.service.supervisor.registry.sync.age", Status: health.StatusOK, Details: "1m30s"},
+		{Name: "forta.container.forta-json-rpc.service.json-rpc-proxy.api", Status: health.StatusOK},
+		// the other proxies do not change the main proxy api status
+		{Name: "forta.container.forta-json-rpc.service.json-rpc-proxy-137.api", Status: health.StatusFailing, Details: "connection refused"},
+		{Name: "forta.container.forta-json-rpc.service.protocol-proxy-ipfs.api", Status: health.StatusFailing, Details: "connection refused"},
+	})
+	r.True(status.Live)
+	r.True(status.Ready)
+	r.Equal("v1.2.3", status.Version)
+	r.Equal(5, *status.Bots)
+	r.Equal(uint64(123), *status.LastBlock)
+	r.Equal(health.StatusOK, status.ProxyAPI)
+	r.Equal(int64(90), *status.RegistrySyncAgeSeconds)
+}
+
+func TestNodeStatusFromReports_Stake(t *testing.T) {
+	r := require.New(t)
+
+	status := NodeStatusFromReports(health.Reports{
+		{Name: "forta.container.forta-supervisor.service.supervisor.stake.allocated", Status: health.StatusLagging, Details: "100.00 FORT (min=500.00 FORT, max=3000.00 FORT) - below the minimum stake"},
+		{Name: "forta.container.forta-supervisor.service.supervisor.stake.assigned-bots", Status: health.StatusInfo, Details: "3"},
+		{Name: "forta.container.forta-supervisor.service.supervisor.sla.score", Status: health.StatusLagging, Details: "0.50 - below 0.75"},
+		{Name: "forta.container.forta-supervisor.service.supervisor.readiness.score", Status: health.StatusLagging, Details: "0.70 - below 0.80"},
+	})
+	r.True(status.Ready)
+	r.Equal(3, *status.AssignedBots)
+	r.Equal(0.5, *status.SLAScore)
+	r.Equal(0.7, *status.ReadinessScore)
+	r.Len(status.AssignmentLimits, 2)
+}
+
+func TestNodeStatusFromReports_NotReady(t *testing.T) {
+	r := require.New(t)
+
+	status := NodeStatusFromReports(health.Reports{
+		{Name: "forta.container.forta-scanner", Status: health.StatusDown, Details: "exited"},
+		{Name: "forta.container.forta-json-rpc.service.json-rpc-proxy.api", Status: health.StatusFailing, Details: "connection refused"},
+	})
+	r.True(status.Live)
+	r.False(status.Ready)
+	r.Equal(health.StatusFailing, status.ProxyAPI)
+	r.Len(status.NotReadyReasons, 2)
+}
+
+func TestNodeStatusFromReports_NotLive(t *testing.T) {
+	r := require.New(t)
+
+	status := NodeStatusFromReports(health.Reports{
+		{Name: "health-api", Status: health.StatusDown, Details: "request failed"},
+	})
+	r.False(status.Live)
+	r.False(status.Ready)
+}
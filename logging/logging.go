@@ -0,0 +1,157 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Components which can be configured separately
+const (
+	ComponentSupervisor = "supervisor"
+	ComponentScanner    = "scanner"
+	ComponentProxy      = "json-rpc"
+	ComponentLifecycle  = "lifecycle"
+)
+
+// Components are all of the configurable components.
+var Components = []string{ComponentSupervisor, ComponentScanner, ComponentProxy, ComponentLifecycle}
+
+// ErrUnknownComponent is returned when a component has no logger in this process.
+var ErrUnknownComponent = errors.New("unknown log component")
+
+var (
+	loggers = make(map[string]*log.Logger)
+	mu      sync.Mutex
+)
+
+// Logger returns the logger of a component which runs inside a container. It follows the
+// container logger until the component is configured by Init.
+func Logger(component string) *log.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+	if logger, ok := loggers[component]; ok {
+		return logger
+	}
+	std := log.StandardLogger()
+	logger := log.New()
+	logger.SetLevel(std.GetLevel())
+	logger.SetFormatter(std.Formatter)
+	logger.SetOutput(std.Out)
+	loggers[component] = logger
+	return logger
+}
+
+// Init configures the standard logger as the container component logger and then
+// the loggers of the other components used in the container.
+func Init(cfg config.Config, container string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	std := log.StandardLogger()
+	if err := configure(std, cfg.FortaDir, cfg.Log.ComponentConfig(container)); err != nil {
+		return fmt.Errorf("failed to configure the %s logger: %v", container, err)
+	}
+	for component, logger := range loggers {
+		if component == container {
+			continue
+		}
+		// follow the container output unless the component has its own file
+		logger.SetOutput(std.Out)
+		if err := configure(logger, cfg.FortaDir, cfg.Log.ComponentConfig(component)); err != nil {
+			return fmt.Errorf("failed to configure the %s logger: %v", component, err)
+		}
+	}
+	loggers[container] = std
+	return nil
+}
+
+func configure(logger *log.Logger, fortaDir string, cfg config.ComponentLogConfig) error {
+	lvl := log.InfoLevel
+	if len(cfg.Level) > 0 {
+		var err error
+		lvl, err = log.ParseLevel(cfg.Level)
+		if err != nil {
+			return err
+		}
+	}
+	logger.SetLevel(lvl)
+
+	switch cfg.Format {
+	case "text":
+		logger.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	default:
+		logger.SetFormatter(&log.JSONFormatter{})
+	}
+
+	if len(cfg.File.Path) == 0 {
+		return nil
+	}
+	filePath := cfg.File.Path
+	if !path.IsAbs(filePath) {
+		filePath = path.Join(fortaDir, filePath)
+	}
+	file, err := openRotatingFile(filePath, cfg.File.MaxSizeMB, cfg.File.MaxBackups)
+	if err != nil {
+		return err
+	}
+	logger.SetOutput(io.MultiWriter(logger.Out, file))
+	return nil
+}
+
+// SetLevel changes the level of a component logger at runtime.
+func SetLevel(component, level string) error {
+	lvl, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	logger, ok := loggers[component]
+	mu.Unlock()
+	if !ok {
+		return ErrUnknownComponent
+	}
+	logger.SetLevel(lvl)
+	log.WithFields(log.Fields{
+		"component": component,
+		"level":     lvl.String(),
+	}).Info("changed log level")
+	return nil
+}
+
+// Levels returns the current levels of the component loggers in this process.
+func Levels() map[string]string {
+	mu.Lock()
+	defer mu.Unlock()
+	levels := make(map[string]string)
+	for component, logger := range loggers {
+		levels[component] = logger.GetLevel().String()
+	}
+	return levels
+}
+
+// IsComponent tells if the component is configurable.
+func IsComponent(component string) bool {
+	for _, c := range Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleLevelChange applies the level changes published by the supervisor
+// to the component loggers in this process.
+func HandleLevelChange(payload messaging.LogLevelPayload) error {
+	err := SetLevel(payload.Component, payload.Level)
+	if errors.Is(err, ErrUnknownComponent) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	lifecycleLogger := Logger(ComponentLifecycle)
+
+	cfg := config.Config{FortaDir: dir}
+	cfg.Log.Level = "info"
+	cfg.Log.Format = "json"
+	cfg.Log.Components = map[string]config.ComponentLogConfig{
+		ComponentLifecycle: {
+			Level:  "debug",
+			Format: "text",
+			File:   config.LogFileConfig{Path: "logs/lifecycle.log"},
+		},
+	}
+	r.NoError(Init(cfg, ComponentSupervisor))
+
+	r.Equal(log.InfoLevel, log.StandardLogger().GetLevel())
+	r.Equal(log.DebugLevel, lifecycleLogger.GetLevel())
+	r.IsType(&log.TextFormatter{}, lifecycleLogger.Formatter)
+
+	lifecycleLogger.Debug("hello")
+	b, err := ioutil.ReadFile(path.Join(dir, "logs/lifecycle.log"))
+	r.NoError(err)
+	r.Contains(string(b), "hello")
+
+	r.NoError(HandleLevelChange(messaging.LogLevelPayload{Component: ComponentLifecycle, Level: "warn"}))
+	r.Equal(log.WarnLevel, lifecycleLogger.GetLevel())
+	r.Equal("warning", Levels()[ComponentLifecycle])
+
+	// other containers' components are ignored
+	r.NoError(HandleLevelChange(messaging.LogLevelPayload{Component: ComponentScanner, Level: "debug"}))
+	r.ErrorIs(SetLevel(ComponentScanner, "debug"), ErrUnknownComponent)
+	r.Error(SetLevel(ComponentLifecycle, "loud"))
+}
+
+func TestRotatingFile(t *testing.T) {
+	r := require.New(t)
+
+	filePath := path.Join(t.TempDir(), "test.log")
+	rf, err := openRotatingFile(filePath, 1, 2)
+	r.NoError(err)
+	rf.maxSize = 10
+
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		_, err := rf.Write([]byte(line))
+		r.NoError(err)
+	}
+
+	for name, expected := range map[string]string{
+		filePath:        "dddddddd\n",
+		filePath + ".1": "cccccccc\n",
+		filePath + ".2": "bbbbbbbb\n",
+	} {
+		b, err := ioutil.ReadFile(name)
+		r.NoError(err)
+		r.Equal(expected, string(b))
+	}
+	r.NoFileExists(filePath + ".3")
+}
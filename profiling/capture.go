@@ -0,0 +1,188 @@
+package profiling
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Capture reasons
+const (
+	ReasonHeap       = "heap"
+	ReasonGoroutines = "goroutines"
+)
+
+const captureTimeLayout = "20060102T150405.000Z"
+
+// Capturer writes the heap, goroutine and CPU profiles to disk when the heap size or the
+// goroutine count exceeds the thresholds.
+type Capturer struct {
+	ctx         context.Context
+	cfg         config.ProfilingConfig
+	dir         string
+	lastCapture time.Time
+
+	readStats func() (heapBytes uint64, goroutines int)
+}
+
+// NewCapturer creates a new capturer which writes the profiles under the Forta dir.
+func NewCapturer(ctx context.Context, cfg config.Config) *Capturer {
+	return &Capturer{
+		ctx:       ctx,
+		cfg:       cfg.Profiling,
+		dir:       path.Join(cfg.FortaDir, cfg.Profiling.Dir),
+		readStats: readRuntimeStats,
+	}
+}
+
+func readRuntimeStats() (uint64, int) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	return memStats.HeapAlloc, runtime.NumGoroutine()
+}
+
+// Start starts checking the runtime stats if the auto capture is enabled.
+func (c *Capturer) Start() error {
+	if !c.cfg.AutoCapture {
+		return nil
+	}
+	go c.run()
+	return nil
+}
+
+// Stop implements the service interface.
+func (c *Capturer) Stop() error {
+	return nil
+}
+
+// Name returns the name of the service.
+func (c *Capturer) Name() string {
+	return "profiling"
+}
+
+func (c *Capturer) run() {
+	ticker := time.NewTicker(time.Duration(c.cfg.CheckIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		c.check()
+	}
+}
+
+// check captures the profiles if a threshold is exceeded and the last capture is not too recent.
+func (c *Capturer) check() {
+	if time.Since(c.lastCapture) < time.Duration(c.cfg.CooldownMinutes)*time.Minute {
+		return
+	}
+	heapBytes, goroutines := c.readStats()
+	var reason string
+	switch {
+	case heapBytes > uint64(c.cfg.HeapThresholdMB)*1024*1024:
+		reason = ReasonHeap
+	case goroutines > c.cfg.GoroutineThreshold:
+		reason = ReasonGoroutines
+	default:
+		return
+	}
+	c.lastCapture = time.Now()
+	logger := log.WithFields(log.Fields{
+		"reason":     reason,
+		"heapMb":     heapBytes / 1024 / 1024,
+		"goroutines": goroutines,
+	})
+	captureDir, err := c.capture(reason)
+	if err != nil {
+		logger.WithError(err).Error("failed to capture the profiles")
+		return
+	}
+	logger.WithField("dir", captureDir).Warn("captured the profiles")
+	if err := c.cleanup(); err != nil {
+		log.WithError(err).Warn("failed to clean up the old profiles")
+	}
+}
+
+func (c *Capturer) capture(reason string) (string, error) {
+	captureDir := path.Join(c.dir, fmt.Sprintf("%s-%s", time.Now().UTC().Format(captureTimeLayout), reason))
+	if err := os.MkdirAll(captureDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create the capture dir: %v", err)
+	}
+	for _, name := range []string{"heap", "goroutine"} {
+		if err := writeProfile(path.Join(captureDir, name+".pprof"), name); err != nil {
+			return captureDir, err
+		}
+	}
+	if c.cfg.CPUProfileSeconds > 0 {
+		if err := c.writeCPUProfile(path.Join(captureDir, "cpu.pprof")); err != nil {
+			return captureDir, err
+		}
+	}
+	return captureDir, nil
+}
+
+func writeProfile(filePath, name string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create the %s profile file: %v", name, err)
+	}
+	defer f.Close()
+	if err := pprof.Lookup(name).WriteTo(f, 0); err != nil {
+		return fmt.Errorf("failed to write the %s profile: %v", name, err)
+	}
+	return nil
+}
+
+func (c *Capturer) writeCPUProfile(filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create the cpu profile file: %v", err)
+	}
+	defer f.Close()
+	// fails if a cpu profile is already being collected from the endpoint
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		os.Remove(filePath)
+		return fmt.Errorf("failed to start the cpu profile: %v", err)
+	}
+	select {
+	case <-c.ctx.Done():
+	case <-time.After(time.Duration(c.cfg.CPUProfileSeconds) * time.Second):
+	}
+	pprof.StopCPUProfile()
+	return nil
+}
+
+// cleanup removes the oldest captures which exceed the retention limit.
+func (c *Capturer) cleanup() error {
+	entries, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	var captures []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			captures = append(captures, entry.Name())
+		}
+	}
+	// names start with the capture time
+	sort.Strings(captures)
+	for len(captures) > c.cfg.MaxCaptures {
+		if err := os.RemoveAll(path.Join(c.dir, captures[0])); err != nil {
+			return err
+		}
+		captures = captures[1:]
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package profiling
+
+import (
+	"context"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapturer(t *testing.T) {
+	r := require.New(t)
+
+	cfg := config.Config{FortaDir: t.TempDir()}
+	cfg.Profiling = config.ProfilingConfig{
+		AutoCapture:        true,
+		Dir:                "profiles",
+		HeapThresholdMB:    100,
+		GoroutineThreshold: 1000,
+		CooldownMinutes:    30,
+		MaxCaptures:        2,
+	}
+	c := NewCapturer(context.Background(), cfg)
+
+	var (
+		heapBytes  uint64
+		goroutines int
+	)
+	c.readStats = func() (uint64, int) {
+		return heapBytes, goroutines
+	}
+	captures := func() []string {
+		entries, _ := ioutil.ReadDir(c.dir)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	// under the thresholds
+	heapBytes, goroutines = 1024, 10
+	c.check()
+	r.Empty(captures())
+
+	goroutines = 1001
+	c.check()
+	r.Len(captures(), 1)
+	r.Contains(captures()[0], ReasonGoroutines)
+	for _, name := range []string{"heap.pprof", "goroutine.pprof"} {
+		r.FileExists(path.Join(c.dir, captures()[0], name))
+	}
+	r.NoFileExists(path.Join(c.dir, captures()[0], "cpu.pprof"))
+
+	// cooling down
+	heapBytes = 200 * 1024 * 1024
+	c.check()
+	r.Len(captures(), 1)
+
+	for i := 0; i < 3; i++ {
+		c.lastCapture = c.lastCapture.AddDate(0, 0, -1)
+		c.check()
+	}
+	r.Len(captures(), 2)
+}
@@ -0,0 +1,23 @@
+package profiling
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+)
+
+// Profiling endpoints
+const (
+	PathPprof  = "/debug/pprof/"
+	PathExpvar = "/debug/vars"
+)
+
+// Handle registers the pprof and the expvar endpoints to the mux.
+func Handle(mux *http.ServeMux) {
+	mux.HandleFunc(PathPprof, pprof.Index)
+	mux.HandleFunc(PathPprof+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(PathPprof+"profile", pprof.Profile)
+	mux.HandleFunc(PathPprof+"symbol", pprof.Symbol)
+	mux.HandleFunc(PathPprof+"trace", pprof.Trace)
+	mux.Handle(PathExpvar, expvar.Handler())
+}
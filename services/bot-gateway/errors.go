@@ -0,0 +1,45 @@
+package bot_gateway
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type errorResponse struct {
+	Error gatewayError `json:"error"`
+}
+
+type gatewayError struct {
+	Message string `json:"message"`
+}
+
+func writeAuthError(w http.ResponseWriter) {
+	writeError(w, http.StatusUnauthorized, "request source is not a deployed bot")
+}
+
+func writeTooManyReqsErr(w http.ResponseWriter) {
+	writeError(w, http.StatusTooManyRequests, "bot exceeds request rate limit")
+}
+
+func writeQuotaExceededErr(w http.ResponseWriter) {
+	writeError(w, http.StatusTooManyRequests, "bot exceeds the request quota of the scan node gateway")
+}
+
+func writeNotAllowedErr(w http.ResponseWriter) {
+	writeError(w, http.StatusForbidden, "destination is not allowed by the scan node egress policy")
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(&errorResponse{
+		Error: gatewayError{
+			Message: message,
+		},
+	}); err != nil {
+		log.WithError(err).Error("failed to write error response body")
+	}
+}
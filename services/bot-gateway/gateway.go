@@ -0,0 +1,333 @@
+package bot_gateway
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/clients/ratelimiter"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/services/egress"
+	"github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	defaultRateLimit = config.RateLimitConfig{Rate: 10, Burst: 10}
+
+	errResponseTooLarge = errors.New("response exceeds the size limit of the gateway")
+)
+
+// cachedResponse is a successful GET response which is served again to the same bot.
+type cachedResponse struct {
+	header http.Header
+	body   []byte
+}
+
+type requestStateKey struct{}
+
+// requestState is shared by the handler and the reverse proxy callbacks.
+type requestState struct {
+	cacheKey string
+	failed   bool
+}
+
+// Gateway makes the HTTPS requests of the bots to the external APIs. The bots are authenticated
+// like in the other proxies and they can reach only the hosts which are allowed by the egress policy.
+type Gateway struct {
+	ctx       context.Context
+	cfg       config.BotGatewayConfig
+	egressCfg config.BotEgressConfig
+	server    *http.Server
+	forwarder http.Handler
+	msgClient clients.MessageClient
+	cache     *cache.Cache
+
+	rateLimiter      ratelimiter.RateLimiter
+	botAuthenticator clients.IPAuthenticator
+	quota            *requestQuota
+
+	requests  uint64
+	denied    uint64
+	throttled uint64
+	cacheHits uint64
+}
+
+// NewGateway creates a new bot gateway.
+func NewGateway(
+	ctx context.Context, egressCfg config.BotEgressConfig,
+	botAuthenticator clients.IPAuthenticator, msgClient clients.MessageClient,
+) *Gateway {
+	cfg := egressCfg.Gateway
+	rateLimiting := cfg.RateLimitConfig
+	if rateLimiting == nil {
+		rateLimiting = &defaultRateLimit
+	}
+	g := &Gateway{
+		ctx:              ctx,
+		cfg:              cfg,
+		egressCfg:        egressCfg,
+		msgClient:        msgClient,
+		botAuthenticator: botAuthenticator,
+		rateLimiter:      ratelimiter.NewRateLimiter(rateLimiting.Rate, rateLimiting.Burst),
+		quota:            newRequestQuota(cfg.HourlyRequests, cfg.DailyRequests),
+	}
+	if cfg.CacheTTLSeconds > 0 {
+		ttl := time.Duration(cfg.CacheTTLSeconds) * time.Second
+		g.cache = cache.New(ttl, ttl*2)
+	}
+	dialer := egress.NewDialer()
+	g.forwarder = &httputil.ReverseProxy{
+		// the request url is set by the handler
+		Director: func(r *http.Request) {
+			// do not reveal the internal addresses to the external hosts
+			r.Header["X-Forwarded-For"] = nil
+		},
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: time.Second * 10,
+		},
+		ModifyResponse: g.handleResponse,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.WithError(err).WithField("host", r.URL.Host).Warn("failed to forward the bot request")
+			r.Context().Value(requestStateKey{}).(*requestState).failed = true
+			if errors.Is(err, errResponseTooLarge) {
+				writeError(w, http.StatusBadGateway, errResponseTooLarge.Error())
+				return
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	return g
+}
+
+// ServeHTTP implements http.Handler.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	t := time.Now()
+	botConfig, err := g.botAuthenticator.FindAgentFromRequest(req)
+	if err != nil {
+		log.WithError(err).Warn("failed to authenticate the bot gateway request")
+		writeAuthError(w)
+		return
+	}
+	atomic.AddUint64(&g.requests, 1)
+
+	target, ok := parseTarget(req)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "request path should start with the destination host")
+		return
+	}
+	logger := log.WithFields(log.Fields{
+		"bot":  botConfig.ID,
+		"host": target.Host,
+	})
+	if !g.egressCfg.IsAllowed(*botConfig, target.Host) {
+		atomic.AddUint64(&g.denied, 1)
+		logger.Warn("bot tried to reach a host which is not allowed through the gateway")
+		writeNotAllowedErr(w)
+		g.publishMetrics(botConfig.ID, t, metrics.MetricBotGatewayDenied, 0)
+		return
+	}
+
+	state := &requestState{}
+	if g.cache != nil && req.Method == http.MethodGet {
+		state.cacheKey = strings.Join([]string{botConfig.ID, req.Header.Get("Accept-Encoding"), target.String()}, "\n")
+		if v, ok := g.cache.Get(state.cacheKey); ok {
+			atomic.AddUint64(&g.cacheHits, 1)
+			writeCachedResponse(w, v.(*cachedResponse))
+			g.publishMetrics(botConfig.ID, t, metrics.MetricBotGatewayCacheHit, time.Since(t))
+			return
+		}
+	}
+
+	// the cached responses do not use the rate limit and the quota of the bot
+	if g.rateLimiter.ExceedsLimit(botConfig.ID) {
+		atomic.AddUint64(&g.throttled, 1)
+		writeTooManyReqsErr(w)
+		g.publishMetrics(botConfig.ID, t, metrics.MetricBotGatewayThrottled, 0)
+		return
+	}
+	if !g.quota.Use(botConfig.ID) {
+		atomic.AddUint64(&g.throttled, 1)
+		writeQuotaExceededErr(w)
+		g.publishMetrics(botConfig.ID, t, metrics.MetricBotGatewayQuotaExceeded, 0)
+		return
+	}
+	logger.Debug("forwarding the bot gateway request")
+
+	// the streamed responses which exceed the size limit abort the handler
+	defer func() {
+		metricName := metrics.MetricBotGatewaySuccess
+		if state.failed {
+			metricName = metrics.MetricBotGatewayFailed
+		}
+		g.publishMetrics(botConfig.ID, t, metricName, time.Since(t))
+	}()
+	req = req.WithContext(context.WithValue(req.Context(), requestStateKey{}, state))
+	req.URL = target
+	req.Host = target.Host
+	g.forwarder.ServeHTTP(w, req)
+}
+
+// parseTarget gets the destination from the request path which is like /<host>/<path>.
+func parseTarget(req *http.Request) (*url.URL, bool) {
+	host, path, _ := strings.Cut(strings.TrimPrefix(req.URL.EscapedPath(), "/"), "/")
+	if len(host) == 0 {
+		return nil, false
+	}
+	target, err := url.Parse("https://" + host + "/" + path)
+	// the user info and the other tricks should not change the destination
+	if err != nil || target.Host != host || target.User != nil {
+		return nil, false
+	}
+	target.RawQuery = req.URL.RawQuery
+	return target, true
+}
+
+// handleResponse limits the response size and caches the successful GET responses.
+func (g *Gateway) handleResponse(resp *http.Response) error {
+	maxBytes := int64(g.cfg.MaxResponseBytes)
+	if maxBytes > 0 && resp.ContentLength > maxBytes {
+		return errResponseTooLarge
+	}
+	state := resp.Request.Context().Value(requestStateKey{}).(*requestState)
+	if len(state.cacheKey) == 0 || resp.StatusCode != http.StatusOK ||
+		strings.Contains(resp.Header.Get("Cache-Control"), "no-store") ||
+		(g.cfg.MaxCacheEntries > 0 && g.cache.ItemCount() >= g.cfg.MaxCacheEntries) {
+		if maxBytes > 0 {
+			resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: maxBytes}
+		}
+		return nil
+	}
+
+	reader := io.Reader(resp.Body)
+	if maxBytes > 0 {
+		reader = io.LimitReader(resp.Body, maxBytes+1)
+	}
+	body, err := ioutil.ReadAll(reader)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	if maxBytes > 0 && int64(len(body)) > maxBytes {
+		return errResponseTooLarge
+	}
+	g.cache.SetDefault(state.cacheKey, &cachedResponse{header: resp.Header.Clone(), body: body})
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return nil
+}
+
+func writeCachedResponse(w http.ResponseWriter, resp *cachedResponse) {
+	for h, v := range resp.header {
+		w.Header()[h] = v
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(resp.body)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp.body); err != nil {
+		log.WithError(err).Debug("failed to write the cached response")
+	}
+}
+
+// limitedBody fails the reads after the limit is exceeded.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+// Read implements io.Reader.
+func (lb *limitedBody) Read(b []byte) (int, error) {
+	if lb.remaining < 0 {
+		return 0, errResponseTooLarge
+	}
+	// read one byte more than the limit to tell if the response ends right at the limit
+	if int64(len(b)) > lb.remaining+1 {
+		b = b[:lb.remaining+1]
+	}
+	n, err := lb.ReadCloser.Read(b)
+	lb.remaining -= int64(n)
+	if lb.remaining < 0 {
+		return 0, errResponseTooLarge
+	}
+	return n, err
+}
+
+func (g *Gateway) publishMetrics(botID string, t time.Time, metricName string, latency time.Duration) {
+	g.msgClient.PublishProto(
+		messaging.SubjectMetricAgent, &protocol.AgentMetricList{
+			Metrics: metrics.GetBotGatewayMetrics(botID, t, metricName, latency),
+		},
+	)
+}
+
+// Start starts the gateway.
+func (g *Gateway) Start() error {
+	g.server = &http.Server{
+		Addr:    g.cfg.ListenAddr,
+		Handler: g,
+	}
+	if len(g.server.Addr) == 0 {
+		g.server.Addr = ":" + config.DefaultBotGatewayPort
+	}
+	utils.GoListenAndServe(g.server)
+	return nil
+}
+
+// ShutdownStage implements the services.StagedService interface.
+func (g *Gateway) ShutdownStage() services.ShutdownStage {
+	return services.StageProxy
+}
+
+// Stop stops the gateway.
+func (g *Gateway) Stop() error {
+	if g.server != nil {
+		return g.server.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (g *Gateway) Name() string {
+	return "bot-gateway"
+}
+
+// Health implements health.Reporter interface.
+func (g *Gateway) Health() health.Reports {
+	return health.Reports{
+		{
+			Name:    "bot-gateway.requests",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&g.requests), 10),
+		},
+		{
+			Name:    "bot-gateway.denied",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&g.denied), 10),
+		},
+		{
+			Name:    "bot-gateway.throttled",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&g.throttled), 10),
+		},
+		{
+			Name:    "bot-gateway.cache.hits",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&g.cacheHits), 10),
+		},
+	}
+}
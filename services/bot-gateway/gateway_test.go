@@ -0,0 +1,122 @@
+package bot_gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGateway(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	authenticator := mock_clients.NewMockIPAuthenticator(ctrl)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+	msgClient.EXPECT().PublishProto(gomock.Any(), gomock.Any()).AnyTimes()
+
+	var calls int64
+	api := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		r.Equal("/api/v3/simple/price", req.URL.Path)
+		r.Equal("ethereum", req.URL.Query().Get("ids"))
+		r.Empty(req.Header.Get("X-Forwarded-For"))
+		w.Write([]byte(`{"ethereum":{"usd":1000}}`))
+	}))
+	defer api.Close()
+	apiURL, _ := url.Parse(api.URL)
+
+	gateway := NewGateway(context.Background(), config.BotEgressConfig{
+		ApprovedHosts: []string{"127.0.0.1"},
+		Gateway: config.BotGatewayConfig{
+			Enable:          true,
+			DailyRequests:   2,
+			CacheTTLSeconds: 60,
+		},
+	}, authenticator, msgClient)
+	// the test server is on the loopback address
+	gateway.forwarder.(*httputil.ReverseProxy).Transport = api.Client().Transport
+
+	botConfig := &config.AgentConfig{ID: "0xbot", EgressHosts: []string{"127.0.0.1"}}
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// the requests which are not coming from the bots are rejected
+	authenticator.EXPECT().FindAgentFromRequest(gomock.Any()).Return(nil, errors.New("not found"))
+	r.Equal(http.StatusUnauthorized, serve("/"+apiURL.Host+"/api/v3/simple/price?ids=ethereum").Code)
+
+	authenticator.EXPECT().FindAgentFromRequest(gomock.Any()).Return(botConfig, nil).AnyTimes()
+
+	// the hosts which are not allowed are denied
+	r.Equal(http.StatusForbidden, serve("/evil.com/api").Code)
+	r.Equal(http.StatusBadRequest, serve("/").Code)
+
+	// the second request is served from the cache
+	for i := 0; i < 2; i++ {
+		recorder := serve("/" + apiURL.Host + "/api/v3/simple/price?ids=ethereum")
+		r.Equal(http.StatusOK, recorder.Code)
+		r.Equal(`{"ethereum":{"usd":1000}}`, recorder.Body.String())
+	}
+	r.Equal(int64(1), atomic.LoadInt64(&calls))
+
+	// the bot can make one more request in the same day
+	r.Equal(http.StatusOK, serve("/"+apiURL.Host+"/api/v3/simple/price?ids=ethereum&vs_currencies=usd").Code)
+	r.Equal(http.StatusTooManyRequests, serve("/"+apiURL.Host+"/api/v3/simple/price?ids=ethereum&vs_currencies=eur").Code)
+
+	reports := gateway.Health()
+	r.Equal("6", reports[0].Details)
+	r.Equal("1", reports[1].Details)
+	r.Equal("1", reports[2].Details)
+	r.Equal("1", reports[3].Details)
+}
+
+func TestParseTarget(t *testing.T) {
+	r := require.New(t)
+
+	target, ok := parseTarget(httptest.NewRequest(http.MethodGet, "/api.example.com/v1/a%2Fb?x=1", nil))
+	r.True(ok)
+	r.Equal("https://api.example.com/v1/a%2Fb?x=1", target.String())
+
+	target, ok = parseTarget(httptest.NewRequest(http.MethodGet, "/api.example.com", nil))
+	r.True(ok)
+	r.Equal("https://api.example.com/", target.String())
+
+	for _, path := range []string{"/", "/user@evil.com/"} {
+		_, ok := parseTarget(httptest.NewRequest(http.MethodGet, path, nil))
+		r.False(ok, path)
+	}
+}
+
+func TestRequestQuota(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Date(2023, 1, 1, 10, 30, 0, 0, time.UTC)
+	quota := newRequestQuota(2, 3)
+	quota.now = func() time.Time { return now }
+
+	r.True(quota.Use("0xbot"))
+	r.True(quota.Use("0xbot"))
+	r.False(quota.Use("0xbot"))
+	r.True(quota.Use("0xanother"))
+
+	// the hourly quota is reset in the next hour but the daily quota is not
+	now = now.Add(time.Hour)
+	r.True(quota.Use("0xbot"))
+	r.False(quota.Use("0xbot"))
+
+	now = now.Add(time.Hour * 24)
+	r.True(quota.Use("0xbot"))
+}
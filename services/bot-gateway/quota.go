@@ -0,0 +1,67 @@
+package bot_gateway
+
+import (
+	"sync"
+	"time"
+)
+
+// usageWindow counts the requests in a time window which starts at the given unix time.
+type usageWindow struct {
+	start    int64
+	requests int
+}
+
+func (uw *usageWindow) use(start int64, max int) bool {
+	if uw.start != start {
+		*uw = usageWindow{start: start}
+	}
+	if max > 0 && uw.requests >= max {
+		return false
+	}
+	uw.requests++
+	return true
+}
+
+type botUsage struct {
+	hourly usageWindow
+	daily  usageWindow
+}
+
+// requestQuota limits the hourly and daily requests of each bot.
+type requestQuota struct {
+	hourly int
+	daily  int
+	usage  map[string]*botUsage
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+func newRequestQuota(hourly, daily int) *requestQuota {
+	return &requestQuota{
+		hourly: hourly,
+		daily:  daily,
+		usage:  make(map[string]*botUsage),
+		now:    time.Now,
+	}
+}
+
+// Use counts a request of the bot and tells if the bot is still within the quota. The requests
+// which exceed the quota are not counted.
+func (q *requestQuota) Use(botID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage, ok := q.usage[botID]
+	if !ok {
+		usage = &botUsage{}
+		q.usage[botID] = usage
+	}
+	now := q.now().UTC()
+	hourly, daily := usage.hourly, usage.daily
+	if !hourly.use(now.Truncate(time.Hour).Unix(), q.hourly) ||
+		!daily.use(now.Truncate(time.Hour*24).Unix(), q.daily) {
+		return false
+	}
+	usage.hourly, usage.daily = hourly, daily
+	return true
+}
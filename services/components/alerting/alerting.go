@@ -0,0 +1,263 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/notifier"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// Rule names
+const (
+	RuleBotCrashLoop      = "bot-crash-loop"
+	RuleRegistrySyncStale = "registry-sync-stale"
+	RuleProxyErrorRate    = "proxy-error-rate"
+	RuleDiskSpaceLow      = "disk-space-low"
+)
+
+// Engine evaluates the alerting rules periodically and notifies the operator when a rule
+// starts firing, keeps firing and gets resolved.
+type Engine struct {
+	ctx       context.Context
+	cfg       config.AlertingConfig
+	notifiers []notifier.Notifier
+	disabled  map[string]bool
+	startedAt time.Time
+
+	lastSynced func() time.Time
+	diskFree   func() (uint64, error)
+
+	restarts      map[string][]time.Time
+	proxyRequests float64
+	proxyErrors   float64
+	firing        map[string]*firingRule
+	mu            sync.Mutex
+}
+
+type firingRule struct {
+	message      string
+	lastNotified time.Time
+}
+
+// NewEngine creates a new engine. The registry sync time and the free disk space are read
+// from the given functions.
+func NewEngine(
+	ctx context.Context, cfg config.AlertingConfig, notifiers []notifier.Notifier,
+	lastSynced func() time.Time, diskFree func() (uint64, error),
+) *Engine {
+	disabled := make(map[string]bool)
+	for _, rule := range cfg.Rules.Disabled {
+		disabled[rule] = true
+	}
+	return &Engine{
+		ctx:        ctx,
+		cfg:        cfg,
+		notifiers:  notifiers,
+		disabled:   disabled,
+		startedAt:  time.Now(),
+		lastSynced: lastSynced,
+		diskFree:   diskFree,
+		restarts:   make(map[string][]time.Time),
+		firing:     make(map[string]*firingRule),
+	}
+}
+
+// Start starts evaluating the rules if the alerting is enabled.
+func (e *Engine) Start() {
+	if !e.cfg.Enable {
+		return
+	}
+	if len(e.notifiers) == 0 {
+		log.Warn("alerting is enabled but no notifiers are configured")
+	}
+	go e.run()
+}
+
+func (e *Engine) run() {
+	ticker := time.NewTicker(time.Duration(e.cfg.CheckIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		e.Evaluate()
+	}
+}
+
+// HandleAgentMetrics collects the bot restarts and the proxy request counts from the metrics.
+func (e *Engine) HandleAgentMetrics(payload *protocol.AgentMetricList) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, metric := range payload.Metrics {
+		switch metric.Name {
+		case metrics.MetricActionRestart:
+			botID := strings.ToLower(metric.AgentId)
+			e.restarts[botID] = append(e.restarts[botID], time.Now())
+
+		case metrics.MetricJSONRPCRequest:
+			e.proxyRequests += metric.Value
+
+		// the requests which the proxy did not serve
+		case metrics.MetricJSONRPCThrottled:
+			e.proxyErrors += metric.Value
+
+		case metrics.MetricJSONRPCDenied, metrics.MetricJSONRPCQuotaExceeded:
+			e.proxyRequests += metric.Value
+			e.proxyErrors += metric.Value
+		}
+	}
+	return nil
+}
+
+// Evaluate evaluates all rules and sends the notifications.
+func (e *Engine) Evaluate() {
+	e.mu.Lock()
+	results := map[string]string{
+		RuleBotCrashLoop:   e.checkBotCrashLoop(),
+		RuleProxyErrorRate: e.checkProxyErrorRate(),
+	}
+	e.mu.Unlock()
+	results[RuleRegistrySyncStale] = e.checkRegistrySync()
+	results[RuleDiskSpaceLow] = e.checkDiskSpace()
+
+	var rules []string
+	for rule := range results {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		if e.disabled[rule] {
+			continue
+		}
+		e.update(rule, results[rule])
+	}
+}
+
+// Firing returns the messages of the firing rules.
+func (e *Engine) Firing() map[string]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	firing := make(map[string]string)
+	for rule, state := range e.firing {
+		firing[rule] = state.message
+	}
+	return firing
+}
+
+// update notifies if the rule started firing, is still firing after the repeat interval or got resolved.
+func (e *Engine) update(rule, message string) {
+	e.mu.Lock()
+	state, wasFiring := e.firing[rule]
+	var notification *notifier.Notification
+	switch {
+	case len(message) > 0 && !wasFiring:
+		state = &firingRule{}
+		e.firing[rule] = state
+		fallthrough
+
+	case len(message) > 0 && time.Since(state.lastNotified) >= time.Duration(e.cfg.RepeatIntervalMinutes)*time.Minute:
+		state.message = message
+		state.lastNotified = time.Now()
+		notification = &notifier.Notification{Rule: rule, Firing: true, Message: message, Timestamp: time.Now()}
+
+	case len(message) > 0:
+		state.message = message
+
+	case wasFiring:
+		delete(e.firing, rule)
+		notification = &notifier.Notification{Rule: rule, Message: state.message, Timestamp: time.Now()}
+	}
+	e.mu.Unlock()
+
+	if notification != nil {
+		e.notify(notification)
+	}
+}
+
+func (e *Engine) notify(notification *notifier.Notification) {
+	logger := log.WithFields(log.Fields{
+		"rule":   notification.Rule,
+		"firing": notification.Firing,
+	})
+	logger.Warn(notification.Message)
+	for _, n := range e.notifiers {
+		if err := n.Notify(e.ctx, notification); err != nil {
+			logger.WithError(err).WithField("notifier", n.Name()).Error("failed to send the notification")
+		}
+	}
+}
+
+func (e *Engine) checkBotCrashLoop() string {
+	window := time.Duration(e.cfg.Rules.BotRestartWindowMinutes) * time.Minute
+	minTime := time.Now().Add(-window)
+	var crashLooping []string
+	for botID, restarts := range e.restarts {
+		for len(restarts) > 0 && restarts[0].Before(minTime) {
+			restarts = restarts[1:]
+		}
+		if len(restarts) == 0 {
+			delete(e.restarts, botID)
+			continue
+		}
+		e.restarts[botID] = restarts
+		if len(restarts) >= e.cfg.Rules.BotRestarts {
+			crashLooping = append(crashLooping, fmt.Sprintf("%s (%d restarts)", botID, len(restarts)))
+		}
+	}
+	if len(crashLooping) == 0 {
+		return ""
+	}
+	sort.Strings(crashLooping)
+	return fmt.Sprintf("bots restarted too many times in the last %s: %s", window, strings.Join(crashLooping, ", "))
+}
+
+// checkProxyErrorRate checks the error rate of the JSON-RPC proxy since the last evaluation.
+func (e *Engine) checkProxyErrorRate() string {
+	requests, errs := e.proxyRequests, e.proxyErrors
+	e.proxyRequests, e.proxyErrors = 0, 0
+	if requests < float64(e.cfg.Rules.ProxyMinRequests) {
+		return ""
+	}
+	rate := errs / requests * 100
+	if rate <= e.cfg.Rules.ProxyErrorRatePercent {
+		return ""
+	}
+	return fmt.Sprintf("json-rpc proxy failed %.1f%% of %d requests", rate, int(requests))
+}
+
+func (e *Engine) checkRegistrySync() string {
+	lastSynced := e.lastSynced()
+	if lastSynced.IsZero() {
+		// not synced since the start
+		lastSynced = e.startedAt
+	}
+	age := time.Since(lastSynced)
+	if age <= time.Duration(e.cfg.Rules.RegistryStaleMinutes)*time.Minute {
+		return ""
+	}
+	return fmt.Sprintf("bot assignments were not synced from the registry for %s", age.Truncate(time.Second))
+}
+
+func (e *Engine) checkDiskSpace() string {
+	free, err := e.diskFree()
+	if err != nil {
+		log.WithError(err).Warn("failed to check the free disk space")
+		return ""
+	}
+	freeGB := float64(free) / 1024 / 1024 / 1024
+	if freeGB >= e.cfg.Rules.MinDiskSpaceGB {
+		return ""
+	}
+	return fmt.Sprintf("only %.2f GB of disk space is left", freeGB)
+}
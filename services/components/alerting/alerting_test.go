@@ -0,0 +1,90 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/notifier"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+type testNotifier struct {
+	notifications []*notifier.Notification
+}
+
+func (tn *testNotifier) Notify(ctx context.Context, notification *notifier.Notification) error {
+	tn.notifications = append(tn.notifications, notification)
+	return nil
+}
+
+func (tn *testNotifier) Name() string {
+	return "test"
+}
+
+func testAlertingConfig() config.AlertingConfig {
+	return config.AlertingConfig{
+		Enable:                true,
+		RepeatIntervalMinutes: 60,
+		Rules: config.AlertingRulesConfig{
+			Disabled:                []string{RuleDiskSpaceLow},
+			BotRestarts:             2,
+			BotRestartWindowMinutes: 15,
+			RegistryStaleMinutes:    30,
+			ProxyErrorRatePercent:   10,
+			ProxyMinRequests:        10,
+			MinDiskSpaceGB:          5,
+		},
+	}
+}
+
+func TestEngine(t *testing.T) {
+	r := require.New(t)
+
+	tn := &testNotifier{}
+	lastSynced := time.Now()
+	e := NewEngine(
+		context.Background(), testAlertingConfig(), []notifier.Notifier{tn},
+		func() time.Time { return lastSynced },
+		func() (uint64, error) { return 0, nil },
+	)
+
+	r.NoError(e.HandleAgentMetrics(&protocol.AgentMetricList{
+		Metrics: []*protocol.AgentMetric{
+			{AgentId: "0xbot", Name: metrics.MetricActionRestart, Value: 1},
+			{AgentId: "0xbot", Name: metrics.MetricActionRestart, Value: 1},
+			{AgentId: "0xbot", Name: metrics.MetricJSONRPCRequest, Value: 10},
+			{AgentId: "0xbot", Name: metrics.MetricJSONRPCThrottled, Value: 2},
+		},
+	}))
+	lastSynced = time.Now().Add(-time.Hour)
+	e.Evaluate()
+
+	// the disk space rule is disabled
+	r.Len(tn.notifications, 3)
+	for i, rule := range []string{RuleBotCrashLoop, RuleProxyErrorRate, RuleRegistrySyncStale} {
+		r.Equal(rule, tn.notifications[i].Rule)
+		r.True(tn.notifications[i].Firing)
+	}
+	r.Contains(tn.notifications[0].Message, "0xbot (2 restarts)")
+	r.Len(e.Firing(), 3)
+
+	// not notified again until the repeat interval passes
+	e.Evaluate()
+	r.Len(tn.notifications, 4)
+	r.Equal(RuleProxyErrorRate, tn.notifications[3].Rule)
+	r.False(tn.notifications[3].Firing)
+	r.Len(e.Firing(), 2)
+
+	e.firing[RuleBotCrashLoop].lastNotified = time.Now().Add(-time.Hour * 2)
+	lastSynced = time.Now()
+	e.Evaluate()
+	r.Len(tn.notifications, 6)
+	r.Equal(RuleBotCrashLoop, tn.notifications[4].Rule)
+	r.True(tn.notifications[4].Firing)
+	r.Equal(RuleRegistrySyncStale, tn.notifications[5].Rule)
+	r.False(tn.notifications[5].Firing)
+}
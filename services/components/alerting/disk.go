@@ -0,0 +1,12 @@
+package alerting
+
+import "syscall"
+
+// DiskFree returns the available space of the file system which contains the path.
+func DiskFree(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
@@ -2,10 +2,12 @@ package botio
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -20,8 +22,12 @@ import (
 	"github.com/forta-network/forta-node/nodeutils"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/tracing"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/status"
 )
 
@@ -34,10 +40,15 @@ type BotClient interface {
 	IsInitialized() bool
 	Closed() <-chan struct{}
 	IsClosed() bool
+	ConnectionDown() bool
 
 	TxBufferIsFull() bool
+	Backlog() time.Duration
+	Pending() int
+	ShedRequests(keep time.Duration) (txs, blocks int)
 
 	Initialize()
+	Reinitialize()
 	StartProcessing()
 
 	ShouldProcessBlock(blockNumberHex string) bool
@@ -60,6 +71,8 @@ const (
 	RequestTimeout           = 30 * time.Second
 	MaxFindings              = 50
 	DefaultInitializeTimeout = 5 * time.Minute
+	HealthCheckInterval      = time.Minute
+	HealthCheckTimeout       = 10 * time.Second
 )
 
 // botClient receives blocks and transactions, and produces results.
@@ -74,6 +87,9 @@ type botClient struct {
 	combinationRequests chan *botreq.CombinationRequest // never closed - deallocated when bot is discarded
 
 	resultChannels botreq.SendOnlyChannels
+	limits         requestLimits
+	txLatency      durationAverage
+	blockLatency   durationAverage
 
 	errCounter       *nodeutils.ErrorCounter
 	msgClient        clients.MessageClient
@@ -81,9 +97,12 @@ type botClient struct {
 
 	dialer       agentgrpc.BotDialer
 	clientUnsafe agentgrpc.Client
+	connDown     int32 // set while the connection to the bot is broken
+	inFlight     int32 // the requests which are being evaluated
 
 	initialized     chan struct{}
 	initializedOnce sync.Once
+	healthCheckOnce sync.Once
 
 	closeOnce sync.Once
 
@@ -122,19 +141,21 @@ func MakeCombinerBotSubscriptions(
 
 // NewBotClient creates a new bot client.
 func NewBotClient(
-	ctx context.Context, botCfg config.AgentConfig,
+	ctx context.Context, botCfg config.AgentConfig, grpcCfg config.AgentGrpcConfig,
 	msgClient clients.MessageClient, lifecycleMetrics metrics.Lifecycle, botDialer agentgrpc.BotDialer,
 	resultChannels botreq.SendOnlyChannels,
 ) *botClient {
 	botCtx, botCtxCancel := context.WithCancel(ctx)
+	limits := resolveRequestLimits(grpcCfg, botCfg)
 	return &botClient{
 		ctx:                 botCtx,
 		ctxCancel:           botCtxCancel,
 		configUnsafe:        botCfg,
-		txRequests:          make(chan *botreq.TxRequest, DefaultBufferSize),
-		blockRequests:       make(chan *botreq.BlockRequest, DefaultBufferSize),
-		combinationRequests: make(chan *botreq.CombinationRequest, DefaultBufferSize),
+		txRequests:          make(chan *botreq.TxRequest, limits.queueDepth),
+		blockRequests:       make(chan *botreq.BlockRequest, limits.queueDepth),
+		combinationRequests: make(chan *botreq.CombinationRequest, limits.queueDepth),
 		resultChannels:      resultChannels,
+		limits:              limits,
 		errCounter:          nodeutils.NewErrorCounter(3, isCriticalErr),
 		msgClient:           msgClient,
 		lifecycleMetrics:    lifecycleMetrics,
@@ -158,12 +179,57 @@ func (bot *botClient) LogStatus() {
 		"txBuffer":    len(bot.txRequests),
 		"initialized": bot.IsInitialized(),
 		"closed":      bot.IsClosed(),
+		"connDown":    bot.ConnectionDown(),
 	}).Debug("bot status")
 }
 
 // TxBufferIsFull tells if an bot input buffer is full.
 func (bot *botClient) TxBufferIsFull() bool {
-	return len(bot.txRequests) == DefaultBufferSize
+	return len(bot.txRequests) == cap(bot.txRequests)
+}
+
+// Backlog estimates how long it takes the bot to evaluate the queued requests.
+func (bot *botClient) Backlog() time.Duration {
+	return bot.backlog(len(bot.txRequests), len(bot.blockRequests))
+}
+
+// Pending returns how many requests are queued or being evaluated by the bot.
+func (bot *botClient) Pending() int {
+	if bot.IsClosed() {
+		return 0
+	}
+	return len(bot.txRequests) + len(bot.blockRequests) + len(bot.combinationRequests) +
+		int(atomic.LoadInt32(&bot.inFlight))
+}
+
+func (bot *botClient) backlog(txs, blocks int) time.Duration {
+	work := time.Duration(txs)*bot.txLatency.Get() + time.Duration(blocks)*bot.blockLatency.Get()
+	return work / time.Duration(bot.limits.maxInFlight)
+}
+
+// ShedRequests drops the oldest queued requests until the backlog is not longer than the
+// given duration. The tx requests are dropped before the block requests.
+func (bot *botClient) ShedRequests(keep time.Duration) (txs, blocks int) {
+	queuedTxs, queuedBlocks := len(bot.txRequests), len(bot.blockRequests)
+	for bot.backlog(queuedTxs-txs, queuedBlocks) > keep && txs < queuedTxs {
+		select {
+		case request := <-bot.txRequests:
+			request.Encoded.Release()
+			txs++
+		default:
+			queuedTxs = txs
+		}
+	}
+	for bot.backlog(queuedTxs-txs, queuedBlocks-blocks) > keep && blocks < queuedBlocks {
+		select {
+		case request := <-bot.blockRequests:
+			request.Encoded.Release()
+			blocks++
+		default:
+			queuedBlocks = blocks
+		}
+	}
+	return
 }
 
 // SetConfig sets the bot config.
@@ -313,6 +379,29 @@ func (bot *botClient) initialize() {
 	bot.setGrpcClient(botClient)
 	bot.lifecycleMetrics.StatusAttached(botConfig)
 	logger.Info("attached to bot")
+	go bot.watchConnection(botClient)
+
+	bot.initializeBot(botClient)
+}
+
+// Reinitialize invokes the initialize method of the bot again without dialing a new connection.
+// The client reconnects to the bot by itself after the connection breaks.
+func (bot *botClient) Reinitialize() {
+	botClient := bot.grpcClient()
+	if botClient == nil {
+		bot.initialize()
+		return
+	}
+	log.WithField("bot", bot.Config().ID).Info("reinitializing bot")
+	bot.initializeBot(botClient)
+}
+
+func (bot *botClient) initializeBot(botClient agentgrpc.Client) {
+	botConfig := bot.Config()
+
+	logger := log.WithFields(log.Fields{
+		"bot": botConfig.ID,
+	})
 
 	ctx, cancel := context.WithTimeout(bot.ctx, DefaultInitializeTimeout)
 	defer cancel()
@@ -361,9 +450,75 @@ func (bot *botClient) initialize() {
 	logger.Info("bot initialization succeeded")
 }
 
+// watchConnection tracks the connection state to tell the broken connections apart from the
+// failing bots.
+func (bot *botClient) watchConnection(botClient agentgrpc.Client) {
+	botClient.WatchConnState(bot.ctx, func(state connectivity.State) {
+		botConfig := bot.Config()
+		logger := log.WithFields(log.Fields{
+			"bot":   botConfig.ID,
+			"state": state.String(),
+		})
+		switch state {
+		case connectivity.Ready:
+			if atomic.CompareAndSwapInt32(&bot.connDown, 1, 0) {
+				logger.Info("connection to bot is recovered")
+				bot.lifecycleMetrics.ConnectionReady(botConfig)
+			}
+		case connectivity.Shutdown:
+			return
+		default:
+			if atomic.CompareAndSwapInt32(&bot.connDown, 0, 1) {
+				logger.Warn("connection to bot is down")
+				bot.lifecycleMetrics.ConnectionDown(botConfig)
+			}
+		}
+	})
+}
+
+// checkHealth checks the bots which serve the gRPC health checks periodically so that the idle
+// bots are not mistaken for the inactive ones.
+func (bot *botClient) checkHealth() {
+	ticker := time.NewTicker(HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-bot.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		botClient := bot.grpcClient()
+		if botClient == nil || bot.ConnectionDown() {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(bot.ctx, HealthCheckTimeout)
+		err := botClient.CheckHealth(ctx)
+		cancel()
+		botID := bot.Config().ID
+		if err != nil {
+			log.WithError(err).WithField("bot", botID).Warn("bot health check failed")
+			bot.publishInvokeError(BotErrorHealthCheck, err)
+			continue
+		}
+		metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(botID, metrics.MetricHealthServing, 1),
+		})
+	}
+}
+
+// ConnectionDown tells if the connection to the bot is broken and the client is reconnecting.
+func (bot *botClient) ConnectionDown() bool {
+	return atomic.LoadInt32(&bot.connDown) == 1
+}
+
 func (bot *botClient) initSuccess(botConfig config.AgentConfig) {
 	bot.setInitialized()
 	bot.lifecycleMetrics.StatusInitialized(botConfig)
+	if botConfig.SupportsHealthChecks() {
+		bot.healthCheckOnce.Do(func() {
+			go bot.checkHealth()
+		})
+	}
 }
 
 func validateInitializeResponse(response *protocol.InitializeResponse) error {
@@ -392,8 +547,8 @@ func (bot *botClient) StartProcessing() {
 }
 
 func processRequests[R any](
-	ctx context.Context, reqCh <-chan *R, closedCh <-chan struct{}, logger *log.Entry,
-	processFunc func(context.Context, *log.Entry, *R) bool,
+	ctx context.Context, reqCh <-chan *R, closedCh <-chan struct{}, timeout time.Duration, logger *log.Entry,
+	inFlight *int32, processFunc func(context.Context, *log.Entry, *R) bool,
 ) {
 	for {
 		select {
@@ -402,9 +557,11 @@ func processRequests[R any](
 			return
 
 		case request := <-reqCh:
-			ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+			atomic.AddInt32(inFlight, 1)
+			ctx, cancel := context.WithTimeout(ctx, timeout)
 			exit := processFunc(ctx, logger, request)
 			cancel()
+			atomic.AddInt32(inFlight, -1)
 			if exit {
 				return
 			}
@@ -423,7 +580,10 @@ func (bot *botClient) processTransactions() {
 
 	<-bot.Initialized()
 
-	processRequests(bot.ctx, bot.txRequests, bot.Closed(), lg, bot.processTransaction)
+	for i := 1; i < bot.limits.maxInFlight; i++ {
+		go processRequests(bot.ctx, bot.txRequests, bot.Closed(), bot.limits.timeout, lg, &bot.inFlight, bot.processTransaction)
+	}
+	processRequests(bot.ctx, bot.txRequests, bot.Closed(), bot.limits.timeout, lg, &bot.inFlight, bot.processTransaction)
 }
 func (bot *botClient) processBlocks() {
 	lg := log.WithFields(
@@ -436,7 +596,10 @@ func (bot *botClient) processBlocks() {
 
 	<-bot.Initialized()
 
-	processRequests(bot.ctx, bot.blockRequests, bot.Closed(), lg, bot.processBlock)
+	for i := 1; i < bot.limits.maxInFlight; i++ {
+		go processRequests(bot.ctx, bot.blockRequests, bot.Closed(), bot.limits.timeout, lg, &bot.inFlight, bot.processBlock)
+	}
+	processRequests(bot.ctx, bot.blockRequests, bot.Closed(), bot.limits.timeout, lg, &bot.inFlight, bot.processBlock)
 }
 
 func (bot *botClient) processCombinationAlerts() {
@@ -450,7 +613,10 @@ func (bot *botClient) processCombinationAlerts() {
 
 	<-bot.Initialized()
 
-	processRequests(bot.ctx, bot.combinationRequests, bot.Closed(), lg, bot.processCombinationAlert)
+	for i := 1; i < bot.limits.maxInFlight; i++ {
+		go processRequests(bot.ctx, bot.combinationRequests, bot.Closed(), bot.limits.timeout, lg, &bot.inFlight, bot.processCombinationAlert)
+	}
+	processRequests(bot.ctx, bot.combinationRequests, bot.Closed(), bot.limits.timeout, lg, &bot.inFlight, bot.processCombinationAlert)
 }
 
 func (bot *botClient) processTransaction(ctx context.Context, lg *log.Entry, request *botreq.TxRequest) (exit bool) {
@@ -461,26 +627,34 @@ func (bot *botClient) processTransaction(ctx context.Context, lg *log.Entry, req
 		return true
 	}
 
+	if bot.lagsBehind(request.Original.Event.Block.BlockNumber, request.LatestBlock) {
+		request.Encoded.Release()
+		bot.publishLag(metrics.MetricTxLag)
+		return false
+	}
+
 	startTime := time.Now()
 
 	lg.WithField("duration", time.Since(startTime)).Debugf("sending request")
 	resp := new(protocol.EvaluateTxResponse)
 
+	ctx, span := bot.startEvaluateSpan(ctx, request.SpanContext, agentgrpc.MethodEvaluateTx)
+	defer span.End()
+
 	requestTime := time.Now().UTC()
-	err := botClient.Invoke(ctx, agentgrpc.MethodEvaluateTx, request.Original, resp)
+	invokeCtx, payload := withPayloadRef(ctx, botConfig, botClient, request.PayloadRef, request.Payload())
+	err := botClient.Invoke(invokeCtx, agentgrpc.MethodEvaluateTx, payload, resp)
+	if err == nil {
+		// the failed requests may still be referenced by the transport
+		request.Encoded.Release()
+	}
+	tracing.SetError(span, err)
 	responseTime := time.Now().UTC()
+	bot.txLatency.Record(responseTime.Sub(requestTime))
 
 	if err == nil {
-		// truncate findings
-		if len(resp.Findings) > MaxFindings {
-			dropped := len(resp.Findings) - MaxFindings
-			droppedMetric := metrics.CreateAgentMetric(botConfig.ID, metrics.MetricFindingsDropped, float64(dropped))
-			bot.msgClient.PublishProto(
-				messaging.SubjectMetricAgent,
-				&protocol.AgentMetricList{Metrics: []*protocol.AgentMetric{droppedMetric}},
-			)
-			resp.Findings = resp.Findings[:MaxFindings]
-		}
+		bot.publishResponseError(BotErrorEvaluateTxResponse, resp.Status, resp.Errors)
+		resp.Findings = bot.sanitizeFindings(ctx, lg, resp.Findings)
 		var duration time.Duration
 		resp.Timestamp, resp.LatencyMs, duration = calculateResponseTime(&startTime)
 		lg.WithField("duration", duration).Debugf("request successful")
@@ -495,10 +669,12 @@ func (bot *botClient) processTransaction(ctx context.Context, lg *log.Entry, req
 		ts.BotResponse = responseTime
 
 		bot.resultChannels.Tx <- &botreq.TxResult{
-			AgentConfig: botConfig,
-			Request:     request.Original,
-			Response:    resp,
-			Timestamps:  ts,
+			AgentConfig:  botConfig,
+			Request:      request.Original,
+			Response:     resp,
+			Timestamps:   ts,
+			SpanContext:  span.SpanContext(),
+			EvaluationID: botreq.TxEvaluationID(botConfig, request.Original),
 		}
 		lg.WithField("duration", time.Since(startTime)).Debugf("sent results")
 
@@ -508,6 +684,8 @@ func (bot *botClient) processTransaction(ctx context.Context, lg *log.Entry, req
 	if status.Code(err) == codes.Unimplemented {
 		return false
 	}
+	bot.publishTimeout(err, metrics.MetricTxTimeout)
+	bot.publishInvokeError(BotErrorEvaluateTx, err)
 
 	lg.WithField("duration", time.Since(startTime)).WithError(err).Error("error invoking bot")
 	if bot.errCounter.TooManyErrs(err) {
@@ -528,27 +706,33 @@ func (bot *botClient) processBlock(ctx context.Context, lg *log.Entry, request *
 		return true
 	}
 
+	if bot.lagsBehind(request.Original.Event.BlockNumber, request.LatestBlock) {
+		request.Encoded.Release()
+		bot.publishLag(metrics.MetricBlockLag)
+		return false
+	}
+
 	startTime := time.Now()
 
 	lg.WithField("duration", time.Since(startTime)).Debugf("sending request")
 	resp := new(protocol.EvaluateBlockResponse)
+	ctx, span := bot.startEvaluateSpan(ctx, request.SpanContext, agentgrpc.MethodEvaluateBlock)
+	defer span.End()
+
 	requestTime := time.Now().UTC()
-	err := botClient.Invoke(ctx, agentgrpc.MethodEvaluateBlock, request.Original, resp)
+	invokeCtx, payload := withPayloadRef(ctx, botConfig, botClient, request.PayloadRef, request.Payload())
+	err := botClient.Invoke(invokeCtx, agentgrpc.MethodEvaluateBlock, payload, resp)
+	if err == nil {
+		// the failed requests may still be referenced by the transport
+		request.Encoded.Release()
+	}
+	tracing.SetError(span, err)
 	responseTime := time.Now().UTC()
+	bot.blockLatency.Record(responseTime.Sub(requestTime))
 
 	if err == nil {
-		// truncate findings
-		if len(resp.Findings) > MaxFindings {
-			dropped := len(resp.Findings) - MaxFindings
-			droppedMetric := metrics.CreateAgentMetric(
-				botConfig.ID, metrics.MetricFindingsDropped, float64(dropped),
-			)
-			bot.msgClient.PublishProto(
-				messaging.SubjectMetricAgent,
-				&protocol.AgentMetricList{Metrics: []*protocol.AgentMetric{droppedMetric}},
-			)
-			resp.Findings = resp.Findings[:MaxFindings]
-		}
+		bot.publishResponseError(BotErrorEvaluateBlockResponse, resp.Status, resp.Errors)
+		resp.Findings = bot.sanitizeFindings(ctx, lg, resp.Findings)
 		var duration time.Duration
 		resp.Timestamp, resp.LatencyMs, duration = calculateResponseTime(&startTime)
 		lg.WithField("duration", duration).Debugf("request successful")
@@ -563,10 +747,12 @@ func (bot *botClient) processBlock(ctx context.Context, lg *log.Entry, request *
 		ts.BotResponse = responseTime
 
 		bot.resultChannels.Block <- &botreq.BlockResult{
-			AgentConfig: botConfig,
-			Request:     request.Original,
-			Response:    resp,
-			Timestamps:  ts,
+			AgentConfig:  botConfig,
+			Request:      request.Original,
+			Response:     resp,
+			Timestamps:   ts,
+			SpanContext:  span.SpanContext(),
+			EvaluationID: botreq.BlockEvaluationID(botConfig, request.Original),
 		}
 		lg.WithField("duration", time.Since(startTime)).Debugf("sent results")
 
@@ -576,6 +762,8 @@ func (bot *botClient) processBlock(ctx context.Context, lg *log.Entry, request *
 	if status.Code(err) == codes.Unimplemented {
 		return false
 	}
+	bot.publishTimeout(err, metrics.MetricBlockTimeout)
+	bot.publishInvokeError(BotErrorEvaluateBlock, err)
 
 	lg.WithField("duration", time.Since(startTime)).WithError(err).Error("error invoking bot")
 	if bot.errCounter.TooManyErrs(err) {
@@ -588,6 +776,18 @@ func (bot *botClient) processBlock(ctx context.Context, lg *log.Entry, request *
 	return false
 }
 
+// withPayloadRef replaces the request with the payload reference if the bot declares that it supports
+// the references and accepted them on initialization.
+func withPayloadRef(
+	ctx context.Context, botConfig config.AgentConfig, botClient agentgrpc.Client, payloadRef *agentgrpc.PayloadRef,
+	payload interface{},
+) (context.Context, interface{}) {
+	if payloadRef == nil || !botConfig.SupportsPayloadRefs() || !botClient.SupportsPayloadRefs() {
+		return ctx, payload
+	}
+	return agentgrpc.WithPayloadRef(ctx, payloadRef), payloadRef.Request
+}
+
 func (bot *botClient) processCombinationAlert(ctx context.Context, lg *log.Entry, request *botreq.CombinationRequest) bool {
 	botConfig := bot.Config()
 	botClient := bot.grpcClient()
@@ -600,14 +800,20 @@ func (bot *botClient) processCombinationAlert(ctx context.Context, lg *log.Entry
 
 	lg.WithField("duration", time.Since(startTime)).Debugf("sending request")
 	resp := new(protocol.EvaluateAlertResponse)
+	ctx, span := bot.startEvaluateSpan(ctx, request.SpanContext, agentgrpc.MethodEvaluateAlert)
+	defer span.End()
+
 	requestTime := time.Now().UTC()
 	err := botClient.Invoke(ctx, agentgrpc.MethodEvaluateAlert, request.Original, resp)
+	tracing.SetError(span, err)
 	responseTime := time.Now().UTC()
 
 	if err != nil {
 		if status.Code(err) != codes.Unimplemented {
 			lg.WithField("duration", time.Since(startTime)).WithError(err).Error("error invoking bot")
+			bot.publishInvokeError(BotErrorEvaluateAlert, err)
 		}
+		bot.publishTimeout(err, metrics.MetricCombinerTimeout)
 		if bot.errCounter.TooManyErrs(err) {
 			lg.WithField("duration", time.Since(startTime)).Error("too many errors - shutting down bot")
 			_ = bot.Close()
@@ -626,15 +832,8 @@ func (bot *botClient) processCombinationAlert(ctx context.Context, lg *log.Entry
 		return false
 	}
 
-	// truncate findings
-	if len(resp.Findings) > MaxFindings {
-		dropped := len(resp.Findings) - MaxFindings
-		droppedMetric := metrics.CreateAgentMetric(botConfig.ID, metrics.MetricFindingsDropped, float64(dropped))
-		bot.msgClient.PublishProto(
-			messaging.SubjectMetricAgent, &protocol.AgentMetricList{Metrics: []*protocol.AgentMetric{droppedMetric}},
-		)
-		resp.Findings = resp.Findings[:MaxFindings]
-	}
+	bot.publishResponseError(BotErrorEvaluateAlertResponse, resp.Status, resp.Errors)
+	resp.Findings = bot.sanitizeFindings(ctx, lg, resp.Findings)
 
 	var duration time.Duration
 	resp.Timestamp, resp.LatencyMs, duration = calculateResponseTime(&startTime)
@@ -651,16 +850,83 @@ func (bot *botClient) processCombinationAlert(ctx context.Context, lg *log.Entry
 	ts.BotResponse = responseTime
 
 	bot.resultChannels.CombinationAlert <- &botreq.CombinationAlertResult{
-		AgentConfig: botConfig,
-		Request:     request.Original,
-		Response:    resp,
-		Timestamps:  ts,
+		AgentConfig:  botConfig,
+		Request:      request.Original,
+		Response:     resp,
+		Timestamps:   ts,
+		SpanContext:  span.SpanContext(),
+		EvaluationID: botreq.CombinationEvaluationID(botConfig, request.Original),
 	}
 
 	lg.WithField("duration", time.Since(startTime)).Debugf("sent results")
 	return false
 }
 
+// startEvaluateSpan starts the span of a bot request and propagates the trace context
+// to the bot if the bot opted in.
+func (bot *botClient) startEvaluateSpan(ctx context.Context, parent trace.SpanContext, method agentgrpc.Method) (context.Context, trace.Span) {
+	botConfig := bot.Config()
+	ctx, span := tracing.Tracer().Start(
+		trace.ContextWithSpanContext(ctx, parent), tracing.SpanBotEvaluate,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("bot.id", botConfig.ID),
+			attribute.String("bot.image", botConfig.Image),
+			attribute.String("method", string(method)),
+		),
+	)
+	if botConfig.TraceContext {
+		ctx = tracing.InjectGRPC(ctx)
+	}
+	return ctx, span
+}
+
+// sanitizeFindings sanitizes the findings of the bot before publishing and reports the changes.
+func (bot *botClient) sanitizeFindings(ctx context.Context, lg *log.Entry, findings []*protocol.Finding) []*protocol.Finding {
+	_, span := tracing.Tracer().Start(ctx, tracing.SpanFindingValidation)
+	defer span.End()
+
+	sanitized, result := sanitizeFindings(findings)
+	span.SetAttributes(attribute.Int("findings", len(findings)), attribute.Int("rejected", result.Rejected))
+	if result.Rejected > 0 {
+		lg.WithField("rejected", result.Rejected).Warn("rejected malformed findings")
+	}
+	metrics.SendAgentMetrics(bot.msgClient, result.Metrics(bot.Config().ID))
+	return sanitized
+}
+
+// publishTimeout publishes the timeout metric if the bot did not respond in time.
+func (bot *botClient) publishTimeout(err error, metricName string) {
+	if !errors.Is(err, context.DeadlineExceeded) && status.Code(err) != codes.DeadlineExceeded {
+		return
+	}
+	metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+		metrics.CreateAgentMetric(bot.Config().ID, metricName, 1),
+	})
+}
+
+// lagsBehind tells if the block of the request is older than the block lag which the bot tolerates.
+func (bot *botClient) lagsBehind(blockNumberHex string, latestBlock func() uint64) bool {
+	botConfig := bot.Config()
+	maxLag := botConfig.MaxBlockLag()
+	if maxLag == 0 || latestBlock == nil {
+		return false
+	}
+	blockNumber, err := hexutil.DecodeUint64(blockNumberHex)
+	if err != nil {
+		return false
+	}
+	latest := latestBlock()
+	return latest > blockNumber && latest-blockNumber > maxLag
+}
+
+// publishLag publishes the metric of a request which was dropped because of the block lag.
+func (bot *botClient) publishLag(metricName string) {
+	metrics.SendAgentMetrics(bot.msgClient, []*protocol.AgentMetric{
+		metrics.CreateAgentMetric(bot.Config().ID, metricName, 1),
+	})
+}
+
 func validateEvaluateAlertResponse(resp *protocol.EvaluateAlertResponse) (err error) {
 	if resp == nil {
 		return fmt.Errorf("nil response")
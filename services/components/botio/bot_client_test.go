@@ -19,6 +19,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/connectivity"
 )
 
 const (
@@ -62,6 +63,7 @@ func (s *BotClientSuite) SetupTest() {
 	s.resultChannels = botreq.MakeResultChannels()
 
 	s.botDialer.EXPECT().DialBot(gomock.Any()).Return(s.botGrpc, nil).AnyTimes()
+	s.botGrpc.EXPECT().WatchConnState(gomock.Any(), gomock.Any()).AnyTimes()
 
 	s.alertConfig = &protocol.AlertConfig{
 		Subscriptions: []*protocol.CombinerBotSubscription{
@@ -73,7 +75,7 @@ func (s *BotClientSuite) SetupTest() {
 
 	s.botClient = NewBotClient(context.Background(), config.AgentConfig{
 		ID: testBotID,
-	}, s.msgClient, s.lifecycleMetrics, s.botDialer, s.resultChannels.SendOnly())
+	}, config.AgentGrpcConfig{}, s.msgClient, s.lifecycleMetrics, s.botDialer, s.resultChannels.SendOnly())
 }
 
 // TestStartProcessStop tests the starting, processing and stopping flow for a bot.
@@ -204,6 +206,51 @@ func (s *BotClientSuite) TestInitialize_Success() {
 	s.botClient.Initialize()
 }
 
+func (s *BotClientSuite) TestReinitialize() {
+	s.lifecycleMetrics.EXPECT().ClientDial(s.botClient.configUnsafe)
+	s.lifecycleMetrics.EXPECT().StatusAttached(s.botClient.configUnsafe)
+	s.botGrpc.EXPECT().Initialize(gomock.Any(), gomock.Any()).Return(&protocol.InitializeResponse{
+		Status: protocol.ResponseStatus_SUCCESS,
+	}, nil).Times(2)
+	s.lifecycleMetrics.EXPECT().StatusInitialized(s.botClient.configUnsafe).Times(2)
+
+	s.botClient.Initialize()
+	// the bot is initialized again by using the same client
+	s.botClient.Reinitialize()
+}
+
+func (s *BotClientSuite) TestWatchConnection() {
+	ctrl := gomock.NewController(s.T())
+	botGrpc := mock_agentgrpc.NewMockClient(ctrl)
+	botGrpc.EXPECT().WatchConnState(gomock.Any(), gomock.Any()).Do(
+		func(ctx context.Context, handler func(connectivity.State)) {
+			handler(connectivity.Connecting)
+			handler(connectivity.TransientFailure)
+			s.r.True(s.botClient.ConnectionDown())
+			handler(connectivity.Ready)
+		},
+	)
+	s.lifecycleMetrics.EXPECT().ConnectionDown(s.botClient.configUnsafe).Times(1)
+	s.lifecycleMetrics.EXPECT().ConnectionReady(s.botClient.configUnsafe).Times(1)
+
+	s.botClient.watchConnection(botGrpc)
+	s.r.False(s.botClient.ConnectionDown())
+}
+
+func (s *BotClientSuite) TestLagsBehind() {
+	latestBlock := func() uint64 { return 16 }
+	s.r.False(s.botClient.lagsBehind("0xa", latestBlock))
+
+	s.botClient.SetConfig(config.AgentConfig{
+		ID:           testBotID,
+		Capabilities: &config.BotCapabilities{MaxBlockLag: 5},
+	})
+	s.r.True(s.botClient.lagsBehind("0xa", latestBlock))
+	s.r.False(s.botClient.lagsBehind("0xb", latestBlock))
+	s.r.False(s.botClient.lagsBehind("0x11", latestBlock))
+	s.r.False(s.botClient.lagsBehind("0xa", nil))
+}
+
 func (s *BotClientSuite) TestInitialize_Error() {
 	s.lifecycleMetrics.EXPECT().ClientDial(s.botClient.configUnsafe)
 	s.lifecycleMetrics.EXPECT().StatusAttached(s.botClient.configUnsafe)
@@ -0,0 +1,62 @@
+package botio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"google.golang.org/grpc/status"
+)
+
+// MaxBotErrorDetailsLength limits the error details attached to the bot error metrics.
+const MaxBotErrorDetailsLength = 1024
+
+// Bot evaluation error metrics
+const (
+	BotErrorEvaluateTx            = "evaluate.tx"
+	BotErrorEvaluateTxResponse    = "evaluate.tx.response"
+	BotErrorEvaluateBlock         = "evaluate.block"
+	BotErrorEvaluateBlockResponse = "evaluate.block.response"
+	BotErrorEvaluateAlert         = "evaluate.alert"
+	BotErrorEvaluateAlertResponse = "evaluate.alert.response"
+	BotErrorHealthCheck           = "health.check"
+)
+
+// invokeErrorDetails describes the gRPC error together with the status details.
+func invokeErrorDetails(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return truncateString(err.Error(), MaxBotErrorDetailsLength)
+	}
+	details := fmt.Sprintf("code=%s message=%s", st.Code(), st.Message())
+	for _, detail := range st.Details() {
+		details += fmt.Sprintf(" detail=%v", detail)
+	}
+	return truncateString(details, MaxBotErrorDetailsLength)
+}
+
+// responseErrorDetails joins the errors which the bot returned in the response.
+func responseErrorDetails(errs []*protocol.Error) string {
+	var messages []string
+	for _, err := range errs {
+		if err != nil && len(err.Message) > 0 {
+			messages = append(messages, err.Message)
+		}
+	}
+	if len(messages) == 0 {
+		return "bot responded with error status"
+	}
+	return truncateString(strings.Join(messages, "; "), MaxBotErrorDetailsLength)
+}
+
+func (bot *botClient) publishInvokeError(metricName string, err error) {
+	bot.lifecycleMetrics.BotError(metricName, errors.New(invokeErrorDetails(err)), bot.Config().ID)
+}
+
+func (bot *botClient) publishResponseError(metricName string, respStatus protocol.ResponseStatus, errs []*protocol.Error) {
+	if respStatus != protocol.ResponseStatus_ERROR {
+		return
+	}
+	bot.lifecycleMetrics.BotError(metricName, errors.New(responseErrorDetails(errs)), bot.Config().ID)
+}
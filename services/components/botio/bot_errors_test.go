@@ -0,0 +1,36 @@
+package botio
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInvokeErrorDetails(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("some error", invokeErrorDetails(errors.New("some error")))
+
+	st, err := status.New(codes.Internal, "panic").WithDetails(&protocol.Error{Message: "stack trace"})
+	r.NoError(err)
+	details := invokeErrorDetails(st.Err())
+	r.Contains(details, "code=Internal message=panic")
+	r.Contains(details, "stack trace")
+
+	details = invokeErrorDetails(status.Error(codes.Unknown, strings.Repeat("a", MaxBotErrorDetailsLength)))
+	r.Len(details, MaxBotErrorDetailsLength)
+}
+
+func TestResponseErrorDetails(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("bot responded with error status", responseErrorDetails(nil))
+	r.Equal("error 1; error 2", responseErrorDetails([]*protocol.Error{
+		{Message: "error 1"}, nil, {Message: "error 2"},
+	}))
+}
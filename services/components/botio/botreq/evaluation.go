@@ -0,0 +1,68 @@
+package botreq
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+)
+
+// EvaluationID returns the deterministic ID of the evaluation of an input by a bot shard. The
+// retries and the re-evaluations after the restarts have the same ID so that the findings of
+// an evaluation are published once. The input tells apart the evaluations of the same block.
+func EvaluationID(chainID, blockHash, botID string, shardID uint, input string) string {
+	s := fmt.Sprintf("%s|%s|%s|%d|%s", chainID, blockHash, botID, shardID, input)
+	return crypto.Keccak256Hash([]byte(s)).Hex()
+}
+
+// TxEvaluationID returns the evaluation ID of the tx request.
+func TxEvaluationID(botConfig config.AgentConfig, req *protocol.EvaluateTxRequest) string {
+	evt := req.Event
+	if evt == nil || evt.Transaction == nil {
+		return ""
+	}
+	var chainID, blockHash string
+	if evt.Network != nil {
+		chainID = evt.Network.ChainId
+	}
+	if evt.Block != nil {
+		blockHash = evt.Block.BlockHash
+	}
+	return EvaluationID(chainID, blockHash, botConfig.ID, shardID(botConfig), evt.Type.String()+":"+evt.Transaction.Hash)
+}
+
+// BlockEvaluationID returns the evaluation ID of the block request.
+func BlockEvaluationID(botConfig config.AgentConfig, req *protocol.EvaluateBlockRequest) string {
+	evt := req.Event
+	if evt == nil {
+		return ""
+	}
+	var chainID string
+	if evt.Network != nil {
+		chainID = evt.Network.ChainId
+	}
+	return EvaluationID(chainID, evt.BlockHash, botConfig.ID, shardID(botConfig), evt.Type.String())
+}
+
+// CombinationEvaluationID returns the evaluation ID of the alert request.
+func CombinationEvaluationID(botConfig config.AgentConfig, req *protocol.EvaluateAlertRequest) string {
+	if req.Event == nil || req.Event.Alert == nil {
+		return ""
+	}
+	alert := req.Event.Alert
+	var chainID, blockHash string
+	if alert.Source != nil && alert.Source.Block != nil {
+		chainID = strconv.FormatUint(alert.Source.Block.ChainId, 10)
+		blockHash = alert.Source.Block.Hash
+	}
+	return EvaluationID(chainID, blockHash, botConfig.ID, shardID(botConfig), "alert:"+alert.Hash)
+}
+
+func shardID(botConfig config.AgentConfig) uint {
+	if botConfig.ShardConfig == nil {
+		return 0
+	}
+	return botConfig.ShardConfig.ShardID
+}
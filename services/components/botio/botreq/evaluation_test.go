@@ -0,0 +1,50 @@
+package botreq
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxEvaluationID(t *testing.T) {
+	r := require.New(t)
+
+	req := func(txHash string) *protocol.EvaluateTxRequest {
+		return &protocol.EvaluateTxRequest{
+			RequestId: "1",
+			Event: &protocol.TransactionEvent{
+				Network:     &protocol.TransactionEvent_Network{ChainId: "1"},
+				Block:       &protocol.TransactionEvent_EthBlock{BlockHash: "0xblock"},
+				Transaction: &protocol.TransactionEvent_EthTransaction{Hash: txHash},
+			},
+		}
+	}
+	botConfig := config.AgentConfig{ID: "0xbot"}
+	shardConfig := config.AgentConfig{ID: "0xbot", ShardConfig: &config.ShardConfig{ShardID: 1}}
+
+	id := TxEvaluationID(botConfig, req("0x1"))
+	r.NotEmpty(id)
+	// the request ID does not matter
+	retried := req("0x1")
+	retried.RequestId = "2"
+	r.Equal(id, TxEvaluationID(botConfig, retried))
+	r.NotEqual(id, TxEvaluationID(botConfig, req("0x2")))
+	r.NotEqual(id, TxEvaluationID(shardConfig, req("0x1")))
+	r.Empty(TxEvaluationID(botConfig, &protocol.EvaluateTxRequest{}))
+}
+
+func TestBlockEvaluationID(t *testing.T) {
+	r := require.New(t)
+
+	req := &protocol.EvaluateBlockRequest{
+		Event: &protocol.BlockEvent{
+			Network:   &protocol.BlockEvent_Network{ChainId: "1"},
+			BlockHash: "0xblock",
+		},
+	}
+	id := BlockEvaluationID(config.AgentConfig{ID: "0xbot"}, req)
+	r.Equal(EvaluationID("1", "0xblock", "0xbot", 0, protocol.BlockEvent_BLOCK.String()), id)
+	r.NotEqual(id, BlockEvaluationID(config.AgentConfig{ID: "0xotherbot"}, req))
+}
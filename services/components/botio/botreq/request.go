@@ -2,19 +2,54 @@ package botreq
 
 import (
 	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TxRequest contains the request data.
 type TxRequest struct {
-	Original *protocol.EvaluateTxRequest
+	Original    *protocol.EvaluateTxRequest
+	SpanContext trace.SpanContext
+	// Encoded is the original request which is marshaled once for all bots. The bot
+	// releases it after the request is sent successfully.
+	Encoded *agentgrpc.EncodedMessage
+	// PayloadRef refers to the stored request if it is large. It is nil if the request is not stored.
+	PayloadRef *agentgrpc.PayloadRef
+	// LatestBlock returns the latest block seen by the sender when the request is processed.
+	LatestBlock func() uint64
+}
+
+// Payload returns the request to send to the bot.
+func (req *TxRequest) Payload() interface{} {
+	if req.Encoded != nil {
+		return req.Encoded
+	}
+	return req.Original
 }
 
 // BlockRequest contains the request data.
 type BlockRequest struct {
-	Original *protocol.EvaluateBlockRequest
+	Original    *protocol.EvaluateBlockRequest
+	SpanContext trace.SpanContext
+	// Encoded is the original request which is marshaled once for all bots. The bot
+	// releases it after the request is sent successfully.
+	Encoded *agentgrpc.EncodedMessage
+	// PayloadRef refers to the stored request if it is large. It is nil if the request is not stored.
+	PayloadRef *agentgrpc.PayloadRef
+	// LatestBlock returns the latest block seen by the sender when the request is processed.
+	LatestBlock func() uint64
+}
+
+// Payload returns the request to send to the bot.
+func (req *BlockRequest) Payload() interface{} {
+	if req.Encoded != nil {
+		return req.Encoded
+	}
+	return req.Original
 }
 
 // CombinationRequest contains the request data.
 type CombinationRequest struct {
-	Original *protocol.EvaluateAlertRequest
+	Original    *protocol.EvaluateAlertRequest
+	SpanContext trace.SpanContext
 }
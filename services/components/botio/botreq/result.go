@@ -4,6 +4,7 @@ import (
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/config"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TxResult contains request and response data.
@@ -12,6 +13,9 @@ type TxResult struct {
 	Request     *protocol.EvaluateTxRequest
 	Response    *protocol.EvaluateTxResponse
 	Timestamps  *domain.TrackingTimestamps
+	SpanContext trace.SpanContext
+	// EvaluationID is the deterministic ID of the evaluation by the bot.
+	EvaluationID string
 }
 
 // BlockResult contains request and response data.
@@ -20,6 +24,9 @@ type BlockResult struct {
 	Request     *protocol.EvaluateBlockRequest
 	Response    *protocol.EvaluateBlockResponse
 	Timestamps  *domain.TrackingTimestamps
+	SpanContext trace.SpanContext
+	// EvaluationID is the deterministic ID of the evaluation by the bot.
+	EvaluationID string
 }
 
 // CombinationAlertResult contains request and response data.
@@ -28,6 +35,9 @@ type CombinationAlertResult struct {
 	Request     *protocol.EvaluateAlertRequest
 	Response    *protocol.EvaluateAlertResponse
 	Timestamps  *domain.TrackingTimestamps
+	SpanContext trace.SpanContext
+	// EvaluationID is the deterministic ID of the evaluation by the bot.
+	EvaluationID string
 }
 
 // SendReceiveChannels has the bot result channels.
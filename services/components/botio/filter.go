@@ -0,0 +1,36 @@
+package botio
+
+import (
+	"strings"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+)
+
+// matchesTxFilter tells if the tx should be sent to the bot. The filter values are lowercase
+// after the manifest validation and so are the event addresses.
+func matchesTxFilter(filter *config.BotTxFilter, evt *protocol.TransactionEvent) bool {
+	if filter == nil || (len(filter.Addresses) == 0 && len(filter.Topics) == 0) {
+		return true
+	}
+	for _, address := range filter.Addresses {
+		if evt.Addresses[address] {
+			return true
+		}
+	}
+	if len(filter.Topics) == 0 {
+		return false
+	}
+	topics := make(map[string]bool, len(filter.Topics))
+	for _, topic := range filter.Topics {
+		topics[topic] = true
+	}
+	for _, txLog := range evt.Logs {
+		for _, topic := range txLog.Topics {
+			if topics[strings.ToLower(topic)] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package botio
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesTxFilter(t *testing.T) {
+	r := require.New(t)
+
+	evt := &protocol.TransactionEvent{
+		Addresses: map[string]bool{"0xabc": true},
+		Logs: []*protocol.TransactionEvent_Log{
+			{Topics: []string{"0xDDF2"}},
+		},
+	}
+
+	r.True(matchesTxFilter(nil, evt))
+	r.True(matchesTxFilter(&config.BotTxFilter{}, evt))
+	r.True(matchesTxFilter(&config.BotTxFilter{Addresses: []string{"0xabc"}}, evt))
+	r.True(matchesTxFilter(&config.BotTxFilter{Topics: []string{"0xddf2"}}, evt))
+	r.True(matchesTxFilter(&config.BotTxFilter{Addresses: []string{"0xdef"}, Topics: []string{"0xddf2"}}, evt))
+	r.False(matchesTxFilter(&config.BotTxFilter{Addresses: []string{"0xdef"}}, evt))
+	r.False(matchesTxFilter(&config.BotTxFilter{Topics: []string{"0x1234"}}, evt))
+}
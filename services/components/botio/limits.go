@@ -0,0 +1,83 @@
+package botio
+
+import (
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+// the largest manifest limits when the node config does not set them
+const (
+	defaultMaxRequestTimeoutSeconds = 300
+	defaultMaxInFlight              = 32
+	defaultMaxQueueDepth            = 10000
+)
+
+// requestLimits are the resolved request limits of a bot.
+type requestLimits struct {
+	timeout     time.Duration
+	maxInFlight int
+	queueDepth  int
+}
+
+func (rl *requestLimits) apply(limits *config.BotRequestLimits) {
+	if limits == nil {
+		return
+	}
+	if limits.TimeoutSeconds > 0 {
+		rl.timeout = time.Duration(limits.TimeoutSeconds) * time.Second
+	}
+	if limits.MaxInFlight > 0 {
+		rl.maxInFlight = limits.MaxInFlight
+	}
+	if limits.QueueDepth > 0 {
+		rl.queueDepth = limits.QueueDepth
+	}
+}
+
+// capManifestLimits caps the limits from the bot manifest so that a bot can not make the scanner
+// allocate large queues or run too many requests for it.
+func capManifestLimits(maxLimits config.BotMaxRequestLimits, limits *config.BotRequestLimits) *config.BotRequestLimits {
+	if limits == nil {
+		return nil
+	}
+	capped := *limits
+	capped.TimeoutSeconds = capLimit(capped.TimeoutSeconds, maxLimits.TimeoutSeconds, defaultMaxRequestTimeoutSeconds)
+	capped.MaxInFlight = capLimit(capped.MaxInFlight, maxLimits.MaxInFlight, defaultMaxInFlight)
+	capped.QueueDepth = capLimit(capped.QueueDepth, maxLimits.QueueDepth, defaultMaxQueueDepth)
+	return &capped
+}
+
+func capLimit(value, maxValue, defaultMaxValue int) int {
+	if maxValue <= 0 {
+		maxValue = defaultMaxValue
+	}
+	if value > maxValue {
+		return maxValue
+	}
+	return value
+}
+
+// resolveRequestLimits resolves the request limits of the bot by applying the node defaults,
+// the capped limits from the bot manifest and the node config overrides in order.
+func resolveRequestLimits(cfg config.AgentGrpcConfig, botConfig config.AgentConfig) requestLimits {
+	limits := requestLimits{
+		timeout:     RequestTimeout,
+		maxInFlight: 1,
+		queueDepth:  DefaultBufferSize,
+	}
+	// the streams can pipeline the requests by default
+	if cfg.Streaming && cfg.StreamPipelineDepth > 1 {
+		limits.maxInFlight = cfg.StreamPipelineDepth
+	}
+	limits.apply(&cfg.RequestLimits)
+	limits.apply(capManifestLimits(cfg.MaxRequestLimits, botConfig.RequestLimits))
+	for botID, botLimits := range cfg.BotRequestLimits {
+		if strings.EqualFold(botID, botConfig.ID) {
+			botLimits := botLimits
+			limits.apply(&botLimits)
+		}
+	}
+	return limits
+}
@@ -0,0 +1,57 @@
+package botio
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRequestLimits(t *testing.T) {
+	r := require.New(t)
+
+	// the constants are used without any config
+	limits := resolveRequestLimits(config.AgentGrpcConfig{}, config.AgentConfig{ID: "0xbot"})
+	r.Equal(requestLimits{timeout: RequestTimeout, maxInFlight: 1, queueDepth: DefaultBufferSize}, limits)
+
+	cfg := config.AgentGrpcConfig{
+		Streaming:           true,
+		StreamPipelineDepth: 4,
+		RequestLimits:       config.BotRequestLimits{TimeoutSeconds: 10, QueueDepth: 100},
+		BotRequestLimits: map[string]config.BotRequestLimits{
+			"0xBOT": {MaxInFlight: 2},
+		},
+	}
+
+	// the node defaults are applied on top of the pipeline depth
+	limits = resolveRequestLimits(cfg, config.AgentConfig{ID: "0xother"})
+	r.Equal(requestLimits{timeout: time.Second * 10, maxInFlight: 4, queueDepth: 100}, limits)
+
+	// the manifest limits are overridden by the node config
+	limits = resolveRequestLimits(cfg, config.AgentConfig{
+		ID:            "0xbot",
+		RequestLimits: &config.BotRequestLimits{TimeoutSeconds: 5, MaxInFlight: 8},
+	})
+	r.Equal(requestLimits{timeout: time.Second * 5, maxInFlight: 2, queueDepth: 100}, limits)
+
+	// the manifest limits are capped by the node maximums
+	limits = resolveRequestLimits(config.AgentGrpcConfig{}, config.AgentConfig{
+		ID:            "0xbot",
+		RequestLimits: &config.BotRequestLimits{TimeoutSeconds: math.MaxInt32, MaxInFlight: math.MaxInt32, QueueDepth: math.MaxInt32},
+	})
+	r.Equal(requestLimits{timeout: time.Second * defaultMaxRequestTimeoutSeconds, maxInFlight: defaultMaxInFlight, queueDepth: defaultMaxQueueDepth}, limits)
+
+	cfg.MaxRequestLimits = config.BotMaxRequestLimits{TimeoutSeconds: 60, MaxInFlight: 4, QueueDepth: 500}
+	limits = resolveRequestLimits(cfg, config.AgentConfig{
+		ID:            "0xother",
+		RequestLimits: &config.BotRequestLimits{TimeoutSeconds: 120, MaxInFlight: 16, QueueDepth: 1000000},
+	})
+	r.Equal(requestLimits{timeout: time.Second * 60, maxInFlight: 4, queueDepth: 500}, limits)
+
+	// the node config overrides are not capped
+	cfg.BotRequestLimits["0xBOT"] = config.BotRequestLimits{QueueDepth: 5000}
+	limits = resolveRequestLimits(cfg, config.AgentConfig{ID: "0xbot"})
+	r.Equal(5000, limits.queueDepth)
+}
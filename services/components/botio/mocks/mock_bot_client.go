@@ -6,6 +6,7 @@ package mock_botio
 
 import (
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/forta-network/forta-core-go/domain"
 	protocol "github.com/forta-network/forta-core-go/protocol"
@@ -37,6 +38,20 @@ func (m *MockBotClient) EXPECT() *MockBotClientMockRecorder {
 	return m.recorder
 }
 
+// Backlog mocks base method.
+func (m *MockBotClient) Backlog() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Backlog")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// Backlog indicates an expected call of Backlog.
+func (mr *MockBotClientMockRecorder) Backlog() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Backlog", reflect.TypeOf((*MockBotClient)(nil).Backlog))
+}
+
 // BlockRequestCh mocks base method.
 func (m *MockBotClient) BlockRequestCh() chan<- *botreq.BlockRequest {
 	m.ctrl.T.Helper()
@@ -121,6 +136,20 @@ func (mr *MockBotClientMockRecorder) Config() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Config", reflect.TypeOf((*MockBotClient)(nil).Config))
 }
 
+// ConnectionDown mocks base method.
+func (m *MockBotClient) ConnectionDown() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnectionDown")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ConnectionDown indicates an expected call of ConnectionDown.
+func (mr *MockBotClientMockRecorder) ConnectionDown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionDown", reflect.TypeOf((*MockBotClient)(nil).ConnectionDown))
+}
+
 // Initialize mocks base method.
 func (m *MockBotClient) Initialize() {
 	m.ctrl.T.Helper()
@@ -187,6 +216,32 @@ func (mr *MockBotClientMockRecorder) LogStatus() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogStatus", reflect.TypeOf((*MockBotClient)(nil).LogStatus))
 }
 
+// Pending mocks base method.
+func (m *MockBotClient) Pending() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pending")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Pending indicates an expected call of Pending.
+func (mr *MockBotClientMockRecorder) Pending() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockBotClient)(nil).Pending))
+}
+
+// Reinitialize mocks base method.
+func (m *MockBotClient) Reinitialize() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reinitialize")
+}
+
+// Reinitialize indicates an expected call of Reinitialize.
+func (mr *MockBotClientMockRecorder) Reinitialize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reinitialize", reflect.TypeOf((*MockBotClient)(nil).Reinitialize))
+}
+
 // SetConfig mocks base method.
 func (m *MockBotClient) SetConfig(arg0 config.AgentConfig) {
 	m.ctrl.T.Helper()
@@ -199,6 +254,21 @@ func (mr *MockBotClientMockRecorder) SetConfig(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConfig", reflect.TypeOf((*MockBotClient)(nil).SetConfig), arg0)
 }
 
+// ShedRequests mocks base method.
+func (m *MockBotClient) ShedRequests(keep time.Duration) (int, int) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShedRequests", keep)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	return ret0, ret1
+}
+
+// ShedRequests indicates an expected call of ShedRequests.
+func (mr *MockBotClientMockRecorder) ShedRequests(keep interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShedRequests", reflect.TypeOf((*MockBotClient)(nil).ShedRequests), keep)
+}
+
 // ShouldProcessAlert mocks base method.
 func (m *MockBotClient) ShouldProcessAlert(event *protocol.AlertEvent) bool {
 	m.ctrl.T.Helper()
@@ -5,6 +5,7 @@
 package mock_botio
 
 import (
+	context "context"
 	reflect "reflect"
 
 	health "github.com/forta-network/forta-core-go/clients/health"
@@ -36,6 +37,20 @@ func (m *MockSender) EXPECT() *MockSenderMockRecorder {
 	return m.recorder
 }
 
+// Drain mocks base method.
+func (m *MockSender) Drain(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drain", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Drain indicates an expected call of Drain.
+func (mr *MockSenderMockRecorder) Drain(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drain", reflect.TypeOf((*MockSender)(nil).Drain), ctx)
+}
+
 // Health mocks base method.
 func (m *MockSender) Health() health.Reports {
 	m.ctrl.T.Helper()
@@ -65,39 +80,39 @@ func (mr *MockSenderMockRecorder) Name() *gomock.Call {
 }
 
 // SendEvaluateAlertRequest mocks base method.
-func (m *MockSender) SendEvaluateAlertRequest(req *protocol.EvaluateAlertRequest) {
+func (m *MockSender) SendEvaluateAlertRequest(ctx context.Context, req *protocol.EvaluateAlertRequest) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SendEvaluateAlertRequest", req)
+	m.ctrl.Call(m, "SendEvaluateAlertRequest", ctx, req)
 }
 
 // SendEvaluateAlertRequest indicates an expected call of SendEvaluateAlertRequest.
-func (mr *MockSenderMockRecorder) SendEvaluateAlertRequest(req interface{}) *gomock.Call {
+func (mr *MockSenderMockRecorder) SendEvaluateAlertRequest(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateAlertRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateAlertRequest), req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateAlertRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateAlertRequest), ctx, req)
 }
 
 // SendEvaluateBlockRequest mocks base method.
-func (m *MockSender) SendEvaluateBlockRequest(req *protocol.EvaluateBlockRequest) {
+func (m *MockSender) SendEvaluateBlockRequest(ctx context.Context, req *protocol.EvaluateBlockRequest) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SendEvaluateBlockRequest", req)
+	m.ctrl.Call(m, "SendEvaluateBlockRequest", ctx, req)
 }
 
 // SendEvaluateBlockRequest indicates an expected call of SendEvaluateBlockRequest.
-func (mr *MockSenderMockRecorder) SendEvaluateBlockRequest(req interface{}) *gomock.Call {
+func (mr *MockSenderMockRecorder) SendEvaluateBlockRequest(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateBlockRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateBlockRequest), req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateBlockRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateBlockRequest), ctx, req)
 }
 
 // SendEvaluateTxRequest mocks base method.
-func (m *MockSender) SendEvaluateTxRequest(req *protocol.EvaluateTxRequest) {
+func (m *MockSender) SendEvaluateTxRequest(ctx context.Context, req *protocol.EvaluateTxRequest) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SendEvaluateTxRequest", req)
+	m.ctrl.Call(m, "SendEvaluateTxRequest", ctx, req)
 }
 
 // SendEvaluateTxRequest indicates an expected call of SendEvaluateTxRequest.
-func (mr *MockSenderMockRecorder) SendEvaluateTxRequest(req interface{}) *gomock.Call {
+func (mr *MockSenderMockRecorder) SendEvaluateTxRequest(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateTxRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateTxRequest), req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEvaluateTxRequest", reflect.TypeOf((*MockSender)(nil).SendEvaluateTxRequest), ctx, req)
 }
 
 // MockBotPool is a mock of BotPool interface.
@@ -0,0 +1,170 @@
+package botio
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+const durationAverageWeight = 0.2
+
+// durationAverage tracks the exponential moving average of a duration.
+type durationAverage struct {
+	avg time.Duration
+	mu  sync.Mutex
+}
+
+func (da *durationAverage) Record(d time.Duration) {
+	da.mu.Lock()
+	defer da.mu.Unlock()
+	if da.avg == 0 {
+		da.avg = d
+		return
+	}
+	da.avg = time.Duration(durationAverageWeight*float64(d) + (1-durationAverageWeight)*float64(da.avg))
+}
+
+func (da *durationAverage) Get() time.Duration {
+	da.mu.Lock()
+	defer da.mu.Unlock()
+	return da.avg
+}
+
+// overloadScheduler sheds the oldest queued requests of the bots which cannot keep up with the
+// chain so that the newest blocks are evaluated first and the queues do not keep growing.
+type overloadScheduler struct {
+	cfg config.BotOverloadConfig
+
+	lastBlock     uint64
+	lastTimestamp uint64
+	interval      durationAverage
+
+	shedBots int
+	mu       sync.Mutex
+}
+
+func newOverloadScheduler(cfg config.BotOverloadConfig) *overloadScheduler {
+	return &overloadScheduler{cfg: cfg}
+}
+
+// ObserveBlock updates the block interval from the block timestamps and tells if the block is
+// newer than the previous blocks. The older blocks from the backfills and the reorgs are ignored.
+func (sch *overloadScheduler) ObserveBlock(block *protocol.BlockEvent_EthBlock) bool {
+	if block == nil {
+		return false
+	}
+	number, err := hexutil.DecodeUint64(block.Number)
+	if err != nil {
+		return false
+	}
+	timestamp, err := hexutil.DecodeUint64(block.Timestamp)
+	if err != nil {
+		return false
+	}
+
+	sch.mu.Lock()
+	defer sch.mu.Unlock()
+	if number <= sch.lastBlock {
+		return false
+	}
+	if sch.lastBlock > 0 && timestamp > sch.lastTimestamp {
+		blocks := number - sch.lastBlock
+		sch.interval.Record(time.Duration(timestamp-sch.lastTimestamp) * time.Second / time.Duration(blocks))
+	}
+	sch.lastBlock = number
+	sch.lastTimestamp = timestamp
+	return true
+}
+
+// LatestBlock returns the newest block observed so far.
+func (sch *overloadScheduler) LatestBlock() uint64 {
+	sch.mu.Lock()
+	defer sch.mu.Unlock()
+	return sch.lastBlock
+}
+
+// BlockInterval returns the average time between the blocks.
+func (sch *overloadScheduler) BlockInterval() time.Duration {
+	return sch.interval.Get()
+}
+
+func (sch *overloadScheduler) isBestEffort(botConfig config.AgentConfig) bool {
+	if botConfig.BestEffort {
+		return true
+	}
+	for _, botID := range sch.cfg.BestEffortBots {
+		if strings.EqualFold(botID, botConfig.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Shed drops the oldest queued requests of the overloaded bots and returns the overload metrics.
+// The bots are overloaded when the time to evaluate the queued requests is longer than the max
+// backlog. If the bots are behind by more than a block interval on average, the best-effort bots
+// are shed down to a single block interval first.
+func (sch *overloadScheduler) Shed(bots []BotClient) (metricsList []*protocol.AgentMetric) {
+	interval := sch.BlockInterval()
+	if sch.cfg.Disable || interval == 0 || len(bots) == 0 {
+		return nil
+	}
+
+	backlogs := make([]time.Duration, len(bots))
+	var total time.Duration
+	for i, bot := range bots {
+		backlogs[i] = bot.Backlog()
+		total += backlogs[i]
+	}
+	underPressure := total > interval*time.Duration(len(bots))
+
+	maxBacklog := interval * time.Duration(sch.cfg.MaxBacklogBlocks)
+	var shedBots int
+	for i, bot := range bots {
+		botConfig := bot.Config()
+		keep := maxBacklog
+		if underPressure && sch.isBestEffort(botConfig) {
+			keep = interval
+		}
+		if backlogs[i] <= keep {
+			continue
+		}
+		txs, blocks := bot.ShedRequests(keep)
+		if txs == 0 && blocks == 0 {
+			continue
+		}
+		shedBots++
+		log.WithFields(log.Fields{
+			"bot":           botConfig.ID,
+			"backlog":       backlogs[i],
+			"blockInterval": interval,
+			"underPressure": underPressure,
+			"txs":           txs,
+			"blocks":        blocks,
+		}).Warn("bot is overloaded - dropped the oldest requests")
+		if txs > 0 {
+			metricsList = append(metricsList, metrics.CreateAgentMetric(botConfig.ID, metrics.MetricTxOverload, float64(txs)))
+		}
+		if blocks > 0 {
+			metricsList = append(metricsList, metrics.CreateAgentMetric(botConfig.ID, metrics.MetricBlockOverload, float64(blocks)))
+		}
+	}
+
+	sch.mu.Lock()
+	sch.shedBots = shedBots
+	sch.mu.Unlock()
+	return
+}
+
+// ShedBots returns how many bots were shed for the last block.
+func (sch *overloadScheduler) ShedBots() int {
+	sch.mu.Lock()
+	defer sch.mu.Unlock()
+	return sch.shedBots
+}
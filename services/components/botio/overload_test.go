@@ -0,0 +1,62 @@
+package botio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio/botreq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotClient_ShedRequests(t *testing.T) {
+	r := require.New(t)
+
+	bot := &botClient{
+		txRequests:    make(chan *botreq.TxRequest, 10),
+		blockRequests: make(chan *botreq.BlockRequest, 10),
+		limits:        requestLimits{maxInFlight: 2},
+	}
+	bot.txLatency.Record(time.Second)
+	bot.blockLatency.Record(time.Second * 2)
+	for i := 0; i < 6; i++ {
+		bot.txRequests <- &botreq.TxRequest{}
+	}
+	for i := 0; i < 3; i++ {
+		bot.blockRequests <- &botreq.BlockRequest{}
+	}
+	r.Equal(time.Second*6, bot.Backlog())
+
+	// the txs are dropped first
+	txs, blocks := bot.ShedRequests(time.Second * 4)
+	r.Equal(4, txs)
+	r.Equal(0, blocks)
+	r.Equal(time.Second*4, bot.Backlog())
+
+	txs, blocks = bot.ShedRequests(time.Second)
+	r.Equal(2, txs)
+	r.Equal(2, blocks)
+	r.Equal(time.Second, bot.Backlog())
+}
+
+func TestOverloadScheduler_ObserveBlock(t *testing.T) {
+	r := require.New(t)
+
+	sch := newOverloadScheduler(config.BotOverloadConfig{MaxBacklogBlocks: 5})
+	r.True(sch.ObserveBlock(&protocol.BlockEvent_EthBlock{Number: "0x10", Timestamp: "0x64"}))
+	r.Equal(time.Duration(0), sch.BlockInterval())
+
+	// the interval is averaged over the missed blocks
+	r.True(sch.ObserveBlock(&protocol.BlockEvent_EthBlock{Number: "0x12", Timestamp: "0x7c"}))
+	r.Equal(time.Second*12, sch.BlockInterval())
+
+	// the older blocks are ignored
+	r.False(sch.ObserveBlock(&protocol.BlockEvent_EthBlock{Number: "0x11", Timestamp: "0x70"}))
+	r.Equal(time.Second*12, sch.BlockInterval())
+
+	r.True(sch.isBestEffort(config.AgentConfig{BestEffort: true}))
+	sch.cfg.BestEffortBots = []string{"0xBOT"}
+	r.True(sch.isBestEffort(config.AgentConfig{ID: "0xbot"}))
+	r.False(sch.isBestEffort(config.AgentConfig{ID: "0xother"}))
+}
@@ -0,0 +1,212 @@
+package botio
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+)
+
+const payloadStorePath = "/payloads/"
+
+type storedPayload struct {
+	hash      string
+	b         []byte
+	expiresAt time.Time
+}
+
+// PayloadStore keeps the large marshaled requests in memory by their SHA-256 hashes and serves
+// them to the bots so that a request is written once instead of being sent to every bot.
+type PayloadStore struct {
+	cfg     config.BotPayloadRefsConfig
+	baseURL string
+	server  *http.Server
+
+	payloads map[string]*list.Element
+	order    *list.List
+	size     int
+	mu       sync.RWMutex
+}
+
+// NewPayloadStore creates a new payload store.
+func NewPayloadStore(cfg config.BotPayloadRefsConfig, orchestratorCfg config.OrchestratorConfig) *PayloadStore {
+	return &PayloadStore{
+		cfg: cfg,
+		baseURL: fmt.Sprintf(
+			"http://%s:%s%s", orchestratorCfg.ServiceHost(config.DockerScannerContainerName),
+			config.DefaultPayloadStorePort, payloadStorePath,
+		),
+		payloads: make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Start starts serving the payloads.
+func (ps *PayloadStore) Start() error {
+	ps.server = &http.Server{
+		Addr:    ":" + config.DefaultPayloadStorePort,
+		Handler: ps,
+	}
+	utils.GoListenAndServe(ps.server)
+	return nil
+}
+
+// Stop stops the service.
+func (ps *PayloadStore) Stop() error {
+	if ps.server != nil {
+		return ps.server.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (ps *PayloadStore) Name() string {
+	return "payload-store"
+}
+
+// Health implements the health.Reporter interface.
+func (ps *PayloadStore) Health() health.Reports {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return health.Reports{
+		{
+			Name:    "payloads",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(len(ps.payloads)),
+		},
+		{
+			Name:    "payloads.bytes",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(ps.size),
+		},
+	}
+}
+
+// MinSize returns the size of the smallest payload which should be stored.
+func (ps *PayloadStore) MinSize() int {
+	return ps.cfg.MinSizeKB * 1024
+}
+
+// Put copies the marshaled request to the store and returns the reference. It returns
+// false if the payload does not fit in the store.
+func (ps *PayloadStore) Put(b []byte) (*agentgrpc.PayloadRef, bool) {
+	maxSize := ps.cfg.MaxStoreSizeMB * 1024 * 1024
+	if len(b) > maxSize {
+		return nil, false
+	}
+	sum := sha256.Sum256(b)
+	hash := hex.EncodeToString(sum[:])
+	ref := &agentgrpc.PayloadRef{URL: ps.baseURL + hash, SHA256: hash}
+	expiresAt := time.Now().Add(time.Duration(ps.cfg.TTLSeconds) * time.Second)
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.evictUnsafe(time.Now(), maxSize-len(b))
+	// the same payload is stored once and lives longer
+	if elem, ok := ps.payloads[hash]; ok {
+		ps.removeUnsafe(elem)
+	}
+	payload := &storedPayload{hash: hash, b: make([]byte, len(b)), expiresAt: expiresAt}
+	copy(payload.b, b)
+	ps.payloads[hash] = ps.order.PushBack(payload)
+	ps.size += len(b)
+	return ref, true
+}
+
+// evictUnsafe removes the expired payloads and then the oldest ones until the size is not larger
+// than the limit. The payloads are ordered by the expiry since they have the same TTL.
+func (ps *PayloadStore) evictUnsafe(now time.Time, sizeLimit int) {
+	for elem := ps.order.Front(); elem != nil; elem = ps.order.Front() {
+		payload := elem.Value.(*storedPayload)
+		if now.Before(payload.expiresAt) && ps.size <= sizeLimit {
+			return
+		}
+		ps.removeUnsafe(elem)
+	}
+}
+
+func (ps *PayloadStore) removeUnsafe(elem *list.Element) {
+	payload := ps.order.Remove(elem).(*storedPayload)
+	delete(ps.payloads, payload.hash)
+	ps.size -= len(payload.b)
+}
+
+// Get returns the stored payload.
+func (ps *PayloadStore) Get(hash string) ([]byte, bool) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	elem, ok := ps.payloads[strings.ToLower(hash)]
+	if !ok {
+		return nil, false
+	}
+	payload := elem.Value.(*storedPayload)
+	if time.Now().After(payload.expiresAt) {
+		return nil, false
+	}
+	return payload.b, true
+}
+
+// ServeHTTP implements http.Handler.
+func (ps *PayloadStore) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(req.URL.Path, payloadStorePath) {
+		http.NotFound(w, req)
+		return
+	}
+	b, ok := ps.Get(strings.TrimPrefix(req.URL.Path, payloadStorePath))
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	_, _ = w.Write(b)
+}
+
+// txRequestHeader returns the tx request without the large fields so that the bot can tell which
+// tx the reference is for.
+func txRequestHeader(req *protocol.EvaluateTxRequest) *protocol.EvaluateTxRequest {
+	header := &protocol.EvaluateTxRequest{RequestId: req.RequestId}
+	if req.Event != nil {
+		header.Event = &protocol.TransactionEvent{
+			Type:       req.Event.Type,
+			Network:    req.Event.Network,
+			Block:      req.Event.Block,
+			Timestamps: req.Event.Timestamps,
+		}
+		if req.Event.Transaction != nil {
+			header.Event.Transaction = &protocol.TransactionEvent_EthTransaction{Hash: req.Event.Transaction.Hash}
+		}
+	}
+	return header
+}
+
+// blockRequestHeader returns the block request without the block.
+func blockRequestHeader(req *protocol.EvaluateBlockRequest) *protocol.EvaluateBlockRequest {
+	header := &protocol.EvaluateBlockRequest{RequestId: req.RequestId}
+	if req.Event != nil {
+		header.Event = &protocol.BlockEvent{
+			Type:        req.Event.Type,
+			BlockHash:   req.Event.BlockHash,
+			BlockNumber: req.Event.BlockNumber,
+			Network:     req.Event.Network,
+			Timestamps:  req.Event.Timestamps,
+		}
+	}
+	return header
+}
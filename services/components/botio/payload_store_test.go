@@ -0,0 +1,122 @@
+package botio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadStore(t *testing.T) {
+	r := require.New(t)
+
+	ps := NewPayloadStore(config.BotPayloadRefsConfig{
+		MinSizeKB:      1,
+		MaxStoreSizeMB: 1,
+		TTLSeconds:     60,
+	}, config.OrchestratorConfig{})
+	r.Equal(1024, ps.MinSize())
+
+	payload := bytes.Repeat([]byte{1}, 400*1024)
+	sum := sha256.Sum256(payload)
+	ref, ok := ps.Put(payload)
+	r.True(ok)
+	r.Equal(hex.EncodeToString(sum[:]), ref.SHA256)
+	r.Equal("http://"+config.DockerScannerContainerName+":"+config.DefaultPayloadStorePort+"/payloads/"+ref.SHA256, ref.URL)
+
+	// the payload is copied
+	payload[0] = 2
+	b, ok := ps.Get(ref.SHA256)
+	r.True(ok)
+	r.Equal(byte(1), b[0])
+
+	// the same payload is stored once
+	payload[0] = 1
+	_, ok = ps.Put(payload)
+	r.True(ok)
+	r.Len(ps.payloads, 1)
+	r.Equal(len(payload), ps.size)
+
+	// the oldest payloads are evicted when the store is full
+	ref2, ok := ps.Put(bytes.Repeat([]byte{2}, 400*1024))
+	r.True(ok)
+	ref3, ok := ps.Put(bytes.Repeat([]byte{3}, 400*1024))
+	r.True(ok)
+	_, ok = ps.Get(ref.SHA256)
+	r.False(ok)
+	_, ok = ps.Get(ref2.SHA256)
+	r.True(ok)
+
+	// the payloads which are larger than the store are not stored
+	_, ok = ps.Put(make([]byte, 2*1024*1024))
+	r.False(ok)
+
+	// the expired payloads are not served
+	ps.payloads[ref3.SHA256].Value.(*storedPayload).expiresAt = time.Now().Add(-time.Second)
+	_, ok = ps.Get(ref3.SHA256)
+	r.False(ok)
+}
+
+func TestPayloadStore_ServeHTTP(t *testing.T) {
+	r := require.New(t)
+
+	ps := NewPayloadStore(config.BotPayloadRefsConfig{MinSizeKB: 1, MaxStoreSizeMB: 1, TTLSeconds: 60}, config.OrchestratorConfig{})
+	ref, ok := ps.Put([]byte("payload"))
+	r.True(ok)
+
+	rec := httptest.NewRecorder()
+	ps.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/payloads/"+ref.SHA256, nil))
+	r.Equal(http.StatusOK, rec.Code)
+	b, err := ioutil.ReadAll(rec.Body)
+	r.NoError(err)
+	r.Equal("payload", string(b))
+
+	rec = httptest.NewRecorder()
+	ps.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/payloads/123", nil))
+	r.Equal(http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	ps.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/payloads/"+ref.SHA256, nil))
+	r.Equal(http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestRequestHeaders(t *testing.T) {
+	r := require.New(t)
+
+	txReq := &protocol.EvaluateTxRequest{
+		RequestId: "1",
+		Event: &protocol.TransactionEvent{
+			Transaction: &protocol.TransactionEvent_EthTransaction{Hash: "0x1", Input: "0x1234"},
+			Block:       &protocol.TransactionEvent_EthBlock{BlockNumber: "0x1"},
+			Traces:      []*protocol.TransactionEvent_Trace{{}},
+			Logs:        []*protocol.TransactionEvent_Log{{}},
+		},
+	}
+	txHeader := txRequestHeader(txReq)
+	r.Equal("1", txHeader.RequestId)
+	r.Equal("0x1", txHeader.Event.Transaction.Hash)
+	r.Empty(txHeader.Event.Transaction.Input)
+	r.Equal("0x1", txHeader.Event.Block.BlockNumber)
+	r.Empty(txHeader.Event.Traces)
+	r.Empty(txHeader.Event.Logs)
+
+	blockReq := &protocol.EvaluateBlockRequest{
+		RequestId: "2",
+		Event: &protocol.BlockEvent{
+			BlockNumber: "0x2",
+			Block:       &protocol.BlockEvent_EthBlock{Transactions: []string{"0x1"}},
+		},
+	}
+	blockHeader := blockRequestHeader(blockReq)
+	r.Equal("2", blockHeader.RequestId)
+	r.Equal("0x2", blockHeader.Event.BlockNumber)
+	r.Nil(blockHeader.Event.Block)
+}
@@ -0,0 +1,179 @@
+package botio
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	recordingFileExt        = ".jsonl"
+	recordingFileTimeFormat = "20060102-150405.000000000"
+	maxRecordingLineSize    = 256 * 1024 * 1024
+)
+
+// Recording is a request which was sent to a bot. The recordings are written to the files as
+// JSON lines.
+type Recording struct {
+	Time        time.Time        `json:"time"`
+	BotID       string           `json:"botId"`
+	Image       string           `json:"image"`
+	Method      agentgrpc.Method `json:"method"`
+	BlockNumber uint64           `json:"blockNumber"`
+	// Request is the protobuf encoded request.
+	Request []byte `json:"request"`
+}
+
+type recordingFile struct {
+	file *os.File
+	size int
+}
+
+// Recorder writes the requests which are sent to the selected bots to files in a directory per
+// bot. A nil recorder records nothing.
+type Recorder struct {
+	cfg  config.BotRecordingConfig
+	dir  string
+	bots map[string]bool
+	rand *rand.Rand
+
+	files map[string]*recordingFile
+	mu    sync.Mutex
+}
+
+// NewRecorder creates a new recorder which writes to the given dir.
+func NewRecorder(dir string, cfg config.BotRecordingConfig) *Recorder {
+	bots := make(map[string]bool)
+	for _, botID := range cfg.BotIDs {
+		bots[strings.ToLower(botID)] = true
+	}
+	return &Recorder{
+		cfg:   cfg,
+		dir:   dir,
+		bots:  bots,
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		files: make(map[string]*recordingFile),
+	}
+}
+
+// ShouldRecord tells if the request which is sent to the bot for the block should be recorded.
+func (rec *Recorder) ShouldRecord(botID string, blockNumber uint64) bool {
+	if rec == nil || !rec.bots[strings.ToLower(botID)] {
+		return false
+	}
+	if rec.cfg.FromBlock > 0 && blockNumber < rec.cfg.FromBlock {
+		return false
+	}
+	if rec.cfg.ToBlock > 0 && blockNumber > rec.cfg.ToBlock {
+		return false
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.rand.Float64() < rec.cfg.SampleRate
+}
+
+// Record writes the encoded request to the current file of the bot.
+func (rec *Recorder) Record(botConfig config.AgentConfig, method agentgrpc.Method, blockNumber uint64, b []byte) {
+	line, err := json.Marshal(&Recording{
+		Time:        time.Now().UTC(),
+		BotID:       botConfig.ID,
+		Image:       botConfig.Image,
+		Method:      method,
+		BlockNumber: blockNumber,
+		Request:     b,
+	})
+	if err != nil {
+		log.WithError(err).Warn("failed to encode the recording")
+		return
+	}
+	line = append(line, '\n')
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if err := rec.writeUnsafe(strings.ToLower(botConfig.ID), line); err != nil {
+		log.WithError(err).WithField("bot", botConfig.ID).Warn("failed to record the request")
+	}
+}
+
+func (rec *Recorder) writeUnsafe(botID string, line []byte) error {
+	file := rec.files[botID]
+	if file != nil && file.size+len(line) > rec.cfg.MaxFileSizeMB*1024*1024 {
+		file.file.Close()
+		file = nil
+	}
+	if file == nil {
+		var err error
+		file, err = rec.createFile(botID)
+		if err != nil {
+			return err
+		}
+		rec.files[botID] = file
+	}
+	n, err := file.file.Write(line)
+	file.size += n
+	return err
+}
+
+// createFile creates a new file for the bot and removes the oldest files of the bot.
+func (rec *Recorder) createFile(botID string) (*recordingFile, error) {
+	botDir := path.Join(rec.dir, botID)
+	if err := os.MkdirAll(botDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the recordings dir: %v", err)
+	}
+	name := time.Now().UTC().Format(recordingFileTimeFormat) + recordingFileExt
+	file, err := os.OpenFile(path.Join(botDir, name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the recording file: %v", err)
+	}
+
+	entries, err := os.ReadDir(botDir)
+	if err != nil {
+		return &recordingFile{file: file}, nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), recordingFileExt) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > rec.cfg.MaxFiles {
+		if err := os.Remove(path.Join(botDir, names[0])); err != nil {
+			log.WithError(err).Warn("failed to remove the old recording file")
+		}
+		names = names[1:]
+	}
+	return &recordingFile{file: file}, nil
+}
+
+// ReadRecordings reads the recordings in order and calls the handler for each.
+func ReadRecordings(r io.Reader, handler func(*Recording) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordingLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var recording Recording
+		if err := json.Unmarshal(line, &recording); err != nil {
+			return fmt.Errorf("failed to decode the recording: %v", err)
+		}
+		if err := handler(&recording); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
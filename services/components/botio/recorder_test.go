@@ -0,0 +1,72 @@
+package botio
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_ShouldRecord(t *testing.T) {
+	r := require.New(t)
+
+	var nilRecorder *Recorder
+	r.False(nilRecorder.ShouldRecord("0x1", 1))
+
+	rec := NewRecorder(t.TempDir(), config.BotRecordingConfig{
+		BotIDs:     []string{"0xABC"},
+		SampleRate: 1,
+		FromBlock:  10,
+		ToBlock:    20,
+	})
+	r.True(rec.ShouldRecord("0xabc", 10))
+	r.True(rec.ShouldRecord("0xabc", 20))
+	r.False(rec.ShouldRecord("0xabc", 9))
+	r.False(rec.ShouldRecord("0xabc", 21))
+	r.False(rec.ShouldRecord("0xdef", 15))
+
+	rec.cfg.SampleRate = 0
+	r.False(rec.ShouldRecord("0xabc", 15))
+}
+
+func TestRecorder_Record(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	rec := NewRecorder(dir, config.BotRecordingConfig{
+		BotIDs:        []string{"0xabc"},
+		SampleRate:    1,
+		MaxFileSizeMB: 1,
+		MaxFiles:      2,
+	})
+	botConfig := config.AgentConfig{ID: "0xABC", Image: "bot-image"}
+	payload := bytes.Repeat([]byte{1}, 300*1024)
+	for i := 0; i < 5; i++ {
+		rec.Record(botConfig, agentgrpc.MethodEvaluateTx, uint64(i), payload)
+	}
+
+	// the files are rotated and the oldest file is removed
+	botDir := path.Join(dir, "0xabc")
+	entries, err := os.ReadDir(botDir)
+	r.NoError(err)
+	r.Len(entries, 2)
+
+	file, err := os.Open(path.Join(botDir, entries[0].Name()))
+	r.NoError(err)
+	defer file.Close()
+	var recordings []*Recording
+	r.NoError(ReadRecordings(file, func(recording *Recording) error {
+		recordings = append(recordings, recording)
+		return nil
+	}))
+	r.Len(recordings, 2)
+	r.Equal("0xABC", recordings[0].BotID)
+	r.Equal("bot-image", recordings[0].Image)
+	r.Equal(agentgrpc.MethodEvaluateTx, recordings[0].Method)
+	r.EqualValues(2, recordings[0].BlockNumber)
+	r.Equal(payload, recordings[0].Request)
+}
@@ -0,0 +1,189 @@
+package botio
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services/components/metrics"
+)
+
+// Finding limits
+const (
+	MaxFindingAlertIDLength     = 256
+	MaxFindingProtocolLength    = 256
+	MaxFindingNameLength        = 256
+	MaxFindingDescriptionLength = 4096
+	MaxFindingMetadataSize      = 16 * 1024 // total size of the keys and the values
+	MaxFindingAddresses         = 1000
+	MaxFindingIndicators        = 100
+	MaxFindingLabels            = 100
+	MaxFindingRelatedAlerts     = 100
+	MaxLabelFieldLength         = 1024
+	MaxLabelMetadata            = 50
+)
+
+// sanitizeResult counts the findings which were changed by the sanitization.
+type sanitizeResult struct {
+	Dropped   int
+	Rejected  int
+	Truncated int
+}
+
+// sanitizeFindings rejects the malformed findings, truncates the oversized ones and
+// drops the ones over the max finding count.
+func sanitizeFindings(findings []*protocol.Finding) ([]*protocol.Finding, sanitizeResult) {
+	var (
+		result    sanitizeResult
+		sanitized []*protocol.Finding
+	)
+	for _, finding := range findings {
+		if !isWellFormedFinding(finding) {
+			result.Rejected++
+			continue
+		}
+		if truncateFinding(finding) {
+			result.Truncated++
+		}
+		sanitized = append(sanitized, finding)
+	}
+	if len(sanitized) > MaxFindings {
+		result.Dropped = len(sanitized) - MaxFindings
+		sanitized = sanitized[:MaxFindings]
+	}
+	return sanitized, result
+}
+
+// Metrics returns the metrics of the bot for the non-zero counts.
+func (result sanitizeResult) Metrics(botID string) (metricsList []*protocol.AgentMetric) {
+	for name, count := range map[string]int{
+		metrics.MetricFindingsDropped:   result.Dropped,
+		metrics.MetricFindingsRejected:  result.Rejected,
+		metrics.MetricFindingsTruncated: result.Truncated,
+	} {
+		if count > 0 {
+			metricsList = append(metricsList, metrics.CreateAgentMetric(botID, name, float64(count)))
+		}
+	}
+	return
+}
+
+func isWellFormedFinding(finding *protocol.Finding) bool {
+	if finding == nil {
+		return false
+	}
+	if _, ok := protocol.Finding_Severity_name[int32(finding.Severity)]; !ok {
+		return false
+	}
+	if _, ok := protocol.Finding_FindingType_name[int32(finding.Type)]; !ok {
+		return false
+	}
+	// truncating the alert id would change which alert this is
+	if len(finding.AlertId) > MaxFindingAlertIDLength {
+		return false
+	}
+	for _, label := range finding.Labels {
+		if label == nil {
+			return false
+		}
+		if _, ok := protocol.Label_EntityType_name[int32(label.EntityType)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateFinding truncates the fields of the finding which exceed the limits and
+// tells if anything was truncated.
+func truncateFinding(finding *protocol.Finding) (truncated bool) {
+	truncateStr := func(s *string, maxLen int) {
+		if len(*s) > maxLen {
+			*s = truncateString(*s, maxLen)
+			truncated = true
+		}
+	}
+
+	truncateStr(&finding.Protocol, MaxFindingProtocolLength)
+	truncateStr(&finding.Name, MaxFindingNameLength)
+	truncateStr(&finding.Description, MaxFindingDescriptionLength)
+
+	if metadata, ok := truncateMetadata(finding.Metadata, MaxFindingMetadataSize); !ok {
+		finding.Metadata = metadata
+		truncated = true
+	}
+	if len(finding.Addresses) > MaxFindingAddresses {
+		finding.Addresses = finding.Addresses[:MaxFindingAddresses]
+		truncated = true
+	}
+	if len(finding.RelatedAlerts) > MaxFindingRelatedAlerts {
+		finding.RelatedAlerts = finding.RelatedAlerts[:MaxFindingRelatedAlerts]
+		truncated = true
+	}
+	if len(finding.Indicators) > MaxFindingIndicators {
+		keys := sortedKeys(finding.Indicators)
+		for _, key := range keys[MaxFindingIndicators:] {
+			delete(finding.Indicators, key)
+		}
+		truncated = true
+	}
+	if len(finding.Labels) > MaxFindingLabels {
+		finding.Labels = finding.Labels[:MaxFindingLabels]
+		truncated = true
+	}
+	for _, label := range finding.Labels {
+		truncateStr(&label.Entity, MaxLabelFieldLength)
+		truncateStr(&label.Label, MaxLabelFieldLength)
+		if len(label.Metadata) > MaxLabelMetadata {
+			label.Metadata = label.Metadata[:MaxLabelMetadata]
+			truncated = true
+		}
+		for i := range label.Metadata {
+			truncateStr(&label.Metadata[i], MaxLabelFieldLength)
+		}
+	}
+	return
+}
+
+// truncateMetadata keeps the entries in key order until the size limit is reached.
+// It returns false if some entries were removed.
+func truncateMetadata(metadata map[string]string, maxSize int) (map[string]string, bool) {
+	var size int
+	for key, value := range metadata {
+		size += len(key) + len(value)
+	}
+	if size <= maxSize {
+		return metadata, true
+	}
+
+	truncated := make(map[string]string)
+	size = 0
+	for _, key := range sortedKeys(metadata) {
+		value := metadata[key]
+		if size+len(key)+len(value) > maxSize {
+			continue
+		}
+		size += len(key) + len(value)
+		truncated[key] = value
+	}
+	return truncated, false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// truncateString cuts the string without splitting a multi-byte character.
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	for maxLen > 0 && !utf8.RuneStart(s[maxLen]) {
+		maxLen--
+	}
+	return s[:maxLen]
+}
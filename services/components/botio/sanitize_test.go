@@ -0,0 +1,55 @@
+package botio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeFindings(t *testing.T) {
+	r := require.New(t)
+
+	findings := []*protocol.Finding{
+		nil,
+		{Name: "bad severity", Severity: protocol.Finding_Severity(100)},
+		{Name: "bad label", Labels: []*protocol.Label{nil}},
+		{AlertId: strings.Repeat("a", MaxFindingAlertIDLength+1)},
+		{
+			Name:        strings.Repeat("ü", MaxFindingNameLength),
+			Description: "ok",
+			Metadata: map[string]string{
+				"a": strings.Repeat("a", MaxFindingMetadataSize/2),
+				"b": strings.Repeat("b", MaxFindingMetadataSize/2),
+			},
+			Addresses: make([]string, MaxFindingAddresses+1),
+		},
+	}
+	for i := 0; i < MaxFindings; i++ {
+		findings = append(findings, &protocol.Finding{Name: "ok", Severity: protocol.Finding_HIGH})
+	}
+
+	sanitized, result := sanitizeFindings(findings)
+	r.Len(sanitized, MaxFindings)
+	r.Equal(sanitizeResult{Dropped: 1, Rejected: 4, Truncated: 1}, result)
+
+	truncated := sanitized[0]
+	r.LessOrEqual(len(truncated.Name), MaxFindingNameLength)
+	r.True(strings.HasPrefix(strings.Repeat("ü", MaxFindingNameLength), truncated.Name))
+	r.Equal("ok", truncated.Description)
+	r.Len(truncated.Metadata, 1)
+	r.Contains(truncated.Metadata, "a")
+	r.Len(truncated.Addresses, MaxFindingAddresses)
+
+	metricNames := make(map[string]float64)
+	for _, metric := range result.Metrics("0xbot") {
+		metricNames[metric.Name] = metric.Value
+	}
+	r.Equal(map[string]float64{
+		metrics.MetricFindingsDropped:   1,
+		metrics.MetricFindingsRejected:  4,
+		metrics.MetricFindingsTruncated: 1,
+	}, metricNames)
+}
@@ -9,17 +9,27 @@ import (
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
 	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/golang/protobuf/proto"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// TxEventTypePending marks the pending txs from the mempool. The protocol does not define
+// an event type for the pending txs yet so the bots receive it as the next enum value.
+const TxEventTypePending protocol.TransactionEvent_EventType = 2
+
 // Sender sends requests to all bots and outputs bot responses.
 type Sender interface {
-	SendEvaluateTxRequest(req *protocol.EvaluateTxRequest)
-	SendEvaluateBlockRequest(req *protocol.EvaluateBlockRequest)
-	SendEvaluateAlertRequest(req *protocol.EvaluateAlertRequest)
+	SendEvaluateTxRequest(ctx context.Context, req *protocol.EvaluateTxRequest)
+	SendEvaluateBlockRequest(ctx context.Context, req *protocol.EvaluateBlockRequest)
+	SendEvaluateAlertRequest(ctx context.Context, req *protocol.EvaluateAlertRequest)
+	Drain(ctx context.Context) error
 	health.Reporter
 }
 
@@ -32,16 +42,26 @@ type BotPool interface {
 type requestSender struct {
 	ctx context.Context
 
-	botPool   BotPool
-	msgClient clients.MessageClient
+	botPool      BotPool
+	msgClient    clients.MessageClient
+	overload     *overloadScheduler
+	payloadStore *PayloadStore
+	recorder     *Recorder
 }
 
-// NewSender creates a new requestSender.
-func NewSender(ctx context.Context, msgClient clients.MessageClient, botPool BotPool) Sender {
+// NewSender creates a new requestSender. The large requests are sent by reference if the payload
+// store is not nil and the requests are recorded if the recorder is not nil.
+func NewSender(
+	ctx context.Context, msgClient clients.MessageClient, botPool BotPool, overloadCfg config.BotOverloadConfig,
+	payloadStore *PayloadStore, recorder *Recorder,
+) Sender {
 	return &requestSender{
-		ctx:       ctx,
-		botPool:   botPool,
-		msgClient: msgClient,
+		ctx:          ctx,
+		botPool:      botPool,
+		msgClient:    msgClient,
+		overload:     newOverloadScheduler(overloadCfg),
+		payloadStore: payloadStore,
+		recorder:     recorder,
 	}
 }
 
@@ -71,6 +91,11 @@ func (rs *requestSender) Health() health.Reports {
 			Status:  health.StatusInfo,
 			Details: strconv.Itoa(fullCount),
 		},
+		&health.Report{
+			Name:    "agents.overloaded",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(rs.overload.ShedBots()),
+		},
 	}
 }
 
@@ -79,10 +104,23 @@ func (rs *requestSender) Name() string {
 	return "sender"
 }
 
+// Drain waits until the bots finish evaluating the requests which were already sent to them.
+func (rs *requestSender) Drain(ctx context.Context) error {
+	return services.WaitIdle(ctx, func() bool {
+		for _, bot := range rs.botPool.GetCurrentBotClients() {
+			if bot.Pending() > 0 {
+				return false
+			}
+		}
+		return true
+	})
+}
+
 // SendEvaluateTxRequest sends the request to all of the active bots which
 // should be processing the block.
-func (rs *requestSender) SendEvaluateTxRequest(req *protocol.EvaluateTxRequest) {
+func (rs *requestSender) SendEvaluateTxRequest(ctx context.Context, req *protocol.EvaluateTxRequest) {
 	startTime := time.Now()
+	spanContext := trace.SpanContextFromContext(ctx)
 	lg := log.WithFields(log.Fields{
 		"tx":        req.Event.Transaction.Hash,
 		"component": "pool",
@@ -93,12 +131,30 @@ func (rs *requestSender) SendEvaluateTxRequest(req *protocol.EvaluateTxRequest)
 
 	bots := rs.botPool.GetCurrentBotClients()
 
+	// the request is marshaled once for all bots and the sender keeps a reference until all
+	// bots receive it
+	encoded := agentgrpc.NewEncodedMessage(req)
+	defer encoded.Release()
+	payloadRef := rs.putPayload(bots, encoded)
+	if payloadRef != nil {
+		payloadRef.Request = txRequestHeader(req)
+	}
+
 	var metricsList []*protocol.AgentMetric
 	for _, bot := range bots {
 		if !bot.ShouldProcessBlock(req.Event.Block.BlockNumber) {
 			continue
 		}
 		botConfig := bot.Config()
+		// only the bots which opt in receive the pending txs
+		if req.Event.Type == TxEventTypePending && !botConfig.MempoolEvents {
+			continue
+		}
+		// do not send the txs which the bot filters out from its manifest
+		if !matchesTxFilter(botConfig.TxFilter, req.Event) {
+			metricsList = append(metricsList, metrics.CreateAgentMetric(botConfig.ID, metrics.MetricTxFiltered, 1))
+			continue
+		}
 
 		lg.WithFields(log.Fields{
 			"bot":      botConfig.ID,
@@ -107,13 +163,21 @@ func (rs *requestSender) SendEvaluateTxRequest(req *protocol.EvaluateTxRequest)
 
 		// unblock req send and discard agent if agent is closed
 
+		encoded.Retain()
 		select {
 		case <-bot.Closed():
+			encoded.Release()
 			lg.WithField("bot", botConfig.ID).Debug("bot is closed - skipping")
 		case bot.TxRequestCh() <- &botreq.TxRequest{
-			Original: req,
+			Original:    req,
+			SpanContext: spanContext,
+			Encoded:     encoded,
+			PayloadRef:  payloadRef,
+			LatestBlock: rs.overload.LatestBlock,
 		}:
+			rs.record(botConfig, agentgrpc.MethodEvaluateTx, req.Event.Block.BlockNumber, encoded.Bytes)
 		default: // do not try to send if the buffer is full
+			encoded.Release()
 			lg.WithField("bot", botConfig.ID).Debug("agent tx request buffer is full - skipping")
 			metricsList = append(metricsList, metrics.CreateAgentMetric(botConfig.ID, metrics.MetricTxDrop, 1))
 		}
@@ -131,8 +195,9 @@ func (rs *requestSender) SendEvaluateTxRequest(req *protocol.EvaluateTxRequest)
 
 // SendEvaluateBlockRequest sends the request to all of the active bots which
 // should be processing the block.
-func (rs *requestSender) SendEvaluateBlockRequest(req *protocol.EvaluateBlockRequest) {
+func (rs *requestSender) SendEvaluateBlockRequest(ctx context.Context, req *protocol.EvaluateBlockRequest) {
 	startTime := time.Now()
+	spanContext := trace.SpanContextFromContext(ctx)
 	lg := log.WithFields(log.Fields{
 		"block":     req.Event.BlockNumber,
 		"component": "pool",
@@ -144,11 +209,25 @@ func (rs *requestSender) SendEvaluateBlockRequest(req *protocol.EvaluateBlockReq
 	bots := rs.botPool.GetCurrentBotClients()
 
 	var metricsList []*protocol.AgentMetric
+	// make room for the newest block if the bots are not keeping up with the chain
+	if req.Event.Type != protocol.BlockEvent_REORG && rs.overload.ObserveBlock(req.Event.Block) {
+		metricsList = append(metricsList, rs.overload.Shed(bots)...)
+	}
+	encoded := agentgrpc.NewEncodedMessage(req)
+	defer encoded.Release()
+	payloadRef := rs.putPayload(bots, encoded)
+	if payloadRef != nil {
+		payloadRef.Request = blockRequestHeader(req)
+	}
 	for _, bot := range bots {
 		if !bot.ShouldProcessBlock(req.Event.BlockNumber) {
 			continue
 		}
 		botConfig := bot.Config()
+		// only the bots which opt in receive the reorg events
+		if req.Event.Type == protocol.BlockEvent_REORG && !botConfig.ReorgEvents {
+			continue
+		}
 
 		lg.WithFields(log.Fields{
 			"bot":      botConfig.ID,
@@ -156,13 +235,21 @@ func (rs *requestSender) SendEvaluateBlockRequest(req *protocol.EvaluateBlockReq
 		}).Debug("sending block request to evalBlockCh")
 
 		// unblock req send if agent is closed
+		encoded.Retain()
 		select {
 		case <-bot.Closed():
+			encoded.Release()
 			lg.WithField("bot", botConfig.ID).Debug("bot is closed - skipping")
 		case bot.BlockRequestCh() <- &botreq.BlockRequest{
-			Original: req,
+			Original:    req,
+			SpanContext: spanContext,
+			Encoded:     encoded,
+			PayloadRef:  payloadRef,
+			LatestBlock: rs.overload.LatestBlock,
 		}:
+			rs.record(botConfig, agentgrpc.MethodEvaluateBlock, req.Event.BlockNumber, encoded.Bytes)
 		default: // do not try to send if the buffer is full
+			encoded.Release()
 			lg.WithField("bot", botConfig.ID).Warn("agent block request buffer is full - skipping")
 			metricsList = append(metricsList, metrics.CreateAgentMetric(botConfig.ID, metrics.MetricBlockDrop, 1))
 		}
@@ -174,10 +261,13 @@ func (rs *requestSender) SendEvaluateBlockRequest(req *protocol.EvaluateBlockReq
 		).Debug("sent tx request to evalBlockCh")
 	}
 
-	blockNumber, _ := hexutil.DecodeUint64(req.Event.BlockNumber)
-	rs.msgClient.Publish(messaging.SubjectScannerBlock, &messaging.ScannerPayload{
-		LatestBlockInput: blockNumber,
-	})
+	// the reorg events are for the past blocks
+	if req.Event.Type != protocol.BlockEvent_REORG {
+		blockNumber, _ := hexutil.DecodeUint64(req.Event.BlockNumber)
+		rs.msgClient.Publish(messaging.SubjectScannerBlock, &messaging.ScannerPayload{
+			LatestBlockInput: blockNumber,
+		})
+	}
 
 	metrics.SendAgentMetrics(rs.msgClient, metricsList)
 	lg.WithFields(log.Fields{
@@ -185,10 +275,55 @@ func (rs *requestSender) SendEvaluateBlockRequest(req *protocol.EvaluateBlockReq
 	}).Debug("Finished SendEvaluateBlockRequest")
 }
 
+// putPayload writes the large request to the payload store if any of the bots can fetch it
+// by reference.
+func (rs *requestSender) putPayload(bots []BotClient, encoded *agentgrpc.EncodedMessage) *agentgrpc.PayloadRef {
+	if rs.payloadStore == nil {
+		return nil
+	}
+	var supported bool
+	for _, bot := range bots {
+		botConfig := bot.Config()
+		if botConfig.SupportsPayloadRefs() {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil
+	}
+	b, err := encoded.Bytes()
+	if err != nil || len(b) < rs.payloadStore.MinSize() {
+		return nil
+	}
+	payloadRef, ok := rs.payloadStore.Put(b)
+	if !ok {
+		return nil
+	}
+	return payloadRef
+}
+
+// record records the request if the bot is selected for recording.
+func (rs *requestSender) record(
+	botConfig config.AgentConfig, method agentgrpc.Method, blockNumberHex string, encode func() ([]byte, error),
+) {
+	blockNumber, _ := hexutil.DecodeUint64(blockNumberHex)
+	if !rs.recorder.ShouldRecord(botConfig.ID, blockNumber) {
+		return
+	}
+	b, err := encode()
+	if err != nil {
+		log.WithError(err).WithField("bot", botConfig.ID).Warn("failed to encode the request to record")
+		return
+	}
+	rs.recorder.Record(botConfig, method, blockNumber, b)
+}
+
 // SendEvaluateAlertRequest sends the request to all the active bots which
 // should be processing the alert.
-func (rs *requestSender) SendEvaluateAlertRequest(req *protocol.EvaluateAlertRequest) {
+func (rs *requestSender) SendEvaluateAlertRequest(ctx context.Context, req *protocol.EvaluateAlertRequest) {
 	startTime := time.Now()
+	spanContext := trace.SpanContextFromContext(ctx)
 	lg := log.WithFields(
 		log.Fields{
 			"target":    req.TargetBotId,
@@ -245,8 +380,13 @@ func (rs *requestSender) SendEvaluateAlertRequest(req *protocol.EvaluateAlertReq
 	case <-target.Closed():
 		lg.WithField("bot", botConfig.ID).Debug("bot is closed - skipping")
 	case target.CombinationRequestCh() <- &botreq.CombinationRequest{
-		Original: req,
+		Original:    req,
+		SpanContext: spanContext,
 	}:
+		rs.record(
+			botConfig, agentgrpc.MethodEvaluateAlert, hexutil.EncodeUint64(req.Event.Alert.Source.Block.GetNumber()),
+			func() ([]byte, error) { return proto.Marshal(req) },
+		)
 	default: // do not try to send if the buffer is full
 		lg.WithField("bot", botConfig.ID).Warn("agent alert request buffer is full - skipping")
 		metricsList = append(metricsList, metrics.CreateAgentMetric(botConfig.ID, metrics.MetricCombinerDrop, 1))
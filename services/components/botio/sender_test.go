@@ -3,6 +3,7 @@ package botio_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/clients/messaging"
@@ -11,6 +12,7 @@ import (
 	"github.com/forta-network/forta-node/services/components/botio"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/forta-network/forta-node/services/components/metrics"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -42,7 +44,7 @@ func (s *SenderTestSuite) SetupTest() {
 
 	s.botPool.EXPECT().GetCurrentBotClients().Return([]botio.BotClient{s.botClient}).AnyTimes()
 
-	s.sender = botio.NewSender(context.Background(), s.msgClient, s.botPool)
+	s.sender = botio.NewSender(context.Background(), s.msgClient, s.botPool, config.BotOverloadConfig{MaxBacklogBlocks: 5}, nil, nil)
 }
 
 func (s *SenderTestSuite) TestHealth() {
@@ -52,6 +54,19 @@ func (s *SenderTestSuite) TestHealth() {
 	s.r.Equal("agents.lagging", reports[1].Name)
 }
 
+func (s *SenderTestSuite) TestDrain() {
+	gomock.InOrder(
+		s.botClient.EXPECT().Pending().Return(1),
+		s.botClient.EXPECT().Pending().Return(0).Times(2),
+	)
+	s.r.NoError(s.sender.Drain(context.Background()))
+
+	s.botClient.EXPECT().Pending().Return(1).AnyTimes()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*300)
+	defer cancel()
+	s.r.ErrorIs(s.sender.Drain(ctx), context.DeadlineExceeded)
+}
+
 func (s *SenderTestSuite) TestSendEvaluateTxRequest() {
 	s.botPool.EXPECT().WaitForAll().Times(1)
 	s.botClient.EXPECT().ShouldProcessBlock(gomock.Any()).Return(true)
@@ -59,7 +74,7 @@ func (s *SenderTestSuite) TestSendEvaluateTxRequest() {
 	s.botClient.EXPECT().Closed().Return(make(chan struct{}))
 	s.botClient.EXPECT().TxRequestCh().Return(make(chan *botreq.TxRequest, 1))
 
-	s.sender.SendEvaluateTxRequest(&protocol.EvaluateTxRequest{
+	s.sender.SendEvaluateTxRequest(context.Background(), &protocol.EvaluateTxRequest{
 		Event: &protocol.TransactionEvent{
 			Transaction: &protocol.TransactionEvent_EthTransaction{
 				Hash: "0x1",
@@ -71,6 +86,31 @@ func (s *SenderTestSuite) TestSendEvaluateTxRequest() {
 	})
 }
 
+func (s *SenderTestSuite) TestSendEvaluateTxRequest_PendingTx() {
+	req := &protocol.EvaluateTxRequest{
+		Event: &protocol.TransactionEvent{
+			Type: botio.TxEventTypePending,
+			Transaction: &protocol.TransactionEvent_EthTransaction{
+				Hash: "0x1",
+			},
+			Block: &protocol.TransactionEvent_EthBlock{
+				BlockNumber: "0x2",
+			},
+		},
+	}
+
+	// skipped if the bot did not opt in
+	s.botPool.EXPECT().WaitForAll().Times(2)
+	s.botClient.EXPECT().ShouldProcessBlock(gomock.Any()).Return(true).Times(2)
+	s.botClient.EXPECT().Config().Return(config.AgentConfig{})
+	s.sender.SendEvaluateTxRequest(context.Background(), req)
+
+	s.botClient.EXPECT().Config().Return(config.AgentConfig{MempoolEvents: true})
+	s.botClient.EXPECT().Closed().Return(make(chan struct{}))
+	s.botClient.EXPECT().TxRequestCh().Return(make(chan *botreq.TxRequest, 1))
+	s.sender.SendEvaluateTxRequest(context.Background(), req)
+}
+
 func (s *SenderTestSuite) TestSendEvaluateBlockRequest() {
 	s.botPool.EXPECT().WaitForAll().Times(1)
 	s.botClient.EXPECT().ShouldProcessBlock(gomock.Any()).Return(true)
@@ -79,7 +119,7 @@ func (s *SenderTestSuite) TestSendEvaluateBlockRequest() {
 	s.botClient.EXPECT().BlockRequestCh().Return(make(chan *botreq.BlockRequest, 1))
 	s.msgClient.EXPECT().Publish(messaging.SubjectScannerBlock, gomock.Any())
 
-	s.sender.SendEvaluateBlockRequest(&protocol.EvaluateBlockRequest{
+	s.sender.SendEvaluateBlockRequest(context.Background(), &protocol.EvaluateBlockRequest{
 		Event: &protocol.BlockEvent{
 			Block: &protocol.BlockEvent_EthBlock{
 				Number: "0x1",
@@ -88,6 +128,47 @@ func (s *SenderTestSuite) TestSendEvaluateBlockRequest() {
 	})
 }
 
+func (s *SenderTestSuite) TestSendEvaluateBlockRequest_Overload() {
+	s.botPool.EXPECT().WaitForAll().Times(2)
+	s.botClient.EXPECT().ShouldProcessBlock(gomock.Any()).Return(true).Times(2)
+	s.botClient.EXPECT().Config().Return(config.AgentConfig{ID: "0xbot", BestEffort: true}).AnyTimes()
+	s.botClient.EXPECT().Closed().Return(make(chan struct{})).Times(2)
+	s.botClient.EXPECT().BlockRequestCh().Return(make(chan *botreq.BlockRequest, 2)).Times(2)
+	s.msgClient.EXPECT().Publish(messaging.SubjectScannerBlock, gomock.Any()).Times(2)
+
+	// the first block does not tell the block interval
+	s.sender.SendEvaluateBlockRequest(context.Background(), &protocol.EvaluateBlockRequest{
+		Event: &protocol.BlockEvent{
+			BlockNumber: "0x1",
+			Block:       &protocol.BlockEvent_EthBlock{Number: "0x1", Timestamp: "0x0"},
+		},
+	})
+
+	// the best-effort bot is shed down to a single block interval under pressure
+	s.botClient.EXPECT().Backlog().Return(time.Second * 30)
+	s.botClient.EXPECT().ShedRequests(time.Second*12).Return(10, 1)
+	var sent *protocol.AgentMetricList
+	s.msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any()).Do(
+		func(subject string, payload *protocol.AgentMetricList) {
+			sent = payload
+		},
+	)
+	s.sender.SendEvaluateBlockRequest(context.Background(), &protocol.EvaluateBlockRequest{
+		Event: &protocol.BlockEvent{
+			BlockNumber: "0x2",
+			Block:       &protocol.BlockEvent_EthBlock{Number: "0x2", Timestamp: "0xc"},
+		},
+	})
+	s.r.Len(sent.Metrics, 2)
+	s.r.Equal(metrics.MetricTxOverload, sent.Metrics[0].Name)
+	s.r.Equal(float64(10), sent.Metrics[0].Value)
+	s.r.Equal(metrics.MetricBlockOverload, sent.Metrics[1].Name)
+	s.r.Equal(float64(1), sent.Metrics[1].Value)
+
+	s.botClient.EXPECT().TxBufferIsFull().Return(false)
+	s.r.Equal("1", s.sender.Health()[2].Details)
+}
+
 func (s *SenderTestSuite) TestSendEvaluateAlertRequest() {
 	s.botPool.EXPECT().WaitForAll().Times(1)
 	s.botClient.EXPECT().ShouldProcessAlert(gomock.Any()).Return(true)
@@ -96,7 +177,7 @@ func (s *SenderTestSuite) TestSendEvaluateAlertRequest() {
 	s.botClient.EXPECT().CombinationRequestCh().Return(make(chan *botreq.CombinationRequest, 1))
 	s.msgClient.EXPECT().Publish(messaging.SubjectScannerAlert, gomock.Any())
 
-	s.sender.SendEvaluateAlertRequest(&protocol.EvaluateAlertRequest{
+	s.sender.SendEvaluateAlertRequest(context.Background(), &protocol.EvaluateAlertRequest{
 		Event: &protocol.AlertEvent{
 			Alert: &protocol.AlertEvent_Alert{
 				Source: &protocol.AlertEvent_Alert_Source{
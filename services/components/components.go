@@ -3,12 +3,16 @@ package components
 import (
 	"context"
 	"fmt"
+	"path"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/audit"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/agentgrpc"
 	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/botio"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
@@ -29,6 +33,8 @@ type BotProcessingConfig struct {
 type BotProcessing struct {
 	RequestSender botio.Sender
 	Results       botreq.ReceiveOnlyChannels
+	// PayloadStore is nil if the payload references are disabled.
+	PayloadStore *botio.PayloadStore
 }
 
 // GetBotProcessingComponents returns the bot processing components after doing dependency injection.
@@ -37,7 +43,7 @@ func GetBotProcessingComponents(ctx context.Context, botProcCfg BotProcessingCon
 	lifecycleMetrics := metrics.NewLifecycleClient(botProcCfg.MessageClient)
 	botClientFactory := botio.NewBotClientFactory(
 		resultChannels.SendOnly(), botProcCfg.MessageClient,
-		lifecycleMetrics, agentgrpc.NewBotDialer(),
+		lifecycleMetrics, agentgrpc.NewBotDialer(botProcCfg.Config.AgentGrpc), botProcCfg.Config.AgentGrpc,
 	)
 	botPool := lifecycle.NewBotPool(
 		ctx, lifecycleMetrics, botClientFactory, botProcCfg.Config.BotsToWait(),
@@ -59,10 +65,31 @@ func GetBotProcessingComponents(ctx context.Context, botProcCfg BotProcessingCon
 		}
 	}
 
-	sender := botio.NewSender(ctx, botProcCfg.MessageClient, botPool)
+	// there is no supervisor to update the bot pool in the development mode
+	if botProcCfg.Config.Dev.Enable {
+		bots := botProcCfg.Config.Dev.AgentConfigs(botProcCfg.Config.ChainID)
+		if err := botPool.UpdateBotsWithLatestConfigs(bots); err != nil {
+			return BotProcessing{}, fmt.Errorf("failed to update the development mode bot pool: %v", err)
+		}
+	}
+
+	var payloadStore *botio.PayloadStore
+	if botProcCfg.Config.AgentGrpc.PayloadRefs.Enable {
+		payloadStore = botio.NewPayloadStore(botProcCfg.Config.AgentGrpc.PayloadRefs, botProcCfg.Config.Orchestrator)
+	}
+	var recorder *botio.Recorder
+	if botProcCfg.Config.BotRecording.Enable {
+		recorder = botio.NewRecorder(
+			path.Join(botProcCfg.Config.FortaDir, config.DefaultRecordingsDirName), botProcCfg.Config.BotRecording,
+		)
+	}
+	sender := botio.NewSender(
+		ctx, botProcCfg.MessageClient, botPool, botProcCfg.Config.AgentGrpc.Overload, payloadStore, recorder,
+	)
 	return BotProcessing{
 		RequestSender: sender,
 		Results:       resultChannels.ReceiveOnly(),
+		PayloadStore:  payloadStore,
 	}, nil
 }
 
@@ -70,14 +97,19 @@ func GetBotProcessingComponents(ctx context.Context, botProcCfg BotProcessingCon
 type BotLifecycleConfig struct {
 	Config         config.Config
 	ScannerAddress common.Address
+	Signer         signer.Signer
 	MessageClient  clients.MessageClient
 	BotRegistry    registry.BotRegistry
+	// AuditLog records the bot lifecycle actions if it is not nil.
+	AuditLog *audit.Log
 }
 
 // BotLifecycle contains the bot lifecycle components.
 type BotLifecycle struct {
 	BotManager lifecycle.BotLifecycleManager
 	BotClient  containers.BotClient
+	// BotImagePrefetcher is nil if the prefetching is disabled.
+	BotImagePrefetcher lifecycle.BotImagePrefetcher
 }
 
 // GetBotLifecycleComponents returns the bot lifecycle management components.
@@ -100,16 +132,29 @@ func GetBotLifecycleComponents(ctx context.Context, botLifeConfig BotLifecycleCo
 	if err != nil {
 		return BotLifecycle{}, fmt.Errorf("failed to create the bot image docker client: %v", err)
 	}
+	botImageClient.SetImagePullProgress(cfg.ImagePull, func(progress docker.ImagePullProgress) {
+		metrics.SendAgentMetrics(botLifeConfig.MessageClient, metrics.GetImagePullMetrics(time.Now(), progress))
+	})
 
 	dockerClient, err := docker.NewDockerClient(containers.LabelFortaSupervisor)
 	if err != nil {
 		return BotLifecycle{}, fmt.Errorf("failed to create the bot docker client: %v", err)
 	}
 
-	botClient := containers.NewBotClient(
-		botLifeConfig.Config.Log, botLifeConfig.Config.ResourcesConfig,
-		dockerClient, botImageClient,
+	// the bots receive signed tokens only if the services expect them
+	var botTokenSigner signer.Signer
+	if botLifeConfig.Config.BotAuth.Enable {
+		botTokenSigner = botLifeConfig.Signer
+	}
+	var botClient containers.BotClient = containers.NewBotClient(
+		botLifeConfig.Config.Log, botLifeConfig.Config.ResourcesConfig, botLifeConfig.Config.JsonRpcProxy,
+		botLifeConfig.Config.Orchestrator, botLifeConfig.Config.BotEgress,
+		containers.NewBotSecretStore(botLifeConfig.Config.BotSecrets, botLifeConfig.Config.FortaDir),
+		dockerClient, botImageClient, botTokenSigner,
 	)
+	if botLifeConfig.AuditLog != nil {
+		botClient = containers.NewAuditedBotClient(botClient, dockerClient, botLifeConfig.AuditLog)
+	}
 	lifecycleMetrics := metrics.NewLifecycleClient(botLifeConfig.MessageClient)
 	lifecycleMediator := mediator.New(botLifeConfig.MessageClient, lifecycleMetrics)
 	botMonitor := lifecycle.NewBotMonitor(lifecycleMetrics)
@@ -119,8 +164,16 @@ func GetBotLifecycleComponents(ctx context.Context, botLifeConfig BotLifecycleCo
 		lifecycleMetrics, botMonitor,
 	)
 
+	var botImagePrefetcher lifecycle.BotImagePrefetcher
+	if cfg.BotPrefetch.Enable {
+		botImagePrefetcher = lifecycle.NewBotImagePrefetcher(
+			cfg.BotPrefetch, botLifeConfig.BotRegistry, botManager, botImageClient,
+		)
+	}
+
 	return BotLifecycle{
-		BotManager: botManager,
-		BotClient:  botClient,
+		BotManager:         botManager,
+		BotClient:          botClient,
+		BotImagePrefetcher: botImagePrefetcher,
 	}, nil
 }
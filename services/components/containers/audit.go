@@ -0,0 +1,101 @@
+package containers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/forta-network/forta-node/audit"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+type auditedBotClient struct {
+	BotClient
+	client   clients.DockerClient
+	auditLog *audit.Log
+}
+
+// NewAuditedBotClient records the bot launches, teardowns and stops with the image digests
+// to the audit log.
+func NewAuditedBotClient(botClient BotClient, client clients.DockerClient, auditLog *audit.Log) BotClient {
+	return &auditedBotClient{
+		BotClient: botClient,
+		client:    client,
+		auditLog:  auditLog,
+	}
+}
+
+// LaunchBot launches the bot and records it.
+func (abc *auditedBotClient) LaunchBot(ctx context.Context, botConfig config.AgentConfig) error {
+	err := abc.BotClient.LaunchBot(ctx, botConfig)
+	details := map[string]string{
+		"image":     botConfig.Image,
+		"digest":    imageDigest(botConfig.Image),
+		"container": botConfig.ContainerName(),
+	}
+	if err == nil {
+		abc.addContainerDetails(ctx, botConfig.ContainerName(), details)
+	}
+	abc.record(audit.ActionBotLaunch, botConfig.ID, details, err)
+	return err
+}
+
+// TearDownBot tears down the bot and records it.
+func (abc *auditedBotClient) TearDownBot(ctx context.Context, containerName string, removeImage bool) error {
+	details := map[string]string{
+		"container":   containerName,
+		"removeImage": strconv.FormatBool(removeImage),
+	}
+	botID := abc.addContainerDetails(ctx, containerName, details)
+	err := abc.BotClient.TearDownBot(ctx, containerName, removeImage)
+	abc.record(audit.ActionBotTeardown, botID, details, err)
+	return err
+}
+
+// StopBot stops the bot and records it.
+func (abc *auditedBotClient) StopBot(ctx context.Context, botConfig config.AgentConfig) error {
+	details := map[string]string{
+		"image":     botConfig.Image,
+		"digest":    imageDigest(botConfig.Image),
+		"container": botConfig.ContainerName(),
+	}
+	err := abc.BotClient.StopBot(ctx, botConfig)
+	abc.record(audit.ActionBotStop, botConfig.ID, details, err)
+	return err
+}
+
+// addContainerDetails adds the container and the local image IDs and returns the bot ID from the labels.
+func (abc *auditedBotClient) addContainerDetails(ctx context.Context, containerName string, details map[string]string) string {
+	container, err := abc.client.GetContainerByName(ctx, containerName)
+	if err != nil || container == nil {
+		return ""
+	}
+	details["containerId"] = container.ID
+	details["imageId"] = container.ImageID
+	if _, ok := details["image"]; !ok {
+		details["image"] = container.Image
+		details["digest"] = imageDigest(container.Image)
+	}
+	return container.Labels[docker.LabelFortaBotID]
+}
+
+func (abc *auditedBotClient) record(action, botID string, details map[string]string, err error) {
+	if err != nil {
+		details["error"] = err.Error()
+	}
+	if err := abc.auditLog.Record(action, botID, details); err != nil {
+		log.WithError(err).WithField("action", action).Error("failed to record to the audit log")
+	}
+}
+
+// imageDigest returns the digest of the image reference if it is pinned to a digest.
+func imageDigest(image string) string {
+	parts := strings.SplitN(image, "@", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[1]
+}
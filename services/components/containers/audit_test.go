@@ -0,0 +1,65 @@
+package containers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/audit"
+	"github.com/forta-network/forta-node/clients/docker"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	mock_containers "github.com/forta-network/forta-node/services/components/containers/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditedBotClient(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	botClient := mock_containers.NewMockBotClient(ctrl)
+	client := mock_clients.NewMockDockerClient(ctrl)
+
+	logPath := path.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.Open(logPath, 1, 0)
+	r.NoError(err)
+
+	abc := NewAuditedBotClient(botClient, client, auditLog)
+	botConfig := config.AgentConfig{ID: testBotID1, Image: testImageRef}
+	container := &types.Container{
+		ID:      testContainerID,
+		Image:   testImageRef,
+		ImageID: "sha256:local",
+		Labels:  map[string]string{docker.LabelFortaBotID: testBotID1},
+	}
+
+	botClient.EXPECT().LaunchBot(gomock.Any(), botConfig).Return(nil)
+	client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(container, nil).Times(2)
+	r.NoError(abc.LaunchBot(context.Background(), botConfig))
+
+	botClient.EXPECT().TearDownBot(gomock.Any(), botConfig.ContainerName(), true).Return(errors.New("failed"))
+	r.Error(abc.TearDownBot(context.Background(), botConfig.ContainerName(), true))
+	r.NoError(auditLog.Close())
+
+	var buf bytes.Buffer
+	count, err := audit.Export(logPath, &buf)
+	r.NoError(err)
+	r.Equal(2, count)
+	var launch, teardown audit.Entry
+	dec := json.NewDecoder(&buf)
+	r.NoError(dec.Decode(&launch))
+	r.NoError(dec.Decode(&teardown))
+
+	r.Equal(audit.ActionBotLaunch, launch.Action)
+	r.Equal(testBotID1, launch.BotID)
+	r.Equal("sha256:e0e9efb6699b02750f6a9668084d37314f1de3a80da7e19c1d40da73ee57dd45", launch.Details["digest"])
+	r.Equal("sha256:local", launch.Details["imageId"])
+	r.Equal(audit.ActionBotTeardown, teardown.Action)
+	r.Equal(testBotID1, teardown.BotID)
+	r.Equal("failed", teardown.Details["error"])
+}
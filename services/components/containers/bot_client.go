@@ -9,6 +9,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/config"
 	log "github.com/sirupsen/logrus"
 )
@@ -22,12 +23,16 @@ const (
 	ImagePullCooldownDuration  = time.Minute * 10
 )
 
+// ErrContainerNameCollision is returned when the bot container name is used by another node instance.
+var ErrContainerNameCollision = errors.New("bot container name collision")
+
 // BotClient launches a bot.
 type BotClient interface {
 	EnsureBotImages(ctx context.Context, botConfigs []config.AgentConfig) []error
 	LaunchBot(ctx context.Context, botConfig config.AgentConfig) error
 	TearDownBot(ctx context.Context, containerName string, removeImage bool) error
 	StopBot(ctx context.Context, botConfig config.AgentConfig) error
+	GetBotExit(ctx context.Context, containerName string) (*docker.ContainerExit, error)
 	LoadBotContainers(ctx context.Context) ([]types.Container, error)
 	StartWaitBotContainer(ctx context.Context, containerID string) error
 }
@@ -35,21 +40,34 @@ type BotClient interface {
 type botClient struct {
 	logConfig       config.LogConfig
 	resourcesConfig config.ResourcesConfig
+	jsonRpcProxyCfg config.JsonRpcProxyConfig
+	orchestratorCfg config.OrchestratorConfig
+	egressCfg       config.BotEgressConfig
+	secretStore     *BotSecretStore
 	client          clients.DockerClient
 	botImageClient  clients.DockerClient
+	botTokenSigner  signer.Signer
 }
 
-// NewBotClient creates a new bot client to manage bot containers.
+// NewBotClient creates a new bot client to manage bot containers. The bots receive signed
+// tokens to authenticate with if the token signer is not nil and the operator's secrets if the
+// secret store is not nil.
 func NewBotClient(
-	logConfig config.LogConfig, resourcesConfig config.ResourcesConfig,
-	client clients.DockerClient, botImageClient clients.DockerClient,
+	logConfig config.LogConfig, resourcesConfig config.ResourcesConfig, jsonRpcProxyCfg config.JsonRpcProxyConfig,
+	orchestratorCfg config.OrchestratorConfig, egressCfg config.BotEgressConfig, secretStore *BotSecretStore,
+	client clients.DockerClient, botImageClient clients.DockerClient, botTokenSigner signer.Signer,
 ) *botClient {
 	botImageClient.SetImagePullCooldown(ImagePullCooldownThreshold, ImagePullCooldownDuration)
 	return &botClient{
 		logConfig:       logConfig,
 		resourcesConfig: resourcesConfig,
+		jsonRpcProxyCfg: jsonRpcProxyCfg,
+		orchestratorCfg: orchestratorCfg,
+		egressCfg:       egressCfg,
+		secretStore:     secretStore,
 		client:          client,
 		botImageClient:  botImageClient,
+		botTokenSigner:  botTokenSigner,
 	}
 }
 
@@ -63,6 +81,12 @@ func (bc *botClient) EnsureBotImages(ctx context.Context, botConfigs []config.Ag
 			Name: botConfig.ID,
 			Ref:  botConfig.Image,
 		})
+		for _, dep := range botConfig.Dependencies {
+			imagePulls = append(imagePulls, docker.ImagePull{
+				Name: botConfig.DependencyContainerName(dep),
+				Ref:  dep.Image,
+			})
+		}
 	}
 	return bc.botImageClient.EnsureLocalImages(ctx, BotPullTimeout, imagePulls)
 }
@@ -75,26 +99,55 @@ func (bc *botClient) LaunchBot(ctx context.Context, botConfig config.AgentConfig
 	defer cancel()
 
 	// first make sure that the bot's bridge network exists
-	botNetworkID, err := bc.client.EnsurePublicNetwork(ctx, botConfig.ContainerName())
+	botNetworkID, err := bc.ensureBotNetwork(ctx, botConfig)
 	if err != nil {
-		return fmt.Errorf("error creating public network: %v", err)
+		return fmt.Errorf("error creating bot network: %v", err)
 	}
 
-	_, err = bc.client.GetContainerByName(ctx, botConfig.ContainerName())
+	// the dependencies should be up before the bot starts
+	if err := bc.launchBotDependencies(ctx, botNetworkID, botConfig); err != nil {
+		return err
+	}
+
+	container, err := bc.client.GetContainerByName(ctx, botConfig.ContainerName())
+	if err == nil && container != nil && !isOwnContainer(container) {
+		err = bc.resolveNameCollision(ctx, container)
+	}
 	switch {
 	case err == nil:
 		// do not create a new container - we already have it
 
 	case errors.Is(err, docker.ErrContainerNotFound):
 		// if the bot container doesn't exist, create and start the container
-		botContainerCfg := NewBotContainerConfig(botNetworkID, botConfig, bc.logConfig, bc.resourcesConfig)
+		botContainerCfg := NewBotContainerConfig(botNetworkID, botConfig, bc.jsonRpcProxyCfg, bc.logConfig, bc.resourcesConfig)
+		setServiceHosts(botContainerCfg.Env, bc.orchestratorCfg)
+		setEgressProxy(botContainerCfg.Env, botConfig, bc.egressCfg, bc.orchestratorCfg)
+		setBotGateway(botContainerCfg.Env, botConfig, bc.egressCfg, bc.orchestratorCfg)
+		botContainerCfg.Labels[docker.LabelFortaSupervisorStrategyVersion] = StrategyVersion(bc.egressCfg)
+		if bc.botTokenSigner != nil {
+			token, err := clients.CreateBotToken(bc.botTokenSigner, botConfig)
+			if err != nil {
+				return fmt.Errorf("failed to create the bot token: %v", err)
+			}
+			botContainerCfg.Env[config.EnvFortaBotToken] = token
+		}
+		if bc.secretStore != nil {
+			if err := bc.secretStore.Apply(botConfig, &botContainerCfg); err != nil {
+				return fmt.Errorf("failed to deliver the bot secrets: %v", err)
+			}
+		}
 		_, err = bc.client.StartContainer(ctx, botContainerCfg)
 		if err != nil {
 			return fmt.Errorf("failed to start bot container: %v", err)
 		}
 
 	default:
-		return fmt.Errorf("unexpected error while getting the bot container '%s': %v", botConfig.ContainerName(), err)
+		return fmt.Errorf("unexpected error while getting the bot container '%s': %w", botConfig.ContainerName(), err)
+	}
+
+	// the orchestrator connects the services to the bot network
+	if bc.orchestratorCfg.Enable {
+		return nil
 	}
 
 	// at this point we have created a new bot container and a new bridge network for the bot
@@ -103,6 +156,63 @@ func (bc *botClient) LaunchBot(ctx context.Context, botConfig config.AgentConfig
 	return bc.attachServiceContainers(ctx, botNetworkID)
 }
 
+// isOwnContainer tells if the container belongs to the node instance.
+func isOwnContainer(container *types.Container) bool {
+	return container.Labels[docker.LabelFortaNodeInstance] == config.ContainerNamingInstance()
+}
+
+// resolveNameCollision removes the stopped container of another node instance which has the same name
+// so that the bot container can be created. The running ones are not touched.
+func (bc *botClient) resolveNameCollision(ctx context.Context, container *types.Container) error {
+	logger := log.WithFields(log.Fields{
+		"containerId":   container.ID,
+		"containerName": docker.GetContainerName(*container),
+		"instance":      container.Labels[docker.LabelFortaNodeInstance],
+	})
+	if container.State == "running" {
+		logger.Error("bot container name is used by a running container of another node instance")
+		return fmt.Errorf("%w: set a different containerNaming.instance for each node on the host", ErrContainerNameCollision)
+	}
+	logger.Warn("removing the leftover container of another node instance with the same name")
+	if err := bc.client.RemoveContainer(ctx, container.ID); err != nil {
+		return fmt.Errorf("failed to remove the leftover container with the same name: %v", err)
+	}
+	return docker.ErrContainerNotFound
+}
+
+// ensureBotNetwork creates the bot network. The bot networks are internal if the egress policy
+// is enabled so that the bots can reach only the node services and the egress proxy.
+func (bc *botClient) ensureBotNetwork(ctx context.Context, botConfig config.AgentConfig) (string, error) {
+	if bc.egressCfg.Enable {
+		return bc.client.EnsureInternalNetwork(ctx, bc.botNetworkName(botConfig))
+	}
+	return bc.client.EnsurePublicNetwork(ctx, bc.botNetworkName(botConfig))
+}
+
+// all bots share the same network when the services are run by an orchestrator
+func (bc *botClient) botNetworkName(botConfig config.AgentConfig) string {
+	if bc.orchestratorCfg.Enable {
+		return bc.orchestratorCfg.BotNetwork
+	}
+	return botConfig.ContainerName()
+}
+
+func (bc *botClient) launchBotDependencies(ctx context.Context, botNetworkID string, botConfig config.AgentConfig) error {
+	for _, dep := range botConfig.Dependencies {
+		depContainerCfg := NewBotDependencyContainerConfig(botNetworkID, botConfig, dep, bc.logConfig, bc.resourcesConfig)
+		depContainerCfg.Labels[docker.LabelFortaSupervisorStrategyVersion] = StrategyVersion(bc.egressCfg)
+		// this starts the container if it exists but is not running
+		depContainer, err := bc.client.StartContainer(ctx, depContainerCfg)
+		if err != nil {
+			return fmt.Errorf("failed to start bot dependency '%s': %v", dep.Name, err)
+		}
+		if err := bc.client.WaitContainerStart(ctx, depContainer.ID); err != nil {
+			return fmt.Errorf("failed while waiting for bot dependency '%s' to start: %v", dep.Name, err)
+		}
+	}
+	return nil
+}
+
 func (bc *botClient) attachServiceContainers(ctx context.Context, botNetworkID string) error {
 	serviceContainerIDs, err := bc.getServiceContainerIDs(ctx)
 	if err != nil {
@@ -144,9 +254,12 @@ func (bc *botClient) TearDownBot(ctx context.Context, containerName string, remo
 	if err != nil {
 		return fmt.Errorf("failed to get the bot container to tear down: %v", err)
 	}
-	serviceContainerIDs, err := bc.getServiceContainerIDs(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get service container ids during bot cleanup: %v", err)
+	var serviceContainerIDs []string
+	if !bc.orchestratorCfg.Enable {
+		serviceContainerIDs, err = bc.getServiceContainerIDs(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get service container ids during bot cleanup: %v", err)
+		}
 	}
 	defer log.WithField("botContainer", containerName).Info("done tearing down the bot and the associated docker resources")
 	// not returning any errors in `if`s below so we keep on by removing whatever is left
@@ -164,10 +277,17 @@ func (bc *botClient) TearDownBot(ctx context.Context, containerName string, remo
 			"containerName": containerName,
 		}).WithError(err).Warn("failed to destroy the bot container")
 	}
-	if err := bc.client.RemoveNetworkByName(ctx, containerName); err != nil {
-		log.WithFields(log.Fields{
-			"network": containerName,
-		}).WithError(err).Warn("failed to destroy the bot network")
+	bc.removeBotDependencies(ctx, containerName)
+	if bc.secretStore != nil {
+		bc.secretStore.Remove(containerName)
+	}
+	// the shared bot network is kept for the other bots
+	if !bc.orchestratorCfg.Enable {
+		if err := bc.client.RemoveNetworkByName(ctx, containerName); err != nil {
+			log.WithFields(log.Fields{
+				"network": containerName,
+			}).WithError(err).Warn("failed to destroy the bot network")
+		}
 	}
 	if !removeImage {
 		return nil
@@ -180,6 +300,22 @@ func (bc *botClient) TearDownBot(ctx context.Context, containerName string, remo
 	return nil
 }
 
+func (bc *botClient) removeBotDependencies(ctx context.Context, botContainerName string) {
+	depContainers, err := bc.client.GetContainersByLabel(ctx, docker.LabelFortaBotDependencyOf, botContainerName)
+	if err != nil {
+		log.WithField("botContainer", botContainerName).WithError(err).Warn("failed to get the bot dependency containers")
+		return
+	}
+	for _, depContainer := range depContainers {
+		if err := bc.client.RemoveContainer(ctx, depContainer.ID); err != nil {
+			log.WithFields(log.Fields{
+				"containerId":  depContainer.ID,
+				"botContainer": botContainerName,
+			}).WithError(err).Warn("failed to destroy the bot dependency container")
+		}
+	}
+}
+
 // StopBot shuts down a bot container.
 func (bc *botClient) StopBot(ctx context.Context, botConfig config.AgentConfig) error {
 	container, err := bc.client.GetContainerByName(ctx, botConfig.ContainerName())
@@ -192,9 +328,29 @@ func (bc *botClient) StopBot(ctx context.Context, botConfig config.AgentConfig)
 	return nil
 }
 
-// LoadBotContainers loads the latest bot list for the running scanner.
+// GetBotExit returns the exit details of a bot container.
+func (bc *botClient) GetBotExit(ctx context.Context, containerName string) (*docker.ContainerExit, error) {
+	info, err := bc.client.InspectContainer(ctx, containerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect the bot container: %v", err)
+	}
+	return docker.NewContainerExit(info.State), nil
+}
+
+// LoadBotContainers loads the bot containers of this node instance.
 func (bc *botClient) LoadBotContainers(ctx context.Context) ([]types.Container, error) {
-	return bc.client.GetContainersByLabel(ctx, docker.LabelFortaIsBot, LabelValueFortaIsBot)
+	botContainers, err := bc.client.GetContainersByLabel(ctx, docker.LabelFortaIsBot, LabelValueFortaIsBot)
+	if err != nil {
+		return nil, err
+	}
+	// the other nodes on the same host manage their own bots
+	ownContainers := botContainers[:0]
+	for _, botContainer := range botContainers {
+		if isOwnContainer(&botContainer) {
+			ownContainers = append(ownContainers, botContainer)
+		}
+	}
+	return ownContainers, nil
 }
 
 // StartWaitBotContainer starts the bot container and waits.
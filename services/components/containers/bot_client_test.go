@@ -49,7 +49,7 @@ func (s *BotClientTestSuite) SetupTest() {
 
 	s.botImageClient.EXPECT().SetImagePullCooldown(ImagePullCooldownThreshold, ImagePullCooldownDuration)
 
-	s.botClient = NewBotClient(config.LogConfig{}, config.ResourcesConfig{}, s.client, s.botImageClient)
+	s.botClient = NewBotClient(config.LogConfig{}, config.ResourcesConfig{}, config.JsonRpcProxyConfig{}, config.OrchestratorConfig{}, config.BotEgressConfig{}, nil, s.client, s.botImageClient, nil)
 }
 
 func (s *BotClientTestSuite) TestEnsureBotImages() {
@@ -121,7 +121,136 @@ func (s *BotClientTestSuite) TestLaunchBot_DoesNotExist() {
 
 	s.client.EXPECT().EnsurePublicNetwork(gomock.Any(), botConfig.ContainerName()).Return(testBotNetworkID, nil)
 	s.client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(nil, docker.ErrContainerNotFound)
-	botContainerCfg := NewBotContainerConfig(testBotNetworkID, botConfig, config.LogConfig{}, config.ResourcesConfig{})
+	botContainerCfg := NewBotContainerConfig(testBotNetworkID, botConfig, config.JsonRpcProxyConfig{}, config.LogConfig{}, config.ResourcesConfig{})
+	s.client.EXPECT().StartContainer(gomock.Any(), botContainerCfg).Return(nil, nil)
+	for _, serviceContainerName := range getServiceContainerNames() {
+		s.client.EXPECT().GetContainerByName(gomock.Any(), serviceContainerName).Return(&types.Container{
+			ID: testContainerID,
+		}, nil)
+		s.client.EXPECT().AttachNetwork(gomock.Any(), testContainerID, testBotNetworkID).Return(nil)
+	}
+
+	s.r.NoError(s.botClient.LaunchBot(context.Background(), botConfig))
+}
+
+func (s *BotClientTestSuite) TestLaunchBot_NameCollision() {
+	botConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testImageRef,
+	}
+
+	s.client.EXPECT().EnsurePublicNetwork(gomock.Any(), botConfig.ContainerName()).Return(testBotNetworkID, nil)
+	s.client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(&types.Container{
+		ID:     testContainerID1,
+		Names:  []string{"/" + botConfig.ContainerName()},
+		Labels: map[string]string{docker.LabelFortaNodeInstance: "other"},
+		State:  "running",
+	}, nil)
+
+	s.r.ErrorIs(s.botClient.LaunchBot(context.Background(), botConfig), ErrContainerNameCollision)
+}
+
+func (s *BotClientTestSuite) TestLaunchBot_LeftoverContainer() {
+	botConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testImageRef,
+	}
+
+	s.client.EXPECT().EnsurePublicNetwork(gomock.Any(), botConfig.ContainerName()).Return(testBotNetworkID, nil)
+	s.client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(&types.Container{
+		ID:     testContainerID1,
+		Names:  []string{"/" + botConfig.ContainerName()},
+		Labels: map[string]string{docker.LabelFortaNodeInstance: "other"},
+		State:  "exited",
+	}, nil)
+	s.client.EXPECT().RemoveContainer(gomock.Any(), testContainerID1).Return(nil)
+	botContainerCfg := NewBotContainerConfig(testBotNetworkID, botConfig, config.JsonRpcProxyConfig{}, config.LogConfig{}, config.ResourcesConfig{})
+	s.client.EXPECT().StartContainer(gomock.Any(), botContainerCfg).Return(nil, nil)
+	for _, serviceContainerName := range getServiceContainerNames() {
+		s.client.EXPECT().GetContainerByName(gomock.Any(), serviceContainerName).Return(&types.Container{
+			ID: testContainerID,
+		}, nil)
+		s.client.EXPECT().AttachNetwork(gomock.Any(), testContainerID, testBotNetworkID).Return(nil)
+	}
+
+	s.r.NoError(s.botClient.LaunchBot(context.Background(), botConfig))
+}
+
+func (s *BotClientTestSuite) TestLaunchBot_Orchestrator() {
+	botConfig := config.AgentConfig{
+		ID:    testBotID1,
+		Image: testImageRef,
+	}
+	s.botClient.orchestratorCfg = config.OrchestratorConfig{
+		Enable:           true,
+		BotNetwork:       "forta-bots",
+		JsonRpcProxyHost: "json-rpc.forta.svc",
+	}
+
+	// no service containers are attached to the shared bot network
+	s.client.EXPECT().EnsurePublicNetwork(gomock.Any(), "forta-bots").Return(testBotNetworkID, nil)
+	s.client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(nil, docker.ErrContainerNotFound)
+	botContainerCfg := NewBotContainerConfig(testBotNetworkID, botConfig, config.JsonRpcProxyConfig{}, config.LogConfig{}, config.ResourcesConfig{})
+	botContainerCfg.Env[config.EnvJsonRpcHost] = "json-rpc.forta.svc"
+	s.client.EXPECT().StartContainer(gomock.Any(), botContainerCfg).Return(nil, nil)
+
+	s.r.NoError(s.botClient.LaunchBot(context.Background(), botConfig))
+}
+
+func (s *BotClientTestSuite) TestLaunchBot_WithDependencies() {
+	dep := config.BotDependency{
+		Name:  "redis",
+		Image: testImageRef,
+	}
+	botConfig := config.AgentConfig{
+		ID:           testBotID1,
+		Image:        testImageRef,
+		Dependencies: []config.BotDependency{dep},
+	}
+
+	s.client.EXPECT().EnsurePublicNetwork(gomock.Any(), botConfig.ContainerName()).Return(testBotNetworkID, nil)
+	depContainerCfg := NewBotDependencyContainerConfig(testBotNetworkID, botConfig, dep, config.LogConfig{}, config.ResourcesConfig{})
+	s.client.EXPECT().StartContainer(gomock.Any(), depContainerCfg).Return(&docker.Container{ID: testContainerID1}, nil)
+	s.client.EXPECT().WaitContainerStart(gomock.Any(), testContainerID1).Return(nil)
+	s.client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(nil, docker.ErrContainerNotFound)
+	botContainerCfg := NewBotContainerConfig(testBotNetworkID, botConfig, config.JsonRpcProxyConfig{}, config.LogConfig{}, config.ResourcesConfig{})
+	s.r.Equal(botConfig.DependencyContainerName(dep), botContainerCfg.Env["FORTA_DEPENDENCY_REDIS_HOST"])
+	s.client.EXPECT().StartContainer(gomock.Any(), botContainerCfg).Return(nil, nil)
+	for _, serviceContainerName := range getServiceContainerNames() {
+		s.client.EXPECT().GetContainerByName(gomock.Any(), serviceContainerName).Return(&types.Container{
+			ID: testContainerID,
+		}, nil)
+		s.client.EXPECT().AttachNetwork(gomock.Any(), testContainerID, testBotNetworkID).Return(nil)
+	}
+
+	s.r.NoError(s.botClient.LaunchBot(context.Background(), botConfig))
+}
+
+func (s *BotClientTestSuite) TestLaunchBot_Egress() {
+	botConfig := config.AgentConfig{
+		ID:          testBotID1,
+		Image:       testImageRef,
+		EgressHosts: []string{"api.coingecko.com", "evil.com"},
+	}
+	s.botClient.egressCfg = config.BotEgressConfig{
+		Enable:        true,
+		ApprovedHosts: []string{"api.coingecko.com"},
+		Gateway:       config.BotGatewayConfig{Enable: true},
+	}
+
+	// the bot network is internal and the bot reaches the approved hosts through the egress proxy
+	// or the bot gateway
+	s.client.EXPECT().EnsureInternalNetwork(gomock.Any(), botConfig.ContainerName()).Return(testBotNetworkID, nil)
+	s.client.EXPECT().GetContainerByName(gomock.Any(), botConfig.ContainerName()).Return(nil, docker.ErrContainerNotFound)
+	botContainerCfg := NewBotContainerConfig(testBotNetworkID, botConfig, config.JsonRpcProxyConfig{}, config.LogConfig{}, config.ResourcesConfig{})
+	proxyURL := "http://" + config.DockerJSONRPCProxyContainerName + ":" + config.DefaultEgressProxyPort
+	for _, name := range proxyEnvVars {
+		botContainerCfg.Env[name] = proxyURL
+	}
+	botContainerCfg.Env["NO_PROXY"] = config.DockerJSONRPCProxyContainerName + "," + config.DockerJWTProviderContainerName + "," + config.DockerPublicAPIProxyContainerName
+	botContainerCfg.Env["no_proxy"] = botContainerCfg.Env["NO_PROXY"]
+	botContainerCfg.Env[config.EnvBotGatewayURL] = "http://" + config.DockerJSONRPCProxyContainerName + ":" + config.DefaultBotGatewayPort
+	botContainerCfg.Labels[docker.LabelFortaSupervisorStrategyVersion] = LabelValueStrategyVersion + "+egress+gateway"
 	s.client.EXPECT().StartContainer(gomock.Any(), botContainerCfg).Return(nil, nil)
 	for _, serviceContainerName := range getServiceContainerNames() {
 		s.client.EXPECT().GetContainerByName(gomock.Any(), serviceContainerName).Return(&types.Container{
@@ -151,6 +280,9 @@ func (s *BotClientTestSuite) TestTearDownBot() {
 		s.client.EXPECT().DetachNetwork(gomock.Any(), testContainerID, botConfig.ContainerName()).Return(testErr)
 	}
 	s.client.EXPECT().RemoveContainer(gomock.Any(), testContainerID2).Return(testErr)
+	s.client.EXPECT().GetContainersByLabel(gomock.Any(), docker.LabelFortaBotDependencyOf, botConfig.ContainerName()).
+		Return(docker.ContainerList{{ID: testContainerID1}}, nil)
+	s.client.EXPECT().RemoveContainer(gomock.Any(), testContainerID1).Return(testErr)
 	s.client.EXPECT().RemoveNetworkByName(gomock.Any(), botConfig.ContainerName()).Return(testErr)
 	s.client.EXPECT().RemoveImage(gomock.Any(), testImageRef).Return(testErr)
 
@@ -173,8 +305,11 @@ func (s *BotClientTestSuite) TestStopBot() {
 }
 
 func (s *BotClientTestSuite) TestLoadBotContainers() {
-	expectedContainers := docker.ContainerList{{}}
-	s.client.EXPECT().GetContainersByLabel(gomock.Any(), docker.LabelFortaIsBot, LabelValueFortaIsBot).Return(expectedContainers, nil)
+	expectedContainers := docker.ContainerList{{ID: testContainerID1}}
+	s.client.EXPECT().GetContainersByLabel(gomock.Any(), docker.LabelFortaIsBot, LabelValueFortaIsBot).Return(docker.ContainerList{
+		{ID: testContainerID1},
+		{ID: testContainerID2, Labels: map[string]string{docker.LabelFortaNodeInstance: "other"}},
+	}, nil)
 
 	containers, err := s.botClient.LoadBotContainers(context.Background())
 	s.r.NoError(err)
@@ -2,6 +2,8 @@ package containers
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/config"
@@ -26,19 +28,19 @@ type Limits struct {
 
 // NewBotContainerConfig creates a new bot container config.
 func NewBotContainerConfig(
-	networkID string, botConfig config.AgentConfig,
+	networkID string, botConfig config.AgentConfig, jsonRpcProxyCfg config.JsonRpcProxyConfig,
 	logConfig config.LogConfig, resourcesConfig config.ResourcesConfig,
 ) docker.ContainerConfig {
 	limits := config.GetAgentResourceLimits(resourcesConfig)
 
-	return docker.ContainerConfig{
+	cntCfg := docker.ContainerConfig{
 		Name:           botConfig.ContainerName(),
 		Image:          botConfig.Image,
 		NetworkID:      networkID,
 		LinkNetworkIDs: []string{},
 		Env: map[string]string{
 			config.EnvJsonRpcHost:        config.DockerJSONRPCProxyContainerName,
-			config.EnvJsonRpcPort:        config.DefaultJSONRPCProxyPort,
+			config.EnvJsonRpcPort:        jsonRpcProxyCfg.Port(),
 			config.EnvJWTProviderHost:    config.DockerJWTProviderContainerName,
 			config.EnvJWTProviderPort:    config.DefaultJWTProviderPort,
 			config.EnvPublicAPIProxyHost: config.DockerPublicAPIProxyContainerName,
@@ -51,11 +53,156 @@ func NewBotContainerConfig(
 		MaxLogFiles: logConfig.MaxLogFiles,
 		MaxLogSize:  logConfig.MaxLogSize,
 		CPUQuota:    limits.CPUQuota,
+		CPUWeight:   config.GetBotCPUWeight(resourcesConfig, botConfig),
 		Memory:      limits.Memory,
 		Labels: map[string]string{
 			docker.LabelFortaIsBot:                     LabelValueFortaIsBot,
 			docker.LabelFortaSupervisorStrategyVersion: LabelValueStrategyVersion,
 			docker.LabelFortaBotID:                     botConfig.ID,
+			docker.LabelFortaNodeInstance:              config.ContainerNamingInstance(),
 		},
 	}
+	for _, dep := range botConfig.Dependencies {
+		cntCfg.Env[dep.EnvHostName()] = botConfig.DependencyContainerName(dep)
+	}
+	// the proxies of the other chains are on the same host and only the ones for the chains
+	// declared by the bot and the data sources are exposed
+	chainIDs := []string{strconv.Itoa(botConfig.ChainID)}
+	var dataChainIDs []string
+	for _, instance := range jsonRpcProxyCfg.Instances {
+		if instance.ChainID == botConfig.ChainID {
+			continue
+		}
+		switch {
+		case botConfig.SupportsChain(instance.ChainID):
+			chainIDs = append(chainIDs, strconv.Itoa(instance.ChainID))
+		case instance.DataSource:
+			dataChainIDs = append(dataChainIDs, strconv.Itoa(instance.ChainID))
+		default:
+			continue
+		}
+		for _, envName := range instance.EnvPortNames() {
+			cntCfg.Env[envName] = instance.Port()
+		}
+	}
+	cntCfg.Env[config.EnvFortaChainIDs] = strings.Join(chainIDs, ",")
+	if len(dataChainIDs) > 0 {
+		cntCfg.Env[config.EnvFortaDataChainIDs] = strings.Join(dataChainIDs, ",")
+	}
+	for _, protocolProxy := range jsonRpcProxyCfg.ProtocolProxies {
+		cntCfg.Env[protocolProxy.EnvPortName()] = protocolProxy.Port()
+	}
+	setUlimitsAndTmpfs(&cntCfg, config.GetBotContainerConfig(resourcesConfig, botConfig))
+	return cntCfg
+}
+
+// setUlimitsAndTmpfs sets the ulimits and the tmpfs mounts of the bot container.
+func setUlimitsAndTmpfs(cntCfg *docker.ContainerConfig, botContainerCfg config.BotContainerConfig) {
+	addUlimit := func(name string, ulimit *config.UlimitConfig) {
+		if ulimit != nil {
+			cntCfg.Ulimits = append(cntCfg.Ulimits, docker.Ulimit{Name: name, Soft: ulimit.Soft, Hard: ulimit.HardLimit()})
+		}
+	}
+	addUlimit("nofile", botContainerCfg.Ulimits.NoFile)
+	addUlimit("nproc", botContainerCfg.Ulimits.NProc)
+	if len(botContainerCfg.Tmpfs) == 0 {
+		return
+	}
+	cntCfg.Tmpfs = make(map[string]string)
+	for _, mount := range botContainerCfg.Tmpfs {
+		cntCfg.Tmpfs[mount.Target] = mount.Options()
+	}
+}
+
+// setServiceHosts points the bots to the services run by the orchestrator.
+func setServiceHosts(env map[string]string, orchestratorCfg config.OrchestratorConfig) {
+	if !orchestratorCfg.Enable {
+		return
+	}
+	env[config.EnvJsonRpcHost] = orchestratorCfg.ServiceHost(config.DockerJSONRPCProxyContainerName)
+	env[config.EnvJWTProviderHost] = orchestratorCfg.ServiceHost(config.DockerJWTProviderContainerName)
+	env[config.EnvPublicAPIProxyHost] = orchestratorCfg.ServiceHost(config.DockerPublicAPIProxyContainerName)
+}
+
+// proxyEnvVars are the env vars which the common HTTP clients use for finding the proxy.
+var proxyEnvVars = []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"}
+
+// setEgressProxy points the bot to the egress proxy if the bot can reach any external hosts. The
+// node services and the dependencies of the bot are reached directly.
+func setEgressProxy(
+	env map[string]string, botConfig config.AgentConfig,
+	egressCfg config.BotEgressConfig, orchestratorCfg config.OrchestratorConfig,
+) {
+	if !egressCfg.Enable || len(egressCfg.AllowedHosts(botConfig)) == 0 {
+		return
+	}
+	proxyURL := fmt.Sprintf("http://%s:%s", orchestratorCfg.ServiceHost(config.DockerJSONRPCProxyContainerName), egressCfg.Port())
+	for _, name := range proxyEnvVars {
+		env[name] = proxyURL
+	}
+	noProxy := []string{env[config.EnvJsonRpcHost], env[config.EnvJWTProviderHost], env[config.EnvPublicAPIProxyHost]}
+	for _, dep := range botConfig.Dependencies {
+		noProxy = append(noProxy, env[dep.EnvHostName()])
+	}
+	env["NO_PROXY"] = strings.Join(noProxy, ",")
+	env["no_proxy"] = env["NO_PROXY"]
+}
+
+// setBotGateway tells the bot the url of the bot gateway if the bot can reach any external hosts.
+func setBotGateway(
+	env map[string]string, botConfig config.AgentConfig,
+	egressCfg config.BotEgressConfig, orchestratorCfg config.OrchestratorConfig,
+) {
+	if !egressCfg.Gateway.Enable || len(egressCfg.AllowedHosts(botConfig)) == 0 {
+		return
+	}
+	env[config.EnvBotGatewayURL] = fmt.Sprintf(
+		"http://%s:%s", orchestratorCfg.ServiceHost(config.DockerJSONRPCProxyContainerName), egressCfg.Gateway.Port(),
+	)
+}
+
+// StrategyVersion returns the strategy version of the bot containers. The containers are re-created
+// when the egress policy or the bot gateway is toggled so that they get the right network and env vars.
+func StrategyVersion(egressCfg config.BotEgressConfig) string {
+	version := LabelValueStrategyVersion
+	if egressCfg.Enable {
+		version += "+egress"
+	}
+	if egressCfg.Gateway.Enable {
+		version += "+gateway"
+	}
+	return version
+}
+
+// NewBotDependencyContainerConfig creates a new container config for a bot dependency.
+func NewBotDependencyContainerConfig(
+	networkID string, botConfig config.AgentConfig, dep config.BotDependency,
+	logConfig config.LogConfig, resourcesConfig config.ResourcesConfig,
+) docker.ContainerConfig {
+	limits := config.GetAgentResourceLimits(resourcesConfig)
+
+	env := make(map[string]string)
+	for k, v := range dep.Env {
+		env[k] = v
+	}
+
+	cntCfg := docker.ContainerConfig{
+		Name:           botConfig.DependencyContainerName(dep),
+		Image:          dep.Image,
+		NetworkID:      networkID,
+		LinkNetworkIDs: []string{},
+		Env:            env,
+		MaxLogFiles:    logConfig.MaxLogFiles,
+		MaxLogSize:     logConfig.MaxLogSize,
+		CPUQuota:       limits.CPUQuota,
+		CPUWeight:      config.GetBotCPUWeight(resourcesConfig, botConfig),
+		Memory:         limits.Memory,
+		Labels: map[string]string{
+			docker.LabelFortaSupervisorStrategyVersion: LabelValueStrategyVersion,
+			docker.LabelFortaBotID:                     botConfig.ID,
+			docker.LabelFortaBotDependencyOf:           botConfig.ContainerName(),
+		},
+	}
+	setUlimitsAndTmpfs(&cntCfg, config.GetBotContainerConfig(resourcesConfig, botConfig))
+	return cntCfg
 }
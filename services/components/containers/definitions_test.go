@@ -0,0 +1,75 @@
+package containers
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBotContainerConfig_ChainEnv(t *testing.T) {
+	r := require.New(t)
+
+	proxyCfg := config.JsonRpcProxyConfig{
+		Instances: []config.JsonRpcProxyInstanceConfig{
+			{ChainID: 137, ListenAddr: ":8547"},
+			{ChainID: 10, ListenAddr: ":8548"},
+		},
+	}
+
+	cntCfg := NewBotContainerConfig(
+		"", config.AgentConfig{ID: "0x1", ChainID: 1, ChainIDs: []int{1, 137}},
+		proxyCfg, config.LogConfig{}, config.ResourcesConfig{},
+	)
+	r.Equal("1", cntCfg.Env[config.EnvFortaChainID])
+	r.Equal("1,137", cntCfg.Env[config.EnvFortaChainIDs])
+	r.Equal("8547", cntCfg.Env["JSON_RPC_PORT_137"])
+	r.NotContains(cntCfg.Env, "JSON_RPC_PORT_10")
+
+	// all of the chains are exposed if the bot does not declare any
+	cntCfg = NewBotContainerConfig(
+		"", config.AgentConfig{ID: "0x1", ChainID: 1},
+		proxyCfg, config.LogConfig{}, config.ResourcesConfig{},
+	)
+	r.Equal("1,137,10", cntCfg.Env[config.EnvFortaChainIDs])
+	r.Equal("8548", cntCfg.Env["JSON_RPC_PORT_10"])
+
+	// the data sources are exposed to all bots
+	proxyCfg.Instances[1].Name = "optimism"
+	proxyCfg.Instances[1].DataSource = true
+	cntCfg = NewBotContainerConfig(
+		"", config.AgentConfig{ID: "0x1", ChainID: 1, ChainIDs: []int{1}},
+		proxyCfg, config.LogConfig{}, config.ResourcesConfig{},
+	)
+	r.Equal("1", cntCfg.Env[config.EnvFortaChainIDs])
+	r.Equal("10", cntCfg.Env[config.EnvFortaDataChainIDs])
+	r.Equal("8548", cntCfg.Env["JSON_RPC_PORT_10"])
+	r.Equal("8548", cntCfg.Env["JSON_RPC_PORT_OPTIMISM"])
+	r.NotContains(cntCfg.Env, "JSON_RPC_PORT_137")
+}
+
+func TestNewBotContainerConfig_UlimitsAndTmpfs(t *testing.T) {
+	r := require.New(t)
+
+	var resourcesCfg config.ResourcesConfig
+	resourcesCfg.BotContainers.Ulimits.NoFile = &config.UlimitConfig{Soft: 4096, Hard: 8192}
+	resourcesCfg.BotContainers.Ulimits.NProc = &config.UlimitConfig{Soft: 512}
+	resourcesCfg.BotContainers.Tmpfs = []config.TmpfsMountConfig{{Target: "/tmp", SizeMiB: 64}}
+	botConfig := config.AgentConfig{ID: "0x1", ChainID: 1}
+
+	cntCfg := NewBotContainerConfig("", botConfig, config.JsonRpcProxyConfig{}, config.LogConfig{}, resourcesCfg)
+	r.Equal([]docker.Ulimit{
+		{Name: "nofile", Soft: 4096, Hard: 8192},
+		{Name: "nproc", Soft: 512, Hard: 512},
+	}, cntCfg.Ulimits)
+	r.Equal(map[string]string{"/tmp": "rw,nosuid,nodev,size=64m"}, cntCfg.Tmpfs)
+
+	depCfg := NewBotDependencyContainerConfig("", botConfig, config.BotDependency{}, config.LogConfig{}, resourcesCfg)
+	r.Equal(cntCfg.Ulimits, depCfg.Ulimits)
+	r.Equal(cntCfg.Tmpfs, depCfg.Tmpfs)
+
+	cntCfg = NewBotContainerConfig("", botConfig, config.JsonRpcProxyConfig{}, config.LogConfig{}, config.ResourcesConfig{})
+	r.Nil(cntCfg.Ulimits)
+	r.Nil(cntCfg.Tmpfs)
+}
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	types "github.com/docker/docker/api/types"
+	docker "github.com/forta-network/forta-node/clients/docker"
 	config "github.com/forta-network/forta-node/config"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -50,6 +51,21 @@ func (mr *MockBotClientMockRecorder) EnsureBotImages(ctx, botConfigs interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureBotImages", reflect.TypeOf((*MockBotClient)(nil).EnsureBotImages), ctx, botConfigs)
 }
 
+// GetBotExit mocks base method.
+func (m *MockBotClient) GetBotExit(ctx context.Context, containerName string) (*docker.ContainerExit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBotExit", ctx, containerName)
+	ret0, _ := ret[0].(*docker.ContainerExit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBotExit indicates an expected call of GetBotExit.
+func (mr *MockBotClientMockRecorder) GetBotExit(ctx, containerName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBotExit", reflect.TypeOf((*MockBotClient)(nil).GetBotExit), ctx, containerName)
+}
+
 // LaunchBot mocks base method.
 func (m *MockBotClient) LaunchBot(ctx context.Context, botConfig config.AgentConfig) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,127 @@
+package containers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	botSecretsDirName = "bot-secrets"
+	// BotSecretsMountPath is where the file secrets are mounted in the bot containers.
+	BotSecretsMountPath = "/run/secrets"
+)
+
+var (
+	botSecretEnvNameRegexp  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	botSecretFileNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+)
+
+// BotSecretStore delivers the secrets which the operator configures for the bots. The file secrets
+// are written to the Forta dir and mounted to the bot containers from the host.
+type BotSecretStore struct {
+	cfg      config.BotSecretsConfig
+	localDir string
+	hostDir  string
+}
+
+// NewBotSecretStore creates a new bot secret store.
+func NewBotSecretStore(cfg config.BotSecretsConfig, fortaDir string) *BotSecretStore {
+	store := &BotSecretStore{
+		cfg:      cfg,
+		localDir: path.Join(fortaDir, botSecretsDirName),
+	}
+	if hostFortaDir := os.Getenv(config.EnvHostFortaDir); len(hostFortaDir) > 0 {
+		store.hostDir = path.Join(hostFortaDir, botSecretsDirName)
+	}
+	return store
+}
+
+// Apply adds the secrets of the bot to the bot container config. The names of the delivered
+// secrets are logged for auditing but the values are never logged.
+func (store *BotSecretStore) Apply(botConfig config.AgentConfig, cntCfg *docker.ContainerConfig) error {
+	secrets := store.cfg.Secrets(botConfig.ID)
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	var envNames, fileNames []string
+	for _, secret := range secrets {
+		if secret.File {
+			if !botSecretFileNameRegexp.MatchString(secret.Name) {
+				return fmt.Errorf("invalid bot secret file name: %s", secret.Name)
+			}
+			fileNames = append(fileNames, secret.Name)
+			continue
+		}
+		if !botSecretEnvNameRegexp.MatchString(secret.Name) {
+			return fmt.Errorf("invalid bot secret env var name: %s", secret.Name)
+		}
+		// the env vars of the node can not be overridden by the secrets
+		if _, ok := cntCfg.Env[secret.Name]; ok {
+			return fmt.Errorf("bot secret %s conflicts with a node env var", secret.Name)
+		}
+		cntCfg.Env[secret.Name] = secret.Value
+		envNames = append(envNames, secret.Name)
+	}
+
+	if len(fileNames) > 0 {
+		if err := store.writeFiles(botConfig, cntCfg, secrets); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"bot":         botConfig.ID,
+		"container":   cntCfg.Name,
+		"envSecrets":  envNames,
+		"fileSecrets": fileNames,
+	}).Info("delivering secrets to the bot")
+	return nil
+}
+
+func (store *BotSecretStore) writeFiles(botConfig config.AgentConfig, cntCfg *docker.ContainerConfig, secrets []config.BotSecretConfig) error {
+	if len(store.hostDir) == 0 {
+		return fmt.Errorf("file secrets need $%s to mount the secrets to the bot", config.EnvHostFortaDir)
+	}
+	// only the docker daemon needs to find the bot dirs on the host
+	if err := os.MkdirAll(store.localDir, 0700); err != nil {
+		return fmt.Errorf("failed to create the bot secrets dir: %v", err)
+	}
+	botDir := path.Join(store.localDir, cntCfg.Name)
+	if err := os.RemoveAll(botDir); err != nil {
+		return fmt.Errorf("failed to clean up the bot secrets dir: %v", err)
+	}
+	if err := os.Mkdir(botDir, 0755); err != nil {
+		return fmt.Errorf("failed to create the bot secrets dir: %v", err)
+	}
+	for _, secret := range secrets {
+		if !secret.File {
+			continue
+		}
+		// the bots can run as non-root users
+		if err := ioutil.WriteFile(path.Join(botDir, secret.Name), []byte(secret.Value), 0444); err != nil {
+			return fmt.Errorf("failed to write the bot secret %s: %v", secret.Name, err)
+		}
+	}
+
+	if cntCfg.Volumes == nil {
+		cntCfg.Volumes = make(map[string]string)
+	}
+	cntCfg.Volumes[path.Join(store.hostDir, cntCfg.Name)] = BotSecretsMountPath + ":ro"
+	cntCfg.Env[config.EnvBotSecretsDir] = BotSecretsMountPath
+	return nil
+}
+
+// Remove removes the secret files of a bot container.
+func (store *BotSecretStore) Remove(containerName string) {
+	if err := os.RemoveAll(path.Join(store.localDir, containerName)); err != nil {
+		log.WithError(err).WithField("botContainer", containerName).Warn("failed to remove the bot secrets")
+	}
+}
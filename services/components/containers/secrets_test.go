@@ -0,0 +1,63 @@
+package containers
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotSecretStore(t *testing.T) {
+	r := require.New(t)
+
+	fortaDir := t.TempDir()
+	t.Setenv(config.EnvHostFortaDir, "/home/operator/.forta")
+	store := NewBotSecretStore(config.BotSecretsConfig{
+		testBotID1: {
+			{Name: "COINGECKO_API_KEY", Value: "secret1"},
+			{Name: "etherscan.key", Value: "secret2", File: true},
+		},
+	}, fortaDir)
+
+	botConfig := config.AgentConfig{ID: testBotID1, Image: testImageRef}
+	cntCfg := NewBotContainerConfig(testBotNetworkID, botConfig, config.JsonRpcProxyConfig{}, config.LogConfig{}, config.ResourcesConfig{})
+	r.NoError(store.Apply(botConfig, &cntCfg))
+
+	r.Equal("secret1", cntCfg.Env["COINGECKO_API_KEY"])
+	r.Equal(BotSecretsMountPath, cntCfg.Env[config.EnvBotSecretsDir])
+	r.Equal(BotSecretsMountPath+":ro", cntCfg.Volumes["/home/operator/.forta/bot-secrets/"+cntCfg.Name])
+	b, err := ioutil.ReadFile(path.Join(fortaDir, botSecretsDirName, cntCfg.Name, "etherscan.key"))
+	r.NoError(err)
+	r.Equal("secret2", string(b))
+
+	store.Remove(cntCfg.Name)
+	_, err = ioutil.ReadFile(path.Join(fortaDir, botSecretsDirName, cntCfg.Name, "etherscan.key"))
+	r.Error(err)
+
+	// the other bots do not get the secrets
+	otherBotConfig := config.AgentConfig{ID: testBotID2, Image: testImageRef}
+	cntCfg = NewBotContainerConfig(testBotNetworkID, otherBotConfig, config.JsonRpcProxyConfig{}, config.LogConfig{}, config.ResourcesConfig{})
+	r.NoError(store.Apply(otherBotConfig, &cntCfg))
+	r.Empty(cntCfg.Env["COINGECKO_API_KEY"])
+	r.Empty(cntCfg.Volumes)
+}
+
+func TestBotSecretStore_Invalid(t *testing.T) {
+	r := require.New(t)
+
+	t.Setenv(config.EnvHostFortaDir, "")
+	botConfig := config.AgentConfig{ID: testBotID1, Image: testImageRef}
+	for _, secret := range []config.BotSecretConfig{
+		{Name: config.EnvJsonRpcHost, Value: "secret"},
+		{Name: "API-KEY", Value: "secret"},
+		{Name: "../key", Value: "secret", File: true},
+		// the file secrets can not be mounted without the host dir
+		{Name: "key", Value: "secret", File: true},
+	} {
+		store := NewBotSecretStore(config.BotSecretsConfig{testBotID1: {secret}}, t.TempDir())
+		cntCfg := NewBotContainerConfig(testBotNetworkID, botConfig, config.JsonRpcProxyConfig{}, config.LogConfig{}, config.ResourcesConfig{})
+		r.Error(store.Apply(botConfig, &cntCfg), secret.Name)
+	}
+}
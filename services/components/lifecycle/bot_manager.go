@@ -2,11 +2,17 @@ package lifecycle
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/logging"
 	"github.com/forta-network/forta-node/services/components/containers"
 	"github.com/forta-network/forta-node/services/components/metrics"
 	"github.com/forta-network/forta-node/services/components/registry"
@@ -18,6 +24,9 @@ var (
 	botRemoveTimeout = time.Second * 5
 )
 
+// lifecycleLog can be configured separately from the container logs.
+var lifecycleLog = logging.Logger(logging.ComponentLifecycle)
+
 // BotLifecycleManager manages lifecycles of running bots.
 type BotLifecycleManager interface {
 	ManageBots(ctx context.Context) error
@@ -25,8 +34,14 @@ type BotLifecycleManager interface {
 	ExitInactiveBots(ctx context.Context) error
 	RestartExitedBots(ctx context.Context) error
 	TearDownRunningBots(ctx context.Context)
+	RunningBots() []config.AgentConfig
+	RestartBot(ctx context.Context, botID string) error
+	StopBot(ctx context.Context, botID string) error
 }
 
+// ErrBotNotFound is returned when the bot is not one of the running bots.
+var ErrBotNotFound = errors.New("bot not found")
+
 type botLifecycleManager struct {
 	botRegistry      registry.BotRegistry
 	botClient        containers.BotClient
@@ -35,6 +50,18 @@ type botLifecycleManager struct {
 	botMonitor       BotMonitor
 
 	runningBots []config.AgentConfig
+	// lastAssignedBots are from the last time the registry was available
+	lastAssignedBots []config.AgentConfig
+	// stoppedBots are stopped by the operator and are not restarted until requested
+	stoppedBots map[string]bool
+	// stopReasons are the reasons of the bot stops which are reported when the bots are restarted
+	stopReasons map[string]string
+	mu          sync.RWMutex
+
+	lastManageErrs ManageBotsErrors
+	// manageErrCounts counts the management cycles which failed with each kind of error
+	manageErrCounts map[error]uint64
+	manageErrsMu    sync.RWMutex
 }
 
 var _ BotLifecycleManager = &botLifecycleManager{}
@@ -51,23 +78,62 @@ func NewManager(
 		botPool:          botPool,
 		lifecycleMetrics: lifecycleMetrics,
 		botMonitor:       botMonitor,
+		stoppedBots:      make(map[string]bool),
+		stopReasons:      make(map[string]string),
+		manageErrCounts:  make(map[error]uint64),
 	}
 }
 
 // ManageBots starts containers for assigned bots and stops the containers for unassigned
-// bots and lets other services know.
+// bots and lets other services know. The returned errors are ManageBotsErrors.
 func (blm *botLifecycleManager) ManageBots(ctx context.Context) error {
+	errs := blm.manageBots(ctx)
+	blm.trackManageErrs(errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	for _, err := range errs {
+		blm.lifecycleMetrics.SystemError(fmt.Sprintf("manage.bots.%s", err.Phase), err)
+	}
+	return errs
+}
+
+// manageBots goes through all phases even if some of them fail for some bots. Only a registry
+// failure before any bots were loaded aborts the management.
+func (blm *botLifecycleManager) manageBots(ctx context.Context) (errs ManageBotsErrors) {
 	assignedBots, err := blm.botRegistry.LoadAssignedBots()
 	if err != nil {
 		blm.lifecycleMetrics.SystemError("load.assigned.bots", err)
-		return fmt.Errorf("failed to load assigned bots: %v", err)
+		errs = errs.Add(PhaseLoad, ErrRegistryUnavailable, "", fmt.Errorf("failed to load assigned bots: %v", err))
+		// the last assignments are still good for retrying the bots which failed to launch
+		assignedBots = blm.getLastAssignedBots()
+		if assignedBots == nil {
+			return
+		}
+		lifecycleLog.WithError(err).Warn("using the last assigned bots while the registry is unavailable")
+	} else {
+		blm.setLastAssignedBots(assignedBots)
+		// let the rejected bots be known
+		if rejecter, ok := blm.botRegistry.(interface{ TakeRejectedBots() map[string]error }); ok {
+			for botID, err := range rejecter.TakeRejectedBots() {
+				blm.lifecycleMetrics.FailureManifest(err, botID)
+			}
+		}
+		// let the network know about the bots which are declined by the operator policy
+		if decliner, ok := blm.botRegistry.(interface{ TakeDeclinedBots() map[string]string }); ok {
+			for botID, reason := range decliner.TakeDeclinedBots() {
+				blm.lifecycleMetrics.StatusDeclined(reason, botID)
+			}
+		}
 	}
 
+	runningBots := blm.RunningBots()
+
 	// find the removed bots and remove them from the pool
-	removedBotConfigs := FindMissingBots(blm.runningBots, assignedBots)
+	removedBotConfigs := FindMissingBots(runningBots, assignedBots)
 	if len(removedBotConfigs) > 0 {
 		if err := blm.botPool.RemoveBotsWithConfigs(removedBotConfigs); err != nil {
-			log.WithError(err).Error("error removing bots")
+			lifecycleLog.WithError(err).Error("error removing bots")
 			blm.lifecycleMetrics.SystemError("remove.bots.with.configs", err)
 		}
 		blm.lifecycleMetrics.StatusStopping(removedBotConfigs...)
@@ -77,21 +143,31 @@ func (blm *botLifecycleManager) ManageBots(ctx context.Context) error {
 	// this is just for avoiding bot client error noise
 	time.Sleep(botRemoveTimeout)
 
-	// then stop the containers
+	// then stop the containers - the unused bot cleanup retries the ones which fail here
+	dockerUnavailable := false
 	for _, removedBotConfig := range removedBotConfigs {
+		if dockerUnavailable {
+			errs = errs.Add(PhaseTeardown, ErrDockerUnavailable, removedBotConfig.ID, nil)
+			continue
+		}
+		blm.reportTearDown(ExitReasonUnassigned, removedBotConfig)
 		if err := blm.botClient.TearDownBot(ctx, removedBotConfig.ContainerName(), true); err != nil {
-			log.WithError(err).WithField("container", removedBotConfig.ContainerName()).
+			lifecycleLog.WithError(err).WithField("container", removedBotConfig.ContainerName()).
 				Warn("failed to tear down unassigned bot container")
 			blm.lifecycleMetrics.BotError("unassigned.teardown", err, removedBotConfig.ID)
+			if launchErrKind(err) == ErrDockerUnavailable {
+				dockerUnavailable = true
+				errs = errs.Add(PhaseTeardown, ErrDockerUnavailable, removedBotConfig.ID, err)
+			}
 		}
 	}
 
 	// find the bot containers to start
-	addedBotConfigs := FindExtraBots(blm.runningBots, assignedBots)
+	addedBotConfigs := FindExtraBots(runningBots, assignedBots)
 
 	// then download all images concurrently
-	var downloadErrs []error
-	if len(addedBotConfigs) > 0 {
+	downloadErrs := make([]error, len(addedBotConfigs))
+	if len(addedBotConfigs) > 0 && !dockerUnavailable {
 		downloadErrs = blm.botClient.EnsureBotImages(ctx, addedBotConfigs)
 	}
 
@@ -100,7 +176,7 @@ func (blm *botLifecycleManager) ManageBots(ctx context.Context) error {
 
 		// skip start if we could not download
 		if downloadErrs[i] != nil {
-			log.WithFields(log.Fields{
+			lifecycleLog.WithFields(log.Fields{
 				"bot":   addedBotConfig.ID,
 				"image": addedBotConfig.Image,
 				"error": downloadErrs[i],
@@ -108,17 +184,31 @@ func (blm *botLifecycleManager) ManageBots(ctx context.Context) error {
 			// drop the bot from the list so it can be picked again next time
 			assignedBots = Drop(addedBotConfig, assignedBots)
 			blm.lifecycleMetrics.FailurePull(downloadErrs[i], addedBotConfig)
+			errKind := pullErrKind(downloadErrs[i])
+			dockerUnavailable = dockerUnavailable || errKind == ErrDockerUnavailable
+			errs = errs.Add(PhasePull, errKind, addedBotConfig.ID, downloadErrs[i])
+			continue
+		}
+
+		// the other bots can not be launched either without docker
+		if dockerUnavailable {
+			assignedBots = Drop(addedBotConfig, assignedBots)
+			blm.lifecycleMetrics.FailureLaunch(ErrDockerUnavailable, addedBotConfig)
+			errs = errs.Add(PhaseLaunch, ErrDockerUnavailable, addedBotConfig.ID, nil)
 			continue
 		}
 
 		// skip if the bot could not start
 		err := blm.botClient.LaunchBot(ctx, addedBotConfig)
 		if err != nil {
-			log.WithError(err).WithField("container", addedBotConfig.ContainerName()).
+			lifecycleLog.WithError(err).WithField("container", addedBotConfig.ContainerName()).
 				Warn("failed to launch bot")
 			// drop the bot from the list so it can be picked again next time
 			assignedBots = Drop(addedBotConfig, assignedBots)
 			blm.lifecycleMetrics.FailureLaunch(err, addedBotConfig)
+			errKind := launchErrKind(err)
+			dockerUnavailable = errKind == ErrDockerUnavailable
+			errs = errs.Add(PhaseLaunch, errKind, addedBotConfig.ID, err)
 			continue
 		}
 	}
@@ -130,13 +220,25 @@ func (blm *botLifecycleManager) ManageBots(ctx context.Context) error {
 	blm.lifecycleMetrics.StatusRunning(assignedBots...)
 	blm.botMonitor.MonitorBots(GetBotIDs(assignedBots))
 
+	blm.mu.Lock()
 	blm.runningBots = assignedBots
-	return nil
+	for botID := range blm.stoppedBots {
+		if _, ok := blm.findBotConfigByIDUnsafe(botID); !ok {
+			delete(blm.stoppedBots, botID)
+		}
+	}
+	for botID := range blm.stopReasons {
+		if _, ok := blm.findBotConfigByIDUnsafe(botID); !ok {
+			delete(blm.stopReasons, botID)
+		}
+	}
+	blm.mu.Unlock()
+	return
 }
 
 // CleanupUnusedBots cleans up unused bots.
 func (blm *botLifecycleManager) CleanupUnusedBots(ctx context.Context) error {
-	if len(blm.runningBots) == 0 {
+	if len(blm.RunningBots()) == 0 {
 		return nil
 	}
 
@@ -156,8 +258,11 @@ func (blm *botLifecycleManager) CleanupUnusedBots(ctx context.Context) error {
 			continue
 		}
 
+		if botID := botContainer.Labels[docker.LabelFortaBotID]; len(botID) > 0 {
+			blm.reportTearDown(ExitReasonUnused, config.AgentConfig{ID: botID})
+		}
 		if err := blm.botClient.TearDownBot(ctx, botContainerName, true); err != nil {
-			log.WithField("botContainer", botContainerName).WithError(err).
+			lifecycleLog.WithField("botContainer", botContainerName).WithError(err).
 				Error("error while tearing down the unused bot")
 		}
 	}
@@ -173,16 +278,21 @@ func (blm *botLifecycleManager) ExitInactiveBots(ctx context.Context) error {
 	}
 	for _, inactiveBotID := range inactiveBotIDs {
 		botConfig, found := blm.findBotConfigByID(inactiveBotID)
-		logger := log.WithField("bot", inactiveBotID)
+		logger := lifecycleLog.WithField("bot", inactiveBotID)
 		if !found {
 			logger.Warn("could not find the config for inactive bot - skipping stop")
 			continue
 		}
+		if blm.isStopped(botConfig.ID) {
+			continue
+		}
 		logger.Info("killing inactive bot for reinitialization")
 		if err := blm.botClient.StopBot(ctx, botConfig); err != nil {
 			logger.WithError(err).Error("failed to stop the inactive bot")
 			blm.lifecycleMetrics.FailureStop(fmt.Errorf("failed to stop the inactive bot: %v", err.Error()), botConfig)
+			continue
 		}
+		blm.setStopReason(botConfig.ID, ExitReasonInactive)
 	}
 	return nil
 }
@@ -203,13 +313,17 @@ func (blm *botLifecycleManager) RestartExitedBots(ctx context.Context) error {
 		}
 
 		containerName := docker.GetContainerName(botContainer)
-		logger := log.WithField("container", containerName)
+		logger := lifecycleLog.WithField("container", containerName)
 		restartedBotConfig, found := blm.findBotConfig(containerName)
 		if !found {
 			logger.Warn("could not find config for exited bot container")
 			continue
 		}
-		logger = log.WithField("botId", restartedBotConfig.ID)
+		if blm.isStopped(restartedBotConfig.ID) {
+			continue
+		}
+		logger = lifecycleLog.WithField("botId", restartedBotConfig.ID)
+		blm.reportExit(ctx, blm.takeStopReason(restartedBotConfig.ID), restartedBotConfig)
 		logger.Warn("restarting bot container")
 		blm.lifecycleMetrics.ActionRestart(restartedBotConfig)
 		if err := blm.botClient.StartWaitBotContainer(ctx, botContainer.ID); err != nil {
@@ -231,15 +345,16 @@ func (blm *botLifecycleManager) RestartExitedBots(ctx context.Context) error {
 
 // TearDownRunningBots tears down all running bots.
 func (blm *botLifecycleManager) TearDownRunningBots(ctx context.Context) {
-	if len(blm.runningBots) == 0 {
+	runningBots := blm.RunningBots()
+	if len(runningBots) == 0 {
 		return
 	}
-	log.WithField("count", len(blm.runningBots)).Info("tearing down running bots")
+	lifecycleLog.WithField("count", len(runningBots)).Info("tearing down running bots")
 
 	// remove all bots from the pool
-	if err := blm.botPool.RemoveBotsWithConfigs(blm.runningBots); err != nil {
+	if err := blm.botPool.RemoveBotsWithConfigs(runningBots); err != nil {
 		blm.lifecycleMetrics.SystemError("teardown.remove.bots.with.configs", err)
-		log.WithError(err).Error("error removing bots with configs")
+		lifecycleLog.WithError(err).Error("error removing bots with configs")
 	}
 
 	// then wait a little to let the bot pool process this
@@ -247,17 +362,101 @@ func (blm *botLifecycleManager) TearDownRunningBots(ctx context.Context) {
 	time.Sleep(botRemoveTimeout)
 
 	// then stop the containers
-	for _, runningBotConfig := range blm.runningBots {
+	for _, runningBotConfig := range runningBots {
+		blm.reportTearDown(ExitReasonShutdown, runningBotConfig)
 		err := blm.botClient.TearDownBot(ctx, runningBotConfig.ContainerName(), false)
 		if err != nil {
 			blm.lifecycleMetrics.BotError("teardown.bot", err, runningBotConfig.ID)
-			log.WithError(err).WithField("container", runningBotConfig.ContainerName()).
+			lifecycleLog.WithError(err).WithField("container", runningBotConfig.ContainerName()).
 				Warn("failed to tear down running bot container")
 		}
 	}
 }
 
+// RunningBots returns the configs of the bots which the node runs.
+func (blm *botLifecycleManager) RunningBots() []config.AgentConfig {
+	blm.mu.RLock()
+	defer blm.mu.RUnlock()
+	return blm.runningBots
+}
+
+// RestartBot restarts the bot container and lets the pool reconnect. A bot which was stopped
+// by the operator is started again.
+func (blm *botLifecycleManager) RestartBot(ctx context.Context, botID string) error {
+	botConfig, found := blm.findBotConfigByID(botID)
+	if !found {
+		return ErrBotNotFound
+	}
+	logger := lifecycleLog.WithField("bot", botConfig.ID)
+	logger.Info("restarting bot on request")
+
+	var botContainerID string
+	botContainers, err := blm.botClient.LoadBotContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load bot containers: %v", err)
+	}
+	for _, botContainer := range botContainers {
+		if docker.GetContainerName(botContainer) == botConfig.ContainerName() {
+			botContainerID = botContainer.ID
+			break
+		}
+	}
+	if len(botContainerID) == 0 {
+		return fmt.Errorf("bot container '%s' was not found", botConfig.ContainerName())
+	}
+	if err := blm.botClient.StopBot(ctx, botConfig); err != nil {
+		blm.lifecycleMetrics.FailureStop(err, botConfig)
+		return err
+	}
+	blm.reportExit(ctx, ExitReasonOperatorRestart, botConfig)
+	blm.lifecycleMetrics.ActionRestart(botConfig)
+	if err := blm.botClient.StartWaitBotContainer(ctx, botContainerID); err != nil {
+		blm.lifecycleMetrics.BotError("start.requested.bot.container", err, botConfig.ID)
+		return err
+	}
+
+	blm.mu.Lock()
+	delete(blm.stoppedBots, strings.ToLower(botConfig.ID))
+	blm.mu.Unlock()
+
+	if err := blm.botPool.ReconnectToBotsWithConfigs([]config.AgentConfig{botConfig}); err != nil {
+		blm.lifecycleMetrics.SystemError("reinit.bots.with.configs", fmt.Errorf("failed to reinit bots with configs: %v", err.Error()))
+	}
+	return nil
+}
+
+// StopBot stops the bot container and keeps it stopped until it is restarted on request
+// or the node restarts.
+func (blm *botLifecycleManager) StopBot(ctx context.Context, botID string) error {
+	botConfig, found := blm.findBotConfigByID(botID)
+	if !found {
+		return ErrBotNotFound
+	}
+	lifecycleLog.WithField("bot", botConfig.ID).Info("stopping bot on request")
+
+	blm.mu.Lock()
+	blm.stoppedBots[strings.ToLower(botConfig.ID)] = true
+	blm.mu.Unlock()
+
+	blm.lifecycleMetrics.StatusStopping(botConfig)
+	if err := blm.botClient.StopBot(ctx, botConfig); err != nil {
+		blm.lifecycleMetrics.FailureStop(err, botConfig)
+		return err
+	}
+	blm.reportExit(ctx, ExitReasonOperatorStop, botConfig)
+	return nil
+}
+
+// isStopped tells if the bot was stopped on request.
+func (blm *botLifecycleManager) isStopped(botID string) bool {
+	blm.mu.RLock()
+	defer blm.mu.RUnlock()
+	return blm.stoppedBots[strings.ToLower(botID)]
+}
+
 func (blm *botLifecycleManager) findBotConfig(containerName string) (config.AgentConfig, bool) {
+	blm.mu.RLock()
+	defer blm.mu.RUnlock()
 	for _, bot := range blm.runningBots {
 		if bot.ContainerName() == containerName {
 			return bot, true
@@ -267,10 +466,72 @@ func (blm *botLifecycleManager) findBotConfig(containerName string) (config.Agen
 }
 
 func (blm *botLifecycleManager) findBotConfigByID(botID string) (config.AgentConfig, bool) {
+	blm.mu.RLock()
+	defer blm.mu.RUnlock()
+	return blm.findBotConfigByIDUnsafe(botID)
+}
+
+func (blm *botLifecycleManager) findBotConfigByIDUnsafe(botID string) (config.AgentConfig, bool) {
 	for _, bot := range blm.runningBots {
-		if bot.ID == botID {
+		if strings.EqualFold(bot.ID, botID) {
 			return bot, true
 		}
 	}
 	return config.AgentConfig{}, false
 }
+
+func (blm *botLifecycleManager) getLastAssignedBots() []config.AgentConfig {
+	blm.mu.RLock()
+	defer blm.mu.RUnlock()
+	return blm.lastAssignedBots
+}
+
+func (blm *botLifecycleManager) setLastAssignedBots(assignedBots []config.AgentConfig) {
+	blm.mu.Lock()
+	defer blm.mu.Unlock()
+	blm.lastAssignedBots = assignedBots
+}
+
+func (blm *botLifecycleManager) trackManageErrs(errs ManageBotsErrors) {
+	blm.manageErrsMu.Lock()
+	defer blm.manageErrsMu.Unlock()
+
+	blm.lastManageErrs = errs
+	for _, err := range errs {
+		blm.manageErrCounts[err.Kind]++
+	}
+}
+
+// Name implements the health.Reporter interface.
+func (blm *botLifecycleManager) Name() string {
+	return "bot-lifecycle"
+}
+
+// Health implements the health.Reporter interface.
+func (blm *botLifecycleManager) Health() health.Reports {
+	blm.manageErrsMu.RLock()
+	defer blm.manageErrsMu.RUnlock()
+
+	// the node can not run any new bots without the registry or docker
+	errReport := &health.Report{
+		Name:   "bot-management.error",
+		Status: health.StatusOK,
+	}
+	if len(blm.lastManageErrs) > 0 {
+		errReport.Details = blm.lastManageErrs.Error()
+		errReport.Status = health.StatusLagging
+		if errors.Is(blm.lastManageErrs, ErrRegistryUnavailable) || errors.Is(blm.lastManageErrs, ErrDockerUnavailable) {
+			errReport.Status = health.StatusFailing
+		}
+	}
+
+	reports := health.Reports{errReport}
+	for _, errKind := range manageBotsErrKinds {
+		reports = append(reports, &health.Report{
+			Name:    "bot-management.errors." + errKind.name,
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(blm.manageErrCounts[errKind.kind], 10),
+		})
+	}
+	return reports
+}
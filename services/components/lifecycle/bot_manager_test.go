@@ -7,7 +7,9 @@ import (
 	"testing"
 
 	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
 	mock_agentgrpc "github.com/forta-network/forta-node/clients/agentgrpc/mocks"
+	"github.com/forta-network/forta-node/clients/docker"
 	mock_clients "github.com/forta-network/forta-node/clients/mocks"
 	"github.com/forta-network/forta-node/config"
 	mock_containers "github.com/forta-network/forta-node/services/components/containers/mocks"
@@ -92,6 +94,7 @@ func (s *BotLifecycleManagerTestSuite) TestAddUpdateRemove() {
 
 	s.botPool.EXPECT().RemoveBotsWithConfigs([]config.AgentConfig{removedBot})
 	s.lifecycleMetrics.EXPECT().StatusStopping([]config.AgentConfig{removedBot})
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonUnassigned, nil, removedBot)
 	s.botContainers.EXPECT().TearDownBot(gomock.Any(), removedBot.ContainerName(), true)
 
 	s.lifecycleMetrics.EXPECT().StatusRunning(latestAssigned).Times(1)
@@ -106,8 +109,138 @@ func (s *BotLifecycleManagerTestSuite) TestLoadBotsError() {
 	s.botRegistry.EXPECT().LoadAssignedBots().Return(nil, err).Times(1)
 
 	s.lifecycleMetrics.EXPECT().SystemError("load.assigned.bots", err)
+	s.lifecycleMetrics.EXPECT().SystemError("manage.bots.load", gomock.Any())
 
-	s.r.Error(s.botManager.ManageBots(context.Background()))
+	s.r.ErrorIs(s.botManager.ManageBots(context.Background()), ErrRegistryUnavailable)
+
+	reports := s.botManager.Health()
+	s.r.Equal(health.StatusFailing, reports[0].Status)
+	errCount, ok := reports.NameContains("bot-management.errors.registry-unavailable")
+	s.r.True(ok)
+	s.r.Equal("1", errCount.Details)
+}
+
+func (s *BotLifecycleManagerTestSuite) TestLaunchErrors() {
+	addedBots := []config.AgentConfig{
+		{
+			ID:    testBotID1,
+			Image: testImageRef,
+		},
+		{
+			ID:    testBotID2,
+			Image: testImageRef,
+		},
+		{
+			ID:    testBotID3,
+			Image: testImageRef,
+		},
+	}
+	s.botRegistry.EXPECT().LoadAssignedBots().Return(addedBots, nil)
+
+	pullErr := errors.New("pull error: pull access denied for bot image")
+	launchErr := errors.New("failed to create the bot container")
+	s.botContainers.EXPECT().EnsureBotImages(gomock.Any(), addedBots).Return([]error{pullErr, nil, nil})
+	s.lifecycleMetrics.EXPECT().FailurePull(pullErr, addedBots[0])
+	s.botContainers.EXPECT().LaunchBot(gomock.Any(), addedBots[1]).Return(launchErr)
+	s.lifecycleMetrics.EXPECT().FailureLaunch(launchErr, addedBots[1])
+	s.botContainers.EXPECT().LaunchBot(gomock.Any(), addedBots[2]).Return(nil)
+	s.lifecycleMetrics.EXPECT().SystemError("manage.bots.pull", gomock.Any())
+	s.lifecycleMetrics.EXPECT().SystemError("manage.bots.launch", gomock.Any())
+
+	s.botPool.EXPECT().UpdateBotsWithLatestConfigs(addedBots[2:])
+	s.lifecycleMetrics.EXPECT().StatusRunning(addedBots[2:])
+	s.botMonitor.EXPECT().MonitorBots(GetBotIDs(addedBots[2:]))
+
+	err := s.botManager.ManageBots(context.Background())
+	s.r.ErrorIs(err, ErrImagePullDenied)
+	s.r.ErrorIs(err, ErrPartialLaunch)
+	s.r.NotErrorIs(err, ErrDockerUnavailable)
+
+	var errs ManageBotsErrors
+	s.r.ErrorAs(err, &errs)
+	s.r.Len(errs, 2)
+	s.r.Equal([]string{testBotID1}, errs[0].BotIDs)
+	s.r.Equal([]string{testBotID2}, errs[1].BotIDs)
+
+	// the node can still run the other bots
+	reports := s.botManager.Health()
+	s.r.Equal(health.StatusLagging, reports[0].Status)
+}
+
+func (s *BotLifecycleManagerTestSuite) TestRegistryUnavailable() {
+	assigned := []config.AgentConfig{
+		{
+			ID:    testBotID1,
+			Image: testImageRef,
+		},
+		{
+			ID:    testBotID2,
+			Image: testImageRef,
+		},
+	}
+	launchErr := errors.New("failed to launch")
+
+	// the second bot fails to launch
+	s.botRegistry.EXPECT().LoadAssignedBots().Return(assigned, nil)
+	s.botContainers.EXPECT().EnsureBotImages(gomock.Any(), assigned).Return([]error{nil, nil})
+	s.botContainers.EXPECT().LaunchBot(gomock.Any(), assigned[0]).Return(nil)
+	s.botContainers.EXPECT().LaunchBot(gomock.Any(), assigned[1]).Return(launchErr)
+	s.lifecycleMetrics.EXPECT().FailureLaunch(launchErr, assigned[1])
+	s.lifecycleMetrics.EXPECT().SystemError("manage.bots.launch", gomock.Any())
+	s.botPool.EXPECT().UpdateBotsWithLatestConfigs(assigned[:1])
+	s.lifecycleMetrics.EXPECT().StatusRunning(assigned[:1])
+	s.botMonitor.EXPECT().MonitorBots(GetBotIDs(assigned[:1]))
+
+	s.r.ErrorIs(s.botManager.ManageBots(context.Background()), ErrPartialLaunch)
+
+	// then the registry is unavailable but the launch is retried with the last assigned bots
+	registryErr := errors.New("registry error")
+	s.botRegistry.EXPECT().LoadAssignedBots().Return(nil, registryErr)
+	s.lifecycleMetrics.EXPECT().SystemError("load.assigned.bots", registryErr)
+	s.lifecycleMetrics.EXPECT().SystemError("manage.bots.load", gomock.Any())
+	s.botContainers.EXPECT().EnsureBotImages(gomock.Any(), assigned[1:]).Return([]error{nil})
+	s.botContainers.EXPECT().LaunchBot(gomock.Any(), assigned[1]).Return(nil)
+	s.botPool.EXPECT().UpdateBotsWithLatestConfigs(assigned)
+	s.lifecycleMetrics.EXPECT().StatusRunning(assigned)
+	s.botMonitor.EXPECT().MonitorBots(GetBotIDs(assigned))
+
+	err := s.botManager.ManageBots(context.Background())
+	s.r.ErrorIs(err, ErrRegistryUnavailable)
+	s.r.NotErrorIs(err, ErrPartialLaunch)
+	s.r.Equal(assigned, s.botManager.RunningBots())
+}
+
+func (s *BotLifecycleManagerTestSuite) TestDockerUnavailable() {
+	assigned := []config.AgentConfig{
+		{
+			ID:    testBotID1,
+			Image: testImageRef,
+		},
+		{
+			ID:    testBotID2,
+			Image: testImageRef,
+		},
+	}
+	dockerErr := errors.New("Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?")
+
+	s.botRegistry.EXPECT().LoadAssignedBots().Return(assigned, nil)
+	s.botContainers.EXPECT().EnsureBotImages(gomock.Any(), assigned).Return([]error{nil, nil})
+	// the second bot is not launched after docker fails
+	s.botContainers.EXPECT().LaunchBot(gomock.Any(), assigned[0]).Return(dockerErr)
+	s.lifecycleMetrics.EXPECT().FailureLaunch(dockerErr, assigned[0])
+	s.lifecycleMetrics.EXPECT().FailureLaunch(ErrDockerUnavailable, assigned[1])
+	s.lifecycleMetrics.EXPECT().SystemError("manage.bots.launch", gomock.Any())
+	s.botPool.EXPECT().UpdateBotsWithLatestConfigs(nil)
+	s.lifecycleMetrics.EXPECT().StatusRunning()
+	s.botMonitor.EXPECT().MonitorBots(nil)
+
+	err := s.botManager.ManageBots(context.Background())
+	s.r.ErrorIs(err, ErrDockerUnavailable)
+	s.r.NotErrorIs(err, ErrPartialLaunch)
+
+	var errs ManageBotsErrors
+	s.r.ErrorAs(err, &errs)
+	s.r.Equal([]string{testBotID1, testBotID2}, errs[0].BotIDs)
 }
 
 func (s *BotLifecycleManagerTestSuite) TestRestart() {
@@ -140,9 +273,15 @@ func (s *BotLifecycleManagerTestSuite) TestRestart() {
 		},
 	}, nil).Times(1)
 
+	oomExit := &docker.ContainerExit{ExitCode: 137, OOMKilled: true, Signal: 9}
+	s.botContainers.EXPECT().GetBotExit(gomock.Any(), botConfigs[0].ContainerName()).Return(oomExit, nil)
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonOOMKilled, oomExit, botConfigs[0])
 	s.lifecycleMetrics.EXPECT().ActionRestart(botConfigs[0])
 	s.botContainers.EXPECT().StartWaitBotContainer(gomock.Any(), testContainerID1).Return(nil)
 
+	crashExit := &docker.ContainerExit{ExitCode: 1}
+	s.botContainers.EXPECT().GetBotExit(gomock.Any(), botConfigs[1].ContainerName()).Return(crashExit, nil)
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonCrash, crashExit, botConfigs[1])
 	s.lifecycleMetrics.EXPECT().ActionRestart(botConfigs[1])
 	err := errors.New("failed to start")
 	s.lifecycleMetrics.EXPECT().BotError("start.exited.bot.container", gomock.Any(), testBotID2)
@@ -172,6 +311,23 @@ func (s *BotLifecycleManagerTestSuite) TestExit() {
 	s.botContainers.EXPECT().StopBot(gomock.Any(), botConfigs[1])
 
 	s.r.NoError(s.botManager.ExitInactiveBots(context.Background()))
+
+	// the exit is reported as inactive when the bot is restarted
+	s.botContainers.EXPECT().LoadBotContainers(gomock.Any()).Return([]types.Container{
+		{
+			ID:    testContainerID2,
+			Names: []string{fmt.Sprintf("/%s", botConfigs[1].ContainerName())},
+			State: "exited",
+		},
+	}, nil)
+	exit := &docker.ContainerExit{ExitCode: 143, Signal: 15}
+	s.botContainers.EXPECT().GetBotExit(gomock.Any(), botConfigs[1].ContainerName()).Return(exit, nil)
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonInactive, exit, botConfigs[1])
+	s.lifecycleMetrics.EXPECT().ActionRestart(botConfigs[1])
+	s.botContainers.EXPECT().StartWaitBotContainer(gomock.Any(), testContainerID2)
+	s.botPool.EXPECT().ReconnectToBotsWithConfigs([]config.AgentConfig{botConfigs[1]})
+
+	s.r.NoError(s.botManager.RestartExitedBots(context.Background()))
 }
 
 func (s *BotLifecycleManagerTestSuite) TestCleanup() {
@@ -193,11 +349,13 @@ func (s *BotLifecycleManagerTestSuite) TestCleanup() {
 
 	s.botContainers.EXPECT().LoadBotContainers(gomock.Any()).Return([]types.Container{
 		{
-			ID:    testContainerID,
-			Names: []string{dockerContainerName},
-			State: "exited",
+			ID:     testContainerID,
+			Names:  []string{dockerContainerName},
+			State:  "exited",
+			Labels: map[string]string{docker.LabelFortaBotID: unusedBotConfig.ID},
 		},
 	}, nil).Times(1)
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonUnused, nil, config.AgentConfig{ID: unusedBotConfig.ID})
 	s.botContainers.EXPECT().TearDownBot(gomock.Any(), unusedBotConfig.ContainerName(), true).Return(nil)
 
 	s.r.NoError(s.botManager.CleanupUnusedBots(context.Background()))
@@ -217,8 +375,52 @@ func (s *BotLifecycleManagerTestSuite) TestTearDown() {
 	s.botManager.runningBots = botConfigs
 
 	s.botPool.EXPECT().RemoveBotsWithConfigs(botConfigs)
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonShutdown, nil, botConfigs[0])
 	s.botContainers.EXPECT().TearDownBot(gomock.Any(), botConfigs[0].ContainerName(), false).Return(nil)
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonShutdown, nil, botConfigs[1])
 	s.botContainers.EXPECT().TearDownBot(gomock.Any(), botConfigs[1].ContainerName(), false).Return(nil)
 
 	s.botManager.TearDownRunningBots(context.Background())
 }
+
+func (s *BotLifecycleManagerTestSuite) TestStopAndRestartOnRequest() {
+	botConfigs := []config.AgentConfig{
+		{
+			ID:    testBotID1,
+			Image: testImageRef,
+		},
+	}
+
+	s.botManager.runningBots = botConfigs
+
+	s.r.ErrorIs(s.botManager.StopBot(context.Background(), testBotID2), ErrBotNotFound)
+
+	s.lifecycleMetrics.EXPECT().StatusStopping(botConfigs[0])
+	s.botContainers.EXPECT().StopBot(gomock.Any(), botConfigs[0])
+	exit := &docker.ContainerExit{ExitCode: 143, Signal: 15}
+	s.botContainers.EXPECT().GetBotExit(gomock.Any(), botConfigs[0].ContainerName()).Return(exit, nil)
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonOperatorStop, exit, botConfigs[0])
+	s.r.NoError(s.botManager.StopBot(context.Background(), testBotID1))
+
+	// the stopped bot is not restarted or stopped again automatically
+	dockerContainerName := fmt.Sprintf("/%s", botConfigs[0].ContainerName())
+	s.botContainers.EXPECT().LoadBotContainers(gomock.Any()).Return([]types.Container{
+		{
+			ID:    testContainerID1,
+			Names: []string{dockerContainerName},
+			State: "exited",
+		},
+	}, nil).Times(2)
+	s.r.NoError(s.botManager.RestartExitedBots(context.Background()))
+	s.botMonitor.EXPECT().GetInactiveBots().Return([]string{testBotID1})
+	s.r.NoError(s.botManager.ExitInactiveBots(context.Background()))
+
+	s.botContainers.EXPECT().StopBot(gomock.Any(), botConfigs[0])
+	s.botContainers.EXPECT().GetBotExit(gomock.Any(), botConfigs[0].ContainerName()).Return(exit, nil)
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonOperatorRestart, exit, botConfigs[0])
+	s.lifecycleMetrics.EXPECT().ActionRestart(botConfigs[0])
+	s.botContainers.EXPECT().StartWaitBotContainer(gomock.Any(), testContainerID1)
+	s.botPool.EXPECT().ReconnectToBotsWithConfigs([]config.AgentConfig{botConfigs[0]})
+	s.r.NoError(s.botManager.RestartBot(context.Background(), testBotID1))
+	s.r.False(s.botManager.isStopped(testBotID1))
+}
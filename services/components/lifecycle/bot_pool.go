@@ -66,7 +66,7 @@ func NewBotPool(
 func (bp *botPool) logBotWait() {
 	if bp.botWg != nil {
 		bp.botWg.Wait()
-		log.Info("started all bots")
+		lifecycleLog.Info("started all bots")
 	}
 }
 
@@ -145,6 +145,14 @@ func (bp *botPool) startBotClient(botConfig config.AgentConfig) botio.BotClient
 	return botClient
 }
 
+func (bp *botPool) reinitializeBotClient(botClient botio.BotClient) {
+	if bp.waitInit {
+		botClient.Reinitialize()
+	} else {
+		go botClient.Reinitialize()
+	}
+}
+
 // RemoveBotsWithConfigs closes and discards the bots to be removed.
 func (bp *botPool) RemoveBotsWithConfigs(removedBotConfigs messaging.AgentPayload) error {
 	bp.mu.Lock()
@@ -183,6 +191,12 @@ func (bp *botPool) ReconnectToBotsWithConfigs(reconnectedBots messaging.AgentPay
 	var latestBotClients []botio.BotClient
 	for _, botClient := range bp.botClients {
 		botConfig, found := FindBot(botClient.Config().ContainerName(), reconnectedBots)
+		// if the connection is down, the client is already reconnecting so only reinitialize the bot
+		if found && !botClient.IsClosed() && botClient.ConnectionDown() {
+			botLogger(botConfig).Info("connection to bot is down - reinitializing with the same client")
+			bp.reinitializeBotClient(botClient)
+			found = false
+		}
 		// if found, close old and replace with new
 		if found {
 			_ = botClient.Close()
@@ -213,7 +227,7 @@ func (bp *botPool) WaitForAll() {
 }
 
 func botLogger(botConfig config.AgentConfig) *log.Entry {
-	return log.WithField("bot", botConfig.ID).WithField("container", botConfig.ContainerName())
+	return lifecycleLog.WithField("bot", botConfig.ID).WithField("container", botConfig.ContainerName())
 }
 
 func (bp *botPool) getBotClient(containerName string) (botio.BotClient, bool) {
@@ -125,6 +125,8 @@ func (s *BotPoolTestSuite) TestReconnect() {
 
 	s.botPool.botClients = []botio.BotClient{s.botClient1}
 	s.botClient1.EXPECT().Config().Return(assigned[0]).AnyTimes()
+	s.botClient1.EXPECT().IsClosed().Return(false)
+	s.botClient1.EXPECT().ConnectionDown().Return(false)
 	s.botClient1.EXPECT().Close()
 	s.botClientFactory.EXPECT().NewBotClient(gomock.Any(), assigned[0]).Return(s.botClient2)
 	s.botClient2.EXPECT().Initialize()
@@ -136,6 +138,27 @@ func (s *BotPoolTestSuite) TestReconnect() {
 	s.r.Equal(s.botPool.botClients[0], s.botClient2)
 }
 
+func (s *BotPoolTestSuite) TestReconnect_ConnectionDown() {
+	assigned := []config.AgentConfig{
+		{
+			ID:    testBotID1,
+			Image: testImageRef,
+		},
+	}
+
+	s.botPool.botClients = []botio.BotClient{s.botClient1}
+	s.botClient1.EXPECT().Config().Return(assigned[0]).AnyTimes()
+	s.botClient1.EXPECT().IsClosed().Return(false)
+	s.botClient1.EXPECT().ConnectionDown().Return(true)
+	// the client is kept and the bot is initialized again
+	s.botClient1.EXPECT().Reinitialize()
+
+	s.botPool.ReconnectToBotsWithConfigs(assigned)
+
+	s.r.Len(s.botPool.botClients, 1)
+	s.r.Equal(s.botPool.botClients[0], s.botClient1)
+}
+
 func (s *BotPoolTestSuite) TestWaitForAll() {
 	latest := []config.AgentConfig{
 		{
@@ -0,0 +1,132 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The kinds of the errors which can be returned from the bot management.
+var (
+	ErrRegistryUnavailable = errors.New("bot registry is unavailable")
+	ErrDockerUnavailable   = errors.New("docker is unavailable")
+	ErrImagePullDenied     = errors.New("bot image pull is denied")
+	ErrPartialLaunch       = errors.New("some bots failed to launch")
+)
+
+// The phases of the bot management
+const (
+	PhaseLoad     = "load"
+	PhaseTeardown = "teardown"
+	PhasePull     = "pull"
+	PhaseLaunch   = "launch"
+)
+
+// manageBotsErrKinds are ordered by how much they affect the node and are named for the health reports.
+var manageBotsErrKinds = []struct {
+	kind error
+	name string
+}{
+	{kind: ErrRegistryUnavailable, name: "registry-unavailable"},
+	{kind: ErrDockerUnavailable, name: "docker-unavailable"},
+	{kind: ErrImagePullDenied, name: "image-pull-denied"},
+	{kind: ErrPartialLaunch, name: "partial-launch"},
+}
+
+// ManageBotsError is an error of one kind from a bot management phase and the bots it affected.
+type ManageBotsError struct {
+	Phase  string
+	Kind   error
+	BotIDs []string
+	// Err is the first error of this kind.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ManageBotsError) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Phase, e.Kind)
+	if len(e.BotIDs) > 0 {
+		msg = fmt.Sprintf("%s (bots: %s)", msg, strings.Join(e.BotIDs, ", "))
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+	return msg
+}
+
+// Is tells if the error is of the target kind.
+func (e *ManageBotsError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the underlying error.
+func (e *ManageBotsError) Unwrap() error {
+	return e.Err
+}
+
+// ManageBotsErrors are all errors from a bot management cycle, aggregated by phase and kind.
+type ManageBotsErrors []*ManageBotsError
+
+// Error implements the error interface.
+func (errs ManageBotsErrors) Error() string {
+	var msgs []string
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is tells if any of the errors is of the target kind.
+func (errs ManageBotsErrors) Is(target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds the error of a bot to the error of the same phase and kind.
+func (errs ManageBotsErrors) Add(phase string, kind error, botID string, err error) ManageBotsErrors {
+	for _, existing := range errs {
+		if existing.Phase == phase && existing.Kind == kind {
+			if len(botID) > 0 {
+				existing.BotIDs = append(existing.BotIDs, botID)
+			}
+			return errs
+		}
+	}
+	newErr := &ManageBotsError{Phase: phase, Kind: kind, Err: err}
+	if len(botID) > 0 {
+		newErr.BotIDs = []string{botID}
+	}
+	return append(errs, newErr)
+}
+
+// pullErrKind tells why a bot image could not be pulled.
+func pullErrKind(err error) error {
+	errStr := strings.ToLower(err.Error())
+	switch {
+	case isDockerUnavailable(errStr):
+		return ErrDockerUnavailable
+	case strings.Contains(errStr, "denied") || strings.Contains(errStr, "unauthorized") ||
+		strings.Contains(errStr, "forbidden"):
+		return ErrImagePullDenied
+	default:
+		return ErrPartialLaunch
+	}
+}
+
+// launchErrKind tells why a bot could not be launched.
+func launchErrKind(err error) error {
+	if isDockerUnavailable(strings.ToLower(err.Error())) {
+		return ErrDockerUnavailable
+	}
+	return ErrPartialLaunch
+}
+
+// isDockerUnavailable checks for the errors of the Docker client when the daemon can not be reached.
+func isDockerUnavailable(errStr string) bool {
+	return strings.Contains(errStr, "cannot connect to the docker daemon") ||
+		strings.Contains(errStr, "is the docker daemon running")
+}
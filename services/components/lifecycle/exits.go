@@ -0,0 +1,75 @@
+package lifecycle
+
+import (
+	"context"
+	"strings"
+
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+)
+
+// The reasons of the bot container exits
+const (
+	ExitReasonCrash     = "crash"
+	ExitReasonClean     = "clean"
+	ExitReasonOOMKilled = "oom-killed"
+	ExitReasonSignal    = "signal"
+
+	ExitReasonInactive        = "inactive"
+	ExitReasonOperatorStop    = "operator-stop"
+	ExitReasonOperatorRestart = "operator-restart"
+	ExitReasonUnassigned      = "unassigned"
+	ExitReasonUnused          = "unused"
+	ExitReasonShutdown        = "shutdown"
+)
+
+// GetExitReason tells why a bot container exited on its own.
+func GetExitReason(exit *docker.ContainerExit) string {
+	switch {
+	case exit.OOMKilled:
+		return ExitReasonOOMKilled
+	case exit.ExitCode == 0:
+		return ExitReasonClean
+	case exit.Signal > 0:
+		return ExitReasonSignal
+	default:
+		return ExitReasonCrash
+	}
+}
+
+// reportExit inspects the exited bot container and reports the exit with the reason. The exits
+// without a reason are classified by the exit code.
+func (blm *botLifecycleManager) reportExit(ctx context.Context, reason string, botConfig config.AgentConfig) {
+	exit, err := blm.botClient.GetBotExit(ctx, botConfig.ContainerName())
+	if err != nil {
+		lifecycleLog.WithError(err).WithField("container", botConfig.ContainerName()).
+			Warn("failed to get the bot container exit")
+	}
+	if len(reason) == 0 {
+		reason = ExitReasonCrash
+		if exit != nil {
+			reason = GetExitReason(exit)
+		}
+	}
+	blm.lifecycleMetrics.StatusExited(reason, exit, botConfig)
+}
+
+// reportTearDown reports the exit of a bot container which is about to be removed.
+func (blm *botLifecycleManager) reportTearDown(reason string, botConfig config.AgentConfig) {
+	blm.lifecycleMetrics.StatusExited(reason, nil, botConfig)
+}
+
+func (blm *botLifecycleManager) setStopReason(botID, reason string) {
+	blm.mu.Lock()
+	defer blm.mu.Unlock()
+	blm.stopReasons[strings.ToLower(botID)] = reason
+}
+
+func (blm *botLifecycleManager) takeStopReason(botID string) string {
+	blm.mu.Lock()
+	defer blm.mu.Unlock()
+	botID = strings.ToLower(botID)
+	reason := blm.stopReasons[botID]
+	delete(blm.stopReasons, botID)
+	return reason
+}
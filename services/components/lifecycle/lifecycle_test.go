@@ -9,6 +9,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/forta-network/forta-core-go/protocol"
 	mock_agentgrpc "github.com/forta-network/forta-node/clients/agentgrpc/mocks"
+	"github.com/forta-network/forta-node/clients/docker"
 	mock_clients "github.com/forta-network/forta-node/clients/mocks"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/botio"
@@ -78,8 +79,9 @@ func (s *LifecycleTestSuite) SetupTest() {
 	s.dialer = mock_agentgrpc.NewMockBotDialer(ctrl)
 	s.resultChannels = botreq.MakeResultChannels()
 	s.botMonitor = mock_lifecycle.NewMockBotMonitor(ctrl)
+	s.botGrpc.EXPECT().WatchConnState(gomock.Any(), gomock.Any()).AnyTimes()
 
-	botClientFactory := botio.NewBotClientFactory(s.resultChannels.SendOnly(), s.msgClient, s.lifecycleMetrics, s.dialer)
+	botClientFactory := botio.NewBotClientFactory(s.resultChannels.SendOnly(), s.msgClient, s.lifecycleMetrics, s.dialer, config.AgentGrpcConfig{})
 	s.botPool = NewBotPool(context.Background(), s.lifecycleMetrics, botClientFactory, 0)
 	s.botPool.waitInit = true // hack to make testing synchronous
 	s.botManager = NewManager(s.botRegistry, s.botContainers, s.botPool, s.lifecycleMetrics, s.botMonitor)
@@ -106,6 +108,7 @@ func (s *LifecycleTestSuite) TestDownloadTimeout() {
 	s.botContainers.EXPECT().EnsureBotImages(gomock.Any(), assigned).
 		Return([]error{err}).Times(1)
 	s.lifecycleMetrics.EXPECT().FailurePull(err, assigned[0]).Times(1)
+	s.lifecycleMetrics.EXPECT().SystemError("manage.bots.pull", gomock.Any()).Times(1)
 	s.lifecycleMetrics.EXPECT().StatusRunning().Times(1) // not bots running due to download failure
 
 	s.botContainers.EXPECT().EnsureBotImages(gomock.Any(), assigned).
@@ -124,7 +127,7 @@ func (s *LifecycleTestSuite) TestDownloadTimeout() {
 	s.botMonitor.EXPECT().MonitorBots(GetBotIDs(assigned)).Times(1)
 
 	// when the bot manager manages the assigned bots over time
-	s.r.NoError(s.botManager.ManageBots(context.Background()))
+	s.r.ErrorIs(s.botManager.ManageBots(context.Background()), ErrPartialLaunch)
 	s.r.NoError(s.botManager.ManageBots(context.Background()))
 }
 
@@ -150,6 +153,7 @@ func (s *LifecycleTestSuite) TestLaunchFailure() {
 		Return([]error{nil}).Times(1)
 	s.botContainers.EXPECT().LaunchBot(gomock.Any(), assigned[0]).Return(err).Times(1)
 	s.lifecycleMetrics.EXPECT().FailureLaunch(err, assigned[0]).Times(1)
+	s.lifecycleMetrics.EXPECT().SystemError("manage.bots.launch", gomock.Any()).Times(1)
 	s.lifecycleMetrics.EXPECT().StatusRunning().Times(1) // not bots running due to download failure
 
 	s.botContainers.EXPECT().EnsureBotImages(gomock.Any(), assigned).
@@ -168,7 +172,7 @@ func (s *LifecycleTestSuite) TestLaunchFailure() {
 	s.botMonitor.EXPECT().MonitorBots(nil).Times(1)
 
 	// when the bot manager manages the assigned bots over time
-	s.r.NoError(s.botManager.ManageBots(context.Background()))
+	s.r.ErrorIs(s.botManager.ManageBots(context.Background()), ErrPartialLaunch)
 	s.r.NoError(s.botManager.ManageBots(context.Background()))
 }
 
@@ -296,6 +300,9 @@ func (s *LifecycleTestSuite) TestExitedRestarted() {
 		},
 	}, nil).Times(1)
 
+	exit := &docker.ContainerExit{ExitCode: 1}
+	s.botContainers.EXPECT().GetBotExit(gomock.Any(), assigned[0].ContainerName()).Return(exit, nil)
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonCrash, exit, assigned[0])
 	s.lifecycleMetrics.EXPECT().ActionRestart(assigned[0])
 	s.botContainers.EXPECT().StartWaitBotContainer(gomock.Any(), testContainerID).Return(nil)
 	s.botMonitor.EXPECT().MonitorBots(GetBotIDs(assigned)).Times(2)
@@ -354,6 +361,9 @@ func (s *LifecycleTestSuite) TestInactiveRestarted() {
 		},
 	}, nil).Times(1)
 
+	exit := &docker.ContainerExit{ExitCode: 137, Signal: 9}
+	s.botContainers.EXPECT().GetBotExit(gomock.Any(), assigned[0].ContainerName()).Return(exit, nil)
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonInactive, exit, assigned[0])
 	s.lifecycleMetrics.EXPECT().ActionRestart(assigned[0])
 	s.botContainers.EXPECT().StartWaitBotContainer(gomock.Any(), testContainerID).Return(nil)
 
@@ -393,6 +403,7 @@ func (s *LifecycleTestSuite) TestUnassigned() {
 
 	// and should shortly be torn down
 	s.lifecycleMetrics.EXPECT().StatusStopping(assigned[0])
+	s.lifecycleMetrics.EXPECT().StatusExited(ExitReasonUnassigned, nil, assigned[0])
 	s.botContainers.EXPECT().TearDownBot(gomock.Any(), assigned[0].ContainerName(), true).Return(nil)
 	s.lifecycleMetrics.EXPECT().StatusRunning().Times(1)
 	s.lifecycleMetrics.EXPECT().ClientClose(assigned[0])
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	config "github.com/forta-network/forta-node/config"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -76,6 +77,20 @@ func (mr *MockBotLifecycleManagerMockRecorder) ManageBots(ctx interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ManageBots", reflect.TypeOf((*MockBotLifecycleManager)(nil).ManageBots), ctx)
 }
 
+// RestartBot mocks base method.
+func (m *MockBotLifecycleManager) RestartBot(ctx context.Context, botID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestartBot", ctx, botID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestartBot indicates an expected call of RestartBot.
+func (mr *MockBotLifecycleManagerMockRecorder) RestartBot(ctx, botID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestartBot", reflect.TypeOf((*MockBotLifecycleManager)(nil).RestartBot), ctx, botID)
+}
+
 // RestartExitedBots mocks base method.
 func (m *MockBotLifecycleManager) RestartExitedBots(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -90,6 +105,34 @@ func (mr *MockBotLifecycleManagerMockRecorder) RestartExitedBots(ctx interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestartExitedBots", reflect.TypeOf((*MockBotLifecycleManager)(nil).RestartExitedBots), ctx)
 }
 
+// RunningBots mocks base method.
+func (m *MockBotLifecycleManager) RunningBots() []config.AgentConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunningBots")
+	ret0, _ := ret[0].([]config.AgentConfig)
+	return ret0
+}
+
+// RunningBots indicates an expected call of RunningBots.
+func (mr *MockBotLifecycleManagerMockRecorder) RunningBots() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunningBots", reflect.TypeOf((*MockBotLifecycleManager)(nil).RunningBots))
+}
+
+// StopBot mocks base method.
+func (m *MockBotLifecycleManager) StopBot(ctx context.Context, botID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopBot", ctx, botID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopBot indicates an expected call of StopBot.
+func (mr *MockBotLifecycleManagerMockRecorder) StopBot(ctx, botID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopBot", reflect.TypeOf((*MockBotLifecycleManager)(nil).StopBot), ctx, botID)
+}
+
 // TearDownRunningBots mocks base method.
 func (m *MockBotLifecycleManager) TearDownRunningBots(ctx context.Context) {
 	m.ctrl.T.Helper()
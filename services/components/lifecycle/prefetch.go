@@ -0,0 +1,248 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/registry"
+)
+
+// Timeouts
+var (
+	prefetchPullTimeout = time.Minute * 10
+)
+
+// UpcomingBotsLoader is implemented by the bot registries which know the bots before they are assigned.
+type UpcomingBotsLoader interface {
+	LoadUpcomingBots() ([]config.AgentConfig, error)
+}
+
+// BotImagePrefetcher pulls the bot images before the bots are assigned.
+type BotImagePrefetcher interface {
+	PrefetchBotImages(ctx context.Context) error
+	health.Reporter
+}
+
+type botImagePrefetcher struct {
+	cfg         config.BotPrefetchConfig
+	botRegistry registry.BotRegistry
+	botManager  BotLifecycleManager
+	imageClient clients.DockerClient
+
+	// prefetched are the sizes of the pulled images which are not used by the running bots yet
+	prefetched map[string]int64
+	// tooLarge are not pulled again because they do not fit in the disk budget
+	tooLarge map[string]bool
+	mu       sync.RWMutex
+}
+
+var _ BotImagePrefetcher = &botImagePrefetcher{}
+
+// NewBotImagePrefetcher creates a new bot image prefetcher.
+func NewBotImagePrefetcher(
+	cfg config.BotPrefetchConfig, botRegistry registry.BotRegistry,
+	botManager BotLifecycleManager, imageClient clients.DockerClient,
+) *botImagePrefetcher {
+	return &botImagePrefetcher{
+		cfg:         cfg,
+		botRegistry: botRegistry,
+		botManager:  botManager,
+		imageClient: imageClient,
+		prefetched:  make(map[string]int64),
+		tooLarge:    make(map[string]bool),
+	}
+}
+
+// PrefetchBotImages pulls the images of the upcoming bots and the configured images until the disk
+// budget is used up. The prefetched images which are not needed anymore are removed.
+func (bip *botImagePrefetcher) PrefetchBotImages(ctx context.Context) error {
+	wantedImages, err := bip.loadWantedImages()
+	if err != nil {
+		// the configured images can still be prefetched
+		lifecycleLog.WithError(err).Warn("failed to load the upcoming bots for prefetching")
+	}
+	wanted := make(map[string]bool)
+	for _, image := range wantedImages {
+		wanted[image] = true
+	}
+	inUse := bip.imagesInUse()
+
+	for image := range bip.getPrefetched() {
+		switch {
+		case inUse[image]:
+			// the running bots use the image now so it is not prefetched anymore
+			bip.setPrefetched(image, 0, false)
+
+		case !wanted[image]:
+			if err := bip.imageClient.RemoveImage(ctx, image); err != nil {
+				lifecycleLog.WithError(err).WithField("image", image).Warn("failed to remove the unneeded prefetched image")
+				continue
+			}
+			bip.setPrefetched(image, 0, false)
+		}
+	}
+
+	budget := int64(bip.cfg.MaxDiskMB) * 1024 * 1024
+	for _, image := range wantedImages {
+		if inUse[image] || bip.isPrefetchedOrSkipped(image) {
+			continue
+		}
+		logger := lifecycleLog.WithField("image", image)
+		usedDisk := bip.usedDisk()
+		if usedDisk >= budget {
+			logger.WithField("usedDiskMb", usedDisk/1024/1024).Info("prefetch disk budget is used up - skipping the rest")
+			break
+		}
+		exists, err := bip.imageClient.HasLocalImage(ctx, image)
+		if err != nil {
+			logger.WithError(err).Warn("failed to check the local image before prefetching")
+			continue
+		}
+		if exists {
+			continue
+		}
+		size, err := bip.pull(ctx, image)
+		if err != nil {
+			logger.WithError(err).Warn("failed to prefetch the bot image")
+			continue
+		}
+		if usedDisk+size > budget {
+			logger.WithField("sizeMb", size/1024/1024).Warn("prefetched image does not fit in the disk budget - removing")
+			if err := bip.imageClient.RemoveImage(ctx, image); err != nil {
+				logger.WithError(err).Warn("failed to remove the prefetched image")
+			}
+			bip.setTooLarge(image)
+			continue
+		}
+		bip.setPrefetched(image, size, true)
+		logger.WithField("sizeMb", size/1024/1024).Info("prefetched the bot image")
+	}
+	return err
+}
+
+func (bip *botImagePrefetcher) pull(ctx context.Context, image string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, prefetchPullTimeout)
+	defer cancel()
+
+	if err := bip.imageClient.PullImage(ctx, image); err != nil {
+		return 0, err
+	}
+	size, err := bip.imageClient.GetImageSize(ctx, image)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the image size: %v", err)
+	}
+	return size, nil
+}
+
+// loadWantedImages returns the images of the upcoming bots and the configured images in order.
+func (bip *botImagePrefetcher) loadWantedImages() (images []string, err error) {
+	var upcomingBots []config.AgentConfig
+	if loader, ok := bip.botRegistry.(UpcomingBotsLoader); ok {
+		upcomingBots, err = loader.LoadUpcomingBots()
+	}
+	seen := make(map[string]bool)
+	add := func(image string) {
+		if len(image) > 0 && !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	for _, botImage := range botImages(upcomingBots) {
+		add(botImage)
+	}
+	for _, image := range bip.cfg.Images {
+		add(image)
+	}
+	return
+}
+
+func (bip *botImagePrefetcher) imagesInUse() map[string]bool {
+	inUse := make(map[string]bool)
+	for _, image := range botImages(bip.botManager.RunningBots()) {
+		inUse[image] = true
+	}
+	return inUse
+}
+
+func botImages(botConfigs []config.AgentConfig) (images []string) {
+	for _, botConfig := range botConfigs {
+		images = append(images, botConfig.Image)
+		for _, dep := range botConfig.Dependencies {
+			images = append(images, dep.Image)
+		}
+	}
+	return
+}
+
+func (bip *botImagePrefetcher) getPrefetched() map[string]int64 {
+	bip.mu.RLock()
+	defer bip.mu.RUnlock()
+	prefetched := make(map[string]int64)
+	for image, size := range bip.prefetched {
+		prefetched[image] = size
+	}
+	return prefetched
+}
+
+func (bip *botImagePrefetcher) setPrefetched(image string, size int64, prefetched bool) {
+	bip.mu.Lock()
+	defer bip.mu.Unlock()
+	if prefetched {
+		bip.prefetched[image] = size
+	} else {
+		delete(bip.prefetched, image)
+	}
+}
+
+func (bip *botImagePrefetcher) setTooLarge(image string) {
+	bip.mu.Lock()
+	defer bip.mu.Unlock()
+	bip.tooLarge[image] = true
+}
+
+func (bip *botImagePrefetcher) isPrefetchedOrSkipped(image string) bool {
+	bip.mu.RLock()
+	defer bip.mu.RUnlock()
+	_, ok := bip.prefetched[image]
+	return ok || bip.tooLarge[image]
+}
+
+func (bip *botImagePrefetcher) usedDisk() (total int64) {
+	bip.mu.RLock()
+	defer bip.mu.RUnlock()
+	for _, size := range bip.prefetched {
+		total += size
+	}
+	return
+}
+
+// Name implements the health.Reporter interface.
+func (bip *botImagePrefetcher) Name() string {
+	return "bot-prefetch"
+}
+
+// Health implements the health.Reporter interface.
+func (bip *botImagePrefetcher) Health() health.Reports {
+	bip.mu.RLock()
+	count := len(bip.prefetched)
+	bip.mu.RUnlock()
+
+	return health.Reports{
+		&health.Report{
+			Name:    "bot-prefetch.images",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(count),
+		},
+		&health.Report{
+			Name:    "bot-prefetch.disk.mb",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatInt(bip.usedDisk()/1024/1024, 10),
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	mock_lifecycle "github.com/forta-network/forta-node/services/components/lifecycle/mocks"
+	mock_registry "github.com/forta-network/forta-node/services/components/registry/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type upcomingBotRegistry struct {
+	*mock_registry.MockBotRegistry
+	upcomingBots []config.AgentConfig
+}
+
+func (r *upcomingBotRegistry) LoadUpcomingBots() ([]config.AgentConfig, error) {
+	return r.upcomingBots, nil
+}
+
+func TestBotImagePrefetcher(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	imageClient := mock_clients.NewMockDockerClient(ctrl)
+	botManager := mock_lifecycle.NewMockBotLifecycleManager(ctrl)
+
+	const (
+		image1 = "image1"
+		image2 = "image2"
+		image3 = "image3"
+		mb     = 1024 * 1024
+	)
+	botRegistry := &upcomingBotRegistry{
+		MockBotRegistry: mock_registry.NewMockBotRegistry(ctrl),
+		upcomingBots: []config.AgentConfig{
+			{ID: testBotID1, Image: image1},
+			{ID: testBotID2, Image: image2},
+		},
+	}
+	prefetcher := NewBotImagePrefetcher(config.BotPrefetchConfig{
+		Images:    []string{image3},
+		MaxDiskMB: 100,
+	}, botRegistry, botManager, imageClient)
+
+	// the second image exceeds the budget and the third one is not pulled after that
+	botManager.EXPECT().RunningBots().Return(nil)
+	imageClient.EXPECT().HasLocalImage(gomock.Any(), image1).Return(false, nil)
+	imageClient.EXPECT().PullImage(gomock.Any(), image1).Return(nil)
+	imageClient.EXPECT().GetImageSize(gomock.Any(), image1).Return(int64(60*mb), nil)
+	imageClient.EXPECT().HasLocalImage(gomock.Any(), image2).Return(false, nil)
+	imageClient.EXPECT().PullImage(gomock.Any(), image2).Return(nil)
+	imageClient.EXPECT().GetImageSize(gomock.Any(), image2).Return(int64(50*mb), nil)
+	imageClient.EXPECT().RemoveImage(gomock.Any(), image2).Return(nil)
+	imageClient.EXPECT().HasLocalImage(gomock.Any(), image3).Return(false, nil)
+	imageClient.EXPECT().PullImage(gomock.Any(), image3).Return(errors.New("pull failed"))
+
+	r.NoError(prefetcher.PrefetchBotImages(context.Background()))
+	r.Equal(int64(60*mb), prefetcher.usedDisk())
+
+	// the first bot is running now so its image frees up the budget for the third image
+	botManager.EXPECT().RunningBots().Return([]config.AgentConfig{{ID: testBotID1, Image: image1}})
+	imageClient.EXPECT().HasLocalImage(gomock.Any(), image3).Return(false, nil)
+	imageClient.EXPECT().PullImage(gomock.Any(), image3).Return(nil)
+	imageClient.EXPECT().GetImageSize(gomock.Any(), image3).Return(int64(10*mb), nil)
+
+	r.NoError(prefetcher.PrefetchBotImages(context.Background()))
+	r.Equal(int64(10*mb), prefetcher.usedDisk())
+
+	// the third image is removed after it is not wanted anymore
+	prefetcher.cfg.Images = nil
+	botManager.EXPECT().RunningBots().Return(nil)
+	imageClient.EXPECT().RemoveImage(gomock.Any(), image3).Return(nil)
+	// the first bot stopped but its image is still local
+	imageClient.EXPECT().HasLocalImage(gomock.Any(), image1).Return(true, nil)
+
+	r.NoError(prefetcher.PrefetchBotImages(context.Background()))
+	r.Zero(prefetcher.usedDisk())
+	r.Equal("0", prefetcher.Health()[0].Details)
+}
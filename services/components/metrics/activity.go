@@ -2,14 +2,16 @@ package metrics
 
 import "github.com/forta-network/forta-core-go/protocol"
 
-// FindActiveBotsFromMetrics finds the active bots from given bot metrics.
+// FindActiveBotsFromMetrics finds the active bots from given bot metrics. The bots which serve the
+// health checks are active even when they do not receive any requests.
 func FindActiveBotsFromMetrics(allBotMetrics []*protocol.AgentMetrics) (found []string) {
 	for _, botMetrics := range allBotMetrics {
 		botID := botMetrics.AgentId
 		for _, botMetric := range botMetrics.Metrics {
 			if botMetric.Name == MetricTxLatency ||
 				botMetric.Name == MetricBlockLatency ||
-				botMetric.Name == MetricCombinerLatency {
+				botMetric.Name == MetricCombinerLatency ||
+				botMetric.Name == MetricHealthServing {
 				found = append(found, botID)
 				break
 			}
@@ -0,0 +1,279 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+)
+
+// Bot error categories
+const (
+	ErrorCategoryTimeout         = "timeout"
+	ErrorCategoryConnection      = "connection"
+	ErrorCategoryInvalidResponse = "invalid-response"
+	ErrorCategoryBotError        = "bot-error"
+	ErrorCategoryOther           = "other"
+)
+
+const botStatsResolution = time.Minute
+
+var (
+	requestMetrics = map[string]bool{MetricTxRequest: true, MetricBlockRequest: true, MetricCombinerRequest: true}
+	successMetrics = map[string]bool{MetricTxSuccess: true, MetricBlockSuccess: true, MetricCombinerSuccess: true}
+	latencyMetrics = map[string]bool{MetricTxLatency: true, MetricBlockLatency: true, MetricCombinerLatency: true}
+	timeoutMetrics = map[string]bool{MetricTxTimeout: true, MetricBlockTimeout: true, MetricCombinerTimeout: true}
+	errorMetrics   = map[string]bool{MetricTxError: true, MetricBlockError: true, MetricCombinerError: true}
+	invokeErrors   = map[string]bool{
+		"agent.error.evaluate.tx":    true,
+		"agent.error.evaluate.block": true,
+		"agent.error.evaluate.alert": true,
+	}
+)
+
+// BotStats are the statistics of a bot over a time window.
+type BotStats struct {
+	BotID        string            `json:"botId"`
+	Window       string            `json:"window"`
+	Requests     uint64            `json:"requests"`
+	SuccessRate  float64           `json:"successRate"`
+	LatencyP50Ms float64           `json:"latencyP50Ms"`
+	LatencyP95Ms float64           `json:"latencyP95Ms"`
+	Errors       map[string]uint64 `json:"errors"`
+}
+
+// statsBucket contains the counts of a bot in a minute.
+type statsBucket struct {
+	time       time.Time
+	requests   uint64
+	successes  uint64
+	latencies  []uint64 // counts per durationBuckets, the last one is the overflow
+	maxLatency float64
+	errors     map[string]uint64
+}
+
+// BotStatsTracker computes the rolling statistics of the bots from the metrics which the
+// scanner and the bot clients publish.
+type BotStatsTracker struct {
+	windows   []time.Duration
+	retention time.Duration
+	bots      map[string][]*statsBucket
+	mu        sync.Mutex
+}
+
+// NewBotStatsTracker creates a new tracker which computes the stats over the given windows.
+func NewBotStatsTracker(windows []time.Duration) *BotStatsTracker {
+	var retention time.Duration
+	for _, window := range windows {
+		if window > retention {
+			retention = window
+		}
+	}
+	return &BotStatsTracker{
+		windows:   windows,
+		retention: retention,
+		bots:      make(map[string][]*statsBucket),
+	}
+}
+
+// HandleAgentMetrics handles the metrics published to the message bus.
+func (bst *BotStatsTracker) HandleAgentMetrics(payload *protocol.AgentMetricList) error {
+	bst.mu.Lock()
+	defer bst.mu.Unlock()
+
+	for _, metric := range payload.Metrics {
+		if metric.AgentId == "system" {
+			continue
+		}
+		switch {
+		case requestMetrics[metric.Name]:
+			bst.bucket(metric).requests += uint64(metric.Value)
+
+		case successMetrics[metric.Name]:
+			bst.bucket(metric).successes += uint64(metric.Value)
+
+		case latencyMetrics[metric.Name]:
+			bst.bucket(metric).addLatency(metric.Value)
+
+		case timeoutMetrics[metric.Name]:
+			bucket := bst.bucket(metric)
+			bucket.requests++
+			bucket.errors[ErrorCategoryTimeout]++
+
+		case errorMetrics[metric.Name]:
+			bst.bucket(metric).errors[ErrorCategoryBotError]++
+
+		case invokeErrors[metric.Name]:
+			category := invokeErrorCategory(metric.Details)
+			// timeouts are already counted from the timeout metrics
+			if category == ErrorCategoryTimeout {
+				continue
+			}
+			bucket := bst.bucket(metric)
+			bucket.requests++
+			bucket.errors[category]++
+
+		case strings.HasPrefix(metric.Name, "agent.error.validate."), metric.Name == MetricFindingsRejected:
+			bst.bucket(metric).errors[ErrorCategoryInvalidResponse] += uint64(metric.Value)
+		}
+	}
+	return nil
+}
+
+func invokeErrorCategory(details string) string {
+	switch {
+	case strings.Contains(details, "DeadlineExceeded"), strings.Contains(details, "deadline exceeded"):
+		return ErrorCategoryTimeout
+	case strings.Contains(details, "code=Unavailable"), strings.Contains(details, "code=Canceled"),
+		strings.Contains(details, "connection"):
+		return ErrorCategoryConnection
+	case strings.Contains(details, "code=Internal"), strings.Contains(details, "failed to unmarshal"):
+		return ErrorCategoryInvalidResponse
+	case strings.Contains(details, "code=Unknown"):
+		return ErrorCategoryBotError
+	default:
+		return ErrorCategoryOther
+	}
+}
+
+// bucket finds or creates the bucket of the metric and drops the buckets older than the longest window.
+func (bst *BotStatsTracker) bucket(metric *protocol.AgentMetric) *statsBucket {
+	t, err := time.Parse(time.RFC3339, metric.Timestamp)
+	if err != nil {
+		t = time.Now()
+	}
+	t = t.Truncate(botStatsResolution)
+
+	botID := strings.ToLower(metric.AgentId)
+	buckets := bst.bots[botID]
+	for i := len(buckets) - 1; i >= 0; i-- {
+		if buckets[i].time.Equal(t) {
+			return buckets[i]
+		}
+	}
+	bucket := &statsBucket{
+		time:      t,
+		latencies: make([]uint64, len(durationBuckets)+1),
+		errors:    make(map[string]uint64),
+	}
+	buckets = append(buckets, bucket)
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].time.Before(buckets[j].time)
+	})
+	minTime := time.Now().Add(-bst.retention)
+	for len(buckets) > 0 && buckets[0].time.Before(minTime) {
+		buckets = buckets[1:]
+	}
+	bst.bots[botID] = buckets
+	return bucket
+}
+
+func (sb *statsBucket) addLatency(ms float64) {
+	i := sort.SearchFloat64s(durationBuckets, ms)
+	sb.latencies[i]++
+	if ms > sb.maxLatency {
+		sb.maxLatency = ms
+	}
+}
+
+// Stats returns the stats of all bots over all windows.
+func (bst *BotStatsTracker) Stats() []*BotStats {
+	bst.mu.Lock()
+	defer bst.mu.Unlock()
+
+	// forget the bots which did not report during the longest window
+	minTime := time.Now().Add(-bst.retention)
+	var botIDs []string
+	for botID, buckets := range bst.bots {
+		if len(buckets) == 0 || buckets[len(buckets)-1].time.Before(minTime) {
+			delete(bst.bots, botID)
+			continue
+		}
+		botIDs = append(botIDs, botID)
+	}
+	sort.Strings(botIDs)
+
+	var allStats []*BotStats
+	for _, botID := range botIDs {
+		for _, window := range bst.windows {
+			allStats = append(allStats, bst.stats(botID, window))
+		}
+	}
+	return allStats
+}
+
+// BotStats returns the stats of a bot over all windows.
+func (bst *BotStatsTracker) BotStats(botID string) []*BotStats {
+	bst.mu.Lock()
+	defer bst.mu.Unlock()
+
+	botID = strings.ToLower(botID)
+	var allStats []*BotStats
+	for _, window := range bst.windows {
+		allStats = append(allStats, bst.stats(botID, window))
+	}
+	return allStats
+}
+
+func (bst *BotStatsTracker) stats(botID string, window time.Duration) *BotStats {
+	stats := &BotStats{
+		BotID:  botID,
+		Window: window.String(),
+		Errors: make(map[string]uint64),
+	}
+	var (
+		successes  uint64
+		latencies  = make([]uint64, len(durationBuckets)+1)
+		maxLatency float64
+	)
+	minTime := time.Now().Add(-window)
+	for _, bucket := range bst.bots[botID] {
+		if bucket.time.Before(minTime) {
+			continue
+		}
+		stats.Requests += bucket.requests
+		successes += bucket.successes
+		for i, count := range bucket.latencies {
+			latencies[i] += count
+		}
+		if bucket.maxLatency > maxLatency {
+			maxLatency = bucket.maxLatency
+		}
+		for category, count := range bucket.errors {
+			stats.Errors[category] += count
+		}
+	}
+	if stats.Requests > 0 {
+		stats.SuccessRate = float64(successes) / float64(stats.Requests)
+	}
+	stats.LatencyP50Ms = latencyPercentile(latencies, maxLatency, 0.5)
+	stats.LatencyP95Ms = latencyPercentile(latencies, maxLatency, 0.95)
+	return stats
+}
+
+// latencyPercentile estimates the percentile as the upper bound of the bucket which contains it.
+func latencyPercentile(counts []uint64, max, p float64) float64 {
+	var total uint64
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p * float64(total)))
+	var cumulative uint64
+	for i, count := range counts {
+		cumulative += count
+		if cumulative < rank {
+			continue
+		}
+		if i < len(durationBuckets) && durationBuckets[i] < max {
+			return durationBuckets[i]
+		}
+		return max
+	}
+	return max
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotStatsTracker(t *testing.T) {
+	r := require.New(t)
+
+	now := utils.FormatTime(time.Now())
+	old := utils.FormatTime(time.Now().Add(-time.Minute * 30))
+
+	bst := NewBotStatsTracker([]time.Duration{time.Minute * 5, time.Hour})
+	metrics := []*protocol.AgentMetric{
+		{AgentId: "0xBOT", Timestamp: old, Name: MetricTxRequest, Value: 10},
+		{AgentId: "0xBOT", Timestamp: old, Name: MetricTxSuccess, Value: 10},
+		{AgentId: "0xbot", Timestamp: now, Name: MetricTxRequest, Value: 6},
+		{AgentId: "0xbot", Timestamp: now, Name: MetricTxSuccess, Value: 4},
+		{AgentId: "0xbot", Timestamp: now, Name: MetricTxTimeout, Value: 1},
+		{AgentId: "0xbot", Timestamp: now, Name: "agent.error.evaluate.tx", Details: "rpc error: code = DeadlineExceeded"},
+		{AgentId: "0xbot", Timestamp: now, Name: "agent.error.evaluate.tx", Details: "rpc error: code=Unavailable"},
+		{AgentId: "0xbot", Timestamp: now, Name: "agent.error.validate.finding", Value: 1},
+		{AgentId: "system", Timestamp: now, Name: MetricTxRequest, Value: 100},
+	}
+	for i := 0; i < 10; i++ {
+		metrics = append(metrics, &protocol.AgentMetric{AgentId: "0xbot", Timestamp: now, Name: MetricTxLatency, Value: 20})
+	}
+	metrics = append(metrics, &protocol.AgentMetric{AgentId: "0xbot", Timestamp: now, Name: MetricTxLatency, Value: 700})
+	r.NoError(bst.HandleAgentMetrics(&protocol.AgentMetricList{Metrics: metrics}))
+
+	allStats := bst.Stats()
+	r.Len(allStats, 2)
+
+	lastMinutes := allStats[0]
+	r.Equal("0xbot", lastMinutes.BotID)
+	r.Equal("5m0s", lastMinutes.Window)
+	r.Equal(uint64(8), lastMinutes.Requests)
+	r.Equal(0.5, lastMinutes.SuccessRate)
+	r.Equal(float64(25), lastMinutes.LatencyP50Ms)
+	r.Equal(float64(700), lastMinutes.LatencyP95Ms)
+	r.Equal(uint64(1), lastMinutes.Errors[ErrorCategoryTimeout])
+	r.Equal(uint64(1), lastMinutes.Errors[ErrorCategoryConnection])
+	r.Equal(uint64(1), lastMinutes.Errors[ErrorCategoryInvalidResponse])
+
+	lastHour := allStats[1]
+	r.Equal(uint64(18), lastHour.Requests)
+	r.Equal(float64(14)/float64(18), lastHour.SuccessRate)
+
+	r.Equal(allStats, bst.BotStats("0xBot"))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/config"
 )
 
@@ -15,12 +16,19 @@ const (
 	MetricClientDial  = "agent.client.dial"
 	MetricClientClose = "agent.client.close"
 
+	MetricConnectionDown  = "agent.connection.down"
+	MetricConnectionReady = "agent.connection.ready"
+
+	MetricHealthServing = "agent.health.serving"
+
 	MetricStatusRunning     = "agent.status.running"
 	MetricStatusAttached    = "agent.status.attached"
 	MetricStatusInitialized = "agent.status.initialized"
 	MetricStatusStopping    = "agent.status.stopping"
 	MetricStatusActive      = "agent.status.active"
 	MetricStatusInactive    = "agent.status.inactive"
+	MetricStatusExited      = "agent.status.exited"
+	MetricStatusDeclined    = "agent.status.declined"
 
 	MetricActionUpdate      = "agent.action.update"
 	MetricActionRestart     = "agent.action.restart"
@@ -35,6 +43,7 @@ const (
 	MetricFailureInitializeResponse = "agent.failure.initialize.response"
 	MetricFailureInitializeValidate = "agent.failure.initialize.validate"
 	MetricFailureTooManyErrs        = "agent.failure.too-many-errs"
+	MetricFailureManifest           = "agent.failure.manifest"
 )
 
 // Lifecycle creates lifecycle metrics. It is useful in
@@ -43,12 +52,17 @@ type Lifecycle interface {
 	ClientDial(...config.AgentConfig)
 	ClientClose(...config.AgentConfig)
 
+	ConnectionDown(...config.AgentConfig)
+	ConnectionReady(...config.AgentConfig)
+
 	StatusRunning(...config.AgentConfig)
 	StatusAttached(...config.AgentConfig)
 	StatusInitialized(...config.AgentConfig)
 	StatusStopping(...config.AgentConfig)
 	StatusActive([]string)
 	StatusInactive([]string)
+	StatusExited(string, *docker.ContainerExit, ...config.AgentConfig)
+	StatusDeclined(reason string, botIDs ...string)
 
 	ActionUpdate(...config.AgentConfig)
 	ActionRestart(...config.AgentConfig)
@@ -63,6 +77,7 @@ type Lifecycle interface {
 	FailureInitializeResponse(error, ...config.AgentConfig)
 	FailureInitializeValidate(error, ...config.AgentConfig)
 	FailureTooManyErrs(error, ...config.AgentConfig)
+	FailureManifest(error, ...string)
 
 	BotError(metricName string, err error, botID ...string)
 	SystemError(metricName string, err error)
@@ -87,6 +102,14 @@ func (lc *lifecycle) ClientClose(botConfigs ...config.AgentConfig) {
 	SendAgentMetrics(lc.msgClient, fromBotConfigs(MetricClientClose, "", botConfigs))
 }
 
+func (lc *lifecycle) ConnectionDown(botConfigs ...config.AgentConfig) {
+	SendAgentMetrics(lc.msgClient, fromBotConfigs(MetricConnectionDown, "", botConfigs))
+}
+
+func (lc *lifecycle) ConnectionReady(botConfigs ...config.AgentConfig) {
+	SendAgentMetrics(lc.msgClient, fromBotConfigs(MetricConnectionReady, "", botConfigs))
+}
+
 func (lc *lifecycle) StatusRunning(botConfigs ...config.AgentConfig) {
 	SendAgentMetrics(lc.msgClient, fromBotConfigs(MetricStatusRunning, "", botConfigs))
 }
@@ -111,6 +134,32 @@ func (lc *lifecycle) StatusInactive(botIDs []string) {
 	SendAgentMetrics(lc.msgClient, fromBotIDs(MetricStatusInactive, "", botIDs))
 }
 
+func (lc *lifecycle) StatusExited(reason string, exit *docker.ContainerExit, botConfigs ...config.AgentConfig) {
+	SendAgentMetrics(lc.msgClient, fromBotConfigs(MetricStatusExited, exitDetails(reason, exit), botConfigs))
+}
+
+// StatusDeclined reports the assigned bots which the node does not run because of the operator policy
+// so that they can be reassigned.
+func (lc *lifecycle) StatusDeclined(reason string, botIDs ...string) {
+	SendAgentMetrics(lc.msgClient, fromBotIDs(MetricStatusDeclined, reason, botIDs))
+}
+
+// exitDetails describes why and how a bot container exited. The exit is nil or running when
+// the container could not be inspected or was still running at the time of the report.
+func exitDetails(reason string, exit *docker.ContainerExit) string {
+	if exit == nil || exit.Running {
+		return fmt.Sprintf("reason=%s", reason)
+	}
+	details := fmt.Sprintf(
+		"reason=%s exitCode=%d oomKilled=%t signal=%d runSeconds=%d",
+		reason, exit.ExitCode, exit.OOMKilled, exit.Signal, int64(exit.RunDuration.Seconds()),
+	)
+	if len(exit.Error) > 0 {
+		details = fmt.Sprintf("%s error=%q", details, exit.Error)
+	}
+	return details
+}
+
 func (lc *lifecycle) ActionUpdate(botConfigs ...config.AgentConfig) {
 	SendAgentMetrics(lc.msgClient, fromBotConfigs(MetricActionUpdate, "", botConfigs))
 }
@@ -159,6 +208,10 @@ func (lc *lifecycle) FailureTooManyErrs(err error, botConfigs ...config.AgentCon
 	SendAgentMetrics(lc.msgClient, fromBotConfigs(MetricFailureTooManyErrs, err.Error(), botConfigs))
 }
 
+func (lc *lifecycle) FailureManifest(err error, botIDs ...string) {
+	SendAgentMetrics(lc.msgClient, fromBotIDs(MetricFailureManifest, err.Error(), botIDs))
+}
+
 func (lc *lifecycle) BotError(metricName string, err error, botIDs ...string) {
 	SendAgentMetrics(lc.msgClient, fromBotIDs(fmt.Sprintf("agent.error.%s", metricName), err.Error(), botIDs))
 }
@@ -2,7 +2,9 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
+	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/config"
 	"github.com/stretchr/testify/require"
 )
@@ -40,3 +42,19 @@ func TestFromBotConfigs(t *testing.T) {
 	r.Equal(details, metrics[1].Details)
 	r.Equal(float64(1), metrics[1].Value)
 }
+
+func TestExitDetails(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("reason=shutdown", exitDetails("shutdown", nil))
+	r.Equal("reason=unassigned", exitDetails("unassigned", &docker.ContainerExit{Running: true}))
+	r.Equal(
+		"reason=oom-killed exitCode=137 oomKilled=true signal=9 runSeconds=90",
+		exitDetails("oom-killed", &docker.ContainerExit{
+			ExitCode:    137,
+			OOMKilled:   true,
+			Signal:      9,
+			RunDuration: 90 * time.Second,
+		}),
+	)
+}
@@ -1,12 +1,14 @@
 package metrics
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/forta-network/forta-core-go/domain"
 
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/config"
 )
@@ -18,6 +20,10 @@ const (
 	MetricTxError       = "tx.error"
 	MetricTxSuccess     = "tx.success"
 	MetricTxDrop        = "tx.drop"
+	MetricTxOverload    = "tx.drop.overload"
+	MetricTxLag         = "tx.drop.lag"
+	MetricTxFiltered    = "tx.filtered"
+	MetricTxTimeout     = "tx.timeout"
 	MetricTxBlockAge    = "tx.block.age"
 	MetricTxEventAge    = "tx.event.age"
 	MetricBlockBlockAge = "block.block.age"
@@ -27,21 +33,66 @@ const (
 	MetricBlockError    = "block.error"
 	MetricBlockSuccess  = "block.success"
 	MetricBlockDrop     = "block.drop"
+	MetricBlockOverload = "block.drop.overload"
+	MetricBlockLag      = "block.drop.lag"
+	MetricBlockTimeout  = "block.timeout"
 
 	MetricJSONRPCLatency          = "jsonrpc.latency"
 	MetricJSONRPCRequest          = "jsonrpc.request"
 	MetricJSONRPCSuccess          = "jsonrpc.success"
 	MetricJSONRPCThrottled        = "jsonrpc.throttled"
+	MetricJSONRPCDenied           = "jsonrpc.denied"
+	MetricJSONRPCRetry            = "jsonrpc.retry"
+	MetricJSONRPCCoalesced        = "jsonrpc.coalesced"
+	MetricJSONRPCQuotaExceeded    = "jsonrpc.quota.exceeded"
+	MetricJSONRPCUpstreamLatency  = "jsonrpc.upstream.latency"
+	MetricJSONRPCUpstreamLag      = "jsonrpc.upstream.lag"
 	MetricPublicAPIProxyLatency   = "publicapi.latency"
 	MetricPublicAPIProxyRequest   = "publicapi.request"
 	MetricPublicAPIProxySuccess   = "publicapi.success"
 	MetricPublicAPIProxyThrottled = "publicapi.throttled"
+	MetricProtocolProxyLatency    = "protocolproxy.latency"
+	MetricProtocolProxyRequest    = "protocolproxy.request"
+	MetricProtocolProxySuccess    = "protocolproxy.success"
+	MetricProtocolProxyError      = "protocolproxy.error"
+	MetricProtocolProxyThrottled  = "protocolproxy.throttled"
+	MetricBotGatewayLatency       = "botgateway.latency"
+	MetricBotGatewayRequest       = "botgateway.request"
+	MetricBotGatewaySuccess       = "botgateway.success"
+	MetricBotGatewayFailed        = "botgateway.failed"
+	MetricBotGatewayDenied        = "botgateway.denied"
+	MetricBotGatewayThrottled     = "botgateway.throttled"
+	MetricBotGatewayQuotaExceeded = "botgateway.quota.exceeded"
+	MetricBotGatewayCacheHit      = "botgateway.cache.hit"
 	MetricFindingsDropped         = "findings.dropped"
+	MetricFindingsRejected        = "findings.rejected"
+	MetricFindingsTruncated       = "findings.truncated"
+	MetricFindingsDuplicate       = "findings.duplicate"
+	MetricFindingsQuotaExceeded   = "findings.quota.exceeded"
 	MetricCombinerRequest         = "combiner.request"
 	MetricCombinerLatency         = "combiner.latency"
 	MetricCombinerError           = "combiner.error"
 	MetricCombinerSuccess         = "combiner.success"
 	MetricCombinerDrop            = "combiner.drop"
+	MetricCombinerTimeout         = "combiner.timeout"
+	MetricCombinerThrottled       = "combiner.throttled"
+	MetricFeedBlockLag            = "feed.block.lag"
+	MetricFeedBlockGap            = "feed.block.gap"
+	MetricFeedBlockGapSkipped     = "feed.block.gap.skipped"
+	MetricPublisherBatchQueue     = "publisher.queue.batches"
+	MetricPublisherNotifyQueue    = "publisher.queue.notifications"
+	MetricPublisherMetricsBuffer  = "publisher.metrics.buffered"
+	MetricPublisherMetricsDropped = "publisher.metrics.dropped"
+	MetricPublisherQueuedBatches  = "publisher.queue.pending"
+	MetricPublisherQueueAge       = "publisher.queue.age"
+	MetricPublisherQueueDropped   = "publisher.queue.dropped"
+	MetricPublisherBatchAlerts    = "publisher.batch.alerts"
+	MetricPublisherBatchBytes     = "publisher.batch.bytes"
+	MetricPublisherBatchLatency   = "publisher.batch.latency"
+	MetricImagePullProgress       = "image.pull.progress"
+	MetricImagePullLayerProgress  = "image.pull.layer.progress"
+	MetricImagePullStalled        = "image.pull.stalled"
+	MetricBotCPUOverShare         = "agent.cpu.over-share"
 )
 
 func SendAgentMetrics(client clients.MessageClient, ms []*protocol.AgentMetric) {
@@ -146,6 +197,27 @@ func GetJSONRPCMetrics(agt config.AgentConfig, at time.Time, success, throttled
 	return createMetrics(agt.ID, at.Format(time.RFC3339), values)
 }
 
+// GetJSONRPCMethodMetrics counts the JSON-RPC requests per method by suffixing the metric name with the method.
+func GetJSONRPCMethodMetrics(agt config.AgentConfig, at time.Time, metric string, methods []string) []*protocol.AgentMetric {
+	values := make(map[string]float64)
+	for _, method := range methods {
+		values[metric+"."+method]++
+	}
+	return createMetrics(agt.ID, at.Format(time.RFC3339), values)
+}
+
+// GetJSONRPCUpstreamMetrics creates the system metrics from an upstream probe. The details tell the upstream.
+func GetJSONRPCUpstreamMetrics(upstream string, at time.Time, latency time.Duration, lag uint64) []*protocol.AgentMetric {
+	ms := createMetrics("system", at.Format(time.RFC3339), map[string]float64{
+		MetricJSONRPCUpstreamLatency: float64(latency.Milliseconds()),
+		MetricJSONRPCUpstreamLag:     float64(lag),
+	})
+	for _, m := range ms {
+		m.Details = upstream
+	}
+	return ms
+}
+
 func GetPublicAPIMetrics(botID string, at time.Time, success, throttled int, latencyMs time.Duration) []*protocol.AgentMetric {
 	values := make(map[string]float64)
 	if latencyMs > 0 {
@@ -161,3 +233,141 @@ func GetPublicAPIMetrics(botID string, at time.Time, success, throttled int, lat
 	}
 	return createMetrics(botID, at.Format(time.RFC3339), values)
 }
+
+// GetProtocolProxyMetrics creates the metrics of a protocol proxy by suffixing the metric names with the proxy name.
+func GetProtocolProxyMetrics(botID, proxyName string, at time.Time, success, failed, throttled int, latencyMs time.Duration) []*protocol.AgentMetric {
+	values := make(map[string]float64)
+	if latencyMs > 0 {
+		values[MetricProtocolProxyLatency+"."+proxyName] = float64(latencyMs.Milliseconds())
+	}
+	if success > 0 {
+		values[MetricProtocolProxySuccess+"."+proxyName] = float64(success)
+		values[MetricProtocolProxyRequest+"."+proxyName] += float64(success)
+	}
+	if failed > 0 {
+		values[MetricProtocolProxyError+"."+proxyName] = float64(failed)
+		values[MetricProtocolProxyRequest+"."+proxyName] += float64(failed)
+	}
+	if throttled > 0 {
+		values[MetricProtocolProxyThrottled+"."+proxyName] = float64(throttled)
+		values[MetricProtocolProxyRequest+"."+proxyName] += float64(throttled)
+	}
+	return createMetrics(botID, at.Format(time.RFC3339), values)
+}
+
+// GetBotGatewayMetrics creates the metrics of a bot gateway request which ended with the given result.
+func GetBotGatewayMetrics(botID string, at time.Time, result string, latency time.Duration) []*protocol.AgentMetric {
+	values := map[string]float64{
+		MetricBotGatewayRequest: 1,
+		result:                  1,
+	}
+	if latency > 0 {
+		values[MetricBotGatewayLatency] = float64(latency.Milliseconds())
+	}
+	return createMetrics(botID, at.Format(time.RFC3339), values)
+}
+
+// GetFeedMetrics creates the system metrics of the block feed.
+func GetFeedMetrics(at time.Time, blockLag time.Duration) []*protocol.AgentMetric {
+	return createMetrics("system", at.Format(time.RFC3339), map[string]float64{
+		MetricFeedBlockLag: float64(blockLag.Milliseconds()),
+	})
+}
+
+// GetFeedGapMetrics creates the system metrics of the missed blocks.
+func GetFeedGapMetrics(at time.Time, gap, skipped uint64) []*protocol.AgentMetric {
+	values := map[string]float64{
+		MetricFeedBlockGap: float64(gap),
+	}
+	if skipped > 0 {
+		values[MetricFeedBlockGapSkipped] = float64(skipped)
+	}
+	return createMetrics("system", at.Format(time.RFC3339), values)
+}
+
+// GetPublisherQueueMetrics creates the system metrics of the publisher queues.
+func GetPublisherQueueMetrics(at time.Time, batches, notifications int) []*protocol.AgentMetric {
+	return createMetrics("system", at.Format(time.RFC3339), map[string]float64{
+		MetricPublisherBatchQueue:  float64(batches),
+		MetricPublisherNotifyQueue: float64(notifications),
+	})
+}
+
+func GetMetricsBufferMetrics(at time.Time, buffered, dropped int) []*protocol.AgentMetric {
+	values := map[string]float64{
+		MetricPublisherMetricsBuffer: float64(buffered),
+	}
+	if dropped > 0 {
+		values[MetricPublisherMetricsDropped] = float64(dropped)
+	}
+	return createMetrics("system", at.Format(time.RFC3339), values)
+}
+
+// GetBatchQueueMetrics creates the system metrics of the batches waiting to be published.
+func GetBatchQueueMetrics(at time.Time, queued int, oldestAge time.Duration, dropped int) []*protocol.AgentMetric {
+	values := map[string]float64{
+		MetricPublisherQueuedBatches: float64(queued),
+		MetricPublisherQueueAge:      oldestAge.Seconds(),
+	}
+	if dropped > 0 {
+		values[MetricPublisherQueueDropped] = float64(dropped)
+	}
+	return createMetrics("system", at.Format(time.RFC3339), values)
+}
+
+// GetBatchPublishMetrics creates the system metrics of a published batch.
+func GetBatchPublishMetrics(at time.Time, alerts, bytes int, latency time.Duration) []*protocol.AgentMetric {
+	return createMetrics("system", at.Format(time.RFC3339), map[string]float64{
+		MetricPublisherBatchAlerts:  float64(alerts),
+		MetricPublisherBatchBytes:   float64(bytes),
+		MetricPublisherBatchLatency: float64(latency.Milliseconds()),
+	})
+}
+
+// GetBotCPUShareMetrics creates the metric of a bot which uses more than its CPU share.
+func GetBotCPUShareMetrics(botID string, at time.Time, cpuPercent, sharePercent float64) []*protocol.AgentMetric {
+	return []*protocol.AgentMetric{
+		{
+			AgentId:   botID,
+			Timestamp: at.Format(time.RFC3339),
+			Name:      MetricBotCPUOverShare,
+			Details:   fmt.Sprintf("share=%.1f", sharePercent),
+			Value:     cpuPercent,
+		},
+	}
+}
+
+// GetImagePullMetrics creates the system metrics of the image and the layer download percentages
+// of an image pull.
+func GetImagePullMetrics(at time.Time, progress docker.ImagePullProgress) []*protocol.AgentMetric {
+	timestamp := at.Format(time.RFC3339)
+	imageDetails := fmt.Sprintf("image=%s", progress.Ref)
+	metrics := []*protocol.AgentMetric{
+		{
+			AgentId:   "system",
+			Timestamp: timestamp,
+			Name:      MetricImagePullProgress,
+			Details:   imageDetails,
+			Value:     progress.Percent(),
+		},
+	}
+	for layerID, percent := range progress.Layers {
+		metrics = append(metrics, &protocol.AgentMetric{
+			AgentId:   "system",
+			Timestamp: timestamp,
+			Name:      MetricImagePullLayerProgress,
+			Details:   fmt.Sprintf("%s layer=%s", imageDetails, layerID),
+			Value:     percent,
+		})
+	}
+	if progress.Stalled {
+		metrics = append(metrics, &protocol.AgentMetric{
+			AgentId:   "system",
+			Timestamp: timestamp,
+			Name:      MetricImagePullStalled,
+			Details:   imageDetails,
+			Value:     1,
+		})
+	}
+	return metrics
+}
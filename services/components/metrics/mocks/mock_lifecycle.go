@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	domain "github.com/forta-network/forta-core-go/domain"
+	docker "github.com/forta-network/forta-node/clients/docker"
 	config "github.com/forta-network/forta-node/config"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -140,6 +141,38 @@ func (mr *MockLifecycleMockRecorder) ClientDial(arg0 ...interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientDial", reflect.TypeOf((*MockLifecycle)(nil).ClientDial), arg0...)
 }
 
+// ConnectionDown mocks base method.
+func (m *MockLifecycle) ConnectionDown(arg0 ...config.AgentConfig) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "ConnectionDown", varargs...)
+}
+
+// ConnectionDown indicates an expected call of ConnectionDown.
+func (mr *MockLifecycleMockRecorder) ConnectionDown(arg0 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionDown", reflect.TypeOf((*MockLifecycle)(nil).ConnectionDown), arg0...)
+}
+
+// ConnectionReady mocks base method.
+func (m *MockLifecycle) ConnectionReady(arg0 ...config.AgentConfig) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "ConnectionReady", varargs...)
+}
+
+// ConnectionReady indicates an expected call of ConnectionReady.
+func (mr *MockLifecycleMockRecorder) ConnectionReady(arg0 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionReady", reflect.TypeOf((*MockLifecycle)(nil).ConnectionReady), arg0...)
+}
+
 // FailureDial mocks base method.
 func (m *MockLifecycle) FailureDial(arg0 error, arg1 ...config.AgentConfig) {
 	m.ctrl.T.Helper()
@@ -225,6 +258,23 @@ func (mr *MockLifecycleMockRecorder) FailureLaunch(arg0 interface{}, arg1 ...int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailureLaunch", reflect.TypeOf((*MockLifecycle)(nil).FailureLaunch), varargs...)
 }
 
+// FailureManifest mocks base method.
+func (m *MockLifecycle) FailureManifest(arg0 error, arg1 ...string) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "FailureManifest", varargs...)
+}
+
+// FailureManifest indicates an expected call of FailureManifest.
+func (mr *MockLifecycleMockRecorder) FailureManifest(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailureManifest", reflect.TypeOf((*MockLifecycle)(nil).FailureManifest), varargs...)
+}
+
 // FailurePull mocks base method.
 func (m *MockLifecycle) FailurePull(arg0 error, arg1 ...config.AgentConfig) {
 	m.ctrl.T.Helper()
@@ -304,6 +354,40 @@ func (mr *MockLifecycleMockRecorder) StatusAttached(arg0 ...interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusAttached", reflect.TypeOf((*MockLifecycle)(nil).StatusAttached), arg0...)
 }
 
+// StatusDeclined mocks base method.
+func (m *MockLifecycle) StatusDeclined(reason string, botIDs ...string) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{reason}
+	for _, a := range botIDs {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "StatusDeclined", varargs...)
+}
+
+// StatusDeclined indicates an expected call of StatusDeclined.
+func (mr *MockLifecycleMockRecorder) StatusDeclined(reason interface{}, botIDs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{reason}, botIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusDeclined", reflect.TypeOf((*MockLifecycle)(nil).StatusDeclined), varargs...)
+}
+
+// StatusExited mocks base method.
+func (m *MockLifecycle) StatusExited(arg0 string, arg1 *docker.ContainerExit, arg2 ...config.AgentConfig) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "StatusExited", varargs...)
+}
+
+// StatusExited indicates an expected call of StatusExited.
+func (mr *MockLifecycleMockRecorder) StatusExited(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusExited", reflect.TypeOf((*MockLifecycle)(nil).StatusExited), varargs...)
+}
+
 // StatusInactive mocks base method.
 func (m *MockLifecycle) StatusInactive(arg0 []string) {
 	m.ctrl.T.Helper()
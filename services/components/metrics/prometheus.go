@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// gaugeMetrics report the latest value instead of adding up.
+var gaugeMetrics = map[string]bool{
+	MetricJSONRPCUpstreamLag:     true,
+	MetricFeedBlockLag:           true,
+	MetricPublisherBatchQueue:    true,
+	MetricPublisherNotifyQueue:   true,
+	MetricPublisherMetricsBuffer: true,
+	MetricPublisherQueuedBatches: true,
+	MetricPublisherQueueAge:      true,
+	MetricPublisherBatchAlerts:   true,
+	MetricPublisherBatchBytes:    true,
+}
+
+var durationBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000, 300000}
+
+// PrometheusExporter converts the metrics published by the node services to the Prometheus metrics.
+type PrometheusExporter struct {
+	registry  *prometheus.Registry
+	events    *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+	values    *prometheus.GaugeVec
+
+	containerCPU         *prometheus.GaugeVec
+	containerMemory      *prometheus.GaugeVec
+	containerMemoryLimit *prometheus.GaugeVec
+
+	botSuccessRate *prometheus.GaugeVec
+	botLatency     *prometheus.GaugeVec
+	botErrors      *prometheus.GaugeVec
+
+	readinessScore  prometheus.Gauge
+	readinessChecks *prometheus.GaugeVec
+}
+
+// NewPrometheusExporter creates a new Prometheus exporter.
+func NewPrometheusExporter() *PrometheusExporter {
+	pe := &PrometheusExporter{
+		registry: prometheus.NewRegistry(),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "forta_events_total",
+			Help: "Count of the bot lifecycle, request and proxy events.",
+		}, []string{"bot", "metric"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "forta_duration_milliseconds",
+			Help:    "Latencies and event ages.",
+			Buckets: durationBuckets,
+		}, []string{"bot", "metric"}),
+		values: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "forta_value",
+			Help: "Latest values like the block feed lag and the publisher queue depth.",
+		}, []string{"bot", "metric", "source"}),
+		containerCPU: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "forta_container_cpu_percent",
+			Help: "CPU usage of the node and bot containers.",
+		}, []string{"container"}),
+		containerMemory: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "forta_container_memory_bytes",
+			Help: "Memory usage of the node and bot containers.",
+		}, []string{"container"}),
+		containerMemoryLimit: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "forta_container_memory_limit_bytes",
+			Help: "Memory limit of the node and bot containers.",
+		}, []string{"container"}),
+		botSuccessRate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "forta_bot_success_rate",
+			Help: "Ratio of the successful bot evaluations in the window.",
+		}, []string{"bot", "window"}),
+		botLatency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "forta_bot_latency_milliseconds",
+			Help: "Bot evaluation latency percentiles in the window.",
+		}, []string{"bot", "window", "quantile"}),
+		botErrors: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "forta_bot_errors",
+			Help: "Bot evaluation errors in the window by category.",
+		}, []string{"bot", "window", "category"}),
+		readinessScore: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "forta_readiness_score",
+			Help: "Weighted score of the node self-inspection checks between 0 and 1.",
+		}),
+		readinessChecks: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "forta_readiness_check_score",
+			Help: "Score of each node self-inspection check between 0 and 1.",
+		}, []string{"check"}),
+	}
+	pe.registry.MustRegister(
+		pe.events, pe.durations, pe.values,
+		pe.containerCPU, pe.containerMemory, pe.containerMemoryLimit,
+		pe.botSuccessRate, pe.botLatency, pe.botErrors,
+		pe.readinessScore, pe.readinessChecks,
+	)
+	return pe
+}
+
+// HandleAgentMetrics handles the metrics published to the message bus.
+func (pe *PrometheusExporter) HandleAgentMetrics(payload *protocol.AgentMetricList) error {
+	for _, metric := range payload.Metrics {
+		switch {
+		case gaugeMetrics[metric.Name]:
+			pe.values.WithLabelValues(metric.AgentId, metric.Name, metric.Details).Set(metric.Value)
+
+		case isDurationMetric(metric.Name):
+			pe.durations.WithLabelValues(metric.AgentId, metric.Name).Observe(metric.Value)
+
+		case metric.Value >= 0:
+			pe.events.WithLabelValues(metric.AgentId, metric.Name).Add(metric.Value)
+		}
+	}
+	return nil
+}
+
+func isDurationMetric(name string) bool {
+	return strings.Contains(name, ".latency") || strings.HasSuffix(name, ".age")
+}
+
+// SetContainerResources sets the latest resource usage of the containers. The containers
+// which are not in the map anymore are removed.
+func (pe *PrometheusExporter) SetContainerResources(containers map[string]*docker.ContainerResources) {
+	pe.containerCPU.Reset()
+	pe.containerMemory.Reset()
+	pe.containerMemoryLimit.Reset()
+	for name, resources := range containers {
+		pe.containerCPU.WithLabelValues(name).Set(resources.CPUPercent)
+		pe.containerMemory.WithLabelValues(name).Set(float64(resources.MemoryBytes))
+		pe.containerMemoryLimit.WithLabelValues(name).Set(float64(resources.MemoryLimit))
+	}
+}
+
+// SetBotStats sets the latest rolling statistics of the bots.
+func (pe *PrometheusExporter) SetBotStats(allStats []*BotStats) {
+	pe.botSuccessRate.Reset()
+	pe.botLatency.Reset()
+	pe.botErrors.Reset()
+	for _, stats := range allStats {
+		pe.botSuccessRate.WithLabelValues(stats.BotID, stats.Window).Set(stats.SuccessRate)
+		pe.botLatency.WithLabelValues(stats.BotID, stats.Window, "0.5").Set(stats.LatencyP50Ms)
+		pe.botLatency.WithLabelValues(stats.BotID, stats.Window, "0.95").Set(stats.LatencyP95Ms)
+		for category, count := range stats.Errors {
+			pe.botErrors.WithLabelValues(stats.BotID, stats.Window, category).Set(float64(count))
+		}
+	}
+}
+
+// SetReadiness sets the latest readiness score and the scores of the checks. The checks
+// which are not in the map anymore are removed.
+func (pe *PrometheusExporter) SetReadiness(score float64, checks map[string]float64) {
+	pe.readinessScore.Set(score)
+	pe.readinessChecks.Reset()
+	for check, checkScore := range checks {
+		pe.readinessChecks.WithLabelValues(check).Set(checkScore)
+	}
+}
+
+// Handler returns the HTTP handler which serves the metrics.
+func (pe *PrometheusExporter) Handler() http.Handler {
+	return promhttp.HandlerFor(pe.registry, promhttp.HandlerOpts{})
+}
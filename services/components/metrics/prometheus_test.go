@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusExporter(t *testing.T) {
+	r := require.New(t)
+
+	pe := NewPrometheusExporter()
+	r.NoError(pe.HandleAgentMetrics(&protocol.AgentMetricList{
+		Metrics: []*protocol.AgentMetric{
+			{AgentId: "0xbot", Name: MetricJSONRPCThrottled, Value: 2},
+			{AgentId: "0xbot", Name: MetricJSONRPCThrottled, Value: 1},
+			{AgentId: "0xbot", Name: MetricJSONRPCLatency, Value: 40},
+			{AgentId: "system", Name: MetricPublisherBatchQueue, Value: 5},
+			{AgentId: "system", Name: MetricPublisherBatchQueue, Value: 3},
+		},
+	}))
+	pe.SetContainerResources(map[string]*docker.ContainerResources{
+		"forta-scanner": {CPUPercent: 12.5, MemoryBytes: 1024},
+	})
+	pe.SetReadiness(0.75, map[string]float64{"disk": 0.5})
+
+	rec := httptest.NewRecorder()
+	pe.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	b, err := io.ReadAll(rec.Body)
+	r.NoError(err)
+	body := string(b)
+
+	r.Contains(body, `forta_events_total{bot="0xbot",metric="jsonrpc.throttled"} 3`)
+	r.Contains(body, `forta_duration_milliseconds_count{bot="0xbot",metric="jsonrpc.latency"} 1`)
+	r.Contains(body, `forta_value{bot="system",metric="publisher.queue.batches",source=""} 3`)
+	r.Contains(body, `forta_container_cpu_percent{container="forta-scanner"} 12.5`)
+	r.Contains(body, `forta_readiness_score 0.75`)
+	r.Contains(body, `forta_readiness_check_score{check="disk"} 0.5`)
+
+	// removed containers are not reported anymore
+	pe.SetContainerResources(map[string]*docker.ContainerResources{})
+	rec = httptest.NewRecorder()
+	pe.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	r.NotContains(rec.Body.String(), "forta-scanner")
+}
@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// botPolicy combines the operator policy in the config with the policy file and reloads the file
+// when it changes.
+type botPolicy struct {
+	fortaDir    string
+	cfg         config.BotPolicyConfig
+	filePolicy  config.BotPolicy
+	fileModTime time.Time
+	changed     bool
+
+	lastErr health.ErrorTracker
+	mu      sync.Mutex
+}
+
+func newBotPolicy(fortaDir string, cfg config.BotPolicyConfig) *botPolicy {
+	bp := &botPolicy{fortaDir: fortaDir}
+	bp.SetConfig(cfg)
+	return bp
+}
+
+// SetConfig sets the policy from the config and makes the file load again.
+func (bp *botPolicy) SetConfig(cfg config.BotPolicyConfig) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	bp.cfg = cfg
+	bp.fileModTime = time.Time{}
+	bp.changed = true
+}
+
+// Refresh reloads the policy file if it changed and tells if the policy changed since the last call.
+// The last good policy file is kept if the file becomes invalid.
+func (bp *botPolicy) Refresh() bool {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	filePath := bp.cfg.FilePath(bp.fortaDir)
+	var modTime time.Time
+	if info, err := os.Stat(filePath); err == nil {
+		modTime = info.ModTime()
+	}
+	if bp.changed || !modTime.Equal(bp.fileModTime) {
+		filePolicy, err := config.LoadBotPolicyFile(filePath)
+		bp.lastErr.Set(err)
+		if err != nil {
+			log.WithError(err).WithField("file", filePath).Error("failed to load the bot policy - keeping the last policy")
+		} else {
+			bp.filePolicy = filePolicy
+			bp.changed = true
+		}
+		bp.fileModTime = modTime
+	}
+
+	changed := bp.changed
+	bp.changed = false
+	return changed
+}
+
+// Policy returns the current policy.
+func (bp *botPolicy) Policy() config.BotPolicy {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	return bp.cfg.BotPolicy.Merge(bp.filePolicy)
+}
+
+// Health reports the last policy file error.
+func (bp *botPolicy) Health() health.Reports {
+	return health.Reports{bp.lastErr.GetReport("bot-policy.error")}
+}
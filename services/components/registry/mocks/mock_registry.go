@@ -6,6 +6,7 @@ package mock_registry
 
 import (
 	reflect "reflect"
+	time "time"
 
 	health "github.com/forta-network/forta-core-go/clients/health"
 	config "github.com/forta-network/forta-node/config"
@@ -49,6 +50,20 @@ func (mr *MockBotRegistryMockRecorder) Health() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockBotRegistry)(nil).Health))
 }
 
+// LastSynced mocks base method.
+func (m *MockBotRegistry) LastSynced() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastSynced")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// LastSynced indicates an expected call of LastSynced.
+func (mr *MockBotRegistryMockRecorder) LastSynced() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastSynced", reflect.TypeOf((*MockBotRegistry)(nil).LastSynced))
+}
+
 // LoadAssignedBots mocks base method.
 func (m *MockBotRegistry) LoadAssignedBots() ([]config.AgentConfig, error) {
 	m.ctrl.T.Helper()
@@ -3,6 +3,9 @@ package registry
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/forta-network/forta-node/store"
 
@@ -12,9 +15,16 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// maxMissedSyncs is how many checks can fail before the assignments are considered stale.
+const maxMissedSyncs = 3
+
+// loadRetryDelay is the wait before loading the assignments again after a registry failure.
+var loadRetryDelay = time.Second * 2
+
 // BotRegistry loads the latest bots from the registry store.
 type BotRegistry interface {
 	LoadAssignedBots() ([]config.AgentConfig, error)
+	LastSynced() time.Time
 	health.Reporter
 }
 
@@ -25,11 +35,23 @@ type botRegistry struct {
 
 	registryStore store.RegistryStore
 
-	botConfigs []config.AgentConfig
+	// assignedBots are the assigned bots which this node can run and botConfigs are the ones
+	// which are not declined by the operator policy.
+	assignedBots []config.AgentConfig
+	botConfigs   []config.AgentConfig
+	policy       *botPolicy
+	declined     map[string]string
 
 	lastChecked        health.TimeTracker
 	lastChangeDetected health.TimeTracker
 	lastErr            health.ErrorTracker
+	chainBreakdown     health.MessageTracker
+	capabilitySkips    health.MessageTracker
+	declinedCount      health.MessageTracker
+	lastSynced         time.Time
+	// stale is set when the last assignments are used because the registry is unavailable
+	stale bool
+	mu    sync.RWMutex
 }
 
 // New creates a new service.
@@ -37,14 +59,18 @@ func New(cfg config.Config, scannerAddress common.Address) (BotRegistry, error)
 	service := &botRegistry{
 		cfg:            cfg,
 		scannerAddress: scannerAddress,
+		policy:         newBotPolicy(cfg.FortaDir, cfg.BotPolicy),
 	}
 	var (
 		regStr store.RegistryStore
 		err    error
 	)
-	if cfg.LocalModeConfig.Enable {
+	switch {
+	case cfg.LocalModeConfig.Enable:
 		regStr, err = store.NewPrivateRegistryStore(context.Background(), cfg)
-	} else {
+	case cfg.Registry.SnapshotMode:
+		regStr, err = store.NewSnapshotRegistryStore(cfg)
+	default:
 		regStr, err = store.NewRegistryStore(context.Background(), cfg)
 	}
 	if err != nil {
@@ -54,19 +80,33 @@ func New(cfg config.Config, scannerAddress common.Address) (BotRegistry, error)
 	return service, nil
 }
 
-// LoadAssignedBots returns the latest bot list for the running scanner.
+// LoadAssignedBots returns the latest bot list for the running scanner. The last bot list is
+// returned while the registry is unavailable until it is older than the staleness window.
 func (br *botRegistry) LoadAssignedBots() ([]config.AgentConfig, error) {
 	br.lastChecked.Set()
-	agts, changed, err := br.registryStore.GetAgentsIfChanged(br.scannerAddress.Hex())
+	agts, changed, err := br.getAgentsIfChanged()
+	br.lastErr.Set(err)
+	logger := log.WithField("component", "bot-loader")
 	if err != nil {
+		if br.canUseStale() {
+			logger.WithError(err).WithField("lastSynced", br.LastSynced()).
+				Warn("registry is unavailable - using the last bot list")
+			return br.botConfigs, nil
+		}
 		return nil, fmt.Errorf("failed to get the latest bot list: %v", err)
 	}
+	br.mu.Lock()
+	br.lastSynced = time.Now()
+	br.stale = false
+	br.mu.Unlock()
 
-	logger := log.WithField("component", "bot-loader")
 	if changed {
 		br.lastChangeDetected.Set()
-		br.botConfigs = agts
-		logger.WithField("count", len(agts)).Info("updated bot list")
+		br.assignedBots = br.filterByCapabilities(br.filterByChain(agts))
+	}
+	if policyChanged := br.policy != nil && br.policy.Refresh(); changed || policyChanged {
+		br.botConfigs = br.filterByPolicy(br.assignedBots)
+		logger.WithField("count", len(br.botConfigs)).Info("updated bot list")
 	} else {
 		logger.Debug("no bot list changes detected")
 	}
@@ -74,6 +114,198 @@ func (br *botRegistry) LoadAssignedBots() ([]config.AgentConfig, error) {
 	return br.botConfigs, nil
 }
 
+// getAgentsIfChanged retries the registry a few times so that the brief failures do not fail the check.
+func (br *botRegistry) getAgentsIfChanged() (agts []config.AgentConfig, changed bool, err error) {
+	for attempt := 0; attempt <= br.cfg.Registry.LoadRetries; attempt++ {
+		if attempt > 0 {
+			log.WithError(err).WithField("attempt", attempt).Debug("retrying to get the latest bot list")
+			time.Sleep(loadRetryDelay)
+		}
+		agts, changed, err = br.registryStore.GetAgentsIfChanged(br.scannerAddress.Hex())
+		if err == nil {
+			return
+		}
+	}
+	return
+}
+
+// canUseStale tells if the last bot list is recent enough to keep using it and marks it as stale.
+func (br *botRegistry) canUseStale() bool {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if br.lastSynced.IsZero() {
+		return false
+	}
+	maxStale := time.Duration(br.cfg.Registry.MaxStaleSeconds) * time.Second
+	br.stale = time.Since(br.lastSynced) <= maxStale
+	return br.stale
+}
+
+// LastSynced returns the last time the assignments were synced. It is zero if never synced.
+func (br *botRegistry) LastSynced() time.Time {
+	br.mu.RLock()
+	defer br.mu.RUnlock()
+	return br.lastSynced
+}
+
+// filterByChain drops the bots which do not support the chain scanned by this node and
+// updates the per-chain breakdown of the assigned bots.
+func (br *botRegistry) filterByChain(bots []config.AgentConfig) []config.AgentConfig {
+	var (
+		filtered  []config.AgentConfig
+		skipped   int
+		nodeChain = br.cfg.ChainID
+	)
+	for _, bot := range bots {
+		if !bot.SupportsChain(nodeChain) {
+			log.WithFields(log.Fields{
+				"bot":       bot.ID,
+				"botChains": bot.ChainIDs,
+				"chain":     nodeChain,
+			}).Warn("bot does not support the chain of this node - skipping")
+			skipped++
+			continue
+		}
+		filtered = append(filtered, bot)
+	}
+
+	var breakdown []string
+	for _, chainID := range br.cfg.ChainIDs() {
+		var count int
+		for _, bot := range filtered {
+			if bot.SupportsChain(chainID) {
+				count++
+			}
+		}
+		breakdown = append(breakdown, fmt.Sprintf("%d=%d", chainID, count))
+	}
+	breakdown = append(breakdown, fmt.Sprintf("skipped=%d", skipped))
+	br.chainBreakdown.Set(strings.Join(breakdown, ", "))
+
+	return filtered
+}
+
+// filterByCapabilities drops the bots which need the capabilities that this node does not provide
+// and runs the bots which can not be sharded as a whole on the nodes assigned the first shard.
+func (br *botRegistry) filterByCapabilities(bots []config.AgentConfig) []config.AgentConfig {
+	var (
+		filtered []config.AgentConfig
+		skipped  int
+	)
+	for _, bot := range bots {
+		logger := log.WithField("bot", bot.ID)
+		if bot.NeedsTraces() && !br.cfg.Trace.Enabled {
+			logger.Warn("bot needs traces but the trace api is not enabled - skipping")
+			skipped++
+			continue
+		}
+		if bot.ShardConfig != nil && bot.ShardConfig.Shards > 1 && !bot.SupportsSharding() {
+			if bot.ShardConfig.ShardID > 0 {
+				logger.WithField("shardId", bot.ShardConfig.ShardID).Info("bot does not support sharding - skipping the shard")
+				skipped++
+				continue
+			}
+			logger.Info("bot does not support sharding - running it unsharded")
+			bot.ShardConfig = nil
+		}
+		filtered = append(filtered, bot)
+	}
+	br.capabilitySkips.Set(fmt.Sprintf("%d", skipped))
+	return filtered
+}
+
+// filterByPolicy drops the bots which are declined by the operator policy and keeps the reasons
+// so that the declined assignments are reported.
+func (br *botRegistry) filterByPolicy(bots []config.AgentConfig) []config.AgentConfig {
+	if br.policy == nil {
+		return bots
+	}
+	policy := br.policy.Policy()
+
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	var (
+		filtered []config.AgentConfig
+		declined int
+	)
+	for _, bot := range bots {
+		if reason := policy.Decline(bot); len(reason) > 0 {
+			log.WithFields(log.Fields{
+				"bot":    bot.ID,
+				"reason": reason,
+			}).Warn("bot is declined by the operator policy - skipping")
+			if br.declined == nil {
+				br.declined = make(map[string]string)
+			}
+			br.declined[bot.ID] = reason
+			declined++
+			continue
+		}
+		filtered = append(filtered, bot)
+	}
+	br.declinedCount.Set(fmt.Sprintf("%d", declined))
+	return filtered
+}
+
+// SetBotPolicy applies the reloaded operator policy on the next load.
+func (br *botRegistry) SetBotPolicy(cfg config.BotPolicyConfig) {
+	if br.policy != nil {
+		br.policy.SetConfig(cfg)
+	}
+}
+
+// TakeDeclinedBots returns the bots which were declined by the operator policy since the last
+// call together with the reasons.
+func (br *botRegistry) TakeDeclinedBots() map[string]string {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	declined := br.declined
+	br.declined = nil
+	return declined
+}
+
+// TakeRejectedBots returns the bots which were rejected because of their manifests
+// since the last call.
+func (br *botRegistry) TakeRejectedBots() map[string]error {
+	if store, ok := br.registryStore.(interface{ TakeRejectedBots() map[string]error }); ok {
+		return store.TakeRejectedBots()
+	}
+	return nil
+}
+
+// syncAgeReport reports how long ago the assignments were last synced. It starts lagging
+// after a few missed checks so that the stale assignments are noticed.
+func (br *botRegistry) syncAgeReport() *health.Report {
+	lastSynced := br.LastSynced()
+
+	report := &health.Report{Name: "registry.sync.age", Status: health.StatusUnknown}
+	if lastSynced.IsZero() {
+		return report
+	}
+	age := time.Since(lastSynced)
+	report.Details = age.Truncate(time.Second).String()
+	checkInterval := time.Duration(br.cfg.Registry.CheckIntervalSeconds+br.cfg.Registry.CheckJitterSeconds) * time.Second
+	if age > checkInterval*maxMissedSyncs {
+		report.Status = health.StatusLagging
+	} else {
+		report.Status = health.StatusOK
+	}
+	return report
+}
+
+// staleReport tells if the last bot list is being used because the registry is unavailable.
+func (br *botRegistry) staleReport() *health.Report {
+	br.mu.RLock()
+	defer br.mu.RUnlock()
+
+	report := &health.Report{Name: "registry.assignments.stale", Status: health.StatusOK, Details: "false"}
+	if br.stale {
+		report.Status = health.StatusLagging
+		report.Details = fmt.Sprintf("true (synced %s ago)", time.Since(br.lastSynced).Truncate(time.Second))
+	}
+	return report
+}
+
 // Name implements health.Reporter interface.
 func (br *botRegistry) Name() string {
 	return "bot-registry"
@@ -81,7 +313,7 @@ func (br *botRegistry) Name() string {
 
 // Health implements the health.Reporter interface.
 func (br *botRegistry) Health() health.Reports {
-	return health.Reports{
+	reports := health.Reports{
 		br.lastErr.GetReport("event.checked.error"),
 		&health.Report{
 			Name:    "event.checked.time",
@@ -93,5 +325,18 @@ func (br *botRegistry) Health() health.Reports {
 			Status:  health.StatusInfo,
 			Details: br.lastChangeDetected.String(),
 		},
+		br.syncAgeReport(),
+		br.staleReport(),
+		br.chainBreakdown.GetReport("registry.bots.by-chain"),
+		br.capabilitySkips.GetReport("registry.bots.skipped.capabilities"),
+		br.declinedCount.GetReport("registry.bots.declined"),
+	}
+	if br.policy != nil {
+		reports = append(reports, br.policy.Health()...)
+	}
+	// the registry store reports the manifest gateway usage
+	if reporter, ok := br.registryStore.(interface{ Health() health.Reports }); ok {
+		reports = append(reports, reporter.Health()...)
 	}
+	return reports
 }
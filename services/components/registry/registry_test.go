@@ -2,9 +2,13 @@ package registry
 
 import (
 	"errors"
+	"io/ioutil"
+	"path"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/config"
 	mock_store "github.com/forta-network/forta-node/store/mocks"
@@ -41,3 +45,158 @@ func TestLoadAssignedBots(t *testing.T) {
 	r.Error(err)
 	r.Nil(retCfgs)
 }
+
+func TestSyncAgeReport(t *testing.T) {
+	r := require.New(t)
+
+	botReg := &botRegistry{}
+	botReg.cfg.Registry.CheckIntervalSeconds = 60
+
+	report, ok := botReg.Health().GetByName("registry.sync.age")
+	r.True(ok)
+	r.Equal(health.StatusUnknown, report.Status)
+
+	botReg.lastSynced = time.Now().Add(-time.Minute)
+	r.Equal(health.StatusOK, botReg.syncAgeReport().Status)
+
+	botReg.lastSynced = time.Now().Add(-time.Hour)
+	report = botReg.syncAgeReport()
+	r.Equal(health.StatusLagging, report.Status)
+	r.Equal("1h0m0s", report.Details)
+}
+
+func TestLoadAssignedBots_FilterByChain(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	regStore := mock_store.NewMockRegistryStore(ctrl)
+	botReg := &botRegistry{
+		scannerAddress: common.HexToAddress(utils.ZeroAddress),
+		registryStore:  regStore,
+	}
+	botReg.cfg.ChainID = 1
+	botReg.cfg.JsonRpcProxy.Instances = []config.JsonRpcProxyInstanceConfig{{ChainID: 137}}
+
+	regStore.EXPECT().GetAgentsIfChanged(utils.ZeroAddress).Return([]config.AgentConfig{
+		{ID: "any-chain"},
+		{ID: "multi-chain", ChainIDs: []int{1, 137}},
+		{ID: "other-chain", ChainIDs: []int{137}},
+	}, true, nil)
+	bots, err := botReg.LoadAssignedBots()
+	r.NoError(err)
+	r.Len(bots, 2)
+	r.Equal("any-chain", bots[0].ID)
+	r.Equal("multi-chain", bots[1].ID)
+
+	report, ok := botReg.Health().GetByName("registry.bots.by-chain")
+	r.True(ok)
+	r.Equal("1=2, 137=2, skipped=1", report.Details)
+}
+
+func TestLoadAssignedBots_FilterByCapabilities(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	regStore := mock_store.NewMockRegistryStore(ctrl)
+	botReg := &botRegistry{
+		scannerAddress: common.HexToAddress(utils.ZeroAddress),
+		registryStore:  regStore,
+	}
+
+	regStore.EXPECT().GetAgentsIfChanged(utils.ZeroAddress).Return([]config.AgentConfig{
+		{ID: "no-capabilities", ShardConfig: &config.ShardConfig{ShardID: 1, Shards: 2}},
+		{ID: "needs-traces", Capabilities: &config.BotCapabilities{Traces: true}},
+		{ID: "unsharded-first", Capabilities: &config.BotCapabilities{}, ShardConfig: &config.ShardConfig{ShardID: 0, Shards: 2}},
+		{ID: "unsharded-second", Capabilities: &config.BotCapabilities{}, ShardConfig: &config.ShardConfig{ShardID: 1, Shards: 2}},
+	}, true, nil)
+	bots, err := botReg.LoadAssignedBots()
+	r.NoError(err)
+	r.Len(bots, 2)
+	r.Equal("no-capabilities", bots[0].ID)
+	r.NotNil(bots[0].ShardConfig)
+	r.Equal("unsharded-first", bots[1].ID)
+	r.Nil(bots[1].ShardConfig)
+
+	report, ok := botReg.Health().GetByName("registry.bots.skipped.capabilities")
+	r.True(ok)
+	r.Equal("2", report.Details)
+}
+
+func TestLoadAssignedBots_Policy(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	regStore := mock_store.NewMockRegistryStore(ctrl)
+	dir := t.TempDir()
+	botReg := &botRegistry{
+		scannerAddress: common.HexToAddress(utils.ZeroAddress),
+		registryStore:  regStore,
+		policy: newBotPolicy(dir, config.BotPolicyConfig{
+			BotPolicy: config.BotPolicy{Deny: config.BotPolicyRules{BotIDs: []string{"0xbot1"}}},
+			File:      config.DefaultBotPolicyFileName,
+		}),
+	}
+
+	regStore.EXPECT().GetAgentsIfChanged(utils.ZeroAddress).Return([]config.AgentConfig{
+		{ID: "0xbot1"}, {ID: "0xbot2", Image: "registry.example.com/bot"},
+	}, true, nil)
+	bots, err := botReg.LoadAssignedBots()
+	r.NoError(err)
+	r.Len(bots, 1)
+	r.Equal("0xbot2", bots[0].ID)
+	r.Equal(map[string]string{"0xbot1": "bot is denied"}, botReg.TakeDeclinedBots())
+	r.Nil(botReg.TakeDeclinedBots())
+
+	// the policy file is applied without any assignment changes
+	r.NoError(ioutil.WriteFile(path.Join(dir, config.DefaultBotPolicyFileName), []byte("allow:\n  registries: [disco.forta.network]\n"), 0644))
+	regStore.EXPECT().GetAgentsIfChanged(utils.ZeroAddress).Return(nil, false, nil)
+	bots, err = botReg.LoadAssignedBots()
+	r.NoError(err)
+	r.Empty(bots)
+	r.Equal(map[string]string{
+		"0xbot1": "bot is denied",
+		"0xbot2": "image registry is not allowed",
+	}, botReg.TakeDeclinedBots())
+
+	report, ok := botReg.Health().GetByName("registry.bots.declined")
+	r.True(ok)
+	r.Equal("2", report.Details)
+}
+
+func TestLoadAssignedBots_StaleOkay(t *testing.T) {
+	r := require.New(t)
+
+	loadRetryDelay = 0
+	ctrl := gomock.NewController(t)
+	regStore := mock_store.NewMockRegistryStore(ctrl)
+	botReg := &botRegistry{
+		scannerAddress: common.HexToAddress(utils.ZeroAddress),
+		registryStore:  regStore,
+	}
+	botReg.cfg.Registry.LoadRetries = 1
+	botReg.cfg.Registry.MaxStaleSeconds = 600
+
+	// retried once and succeeds
+	cfgs := []config.AgentConfig{{}}
+	regStore.EXPECT().GetAgentsIfChanged(utils.ZeroAddress).Return(nil, false, errors.New("some error"))
+	regStore.EXPECT().GetAgentsIfChanged(utils.ZeroAddress).Return(cfgs, true, nil)
+	retCfgs, err := botReg.LoadAssignedBots()
+	r.NoError(err)
+	r.Equal(cfgs, retCfgs)
+	r.Equal(health.StatusOK, botReg.staleReport().Status)
+
+	// keeps using the last bot list within the staleness window
+	regStore.EXPECT().GetAgentsIfChanged(utils.ZeroAddress).Return(nil, false, errors.New("some error")).Times(2)
+	retCfgs, err = botReg.LoadAssignedBots()
+	r.NoError(err)
+	r.Equal(cfgs, retCfgs)
+	r.Equal(health.StatusLagging, botReg.staleReport().Status)
+
+	// fails after the staleness window
+	botReg.lastSynced = time.Now().Add(-time.Hour)
+	regStore.EXPECT().GetAgentsIfChanged(utils.ZeroAddress).Return(nil, false, errors.New("some error")).Times(2)
+	retCfgs, err = botReg.LoadAssignedBots()
+	r.Error(err)
+	r.Nil(retCfgs)
+	r.Equal(health.StatusOK, botReg.staleReport().Status)
+}
@@ -0,0 +1,141 @@
+package devmode
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// BotRunner runs the development bots as local processes or plain containers. The bots
+// which have only an address are expected to be running already.
+type BotRunner struct {
+	ctx          context.Context
+	cfg          config.Config
+	dockerClient clients.DockerClient
+
+	procs      []*exec.Cmd
+	containers []*docker.Container
+}
+
+// NewBotRunner creates a new bot runner.
+func NewBotRunner(ctx context.Context, cfg config.Config, dockerClient clients.DockerClient) *BotRunner {
+	return &BotRunner{
+		ctx:          ctx,
+		cfg:          cfg,
+		dockerClient: dockerClient,
+	}
+}
+
+// Start starts the bots.
+func (br *BotRunner) Start() error {
+	for _, bot := range br.cfg.Dev.Bots {
+		var err error
+		switch {
+		case len(bot.Command) > 0:
+			err = br.startProcess(bot)
+		case len(bot.Image) > 0:
+			err = br.startContainer(bot)
+		default:
+			log.WithFields(log.Fields{
+				"bot":     bot.ID,
+				"address": bot.Address,
+			}).Info("using the running bot")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to start bot %s: %v", bot.ID, err)
+		}
+	}
+	return nil
+}
+
+func (br *BotRunner) startProcess(bot config.DevBotConfig) error {
+	logger := log.WithField("bot", bot.ID)
+	cmd := exec.CommandContext(br.ctx, bot.Command[0], bot.Command[1:]...)
+	cmd.Env = os.Environ()
+	for k, v := range br.botEnv(bot, "localhost", bot.Port) {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	cmd.Stdout = logger.WriterLevel(log.InfoLevel)
+	cmd.Stderr = logger.WriterLevel(log.WarnLevel)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	logger.WithField("pid", cmd.Process.Pid).Info("started the bot process")
+	br.procs = append(br.procs, cmd)
+	go func() {
+		err := cmd.Wait()
+		logger.WithError(err).Warn("bot process exited")
+	}()
+	return nil
+}
+
+func (br *BotRunner) startContainer(bot config.DevBotConfig) error {
+	name := fmt.Sprintf("%s-dev-bot-%s", config.ContainerNamePrefix, utils.ShortenString(bot.ID, 8))
+	if err := br.dockerClient.EnsureLocalImage(br.ctx, name, bot.Image); err != nil {
+		return err
+	}
+	container, err := br.dockerClient.StartContainer(br.ctx, docker.ContainerConfig{
+		Name:  name,
+		Image: bot.Image,
+		// the proxy runs on the host
+		Env:      br.botEnv(bot, "host.docker.internal", config.AgentGrpcPort),
+		Ports:    map[string]string{fmt.Sprintf("127.0.0.1:%s", bot.Port): config.AgentGrpcPort},
+		DialHost: true,
+		Labels: map[string]string{
+			docker.LabelFortaBotID: bot.ID,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	br.containers = append(br.containers, container)
+	return nil
+}
+
+func (br *BotRunner) botEnv(bot config.DevBotConfig, jsonRpcHost, grpcPort string) map[string]string {
+	env := map[string]string{
+		config.EnvJsonRpcHost:   jsonRpcHost,
+		config.EnvJsonRpcPort:   br.cfg.JsonRpcProxy.Port(),
+		config.EnvAgentGrpcPort: grpcPort,
+		config.EnvFortaBotID:    bot.ID,
+		config.EnvFortaChainID:  strconv.Itoa(br.cfg.ChainID),
+	}
+	for k, v := range bot.Env {
+		env[k] = v
+	}
+	return env
+}
+
+// Stop stops the bot processes and removes the bot containers.
+func (br *BotRunner) Stop() error {
+	for _, proc := range br.procs {
+		if proc.Process != nil {
+			proc.Process.Signal(os.Interrupt)
+		}
+	}
+	// the main context is done at this point
+	ctx := context.Background()
+	for _, container := range br.containers {
+		logger := log.WithField("container", container.Name)
+		if err := br.dockerClient.StopContainer(ctx, container.ID); err != nil {
+			logger.WithError(err).Warn("failed to stop the bot container")
+		}
+		if err := br.dockerClient.RemoveContainer(ctx, container.ID); err != nil {
+			logger.WithError(err).Warn("failed to remove the bot container")
+		}
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (br *BotRunner) Name() string {
+	return "dev-bot-runner"
+}
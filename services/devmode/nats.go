@@ -0,0 +1,33 @@
+package devmode
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+const natsReadyTimeout = time.Second * 10
+
+// StartNatsServer starts an embedded NATS server on localhost so that the services in the
+// same process can talk to each other as they do in the containers.
+func StartNatsServer(port string) (*server.Server, error) {
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, err
+	}
+	natsServer, err := server.NewServer(&server.Options{
+		Host: "127.0.0.1",
+		Port: portNum,
+	})
+	if err != nil {
+		return nil, err
+	}
+	natsServer.Start()
+	if !natsServer.ReadyForConnections(natsReadyTimeout) {
+		natsServer.Shutdown()
+		return nil, errors.New("nats server is not ready")
+	}
+	return natsServer, nil
+}
@@ -0,0 +1,213 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
+	log "github.com/sirupsen/logrus"
+)
+
+const dialTimeout = time.Second * 10
+
+// errInternalAddr is returned when an allowed host resolves to an internal address.
+var errInternalAddr = errors.New("destination resolves to an internal address")
+
+// Proxy is an HTTP proxy which lets the bots connect only to the external hosts which are declared
+// in their manifests and approved by the operator. The bot containers can not reach any other
+// destination because they are attached to internal networks.
+type Proxy struct {
+	ctx              context.Context
+	cfg              config.BotEgressConfig
+	server           *http.Server
+	botAuthenticator clients.IPAuthenticator
+	dialer           *net.Dialer
+	forwarder        http.Handler
+
+	allowed uint64
+	denied  uint64
+}
+
+// NewProxy creates a new egress proxy.
+func NewProxy(ctx context.Context, cfg config.BotEgressConfig, botAuthenticator clients.IPAuthenticator) *Proxy {
+	p := &Proxy{
+		ctx:              ctx,
+		cfg:              cfg,
+		botAuthenticator: botAuthenticator,
+		dialer:           NewDialer(),
+	}
+	p.forwarder = &httputil.ReverseProxy{
+		// the requests to the proxy already have the absolute urls
+		Director: func(r *http.Request) {},
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return p.dialer.DialContext(ctx, network, addr)
+			},
+			TLSHandshakeTimeout: dialTimeout,
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.WithError(err).WithField("host", r.URL.Host).Warn("failed to forward the bot request")
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	return p
+}
+
+// NewDialer creates a dialer which can not connect to the internal addresses.
+func NewDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout: dialTimeout,
+		Control: denyInternalAddrs,
+	}
+}
+
+// denyInternalAddrs prevents reaching the node and the host through the hosts which resolve to
+// internal addresses.
+func denyInternalAddrs(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return errInternalAddr
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	botConfig, err := p.botAuthenticator.FindAgentFromRemoteAddr(req.RemoteAddr)
+	if err != nil {
+		log.WithError(err).WithField("remoteAddr", req.RemoteAddr).Warn("failed to authenticate the egress request")
+		http.Error(w, "request source is not a deployed bot", http.StatusForbidden)
+		return
+	}
+
+	host := req.Host
+	if req.Method != http.MethodConnect {
+		if !req.URL.IsAbs() {
+			http.Error(w, "not a proxy request", http.StatusBadRequest)
+			return
+		}
+		host = req.URL.Host
+	}
+	logger := log.WithFields(log.Fields{
+		"bot":  botConfig.ID,
+		"host": host,
+	})
+	if !p.cfg.IsAllowed(*botConfig, host) {
+		atomic.AddUint64(&p.denied, 1)
+		logger.Warn("bot tried to connect to a host which is not allowed")
+		http.Error(w, "destination is not allowed by the scan node egress policy", http.StatusForbidden)
+		return
+	}
+	atomic.AddUint64(&p.allowed, 1)
+	logger.Debug("allowed the bot egress request")
+
+	if req.Method == http.MethodConnect {
+		p.tunnel(w, req)
+		return
+	}
+	p.forwarder.ServeHTTP(w, req)
+}
+
+// tunnel connects the bot to the host for the HTTPS requests.
+func (p *Proxy) tunnel(w http.ResponseWriter, req *http.Request) {
+	upstreamConn, err := p.dialer.DialContext(req.Context(), "tcp", req.Host)
+	if err != nil {
+		log.WithError(err).WithField("host", req.Host).Warn("failed to connect to the host for the bot")
+		http.Error(w, "failed to connect to the destination", http.StatusBadGateway)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstreamConn.Close()
+		http.Error(w, "tunneling is not supported", http.StatusInternalServerError)
+		return
+	}
+	botConn, botRW, err := hijacker.Hijack()
+	if err != nil {
+		upstreamConn.Close()
+		log.WithError(err).Warn("failed to hijack the bot connection")
+		return
+	}
+	if _, err := botConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		upstreamConn.Close()
+		botConn.Close()
+		return
+	}
+
+	// closing both connections when one side is done unblocks the other copy
+	go func() {
+		io.Copy(upstreamConn, botRW)
+		upstreamConn.Close()
+		botConn.Close()
+	}()
+	go func() {
+		io.Copy(botConn, upstreamConn)
+		upstreamConn.Close()
+		botConn.Close()
+	}()
+}
+
+// Start starts the proxy.
+func (p *Proxy) Start() error {
+	listenAddr := p.cfg.ListenAddr
+	if len(listenAddr) == 0 {
+		listenAddr = ":" + config.DefaultEgressProxyPort
+	}
+	p.server = &http.Server{
+		Addr:    listenAddr,
+		Handler: p,
+	}
+	utils.GoListenAndServe(p.server)
+	return nil
+}
+
+// ShutdownStage implements the services.StagedService interface.
+func (p *Proxy) ShutdownStage() services.ShutdownStage {
+	return services.StageProxy
+}
+
+// Stop stops the proxy.
+func (p *Proxy) Stop() error {
+	if p.server != nil {
+		return p.server.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (p *Proxy) Name() string {
+	return "egress-proxy"
+}
+
+// Health implements health.Reporter interface.
+func (p *Proxy) Health() health.Reports {
+	return health.Reports{
+		{
+			Name:    "egress.allowed",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&p.allowed), 10),
+		},
+		{
+			Name:    "egress.denied",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&p.denied), 10),
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package egress
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	authenticator := mock_clients.NewMockIPAuthenticator(ctrl)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer api.Close()
+	tlsAPI := httptest.NewTLSServer(api.Config.Handler)
+	defer tlsAPI.Close()
+
+	proxy := NewProxy(context.Background(), config.BotEgressConfig{
+		Enable:        true,
+		ApprovedHosts: []string{"127.0.0.1"},
+	}, authenticator)
+	// the test servers are local
+	proxy.dialer = &net.Dialer{}
+	proxyServer := httptest.NewServer(proxy)
+	defer proxyServer.Close()
+	proxyURL, _ := url.Parse(proxyServer.URL)
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	// the requests which are not coming from the bots are rejected
+	authenticator.EXPECT().FindAgentFromRemoteAddr(gomock.Any()).Return(nil, errors.New("not found"))
+	resp, err := client.Get(api.URL)
+	r.NoError(err)
+	r.Equal(http.StatusForbidden, resp.StatusCode)
+
+	botConfig := &config.AgentConfig{ID: "0xbot", EgressHosts: []string{"127.0.0.1"}}
+	authenticator.EXPECT().FindAgentFromRemoteAddr(gomock.Any()).Return(botConfig, nil).AnyTimes()
+
+	// the approved hosts are reached with plain requests and tunnels
+	for _, apiURL := range []string{api.URL, tlsAPI.URL} {
+		resp, err = client.Get(apiURL)
+		r.NoError(err)
+		r.Equal(http.StatusOK, resp.StatusCode)
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		r.Equal("ok", string(b))
+	}
+
+	// the hosts which are not approved are rejected
+	_, port, _ := net.SplitHostPort(api.Listener.Addr().String())
+	resp, err = client.Get("http://localhost:" + port)
+	r.NoError(err)
+	r.Equal(http.StatusForbidden, resp.StatusCode)
+
+	reports := proxy.Health()
+	r.Equal("2", reports[0].Details)
+	r.Equal("1", reports[1].Details)
+}
+
+func TestDenyInternalAddrs(t *testing.T) {
+	r := require.New(t)
+
+	for _, addr := range []string{"127.0.0.1:80", "10.0.0.1:443", "172.17.0.1:443", "192.168.1.1:443", "169.254.169.254:80", "[::1]:443", "0.0.0.0:80"} {
+		r.ErrorIs(denyInternalAddrs("tcp", addr, nil), errInternalAddr, addr)
+	}
+	r.NoError(denyInternalAddrs("tcp", "1.1.1.1:443", nil))
+}
@@ -0,0 +1,112 @@
+package json_rpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+// accessLogEntry is a single line in the access log.
+type accessLogEntry struct {
+	Time          string `json:"time"`
+	Bot           string `json:"bot,omitempty"`
+	Method        string `json:"method"`
+	ParamsHash    string `json:"paramsHash,omitempty"`
+	BatchSize     int    `json:"batchSize,omitempty"`
+	LatencyMs     int64  `json:"latencyMs"`
+	Status        int    `json:"status"`
+	RequestBytes  int64  `json:"requestBytes"`
+	ResponseBytes int64  `json:"responseBytes"`
+}
+
+// accessLogger writes the sampled requests as JSON lines.
+type accessLogger struct {
+	sampleRate float64
+	enc        *json.Encoder
+	mu         sync.Mutex
+}
+
+func newAccessLogger(cfg config.JsonRpcAccessLogConfig) (*accessLogger, error) {
+	var w io.Writer = os.Stdout
+	if len(cfg.Path) > 0 {
+		f, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the access log file: %v", err)
+		}
+		w = f
+	}
+	return &accessLogger{sampleRate: cfg.SampleRate, enc: json.NewEncoder(w)}, nil
+}
+
+// Sample tells if the next request should be logged.
+func (al *accessLogger) Sample() bool {
+	return al.sampleRate >= 1 || rand.Float64() < al.sampleRate
+}
+
+// Log writes an entry for each request in the batch.
+func (al *accessLogger) Log(botID string, rpcReqs []jsonRpcRequest, req *http.Request, rec *countingResponseWriter, latency time.Duration) {
+	entry := accessLogEntry{
+		Time:          time.Now().UTC().Format(time.RFC3339Nano),
+		Bot:           botID,
+		LatencyMs:     latency.Milliseconds(),
+		Status:        rec.status,
+		RequestBytes:  req.ContentLength,
+		ResponseBytes: rec.bytes,
+	}
+	if len(rpcReqs) > 1 {
+		entry.BatchSize = len(rpcReqs)
+	}
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	if len(rpcReqs) == 0 {
+		al.enc.Encode(&entry)
+		return
+	}
+	for _, rpcReq := range rpcReqs {
+		entry.Method = rpcReq.Method
+		entry.ParamsHash = hashParams(rpcReq.Params)
+		al.enc.Encode(&entry)
+	}
+}
+
+func hashParams(params json.RawMessage) string {
+	if len(params) == 0 {
+		return ""
+	}
+	h := sha256.Sum256(params)
+	return hex.EncodeToString(h[:8])
+}
+
+// countingResponseWriter keeps the status and the size of the response.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func newCountingResponseWriter(w http.ResponseWriter) *countingResponseWriter {
+	return &countingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (cw *countingResponseWriter) WriteHeader(statusCode int) {
+	cw.status = statusCode
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter.
+func (cw *countingResponseWriter) Write(b []byte) (int, error) {
+	n, err := cw.ResponseWriter.Write(b)
+	cw.bytes += int64(n)
+	return n, err
+}
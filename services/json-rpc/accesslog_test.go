@@ -0,0 +1,55 @@
+package json_rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogger(t *testing.T) {
+	r := require.New(t)
+
+	logPath := path.Join(t.TempDir(), "access.log")
+	al, err := newAccessLogger(config.JsonRpcAccessLogConfig{Enable: true, Path: logPath, SampleRate: 1})
+	r.NoError(err)
+	r.True(al.Sample())
+
+	body := `[{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{}]},{"jsonrpc":"2.0","id":2,"method":"eth_chainId"}]`
+	req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(body))
+	rpcReqs, _, err := readRequests(req)
+	r.NoError(err)
+
+	rec := newCountingResponseWriter(httptest.NewRecorder())
+	rec.WriteHeader(502)
+	rec.Write([]byte("bad gateway"))
+	al.Log("0xbot", rpcReqs, req, rec, time.Millisecond*15)
+
+	f, err := os.Open(logPath)
+	r.NoError(err)
+	defer f.Close()
+	var entries []accessLogEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry accessLogEntry
+		r.NoError(json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	r.Len(entries, 2)
+	r.Equal("0xbot", entries[0].Bot)
+	r.Equal("eth_call", entries[0].Method)
+	r.NotEmpty(entries[0].ParamsHash)
+	r.Empty(entries[1].ParamsHash)
+	r.Equal(2, entries[1].BatchSize)
+	r.Equal(int64(15), entries[1].LatencyMs)
+	r.Equal(502, entries[1].Status)
+	r.Equal(int64(len(body)), entries[1].RequestBytes)
+	r.Equal(int64(len("bad gateway")), entries[1].ResponseBytes)
+}
@@ -0,0 +1,171 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxBatchSize   = 100
+	defaultBatchSplitSize = 20
+)
+
+// batchHandler serves the batch items from the cache and the local data where possible
+// and sends the rest to the upstream in smaller batches.
+type batchHandler struct {
+	maxSize   int
+	splitSize int
+	cache     *jsonRpcCache
+	localData *localData
+}
+
+func newBatchHandler(maxSize, splitSize int, cache *jsonRpcCache, localData *localData) *batchHandler {
+	if maxSize <= 0 {
+		maxSize = defaultMaxBatchSize
+	}
+	if splitSize <= 0 {
+		splitSize = defaultBatchSplitSize
+	}
+	return &batchHandler{maxSize: maxSize, splitSize: splitSize, cache: cache, localData: localData}
+}
+
+func (b *batchHandler) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rpcReqs, isBatch, err := readRequests(req)
+		if isBatch && err != nil {
+			writeParseErr(w, err)
+			return
+		}
+		if err != nil || !isBatch || len(rpcReqs) == 0 {
+			h.ServeHTTP(w, req)
+			return
+		}
+		if len(rpcReqs) > b.maxSize {
+			writeJsonRpcErr(w, http.StatusBadRequest, nil, -32600, fmt.Sprintf("batch size exceeds the limit of %d requests", b.maxSize))
+			return
+		}
+
+		responses := make([]json.RawMessage, len(rpcReqs))
+		var pending []int
+		for i, rpcReq := range rpcReqs {
+			if b.cache != nil {
+				if result, ok := b.cache.lookup(rpcReq); ok {
+					responses[i], _ = json.Marshal(&jsonRpcResponse{JSONRPC: "2.0", ID: rpcReq.ID, Result: result})
+					continue
+				}
+			}
+			if b.localData != nil {
+				if result, ok := b.localData.lookup(req.Context(), rpcReq); ok {
+					responses[i], _ = json.Marshal(&jsonRpcResponse{JSONRPC: "2.0", ID: rpcReq.ID, Result: result})
+					if b.cache != nil {
+						b.cache.store(rpcReq, result)
+					}
+					continue
+				}
+			}
+			pending = append(pending, i)
+		}
+
+		for start := 0; start < len(pending); start += b.splitSize {
+			end := start + b.splitSize
+			if end > len(pending) {
+				end = len(pending)
+			}
+			chunk := pending[start:end]
+			if err := b.serveChunk(h, req, rpcReqs, chunk, responses); err != nil {
+				log.WithError(err).Warn("failed to proxy the batch request")
+				for _, i := range chunk {
+					if len(rpcReqs[i].ID) == 0 {
+						continue
+					}
+					responses[i], _ = json.Marshal(&jsonRpcResponse{
+						JSONRPC: "2.0",
+						ID:      rpcReqs[i].ID,
+						Error:   &jsonRpcError{Code: -32603, Message: "failed to proxy the batch request"},
+					})
+				}
+			}
+		}
+
+		// notifications do not have responses
+		results := make([]json.RawMessage, 0, len(responses))
+		for _, resp := range responses {
+			if resp != nil {
+				results = append(results, resp)
+			}
+		}
+		// a batch of only notifications is answered with nothing at all
+		if len(results) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			log.WithError(err).Error("failed to write jsonrpc batch response")
+		}
+	})
+}
+
+// serveChunk sends the pending batch items to the upstream and sets the responses by matching the ids.
+func (b *batchHandler) serveChunk(h http.Handler, req *http.Request, rpcReqs []jsonRpcRequest, chunk []int, responses []json.RawMessage) error {
+	chunkReqs := make([]jsonRpcRequest, 0, len(chunk))
+	for _, i := range chunk {
+		chunkReqs = append(chunkReqs, rpcReqs[i])
+	}
+	body, err := json.Marshal(chunkReqs)
+	if err != nil {
+		return err
+	}
+
+	chunkReq := req.Clone(req.Context())
+	chunkReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	chunkReq.ContentLength = int64(len(body))
+	// make sure that we get a plain response from the upstream so we can decode it
+	chunkReq.Header.Del("Accept-Encoding")
+	rec := newBufferedResponseWriter()
+	h.ServeHTTP(rec, chunkReq)
+	if rec.status != http.StatusOK && rec.status != http.StatusNoContent {
+		return fmt.Errorf("upstream responded with status code %d", rec.status)
+	}
+	// the upstream answers the notifications with nothing at all
+	if len(bytes.TrimSpace(rec.body.Bytes())) == 0 {
+		return nil
+	}
+
+	var chunkResps []json.RawMessage
+	if err := json.Unmarshal(rec.body.Bytes(), &chunkResps); err != nil {
+		return fmt.Errorf("failed to decode the batch response: %v", err)
+	}
+	respsByID := make(map[string][]json.RawMessage)
+	for _, chunkResp := range chunkResps {
+		var rpcResp jsonRpcResponse
+		if err := json.Unmarshal(chunkResp, &rpcResp); err != nil {
+			continue
+		}
+		id := string(rpcResp.ID)
+		respsByID[id] = append(respsByID[id], chunkResp)
+	}
+
+	for _, i := range chunk {
+		id := string(rpcReqs[i].ID)
+		resps := respsByID[id]
+		if len(rpcReqs[i].ID) == 0 || len(resps) == 0 {
+			continue
+		}
+		responses[i] = resps[0]
+		respsByID[id] = resps[1:]
+		if b.cache != nil {
+			var rpcResp jsonRpcResponse
+			if err := json.Unmarshal(resps[0], &rpcResp); err == nil && rpcResp.Error == nil && len(rpcResp.Result) > 0 && string(rpcResp.Result) != "null" {
+				b.cache.observe(rpcReqs[i], rpcResp.Result)
+				b.cache.store(rpcReqs[i], rpcResp.Result)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchHandler(t *testing.T) {
+	r := require.New(t)
+
+	cache, err := newJsonRpcCache(config.JsonRpcCacheConfig{Enable: true, MaxEntries: 10})
+	r.NoError(err)
+
+	var upstreamCalls, upstreamItems int
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		upstreamCalls++
+		var rpcReqs []jsonRpcRequest
+		r.NoError(json.NewDecoder(req.Body).Decode(&rpcReqs))
+		upstreamItems += len(rpcReqs)
+		var rpcResps []jsonRpcResponse
+		// respond in reverse order to make sure that the responses are matched by id
+		for i := len(rpcReqs) - 1; i >= 0; i-- {
+			if len(rpcReqs[i].ID) == 0 {
+				continue
+			}
+			rpcResps = append(rpcResps, jsonRpcResponse{
+				JSONRPC: "2.0",
+				ID:      rpcReqs[i].ID,
+				Result:  json.RawMessage(`"` + rpcReqs[i].Method + `"`),
+			})
+		}
+		if len(rpcResps) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(rpcResps)
+	})
+	h := newBatchHandler(5, 2, cache, nil).handler(cache.handler(upstream))
+
+	var respBody string
+	doBatch := func(body string) (int, []jsonRpcResponse) {
+		req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(body))
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+		respBody = recorder.Body.String()
+		var rpcResps []jsonRpcResponse
+		json.NewDecoder(recorder.Body).Decode(&rpcResps)
+		return recorder.Code, rpcResps
+	}
+
+	status, rpcResps := doBatch(`[
+		{"jsonrpc":"2.0","id":1,"method":"eth_chainId"},
+		{"jsonrpc":"2.0","id":2,"method":"eth_call"},
+		{"jsonrpc":"2.0","id":3,"method":"eth_getBalance"}
+	]`)
+	r.Equal(http.StatusOK, status)
+	r.Len(rpcResps, 3)
+	r.Equal("1", string(rpcResps[0].ID))
+	r.Equal(`"eth_chainId"`, string(rpcResps[0].Result))
+	r.Equal(`"eth_getBalance"`, string(rpcResps[2].Result))
+	r.Equal(2, upstreamCalls)
+	r.Equal(3, upstreamItems)
+
+	// the chain id is served from the cache
+	_, rpcResps = doBatch(`[{"jsonrpc":"2.0","id":4,"method":"eth_chainId"},{"jsonrpc":"2.0","id":5,"method":"eth_call"}]`)
+	r.Len(rpcResps, 2)
+	r.Equal("4", string(rpcResps[0].ID))
+	r.Equal(`"eth_chainId"`, string(rpcResps[0].Result))
+	r.Equal(3, upstreamCalls)
+	r.Equal(4, upstreamItems)
+
+	// the notifications are not answered
+	_, rpcResps = doBatch(`[{"jsonrpc":"2.0","id":6,"method":"eth_call"},{"jsonrpc":"2.0","method":"eth_call"}]`)
+	r.Len(rpcResps, 1)
+	r.Equal("6", string(rpcResps[0].ID))
+
+	// a batch of only notifications has an empty response
+	status, _ = doBatch(`[{"jsonrpc":"2.0","method":"eth_call"},{"jsonrpc":"2.0","method":"eth_call"}]`)
+	r.Equal(http.StatusNoContent, status)
+	r.Empty(respBody)
+
+	// too large batches are rejected
+	status, _ = doBatch(`[{"id":1},{"id":2},{"id":3},{"id":4},{"id":5},{"id":6}]`)
+	r.Equal(http.StatusBadRequest, status)
+
+	// the batches which cannot be decoded are rejected
+	calls := upstreamCalls
+	status, _ = doBatch(`[{"jsonrpc":"2.0","id":7,"method":"eth_call"},{"method":123}]`)
+	r.Equal(http.StatusBadRequest, status)
+	r.Contains(respBody, "-32600")
+	status, _ = doBatch(`[{"jsonrpc":"2.0","id":8,"method":"eth_call"}`)
+	r.Equal(http.StatusBadRequest, status)
+	r.Contains(respBody, "-32700")
+	r.Equal(calls, upstreamCalls)
+}
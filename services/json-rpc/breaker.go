@@ -0,0 +1,167 @@
+package json_rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultCooldown         = time.Second * 30
+	defaultUpstreamTimeout  = time.Second * 30
+	defaultMaxInFlight      = 200
+)
+
+var (
+	errCircuitOpen = errors.New("upstream circuit is open")
+	errTooBusy     = errors.New("too many in-flight upstream requests")
+)
+
+type circuitState string
+
+const (
+	circuitClosed   circuitState = "closed"
+	circuitOpen     circuitState = "open"
+	circuitHalfOpen circuitState = "half-open"
+)
+
+// circuitBreaker stops sending requests to the upstream after consecutive failures
+// and limits the concurrent upstream requests so that the bot requests fail fast
+// instead of piling up.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	timeout   time.Duration
+	inFlight  chan struct{}
+
+	state    circuitState
+	failures int
+	openedAt time.Time
+	mu       sync.Mutex
+}
+
+func newCircuitBreaker(cfg config.JsonRpcCircuitBreakerConfig) *circuitBreaker {
+	cb := &circuitBreaker{
+		threshold: cfg.FailureThreshold,
+		cooldown:  time.Duration(cfg.CooldownSeconds) * time.Second,
+		timeout:   time.Duration(cfg.UpstreamTimeoutSeconds) * time.Second,
+		state:     circuitClosed,
+	}
+	if cb.threshold <= 0 {
+		cb.threshold = defaultFailureThreshold
+	}
+	if cb.cooldown <= 0 {
+		cb.cooldown = defaultCooldown
+	}
+	if cb.timeout <= 0 {
+		cb.timeout = defaultUpstreamTimeout
+	}
+	maxInFlight := cfg.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMaxInFlight
+	}
+	cb.inFlight = make(chan struct{}, maxInFlight)
+	return cb
+}
+
+// allow checks if a request can go to the upstream. Only a single trial request
+// is let through after the cooldown.
+func (cb *circuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case circuitOpen:
+		if time.Since(cb.openedAt) < cb.cooldown {
+			return errCircuitOpen
+		}
+		cb.state = circuitHalfOpen
+		return nil
+	case circuitHalfOpen:
+		return errCircuitOpen
+	default:
+		return nil
+	}
+}
+
+func (cb *circuitBreaker) record(success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if success {
+		if cb.state != circuitClosed {
+			log.Info("upstream recovered - closing the circuit")
+		}
+		cb.failures = 0
+		cb.state = circuitClosed
+		return
+	}
+	cb.failures++
+	if cb.state == circuitHalfOpen || (cb.state == circuitClosed && cb.failures >= cb.threshold) {
+		log.WithField("failures", cb.failures).Warn("upstream is failing - opening the circuit")
+		cb.state = circuitOpen
+		cb.openedAt = time.Now()
+	}
+}
+
+func (cb *circuitBreaker) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case cb.inFlight <- struct{}{}:
+			defer func() { <-cb.inFlight }()
+		default:
+			writeUnavailableErr(w, req, errTooBusy)
+			return
+		}
+
+		if err := cb.allow(); err != nil {
+			writeUnavailableErr(w, req, err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), cb.timeout)
+		defer cancel()
+		// only the status is needed so that the large responses are not kept in memory
+		rec := newCountingResponseWriter(w)
+		h.ServeHTTP(rec, req.WithContext(ctx))
+		cb.record(rec.status < http.StatusInternalServerError && rec.status != http.StatusTooManyRequests)
+	})
+}
+
+// Health returns the circuit breaker health reports.
+func (cb *circuitBreaker) Health() health.Reports {
+	cb.mu.Lock()
+	state := cb.state
+	cb.mu.Unlock()
+
+	circuitReport := &health.Report{
+		Name:    "circuit",
+		Status:  health.StatusOK,
+		Details: string(state),
+	}
+	if state != circuitClosed {
+		circuitReport.Status = health.StatusFailing
+	}
+	return health.Reports{
+		circuitReport,
+		{
+			Name:    "in-flight",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(len(cb.inFlight)),
+		},
+	}
+}
+
+func writeUnavailableErr(w http.ResponseWriter, req *http.Request, err error) {
+	var id []byte
+	if rpcReqs, isBatch, _ := readRequests(req); !isBatch && len(rpcReqs) == 1 {
+		id = rpcReqs[0].ID
+	}
+	writeJsonRpcErr(w, http.StatusServiceUnavailable, id, -32000, err.Error())
+}
@@ -0,0 +1,54 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	r := require.New(t)
+
+	cb := newCircuitBreaker(config.JsonRpcCircuitBreakerConfig{FailureThreshold: 2, CooldownSeconds: 1})
+
+	var upstreamCalls int
+	upstreamStatus := http.StatusBadGateway
+	h := cb.handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		upstreamCalls++
+		w.WriteHeader(upstreamStatus)
+	}))
+	doRequest := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(`{"jsonrpc":"2.0","id":7,"method":"eth_call"}`))
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	doRequest()
+	doRequest()
+	r.Equal(2, upstreamCalls)
+
+	// the circuit is open now and the requests fail fast
+	recorder := doRequest()
+	r.Equal(2, upstreamCalls)
+	r.Equal(http.StatusServiceUnavailable, recorder.Code)
+	var rpcResp jsonRpcResponse
+	r.NoError(json.NewDecoder(recorder.Body).Decode(&rpcResp))
+	r.Equal("7", string(rpcResp.ID))
+	r.Equal(-32000, rpcResp.Error.Code)
+	r.Equal(health.StatusFailing, cb.Health()[0].Status)
+
+	// a trial request closes the circuit after the cooldown
+	time.Sleep(time.Second)
+	upstreamStatus = http.StatusOK
+	r.Equal(http.StatusOK, doRequest().Code)
+	r.Equal(3, upstreamCalls)
+	r.Equal(circuitClosed, cb.state)
+}
@@ -0,0 +1,359 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	rds "github.com/forta-network/forta-node/clients/redis"
+	"github.com/forta-network/forta-node/config"
+	"github.com/go-redis/redis"
+	log "github.com/sirupsen/logrus"
+)
+
+// cacheRule tells how long a method result can be cached and if the result
+// should belong to a final block before caching it.
+type cacheRule struct {
+	TTL       time.Duration
+	FinalOnly bool
+}
+
+var defaultCacheRules = map[string]cacheRule{
+	"eth_chainId":               {TTL: time.Hour},
+	"net_version":               {TTL: time.Hour},
+	"eth_getBlockByNumber":      {TTL: time.Minute * 10, FinalOnly: true},
+	"eth_getBlockByHash":        {TTL: time.Minute * 10, FinalOnly: true},
+	"eth_getTransactionReceipt": {TTL: time.Minute * 10, FinalOnly: true},
+	"eth_getTransactionByHash":  {TTL: time.Minute * 10, FinalOnly: true},
+}
+
+// responseCache stores the JSON-RPC results.
+type responseCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+type cacheEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+type inMemoryCache struct {
+	entries    map[string]*cacheEntry
+	maxEntries int
+	mu         sync.Mutex
+}
+
+func newInMemoryCache(maxEntries int) *inMemoryCache {
+	return &inMemoryCache{
+		entries:    make(map[string]*cacheEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+// Get implements the responseCache interface.
+func (c *inMemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set implements the responseCache interface.
+func (c *inMemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= c.maxEntries {
+		c.evictExpiredUnsafe()
+	}
+	if len(c.entries) >= c.maxEntries {
+		return
+	}
+	c.entries[key] = &cacheEntry{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+func (c *inMemoryCache) evictExpiredUnsafe() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+type redisCache struct {
+	r rds.Client
+}
+
+// Get implements the responseCache interface.
+func (c *redisCache) Get(key string) ([]byte, bool) {
+	b, err := c.r.Get(key).Bytes()
+	if err == redis.Nil {
+		return nil, false
+	}
+	if err != nil {
+		log.WithError(err).Warn("failed to get from the json-rpc cache")
+		return nil, false
+	}
+	return b, true
+}
+
+// Set implements the responseCache interface.
+func (c *redisCache) Set(key string, value []byte, ttl time.Duration) {
+	if err := c.r.Set(key, value, ttl).Err(); err != nil {
+		log.WithError(err).Warn("failed to set to the json-rpc cache")
+	}
+}
+
+type jsonRpcCache struct {
+	cfg         config.JsonRpcCacheConfig
+	chainID     int
+	rules       map[string]cacheRule
+	cache       responseCache
+	latestBlock uint64
+	hits        uint64
+	misses      uint64
+}
+
+func newJsonRpcCache(cfg config.JsonRpcCacheConfig) (*jsonRpcCache, error) {
+	rules := make(map[string]cacheRule)
+	for method, rule := range defaultCacheRules {
+		rules[method] = rule
+	}
+	for method, ttlSeconds := range cfg.MethodTTLSeconds {
+		if ttlSeconds <= 0 {
+			delete(rules, method)
+			continue
+		}
+		rule := rules[method]
+		rule.TTL = time.Duration(ttlSeconds) * time.Second
+		rules[method] = rule
+	}
+
+	var cache responseCache = newInMemoryCache(cfg.MaxEntries)
+	if cfg.Redis != nil {
+		r, err := rds.NewClient(*cfg.Redis)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the redis client for json-rpc cache: %v", err)
+		}
+		cache = &redisCache{r: r}
+	}
+
+	return &jsonRpcCache{cfg: cfg, rules: rules, cache: cache}, nil
+}
+
+// cacheKey includes the chain so that the proxies of different chains can share a cache.
+func (c *jsonRpcCache) cacheKey(method string, params json.RawMessage) string {
+	return fmt.Sprintf("jsonrpc:%d:%s:%s", c.chainID, method, string(params))
+}
+
+// handler serves the cached results and caches the upstream results.
+func (c *jsonRpcCache) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			http.Error(w, "failed to read the request body", http.StatusBadRequest)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var rpcReq jsonRpcRequest
+		if err := json.Unmarshal(body, &rpcReq); err != nil {
+			// batches are handled item by item by the batch handler and malformed requests are not cached
+			h.ServeHTTP(w, req)
+			return
+		}
+
+		if !c.isCacheable(rpcReq) {
+			c.serveAndObserve(h, w, req, rpcReq)
+			return
+		}
+
+		if result, ok := c.lookup(rpcReq); ok {
+			writeJsonRpcResult(w, rpcReq.ID, result)
+			return
+		}
+
+		result, ok := serveAndRecordResult(h, w, req)
+		if !ok {
+			return
+		}
+		c.store(rpcReq, result)
+	})
+}
+
+func (c *jsonRpcCache) isCacheable(rpcReq jsonRpcRequest) bool {
+	_, ok := c.rules[rpcReq.Method]
+	return ok && isCacheableRequest(rpcReq)
+}
+
+// lookup finds the cached result of a cacheable request.
+func (c *jsonRpcCache) lookup(rpcReq jsonRpcRequest) (json.RawMessage, bool) {
+	if !c.isCacheable(rpcReq) {
+		return nil, false
+	}
+	if result, ok := c.cache.Get(c.cacheKey(rpcReq.Method, rpcReq.Params)); ok {
+		atomic.AddUint64(&c.hits, 1)
+		return result, true
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return nil, false
+}
+
+// store caches the result if the request is cacheable and the result is final enough.
+func (c *jsonRpcCache) store(rpcReq jsonRpcRequest, result json.RawMessage) {
+	rule, ok := c.rules[rpcReq.Method]
+	if !ok || !isCacheableRequest(rpcReq) {
+		return
+	}
+	if rule.FinalOnly && !c.isFinal(result) {
+		return
+	}
+	c.cache.Set(c.cacheKey(rpcReq.Method, rpcReq.Params), result, rule.TTL)
+}
+
+// observe keeps track of the latest block by checking the results.
+func (c *jsonRpcCache) observe(rpcReq jsonRpcRequest, result json.RawMessage) {
+	switch rpcReq.Method {
+	case "eth_blockNumber":
+		c.observeLatestBlock(result)
+
+	case "eth_getBlockByNumber":
+		var block struct {
+			Number json.RawMessage `json:"number"`
+		}
+		if err := json.Unmarshal(result, &block); err == nil {
+			c.observeLatestBlock(block.Number)
+		}
+	}
+}
+
+// serveAndObserve serves the request and keeps track of the latest block by checking the responses.
+func (c *jsonRpcCache) serveAndObserve(h http.Handler, w http.ResponseWriter, req *http.Request, rpcReq jsonRpcRequest) {
+	switch rpcReq.Method {
+	case "eth_blockNumber", "eth_getBlockByNumber":
+		if result, ok := serveAndRecordResult(h, w, req); ok {
+			c.observe(rpcReq, result)
+		}
+
+	default:
+		h.ServeHTTP(w, req)
+	}
+}
+
+// serveAndRecordResult serves the request and returns the successful result from the response.
+func serveAndRecordResult(h http.Handler, w http.ResponseWriter, req *http.Request) (json.RawMessage, bool) {
+	// make sure that we get a plain response from the upstream so we can decode it
+	req.Header.Del("Accept-Encoding")
+	rec := newResponseRecorder(w)
+	h.ServeHTTP(rec, req)
+	if rec.status != http.StatusOK {
+		return nil, false
+	}
+
+	var rpcResp jsonRpcResponse
+	if err := json.Unmarshal(rec.body.Bytes(), &rpcResp); err != nil {
+		return nil, false
+	}
+	if rpcResp.Error != nil || len(rpcResp.Result) == 0 || string(rpcResp.Result) == "null" {
+		return nil, false
+	}
+	return rpcResp.Result, true
+}
+
+// observeLatestBlock remembers the latest block number from eth_blockNumber results.
+func (c *jsonRpcCache) observeLatestBlock(result json.RawMessage) {
+	var hexNum string
+	if err := json.Unmarshal(result, &hexNum); err != nil {
+		return
+	}
+	num, err := strconv.ParseUint(strings.TrimPrefix(hexNum, "0x"), 16, 64)
+	if err != nil {
+		return
+	}
+	for {
+		latest := atomic.LoadUint64(&c.latestBlock)
+		if num <= latest || atomic.CompareAndSwapUint64(&c.latestBlock, latest, num) {
+			return
+		}
+	}
+}
+
+// isFinal checks the block number in the result against the latest known block.
+func (c *jsonRpcCache) isFinal(result json.RawMessage) bool {
+	var blockRef struct {
+		Number      string `json:"number"`
+		BlockNumber string `json:"blockNumber"`
+	}
+	if err := json.Unmarshal(result, &blockRef); err != nil {
+		return false
+	}
+	hexNum := blockRef.Number
+	if len(hexNum) == 0 {
+		hexNum = blockRef.BlockNumber
+	}
+	num, err := strconv.ParseUint(strings.TrimPrefix(hexNum, "0x"), 16, 64)
+	if err != nil {
+		return false
+	}
+	latest := atomic.LoadUint64(&c.latestBlock)
+	return latest > 0 && num+c.cfg.FinalityDepth <= latest
+}
+
+// isCacheableRequest filters out the requests which refer to a moving block tag.
+func isCacheableRequest(rpcReq jsonRpcRequest) bool {
+	if rpcReq.Method != "eth_getBlockByNumber" {
+		return true
+	}
+	var params []interface{}
+	if err := json.Unmarshal(rpcReq.Params, &params); err != nil || len(params) == 0 {
+		return false
+	}
+	blockTag, ok := params[0].(string)
+	return ok && strings.HasPrefix(blockTag, "0x")
+}
+
+// Health returns the cache health reports.
+func (c *jsonRpcCache) Health() health.Reports {
+	return health.Reports{
+		{
+			Name:    "cache.hits",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&c.hits), 10),
+		},
+		{
+			Name:    "cache.misses",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&c.misses), 10),
+		},
+	}
+}
+
+func writeJsonRpcResult(w http.ResponseWriter, id json.RawMessage, result json.RawMessage) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&jsonRpcResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Result:  result,
+	}); err != nil {
+		log.WithError(err).Error("failed to write cached jsonrpc response")
+	}
+}
@@ -0,0 +1,73 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func doCachedRequest(r *require.Assertions, h http.Handler, body string) *jsonRpcResponse {
+	req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(body))
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	var resp jsonRpcResponse
+	r.NoError(json.NewDecoder(recorder.Body).Decode(&resp))
+	return &resp
+}
+
+func TestJsonRpcCache(t *testing.T) {
+	r := require.New(t)
+
+	cache, err := newJsonRpcCache(config.JsonRpcCacheConfig{Enable: true, FinalityDepth: 2, MaxEntries: 10})
+	r.NoError(err)
+
+	var upstreamCalls int
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		upstreamCalls++
+		var rpcReq jsonRpcRequest
+		r.NoError(json.NewDecoder(req.Body).Decode(&rpcReq))
+		var result string
+		switch rpcReq.Method {
+		case "eth_blockNumber":
+			result = `"0xa"`
+		case "eth_chainId":
+			result = `"0x1"`
+		case "eth_getBlockByNumber":
+			var params []string
+			r.NoError(json.Unmarshal(rpcReq.Params, &params))
+			result = `{"number":"` + params[0] + `"}`
+		}
+		json.NewEncoder(w).Encode(&jsonRpcResponse{JSONRPC: "2.0", ID: rpcReq.ID, Result: json.RawMessage(result)})
+	})
+	h := cache.handler(upstream)
+
+	// chain id is cached regardless of the blocks
+	resp := doCachedRequest(r, h, `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`)
+	r.Equal(`"0x1"`, string(resp.Result))
+	resp = doCachedRequest(r, h, `{"jsonrpc":"2.0","id":2,"method":"eth_chainId","params":[]}`)
+	r.Equal(`"0x1"`, string(resp.Result))
+	r.Equal("2", string(resp.ID))
+	r.Equal(1, upstreamCalls)
+
+	// the block is not final before we know the latest block
+	doCachedRequest(r, h, `{"jsonrpc":"2.0","id":3,"method":"eth_getBlockByNumber","params":["0x5"]}`)
+	doCachedRequest(r, h, `{"jsonrpc":"2.0","id":4,"method":"eth_getBlockByNumber","params":["0x5"]}`)
+	r.Equal(3, upstreamCalls)
+
+	// after learning the latest block, the final blocks are cached
+	doCachedRequest(r, h, `{"jsonrpc":"2.0","id":5,"method":"eth_blockNumber","params":[]}`)
+	doCachedRequest(r, h, `{"jsonrpc":"2.0","id":6,"method":"eth_getBlockByNumber","params":["0x5"]}`)
+	resp = doCachedRequest(r, h, `{"jsonrpc":"2.0","id":7,"method":"eth_getBlockByNumber","params":["0x5"]}`)
+	r.Equal(`{"number":"0x5"}`, string(resp.Result))
+	r.Equal(5, upstreamCalls)
+
+	// recent blocks are not cached
+	doCachedRequest(r, h, `{"jsonrpc":"2.0","id":8,"method":"eth_getBlockByNumber","params":["0x9"]}`)
+	doCachedRequest(r, h, `{"jsonrpc":"2.0","id":9,"method":"eth_getBlockByNumber","params":["0x9"]}`)
+	r.Equal(7, upstreamCalls)
+}
@@ -0,0 +1,170 @@
+package json_rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// nonCoalescedMethods have side effects or results which are specific to the caller.
+var nonCoalescedMethods = map[string]bool{
+	"eth_sendRawTransaction":          true,
+	"eth_sendTransaction":             true,
+	"eth_subscribe":                   true,
+	"eth_unsubscribe":                 true,
+	"eth_newFilter":                   true,
+	"eth_newBlockFilter":              true,
+	"eth_newPendingTransactionFilter": true,
+	"eth_getFilterChanges":            true,
+	"eth_uninstallFilter":             true,
+}
+
+// coalescedResponse is the upstream response which is shared by the identical requests.
+type coalescedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// requestCoalescer sends the identical requests which arrive at the same time (e.g. right after
+// a new block) to the upstream only once and fans out the response to all callers. The shared
+// responses are kept in memory and they are bounded by the response size limits.
+type requestCoalescer struct {
+	group     singleflight.Group
+	coalesced uint64
+}
+
+func newRequestCoalescer() *requestCoalescer {
+	return &requestCoalescer{}
+}
+
+func (rc *requestCoalescer) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			http.Error(w, "failed to read the request body", http.StatusBadRequest)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var rpcReq jsonRpcRequest
+		// batches are coalesced item by item after they are split by the batch handler
+		if err := json.Unmarshal(body, &rpcReq); err != nil || !isCoalescable(rpcReq) {
+			h.ServeHTTP(w, req)
+			return
+		}
+
+		var executed, aborted bool
+		key := fmt.Sprintf("%s:%s", rpcReq.Method, string(rpcReq.Params))
+		v, err, _ := rc.group.Do(key, func() (result interface{}, err error) {
+			executed = true
+			// the oversized responses are aborted while they are streamed
+			defer func() {
+				if r := recover(); r != nil {
+					if r != http.ErrAbortHandler {
+						panic(r)
+					}
+					aborted = true
+					err = errResponseTooLarge
+				}
+			}()
+			// make sure that we get a plain response from the upstream so we can share it
+			req.Header.Del("Accept-Encoding")
+			rec := newBufferedResponseWriter()
+			h.ServeHTTP(rec, req)
+			return &coalescedResponse{status: rec.status, header: rec.header, body: rec.body.Bytes()}, nil
+		})
+		if aborted {
+			panic(http.ErrAbortHandler)
+		}
+		if executed {
+			resp := v.(*coalescedResponse)
+			writeCoalescedResponse(w, resp, resp.body)
+			return
+		}
+
+		// the other callers should not be affected by the failures of the first one
+		var (
+			respBody []byte
+			ok       bool
+		)
+		if err == nil {
+			respBody, ok = withResponseID(v.(*coalescedResponse), rpcReq.ID)
+		}
+		if !ok {
+			h.ServeHTTP(w, req)
+			return
+		}
+		atomic.AddUint64(&rc.coalesced, 1)
+		countCoalesced(req.Context())
+		writeCoalescedResponse(w, v.(*coalescedResponse), respBody)
+	})
+}
+
+// Health returns the request coalescing health reports.
+func (rc *requestCoalescer) Health() health.Reports {
+	return health.Reports{
+		{
+			Name:    "coalesce.requests",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&rc.coalesced), 10),
+		},
+	}
+}
+
+func isCoalescable(rpcReq jsonRpcRequest) bool {
+	// notifications do not expect a response
+	return len(rpcReq.ID) > 0 && len(rpcReq.Method) > 0 && !nonCoalescedMethods[rpcReq.Method]
+}
+
+// withResponseID replaces the id in the shared response with the id of the caller.
+func withResponseID(resp *coalescedResponse, id json.RawMessage) ([]byte, bool) {
+	if resp.status != http.StatusOK {
+		return nil, false
+	}
+	var rpcResp jsonRpcResponse
+	if err := json.Unmarshal(resp.body, &rpcResp); err != nil {
+		return nil, false
+	}
+	rpcResp.ID = id
+	body, err := json.Marshal(&rpcResp)
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+func writeCoalescedResponse(w http.ResponseWriter, resp *coalescedResponse, body []byte) {
+	for h, v := range resp.header {
+		w.Header()[h] = v
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(resp.status)
+	if _, err := w.Write(body); err != nil {
+		log.WithError(err).Debug("failed to write the coalesced response")
+	}
+}
+
+type coalesceCounterKey struct{}
+
+// withCoalesceCounter returns a context which collects the coalesced requests.
+func withCoalesceCounter(ctx context.Context) (context.Context, *int64) {
+	counter := new(int64)
+	return context.WithValue(ctx, coalesceCounterKey{}, counter), counter
+}
+
+func countCoalesced(ctx context.Context) {
+	if counter, ok := ctx.Value(coalesceCounterKey{}).(*int64); ok {
+		atomic.AddInt64(counter, 1)
+	}
+}
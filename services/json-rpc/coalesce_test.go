@@ -0,0 +1,82 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestCoalescer(t *testing.T) {
+	r := require.New(t)
+
+	var calls int64
+	release := make(chan struct{})
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		<-release
+		var rpcReq jsonRpcRequest
+		json.NewDecoder(req.Body).Decode(&rpcReq)
+		writeJsonRpcResult(w, rpcReq.ID, json.RawMessage(`"0x10"`))
+	})
+	rc := newRequestCoalescer()
+	handler := rc.handler(upstream)
+
+	const count = 5
+	var (
+		wg        sync.WaitGroup
+		responses [count]jsonRpcResponse
+	)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body, _ := json.Marshal(&jsonRpcRequest{JSONRPC: "2.0", ID: json.RawMessage(string(rune('1' + i))), Method: "eth_blockNumber"})
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+			json.Unmarshal(rec.Body.Bytes(), &responses[i])
+		}(i)
+	}
+	// let the first request reach the upstream and the others wait for it
+	r.Eventually(func() bool { return atomic.LoadInt64(&calls) > 0 }, time.Second, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 100)
+	close(release)
+	wg.Wait()
+
+	for i, resp := range responses {
+		r.Equal(string(rune('1'+i)), string(resp.ID))
+		r.Equal(`"0x10"`, string(resp.Result))
+	}
+	r.Equal(int64(1), atomic.LoadInt64(&calls))
+	r.Equal("4", rc.Health()[0].Details)
+}
+
+func TestRequestCoalescer_NotCoalesced(t *testing.T) {
+	r := require.New(t)
+
+	var calls int64
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		body, _ := ioutil.ReadAll(req.Body)
+		w.Write(body)
+	})
+	handler := newRequestCoalescer().handler(upstream)
+
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x1234"]}`,
+		`{"jsonrpc":"2.0","method":"eth_blockNumber"}`,
+		`[{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber"}]`,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body)))
+		r.Equal(body, rec.Body.String())
+	}
+	r.Equal(int64(3), calls)
+}
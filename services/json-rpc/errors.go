@@ -2,6 +2,8 @@ package json_rpc
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	log "github.com/sirupsen/logrus"
@@ -42,3 +44,51 @@ func writeTooManyReqsErr(w http.ResponseWriter, req *http.Request) {
 		log.WithError(err).Error("failed to write jsonrpc error response body")
 	}
 }
+
+func writeMethodNotAllowedErr(w http.ResponseWriter, rpcReq jsonRpcRequest) {
+	writeJsonRpcErr(w, http.StatusForbidden, rpcReq.ID, -32004, fmt.Sprintf("method '%s' is not allowed by the scan node policy", rpcReq.Method))
+}
+
+// writeQuotaExceededErr responds with the id of the request. The batches get an error without an id.
+func writeQuotaExceededErr(w http.ResponseWriter, rpcReqs []jsonRpcRequest) {
+	var id json.RawMessage
+	if len(rpcReqs) == 1 {
+		id = rpcReqs[0].ID
+	}
+	writeJsonRpcErr(w, http.StatusTooManyRequests, id, -32005, "agent exceeds its scan node usage quota")
+}
+
+// writeAuthErr responds with the id of the request. The batches get an error without an id.
+func writeAuthErr(w http.ResponseWriter, rpcReqs []jsonRpcRequest) {
+	var id json.RawMessage
+	if len(rpcReqs) == 1 {
+		id = rpcReqs[0].ID
+	}
+	writeJsonRpcErr(w, http.StatusUnauthorized, id, -32003, "request source is not an authenticated bot")
+}
+
+// writeParseErr responds with an invalid request error if the body is valid JSON and with a parse error otherwise.
+func writeParseErr(w http.ResponseWriter, err error) {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		writeJsonRpcErr(w, http.StatusBadRequest, nil, -32600, "invalid request")
+		return
+	}
+	writeJsonRpcErr(w, http.StatusBadRequest, nil, -32700, "parse error")
+}
+
+func writeJsonRpcErr(w http.ResponseWriter, statusCode int, id json.RawMessage, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(&jsonRpcResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error: &jsonRpcError{
+			Code:    code,
+			Message: message,
+		},
+	}); err != nil {
+		log.WithError(err).Error("failed to write jsonrpc error response body")
+	}
+}
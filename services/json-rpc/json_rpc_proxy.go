@@ -5,51 +5,76 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httputil"
-	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/ratelimiter"
 	"github.com/rs/cors"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/forta-network/forta-core-go/clients/health"
-	"github.com/forta-network/forta-core-go/ethereum"
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-core-go/protocol/settings"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components/metrics"
 )
 
+const defaultProxyName = "json-rpc-proxy"
+
 // JsonRpcProxy proxies requests from agents to json-rpc endpoint
 type JsonRpcProxy struct {
 	ctx       context.Context
+	name      string
 	cfg       config.JsonRpcConfig
+	proxyCfg  config.JsonRpcProxyConfig
+	upstreams []config.JsonRpcUpstreamConfig
+	trace     config.JsonRpcConfig
+	pool      *upstreamPool
+	limiter   *responseLimiter
 	server    *http.Server
+	tlsServer *http.Server
+	fortaDir  string
 	msgClient clients.MessageClient
 
-	rateLimiter ratelimiter.RateLimiter
+	rateLimiter  ratelimiter.RateLimiter
+	cache        *jsonRpcCache
+	coalescer    *requestCoalescer
+	methodPolicy *methodPolicy
+	methodCosts  methodCosts
+	breaker      *circuitBreaker
+	accessLog    *accessLogger
+	localData    *localData
+	quotas       *quotaTracker
+
+	subscriptions *subscriptionMux
 
 	lastErr          health.ErrorTracker
 	botAuthenticator clients.IPAuthenticator
+
+	defaultRateLimit *config.RateLimitConfig
+	botRateLimits    map[string]config.RateLimitConfig
+	runningBots      []config.AgentConfig
+	reloadMu         sync.RWMutex
 }
 
 func (p *JsonRpcProxy) Start() error {
-	rpcUrl, err := url.Parse(p.cfg.Url)
+	pool, err := newUpstreamPool(p.fortaDir, p.upstreams, p.proxyCfg.Retry, p.proxyCfg.Transport)
 	if err != nil {
 		return err
 	}
-	rp := httputil.NewSingleHostReverseProxy(rpcUrl)
+	p.pool = pool
+	p.limiter = newResponseLimiter(pool, p.proxyCfg)
 
-	d := rp.Director
-	rp.Director = func(r *http.Request) {
-		d(r)
-		r.Host = rpcUrl.Host
-		r.URL = rpcUrl
-		for h, v := range p.cfg.Headers {
-			r.Header.Set(h, v)
-		}
+	// the upstream pool sets the destination and retries on the other upstreams and
+	// the responses are streamed to the bots until they exceed the size limit
+	rp := &httputil.ReverseProxy{
+		Director:  func(r *http.Request) {},
+		Transport: p.limiter,
 	}
 
 	c := cors.New(cors.Options{
@@ -57,12 +82,57 @@ func (p *JsonRpcProxy) Start() error {
 		AllowCredentials: true,
 	})
 
+	// the cache is in front of the circuit breaker so that the cached results are served while the circuit is open
+	var handler http.Handler = p.breaker.handler(rp)
+	// the coalescer is behind the cache and the local data so that only the misses are sent to the upstream
+	if p.coalescer != nil {
+		handler = p.coalescer.handler(handler)
+	}
+	// the local data is behind the cache so that the local results are cached as well
+	if p.localData != nil {
+		handler = p.localData.handler(handler)
+	}
+	if p.cache != nil {
+		handler = p.cache.handler(handler)
+	}
+	handler = newBatchHandler(p.proxyCfg.MaxBatchSize, p.proxyCfg.BatchSplitSize, p.cache, p.localData).handler(handler)
+	if len(p.trace.Url) > 0 {
+		traceHandler, err := newTraceHandler(p.fortaDir, p.trace, p.proxyCfg)
+		if err != nil {
+			return err
+		}
+		handler = p.traceRouter(traceHandler, handler)
+	}
+
+	listenAddr := p.proxyCfg.ListenAddr
+	if len(listenAddr) == 0 {
+		listenAddr = ":" + config.DefaultJSONRPCProxyPort
+	}
 	p.server = &http.Server{
-		Addr:    ":8545",
-		Handler: p.metricHandler(c.Handler(rp)),
+		Addr:    listenAddr,
+		Handler: p.websocketHandler(p.metricHandler(c.Handler(handler)), handler),
 	}
 	utils.GoListenAndServe(p.server)
 
+	if tlsCfg := p.proxyCfg.ServerTLS; tlsCfg != nil {
+		p.tlsServer = &http.Server{
+			Addr:    ":" + config.DefaultJSONRPCProxyTLSPort,
+			Handler: p.server.Handler,
+		}
+		go func() {
+			err := p.tlsServer.ListenAndServeTLS(
+				resolveFortaPath(p.fortaDir, tlsCfg.CertFile),
+				resolveFortaPath(p.fortaDir, tlsCfg.KeyFile),
+			)
+			if err != nil && err != http.ErrServerClosed {
+				log.WithError(err).Panic("tls server error")
+			}
+		}()
+	}
+
+	// the rate limits of the bots are set when the running bots change
+	p.msgClient.Subscribe(messaging.SubjectAgentsStatusRunning, messaging.AgentsHandler(p.handleRunningBots))
+
 	go p.apiHealthChecker()
 
 	return nil
@@ -71,31 +141,156 @@ func (p *JsonRpcProxy) Start() error {
 func (p *JsonRpcProxy) metricHandler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		t := time.Now()
-		agentConfig, err := p.botAuthenticator.FindAgentFromRemoteAddr(req.RemoteAddr)
-		if err == nil && p.rateLimiter.ExceedsLimit(agentConfig.ID) {
+		rpcReqs, _, parseErr := readRequests(req)
+		agentConfig, err := p.botAuthenticator.FindAgentFromRequest(req)
+
+		if p.accessLog != nil && p.accessLog.Sample() {
+			rec := newCountingResponseWriter(w)
+			w = rec
+			defer func() {
+				var botID string
+				if agentConfig != nil {
+					botID = agentConfig.ID
+				}
+				p.accessLog.Log(botID, rpcReqs, req, rec, time.Since(t))
+			}()
+		}
+
+		if err != nil {
+			// the node services use the proxy without a bot token and are not metered
+			if p.isNodeServiceRequest(req) {
+				h.ServeHTTP(w, req)
+				return
+			}
+			log.WithError(err).Warn("failed to authenticate the bot request")
+			writeAuthErr(w, rpcReqs)
+			return
+		}
+		// the method policy can only check the requests it can read
+		if parseErr != nil {
+			writeParseErr(w, parseErr)
+			return
+		}
+		req = req.WithContext(withBot(req.Context(), agentConfig))
+		methods := requestMethods(rpcReqs)
+		// each batch item counts as a request
+		count := len(rpcReqs)
+		if count == 0 {
+			count = 1
+		}
+
+		if p.rateLimiter.ExceedsLimitN(agentConfig.ID, p.methodCosts.RequestsCost(rpcReqs)) {
 			writeTooManyReqsErr(w, req)
 			p.msgClient.PublishProto(
 				messaging.SubjectMetricAgent, &protocol.AgentMetricList{
-					Metrics: metrics.GetJSONRPCMetrics(*agentConfig, t, 0, 1, 0),
+					Metrics: append(
+						metrics.GetJSONRPCMetrics(*agentConfig, t, 0, count, 0),
+						metrics.GetJSONRPCMethodMetrics(*agentConfig, t, metrics.MetricJSONRPCThrottled, methods)...,
+					),
 				},
 			)
 			return
 		}
 
-		h.ServeHTTP(w, req)
+		if !p.checkMethodPolicy(w, rpcReqs, agentConfig) {
+			return
+		}
 
-		if err == nil {
-			duration := time.Since(t)
+		if p.quotas != nil && !p.quotas.Use(agentConfig.ID, count, p.methodCosts.RequestsCost(rpcReqs)) {
+			writeQuotaExceededErr(w, rpcReqs)
 			p.msgClient.PublishProto(
 				messaging.SubjectMetricAgent, &protocol.AgentMetricList{
-					Metrics: metrics.GetJSONRPCMetrics(*agentConfig, t, 1, 0, duration),
+					Metrics: metrics.GetJSONRPCMethodMetrics(*agentConfig, t, metrics.MetricJSONRPCQuotaExceeded, methods),
 				},
 			)
+			return
+		}
+
+		ctx, retries := withRetryCounter(req.Context())
+		ctx, coalesced := withCoalesceCounter(ctx)
+		h.ServeHTTP(w, req.WithContext(ctx))
+
+		duration := time.Since(t)
+		ms := append(
+			metrics.GetJSONRPCMetrics(*agentConfig, t, count, 0, duration),
+			metrics.GetJSONRPCMethodMetrics(*agentConfig, t, metrics.MetricJSONRPCRequest, methods)...,
+		)
+		if n := atomic.LoadInt64(retries); n > 0 {
+			ms = append(ms, metrics.CreateAgentMetric(agentConfig.ID, metrics.MetricJSONRPCRetry, float64(n)))
 		}
+		if n := atomic.LoadInt64(coalesced); n > 0 {
+			ms = append(ms, metrics.CreateAgentMetric(agentConfig.ID, metrics.MetricJSONRPCCoalesced, float64(n)))
+		}
+		p.msgClient.PublishProto(
+			messaging.SubjectMetricAgent, &protocol.AgentMetricList{
+				Metrics: ms,
+			},
+		)
 	})
 }
 
+// isNodeServiceRequest tells if the request is coming from one of the node service containers.
+func (p *JsonRpcProxy) isNodeServiceRequest(req *http.Request) bool {
+	containerName, err := p.botAuthenticator.FindContainerNameFromRemoteAddr(req.Context(), req.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	switch containerName {
+	case config.DockerScannerContainerName, config.DockerInspectorContainerName, config.DockerSupervisorContainerName:
+		return true
+	}
+	return false
+}
+
+type botKey struct{}
+
+// withBot returns a context which carries the authenticated bot of a request.
+func withBot(ctx context.Context, agentConfig *config.AgentConfig) context.Context {
+	return context.WithValue(ctx, botKey{}, agentConfig)
+}
+
+// botFromContext returns the authenticated bot of a request if there is one.
+func botFromContext(ctx context.Context) *config.AgentConfig {
+	agentConfig, _ := ctx.Value(botKey{}).(*config.AgentConfig)
+	return agentConfig
+}
+
+// checkMethodPolicy rejects the request if the bot calls any method which is not allowed.
+func (p *JsonRpcProxy) checkMethodPolicy(w http.ResponseWriter, rpcReqs []jsonRpcRequest, agentConfig *config.AgentConfig) bool {
+	if p.methodPolicy == nil {
+		return true
+	}
+	for _, rpcReq := range rpcReqs {
+		if p.methodPolicy.IsAllowed(agentConfig.ID, rpcReq.Method) {
+			continue
+		}
+		log.WithFields(log.Fields{
+			"bot":    agentConfig.ID,
+			"method": rpcReq.Method,
+		}).Warn("bot called a method which is not allowed")
+		writeMethodNotAllowedErr(w, rpcReq)
+		metrics.SendAgentMetrics(p.msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(agentConfig.ID, metrics.MetricJSONRPCDenied, 1),
+		})
+		return false
+	}
+	return true
+}
+
+// ShutdownStage implements the services.StagedService interface.
+func (p *JsonRpcProxy) ShutdownStage() services.ShutdownStage {
+	return services.StageProxy
+}
+
 func (p *JsonRpcProxy) Stop() error {
+	if p.quotas != nil {
+		if err := p.quotas.Save(); err != nil {
+			log.WithError(err).Warn("failed to save the quota counters")
+		}
+	}
+	if p.tlsServer != nil {
+		p.tlsServer.Close()
+	}
 	if p.server != nil {
 		return p.server.Close()
 	}
@@ -103,55 +298,241 @@ func (p *JsonRpcProxy) Stop() error {
 }
 
 func (p *JsonRpcProxy) Name() string {
-	return "json-rpc-proxy"
+	return p.name
 }
 
 // Health implements health.Reporter interface.
 func (p *JsonRpcProxy) Health() health.Reports {
-	return health.Reports{
+	reports := health.Reports{
 		p.lastErr.GetReport("api"),
 	}
+	if p.breaker != nil {
+		reports = append(reports, p.breaker.Health()...)
+	}
+	if p.cache != nil {
+		reports = append(reports, p.cache.Health()...)
+	}
+	if p.localData != nil {
+		reports = append(reports, p.localData.Health()...)
+	}
+	if p.quotas != nil {
+		reports = append(reports, p.quotas.Health()...)
+	}
+	if p.coalescer != nil {
+		reports = append(reports, p.coalescer.Health()...)
+	}
+	if p.limiter != nil {
+		reports = append(reports, p.limiter.Health()...)
+	}
+	if p.pool != nil {
+		reports = append(reports, p.pool.RetriesReport())
+		reports = append(reports, p.pool.ProbeReports(p.proxyCfg.HealthCheck)...)
+	}
+	if p.pool != nil && len(p.pool.list()) > 1 {
+		reports = append(reports, p.pool.Health()...)
+	}
+	return reports
 }
 
 func (p *JsonRpcProxy) apiHealthChecker() {
-	p.testAPI()
-	ticker := time.NewTicker(time.Minute * 5)
-	for range ticker.C {
+	interval := time.Duration(p.proxyCfg.HealthCheck.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultUpstreamProbeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
 		p.testAPI()
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
+// testAPI probes the upstreams through their transports so that the tls configs are respected.
 func (p *JsonRpcProxy) testAPI() {
-	err := ethereum.TestAPI(p.ctx, p.cfg.Url)
-	p.lastErr.Set(err)
+	p.lastErr.Set(p.pool.probe(p.ctx, p.proxyCfg.HealthCheck))
+	metrics.SendAgentMetrics(p.msgClient, p.pool.ProbeMetrics())
 }
 
 func NewJsonRpcProxy(ctx context.Context, cfg config.Config) (*JsonRpcProxy, error) {
-	jCfg := cfg.Scan.JsonRpc
-	if len(cfg.JsonRpcProxy.JsonRpc.Url) > 0 {
-		jCfg = cfg.JsonRpcProxy.JsonRpc
+	msgClient := messaging.NewClient("json-rpc", cfg.NatsURL())
+
+	botAuthenticator, err := clients.NewBotAuthenticator(ctx, cfg, clients.BotTokenAudienceJSONRPC)
+	if err != nil {
+		return nil, err
 	}
 
-	rateLimiting := cfg.JsonRpcProxy.RateLimitConfig
-	if rateLimiting == nil {
-		rateLimiting = (*config.RateLimitConfig)(settings.GetChainSettings(cfg.ChainID).JsonRpcRateLimiting)
+	quotas, err := initQuotaTracker(ctx, cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	msgClient := messaging.NewClient("json-rpc", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
+	return newJsonRpcProxy(ctx, cfg, defaultProxyName, botAuthenticator, msgClient, quotas)
+}
 
-	botAuthenticator, err := clients.NewBotAuthenticator(ctx)
+// NewJsonRpcProxies creates the main proxy and the additional proxy instances for the other chains.
+// The proxies share the given bot authenticator and message client.
+func NewJsonRpcProxies(
+	ctx context.Context, cfg config.Config,
+	botAuthenticator clients.IPAuthenticator, msgClient clients.MessageClient,
+) ([]*JsonRpcProxy, error) {
+	// the quotas are shared so that the usage of a bot is bounded across all chains
+	quotas, err := initQuotaTracker(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	mainProxy, err := newJsonRpcProxy(ctx, cfg, defaultProxyName, botAuthenticator, msgClient, quotas)
+	if err != nil {
+		return nil, err
+	}
+	proxies := []*JsonRpcProxy{mainProxy}
+
+	for _, instance := range cfg.JsonRpcProxy.Instances {
+		if len(instance.JsonRpc.Url) == 0 && len(instance.Upstreams) == 0 {
+			return nil, fmt.Errorf("no json-rpc api configured for the proxy instance of chain %d", instance.ChainID)
+		}
+		instanceCfg := cfg
+		instanceCfg.ChainID = instance.ChainID
+		instanceCfg.JsonRpcProxy.ListenAddr = instance.ListenAddr
+		instanceCfg.JsonRpcProxy.JsonRpc = instance.JsonRpc
+		instanceCfg.JsonRpcProxy.Upstreams = instance.Upstreams
+		instanceCfg.JsonRpcProxy.WebsocketUrl = instance.WebsocketUrl
+		instanceCfg.JsonRpcProxy.ServerTLS = nil
+		// the scanner keeps the blocks of the main chain only
+		instanceCfg.JsonRpcProxy.LocalData.Enable = false
+		// the trace api serves the main chain only
+		instanceCfg.Trace = config.TraceConfig{}
+		instanceCfg.JsonRpcProxy.Instances = nil
+		// the bots only read from the other chains
+		instanceCfg.JsonRpcProxy.MethodPolicy = readOnlyPolicy(cfg.JsonRpcProxy.MethodPolicy)
+		instanceCfg.JsonRpcProxy.BotMethodPolicies = make(map[string]config.JsonRpcMethodPolicy)
+		for botID, policy := range cfg.JsonRpcProxy.BotMethodPolicies {
+			instanceCfg.JsonRpcProxy.BotMethodPolicies[botID] = readOnlyPolicy(policy)
+		}
+
+		proxy, err := newJsonRpcProxy(ctx, instanceCfg, fmt.Sprintf("%s-%d", defaultProxyName, instance.ChainID), botAuthenticator, msgClient, quotas)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the proxy instance of chain %d: %v", instance.ChainID, err)
+		}
+		proxies = append(proxies, proxy)
+	}
+
+	return proxies, nil
+}
+
+// initQuotaTracker creates the quota tracker and starts saving the counters periodically.
+func initQuotaTracker(ctx context.Context, cfg config.Config) (*quotaTracker, error) {
+	if !cfg.JsonRpcProxy.Quota.Enable {
+		return nil, nil
+	}
+	quotas, err := newQuotaTracker(cfg.FortaDir, cfg.JsonRpcProxy.Quota)
+	if err != nil {
+		return nil, err
+	}
+	go quotas.saveLoop(ctx)
+	return quotas, nil
+}
+
+func newJsonRpcProxy(
+	ctx context.Context, cfg config.Config, name string,
+	botAuthenticator clients.IPAuthenticator, msgClient clients.MessageClient, quotas *quotaTracker,
+) (*JsonRpcProxy, error) {
+	jCfg, upstreams := proxyUpstreams(cfg)
+	rateLimiting := proxyRateLimit(cfg)
+
+	var (
+		cache *jsonRpcCache
+		err   error
+	)
+	if cfg.JsonRpcProxy.Cache.Enable {
+		cache, err = newJsonRpcCache(cfg.JsonRpcProxy.Cache)
+		if err != nil {
+			return nil, err
+		}
+		cache.chainID = cfg.ChainID
+	}
+
+	var accessLog *accessLogger
+	if cfg.JsonRpcProxy.AccessLog.Enable {
+		accessLog, err = newAccessLogger(cfg.JsonRpcProxy.AccessLog)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var local *localData
+	if cfg.JsonRpcProxy.LocalData.Enable {
+		local = newLocalData(defaultLocalDataUrl())
+	}
+
+	var coalescer *requestCoalescer
+	if !cfg.JsonRpcProxy.DisableCoalescing {
+		coalescer = newRequestCoalescer()
+	}
+
+	// the bots which need traces can call the trace api through the proxy
+	var traceCfg config.JsonRpcConfig
+	if cfg.Trace.Enabled {
+		traceCfg = cfg.Trace.JsonRpc
+	}
+
+	wsUrl := cfg.JsonRpcProxy.WebsocketUrl
+	if len(wsUrl) == 0 {
+		wsUrl = toWebsocketUrl(jCfg.Url)
+	}
+
 	return &JsonRpcProxy{
 		ctx:              ctx,
+		name:             name,
 		cfg:              jCfg,
+		proxyCfg:         cfg.JsonRpcProxy,
+		fortaDir:         cfg.FortaDir,
+		upstreams:        upstreams,
+		trace:            traceCfg,
 		botAuthenticator: botAuthenticator,
 		msgClient:        msgClient,
 		rateLimiter: ratelimiter.NewRateLimiter(
 			rateLimiting.Rate,
 			rateLimiting.Burst,
 		),
+		cache:        cache,
+		coalescer:    coalescer,
+		methodPolicy: newMethodPolicy(cfg.JsonRpcProxy.MethodPolicy, cfg.JsonRpcProxy.BotMethodPolicies),
+		methodCosts:  newMethodCosts(cfg.JsonRpcProxy.MethodCosts),
+		breaker:      newCircuitBreaker(cfg.JsonRpcProxy.CircuitBreaker),
+		accessLog:    accessLog,
+		localData:    local,
+		quotas:       quotas,
+
+		subscriptions:    newSubscriptionMux(wsUrl, jCfg.Headers),
+		defaultRateLimit: rateLimiting,
+		botRateLimits:    cfg.JsonRpcProxy.BotRateLimits,
 	}, nil
 }
+
+// proxyUpstreams returns the main JSON-RPC API of the proxy and the upstreams to balance.
+// The main API is the only upstream if no upstreams are configured.
+func proxyUpstreams(cfg config.Config) (config.JsonRpcConfig, []config.JsonRpcUpstreamConfig) {
+	jCfg := cfg.Scan.JsonRpc
+	if len(cfg.JsonRpcProxy.JsonRpc.Url) > 0 {
+		jCfg = cfg.JsonRpcProxy.JsonRpc
+	}
+	upstreams := cfg.JsonRpcProxy.Upstreams
+	if len(upstreams) == 0 {
+		upstreams = []config.JsonRpcUpstreamConfig{{JsonRpcConfig: jCfg, Weight: 1}}
+	}
+	return jCfg, upstreams
+}
+
+// proxyRateLimit returns the default rate limit of the bots.
+func proxyRateLimit(cfg config.Config) *config.RateLimitConfig {
+	rateLimiting := cfg.JsonRpcProxy.RateLimitConfig
+	if rateLimiting == nil {
+		rateLimiting = (*config.RateLimitConfig)(settings.GetChainSettings(cfg.ChainID).JsonRpcRateLimiting)
+	}
+	return rateLimiting
+}
@@ -0,0 +1,116 @@
+package json_rpc
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+const testBotContainerName = "forta-agent-0xbot"
+
+func TestMetricHandler_Auth(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	botAuthenticator := mock_clients.NewMockIPAuthenticator(ctrl)
+	p := &JsonRpcProxy{botAuthenticator: botAuthenticator}
+
+	var proxied int
+	handler := p.metricHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		proxied++
+	}))
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(`{"jsonrpc":"2.0","id":1,"method":"eth_call"}`))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// the bot sends an invalid token
+	botAuthenticator.EXPECT().FindAgentFromRequest(gomock.Any()).Return(nil, errors.New("invalid bot token"))
+	botAuthenticator.EXPECT().FindContainerNameFromRemoteAddr(gomock.Any(), gomock.Any()).Return(testBotContainerName, nil)
+	recorder := serve()
+	r.Equal(http.StatusUnauthorized, recorder.Code)
+	r.Contains(recorder.Body.String(), "-32003")
+
+	// the bot sends no token and the ip fallback is disabled
+	botAuthenticator.EXPECT().FindAgentFromRequest(gomock.Any()).Return(nil, errors.New("bot token is required"))
+	botAuthenticator.EXPECT().FindContainerNameFromRemoteAddr(gomock.Any(), gomock.Any()).Return(testBotContainerName, nil)
+	r.Equal(http.StatusUnauthorized, serve().Code)
+	r.Equal(0, proxied)
+
+	// the node services are proxied
+	botAuthenticator.EXPECT().FindAgentFromRequest(gomock.Any()).Return(nil, errors.New("bot token is required"))
+	botAuthenticator.EXPECT().FindContainerNameFromRemoteAddr(gomock.Any(), gomock.Any()).Return(config.DockerInspectorContainerName, nil)
+	r.Equal(http.StatusOK, serve().Code)
+	r.Equal(1, proxied)
+}
+
+func TestWebsocketHandler_Auth(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	botAuthenticator := mock_clients.NewMockIPAuthenticator(ctrl)
+	p := &JsonRpcProxy{botAuthenticator: botAuthenticator}
+
+	server := httptest.NewServer(p.websocketHandler(http.NotFoundHandler(), http.NotFoundHandler()))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	// the bot sends an invalid token
+	botAuthenticator.EXPECT().FindAgentFromRequest(gomock.Any()).Return(nil, errors.New("invalid bot token"))
+	botAuthenticator.EXPECT().FindContainerNameFromRemoteAddr(gomock.Any(), gomock.Any()).Return(testBotContainerName, nil)
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"X-Forta-Bot-Token": []string{"invalid"}})
+	r.Error(err)
+	r.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	// the bot sends no token and the ip fallback is disabled
+	botAuthenticator.EXPECT().FindAgentFromRequest(gomock.Any()).Return(nil, errors.New("bot token is required"))
+	botAuthenticator.EXPECT().FindContainerNameFromRemoteAddr(gomock.Any(), gomock.Any()).Return(testBotContainerName, nil)
+	_, resp, err = websocket.DefaultDialer.Dial(wsURL, nil)
+	r.Error(err)
+	r.Equal(http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestMetricHandler_MalformedRequest(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	botAuthenticator := mock_clients.NewMockIPAuthenticator(ctrl)
+	p := &JsonRpcProxy{
+		botAuthenticator: botAuthenticator,
+		methodPolicy:     newMethodPolicy(config.JsonRpcMethodPolicy{Deny: []string{"eth_sendRawTransaction"}}, nil),
+	}
+
+	var proxied int
+	handler := p.metricHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		proxied++
+	}))
+	serve := func(body string) *httptest.ResponseRecorder {
+		botAuthenticator.EXPECT().FindAgentFromRequest(gomock.Any()).Return(&config.AgentConfig{ID: "0xbot"}, nil)
+		req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(body))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// a denied method hidden in a batch with an invalid item
+	recorder := serve(`[{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x00"]},{"method":123}]`)
+	r.Equal(http.StatusBadRequest, recorder.Code)
+	r.Contains(recorder.Body.String(), "-32600")
+
+	recorder = serve(`{"jsonrpc":"2.0","id":1,"method":"eth_call"`)
+	r.Equal(http.StatusBadRequest, recorder.Code)
+	r.Contains(recorder.Body.String(), "-32700")
+
+	r.Equal(0, proxied)
+}
@@ -0,0 +1,119 @@
+package json_rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+)
+
+const localDataTimeout = time.Second * 2
+
+// localDataMethods are the methods which the scanner can answer from the recently scanned blocks.
+// The blocks and the transactions are left to the upstream since the scanner does not keep
+// all of their fields.
+var localDataMethods = map[string]bool{
+	"eth_getLogs": true,
+}
+
+// localData answers the requests from the blocks kept by the scanner and passes
+// the misses to the next handler.
+type localData struct {
+	url    string
+	client *http.Client
+	hits   uint64
+	misses uint64
+}
+
+func newLocalData(url string) *localData {
+	return &localData{
+		url:    url,
+		client: &http.Client{Timeout: localDataTimeout},
+	}
+}
+
+func defaultLocalDataUrl() string {
+	return fmt.Sprintf("http://%s:%s", config.DockerScannerContainerName, config.DefaultBlockDataPort)
+}
+
+// handler serves the single requests from the local data. The batch items are looked up by the batch handler.
+func (ld *localData) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rpcReqs, isBatch, err := readRequests(req)
+		if err != nil || isBatch || len(rpcReqs) != 1 {
+			h.ServeHTTP(w, req)
+			return
+		}
+		if result, ok := ld.lookup(req.Context(), rpcReqs[0]); ok {
+			writeJsonRpcResult(w, rpcReqs[0].ID, result)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// lookup asks the scanner for the result of the request.
+func (ld *localData) lookup(ctx context.Context, rpcReq jsonRpcRequest) (json.RawMessage, bool) {
+	if !localDataMethods[rpcReq.Method] {
+		return nil, false
+	}
+	result, ok := ld.fetch(ctx, rpcReq)
+	if ok {
+		atomic.AddUint64(&ld.hits, 1)
+	} else {
+		atomic.AddUint64(&ld.misses, 1)
+	}
+	return result, ok
+}
+
+func (ld *localData) fetch(ctx context.Context, rpcReq jsonRpcRequest) (json.RawMessage, bool) {
+	body, err := json.Marshal(&rpcReq)
+	if err != nil {
+		return nil, false
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ld.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := ld.client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+
+	var rpcResp jsonRpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return nil, false
+	}
+	if rpcResp.Error != nil || len(rpcResp.Result) == 0 || string(rpcResp.Result) == "null" {
+		return nil, false
+	}
+	return rpcResp.Result, true
+}
+
+// Health returns the local data health reports.
+func (ld *localData) Health() health.Reports {
+	return health.Reports{
+		{
+			Name:    "local-data.hits",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&ld.hits), 10),
+		},
+		{
+			Name:    "local-data.misses",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&ld.misses), 10),
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+// methodPolicy decides which JSON-RPC methods the bots are allowed to call.
+type methodPolicy struct {
+	defaultPolicy config.JsonRpcMethodPolicy
+	botPolicies   map[string]config.JsonRpcMethodPolicy
+}
+
+func newMethodPolicy(defaultPolicy config.JsonRpcMethodPolicy, botPolicies map[string]config.JsonRpcMethodPolicy) *methodPolicy {
+	normalized := make(map[string]config.JsonRpcMethodPolicy)
+	for botID, policy := range botPolicies {
+		normalized[strings.ToLower(botID)] = policy
+	}
+	return &methodPolicy{defaultPolicy: defaultPolicy, botPolicies: normalized}
+}
+
+// IsAllowed checks if the bot can call the method. The bot policy replaces the default one if it exists.
+func (mp *methodPolicy) IsAllowed(botID, method string) bool {
+	policy := mp.defaultPolicy
+	if botPolicy, ok := mp.botPolicies[strings.ToLower(botID)]; ok {
+		policy = botPolicy
+	}
+	if matchesAnyMethod(policy.Deny, method) {
+		return false
+	}
+	return len(policy.Allow) == 0 || matchesAnyMethod(policy.Allow, method)
+}
+
+// writeMethods are denied on the proxies of the other chains since the bots only read from them.
+var writeMethods = []string{"eth_sendRawTransaction", "eth_sendTransaction", "eth_sign*"}
+
+// readOnlyPolicy denies the write methods in addition to the denied methods of the policy.
+func readOnlyPolicy(policy config.JsonRpcMethodPolicy) config.JsonRpcMethodPolicy {
+	deny := make([]string, 0, len(policy.Deny)+len(writeMethods))
+	deny = append(deny, policy.Deny...)
+	policy.Deny = append(deny, writeMethods...)
+	return policy
+}
+
+func matchesAnyMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(method, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+			continue
+		}
+		if pattern == method {
+			return true
+		}
+	}
+	return false
+}
+
+// readRequests reads the single or batch JSON-RPC requests from the body and
+// makes the body readable again for the next handlers.
+func readRequests(req *http.Request) (rpcReqs []jsonRpcRequest, isBatch bool, err error) {
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, false, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &rpcReqs); err != nil {
+			return nil, true, err
+		}
+		return rpcReqs, true, nil
+	}
+	var rpcReq jsonRpcRequest
+	if err := json.Unmarshal(trimmed, &rpcReq); err != nil {
+		return nil, false, err
+	}
+	return []jsonRpcRequest{rpcReq}, false, nil
+}
@@ -0,0 +1,96 @@
+package json_rpc
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMethodPolicy(t *testing.T) {
+	r := require.New(t)
+
+	policy := newMethodPolicy(
+		config.JsonRpcMethodPolicy{Deny: []string{"eth_sendRawTransaction", "debug_*"}},
+		map[string]config.JsonRpcMethodPolicy{
+			"0xBOT": {Allow: []string{"eth_call", "debug_traceTransaction"}},
+		},
+	)
+
+	r.True(policy.IsAllowed("0xother", "eth_call"))
+	r.False(policy.IsAllowed("0xother", "eth_sendRawTransaction"))
+	r.False(policy.IsAllowed("0xother", "debug_traceTransaction"))
+
+	r.True(policy.IsAllowed("0xbot", "eth_call"))
+	r.True(policy.IsAllowed("0xbot", "debug_traceTransaction"))
+	r.False(policy.IsAllowed("0xbot", "eth_getBlockByNumber"))
+}
+
+func TestReadRequests(t *testing.T) {
+	r := require.New(t)
+
+	req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(
+		`[{"jsonrpc":"2.0","id":1,"method":"eth_call"},{"jsonrpc":"2.0","id":2,"method":"debug_traceCall"}]`,
+	))
+	rpcReqs, isBatch, err := readRequests(req)
+	r.NoError(err)
+	r.True(isBatch)
+	r.Len(rpcReqs, 2)
+	r.Equal("debug_traceCall", rpcReqs[1].Method)
+
+	// the body is still readable
+	rpcReqs, _, err = readRequests(req)
+	r.NoError(err)
+	r.Len(rpcReqs, 2)
+}
+
+func TestMethodCosts(t *testing.T) {
+	r := require.New(t)
+
+	costs := newMethodCosts(map[string]int{"trace_block": 20, "eth_*": 2})
+	r.Equal(20, costs.Cost("trace_block"))
+	r.Equal(10, costs.Cost("trace_transaction"))
+	r.Equal(5, costs.Cost("eth_getLogs"))
+	r.Equal(2, costs.Cost("eth_blockNumber"))
+	r.Equal(1, costs.Cost("net_version"))
+
+	r.Equal(22, costs.RequestsCost([]jsonRpcRequest{{Method: "trace_block"}, {Method: "eth_call"}}))
+	r.Equal(1, costs.RequestsCost(nil))
+}
+
+func TestBotRateLimit(t *testing.T) {
+	r := require.New(t)
+
+	defaultRateLimit := &config.RateLimitConfig{Rate: 50, Burst: 50}
+	botRateLimits := map[string]config.RateLimitConfig{"0xBOT": {Rate: 200, Burst: 200}}
+
+	// the manifest can lower the default rate limit but can not raise it
+	r.Equal(&config.RateLimitConfig{Rate: 10, Burst: 20}, botRateLimit(defaultRateLimit, botRateLimits, &config.AgentConfig{
+		ID: "0xother", JsonRpcRateLimit: &config.RateLimitConfig{Rate: 10, Burst: 20},
+	}))
+	r.Equal(defaultRateLimit, botRateLimit(defaultRateLimit, botRateLimits, &config.AgentConfig{
+		ID: "0xother", JsonRpcRateLimit: &config.RateLimitConfig{Rate: 1000, Burst: 1000},
+	}))
+	r.Nil(botRateLimit(defaultRateLimit, botRateLimits, &config.AgentConfig{ID: "0xother"}))
+
+	// the node config can raise it
+	r.Equal(&config.RateLimitConfig{Rate: 200, Burst: 200}, botRateLimit(defaultRateLimit, botRateLimits, &config.AgentConfig{
+		ID: "0xbot", JsonRpcRateLimit: &config.RateLimitConfig{Rate: 1000, Burst: 1000},
+	}))
+}
+
+func TestReadOnlyPolicy(t *testing.T) {
+	r := require.New(t)
+
+	policy := config.JsonRpcMethodPolicy{Allow: []string{"eth_*"}, Deny: []string{"eth_getLogs"}}
+	mp := newMethodPolicy(readOnlyPolicy(policy), nil)
+	r.True(mp.IsAllowed("0xbot", "eth_call"))
+	r.False(mp.IsAllowed("0xbot", "eth_getLogs"))
+	r.False(mp.IsAllowed("0xbot", "eth_sendRawTransaction"))
+	r.False(mp.IsAllowed("0xbot", "eth_signTypedData_v4"))
+
+	// the original policy is not changed
+	r.Equal([]string{"eth_getLogs"}, policy.Deny)
+}
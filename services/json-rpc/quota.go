@@ -0,0 +1,206 @@
+package json_rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	quotaEnforcementThrottle = "throttle"
+	quotaSaveInterval        = time.Second * 30
+)
+
+// usageCounter counts the usage in a time window which starts at the given unix time.
+type usageCounter struct {
+	Window       int64 `json:"window"`
+	Requests     int64 `json:"requests"`
+	ComputeUnits int64 `json:"computeUnits"`
+}
+
+// roll resets the counter if the window has changed.
+func (uc *usageCounter) roll(window int64) {
+	if uc.Window != window {
+		*uc = usageCounter{Window: window}
+	}
+}
+
+func (uc *usageCounter) exceeds(requests, computeUnits, maxRequests, maxComputeUnits int64) bool {
+	return (maxRequests > 0 && uc.Requests+requests > maxRequests) ||
+		(maxComputeUnits > 0 && uc.ComputeUnits+computeUnits > maxComputeUnits)
+}
+
+type botUsage struct {
+	Hourly usageCounter `json:"hourly"`
+	Daily  usageCounter `json:"daily"`
+}
+
+// quotaTracker counts the hourly and daily usage of the bots and persists the counters
+// so that the budgets survive the proxy restarts.
+type quotaTracker struct {
+	cfg        config.JsonRpcQuotaConfig
+	path       string
+	usage      map[string]*botUsage
+	throttlers map[string]*rate.Limiter
+	exceeded   map[string]bool
+	dirty      bool
+	now        func() time.Time
+	mu         sync.Mutex
+}
+
+func newQuotaTracker(fortaDir string, cfg config.JsonRpcQuotaConfig) (*quotaTracker, error) {
+	qt := &quotaTracker{
+		cfg:        cfg,
+		path:       resolveFortaPath(fortaDir, cfg.Path),
+		usage:      make(map[string]*botUsage),
+		throttlers: make(map[string]*rate.Limiter),
+		exceeded:   make(map[string]bool),
+		now:        time.Now,
+	}
+	if err := qt.load(); err != nil {
+		return nil, err
+	}
+	return qt, nil
+}
+
+func (qt *quotaTracker) load() error {
+	if len(qt.path) == 0 {
+		return nil
+	}
+	b, err := ioutil.ReadFile(qt.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the quota counters: %v", err)
+	}
+	if err := json.Unmarshal(b, &qt.usage); err != nil {
+		return fmt.Errorf("failed to decode the quota counters: %v", err)
+	}
+	return nil
+}
+
+// quota finds the quota of the bot.
+func (qt *quotaTracker) quota(botID string) config.JsonRpcQuota {
+	for id, quota := range qt.cfg.BotQuotas {
+		if strings.EqualFold(id, botID) {
+			return quota
+		}
+	}
+	return qt.cfg.Default
+}
+
+// Use counts the requests of the bot and tells if they are allowed. The requests over the quota
+// are not counted.
+func (qt *quotaTracker) Use(botID string, requests, computeUnits int) bool {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	now := qt.now().UTC()
+	usage, ok := qt.usage[botID]
+	if !ok {
+		usage = &botUsage{}
+		qt.usage[botID] = usage
+	}
+	usage.Hourly.roll(now.Truncate(time.Hour).Unix())
+	usage.Daily.roll(now.Truncate(time.Hour * 24).Unix())
+
+	quota := qt.quota(botID)
+	reqs, units := int64(requests), int64(computeUnits)
+	exceeds := usage.Hourly.exceeds(reqs, units, quota.HourlyRequests, quota.HourlyComputeUnits) ||
+		usage.Daily.exceeds(reqs, units, quota.DailyRequests, quota.DailyComputeUnits)
+	qt.exceeded[botID] = exceeds
+	if exceeds && !qt.allowThrottledUnsafe(botID) {
+		return false
+	}
+
+	usage.Hourly.Requests += reqs
+	usage.Hourly.ComputeUnits += units
+	usage.Daily.Requests += reqs
+	usage.Daily.ComputeUnits += units
+	qt.dirty = true
+	return true
+}
+
+func (qt *quotaTracker) allowThrottledUnsafe(botID string) bool {
+	if qt.cfg.Enforcement != quotaEnforcementThrottle {
+		return false
+	}
+	limiter, ok := qt.throttlers[botID]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(qt.cfg.ThrottleRate), 1)
+		qt.throttlers[botID] = limiter
+	}
+	return limiter.Allow()
+}
+
+// Save writes the counters to the file if they have changed.
+func (qt *quotaTracker) Save() error {
+	if len(qt.path) == 0 {
+		return nil
+	}
+	qt.mu.Lock()
+	if !qt.dirty {
+		qt.mu.Unlock()
+		return nil
+	}
+	b, err := json.Marshal(qt.usage)
+	qt.dirty = false
+	qt.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that a crash does not leave a corrupt file behind
+	tmpPath := qt.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0644); err != nil {
+		return fmt.Errorf("failed to write the quota counters: %v", err)
+	}
+	return os.Rename(tmpPath, qt.path)
+}
+
+// saveLoop saves the counters periodically.
+func (qt *quotaTracker) saveLoop(ctx context.Context) {
+	ticker := time.NewTicker(quotaSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := qt.Save(); err != nil {
+				log.WithError(err).Warn("failed to save the quota counters")
+			}
+		}
+	}
+}
+
+// Health returns the number of the bots which exceeded their quota on their last request.
+func (qt *quotaTracker) Health() health.Reports {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+	var count int
+	for _, exceeded := range qt.exceeded {
+		if exceeded {
+			count++
+		}
+	}
+	return health.Reports{
+		{
+			Name:    "quota.exceeded",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(count),
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package json_rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaTracker(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	cfg := config.JsonRpcQuotaConfig{
+		Enable:      true,
+		Default:     config.JsonRpcQuota{HourlyRequests: 2, DailyComputeUnits: 10},
+		BotQuotas:   map[string]config.JsonRpcQuota{"0xBot": {HourlyRequests: 1}},
+		Enforcement: "reject",
+		Path:        "quotas.json",
+	}
+	qt, err := newQuotaTracker(dir, cfg)
+	r.NoError(err)
+	now := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	qt.now = func() time.Time { return now }
+
+	r.True(qt.Use("0xother", 1, 1))
+	r.True(qt.Use("0xother", 1, 1))
+	r.False(qt.Use("0xother", 1, 1))
+
+	// the bot quota overrides the default
+	r.True(qt.Use("0xbot", 1, 1))
+	r.False(qt.Use("0xbot", 1, 1))
+
+	// the hourly budget is renewed but the daily budget is not
+	now = now.Add(time.Hour)
+	r.True(qt.Use("0xother", 1, 5))
+	r.False(qt.Use("0xother", 1, 5))
+
+	// the counters survive the restarts
+	r.NoError(qt.Save())
+	qt, err = newQuotaTracker(dir, cfg)
+	r.NoError(err)
+	qt.now = func() time.Time { return now }
+	r.True(qt.Use("0xother", 1, 3))
+	r.False(qt.Use("0xother", 1, 1))
+
+	// the next day starts with a fresh budget
+	now = now.Add(time.Hour * 24)
+	r.True(qt.Use("0xother", 1, 5))
+}
+
+func TestQuotaTracker_Throttle(t *testing.T) {
+	r := require.New(t)
+
+	qt, err := newQuotaTracker("", config.JsonRpcQuotaConfig{
+		Enable:       true,
+		Default:      config.JsonRpcQuota{DailyRequests: 1},
+		Enforcement:  "throttle",
+		ThrottleRate: 1,
+	})
+	r.NoError(err)
+
+	r.True(qt.Use("0xbot", 1, 1))
+	// the throttled bot can send a request at the throttle rate
+	r.True(qt.Use("0xbot", 1, 1))
+	r.False(qt.Use("0xbot", 1, 1))
+}
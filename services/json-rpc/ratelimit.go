@@ -0,0 +1,95 @@
+package json_rpc
+
+import (
+	"strings"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+// defaultMethodCosts weight the expensive methods higher than the rest which cost 1.
+var defaultMethodCosts = map[string]int{
+	"eth_getLogs": 5,
+	"trace_*":     10,
+	"debug_*":     10,
+}
+
+type methodCosts map[string]int
+
+func newMethodCosts(cfg map[string]int) methodCosts {
+	costs := make(methodCosts)
+	for method, cost := range defaultMethodCosts {
+		costs[method] = cost
+	}
+	for method, cost := range cfg {
+		costs[method] = cost
+	}
+	return costs
+}
+
+// Cost returns the cost of a method.
+func (costs methodCosts) Cost(method string) int {
+	if cost, ok := lookupMethodValue(costs, method); ok {
+		return cost
+	}
+	return 1
+}
+
+// lookupMethodValue finds the value for a method. An exact match is preferred over the longest matching prefix.
+func lookupMethodValue(values map[string]int, method string) (int, bool) {
+	if value, ok := values[method]; ok {
+		return value, true
+	}
+	var (
+		value      int
+		found      bool
+		longestLen int
+	)
+	for pattern, patternValue := range values {
+		if !strings.HasSuffix(pattern, "*") {
+			continue
+		}
+		prefix := strings.TrimSuffix(pattern, "*")
+		if strings.HasPrefix(method, prefix) && (!found || len(prefix) > longestLen) {
+			value = patternValue
+			found = true
+			longestLen = len(prefix)
+		}
+	}
+	return value, found
+}
+
+// RequestsCost returns the total cost of the requests.
+func (costs methodCosts) RequestsCost(rpcReqs []jsonRpcRequest) (total int) {
+	for _, rpcReq := range rpcReqs {
+		total += costs.Cost(rpcReq.Method)
+	}
+	if total < 1 {
+		total = 1
+	}
+	return
+}
+
+// botRateLimit finds the rate limit of the bot from the node config or the bot manifest. The bot
+// manifest can only lower the default rate limit and the node config can raise it.
+func botRateLimit(
+	defaultRateLimit *config.RateLimitConfig, botRateLimits map[string]config.RateLimitConfig, agentConfig *config.AgentConfig,
+) *config.RateLimitConfig {
+	for botID, rateLimit := range botRateLimits {
+		if strings.EqualFold(botID, agentConfig.ID) {
+			rateLimit := rateLimit
+			return &rateLimit
+		}
+	}
+	manifestRateLimit := agentConfig.JsonRpcRateLimit
+	if manifestRateLimit == nil || defaultRateLimit == nil {
+		return manifestRateLimit
+	}
+	rateLimit := *manifestRateLimit
+	if rateLimit.Rate > defaultRateLimit.Rate {
+		rateLimit.Rate = defaultRateLimit.Rate
+	}
+	if rateLimit.Burst > defaultRateLimit.Burst {
+		rateLimit.Burst = defaultRateLimit.Burst
+	}
+	return &rateLimit
+}
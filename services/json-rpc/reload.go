@@ -0,0 +1,60 @@
+package json_rpc
+
+import (
+	"fmt"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// handleRunningBots sets the rate limits of the latest running bots.
+func (p *JsonRpcProxy) handleRunningBots(payload messaging.AgentPayload) error {
+	p.reloadMu.Lock()
+	p.runningBots = payload
+	p.reloadMu.Unlock()
+	p.setBotRateLimits()
+	return nil
+}
+
+// setBotRateLimits sets the rate limits of the running bots on the rate limiter.
+func (p *JsonRpcProxy) setBotRateLimits() {
+	p.reloadMu.RLock()
+	defer p.reloadMu.RUnlock()
+	for i := range p.runningBots {
+		if rateLimit := botRateLimit(p.defaultRateLimit, p.botRateLimits, &p.runningBots[i]); rateLimit != nil {
+			p.rateLimiter.SetClientLimit(p.runningBots[i].ID, rateLimit.Rate, rateLimit.Burst)
+		}
+	}
+}
+
+// Reload applies the reloaded rate limits and, for the main proxy, the upstreams.
+func (p *JsonRpcProxy) Reload(cfg config.Config, report *config.ReloadReport) error {
+	if report.IsReloaded("jsonRpcProxy.rateLimit") || report.IsReloaded("jsonRpcProxy.botRateLimits") {
+		rateLimiting := proxyRateLimit(cfg)
+		p.reloadMu.Lock()
+		p.defaultRateLimit = rateLimiting
+		p.botRateLimits = cfg.JsonRpcProxy.BotRateLimits
+		p.reloadMu.Unlock()
+		p.rateLimiter.SetDefaultLimit(rateLimiting.Rate, rateLimiting.Burst)
+		// the bot limits are cleared with the default limit
+		p.setBotRateLimits()
+		log.WithField("proxy", p.name).Info("reloaded the rate limits")
+	}
+
+	// the instances are configured separately
+	if p.name != defaultProxyName || p.pool == nil {
+		return nil
+	}
+	if report.IsReloaded("jsonRpcProxy.jsonRpc") || report.IsReloaded("jsonRpcProxy.upstreams") {
+		_, upstreams := proxyUpstreams(cfg)
+		if err := p.pool.setUpstreams(p.fortaDir, upstreams); err != nil {
+			return fmt.Errorf("failed to reload the upstreams: %v", err)
+		}
+		log.WithFields(log.Fields{
+			"proxy":     p.name,
+			"upstreams": len(upstreams),
+		}).Info("reloaded the upstreams")
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package json_rpc
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_ratelimiter "github.com/forta-network/forta-node/clients/ratelimiter/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotRateLimits(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	rateLimiter := mock_ratelimiter.NewMockRateLimiter(ctrl)
+	p := &JsonRpcProxy{
+		name:             defaultProxyName,
+		rateLimiter:      rateLimiter,
+		defaultRateLimit: &config.RateLimitConfig{Rate: 10, Burst: 20},
+		botRateLimits:    map[string]config.RateLimitConfig{"0xBOT2": {Rate: 200, Burst: 200}},
+	}
+
+	// the limits are set when the running bots change
+	rateLimiter.EXPECT().SetClientLimit("0xbot1", float64(5), 5)
+	rateLimiter.EXPECT().SetClientLimit("0xbot2", float64(200), 200)
+	r.NoError(p.handleRunningBots(messaging.AgentPayload{
+		{ID: "0xbot1", JsonRpcRateLimit: &config.RateLimitConfig{Rate: 5, Burst: 5}},
+		{ID: "0xbot2"},
+		{ID: "0xbot3"},
+	}))
+
+	// the limits are set again when the config changes
+	var cfg config.Config
+	cfg.JsonRpcProxy.RateLimitConfig = &config.RateLimitConfig{Rate: 1, Burst: 2}
+	rateLimiter.EXPECT().SetDefaultLimit(float64(1), 2)
+	rateLimiter.EXPECT().SetClientLimit("0xbot1", float64(1), 2)
+	r.NoError(p.Reload(cfg, &config.ReloadReport{Reloaded: []string{"jsonRpcProxy.rateLimit"}}))
+}
@@ -0,0 +1,172 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultMethodMaxResponseBytes limits the methods which can return huge results with wide ranges.
+var defaultMethodMaxResponseBytes = map[string]int{
+	"eth_getLogs": 64 << 20,
+}
+
+// errResponseTooLarge cuts the responses which exceed the limit while they are streamed.
+var errResponseTooLarge = errors.New("response exceeds the size limit")
+
+// responseLimiter limits the size of the upstream responses without buffering them. The responses
+// which are known to be too large are replaced with an error and the others are cut when they exceed
+// the limit while they are streamed to the bots.
+type responseLimiter struct {
+	next           http.RoundTripper
+	maxBytes       int
+	methodMaxBytes map[string]int
+	exceeded       uint64
+}
+
+func newResponseLimiter(next http.RoundTripper, cfg config.JsonRpcProxyConfig) *responseLimiter {
+	methodMaxBytes := make(map[string]int)
+	for method, maxBytes := range defaultMethodMaxResponseBytes {
+		methodMaxBytes[method] = maxBytes
+	}
+	for method, maxBytes := range cfg.MethodMaxResponseBytes {
+		methodMaxBytes[method] = maxBytes
+	}
+	return &responseLimiter{
+		next:           next,
+		maxBytes:       cfg.MaxResponseBytes,
+		methodMaxBytes: methodMaxBytes,
+	}
+}
+
+// MaxBytes returns the response size limit for the requests. The method limits override the default
+// limit and a batch is limited by its most limited method. Zero means no limit.
+func (rl *responseLimiter) MaxBytes(rpcReqs []jsonRpcRequest) int {
+	if len(rpcReqs) == 0 {
+		return rl.maxBytes
+	}
+	var limit int
+	for _, rpcReq := range rpcReqs {
+		maxBytes, ok := lookupMethodValue(rl.methodMaxBytes, rpcReq.Method)
+		if !ok {
+			maxBytes = rl.maxBytes
+		}
+		if maxBytes > 0 && (limit == 0 || maxBytes < limit) {
+			limit = maxBytes
+		}
+	}
+	return limit
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rl *responseLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	// malformed requests are limited by the default limit
+	rpcReqs, _, _ := readRequests(req)
+	limit := rl.MaxBytes(rpcReqs)
+
+	resp, err := rl.next.RoundTrip(req)
+	if err != nil || limit == 0 {
+		return resp, err
+	}
+
+	logger := log.WithFields(log.Fields{
+		"methods": requestMethods(rpcReqs),
+		"limit":   limit,
+	})
+	if resp.ContentLength > int64(limit) {
+		resp.Body.Close()
+		atomic.AddUint64(&rl.exceeded, 1)
+		logger.WithField("size", resp.ContentLength).Warn("upstream response exceeds the size limit")
+		return responseTooLargeErr(req, rpcReqs, limit), nil
+	}
+
+	resp.Body = &limitedBody{
+		ReadCloser: resp.Body,
+		remaining:  int64(limit),
+		onExceed: func() {
+			atomic.AddUint64(&rl.exceeded, 1)
+			logger.Warn("cut the upstream response which exceeds the size limit")
+		},
+	}
+	return resp, nil
+}
+
+// Health returns the response limit health reports.
+func (rl *responseLimiter) Health() health.Reports {
+	return health.Reports{
+		{
+			Name:    "response-limit.exceeded",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&rl.exceeded), 10),
+		},
+	}
+}
+
+// limitedBody fails the reads after the limit so that the reverse proxy aborts the response.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	onExceed  func()
+}
+
+// Read implements io.Reader.
+func (lb *limitedBody) Read(b []byte) (int, error) {
+	if lb.remaining < 0 {
+		return 0, errResponseTooLarge
+	}
+	// read one byte more than the limit to tell if the response ends right at the limit
+	if int64(len(b)) > lb.remaining+1 {
+		b = b[:lb.remaining+1]
+	}
+	n, err := lb.ReadCloser.Read(b)
+	lb.remaining -= int64(n)
+	if lb.remaining < 0 {
+		lb.onExceed()
+		return 0, errResponseTooLarge
+	}
+	return n, err
+}
+
+// responseTooLargeErr responds with the id of the request. The batches get an error without an id.
+func responseTooLargeErr(req *http.Request, rpcReqs []jsonRpcRequest, limit int) *http.Response {
+	var id json.RawMessage
+	if len(rpcReqs) == 1 {
+		id = rpcReqs[0].ID
+	}
+	body, _ := json.Marshal(&jsonRpcResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error: &jsonRpcError{
+			Code:    -32006,
+			Message: fmt.Sprintf("response exceeds the scan node size limit of %d bytes", limit),
+		},
+	})
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge)),
+		StatusCode:    http.StatusRequestEntityTooLarge,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func requestMethods(rpcReqs []jsonRpcRequest) (methods []string) {
+	for _, rpcReq := range rpcReqs {
+		methods = append(methods, rpcReq.Method)
+	}
+	return
+}
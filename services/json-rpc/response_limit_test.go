@@ -0,0 +1,107 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func testLimitedResponse(body string, contentLength int64) roundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        make(http.Header),
+			Body:          ioutil.NopCloser(strings.NewReader(body)),
+			ContentLength: contentLength,
+			Request:       req,
+		}, nil
+	}
+}
+
+func testLimitRequest(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+}
+
+func TestResponseLimiter_MaxBytes(t *testing.T) {
+	r := require.New(t)
+
+	rl := newResponseLimiter(nil, config.JsonRpcProxyConfig{
+		MaxResponseBytes: 1000,
+		MethodMaxResponseBytes: map[string]int{
+			"trace_*":         100,
+			"eth_getBalance":  0,
+			"eth_getLogs":     500,
+			"debug_traceCall": 2000,
+		},
+	})
+
+	r.Equal(1000, rl.MaxBytes([]jsonRpcRequest{{Method: "eth_chainId"}}))
+	r.Equal(500, rl.MaxBytes([]jsonRpcRequest{{Method: "eth_getLogs"}}))
+	r.Equal(100, rl.MaxBytes([]jsonRpcRequest{{Method: "trace_block"}}))
+	r.Equal(0, rl.MaxBytes([]jsonRpcRequest{{Method: "eth_getBalance"}}))
+	r.Equal(2000, rl.MaxBytes([]jsonRpcRequest{{Method: "debug_traceCall"}}))
+	r.Equal(100, rl.MaxBytes([]jsonRpcRequest{{Method: "eth_getLogs"}, {Method: "trace_block"}}))
+	r.Equal(500, rl.MaxBytes([]jsonRpcRequest{{Method: "eth_getBalance"}, {Method: "eth_getLogs"}}))
+	r.Equal(1000, rl.MaxBytes(nil))
+
+	// eth_getLogs is limited by default
+	rl = newResponseLimiter(nil, config.JsonRpcProxyConfig{})
+	r.Equal(0, rl.MaxBytes([]jsonRpcRequest{{Method: "eth_chainId"}}))
+	r.Equal(defaultMethodMaxResponseBytes["eth_getLogs"], rl.MaxBytes([]jsonRpcRequest{{Method: "eth_getLogs"}}))
+}
+
+func TestResponseLimiter_ContentLength(t *testing.T) {
+	r := require.New(t)
+
+	body := `{"jsonrpc":"2.0","id":1,"result":"0x1234567890"}`
+	rl := newResponseLimiter(testLimitedResponse(body, int64(len(body))), config.JsonRpcProxyConfig{MaxResponseBytes: 10})
+
+	resp, err := rl.RoundTrip(testLimitRequest(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`))
+	r.NoError(err)
+	r.Equal(http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	var rpcResp jsonRpcResponse
+	r.NoError(json.NewDecoder(resp.Body).Decode(&rpcResp))
+	r.Equal("1", string(rpcResp.ID))
+	r.NotNil(rpcResp.Error)
+	r.Equal(-32006, rpcResp.Error.Code)
+	r.Equal("1", rl.Health()[0].Details)
+}
+
+func TestResponseLimiter_Streaming(t *testing.T) {
+	r := require.New(t)
+
+	body := `{"jsonrpc":"2.0","id":1,"result":"0x1234567890"}`
+
+	// the responses without a content length are cut after the limit
+	rl := newResponseLimiter(testLimitedResponse(body, -1), config.JsonRpcProxyConfig{MaxResponseBytes: 10})
+	resp, err := rl.RoundTrip(testLimitRequest(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`))
+	r.NoError(err)
+	r.Equal(http.StatusOK, resp.StatusCode)
+	b, err := ioutil.ReadAll(resp.Body)
+	r.ErrorIs(err, errResponseTooLarge)
+	r.LessOrEqual(len(b), 10)
+	r.Equal("1", rl.Health()[0].Details)
+
+	// the responses right at the limit are passed
+	rl = newResponseLimiter(testLimitedResponse(body, -1), config.JsonRpcProxyConfig{MaxResponseBytes: len(body)})
+	resp, err = rl.RoundTrip(testLimitRequest(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`))
+	r.NoError(err)
+	b, err = ioutil.ReadAll(resp.Body)
+	r.NoError(err)
+	r.Equal(body, string(b))
+	r.Equal("0", rl.Health()[0].Details)
+}
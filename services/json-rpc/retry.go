@@ -0,0 +1,130 @@
+package json_rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+const (
+	defaultInitialBackoff = time.Millisecond * 100
+	defaultMaxBackoff     = time.Second * 2
+)
+
+// defaultMethodMaxRetries disables retrying the methods which are not idempotent.
+var defaultMethodMaxRetries = map[string]int{
+	"eth_sendRawTransaction": 0,
+	"eth_sendTransaction":    0,
+}
+
+// upstreamStatusError is returned when an upstream responds with an error status.
+type upstreamStatusError struct {
+	StatusCode int
+}
+
+func (err *upstreamStatusError) Error() string {
+	return fmt.Sprintf("upstream responded with status code %d", err.StatusCode)
+}
+
+// isTransientErr tells if the request can succeed when retried.
+func isTransientErr(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *upstreamStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+	// connection errors and the upstreams without budget
+	return true
+}
+
+type retryPolicy struct {
+	maxRetries       int
+	methodMaxRetries map[string]int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+}
+
+func newRetryPolicy(cfg config.JsonRpcRetryConfig) *retryPolicy {
+	methodMaxRetries := make(map[string]int)
+	for method, retries := range defaultMethodMaxRetries {
+		methodMaxRetries[method] = retries
+	}
+	for method, retries := range cfg.MethodMaxRetries {
+		methodMaxRetries[method] = retries
+	}
+	rp := &retryPolicy{
+		maxRetries:       cfg.MaxRetries,
+		methodMaxRetries: methodMaxRetries,
+		initialBackoff:   time.Duration(cfg.InitialBackoffMs) * time.Millisecond,
+		maxBackoff:       time.Duration(cfg.MaxBackoffMs) * time.Millisecond,
+	}
+	if rp.initialBackoff <= 0 {
+		rp.initialBackoff = defaultInitialBackoff
+	}
+	if rp.maxBackoff <= 0 {
+		rp.maxBackoff = defaultMaxBackoff
+	}
+	return rp
+}
+
+// MaxRetries returns the max retries for the request body. A batch is retried as
+// many times as its least retryable method allows.
+func (rp *retryPolicy) MaxRetries(body []byte) int {
+	var methods []string
+	var rpcReqs []jsonRpcRequest
+	if err := json.Unmarshal(body, &rpcReqs); err == nil {
+		for _, rpcReq := range rpcReqs {
+			methods = append(methods, rpcReq.Method)
+		}
+	} else {
+		var rpcReq jsonRpcRequest
+		if err := json.Unmarshal(body, &rpcReq); err != nil {
+			return 0
+		}
+		methods = append(methods, rpcReq.Method)
+	}
+
+	maxRetries := rp.maxRetries
+	for _, method := range methods {
+		if retries, ok := lookupMethodValue(rp.methodMaxRetries, method); ok && retries < maxRetries {
+			maxRetries = retries
+		}
+	}
+	return maxRetries
+}
+
+// Backoff returns the wait duration before the given retry.
+func (rp *retryPolicy) Backoff(retry int) time.Duration {
+	backoff := rp.initialBackoff << (retry - 1)
+	if backoff <= 0 || backoff > rp.maxBackoff {
+		return rp.maxBackoff
+	}
+	return backoff
+}
+
+type retryCounterKey struct{}
+
+// withRetryCounter returns a context which collects the upstream retries of a request.
+func withRetryCounter(ctx context.Context) (context.Context, *int64) {
+	counter := new(int64)
+	return context.WithValue(ctx, retryCounterKey{}, counter), counter
+}
+
+func countRetry(ctx context.Context) {
+	if counter, ok := ctx.Value(retryCounterKey{}).(*int64); ok {
+		atomic.AddInt64(counter, 1)
+	}
+}
@@ -0,0 +1,302 @@
+package json_rpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	upstreamCallTimeout      = time.Second * 10
+	subscriptionBufferLength = 100
+)
+
+var errUpstreamClosed = errors.New("upstream websocket connection is closed")
+
+// upstreamSubscription is a single upstream subscription shared by many subscribers.
+type upstreamSubscription struct {
+	key         string
+	upstreamID  string
+	subscribers map[string]chan json.RawMessage
+}
+
+// subscriptionMux multiplexes the subscriptions of the bots onto single upstream subscriptions
+// which are created on one upstream websocket connection.
+type subscriptionMux struct {
+	url     string
+	headers http.Header
+
+	conn      *websocket.Conn
+	writeMu   sync.Mutex
+	nextReqID uint64
+	pending   map[uint64]chan *jsonRpcResponse
+
+	subs           map[string]*upstreamSubscription
+	subsByUpstream map[string]*upstreamSubscription
+	subscriberKeys map[string]string
+	mu             sync.Mutex
+}
+
+func newSubscriptionMux(wsUrl string, headers map[string]string) *subscriptionMux {
+	httpHeaders := make(http.Header)
+	for h, v := range headers {
+		httpHeaders.Set(h, v)
+	}
+	return &subscriptionMux{
+		url:            wsUrl,
+		headers:        httpHeaders,
+		pending:        make(map[uint64]chan *jsonRpcResponse),
+		subs:           make(map[string]*upstreamSubscription),
+		subsByUpstream: make(map[string]*upstreamSubscription),
+		subscriberKeys: make(map[string]string),
+	}
+}
+
+// toWebsocketUrl derives the websocket URL from the JSON-RPC URL.
+func toWebsocketUrl(jsonRpcUrl string) string {
+	switch {
+	case strings.HasPrefix(jsonRpcUrl, "https://"):
+		return "wss://" + strings.TrimPrefix(jsonRpcUrl, "https://")
+	case strings.HasPrefix(jsonRpcUrl, "http://"):
+		return "ws://" + strings.TrimPrefix(jsonRpcUrl, "http://")
+	default:
+		return jsonRpcUrl
+	}
+}
+
+func newSubscriberID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "0x" + hex.EncodeToString(b)
+}
+
+// Subscribe subscribes with the given eth_subscribe params and returns the subscription id
+// and the channel which receives the notification results. The channel is closed if the
+// upstream connection is lost.
+func (mux *subscriptionMux) Subscribe(ctx context.Context, params json.RawMessage) (string, <-chan json.RawMessage, error) {
+	key := string(params)
+	subscriberID := newSubscriberID()
+	ch := make(chan json.RawMessage, subscriptionBufferLength)
+
+	mux.mu.Lock()
+	if sub, ok := mux.subs[key]; ok {
+		sub.subscribers[subscriberID] = ch
+		mux.subscriberKeys[subscriberID] = key
+		mux.mu.Unlock()
+		return subscriberID, ch, nil
+	}
+	mux.mu.Unlock()
+
+	resp, err := mux.call(ctx, "eth_subscribe", params)
+	if err != nil {
+		return "", nil, err
+	}
+	if resp.Error != nil {
+		return "", nil, fmt.Errorf("upstream subscription failed: %s", resp.Error.Message)
+	}
+	var upstreamID string
+	if err := json.Unmarshal(resp.Result, &upstreamID); err != nil {
+		return "", nil, fmt.Errorf("invalid upstream subscription id: %v", err)
+	}
+
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	// somebody else may have subscribed with the same params in the meantime
+	if sub, ok := mux.subs[key]; ok {
+		go mux.unsubscribeUpstream(upstreamID)
+		sub.subscribers[subscriberID] = ch
+		mux.subscriberKeys[subscriberID] = key
+		return subscriberID, ch, nil
+	}
+	sub := &upstreamSubscription{
+		key:         key,
+		upstreamID:  upstreamID,
+		subscribers: map[string]chan json.RawMessage{subscriberID: ch},
+	}
+	mux.subs[key] = sub
+	mux.subsByUpstream[upstreamID] = sub
+	mux.subscriberKeys[subscriberID] = key
+	return subscriberID, ch, nil
+}
+
+// Unsubscribe removes the subscriber and cancels the upstream subscription if it was the last one.
+func (mux *subscriptionMux) Unsubscribe(subscriberID string) bool {
+	mux.mu.Lock()
+	key, ok := mux.subscriberKeys[subscriberID]
+	if !ok {
+		mux.mu.Unlock()
+		return false
+	}
+	delete(mux.subscriberKeys, subscriberID)
+	sub := mux.subs[key]
+	delete(sub.subscribers, subscriberID)
+	lastOne := len(sub.subscribers) == 0
+	if lastOne {
+		delete(mux.subs, key)
+		delete(mux.subsByUpstream, sub.upstreamID)
+	}
+	mux.mu.Unlock()
+
+	if lastOne {
+		mux.unsubscribeUpstream(sub.upstreamID)
+	}
+	return true
+}
+
+func (mux *subscriptionMux) unsubscribeUpstream(upstreamID string) {
+	params, _ := json.Marshal([]string{upstreamID})
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamCallTimeout)
+	defer cancel()
+	if _, err := mux.call(ctx, "eth_unsubscribe", params); err != nil {
+		log.WithError(err).Warn("failed to unsubscribe from the upstream")
+	}
+}
+
+// call sends a request on the upstream connection and waits for the response.
+func (mux *subscriptionMux) call(ctx context.Context, method string, params json.RawMessage) (*jsonRpcResponse, error) {
+	conn, err := mux.ensureConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id := atomic.AddUint64(&mux.nextReqID, 1)
+	respCh := make(chan *jsonRpcResponse, 1)
+	mux.mu.Lock()
+	mux.pending[id] = respCh
+	mux.mu.Unlock()
+	defer func() {
+		mux.mu.Lock()
+		delete(mux.pending, id)
+		mux.mu.Unlock()
+	}()
+
+	mux.writeMu.Lock()
+	err = conn.WriteJSON(&jsonRpcRequest{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(strconv.FormatUint(id, 10)),
+		Method:  method,
+		Params:  params,
+	})
+	mux.writeMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write to the upstream websocket: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, upstreamCallTimeout)
+	defer cancel()
+	select {
+	case resp, ok := <-respCh:
+		if !ok {
+			return nil, errUpstreamClosed
+		}
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (mux *subscriptionMux) ensureConn(ctx context.Context) (*websocket.Conn, error) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	if mux.conn != nil {
+		return mux.conn, nil
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, mux.url, mux.headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the upstream websocket: %v", err)
+	}
+	mux.conn = conn
+	go mux.readLoop(conn)
+	return conn, nil
+}
+
+// upstreamMessage is either a response or a subscription notification.
+type upstreamMessage struct {
+	jsonRpcResponse
+	Method string `json:"method"`
+	Params struct {
+		Subscription string          `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+func (mux *subscriptionMux) readLoop(conn *websocket.Conn) {
+	for {
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			log.WithError(err).Warn("upstream websocket connection is lost")
+			mux.closeConn(conn)
+			return
+		}
+		var msg upstreamMessage
+		if err := json.Unmarshal(b, &msg); err != nil {
+			continue
+		}
+		if msg.Method == "eth_subscription" {
+			mux.notify(msg.Params.Subscription, msg.Params.Result)
+			continue
+		}
+		id, err := strconv.ParseUint(string(msg.ID), 10, 64)
+		if err != nil {
+			continue
+		}
+		mux.mu.Lock()
+		respCh, ok := mux.pending[id]
+		mux.mu.Unlock()
+		if ok {
+			resp := msg.jsonRpcResponse
+			respCh <- &resp
+		}
+	}
+}
+
+func (mux *subscriptionMux) notify(upstreamID string, result json.RawMessage) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	sub, ok := mux.subsByUpstream[upstreamID]
+	if !ok {
+		return
+	}
+	for subscriberID, ch := range sub.subscribers {
+		select {
+		case ch <- result:
+		default:
+			log.WithField("subscription", subscriberID).Warn("subscriber is too slow - dropping notification")
+		}
+	}
+}
+
+// closeConn drops the connection and ends all subscriptions so the subscribers can reconnect.
+func (mux *subscriptionMux) closeConn(conn *websocket.Conn) {
+	conn.Close()
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	if mux.conn != conn {
+		return
+	}
+	mux.conn = nil
+	for _, sub := range mux.subs {
+		for _, ch := range sub.subscribers {
+			close(ch)
+		}
+	}
+	mux.subs = make(map[string]*upstreamSubscription)
+	mux.subsByUpstream = make(map[string]*upstreamSubscription)
+	mux.subscriberKeys = make(map[string]string)
+	for id, respCh := range mux.pending {
+		close(respCh)
+		delete(mux.pending, id)
+	}
+}
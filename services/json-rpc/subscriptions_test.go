@@ -0,0 +1,103 @@
+package json_rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+type testWsUpstream struct {
+	calls []string
+	conn  *websocket.Conn
+	mu    sync.Mutex
+}
+
+func (u *testWsUpstream) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	u.mu.Lock()
+	u.conn = conn
+	u.mu.Unlock()
+	for {
+		var rpcReq jsonRpcRequest
+		if err := conn.ReadJSON(&rpcReq); err != nil {
+			return
+		}
+		u.mu.Lock()
+		u.calls = append(u.calls, rpcReq.Method)
+		result := json.RawMessage(`true`)
+		if rpcReq.Method == "eth_subscribe" {
+			result = json.RawMessage(`"0xupstream"`)
+		}
+		conn.WriteJSON(&jsonRpcResponse{JSONRPC: "2.0", ID: rpcReq.ID, Result: result})
+		u.mu.Unlock()
+	}
+}
+
+func (u *testWsUpstream) notify(result string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.conn.WriteMessage(websocket.TextMessage, []byte(
+		`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xupstream","result":`+result+`}}`,
+	))
+}
+
+func (u *testWsUpstream) getCalls() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string{}, u.calls...)
+}
+
+func TestSubscriptionMux(t *testing.T) {
+	r := require.New(t)
+
+	upstream := &testWsUpstream{}
+	server := httptest.NewServer(upstream)
+	defer server.Close()
+
+	mux := newSubscriptionMux(toWebsocketUrl(server.URL), nil)
+	ctx := context.Background()
+	params := json.RawMessage(`["newHeads"]`)
+
+	subID1, ch1, err := mux.Subscribe(ctx, params)
+	r.NoError(err)
+	subID2, ch2, err := mux.Subscribe(ctx, params)
+	r.NoError(err)
+	r.NotEqual(subID1, subID2)
+	// both subscribers share the same upstream subscription
+	r.Equal([]string{"eth_subscribe"}, upstream.getCalls())
+
+	upstream.notify(`{"number":"0x1"}`)
+	for _, ch := range []<-chan json.RawMessage{ch1, ch2} {
+		select {
+		case result := <-ch:
+			r.Equal(`{"number":"0x1"}`, string(result))
+		case <-time.After(time.Second * 5):
+			r.FailNow("timed out waiting for the notification")
+		}
+	}
+
+	r.True(mux.Unsubscribe(subID1))
+	r.Equal([]string{"eth_subscribe"}, upstream.getCalls())
+	r.True(mux.Unsubscribe(subID2))
+	r.Equal([]string{"eth_subscribe", "eth_unsubscribe"}, upstream.getCalls())
+	r.False(mux.Unsubscribe(subID2))
+}
+
+func TestToWebsocketUrl(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("wss://example.com/rpc", toWebsocketUrl("https://example.com/rpc"))
+	r.Equal("ws://localhost:8545", toWebsocketUrl("http://localhost:8545"))
+	r.True(strings.HasPrefix(toWebsocketUrl("ws://host"), "ws://"))
+}
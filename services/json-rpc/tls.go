@@ -0,0 +1,51 @@
+package json_rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+// resolveFortaPath resolves the relative paths from the Forta directory.
+func resolveFortaPath(fortaDir, p string) string {
+	if len(p) == 0 || filepath.IsAbs(p) {
+		return p
+	}
+	return path.Join(fortaDir, p)
+}
+
+// newTLSClientConfig creates the TLS config with the custom CA bundle and the client certificate.
+func newTLSClientConfig(fortaDir string, cfg *config.TLSClientConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(cfg.CACertFile) > 0 {
+		caCert, err := ioutil.ReadFile(resolveFortaPath(fortaDir, cfg.CACertFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the ca cert file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("no valid ca certs found in the ca cert file")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(cfg.ClientCertFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(
+			resolveFortaPath(fortaDir, cfg.ClientCertFile),
+			resolveFortaPath(fortaDir, cfg.ClientKeyFile),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client cert: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
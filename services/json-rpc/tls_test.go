@@ -0,0 +1,53 @@
+package json_rpc
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveFortaPath(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("/.forta/certs/ca.pem", resolveFortaPath("/.forta", "certs/ca.pem"))
+	r.Equal("/etc/ssl/ca.pem", resolveFortaPath("/.forta", "/etc/ssl/ca.pem"))
+	r.Equal("", resolveFortaPath("/.forta", ""))
+}
+
+func TestUpstreamTransport_CustomCA(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fortaDir := t.TempDir()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	r.NoError(ioutil.WriteFile(path.Join(fortaDir, "ca.pem"), caPEM, os.ModePerm))
+
+	// the default transport does not trust the test server
+	_, err := (&http.Client{Transport: http.DefaultTransport}).Get(server.URL)
+	r.Error(err)
+
+	transport, err := newUpstreamTransport(fortaDir, config.JsonRpcConfig{
+		Url: server.URL,
+		TLS: &config.TLSClientConfig{CACertFile: "ca.pem"},
+	}, config.JsonRpcTransportConfig{})
+	r.NoError(err)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	r.NoError(err)
+	r.Equal(http.StatusOK, resp.StatusCode)
+
+	_, err = newUpstreamTransport(fortaDir, config.JsonRpcConfig{
+		TLS: &config.TLSClientConfig{CACertFile: "missing.pem"},
+	}, config.JsonRpcTransportConfig{})
+	r.Error(err)
+}
@@ -0,0 +1,56 @@
+package json_rpc
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"strings"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+const traceMethodPrefix = "trace_"
+
+// newTraceHandler proxies the requests to the trace API with the same retries and response limits.
+func newTraceHandler(fortaDir string, traceCfg config.JsonRpcConfig, proxyCfg config.JsonRpcProxyConfig) (http.Handler, error) {
+	pool, err := newUpstreamPool(
+		fortaDir, []config.JsonRpcUpstreamConfig{{JsonRpcConfig: traceCfg, Weight: 1}}, proxyCfg.Retry, proxyCfg.Transport,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &httputil.ReverseProxy{
+		Director:  func(r *http.Request) {},
+		Transport: newResponseLimiter(pool, proxyCfg),
+	}, nil
+}
+
+// traceRouter sends the trace calls of the bots which declare that they need traces to the trace API
+// so that they do not depend on the scan API supporting the trace methods.
+func (p *JsonRpcProxy) traceRouter(traceHandler, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if p.isBotTraceRequest(req) {
+			traceHandler.ServeHTTP(w, req)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// isBotTraceRequest tells if the request is from a bot which needs traces and calls only the trace methods.
+func (p *JsonRpcProxy) isBotTraceRequest(req *http.Request) bool {
+	// the bot is authenticated before the request is routed
+	agentConfig := botFromContext(req.Context())
+	if agentConfig == nil || !agentConfig.NeedsTraces() {
+		return false
+	}
+	rpcReqs, _, err := readRequests(req)
+	if err != nil || len(rpcReqs) == 0 {
+		return false
+	}
+	for _, rpcReq := range rpcReqs {
+		if !strings.HasPrefix(rpcReq.Method, traceMethodPrefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package json_rpc
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceRouter(t *testing.T) {
+	r := require.New(t)
+
+	p := &JsonRpcProxy{}
+
+	var routed string
+	handler := p.traceRouter(
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { routed = "trace" }),
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { routed = "scan" }),
+	)
+	serve := func(bot *config.AgentConfig, body string) string {
+		routed = ""
+		req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(body))
+		handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(withBot(req.Context(), bot)))
+		return routed
+	}
+
+	traceBot := &config.AgentConfig{ID: "0xbot", Capabilities: &config.BotCapabilities{Traces: true}}
+	otherBot := &config.AgentConfig{ID: "0xother"}
+
+	r.Equal("trace", serve(traceBot, `{"jsonrpc":"2.0","id":1,"method":"trace_block"}`))
+	r.Equal("trace", serve(traceBot, `[{"jsonrpc":"2.0","id":1,"method":"trace_block"},{"jsonrpc":"2.0","id":2,"method":"trace_transaction"}]`))
+	// the mixed batches are sent to the scan api
+	r.Equal("scan", serve(traceBot, `[{"jsonrpc":"2.0","id":1,"method":"trace_block"},{"jsonrpc":"2.0","id":2,"method":"eth_call"}]`))
+
+	r.Equal("scan", serve(otherBot, `{"jsonrpc":"2.0","id":1,"method":"trace_block"}`))
+	// the node services are not routed to the trace api
+	r.Equal("scan", serve(nil, `{"jsonrpc":"2.0","id":1,"method":"trace_block"}`))
+}
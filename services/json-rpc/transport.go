@@ -0,0 +1,78 @@
+package json_rpc
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+// newUpstreamTransport creates the tuned transport with the TLS config of the upstream if it has any.
+func newUpstreamTransport(
+	fortaDir string, cfg config.JsonRpcConfig, transportCfg config.JsonRpcTransportConfig,
+) (http.RoundTripper, error) {
+	tlsConfig := &tls.Config{}
+	if cfg.TLS != nil {
+		var err error
+		tlsConfig, err = newTLSClientConfig(fortaDir, cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if transportCfg.TLSSessionCacheSize > 0 {
+		tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(transportCfg.TLSSessionCacheSize)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     !transportCfg.DisableHTTP2,
+		MaxIdleConnsPerHost:   transportCfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       time.Duration(transportCfg.IdleConnTimeoutSeconds) * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if transportCfg.DisableHTTP2 {
+		// a non-nil empty map disables HTTP/2
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	if transportCfg.DNSRefreshSeconds == 0 {
+		return transport, nil
+	}
+	return &dnsRefreshingTransport{
+		Transport:   transport,
+		interval:    time.Duration(transportCfg.DNSRefreshSeconds) * time.Second,
+		lastRefresh: time.Now(),
+	}, nil
+}
+
+// dnsRefreshingTransport closes the idle connections periodically so that the new connections
+// resolve the upstream host again and follow its DNS changes.
+type dnsRefreshingTransport struct {
+	*http.Transport
+	interval    time.Duration
+	lastRefresh time.Time
+	mu          sync.Mutex
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *dnsRefreshingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	refresh := time.Since(t.lastRefresh) >= t.interval
+	if refresh {
+		t.lastRefresh = time.Now()
+	}
+	t.mu.Unlock()
+	if refresh {
+		t.CloseIdleConnections()
+	}
+	return t.Transport.RoundTrip(req)
+}
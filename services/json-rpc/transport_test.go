@@ -0,0 +1,120 @@
+package json_rpc
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTLSUpstream(t *testing.T, newConns *int32) (*httptest.Server, string) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.EnableHTTP2 = true
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(newConns, 1)
+		}
+	}
+	server.StartTLS()
+
+	fortaDir := t.TempDir()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, ioutil.WriteFile(path.Join(fortaDir, "ca.pem"), caPEM, os.ModePerm))
+	return server, fortaDir
+}
+
+func TestUpstreamTransport_Tuning(t *testing.T) {
+	r := require.New(t)
+
+	var newConns int32
+	server, fortaDir := newTestTLSUpstream(t, &newConns)
+	defer server.Close()
+	jsonRpcCfg := config.JsonRpcConfig{
+		Url: server.URL,
+		TLS: &config.TLSClientConfig{CACertFile: "ca.pem"},
+	}
+	transportCfg := config.JsonRpcTransportConfig{
+		MaxIdleConnsPerHost:    8,
+		IdleConnTimeoutSeconds: 90,
+		TLSSessionCacheSize:    8,
+	}
+
+	transport, err := newUpstreamTransport(fortaDir, jsonRpcCfg, transportCfg)
+	r.NoError(err)
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(server.URL)
+	r.NoError(err)
+	resp.Body.Close()
+	r.Equal(2, resp.ProtoMajor)
+	r.False(resp.TLS.DidResume)
+
+	// the next connection resumes the TLS session
+	transport.(*http.Transport).CloseIdleConnections()
+	resp, err = client.Get(server.URL)
+	r.NoError(err)
+	resp.Body.Close()
+	r.True(resp.TLS.DidResume)
+	r.Equal(int32(2), atomic.LoadInt32(&newConns))
+
+	transportCfg.DisableHTTP2 = true
+	transport, err = newUpstreamTransport(fortaDir, jsonRpcCfg, transportCfg)
+	r.NoError(err)
+	resp, err = (&http.Client{Transport: transport}).Get(server.URL)
+	r.NoError(err)
+	resp.Body.Close()
+	r.Equal(1, resp.ProtoMajor)
+
+	transportCfg.DNSRefreshSeconds = 300
+	transport, err = newUpstreamTransport(fortaDir, jsonRpcCfg, transportCfg)
+	r.NoError(err)
+	r.IsType(&dnsRefreshingTransport{}, transport)
+}
+
+func TestDNSRefreshingTransport(t *testing.T) {
+	r := require.New(t)
+
+	var newConns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&newConns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	transport := &dnsRefreshingTransport{
+		Transport:   &http.Transport{},
+		interval:    time.Hour,
+		lastRefresh: time.Now(),
+	}
+	client := &http.Client{Transport: transport}
+	get := func() {
+		resp, err := client.Get(server.URL)
+		r.NoError(err)
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
+	// the idle connection is reused until the refresh time
+	get()
+	get()
+	r.Equal(int32(1), atomic.LoadInt32(&newConns))
+
+	transport.lastRefresh = time.Now().Add(-time.Hour)
+	get()
+	r.Equal(int32(2), atomic.LoadInt32(&newConns))
+}
@@ -0,0 +1,431 @@
+package json_rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/faults"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultUpstreamProbeInterval = time.Second * 30
+	defaultMaxBlockLag           = 5
+	defaultMaxProbeLatency       = time.Second * 2
+
+	probeHeadRequest = `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["latest",false]}`
+)
+
+// errNoUpstream is returned when all upstreams are down or out of budget.
+var errNoUpstream = errors.New("no available upstream json-rpc api")
+
+type upstream struct {
+	cfg           config.JsonRpcConfig
+	url           *url.URL
+	transport     http.RoundTripper
+	weight        int
+	currentWeight int
+	limiter       *rate.Limiter
+	healthy       bool
+	lastErr       health.ErrorTracker
+
+	// the results of the last probe
+	latency time.Duration
+	head    *upstreamHead
+	lag     uint64
+}
+
+// upstreamHead is the latest block of an upstream.
+type upstreamHead struct {
+	Number    hexutil.Uint64 `json:"number"`
+	Timestamp hexutil.Uint64 `json:"timestamp"`
+}
+
+// upstreamPool balances the requests to the upstreams with smooth weighted round-robin
+// and fails over to the next upstream when one fails.
+type upstreamPool struct {
+	upstreams    []*upstream
+	retry        *retryPolicy
+	retries      uint64
+	transportCfg config.JsonRpcTransportConfig
+	mu           sync.Mutex
+}
+
+func newUpstreamPool(
+	fortaDir string, cfgs []config.JsonRpcUpstreamConfig,
+	retryCfg config.JsonRpcRetryConfig, transportCfg config.JsonRpcTransportConfig,
+) (*upstreamPool, error) {
+	upstreams, err := newUpstreams(fortaDir, cfgs, transportCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &upstreamPool{upstreams: upstreams, retry: newRetryPolicy(retryCfg), transportCfg: transportCfg}, nil
+}
+
+func newUpstreams(
+	fortaDir string, cfgs []config.JsonRpcUpstreamConfig, transportCfg config.JsonRpcTransportConfig,
+) ([]*upstream, error) {
+	if len(cfgs) == 0 {
+		return nil, errors.New("no upstreams configured")
+	}
+	var upstreams []*upstream
+	for _, cfg := range cfgs {
+		u, err := url.Parse(cfg.Url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upstream url: %v", err)
+		}
+		transport, err := newUpstreamTransport(fortaDir, cfg.JsonRpcConfig, transportCfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upstream tls config: %v", err)
+		}
+		transport = faults.Transport(faults.RPCRateLimit, transport)
+		weight := cfg.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		var limiter *rate.Limiter
+		if cfg.RateLimit != nil {
+			limiter = rate.NewLimiter(rate.Limit(cfg.RateLimit.Rate), cfg.RateLimit.Burst)
+		}
+		upstreams = append(upstreams, &upstream{
+			cfg:       cfg.JsonRpcConfig,
+			url:       u,
+			transport: transport,
+			weight:    weight,
+			limiter:   limiter,
+			healthy:   true,
+		})
+	}
+	return upstreams, nil
+}
+
+// setUpstreams replaces the upstreams. The requests in progress continue with the old ones.
+func (pool *upstreamPool) setUpstreams(fortaDir string, cfgs []config.JsonRpcUpstreamConfig) error {
+	upstreams, err := newUpstreams(fortaDir, cfgs, pool.transportCfg)
+	if err != nil {
+		return err
+	}
+	pool.mu.Lock()
+	pool.upstreams = upstreams
+	pool.mu.Unlock()
+	return nil
+}
+
+// list returns the current upstreams.
+func (pool *upstreamPool) list() []*upstream {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return pool.upstreams
+}
+
+// pick selects the next healthy upstream which has budget, skipping the excluded ones.
+func (pool *upstreamPool) pick(exclude map[*upstream]bool) *upstream {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	var (
+		best        *upstream
+		totalWeight int
+	)
+	for _, u := range pool.upstreams {
+		if exclude[u] || !u.healthy {
+			continue
+		}
+		u.currentWeight += u.weight
+		totalWeight += u.weight
+		if best == nil || u.currentWeight > best.currentWeight {
+			best = u
+		}
+	}
+	// fall back to the unhealthy ones if nothing else is left
+	if best == nil {
+		for _, u := range pool.upstreams {
+			if !exclude[u] {
+				return u
+			}
+		}
+		return nil
+	}
+	best.currentWeight -= totalWeight
+	return best
+}
+
+func (pool *upstreamPool) setHealthy(u *upstream, err error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	u.healthy = err == nil
+	u.lastErr.Set(err)
+}
+
+// RoundTrip implements http.RoundTripper and retries the request on the other upstreams on failure.
+// If all upstreams fail with a transient error, the request is retried with exponential backoff.
+func (pool *upstreamPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	maxRetries := pool.retry.MaxRetries(body)
+	for retry := 0; ; retry++ {
+		if retry > 0 {
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(pool.retry.Backoff(retry)):
+			}
+			atomic.AddUint64(&pool.retries, 1)
+			countRetry(req.Context())
+		}
+		resp, err := pool.roundTripOnce(req, body)
+		if err == nil {
+			return resp, nil
+		}
+		if retry >= maxRetries || !isTransientErr(err) {
+			// pass the last upstream response through so the bots can see the upstream error
+			if resp != nil {
+				return resp, nil
+			}
+			return nil, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		log.WithError(err).WithField("retry", retry+1).Debug("retrying the upstream request")
+	}
+}
+
+// roundTripOnce tries the upstreams one by one until one of them succeeds.
+// If none succeeds, the last upstream error response is returned together with the error.
+func (pool *upstreamPool) roundTripOnce(req *http.Request, body []byte) (*http.Response, error) {
+	tried := make(map[*upstream]bool)
+	lastErr := errNoUpstream
+	var lastResp *http.Response
+	for len(tried) < len(pool.list()) {
+		u := pool.pick(tried)
+		if u == nil {
+			break
+		}
+		tried[u] = true
+		if u.limiter != nil && !u.limiter.Allow() {
+			lastErr = fmt.Errorf("upstream '%s' is out of budget", u.url.Host)
+			continue
+		}
+
+		resp, err := u.transport.RoundTrip(u.newRequest(req, body))
+		if err == nil && resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+			if lastResp != nil {
+				lastResp.Body.Close()
+			}
+			return resp, nil
+		}
+		if err == nil {
+			err = &upstreamStatusError{StatusCode: resp.StatusCode}
+			if lastResp != nil {
+				lastResp.Body.Close()
+			}
+			lastResp = resp
+		}
+		log.WithError(err).WithField("upstream", u.url.Host).Warn("upstream request failed - trying the next one")
+		pool.setHealthy(u, err)
+		lastErr = err
+	}
+	return lastResp, lastErr
+}
+
+// newRequest creates the request to the upstream with the upstream url and headers.
+func (u *upstream) newRequest(req *http.Request, body []byte) *http.Request {
+	outReq := req.Clone(req.Context())
+	outReq.URL = u.url
+	outReq.Host = u.url.Host
+	for h, v := range u.cfg.Headers {
+		outReq.Header.Set(h, v)
+	}
+	outReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	outReq.ContentLength = int64(len(body))
+	return outReq
+}
+
+// probeHead gets the latest block from the upstream by sending the request
+// the same way as the proxied requests.
+func (u *upstream) probeHead(ctx context.Context) (*upstreamHead, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := u.transport.RoundTrip(u.newRequest(req, []byte(probeHeadRequest)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &upstreamStatusError{StatusCode: resp.StatusCode}
+	}
+
+	var rpcResp jsonRpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return nil, fmt.Errorf("failed to decode the latest block response: %v", err)
+	}
+	if rpcResp.Error != nil {
+		return nil, fmt.Errorf("upstream returned error: %s", rpcResp.Error.Message)
+	}
+	var head *upstreamHead
+	if err := json.Unmarshal(rpcResp.Result, &head); err != nil {
+		return nil, fmt.Errorf("failed to decode the latest block: %v", err)
+	}
+	if head == nil {
+		return nil, errors.New("upstream returned no latest block")
+	}
+	return head, nil
+}
+
+// probe checks all upstreams by measuring the latency and the head block lag. An upstream is
+// marked unhealthy if it fails or falls behind the best upstream by more than the max lag.
+// It returns an error only if all upstreams fail.
+func (pool *upstreamPool) probe(ctx context.Context, cfg config.JsonRpcHealthCheckConfig) error {
+	upstreams := pool.list()
+	heads := make([]*upstreamHead, len(upstreams))
+	latencies := make([]time.Duration, len(upstreams))
+	errs := make([]error, len(upstreams))
+	var best uint64
+	for i, u := range upstreams {
+		t := time.Now()
+		heads[i], errs[i] = u.probeHead(ctx)
+		latencies[i] = time.Since(t)
+		if errs[i] == nil && uint64(heads[i].Number) > best {
+			best = uint64(heads[i].Number)
+		}
+	}
+
+	maxLag := cfg.MaxBlockLag
+	if maxLag == 0 {
+		maxLag = defaultMaxBlockLag
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	var lastErr error
+	for i, u := range upstreams {
+		u.latency = latencies[i]
+		err := errs[i]
+		if err == nil {
+			u.head = heads[i]
+			u.lag = best - uint64(heads[i].Number)
+			if u.lag > maxLag {
+				err = fmt.Errorf("upstream is %d blocks behind", u.lag)
+			}
+		}
+		u.healthy = err == nil
+		u.lastErr.Set(err)
+		if errs[i] != nil {
+			lastErr = errs[i]
+		}
+	}
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// Health returns the upstream health reports.
+func (pool *upstreamPool) Health() (reports health.Reports) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	for i, u := range pool.upstreams {
+		reports = append(reports, u.lastErr.GetReport(fmt.Sprintf("upstream.%d", i)))
+	}
+	return
+}
+
+// ProbeReports returns the latency and the head block lag of the upstreams from the last probe.
+func (pool *upstreamPool) ProbeReports(cfg config.JsonRpcHealthCheckConfig) (reports health.Reports) {
+	maxLatency := time.Duration(cfg.MaxLatencyMs) * time.Millisecond
+	if maxLatency == 0 {
+		maxLatency = defaultMaxProbeLatency
+	}
+	maxLag := cfg.MaxBlockLag
+	if maxLag == 0 {
+		maxLag = defaultMaxBlockLag
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	for i, u := range pool.upstreams {
+		if u.head == nil {
+			continue
+		}
+		latencyStatus := health.StatusOK
+		if u.latency > maxLatency {
+			latencyStatus = health.StatusLagging
+		}
+		lagStatus := health.StatusOK
+		if u.lag > maxLag {
+			lagStatus = health.StatusLagging
+		}
+		headAge := time.Since(time.Unix(int64(u.head.Timestamp), 0)).Truncate(time.Second)
+		reports = append(reports,
+			&health.Report{
+				Name:    fmt.Sprintf("probe.%d.latency", i),
+				Status:  latencyStatus,
+				Details: u.latency.String(),
+			},
+			&health.Report{
+				Name:    fmt.Sprintf("probe.%d.lag", i),
+				Status:  lagStatus,
+				Details: strconv.FormatUint(u.lag, 10),
+			},
+			&health.Report{
+				Name:    fmt.Sprintf("probe.%d.head-age", i),
+				Status:  health.StatusInfo,
+				Details: headAge.String(),
+			},
+		)
+	}
+	return
+}
+
+// ProbeMetrics returns the system metrics from the last probe.
+func (pool *upstreamPool) ProbeMetrics() (ms []*protocol.AgentMetric) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	now := time.Now()
+	for _, u := range pool.upstreams {
+		if u.head == nil {
+			continue
+		}
+		ms = append(ms, metrics.GetJSONRPCUpstreamMetrics(u.url.Host, now, u.latency, u.lag)...)
+	}
+	return
+}
+
+// RetriesReport returns the total number of the upstream retries.
+func (pool *upstreamPool) RetriesReport() *health.Report {
+	return &health.Report{
+		Name:    "retries",
+		Status:  health.StatusInfo,
+		Details: strconv.FormatUint(atomic.LoadUint64(&pool.retries), 10),
+	}
+}
@@ -0,0 +1,221 @@
+package json_rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUpstream(status int, calls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.WriteHeader(status)
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	}))
+}
+
+func TestUpstreamPool_Failover(t *testing.T) {
+	r := require.New(t)
+
+	var failingCalls, workingCalls int
+	failing := newTestUpstream(http.StatusBadGateway, &failingCalls)
+	defer failing.Close()
+	working := newTestUpstream(http.StatusOK, &workingCalls)
+	defer working.Close()
+
+	pool, err := newUpstreamPool("", []config.JsonRpcUpstreamConfig{
+		{JsonRpcConfig: config.JsonRpcConfig{Url: failing.URL}, Weight: 1},
+		{JsonRpcConfig: config.JsonRpcConfig{Url: working.URL}, Weight: 1},
+	}, config.JsonRpcRetryConfig{}, config.JsonRpcTransportConfig{})
+	r.NoError(err)
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(`{"id":1}`))
+		resp, err := pool.RoundTrip(req)
+		r.NoError(err)
+		body, _ := ioutil.ReadAll(resp.Body)
+		r.Equal(`{"id":1}`, string(body))
+	}
+	// the failing upstream is marked unhealthy after the first failure
+	r.Equal(1, failingCalls)
+	r.Equal(3, workingCalls)
+}
+
+func TestUpstreamPool_Weights(t *testing.T) {
+	r := require.New(t)
+
+	pool, err := newUpstreamPool("", []config.JsonRpcUpstreamConfig{
+		{JsonRpcConfig: config.JsonRpcConfig{Url: "http://upstream1"}, Weight: 3},
+		{JsonRpcConfig: config.JsonRpcConfig{Url: "http://upstream2"}, Weight: 1},
+	}, config.JsonRpcRetryConfig{}, config.JsonRpcTransportConfig{})
+	r.NoError(err)
+
+	picks := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		picks[pool.pick(nil).url.Host]++
+	}
+	r.Equal(6, picks["upstream1"])
+	r.Equal(2, picks["upstream2"])
+}
+
+func TestUpstreamPool_Retry(t *testing.T) {
+	r := require.New(t)
+
+	var calls int
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	pool, err := newUpstreamPool("", []config.JsonRpcUpstreamConfig{
+		{JsonRpcConfig: config.JsonRpcConfig{Url: upstream.URL}, Weight: 1},
+	}, config.JsonRpcRetryConfig{MaxRetries: 2, InitialBackoffMs: 1, MaxBackoffMs: 5}, config.JsonRpcTransportConfig{})
+	r.NoError(err)
+
+	ctx, retries := withRetryCounter(context.Background())
+	req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(`{"id":1,"method":"eth_call"}`)).WithContext(ctx)
+	resp, err := pool.RoundTrip(req)
+	r.NoError(err)
+	r.Equal(http.StatusOK, resp.StatusCode)
+	r.Equal(3, calls)
+	r.Equal(int64(2), *retries)
+
+	// transactions are not retried and the upstream response is passed through
+	calls = 0
+	req = httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(`{"id":1,"method":"eth_sendRawTransaction"}`))
+	resp, err = pool.RoundTrip(req)
+	r.NoError(err)
+	r.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+	r.Equal(1, calls)
+}
+
+func TestUpstreamPool_AllFailing(t *testing.T) {
+	r := require.New(t)
+
+	var calls1, calls2 int
+	upstream1 := newTestUpstream(http.StatusBadGateway, &calls1)
+	defer upstream1.Close()
+	upstream2 := newTestUpstream(http.StatusTooManyRequests, &calls2)
+	defer upstream2.Close()
+
+	pool, err := newUpstreamPool("", []config.JsonRpcUpstreamConfig{
+		{JsonRpcConfig: config.JsonRpcConfig{Url: upstream1.URL}, Weight: 1},
+		{JsonRpcConfig: config.JsonRpcConfig{Url: upstream2.URL}, Weight: 1},
+	}, config.JsonRpcRetryConfig{}, config.JsonRpcTransportConfig{})
+	r.NoError(err)
+
+	req := httptest.NewRequest("POST", "http://localhost:8545", bytes.NewBufferString(`{"id":1}`))
+	resp, err := pool.RoundTrip(req)
+	r.NoError(err)
+	r.Equal(1, calls1)
+	r.Equal(1, calls2)
+	// the last upstream response is passed through
+	r.Equal(http.StatusTooManyRequests, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	r.Equal(`{"id":1}`, string(body))
+}
+
+func newTestHeadUpstream(blockNumber string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rpcReq jsonRpcRequest
+		json.NewDecoder(r.Body).Decode(&rpcReq)
+		json.NewEncoder(w).Encode(&jsonRpcResponse{
+			JSONRPC: "2.0",
+			ID:      rpcReq.ID,
+			Result:  json.RawMessage(`{"number":"` + blockNumber + `","timestamp":"0x64000000"}`),
+		})
+	}))
+}
+
+func TestUpstreamPool_Probe(t *testing.T) {
+	r := require.New(t)
+
+	upToDate := newTestHeadUpstream("0x10")
+	defer upToDate.Close()
+	behind := newTestHeadUpstream("0x8")
+	defer behind.Close()
+
+	pool, err := newUpstreamPool("", []config.JsonRpcUpstreamConfig{
+		{JsonRpcConfig: config.JsonRpcConfig{Url: upToDate.URL}, Weight: 1},
+		{JsonRpcConfig: config.JsonRpcConfig{Url: behind.URL}, Weight: 1},
+	}, config.JsonRpcRetryConfig{}, config.JsonRpcTransportConfig{})
+	r.NoError(err)
+
+	healthCheckCfg := config.JsonRpcHealthCheckConfig{MaxBlockLag: 5, MaxLatencyMs: 10000}
+	r.NoError(pool.probe(context.Background(), healthCheckCfg))
+	r.True(pool.upstreams[0].healthy)
+	r.False(pool.upstreams[1].healthy)
+
+	reports := pool.ProbeReports(healthCheckCfg)
+	lag, ok := reports.NameContains("probe.1.lag")
+	r.True(ok)
+	r.Equal("8", lag.Details)
+	r.Equal(health.StatusLagging, lag.Status)
+	lag, ok = reports.NameContains("probe.0.lag")
+	r.True(ok)
+	r.Equal(health.StatusOK, lag.Status)
+	r.Len(pool.ProbeMetrics(), 4)
+}
+
+func TestUpstreamPool_ProbeHeaders(t *testing.T) {
+	r := require.New(t)
+
+	headUpstream := newTestHeadUpstream("0x10")
+	defer headUpstream.Close()
+	// the upstream requires the api key from the config
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("x-api-key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		headUpstream.Config.Handler.ServeHTTP(w, req)
+	}))
+	defer upstream.Close()
+
+	pool, err := newUpstreamPool("", []config.JsonRpcUpstreamConfig{
+		{JsonRpcConfig: config.JsonRpcConfig{Url: upstream.URL, Headers: map[string]string{"x-api-key": "secret"}}, Weight: 1},
+		{JsonRpcConfig: config.JsonRpcConfig{Url: upstream.URL}, Weight: 1},
+	}, config.JsonRpcRetryConfig{}, config.JsonRpcTransportConfig{})
+	r.NoError(err)
+
+	r.NoError(pool.probe(context.Background(), config.JsonRpcHealthCheckConfig{}))
+	r.True(pool.upstreams[0].healthy)
+	r.False(pool.upstreams[1].healthy)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	r := require.New(t)
+
+	rp := newRetryPolicy(config.JsonRpcRetryConfig{
+		MaxRetries:       3,
+		MethodMaxRetries: map[string]int{"trace_*": 1},
+		InitialBackoffMs: 100,
+		MaxBackoffMs:     300,
+	})
+	r.Equal(3, rp.MaxRetries([]byte(`{"method":"eth_call"}`)))
+	r.Equal(1, rp.MaxRetries([]byte(`{"method":"trace_block"}`)))
+	r.Equal(0, rp.MaxRetries([]byte(`[{"method":"eth_call"},{"method":"eth_sendRawTransaction"}]`)))
+
+	r.Equal(100*time.Millisecond, rp.Backoff(1))
+	r.Equal(200*time.Millisecond, rp.Backoff(2))
+	r.Equal(300*time.Millisecond, rp.Backoff(3))
+
+	r.True(isTransientErr(&upstreamStatusError{StatusCode: http.StatusBadGateway}))
+	r.False(isTransientErr(&upstreamStatusError{StatusCode: http.StatusInternalServerError}))
+	r.False(isTransientErr(context.Canceled))
+}
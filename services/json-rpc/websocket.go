@@ -0,0 +1,222 @@
+package json_rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultMaxSubscriptions = 10
+
+var upgrader = websocket.Upgrader{
+	// the proxy is only reachable from the bot containers
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+type subscriptionNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  struct {
+		Subscription string          `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+// wsConn serializes the writes to a client websocket connection.
+type wsConn struct {
+	*websocket.Conn
+	mu sync.Mutex
+}
+
+func (conn *wsConn) write(v interface{}) error {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	return conn.WriteJSON(v)
+}
+
+func (conn *wsConn) writeRaw(b []byte) error {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, b)
+}
+
+func (conn *wsConn) writeErr(id json.RawMessage, code int, message string) error {
+	return conn.write(&jsonRpcResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   &jsonRpcError{Code: code, Message: message},
+	})
+}
+
+// websocketHandler upgrades the websocket requests and passes the rest to the given handler.
+func (p *JsonRpcProxy) websocketHandler(httpHandler, rpcHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !websocket.IsWebSocketUpgrade(req) {
+			httpHandler.ServeHTTP(w, req)
+			return
+		}
+		p.serveWebsocket(w, req, rpcHandler)
+	})
+}
+
+// serveWebsocket serves subscriptions from the subscription mux and the rest of
+// the requests by passing them to the JSON-RPC handler.
+func (p *JsonRpcProxy) serveWebsocket(w http.ResponseWriter, req *http.Request, rpcHandler http.Handler) {
+	agentConfig, err := p.botAuthenticator.FindAgentFromRequest(req)
+	if err != nil {
+		// the node services use the proxy without a bot token and are not metered
+		if !p.isNodeServiceRequest(req) {
+			log.WithError(err).Warn("failed to authenticate the bot websocket request")
+			writeAuthErr(w, nil)
+			return
+		}
+	}
+	// the forwarded requests are routed by the bot
+	ctx := withBot(req.Context(), agentConfig)
+
+	c, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		log.WithError(err).Warn("failed to upgrade to websocket")
+		return
+	}
+	conn := &wsConn{Conn: c}
+	defer conn.Close()
+
+	maxSubscriptions := p.proxyCfg.MaxSubscriptions
+	if maxSubscriptions <= 0 {
+		maxSubscriptions = defaultMaxSubscriptions
+	}
+	subscriptions := make(map[string]chan struct{})
+	defer func() {
+		for subscriberID, done := range subscriptions {
+			close(done)
+			p.subscriptions.Unsubscribe(subscriberID)
+		}
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		t := time.Now()
+
+		var rpcReq jsonRpcRequest
+		if err := json.Unmarshal(msg, &rpcReq); err != nil {
+			conn.writeErr(nil, -32700, "parse error")
+			continue
+		}
+
+		if agentConfig != nil {
+			if p.rateLimiter.ExceedsLimitN(agentConfig.ID, p.methodCosts.Cost(rpcReq.Method)) {
+				conn.writeErr(rpcReq.ID, -32000, "agent exceeds scan node request limit")
+				p.publishWebsocketMetrics(agentConfig, t, rpcReq.Method, metrics.MetricJSONRPCThrottled,
+					metrics.GetJSONRPCMetrics(*agentConfig, t, 0, 1, 0))
+				continue
+			}
+			if p.methodPolicy != nil && !p.methodPolicy.IsAllowed(agentConfig.ID, rpcReq.Method) {
+				conn.writeErr(rpcReq.ID, -32004, "method '"+rpcReq.Method+"' is not allowed by the scan node policy")
+				metrics.SendAgentMetrics(p.msgClient, []*protocol.AgentMetric{
+					metrics.CreateAgentMetric(agentConfig.ID, metrics.MetricJSONRPCDenied, 1),
+				})
+				continue
+			}
+			if p.quotas != nil && !p.quotas.Use(agentConfig.ID, 1, p.methodCosts.Cost(rpcReq.Method)) {
+				conn.writeErr(rpcReq.ID, -32005, "agent exceeds its scan node usage quota")
+				p.publishWebsocketMetrics(agentConfig, t, rpcReq.Method, metrics.MetricJSONRPCQuotaExceeded, nil)
+				continue
+			}
+		}
+
+		switch rpcReq.Method {
+		case "eth_subscribe":
+			if len(subscriptions) >= maxSubscriptions {
+				conn.writeErr(rpcReq.ID, -32005, "too many subscriptions")
+				continue
+			}
+			subscriberID, ch, err := p.subscriptions.Subscribe(req.Context(), rpcReq.Params)
+			if err != nil {
+				log.WithError(err).Warn("failed to subscribe")
+				conn.writeErr(rpcReq.ID, -32603, "failed to subscribe")
+				continue
+			}
+			done := make(chan struct{})
+			subscriptions[subscriberID] = done
+			result, _ := json.Marshal(subscriberID)
+			conn.write(&jsonRpcResponse{JSONRPC: "2.0", ID: rpcReq.ID, Result: result})
+			go forwardNotifications(conn, subscriberID, ch, done)
+
+		case "eth_unsubscribe":
+			var subscriberIDs []string
+			if err := json.Unmarshal(rpcReq.Params, &subscriberIDs); err != nil || len(subscriberIDs) == 0 {
+				conn.writeErr(rpcReq.ID, -32602, "invalid params")
+				continue
+			}
+			done, ok := subscriptions[subscriberIDs[0]]
+			if ok {
+				close(done)
+				delete(subscriptions, subscriberIDs[0])
+				p.subscriptions.Unsubscribe(subscriberIDs[0])
+			}
+			result, _ := json.Marshal(ok)
+			conn.write(&jsonRpcResponse{JSONRPC: "2.0", ID: rpcReq.ID, Result: result})
+
+		default:
+			rpcHttpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", bytes.NewReader(msg))
+			if err != nil {
+				conn.writeErr(rpcReq.ID, -32603, "internal error")
+				continue
+			}
+			rpcHttpReq.Header.Set("Content-Type", "application/json")
+			rec := newBufferedResponseWriter()
+			rpcHandler.ServeHTTP(rec, rpcHttpReq)
+			conn.writeRaw(bytes.TrimSpace(rec.body.Bytes()))
+		}
+
+		if agentConfig != nil {
+			p.publishWebsocketMetrics(agentConfig, t, rpcReq.Method, metrics.MetricJSONRPCRequest,
+				metrics.GetJSONRPCMetrics(*agentConfig, t, 1, 0, time.Since(t)))
+		}
+	}
+}
+
+func (p *JsonRpcProxy) publishWebsocketMetrics(agentConfig *config.AgentConfig, t time.Time, method, methodMetric string, ms []*protocol.AgentMetric) {
+	p.msgClient.PublishProto(
+		messaging.SubjectMetricAgent, &protocol.AgentMetricList{
+			Metrics: append(ms, metrics.GetJSONRPCMethodMetrics(*agentConfig, t, methodMetric, []string{method})...),
+		},
+	)
+}
+
+// forwardNotifications writes the notifications to the client until the subscription is done.
+// The client connection is closed if the upstream subscription ends so the client can reconnect.
+func forwardNotifications(conn *wsConn, subscriberID string, ch <-chan json.RawMessage, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case result, ok := <-ch:
+			if !ok {
+				conn.Close()
+				return
+			}
+			var notification subscriptionNotification
+			notification.JSONRPC = "2.0"
+			notification.Method = "eth_subscription"
+			notification.Params.Subscription = subscriberID
+			notification.Params.Result = result
+			if err := conn.write(&notification); err != nil {
+				return
+			}
+		}
+	}
+}
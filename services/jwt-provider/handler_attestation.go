@@ -0,0 +1,183 @@
+package jwt_provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
+	"github.com/sirupsen/logrus"
+)
+
+type AttestationMessage struct {
+	// Nonce is attested together with the node identity so that the bots can bind the attestation
+	// to a finding or to a challenge.
+	Nonce string `json:"nonce"`
+}
+
+type AttestationResponse struct {
+	Attestation string               `json:"attestation"`
+	Statement   AttestationStatement `json:"statement"`
+}
+
+// AttestationStatement is what the node attests to. The attestation is a scanner JWT with the
+// same values in its claims.
+type AttestationStatement struct {
+	ScannerAddress string `json:"scannerAddress"`
+	ChainID        int    `json:"chainId"`
+	BlockNumber    uint64 `json:"blockNumber"`
+	BotID          string `json:"botId"`
+	Nonce          string `json:"nonce,omitempty"`
+	// IssuedAt and ExpiresAt are unix timestamps.
+	IssuedAt  int64 `json:"issuedAt"`
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+const (
+	maxAttestationNonceLength = 256
+	attestationBlockCacheTTL  = time.Second * 3
+	attestationBlockTimeout   = time.Second * 5
+
+	errAttestationsDisabled      = "attestations are disabled"
+	errBadAttestationMessage     = "bad attestation message body"
+	errAttestationRateLimited    = "too many attestation requests"
+	errFailedToGetLatestBlock    = "can't get the latest block number"
+	errFailedToCreateAttestation = "can't create attestation"
+)
+
+var defaultAttestationRateLimit = config.RateLimitConfig{Rate: 1, Burst: 5}
+
+// EthClient gets the latest block number for the attestations.
+type EthClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// latestBlock reuses the latest block number for a short time so that the bots do not multiply
+// the requests to the scan API.
+type latestBlock struct {
+	client    EthClient
+	number    uint64
+	fetchedAt time.Time
+	mu        sync.Mutex
+}
+
+func (lb *latestBlock) get(ctx context.Context) (uint64, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	if time.Since(lb.fetchedAt) < attestationBlockCacheTTL {
+		return lb.number, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, attestationBlockTimeout)
+	defer cancel()
+	number, err := lb.client.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	lb.number = number
+	lb.fetchedAt = time.Now()
+	return number, nil
+}
+
+// attestationHandler returns a signed statement of the scanner address, the chain ID and the
+// latest block number for the requesting bot.
+func (j *JWTProvider) attestationHandler(w http.ResponseWriter, req *http.Request) {
+	attestationCfg := j.cfg.Config.BotAttestation
+	if attestationCfg.Disable {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, errAttestationsDisabled)
+		return
+	}
+
+	// the message is optional
+	var msg AttestationMessage
+	if err := json.NewDecoder(req.Body).Decode(&msg); err != nil && !errors.Is(err, io.EOF) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, errBadAttestationMessage)
+		return
+	}
+	if len(msg.Nonce) > maxAttestationNonceLength {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "nonce is longer than %d characters", maxAttestationNonceLength)
+		return
+	}
+
+	claims, err := j.authenticateBot(req)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = fmt.Fprint(w, err.Error())
+		return
+	}
+	logger := logrus.WithField("bot", claims.BotID)
+
+	if j.exceedsAttestationLimit(claims.BotID) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = fmt.Fprint(w, errAttestationRateLimited)
+		return
+	}
+
+	blockNumber, err := j.latestBlock.get(req.Context())
+	if err != nil {
+		logger.WithError(err).Warn("failed to get the latest block number for the attestation")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = fmt.Fprint(w, errFailedToGetLatestBlock)
+		return
+	}
+
+	now := time.Now().UTC()
+	statement := AttestationStatement{
+		ScannerAddress: j.cfg.Signer.Address().Hex(),
+		ChainID:        j.cfg.Config.ChainID,
+		BlockNumber:    blockNumber,
+		BotID:          claims.BotID,
+		Nonce:          msg.Nonce,
+		IssuedAt:       now.Unix(),
+		ExpiresAt:      now.Add(time.Duration(attestationCfg.TTLSeconds) * time.Second).Unix(),
+	}
+	attestation, err := CreateAttestationJWT(j.cfg.Signer, statement)
+	if err != nil {
+		logger.WithError(err).Error("failed to create the attestation")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprint(w, errFailedToCreateAttestation)
+		return
+	}
+
+	resp, _ := json.Marshal(AttestationResponse{
+		Attestation: attestation,
+		Statement:   statement,
+	})
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "%s", resp)
+}
+
+// exceedsAttestationLimit applies the rate limit override of the bot before checking the limit.
+func (j *JWTProvider) exceedsAttestationLimit(botID string) bool {
+	for id, rateLimit := range j.cfg.Config.BotAttestation.BotRateLimits {
+		if strings.EqualFold(id, botID) {
+			j.attestationLimiter.SetClientLimit(botID, rateLimit.Rate, rateLimit.Burst)
+			break
+		}
+	}
+	return j.attestationLimiter.ExceedsLimit(botID)
+}
+
+// CreateAttestationJWT returns a scanner JWT which has the statement in its claims.
+func CreateAttestationJWT(scannerSigner signer.Signer, statement AttestationStatement) (string, error) {
+	claims := map[string]interface{}{
+		"chain-id":     statement.ChainID,
+		"block-number": statement.BlockNumber,
+		"iat":          statement.IssuedAt,
+		"exp":          statement.ExpiresAt,
+	}
+	if len(statement.Nonce) > 0 {
+		claims["nonce"] = statement.Nonce
+	}
+	return CreateBotJWT(scannerSigner, statement.BotID, claims)
+}
@@ -0,0 +1,104 @@
+package jwt_provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/ratelimiter"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+type testEthClient struct {
+	blockNumber uint64
+	err         error
+}
+
+func (client *testEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return client.blockNumber, client.err
+}
+
+func TestAttestationHandler(t *testing.T) {
+	r := require.New(t)
+
+	privKey, err := crypto.GenerateKey()
+	r.NoError(err)
+	nodeSigner := signer.NewKeySigner(&keystore.Key{
+		PrivateKey: privKey,
+		Address:    crypto.PubkeyToAddress(privKey.PublicKey),
+	})
+	var cfg config.Config
+	cfg.ChainID = 137
+	cfg.BotAuth = config.BotAuthConfig{Enable: true, DisableIPFallback: true}
+	cfg.BotAttestation.TTLSeconds = 300
+	cfg.BotAttestation.BotRateLimits = map[string]config.RateLimitConfig{"0xBOT": {Rate: 0.001, Burst: 2}}
+	ethClient := &testEthClient{blockNumber: 100}
+	j := &JWTProvider{
+		cfg:                &JWTProviderConfig{Signer: nodeSigner, Config: cfg},
+		attestationLimiter: ratelimiter.NewRateLimiter(0.001, 1),
+		latestBlock:        &latestBlock{client: ethClient},
+	}
+
+	botToken, err := clients.CreateBotToken(nodeSigner, config.AgentConfig{ID: "0xbot"})
+	r.NoError(err)
+	attest := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/attestation", strings.NewReader(body))
+		req.RemoteAddr = "1.1.1.1:1111"
+		req.Header.Set(clients.BotTokenHeader, token)
+		w := httptest.NewRecorder()
+		j.attestationHandler(w, req)
+		return w
+	}
+
+	r.Equal(http.StatusUnauthorized, attest("", `{}`).Code)
+	r.Equal(http.StatusBadRequest, attest(botToken, `{"nonce":"`+strings.Repeat("a", 257)+`"}`).Code)
+
+	w := attest(botToken, `{"nonce":"finding-hash"}`)
+	r.Equal(http.StatusOK, w.Code)
+	var resp AttestationResponse
+	r.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	r.Equal(nodeSigner.Address().Hex(), resp.Statement.ScannerAddress)
+	r.Equal(137, resp.Statement.ChainID)
+	r.Equal(uint64(100), resp.Statement.BlockNumber)
+	r.Equal("0xbot", resp.Statement.BotID)
+	r.Equal(int64(300), resp.Statement.ExpiresAt-resp.Statement.IssuedAt)
+
+	token, err := security.VerifyScannerJWT(resp.Attestation)
+	r.NoError(err)
+	claims := token.Token.Claims.(jwt.MapClaims)
+	r.Equal(nodeSigner.Address().Hex(), claims["sub"])
+	r.Equal("0xbot", claims["bot-id"])
+	r.Equal(float64(100), claims["block-number"])
+	r.Equal("finding-hash", claims["nonce"])
+	r.Equal(float64(resp.Statement.ExpiresAt), claims["exp"])
+
+	// the latest block number is reused for a while
+	ethClient.blockNumber = 101
+	w = attest(botToken, "")
+	r.Equal(http.StatusOK, w.Code)
+	r.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	r.Equal(uint64(100), resp.Statement.BlockNumber)
+
+	// the bot rate limit allows two attestations
+	r.Equal(http.StatusTooManyRequests, attest(botToken, "").Code)
+
+	// the failure to get the latest block is not hidden
+	otherToken, err := clients.CreateBotToken(nodeSigner, config.AgentConfig{ID: "0xother"})
+	r.NoError(err)
+	j.latestBlock = &latestBlock{client: &testEthClient{err: errors.New("failed")}}
+	r.Equal(http.StatusServiceUnavailable, attest(otherToken, "").Code)
+
+	j.cfg.Config.BotAttestation.Disable = true
+	r.Equal(http.StatusNotFound, attest(botToken, "").Code)
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/docker"
 )
 
 type CreateJWTMessage struct {
@@ -31,21 +34,14 @@ func (j *JWTProvider) createJWTHandler(w http.ResponseWriter, req *http.Request)
 		}
 	}
 
-	ipAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	agentID, err := j.findBotID(req)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		_, _ = fmt.Fprintf(w, "can't extract ip from request %s", req.RemoteAddr)
+		_, _ = fmt.Fprint(w, err.Error())
 		return
 	}
 
-	agentID, err := j.agentIDReverseLookup(req.Context(), ipAddr)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_, _ = fmt.Fprintf(w, "can't find bot id from request source %s, err: %v", ipAddr, err)
-		return
-	}
-
-	jwt, err := CreateBotJWT(j.cfg.Key, agentID, msg.Claims)
+	jwt, err := CreateBotJWT(j.cfg.Signer, agentID, msg.Claims)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		_, _ = fmt.Fprint(w, errFailedToCreateJWT)
@@ -57,3 +53,65 @@ func (j *JWTProvider) createJWTHandler(w http.ResponseWriter, req *http.Request)
 	w.WriteHeader(http.StatusOK)
 	_, _ = fmt.Fprintf(w, "%s", resp)
 }
+
+// findBotID finds the bot id from the bot token if token authentication is enabled and
+// falls back to the request source if allowed.
+func (j *JWTProvider) findBotID(req *http.Request) (string, error) {
+	claims, err := j.authenticateBot(req)
+	if err != nil {
+		return "", err
+	}
+	return claims.BotID, nil
+}
+
+// authenticateBot finds the bot and its container from the bot token if token authentication is
+// enabled and falls back to the request source if allowed.
+func (j *JWTProvider) authenticateBot(req *http.Request) (*clients.BotTokenClaims, error) {
+	botAuth := j.cfg.Config.BotAuth
+	if botAuth.Enable && clients.HasBotToken(req) {
+		claims, err := clients.VerifyBotToken(req.Header.Get(clients.BotTokenHeader), j.cfg.Signer.Address())
+		if err != nil {
+			return nil, fmt.Errorf("can't authenticate bot token, err: %v", err)
+		}
+		if botAuth.ScopedTokens {
+			if err := j.checkBootstrapToken(req, claims); err != nil {
+				return nil, fmt.Errorf("can't authenticate bot token, err: %v", err)
+			}
+		}
+		return claims, nil
+	}
+	if botAuth.Enable && botAuth.DisableIPFallback {
+		return nil, fmt.Errorf("bot token is required")
+	}
+
+	ipAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return nil, fmt.Errorf("can't extract ip from request %s", req.RemoteAddr)
+	}
+
+	claims, err := j.botReverseLookup(req.Context(), ipAddr)
+	if err != nil {
+		return nil, fmt.Errorf("can't find bot id from request source %s, err: %v", ipAddr, err)
+	}
+	return claims, nil
+}
+
+// checkBootstrapToken makes sure that the token injected to the bot container is used only from
+// that container and only to get the scoped tokens.
+func (j *JWTProvider) checkBootstrapToken(req *http.Request, claims *clients.BotTokenClaims) error {
+	if claims.Audience != clients.BotTokenAudienceJWTProvider {
+		return fmt.Errorf("bot token is not for %s", clients.BotTokenAudienceJWTProvider)
+	}
+	ipAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return fmt.Errorf("can't extract ip from request %s", req.RemoteAddr)
+	}
+	container, err := j.findContainerByIP(req.Context(), ipAddr)
+	if err != nil {
+		return err
+	}
+	if docker.GetContainerName(container) != claims.ContainerName {
+		return fmt.Errorf("bot token does not belong to the request source %s", ipAddr)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package jwt_provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/sirupsen/logrus"
+)
+
+type CreateTokenMessage struct {
+	Audience string `json:"audience"`
+}
+
+type CreateTokenResponse struct {
+	Token string `json:"token"`
+	// ExpiresAt and RefreshAt are unix timestamps. The bots should get a new token after RefreshAt.
+	ExpiresAt int64 `json:"expiresAt"`
+	RefreshAt int64 `json:"refreshAt"`
+}
+
+const (
+	errBadCreateTokenMessage = "bad create token message body"
+	errFailedToCreateToken   = "can't create bot token"
+)
+
+// createTokenHandler returns a short-lived bot token for the services of the requested audience.
+func (j *JWTProvider) createTokenHandler(w http.ResponseWriter, req *http.Request) {
+	var msg CreateTokenMessage
+	if err := json.NewDecoder(req.Body).Decode(&msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, errBadCreateTokenMessage)
+		return
+	}
+	if !clients.IsBotTokenAudience(msg.Audience) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, "unknown audience '%s'", msg.Audience)
+		return
+	}
+
+	claims, err := j.authenticateBot(req)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = fmt.Fprint(w, err.Error())
+		return
+	}
+
+	ttl := time.Duration(j.cfg.Config.BotAuth.TokenTTLSeconds) * time.Second
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	token, err := clients.CreateScopedBotToken(j.cfg.Signer, claims.BotID, claims.ContainerName, msg.Audience, expiresAt)
+	if err != nil {
+		logrus.WithError(err).WithField("bot", claims.BotID).Error("failed to create scoped bot token")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprint(w, errFailedToCreateToken)
+		return
+	}
+
+	// leave a third of the lifetime to rotate the token
+	resp, _ := json.Marshal(CreateTokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt.Unix(),
+		RefreshAt: now.Add(ttl * 2 / 3).Unix(),
+	})
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "%s", resp)
+}
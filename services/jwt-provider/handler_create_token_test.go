@@ -0,0 +1,88 @@
+package jwt_provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-node/clients"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTokenHandler(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	dockerClient := mock_clients.NewMockDockerClient(ctrl)
+
+	privKey, err := crypto.GenerateKey()
+	r.NoError(err)
+	nodeSigner := signer.NewKeySigner(&keystore.Key{
+		PrivateKey: privKey,
+		Address:    crypto.PubkeyToAddress(privKey.PublicKey),
+	})
+	var cfg config.Config
+	cfg.BotAuth = config.BotAuthConfig{Enable: true, ScopedTokens: true, DisableIPFallback: true, TokenTTLSeconds: 900}
+	j := &JWTProvider{
+		dockerClient: dockerClient,
+		cfg:          &JWTProviderConfig{Signer: nodeSigner, Config: cfg},
+	}
+
+	botConfig := config.AgentConfig{ID: "0xbot"}
+	bootstrapToken, err := clients.CreateBotToken(nodeSigner, botConfig)
+	r.NoError(err)
+	containers := []types.Container{
+		{
+			Names: []string{"/" + botConfig.ContainerName()},
+			NetworkSettings: &types.SummaryNetworkSettings{
+				Networks: map[string]*network.EndpointSettings{"bot": {IPAddress: "1.1.1.1"}},
+			},
+		},
+		{
+			Names: []string{"/forta-agent-other"},
+			NetworkSettings: &types.SummaryNetworkSettings{
+				Networks: map[string]*network.EndpointSettings{"other": {IPAddress: "2.2.2.2"}},
+			},
+		},
+	}
+	createToken := func(remoteAddr, token, audience string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(`{"audience":"`+audience+`"}`))
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(clients.BotTokenHeader, token)
+		w := httptest.NewRecorder()
+		j.createTokenHandler(w, req)
+		return w
+	}
+
+	// only the audiences of the services are allowed
+	r.Equal(http.StatusBadRequest, createToken("1.1.1.1:1111", bootstrapToken, clients.BotTokenAudienceJWTProvider).Code)
+
+	// the bootstrap token can not be used from another container
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(containers, nil)
+	r.Equal(http.StatusUnauthorized, createToken("2.2.2.2:1111", bootstrapToken, clients.BotTokenAudienceJSONRPC).Code)
+
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(containers, nil)
+	w := createToken("1.1.1.1:1111", bootstrapToken, clients.BotTokenAudienceJSONRPC)
+	r.Equal(http.StatusOK, w.Code)
+	var resp CreateTokenResponse
+	r.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	r.Less(resp.RefreshAt, resp.ExpiresAt)
+
+	claims, err := clients.VerifyScopedBotToken(resp.Token, nodeSigner.Address(), clients.BotTokenAudienceJSONRPC)
+	r.NoError(err)
+	r.Equal(botConfig.ID, claims.BotID)
+	r.Equal(botConfig.ContainerName(), claims.ContainerName)
+	r.Equal(resp.ExpiresAt, claims.ExpiresAt.Unix())
+
+	// the scoped tokens can not be used to get more tokens
+	r.Equal(http.StatusUnauthorized, createToken("1.1.1.1:1111", resp.Token, clients.BotTokenAudiencePublicAPI).Code)
+}
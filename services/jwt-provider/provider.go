@@ -10,13 +10,15 @@ import (
 	"time"
 
 	"github.com/docker/docker/api/types"
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/forta-network/forta-core-go/clients/health"
-	"github.com/forta-network/forta-core-go/security"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/clients/ratelimiter"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/config"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -34,26 +36,39 @@ type JWTProvider struct {
 
 	lastErr health.ErrorTracker
 
+	attestationLimiter ratelimiter.RateLimiter
+	latestBlock        *latestBlock
+
 	srv *http.Server
 }
 
 type JWTProviderConfig struct {
-	Key    *keystore.Key
-	Config config.Config
+	Signer    signer.Signer
+	Config    config.Config
+	EthClient EthClient
 }
 
 func NewJWTProvider(
 	cfg config.Config,
 ) (*JWTProvider, error) {
-	key, err := security.LoadKey(config.DefaultContainerKeyDirPath)
+	scannerSigner, err := config.LoadSignerInContainer(cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	rpcClient, err := rpc.DialContext(context.Background(), cfg.Scan.JsonRpc.Url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial the scan api: %v", err)
+	}
+	for k, v := range cfg.Scan.JsonRpc.Headers {
+		rpcClient.SetHeader(k, v)
+	}
+
 	return initProvider(
 		&JWTProviderConfig{
-			Key:    key,
-			Config: cfg,
+			Signer:    scannerSigner,
+			Config:    cfg,
+			EthClient: ethclient.NewClient(rpcClient),
 		},
 	)
 }
@@ -64,7 +79,17 @@ func initProvider(cfg *JWTProviderConfig) (*JWTProvider, error) {
 		return nil, fmt.Errorf("failed to create the global docker client: %v", err)
 	}
 
-	return &JWTProvider{dockerClient: globalClient, cfg: cfg}, nil
+	rateLimiting := cfg.Config.BotAttestation.RateLimitConfig
+	if rateLimiting == nil {
+		rateLimiting = &defaultAttestationRateLimit
+	}
+
+	return &JWTProvider{
+		dockerClient:       globalClient,
+		cfg:                cfg,
+		attestationLimiter: ratelimiter.NewRateLimiter(rateLimiting.Rate, rateLimiting.Burst),
+		latestBlock:        &latestBlock{client: cfg.EthClient},
+	}, nil
 }
 
 // Start spawns a jwt provider routine and returns.
@@ -83,6 +108,8 @@ func (j *JWTProvider) StartWithContext(ctx context.Context) error {
 	// setup routes
 	r := mux.NewRouter()
 	r.HandleFunc("/create", j.createJWTHandler).Methods(http.MethodPost)
+	r.HandleFunc("/token", j.createTokenHandler).Methods(http.MethodPost)
+	r.HandleFunc("/attestation", j.attestationHandler).Methods(http.MethodPost)
 
 	j.srv = &http.Server{
 		Addr:    addr,
@@ -120,19 +147,22 @@ func (j *JWTProvider) listenAndServeWithContext(ctx context.Context) error {
 	}
 }
 
-// agentIDReverseLookup reverse lookup from ip to agent id.
-func (j *JWTProvider) agentIDReverseLookup(ctx context.Context, ipAddr string) (string, error) {
+// botReverseLookup reverse lookup from ip to the bot id and the bot container.
+func (j *JWTProvider) botReverseLookup(ctx context.Context, ipAddr string) (*clients.BotTokenClaims, error) {
 	container, err := j.findContainerByIP(ctx, ipAddr)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	botID, err := j.extractBotIDFromContainer(ctx, container)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return botID, nil
+	return &clients.BotTokenClaims{
+		BotID:         botID,
+		ContainerName: docker.GetContainerName(container),
+	}, nil
 }
 
 const envPrefix = config.EnvFortaBotID + "="
@@ -201,12 +231,12 @@ func requestHash(uri string, payload []byte) common.Hash {
 }
 
 // CreateBotJWT returns a bot JWT token. Basically security.ScannerJWT with bot&request info.
-func CreateBotJWT(key *keystore.Key, agentID string, claims map[string]interface{}) (string, error) {
+func CreateBotJWT(scannerSigner signer.Signer, agentID string, claims map[string]interface{}) (string, error) {
 	if claims == nil {
 		claims = make(map[string]interface{})
 	}
 
 	claims["bot-id"] = agentID
 
-	return security.CreateScannerJWT(key, claims)
+	return signer.CreateScannerJWT(scannerSigner, claims)
 }
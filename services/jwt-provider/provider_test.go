@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/golang-jwt/jwt/v4"
 )
 
@@ -51,7 +52,7 @@ func Test_createBotJWT(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
-				got, err := CreateBotJWT(tt.args.key, tt.args.botID, tt.args.data)
+				got, err := CreateBotJWT(signer.NewKeySigner(tt.args.key), tt.args.botID, tt.args.data)
 				if (err != nil) != tt.wantErr {
 					t.Errorf("createBotJWT() error = %v, wantErr %v", err, tt.wantErr)
 					return
@@ -106,7 +107,7 @@ func TestJWTProvider_Start(t *testing.T) {
 		{
 			name: "spawn jwt provider service for 10 seconds",
 			fields: fields{
-				cfg: JWTProviderConfig{Key: key},
+				cfg: JWTProviderConfig{Signer: signer.NewKeySigner(key)},
 			},
 			wantErr: false,
 		},
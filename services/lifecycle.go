@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+// ShutdownStage is a step of the shutdown sequence.
+type ShutdownStage int
+
+// Shutdown stages in the order they run
+const (
+	// StageIntake stops taking new blocks, transactions and alerts.
+	StageIntake ShutdownStage = iota
+	// StageEvaluations waits for the bots to finish evaluating what was already sent to them.
+	StageEvaluations
+	// StagePublish flushes the findings and publishes the last batch.
+	StagePublish
+	// StageProxy stops the proxies which the bots use during the evaluations.
+	StageProxy
+	// StageTeardown stops the rest of the services.
+	StageTeardown
+)
+
+const (
+	shutdownStageCount  = int(StageTeardown) + 1
+	defaultStageTimeout = time.Second * 30
+	idlePollInterval    = time.Millisecond * 100
+)
+
+var shutdownStageNames = [shutdownStageCount]string{"intake", "evaluations", "publish", "proxy", "teardown"}
+
+func (stage ShutdownStage) String() string {
+	if stage < StageIntake || stage > StageTeardown {
+		return fmt.Sprintf("stage-%d", int(stage))
+	}
+	return shutdownStageNames[stage]
+}
+
+// StagedService is stopped in a specific shutdown stage. The services which do not implement
+// this are stopped in the teardown stage.
+type StagedService interface {
+	ShutdownStage() ShutdownStage
+}
+
+// Drainer finishes the in-flight work of a service before it is stopped. The context is done
+// when the shutdown stage times out.
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// StopTimeouter extends the timeout of the shutdown stage for a service which needs longer to
+// stop, e.g. because it waits for other processes to stop first.
+type StopTimeouter interface {
+	StopTimeout() time.Duration
+}
+
+// Lifecycle stops the services stage by stage. Each stage has a context which is canceled after
+// the stage so that the services which use it can keep working until their turn.
+type Lifecycle struct {
+	timeouts  [shutdownStageCount]time.Duration
+	stageCtxs [shutdownStageCount]context.Context
+	cancels   [shutdownStageCount]context.CancelFunc
+}
+
+// NewLifecycle creates a new lifecycle controller. The stage contexts inherit the values of the
+// given context but they are canceled only by the controller.
+func NewLifecycle(ctx context.Context, cfg config.ShutdownConfig) *Lifecycle {
+	lc := &Lifecycle{}
+	for i, timeoutSeconds := range []int{
+		cfg.IntakeTimeoutSeconds,
+		cfg.EvaluationsTimeoutSeconds,
+		cfg.PublishTimeoutSeconds,
+		cfg.ProxyTimeoutSeconds,
+		cfg.TeardownTimeoutSeconds,
+	} {
+		lc.timeouts[i] = defaultStageTimeout
+		if timeoutSeconds > 0 {
+			lc.timeouts[i] = time.Duration(timeoutSeconds) * time.Second
+		}
+		lc.stageCtxs[i], lc.cancels[i] = context.WithCancel(ctx)
+	}
+	return lc
+}
+
+type lifecycleKey struct{}
+
+func withLifecycle(ctx context.Context, lc *Lifecycle) context.Context {
+	return context.WithValue(ctx, lifecycleKey{}, lc)
+}
+
+func getLifecycle(ctx context.Context) *Lifecycle {
+	lc, _ := ctx.Value(lifecycleKey{}).(*Lifecycle)
+	return lc
+}
+
+// StageContext returns the context which is done after the given shutdown stage. The services
+// which should outlive the main context during the shutdown should be created with it. It returns
+// the given context if the context has no lifecycle controller.
+func StageContext(ctx context.Context, stage ShutdownStage) context.Context {
+	lc := getLifecycle(ctx)
+	if lc == nil {
+		return ctx
+	}
+	return lc.stageCtxs[stageIndex(stage)]
+}
+
+func stageIndex(stage ShutdownStage) int {
+	if stage < StageIntake || stage > StageTeardown {
+		return int(StageTeardown)
+	}
+	return int(stage)
+}
+
+func serviceStage(service Service) ShutdownStage {
+	if staged, ok := service.(StagedService); ok {
+		return ShutdownStage(stageIndex(staged.ShutdownStage()))
+	}
+	return StageTeardown
+}
+
+// Shutdown drains and stops the services in the order of the stages and in the given order
+// within a stage. A stage which times out is left behind and the next stage starts.
+func (lc *Lifecycle) Shutdown(logger *log.Entry, services []Service) {
+	for stage := StageIntake; stage <= StageTeardown; stage++ {
+		var stageServices []Service
+		for _, service := range services {
+			if serviceStage(service) == stage {
+				stageServices = append(stageServices, service)
+			}
+		}
+		lc.runStage(logger.WithField("stage", stage.String()), stage, stageServices)
+		lc.cancels[stage]()
+	}
+}
+
+func (lc *Lifecycle) runStage(logger *log.Entry, stage ShutdownStage, services []Service) {
+	if len(services) == 0 {
+		return
+	}
+	timeout := lc.timeouts[stage]
+	for _, service := range services {
+		if timeouter, ok := service.(StopTimeouter); ok && timeouter.StopTimeout() > timeout {
+			timeout = timeouter.StopTimeout()
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, service := range services {
+			serviceLogger := logger.WithField("service", service.Name())
+			if drainer, ok := service.(Drainer); ok {
+				serviceLogger.Info("draining service")
+				if err := drainer.Drain(ctx); err != nil {
+					serviceLogger.WithError(err).Warn("failed to drain service")
+				}
+			}
+			serviceLogger.Info("stopping service")
+			err := service.Stop()
+			serviceLogger.WithError(err).Info("stopped service")
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.WithField("timeout", timeout.String()).Warn("shutdown stage timed out - moving on")
+	}
+}
+
+// WaitIdle waits until the check reports idle twice in a row so that the work which is picked up
+// right between the checks is not missed.
+func WaitIdle(ctx context.Context, isIdle func() bool) error {
+	ticker := time.NewTicker(idlePollInterval)
+	defer ticker.Stop()
+	var idleChecks int
+	for {
+		if isIdle() {
+			idleChecks++
+		} else {
+			idleChecks = 0
+		}
+		if idleChecks == 2 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type testStagedService struct {
+	name  string
+	stage ShutdownStage
+	drain func(ctx context.Context) error
+	stop  func()
+}
+
+func (svc *testStagedService) Start() error {
+	return nil
+}
+
+func (svc *testStagedService) Stop() error {
+	if svc.stop != nil {
+		svc.stop()
+	}
+	return nil
+}
+
+func (svc *testStagedService) Name() string {
+	return svc.name
+}
+
+func (svc *testStagedService) ShutdownStage() ShutdownStage {
+	return svc.stage
+}
+
+func (svc *testStagedService) Drain(ctx context.Context) error {
+	if svc.drain != nil {
+		return svc.drain(ctx)
+	}
+	return nil
+}
+
+type testStopRecorder struct {
+	stopped []string
+	mu      sync.Mutex
+}
+
+func (rec *testStopRecorder) record(name string) func() {
+	return func() {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.stopped = append(rec.stopped, name)
+	}
+}
+
+func TestLifecycle_Shutdown(t *testing.T) {
+	r := require.New(t)
+
+	lc := NewLifecycle(context.Background(), config.ShutdownConfig{IntakeTimeoutSeconds: 1})
+	publishCtx := StageContext(withLifecycle(context.Background(), lc), StagePublish)
+
+	var rec testStopRecorder
+	var drainedBeforeStop bool
+	svcs := []Service{
+		&TestService{ctx: context.Background()},
+		&testStagedService{name: "proxy", stage: StageProxy, stop: rec.record("proxy")},
+		&testStagedService{
+			name:  "publisher",
+			stage: StagePublish,
+			drain: func(ctx context.Context) error {
+				drainedBeforeStop = len(rec.stopped) == 2
+				return nil
+			},
+			stop: func() {
+				// the stage context is canceled after the stage
+				r.NoError(publishCtx.Err())
+				rec.record("publisher")()
+			},
+		},
+		&testStagedService{name: "analyzer", stage: StageEvaluations, stop: rec.record("analyzer")},
+		&testStagedService{name: "stream", stage: StageIntake, stop: rec.record("stream")},
+	}
+
+	lc.Shutdown(logrus.NewEntry(logrus.StandardLogger()), svcs)
+	r.Equal([]string{"stream", "analyzer", "publisher", "proxy"}, rec.stopped)
+	r.True(drainedBeforeStop)
+	r.Error(publishCtx.Err())
+}
+
+func TestLifecycle_StageTimeout(t *testing.T) {
+	r := require.New(t)
+
+	lc := NewLifecycle(context.Background(), config.ShutdownConfig{EvaluationsTimeoutSeconds: 1})
+	evaluationsCtx := StageContext(withLifecycle(context.Background(), lc), StageEvaluations)
+
+	var rec testStopRecorder
+	svcs := []Service{
+		&testStagedService{
+			name:  "analyzer",
+			stage: StageEvaluations,
+			drain: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			stop: func() {
+				// blocks until the test ends
+				<-make(chan struct{})
+			},
+		},
+		&testStagedService{name: "publisher", stage: StagePublish, stop: rec.record("publisher")},
+	}
+
+	start := time.Now()
+	lc.Shutdown(logrus.NewEntry(logrus.StandardLogger()), svcs)
+	r.Less(time.Since(start), time.Second*5)
+	r.Equal([]string{"publisher"}, rec.stopped)
+	r.Error(evaluationsCtx.Err())
+}
+
+type testSlowService struct {
+	testStagedService
+	stopTimeout time.Duration
+}
+
+func (svc *testSlowService) StopTimeout() time.Duration {
+	return svc.stopTimeout
+}
+
+func TestLifecycle_StopTimeout(t *testing.T) {
+	r := require.New(t)
+
+	lc := NewLifecycle(context.Background(), config.ShutdownConfig{TeardownTimeoutSeconds: 1})
+
+	var rec testStopRecorder
+	svcs := []Service{
+		&testSlowService{
+			testStagedService: testStagedService{
+				name:  "supervisor",
+				stage: StageTeardown,
+				stop: func() {
+					time.Sleep(time.Millisecond * 1500)
+					rec.record("supervisor")()
+				},
+			},
+			stopTimeout: time.Second * 3,
+		},
+	}
+
+	// the stop takes longer than the stage timeout but not longer than the service timeout
+	lc.Shutdown(logrus.NewEntry(logrus.StandardLogger()), svcs)
+	r.Equal([]string{"supervisor"}, rec.stopped)
+}
+
+func TestStageContext(t *testing.T) {
+	r := require.New(t)
+
+	ctx := context.Background()
+	r.Equal(ctx, StageContext(ctx, StagePublish))
+
+	execIDCtx := initExecID(context.Background())
+	ctx, cancel := context.WithCancel(withLifecycle(execIDCtx, NewLifecycle(execIDCtx, config.ShutdownConfig{})))
+	stageCtx := StageContext(ctx, StagePublish)
+	r.Equal(ExecID(ctx), ExecID(stageCtx))
+
+	// the main context does not cancel the stage contexts
+	cancel()
+	r.NoError(stageCtx.Err())
+}
+
+func TestWaitIdle(t *testing.T) {
+	r := require.New(t)
+
+	var checks int
+	r.NoError(WaitIdle(context.Background(), func() bool {
+		checks++
+		return checks > 2
+	}))
+	r.Equal(4, checks)
+
+	ctx, cancel := context.WithTimeout(context.Background(), idlePollInterval*3)
+	defer cancel()
+	r.ErrorIs(WaitIdle(ctx, func() bool { return false }), context.DeadlineExceeded)
+}
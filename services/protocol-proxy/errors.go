@@ -0,0 +1,37 @@
+package protocol_proxy
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type errorResponse struct {
+	Error protocolProxyError `json:"error"`
+}
+
+type protocolProxyError struct {
+	Message string `json:"message"`
+}
+
+func writeAuthError(w http.ResponseWriter) {
+	writeError(w, http.StatusUnauthorized, "request source is not a deployed agent")
+}
+
+func writeTooManyReqsErr(w http.ResponseWriter) {
+	writeError(w, http.StatusTooManyRequests, "bot exceeds request rate limit")
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(&errorResponse{
+		Error: protocolProxyError{
+			Message: message,
+		},
+	}); err != nil {
+		log.WithError(err).Error("failed to write error response body")
+	}
+}
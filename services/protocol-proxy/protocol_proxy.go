@@ -0,0 +1,183 @@
+package protocol_proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/clients/ratelimiter"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+var defaultRateLimit = config.RateLimitConfig{Rate: 50, Burst: 10}
+
+// ProtocolProxy proxies the requests of the bots to an API which is not EVM JSON-RPC, so that
+// the bots do not need to embed the credentials of the operator's API.
+type ProtocolProxy struct {
+	ctx       context.Context
+	cfg       config.ProtocolProxyConfig
+	apiURL    *url.URL
+	server    *http.Server
+	msgClient clients.MessageClient
+
+	rateLimiter      ratelimiter.RateLimiter
+	botAuthenticator clients.IPAuthenticator
+
+	lastErr health.ErrorTracker
+}
+
+func (p *ProtocolProxy) newReverseProxy() http.Handler {
+	rp := httputil.NewSingleHostReverseProxy(p.apiURL)
+
+	d := rp.Director
+	rp.Director = func(r *http.Request) {
+		d(r)
+		r.Host = p.apiURL.Host
+		for h, v := range p.cfg.Headers {
+			r.Header.Set(h, v)
+		}
+		r.Header.Set("User-Agent", "forta-scan-node")
+	}
+	rp.ModifyResponse = func(resp *http.Response) error {
+		p.lastErr.Set(nil)
+		return nil
+	}
+	rp.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		log.WithError(err).WithField("proxy", p.cfg.Name).Warn("failed to proxy the bot request")
+		p.lastErr.Set(err)
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	return rp
+}
+
+// statusRecorder keeps the status code of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// authHandler rejects the requests which are not coming from the bots and the bots which
+// exceed the rate limit.
+func (p *ProtocolProxy) authHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t := time.Now()
+		agentConfig, err := p.botAuthenticator.FindAgentFromRequest(req)
+		if err != nil {
+			log.WithError(err).WithField("proxy", p.cfg.Name).Warn("failed to authenticate bot request")
+			writeAuthError(w)
+			return
+		}
+
+		if p.rateLimiter.ExceedsLimit(agentConfig.ID) {
+			writeTooManyReqsErr(w)
+			p.publishMetrics(agentConfig.ID, t, 0, 0, 1, 0)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, req)
+
+		if rec.status < http.StatusBadRequest {
+			p.publishMetrics(agentConfig.ID, t, 1, 0, 0, time.Since(t))
+		} else {
+			p.publishMetrics(agentConfig.ID, t, 0, 1, 0, time.Since(t))
+		}
+	})
+}
+
+func (p *ProtocolProxy) publishMetrics(botID string, t time.Time, success, failed, throttled int, latency time.Duration) {
+	p.msgClient.PublishProto(
+		messaging.SubjectMetricAgent, &protocol.AgentMetricList{
+			Metrics: metrics.GetProtocolProxyMetrics(botID, p.cfg.Name, t, success, failed, throttled, latency),
+		},
+	)
+}
+
+func (p *ProtocolProxy) Start() error {
+	p.server = &http.Server{
+		Addr:    p.cfg.ListenAddr,
+		Handler: p.authHandler(p.newReverseProxy()),
+	}
+	utils.GoListenAndServe(p.server)
+	return nil
+}
+
+// ShutdownStage implements the services.StagedService interface.
+func (p *ProtocolProxy) ShutdownStage() services.ShutdownStage {
+	return services.StageProxy
+}
+
+func (p *ProtocolProxy) Stop() error {
+	if p.server != nil {
+		return p.server.Close()
+	}
+	return nil
+}
+
+func (p *ProtocolProxy) Name() string {
+	return fmt.Sprintf("protocol-proxy-%s", p.cfg.Name)
+}
+
+// Health implements health.Reporter interface.
+func (p *ProtocolProxy) Health() health.Reports {
+	return health.Reports{
+		p.lastErr.GetReport("api"),
+	}
+}
+
+// NewProtocolProxies creates the protocol proxies which share the bot authenticator and the message client.
+func NewProtocolProxies(
+	ctx context.Context, cfgs []config.ProtocolProxyConfig,
+	botAuthenticator clients.IPAuthenticator, msgClient clients.MessageClient,
+) ([]*ProtocolProxy, error) {
+	var proxies []*ProtocolProxy
+	for _, cfg := range cfgs {
+		proxy, err := newProtocolProxy(ctx, cfg, botAuthenticator, msgClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the protocol proxy '%s': %v", cfg.Name, err)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
+
+func newProtocolProxy(
+	ctx context.Context, cfg config.ProtocolProxyConfig,
+	botAuthenticator clients.IPAuthenticator, msgClient clients.MessageClient,
+) (*ProtocolProxy, error) {
+	apiURL, err := url.Parse(cfg.Url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+
+	rateLimiting := cfg.RateLimitConfig
+	if rateLimiting == nil {
+		rateLimiting = &defaultRateLimit
+	}
+
+	return &ProtocolProxy{
+		ctx:              ctx,
+		cfg:              cfg,
+		apiURL:           apiURL,
+		msgClient:        msgClient,
+		botAuthenticator: botAuthenticator,
+		rateLimiter:      ratelimiter.NewRateLimiter(rateLimiting.Rate, rateLimiting.Burst),
+	}, nil
+}
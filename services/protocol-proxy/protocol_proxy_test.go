@@ -0,0 +1,95 @@
+package protocol_proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	mock_ratelimiter "github.com/forta-network/forta-node/clients/ratelimiter/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+const testBotRemoteAddr = "1.1.1.1:1111"
+
+func TestProtocolProxy(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	authenticator := mock_clients.NewMockIPAuthenticator(ctrl)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+	rateLimiter := mock_ratelimiter.NewMockRateLimiter(ctrl)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("secret", req.Header.Get("x-api-key"))
+		if req.URL.Path == "/cosmos/base/tendermint/v1beta1/blocks/unknown" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r.Equal("/cosmos/base/tendermint/v1beta1/blocks/latest", req.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	proxy, err := newProtocolProxy(context.Background(), config.ProtocolProxyConfig{
+		Name:       "cosmos",
+		ListenAddr: ":8565",
+		Url:        api.URL,
+		Headers:    map[string]string{"x-api-key": "secret"},
+	}, authenticator, msgClient)
+	r.NoError(err)
+	proxy.rateLimiter = rateLimiter
+	h := proxy.authHandler(proxy.newReverseProxy())
+
+	// the requests which are not coming from the bots are rejected
+	authenticator.EXPECT().FindAgentFromRequest(gomock.Any()).Return(nil, errors.New("not found"))
+	req := httptest.NewRequest(http.MethodGet, "/cosmos/base/tendermint/v1beta1/blocks/latest", nil)
+	req.RemoteAddr = "2.2.2.2:2222"
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	r.Equal(http.StatusUnauthorized, recorder.Code)
+
+	// the bot requests are proxied with the operator's headers
+	authenticator.EXPECT().FindAgentFromRequest(gomock.Any()).Return(&config.AgentConfig{ID: "0xbot"}, nil).Times(3)
+	var metricNames []string
+	msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any()).Do(func(subject string, payload proto.Message) {
+		for _, metric := range payload.(*protocol.AgentMetricList).Metrics {
+			metricNames = append(metricNames, metric.Name)
+		}
+	}).Times(3)
+	rateLimiter.EXPECT().ExceedsLimit("0xbot").Return(false)
+	req = httptest.NewRequest(http.MethodGet, "/cosmos/base/tendermint/v1beta1/blocks/latest", nil)
+	req.RemoteAddr = testBotRemoteAddr
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	r.Equal(http.StatusOK, recorder.Code)
+	r.Contains(metricNames, metrics.MetricProtocolProxySuccess+".cosmos")
+
+	// the failed requests are counted as errors
+	metricNames = nil
+	rateLimiter.EXPECT().ExceedsLimit("0xbot").Return(false)
+	req = httptest.NewRequest(http.MethodGet, "/cosmos/base/tendermint/v1beta1/blocks/unknown", nil)
+	req.RemoteAddr = testBotRemoteAddr
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	r.Equal(http.StatusInternalServerError, recorder.Code)
+	r.Contains(metricNames, metrics.MetricProtocolProxyError+".cosmos")
+	r.NotContains(metricNames, metrics.MetricProtocolProxySuccess+".cosmos")
+
+	// the bot is throttled
+	metricNames = nil
+	rateLimiter.EXPECT().ExceedsLimit("0xbot").Return(true)
+	req = httptest.NewRequest(http.MethodGet, "/cosmos/base/tendermint/v1beta1/blocks/latest", nil)
+	req.RemoteAddr = testBotRemoteAddr
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	r.Equal(http.StatusTooManyRequests, recorder.Code)
+	r.Contains(metricNames, metrics.MetricProtocolProxyThrottled+".cosmos")
+}
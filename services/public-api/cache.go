@@ -0,0 +1,186 @@
+package public_api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+)
+
+type cachedResponse struct {
+	contentType string
+	body        []byte
+	expiresAt   time.Time
+}
+
+// responseCache keeps the successful public API responses so that the bots which repeat the same
+// queries do not reach the API again.
+type responseCache struct {
+	cfg     config.PublicAPICacheConfig
+	entries map[string]*cachedResponse
+	hits    uint64
+	misses  uint64
+	mu      sync.Mutex
+}
+
+func newResponseCache(cfg config.PublicAPICacheConfig) *responseCache {
+	return &responseCache{
+		cfg:     cfg,
+		entries: make(map[string]*cachedResponse),
+	}
+}
+
+// cacheKey is the hash of the authenticated bot, the request path and the query. The responses
+// are not shared between the bots since the API authorizes each request with the bot's token.
+func cacheKey(req *http.Request, body []byte) string {
+	botID, botOwner, isScanner, _ := getBotFromContext(req.Context())
+	h := sha256.New()
+	h.Write([]byte(fmt.Sprintf("%s/%s/%t", botID, botOwner, isScanner)))
+	h.Write([]byte(req.Method))
+	h.Write([]byte(req.URL.RequestURI()))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *responseCache) get(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (c *responseCache) set(key string, entry *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= c.cfg.MaxEntries {
+		now := time.Now()
+		for key, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+	}
+	if len(c.entries) >= c.cfg.MaxEntries {
+		return
+	}
+	c.entries[key] = entry
+}
+
+// handler serves the cached responses and caches the successful API responses.
+func (c *responseCache) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodPost {
+			h.ServeHTTP(w, req)
+			return
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			http.Error(w, "failed to read the request body", http.StatusBadRequest)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		key := cacheKey(req, body)
+		if entry, ok := c.get(key); ok {
+			atomic.AddUint64(&c.hits, 1)
+			w.Header().Set("Content-Type", entry.contentType)
+			w.Header().Set("X-Forta-Cache", "hit")
+			_, _ = w.Write(entry.body)
+			return
+		}
+		atomic.AddUint64(&c.misses, 1)
+
+		// make sure that we get a plain response from the api so we can check it
+		req.Header.Del("Accept-Encoding")
+		rec := newResponseRecorder(w, c.cfg.MaxResponseBytes)
+		h.ServeHTTP(rec, req)
+		if rec.status != http.StatusOK || rec.overflow || hasGraphQLErrors(rec.body.Bytes()) {
+			return
+		}
+		c.set(key, &cachedResponse{
+			contentType: rec.Header().Get("Content-Type"),
+			body:        rec.body.Bytes(),
+			expiresAt:   time.Now().Add(time.Duration(c.cfg.TTLSeconds) * time.Second),
+		})
+	})
+}
+
+// hasGraphQLErrors tells if the response is not a complete GraphQL result.
+func hasGraphQLErrors(body []byte) bool {
+	var resp struct {
+		Errors json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return true
+	}
+	return len(resp.Errors) > 0 && string(resp.Errors) != "null"
+}
+
+// Health returns the cache health reports.
+func (c *responseCache) Health() health.Reports {
+	return health.Reports{
+		{
+			Name:    "cache.hits",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&c.hits), 10),
+		},
+		{
+			Name:    "cache.misses",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(atomic.LoadUint64(&c.misses), 10),
+		},
+	}
+}
+
+// responseRecorder passes the response to the underlying writer and keeps a copy of it
+// up to the max size.
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	maxSize  int
+	overflow bool
+}
+
+func newResponseRecorder(w http.ResponseWriter, maxSize int) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w, status: http.StatusOK, maxSize: maxSize}
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	rec.status = statusCode
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter.
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if !rec.overflow {
+		if rec.body.Len()+len(b) > rec.maxSize {
+			rec.overflow = true
+			rec.body.Reset()
+		} else {
+			rec.body.Write(b)
+		}
+	}
+	return rec.ResponseWriter.Write(b)
+}
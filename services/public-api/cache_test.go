@@ -0,0 +1,63 @@
+package public_api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	r := require.New(t)
+
+	var apiCalls int
+	api := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		apiCalls++
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(req.URL.RawQuery, "fail") {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"failed"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"alerts":[]}}`))
+	})
+	cache := newResponseCache(config.PublicAPICacheConfig{Enable: true, TTLSeconds: 60, MaxEntries: 10, MaxResponseBytes: 1024})
+	h := cache.handler(api)
+
+	queryAs := func(botID, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		h.ServeHTTP(w, withBotContext(req, botID, "0xowner", false))
+		return w
+	}
+	query := func(path, body string) *httptest.ResponseRecorder {
+		return queryAs("0xbot", path, body)
+	}
+
+	// the same queries are served from the cache
+	r.Equal(`{"data":{"alerts":[]}}`, query("/graphql", `{"query":"a"}`).Body.String())
+	w := query("/graphql", `{"query":"a"}`)
+	r.Equal(`{"data":{"alerts":[]}}`, w.Body.String())
+	r.Equal("hit", w.Header().Get("X-Forta-Cache"))
+	r.Equal("application/json", w.Header().Get("Content-Type"))
+	r.Equal(1, apiCalls)
+
+	// the other queries are not
+	query("/graphql", `{"query":"b"}`)
+	r.Equal(2, apiCalls)
+
+	// the errors are not cached
+	query("/graphql?fail", `{"query":"a"}`)
+	query("/graphql?fail", `{"query":"a"}`)
+	r.Equal(4, apiCalls)
+
+	// the responses are not shared with the other bots
+	w = queryAs("0xotherbot", "/graphql", `{"query":"a"}`)
+	r.Empty(w.Header().Get("X-Forta-Cache"))
+	r.Equal(5, apiCalls)
+
+	r.Equal("1", cache.Health()[0].Details)
+	r.Equal("5", cache.Health()[1].Details)
+}
@@ -39,3 +39,15 @@ func writeTooManyReqsErr(w http.ResponseWriter, req *http.Request) {
 		log.WithError(err).Error("failed to write error response body")
 	}
 }
+
+func writeQuotaExceededErr(w http.ResponseWriter, req *http.Request) {
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	if err := json.NewEncoder(w).Encode(&errorResponse{
+		Error: publicAPIProxyError{
+			Message: "bot exceeds request quota",
+		},
+	}); err != nil {
+		log.WithError(err).Error("failed to write error response body")
+	}
+}
@@ -8,15 +8,14 @@ import (
 	"net/url"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/ethereum"
 	"github.com/forta-network/forta-core-go/protocol"
-	"github.com/forta-network/forta-core-go/security"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/clients/ratelimiter"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services/components/metrics"
 	jwt_provider "github.com/forta-network/forta-node/services/jwt-provider"
@@ -38,12 +37,15 @@ const claimKeyBotOwner = "bot-owner"
 type PublicAPIProxy struct {
 	ctx       context.Context
 	cfg       config.PublicAPIProxyConfig
-	Key       *keystore.Key
+	Signer    signer.Signer
 	msgClient clients.MessageClient
 
 	server *http.Server
 
 	rateLimiter ratelimiter.RateLimiter
+	// cache and quotas are nil if they are disabled
+	cache  *responseCache
+	quotas *quotaTracker
 
 	lastErr       health.ErrorTracker
 	authenticator clients.IPAuthenticator
@@ -78,7 +80,15 @@ func (p *PublicAPIProxy) createPublicAPIProxyHandler() http.Handler {
 			AllowCredentials: true,
 		},
 	)
-	return p.authMiddleware(p.metricMiddleware(c.Handler(p.newReverseProxy())))
+	// the cached responses do not use the quota
+	var h http.Handler = c.Handler(p.newReverseProxy())
+	if p.quotas != nil {
+		h = p.quotas.handler(h)
+	}
+	if p.cache != nil {
+		h = p.cache.handler(h)
+	}
+	return p.authMiddleware(p.metricMiddleware(h))
 }
 
 func (p *PublicAPIProxy) metricMiddleware(h http.Handler) http.Handler {
@@ -128,14 +138,23 @@ func (p *PublicAPIProxy) authMiddleware(h http.Handler) http.Handler {
 }
 
 func (p *PublicAPIProxy) authenticateRequest(req *http.Request) (*http.Request, error) {
+	var botID, botOwner string
+
+	isScanner := false
+	// token authorization
+	if clients.HasBotToken(req) {
+		agentConfig, err := p.authenticator.FindAgentFromRequest(req)
+		if err != nil {
+			return req, err
+		}
+		return withBotContext(req, agentConfig.ID, agentConfig.Owner, isScanner), nil
+	}
+
 	containerName, err := p.authenticator.FindContainerNameFromRemoteAddr(req.Context(), req.RemoteAddr)
 	if err != nil {
 		return req, err
 	}
 
-	var botID, botOwner string
-
-	isScanner := false
 	// combiner feed authorization
 	if containerName == config.DockerScannerContainerName {
 		isScanner = true
@@ -153,14 +172,16 @@ func (p *PublicAPIProxy) authenticateRequest(req *http.Request) (*http.Request,
 		botOwner = agentConfig.Owner
 	}
 
-	// set authorization values as context to use in next middlewares
+	return withBotContext(req, botID, botOwner, isScanner), nil
+}
+
+// withBotContext sets authorization values as context to use in next middlewares.
+func withBotContext(req *http.Request, botID, botOwner string, isScanner bool) *http.Request {
 	ctxWithBot := context.WithValue(req.Context(), botIDKey, botID)
 	ctxWithBot = context.WithValue(ctxWithBot, botOwnerKey, botOwner)
 	ctxWithBot = context.WithValue(ctxWithBot, isScannerKey, isScanner)
 
-	botReq := req.WithContext(ctxWithBot)
-
-	return botReq, nil
+	return req.WithContext(ctxWithBot)
 }
 
 func (p *PublicAPIProxy) setAuthBearer(r *http.Request) {
@@ -172,7 +193,7 @@ func (p *PublicAPIProxy) setAuthBearer(r *http.Request) {
 
 	claims := map[string]interface{}{claimKeyBotOwner: botOwner}
 
-	jwtToken, err := jwt_provider.CreateBotJWT(p.Key, botID, claims)
+	jwtToken, err := jwt_provider.CreateBotJWT(p.Signer, botID, claims)
 	if err != nil {
 		log.WithError(err).Warn("can't create bot jwt")
 		return
@@ -241,9 +262,16 @@ func getBotFromContext(ctx context.Context) (string, string, bool, bool) {
 
 // Health implements health.Reporter interface.
 func (p *PublicAPIProxy) Health() health.Reports {
-	return health.Reports{
+	reports := health.Reports{
 		p.lastErr.GetReport("api"),
 	}
+	if p.cache != nil {
+		reports = append(reports, p.cache.Health()...)
+	}
+	if p.quotas != nil {
+		reports = append(reports, p.quotas.Health()...)
+	}
+	return reports
 }
 
 func (p *PublicAPIProxy) apiHealthChecker() {
@@ -260,37 +288,44 @@ func (p *PublicAPIProxy) testAPI() {
 }
 
 func NewPublicAPIProxy(ctx context.Context, cfg config.Config) (*PublicAPIProxy, error) {
-	key, err := security.LoadKey(config.DefaultContainerKeyDirPath)
+	scannerSigner, err := config.LoadSignerInContainer(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	botAuthenticator, err := clients.NewBotAuthenticator(ctx)
+	botAuthenticator, err := clients.NewBotAuthenticator(ctx, cfg, clients.BotTokenAudiencePublicAPI)
 	if err != nil {
 		return nil, err
 	}
 
-	msgClient := messaging.NewClient("public-api", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
+	msgClient := messaging.NewClient("public-api", cfg.NatsURL())
 
 	rateLimiting := cfg.PublicAPIProxy.RateLimitConfig
 	if rateLimiting == nil {
 		rateLimiting = &config.RateLimitConfig{Rate: 1000, Burst: 1}
 	}
 
-	return newPublicAPIProxy(ctx, cfg.PublicAPIProxy, botAuthenticator, ratelimiter.NewRateLimiter(rateLimiting.Rate, rateLimiting.Burst), key, msgClient)
+	return newPublicAPIProxy(ctx, cfg.PublicAPIProxy, botAuthenticator, ratelimiter.NewRateLimiter(rateLimiting.Rate, rateLimiting.Burst), scannerSigner, msgClient)
 }
 
 func newPublicAPIProxy(
-	ctx context.Context, cfg config.PublicAPIProxyConfig, botAuthenticator clients.IPAuthenticator, rateLimiter ratelimiter.RateLimiter, key *keystore.Key, msgClient clients.MessageClient,
+	ctx context.Context, cfg config.PublicAPIProxyConfig, botAuthenticator clients.IPAuthenticator, rateLimiter ratelimiter.RateLimiter, scannerSigner signer.Signer, msgClient clients.MessageClient,
 ) (
 	*PublicAPIProxy, error,
 ) {
-	return &PublicAPIProxy{
+	p := &PublicAPIProxy{
 		ctx:           ctx,
 		cfg:           cfg,
 		authenticator: botAuthenticator,
 		msgClient:     msgClient,
-		Key:           key,
+		Signer:        scannerSigner,
 		rateLimiter:   rateLimiter,
-	}, nil
+	}
+	if cfg.Cache.Enable {
+		p.cache = newResponseCache(cfg.Cache)
+	}
+	if cfg.Quota.Enable {
+		p.quotas = newQuotaTracker(cfg.Quota)
+	}
+	return p, nil
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/forta-network/forta-core-go/security"
 	mock_clients "github.com/forta-network/forta-node/clients/mocks"
 	mock_ratelimiter "github.com/forta-network/forta-node/clients/ratelimiter/mocks"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/config"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/golang/mock/gomock"
@@ -49,7 +50,7 @@ func TestPublicAPIProxy_setAuthBearer(t *testing.T) {
 	ctx = context.WithValue(ctx, isScannerKey, true)
 	req = req.WithContext(ctx)
 
-	proxy := PublicAPIProxy{Key: key}
+	proxy := PublicAPIProxy{Signer: signer.NewKeySigner(key)}
 	proxy.setAuthBearer(req)
 	// parse and authenticate token
 	h := req.Header.Get("Authorization")
@@ -129,7 +130,7 @@ func TestPublicAPIProxy(t *testing.T) {
 		context.Background(), config.PublicAPIProxyConfig{
 			Url:     "https://api.forta.network",
 			Headers: map[string]string{"test-header": "test-header-value"},
-		}, authenticator, ratelimiter, _keyConstructor(t), messageClient,
+		}, authenticator, ratelimiter, signer.NewKeySigner(_keyConstructor(t)), messageClient,
 	)
 
 	server := httptest.NewServer(p.createPublicAPIProxyHandler())
@@ -0,0 +1,118 @@
+package public_api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+)
+
+// requestCounter counts the requests in a time window which starts at the given unix time.
+type requestCounter struct {
+	window   int64
+	requests int64
+}
+
+func (rc *requestCounter) roll(window int64) {
+	if rc.window != window {
+		*rc = requestCounter{window: window}
+	}
+}
+
+func (rc *requestCounter) exceeds(maxRequests int64) bool {
+	return maxRequests > 0 && rc.requests >= maxRequests
+}
+
+type botRequests struct {
+	hourly requestCounter
+	daily  requestCounter
+}
+
+// quotaTracker counts the hourly and daily API requests of the bots.
+type quotaTracker struct {
+	cfg      config.PublicAPIQuotaConfig
+	usage    map[string]*botRequests
+	exceeded map[string]bool
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+func newQuotaTracker(cfg config.PublicAPIQuotaConfig) *quotaTracker {
+	return &quotaTracker{
+		cfg:      cfg,
+		usage:    make(map[string]*botRequests),
+		exceeded: make(map[string]bool),
+		now:      time.Now,
+	}
+}
+
+// quota finds the quota of the bot.
+func (qt *quotaTracker) quota(botID string) config.PublicAPIQuota {
+	for id, quota := range qt.cfg.BotQuotas {
+		if strings.EqualFold(id, botID) {
+			return quota
+		}
+	}
+	return qt.cfg.Default
+}
+
+// Use counts the request of the bot and tells if it is allowed. The requests over the quota
+// are not counted.
+func (qt *quotaTracker) Use(botID string) bool {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	now := qt.now().UTC()
+	usage, ok := qt.usage[botID]
+	if !ok {
+		usage = &botRequests{}
+		qt.usage[botID] = usage
+	}
+	usage.hourly.roll(now.Truncate(time.Hour).Unix())
+	usage.daily.roll(now.Truncate(time.Hour * 24).Unix())
+
+	quota := qt.quota(botID)
+	exceeds := usage.hourly.exceeds(quota.HourlyRequests) || usage.daily.exceeds(quota.DailyRequests)
+	qt.exceeded[botID] = exceeds
+	if exceeds {
+		return false
+	}
+	usage.hourly.requests++
+	usage.daily.requests++
+	return true
+}
+
+// handler rejects the bot requests which exceed the quota. The requests of the scanner are not limited.
+func (qt *quotaTracker) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		botID, _, isScanner, foundAgent := getBotFromContext(req.Context())
+		if foundAgent && !isScanner && !qt.Use(botID) {
+			writeQuotaExceededErr(w, req)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// Health returns the number of the bots which exceeded their quota on their last request.
+func (qt *quotaTracker) Health() health.Reports {
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+	var count int
+	for _, exceeded := range qt.exceeded {
+		if exceeded {
+			count++
+		}
+	}
+	return health.Reports{
+		{
+			Name:    "quota.exceeded",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(count),
+		},
+	}
+}
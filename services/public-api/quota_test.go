@@ -0,0 +1,39 @@
+package public_api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaTracker(t *testing.T) {
+	r := require.New(t)
+
+	qt := newQuotaTracker(config.PublicAPIQuotaConfig{
+		Enable:    true,
+		Default:   config.PublicAPIQuota{HourlyRequests: 2, DailyRequests: 3},
+		BotQuotas: map[string]config.PublicAPIQuota{"0xBOT2": {HourlyRequests: 1}},
+	})
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	qt.now = func() time.Time { return now }
+
+	r.True(qt.Use("0xbot1"))
+	r.True(qt.Use("0xbot1"))
+	r.False(qt.Use("0xbot1"))
+	r.Equal("1", qt.Health()[0].Details)
+
+	// the bots have their own quota
+	r.True(qt.Use("0xbot2"))
+	r.False(qt.Use("0xbot2"))
+
+	// the hourly quota is reset in the next hour but not the daily quota
+	now = now.Add(time.Hour)
+	r.True(qt.Use("0xbot1"))
+	r.False(qt.Use("0xbot1"))
+
+	now = now.Add(time.Hour * 24)
+	r.True(qt.Use("0xbot1"))
+	r.Equal("1", qt.Health()[0].Details)
+}
@@ -0,0 +1,207 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	batchQueueDirName = config.DefaultBatchQueueDirName
+
+	// how many published batch refs to remember for deduplication
+	maxPublishedRefs = 100
+)
+
+// queuedBatch is a signed batch which is waiting to be published. The batch summary is
+// signed when the batch is sent so that it refers to the latest receipt.
+type queuedBatch struct {
+	Request          *domain.AlertBatchRequest `json:"request"`
+	ScannerVersion   *protocol.ScannerVersion  `json:"scannerVersion,omitempty"`
+	LatestBlockInput uint64                    `json:"latestBlockInput"`
+	Metrics          int                       `json:"metrics"`
+	Bytes            int                       `json:"bytes"`
+	QueuedAt         time.Time                 `json:"queuedAt"`
+
+	fileName string
+}
+
+// batchQueue retains the batches on disk until they are published so that the alerts are not lost
+// when the API is unreachable or the node restarts. The batches are published at least once in the
+// queue order and deduplicated by the batch ref.
+type batchQueue struct {
+	dir        string
+	maxBatches int
+	maxAge     time.Duration
+
+	batches   []*queuedBatch
+	published []string
+	readyCh   chan struct{}
+	mu        sync.Mutex
+}
+
+func newBatchQueue(dir string, cfg config.BatchQueueConfig) (*batchQueue, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the batch queue dir: %v", err)
+	}
+	bq := &batchQueue{
+		dir:        dir,
+		maxBatches: cfg.MaxBatches,
+		maxAge:     time.Duration(cfg.MaxAgeMinutes) * time.Minute,
+		readyCh:    make(chan struct{}, 1),
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the batch queue dir: %v", err)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name() < files[j].Name()
+	})
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		filePath := path.Join(dir, file.Name())
+		b, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			log.WithError(err).WithField("file", file.Name()).Warn("failed to read the queued batch")
+			continue
+		}
+		var qb queuedBatch
+		if err := json.Unmarshal(b, &qb); err != nil || qb.Request == nil {
+			log.WithError(err).WithField("file", file.Name()).Warn("failed to decode the queued batch - discarding")
+			_ = os.Remove(filePath)
+			continue
+		}
+		qb.fileName = file.Name()
+		bq.batches = append(bq.batches, &qb)
+	}
+	if len(bq.batches) > 0 {
+		log.WithField("batches", len(bq.batches)).Info("loaded the queued batches")
+		bq.notify()
+	}
+	return bq, nil
+}
+
+// Push adds the batch to the queue unless a batch with the same ref is queued or was published.
+// It returns how many of the oldest batches were dropped to stay within the limit.
+func (bq *batchQueue) Push(qb *queuedBatch) (added bool, dropped int, err error) {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+
+	ref := qb.Request.Ref
+	for _, queued := range bq.batches {
+		if queued.Request.Ref == ref {
+			return false, 0, nil
+		}
+	}
+	for _, publishedRef := range bq.published {
+		if publishedRef == ref {
+			return false, 0, nil
+		}
+	}
+
+	if qb.QueuedAt.IsZero() {
+		qb.QueuedAt = time.Now().UTC()
+	}
+	qb.fileName = fmt.Sprintf("%020d-%s.json", qb.QueuedAt.UnixNano(), ref)
+	b, err := json.Marshal(qb)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to encode the batch: %v", err)
+	}
+	tmpPath := path.Join(bq.dir, qb.fileName+".tmp")
+	if err := ioutil.WriteFile(tmpPath, b, 0600); err != nil {
+		return false, 0, fmt.Errorf("failed to write the batch: %v", err)
+	}
+	if err := os.Rename(tmpPath, path.Join(bq.dir, qb.fileName)); err != nil {
+		return false, 0, fmt.Errorf("failed to write the batch: %v", err)
+	}
+	bq.batches = append(bq.batches, qb)
+
+	for len(bq.batches) > bq.maxBatches {
+		bq.removeAt(0)
+		dropped++
+	}
+	bq.notify()
+	return true, dropped, nil
+}
+
+// Next returns the oldest batch and tells how many expired batches were dropped.
+func (bq *batchQueue) Next() (qb *queuedBatch, dropped int) {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+
+	minTime := time.Now().Add(-bq.maxAge)
+	for len(bq.batches) > 0 && bq.batches[0].QueuedAt.Before(minTime) {
+		bq.removeAt(0)
+		dropped++
+	}
+	if len(bq.batches) == 0 {
+		return nil, dropped
+	}
+	return bq.batches[0], dropped
+}
+
+// Done removes the published batch from the queue.
+func (bq *batchQueue) Done(qb *queuedBatch) {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+
+	for i, queued := range bq.batches {
+		if queued == qb {
+			bq.removeAt(i)
+			break
+		}
+	}
+	bq.published = append(bq.published, qb.Request.Ref)
+	if len(bq.published) > maxPublishedRefs {
+		bq.published = bq.published[1:]
+	}
+}
+
+// Ready notifies when there are batches in the queue.
+func (bq *batchQueue) Ready() <-chan struct{} {
+	return bq.readyCh
+}
+
+// Len returns the number of the queued batches.
+func (bq *batchQueue) Len() int {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	return len(bq.batches)
+}
+
+// OldestAge returns how long the oldest batch has been waiting.
+func (bq *batchQueue) OldestAge() time.Duration {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	if len(bq.batches) == 0 {
+		return 0
+	}
+	return time.Since(bq.batches[0].QueuedAt)
+}
+
+func (bq *batchQueue) removeAt(i int) {
+	if err := os.Remove(path.Join(bq.dir, bq.batches[i].fileName)); err != nil && !os.IsNotExist(err) {
+		log.WithError(err).Warn("failed to remove the queued batch")
+	}
+	bq.batches = append(bq.batches[:i], bq.batches[i+1:]...)
+}
+
+func (bq *batchQueue) notify() {
+	select {
+	case bq.readyCh <- struct{}{}:
+	default:
+	}
+}
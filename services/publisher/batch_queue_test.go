@@ -0,0 +1,120 @@
+package publisher
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testQueuedBatch(ref string, queuedAt time.Time) *queuedBatch {
+	return &queuedBatch{
+		Request: &domain.AlertBatchRequest{
+			Ref:         ref,
+			SignedBatch: &protocol.SignedPayload{Encoded: ref},
+		},
+		QueuedAt: queuedAt,
+	}
+}
+
+func testBatchQueueConfig() config.BatchQueueConfig {
+	return config.BatchQueueConfig{
+		MaxBatches:        2,
+		MaxAgeMinutes:     60,
+		MaxBackoffSeconds: 1,
+	}
+}
+
+func TestBatchQueue_Persist(t *testing.T) {
+	r := require.New(t)
+
+	dir := path.Join(t.TempDir(), batchQueueDirName)
+	now := time.Now().UTC()
+
+	bq, err := newBatchQueue(dir, testBatchQueueConfig())
+	r.NoError(err)
+	added, dropped, err := bq.Push(testQueuedBatch("ref1", now.Add(-time.Minute)))
+	r.NoError(err)
+	r.True(added)
+	r.Zero(dropped)
+	_, _, err = bq.Push(testQueuedBatch("ref2", now))
+	r.NoError(err)
+
+	// reloaded in order after a restart
+	bq, err = newBatchQueue(dir, testBatchQueueConfig())
+	r.NoError(err)
+	r.Equal(2, bq.Len())
+	r.GreaterOrEqual(bq.OldestAge(), time.Minute)
+	<-bq.Ready()
+
+	qb, dropped := bq.Next()
+	r.Zero(dropped)
+	r.Equal("ref1", qb.Request.Ref)
+	r.Equal("ref1", qb.Request.SignedBatch.Encoded)
+
+	// not removed until it is published
+	qb, _ = bq.Next()
+	r.Equal("ref1", qb.Request.Ref)
+	bq.Done(qb)
+
+	qb, _ = bq.Next()
+	r.Equal("ref2", qb.Request.Ref)
+	bq.Done(qb)
+
+	qb, _ = bq.Next()
+	r.Nil(qb)
+	bq, err = newBatchQueue(dir, testBatchQueueConfig())
+	r.NoError(err)
+	r.Zero(bq.Len())
+}
+
+func TestBatchQueue_Dedup(t *testing.T) {
+	r := require.New(t)
+
+	bq, err := newBatchQueue(path.Join(t.TempDir(), batchQueueDirName), testBatchQueueConfig())
+	r.NoError(err)
+
+	added, _, err := bq.Push(testQueuedBatch("ref1", time.Time{}))
+	r.NoError(err)
+	r.True(added)
+
+	// already queued
+	added, _, err = bq.Push(testQueuedBatch("ref1", time.Time{}))
+	r.NoError(err)
+	r.False(added)
+
+	// already published
+	qb, _ := bq.Next()
+	bq.Done(qb)
+	added, _, err = bq.Push(testQueuedBatch("ref1", time.Time{}))
+	r.NoError(err)
+	r.False(added)
+	r.Zero(bq.Len())
+}
+
+func TestBatchQueue_Drop(t *testing.T) {
+	r := require.New(t)
+
+	bq, err := newBatchQueue(path.Join(t.TempDir(), batchQueueDirName), testBatchQueueConfig())
+	r.NoError(err)
+	now := time.Now().UTC()
+
+	// the oldest batches are dropped when the queue is full
+	_, _, err = bq.Push(testQueuedBatch("ref1", now.Add(-time.Hour*2)))
+	r.NoError(err)
+	_, _, err = bq.Push(testQueuedBatch("ref2", now.Add(-time.Hour*2)))
+	r.NoError(err)
+	_, dropped, err := bq.Push(testQueuedBatch("ref3", now))
+	r.NoError(err)
+	r.Equal(1, dropped)
+	r.Equal(2, bq.Len())
+
+	// the expired batches are dropped
+	qb, dropped := bq.Next()
+	r.Equal(1, dropped)
+	r.Equal("ref3", qb.Request.Ref)
+}
@@ -0,0 +1,223 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/clients/webhook"
+	"github.com/forta-network/forta-core-go/clients/webhook/client/operations"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/protocol/transform"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/publisher/webhooklog"
+	log "github.com/sirupsen/logrus"
+)
+
+const findingSinkBufferSize = 1000
+
+// findingSink sends the findings of the node to the operator in the local mode webhook format.
+type findingSink struct {
+	ctx     context.Context
+	chainID uint64
+	signer  signer.Signer
+	client  LocalAlertClient
+	cfg     config.FindingSinkConfig
+
+	minSeverity protocol.Finding_Severity
+	notifCh     chan *protocol.NotifyRequest
+
+	lastSend    health.TimeTracker
+	lastSendErr health.ErrorTracker
+}
+
+func newFindingSink(ctx context.Context, chainID int, scannerSigner signer.Signer, cfg config.FindingSinkConfig) (*findingSink, error) {
+	var (
+		client LocalAlertClient
+		err    error
+	)
+	if len(cfg.WebhookURL) > 0 {
+		client, err = webhook.NewAlertWebhookClient(cfg.WebhookURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the finding sink webhook client: %v", err)
+		}
+	} else {
+		client, err = webhooklog.NewLogger(cfg.LogFileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the finding sink logger: %v", err)
+		}
+	}
+	return &findingSink{
+		ctx:         ctx,
+		chainID:     uint64(chainID),
+		signer:      scannerSigner,
+		client:      client,
+		cfg:         cfg,
+		minSeverity: protocol.Finding_Severity(protocol.Finding_Severity_value[cfg.MinSeverity]),
+		notifCh:     make(chan *protocol.NotifyRequest, findingSinkBufferSize),
+	}, nil
+}
+
+// Add queues the finding if it matches the filters. The findings are dropped if the sink
+// cannot keep up so that publishing is never blocked.
+func (fs *findingSink) Add(notif *protocol.NotifyRequest) {
+	if !fs.matches(notif) {
+		return
+	}
+	select {
+	case fs.notifCh <- notif:
+	default:
+		log.WithField("alertId", notif.SignedAlert.Alert.Id).Warn("finding sink buffer is full - dropping")
+	}
+}
+
+func (fs *findingSink) matches(notif *protocol.NotifyRequest) bool {
+	if notif.SignedAlert == nil || notif.SignedAlert.Alert == nil || notif.SignedAlert.Alert.Finding == nil {
+		return false
+	}
+	if notif.SignedAlert.Alert.Finding.Severity < fs.minSeverity {
+		return false
+	}
+	if len(fs.cfg.BotIDs) > 0 {
+		if notif.AgentInfo == nil || !containsFold(fs.cfg.BotIDs, notif.AgentInfo.Id) {
+			return false
+		}
+	}
+	if len(fs.cfg.ChainIDs) > 0 {
+		chainID, ok := notifChainID(notif)
+		if !ok || !containsInt(fs.cfg.ChainIDs, int(chainID)) {
+			return false
+		}
+	}
+	return true
+}
+
+// run sends the collected findings periodically.
+func (fs *findingSink) run() {
+	ticker := time.NewTicker(time.Duration(fs.cfg.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	batch := &BatchData{ChainId: fs.chainID}
+	var count int
+	for {
+		select {
+		case <-fs.ctx.Done():
+			return
+		case notif := <-fs.notifCh:
+			batch.AppendAlert(notif)
+			count++
+		case <-ticker.C:
+			if count == 0 {
+				continue
+			}
+			err := fs.send((*protocol.AlertBatch)(batch))
+			fs.lastSendErr.Set(err)
+			if err != nil {
+				log.WithError(err).WithField("findings", count).Warn("failed to send the findings to the sink")
+			} else {
+				fs.lastSend.Set()
+			}
+			batch = &BatchData{ChainId: fs.chainID}
+			count = 0
+		}
+	}
+}
+
+func (fs *findingSink) send(batch *protocol.AlertBatch) error {
+	scannerJwt, err := signer.CreateScannerJWT(
+		fs.signer, map[string]interface{}{
+			"findingSink": "true",
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create the jwt: %v", err)
+	}
+	if dropped := dropIncompleteAlerts(batch); dropped > 0 {
+		log.WithField("findings", dropped).Warn("dropped the findings without the bot info")
+	}
+	alertBatch := transform.ToWebhookAlertBatch(batch)
+	_, err = fs.client.SendAlerts(
+		&operations.SendAlertsParams{
+			Context:       fs.ctx,
+			Payload:       alertBatch,
+			Authorization: utils.StringPtr(fmt.Sprintf("Bearer %s", scannerJwt)),
+		},
+	)
+	return err
+}
+
+// dropIncompleteAlerts removes the alerts which cannot be converted to the webhook alerts
+// and returns how many are removed.
+func dropIncompleteAlerts(batch *protocol.AlertBatch) (dropped int) {
+	filter := func(results []*protocol.AgentAlerts) {
+		for _, result := range results {
+			alerts := result.Alerts[:0]
+			for _, alert := range result.Alerts {
+				if alert.Alert == nil || alert.Alert.Agent == nil || alert.Alert.Finding == nil {
+					dropped++
+					continue
+				}
+				alerts = append(alerts, alert)
+			}
+			result.Alerts = alerts
+		}
+	}
+	for _, blockResults := range batch.Results {
+		filter(blockResults.Results)
+		for _, txResults := range blockResults.Transactions {
+			filter(txResults.Results)
+		}
+	}
+	for _, combinationResults := range batch.CombinationAlerts {
+		filter(combinationResults.Results)
+	}
+	return
+}
+
+// Health implements the health.Reporter interface.
+func (fs *findingSink) Health() health.Reports {
+	return health.Reports{
+		fs.lastSend.GetReport("event.finding-sink.time"),
+		fs.lastSendErr.GetReport("event.finding-sink.error"),
+	}
+}
+
+func notifChainID(notif *protocol.NotifyRequest) (uint64, bool) {
+	var chainIDHex string
+	switch {
+	case notif.EvalTxRequest != nil && notif.EvalTxRequest.Event.Network != nil:
+		chainIDHex = notif.EvalTxRequest.Event.Network.ChainId
+	case notif.EvalBlockRequest != nil && notif.EvalBlockRequest.Event.Network != nil:
+		chainIDHex = notif.EvalBlockRequest.Event.Network.ChainId
+	case notif.EvalAlertRequest != nil && notif.EvalAlertRequest.Event.Alert.Source != nil &&
+		notif.EvalAlertRequest.Event.Alert.Source.Block != nil:
+		return notif.EvalAlertRequest.Event.Alert.Source.Block.ChainId, true
+	default:
+		return 0, false
+	}
+	chainID, err := hexutil.DecodeUint64(chainIDHex)
+	return chainID, err == nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(list []int, n int) bool {
+	for _, item := range list {
+		if item == n {
+			return true
+		}
+	}
+	return false
+}
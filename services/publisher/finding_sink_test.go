@@ -0,0 +1,91 @@
+package publisher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/clients/webhook/client/operations"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+type testAlertClient struct {
+	params *operations.SendAlertsParams
+}
+
+func (c *testAlertClient) SendAlerts(params *operations.SendAlertsParams, opts ...operations.ClientOption) (*operations.SendAlertsOK, error) {
+	c.params = params
+	return &operations.SendAlertsOK{}, nil
+}
+
+func testFindingNotif(botID string, severity protocol.Finding_Severity, chainIDHex string) *protocol.NotifyRequest {
+	return &protocol.NotifyRequest{
+		SignedAlert: &protocol.SignedAlert{
+			Alert: &protocol.Alert{
+				Id:      "alert",
+				Finding: &protocol.Finding{Severity: severity},
+				Agent:   &protocol.AgentInfo{Id: botID},
+			},
+		},
+		EvalBlockRequest: &protocol.EvaluateBlockRequest{
+			Event: &protocol.BlockEvent{
+				BlockHash:   "0xblock",
+				BlockNumber: "0x1",
+				Network:     &protocol.BlockEvent_Network{ChainId: chainIDHex},
+				Block:       &protocol.BlockEvent_EthBlock{},
+			},
+		},
+		EvalBlockResponse: &protocol.EvaluateBlockResponse{},
+		AgentInfo:         &protocol.AgentInfo{Id: botID},
+	}
+}
+
+func TestFindingSink_Filters(t *testing.T) {
+	r := require.New(t)
+
+	fs := &findingSink{
+		cfg: config.FindingSinkConfig{
+			BotIDs:   []string{"0xBOT"},
+			ChainIDs: []int{137},
+		},
+		minSeverity: protocol.Finding_HIGH,
+	}
+	r.True(fs.matches(testFindingNotif("0xbot", protocol.Finding_CRITICAL, "0x89")))
+	r.False(fs.matches(testFindingNotif("0xother", protocol.Finding_CRITICAL, "0x89")))
+	r.False(fs.matches(testFindingNotif("0xbot", protocol.Finding_MEDIUM, "0x89")))
+	r.False(fs.matches(testFindingNotif("0xbot", protocol.Finding_CRITICAL, "0x1")))
+	r.False(fs.matches(&protocol.NotifyRequest{}))
+}
+
+func TestFindingSink_Send(t *testing.T) {
+	r := require.New(t)
+
+	privateKey, err := crypto.GenerateKey()
+	r.NoError(err)
+	client := &testAlertClient{}
+	fs := &findingSink{
+		ctx:    context.Background(),
+		signer: signer.NewKeySigner(&keystore.Key{PrivateKey: privateKey, Address: crypto.PubkeyToAddress(privateKey.PublicKey)}),
+		client: client,
+	}
+
+	batch := &BatchData{}
+	batch.AppendAlert(testFindingNotif("0xbot", protocol.Finding_HIGH, "0x1"))
+	r.NoError(fs.send((*protocol.AlertBatch)(batch)))
+	r.NotNil(client.params)
+	r.Len(client.params.Payload.Alerts, 1)
+	r.Contains(*client.params.Authorization, "Bearer ")
+
+	// the alerts without the bot info are not sent
+	notif := testFindingNotif("0xbot", protocol.Finding_HIGH, "0x1")
+	notif.SignedAlert.Alert.Agent = nil
+	batch = &BatchData{}
+	batch.AppendAlert(notif)
+	batch.AppendAlert(testFindingNotif("0xbot", protocol.Finding_HIGH, "0x1"))
+	r.NoError(fs.send((*protocol.AlertBatch)(batch)))
+	r.Len(client.params.Payload.Alerts, 1)
+}
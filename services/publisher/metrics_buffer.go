@@ -0,0 +1,172 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const metricsBufferFileName = config.DefaultMetricsBufferFileName
+
+// metricsBuffer retains the flushed metrics which could not be published so that they can be
+// added to the next batches. It is persisted to disk so that the metrics survive the restarts.
+type metricsBuffer struct {
+	path       string
+	maxBuckets int
+	maxAge     time.Duration
+	dropNewest bool
+
+	buckets []*protocol.AgentMetrics
+	mu      sync.Mutex
+}
+
+func newMetricsBuffer(filePath string, cfg config.MetricsBufferConfig) *metricsBuffer {
+	mb := &metricsBuffer{
+		path:       filePath,
+		maxBuckets: cfg.MaxBuckets,
+		maxAge:     time.Duration(cfg.MaxAgeMinutes) * time.Minute,
+		dropNewest: cfg.DropPolicy == "newest",
+	}
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		log.WithError(err).Warn("failed to read the metrics buffer")
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &mb.buckets); err != nil {
+			log.WithError(err).Warn("failed to decode the metrics buffer - discarding")
+		}
+	}
+	return mb
+}
+
+// Add adds the metrics to the buffer and returns how many buckets were dropped.
+func (mb *metricsBuffer) Add(allMetrics []*protocol.AgentMetrics) (dropped int) {
+	if len(allMetrics) == 0 {
+		return 0
+	}
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	buckets := mergeMetrics(mb.buckets, allMetrics)
+	buckets, dropped = mb.dropExpired(buckets)
+	if overflow := len(buckets) - mb.maxBuckets; overflow > 0 {
+		if mb.dropNewest {
+			buckets = buckets[:mb.maxBuckets]
+		} else {
+			buckets = buckets[overflow:]
+		}
+		dropped += overflow
+	}
+	mb.buckets = buckets
+	mb.persist()
+	return dropped
+}
+
+// Take removes and returns up to n of the oldest buckets and tells how many expired buckets were dropped.
+func (mb *metricsBuffer) Take(n int) (allMetrics []*protocol.AgentMetrics, dropped int) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	if len(mb.buckets) == 0 {
+		return nil, 0
+	}
+	buckets, dropped := mb.dropExpired(mb.buckets)
+	if n > len(buckets) {
+		n = len(buckets)
+	}
+	allMetrics = buckets[:n]
+	mb.buckets = buckets[n:]
+	mb.persist()
+	return allMetrics, dropped
+}
+
+// Len returns the number of the buffered buckets.
+func (mb *metricsBuffer) Len() int {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	return len(mb.buckets)
+}
+
+func (mb *metricsBuffer) dropExpired(buckets []*protocol.AgentMetrics) ([]*protocol.AgentMetrics, int) {
+	minTime := time.Now().Add(-mb.maxAge)
+	var kept []*protocol.AgentMetrics
+	for _, bucket := range buckets {
+		t, err := time.Parse(time.RFC3339, bucket.Timestamp)
+		if err == nil && t.Before(minTime) {
+			continue
+		}
+		kept = append(kept, bucket)
+	}
+	return kept, len(buckets) - len(kept)
+}
+
+func (mb *metricsBuffer) persist() {
+	if err := mb.write(); err != nil {
+		log.WithError(err).Warn("failed to persist the metrics buffer")
+	}
+}
+
+func (mb *metricsBuffer) write() error {
+	if len(mb.buckets) == 0 {
+		if err := os.Remove(mb.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	b, err := json.Marshal(mb.buckets)
+	if err != nil {
+		return fmt.Errorf("failed to encode: %v", err)
+	}
+	tmpPath := mb.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, mb.path)
+}
+
+// mergeMetrics merges the metric buckets of the same bot and time so that the same bucket
+// is not published twice. A summary in both lists is deduplicated by keeping the one with
+// more data points. The result is sorted by time.
+func mergeMetrics(lists ...[]*protocol.AgentMetrics) []*protocol.AgentMetrics {
+	var merged []*protocol.AgentMetrics
+	index := make(map[string]*protocol.AgentMetrics)
+	for _, list := range lists {
+		for _, bucket := range list {
+			key := bucket.AgentId + "|" + bucket.Timestamp
+			existing, ok := index[key]
+			if !ok {
+				index[key] = bucket
+				merged = append(merged, bucket)
+				continue
+			}
+			for _, summary := range bucket.Metrics {
+				mergeSummary(existing, summary)
+			}
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp < merged[j].Timestamp
+	})
+	return merged
+}
+
+func mergeSummary(bucket *protocol.AgentMetrics, summary *protocol.MetricSummary) {
+	for i, existing := range bucket.Metrics {
+		if existing.Name != summary.Name {
+			continue
+		}
+		if summary.Count > existing.Count {
+			bucket.Metrics[i] = summary
+		}
+		return
+	}
+	bucket.Metrics = append(bucket.Metrics, summary)
+}
@@ -0,0 +1,106 @@
+package publisher
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testMetricsBucket(agentID string, t time.Time, name string, count uint32) *protocol.AgentMetrics {
+	return &protocol.AgentMetrics{
+		AgentId:   agentID,
+		Timestamp: utils.FormatTime(t),
+		Metrics:   []*protocol.MetricSummary{{Name: name, Count: count}},
+	}
+}
+
+func testMetricsBufferConfig(dropPolicy string) config.MetricsBufferConfig {
+	return config.MetricsBufferConfig{
+		MaxBuckets:    3,
+		MaxAgeMinutes: 60,
+		FlushBuckets:  2,
+		DropPolicy:    dropPolicy,
+	}
+}
+
+func TestMetricsBuffer_Persist(t *testing.T) {
+	r := require.New(t)
+
+	filePath := path.Join(t.TempDir(), metricsBufferFileName)
+	now := time.Now().Truncate(time.Minute)
+
+	mb := newMetricsBuffer(filePath, testMetricsBufferConfig("oldest"))
+	r.Zero(mb.Add([]*protocol.AgentMetrics{
+		testMetricsBucket("bot1", now.Add(-time.Minute), "tx.request", 1),
+		testMetricsBucket("bot1", now, "tx.request", 1),
+		testMetricsBucket("bot2", now, "tx.request", 1),
+	}))
+
+	// reloaded after a restart
+	mb = newMetricsBuffer(filePath, testMetricsBufferConfig("oldest"))
+	r.Equal(3, mb.Len())
+
+	taken, dropped := mb.Take(2)
+	r.Zero(dropped)
+	r.Len(taken, 2)
+	r.Equal(utils.FormatTime(now.Add(-time.Minute)), taken[0].Timestamp)
+
+	taken, _ = mb.Take(2)
+	r.Len(taken, 1)
+	r.NoFileExists(filePath)
+}
+
+func TestMetricsBuffer_Dedup(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now().Truncate(time.Minute)
+	mb := newMetricsBuffer(path.Join(t.TempDir(), metricsBufferFileName), testMetricsBufferConfig("oldest"))
+
+	r.Zero(mb.Add([]*protocol.AgentMetrics{testMetricsBucket("bot1", now, "tx.request", 1)}))
+	r.Zero(mb.Add([]*protocol.AgentMetrics{
+		testMetricsBucket("bot1", now, "tx.request", 5),
+		testMetricsBucket("bot1", now, "tx.latency", 2),
+	}))
+	r.Equal(1, mb.Len())
+
+	taken, _ := mb.Take(1)
+	r.Len(taken[0].Metrics, 2)
+	r.Equal(uint32(5), taken[0].Metrics[0].Count)
+}
+
+func TestMetricsBuffer_DropPolicy(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	allMetrics := func() []*protocol.AgentMetrics {
+		return []*protocol.AgentMetrics{
+			testMetricsBucket("bot1", now.Add(-time.Hour*2), "tx.request", 1), // expired
+			testMetricsBucket("bot1", now.Add(-time.Minute*3), "tx.request", 1),
+			testMetricsBucket("bot1", now.Add(-time.Minute*2), "tx.request", 1),
+			testMetricsBucket("bot1", now.Add(-time.Minute), "tx.request", 1),
+			testMetricsBucket("bot1", now, "tx.request", 1),
+		}
+	}
+
+	for _, test := range []struct {
+		policy       string
+		expectedTime time.Time
+	}{
+		{policy: "oldest", expectedTime: now.Add(-time.Minute * 2)},
+		{policy: "newest", expectedTime: now.Add(-time.Minute * 3)},
+	} {
+		t.Run(test.policy, func(t *testing.T) {
+			r := require.New(t)
+
+			mb := newMetricsBuffer(path.Join(t.TempDir(), metricsBufferFileName), testMetricsBufferConfig(test.policy))
+			r.Equal(2, mb.Add(allMetrics()))
+			r.Equal(3, mb.Len())
+
+			taken, _ := mb.Take(1)
+			r.Equal(utils.FormatTime(test.expectedTime), taken[0].Timestamp)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package publisher
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const publishKeysFileName = config.DefaultPublishKeysFileName
+
+// publishKeys remembers the publish keys of the latest alerts so that the duplicates of the alerts
+// are not published again. The keys are added when the alerts are put in a batch and they are
+// persisted only after the batch is queued or published, so that a restart in between does not
+// lose the alerts. The keys of a batch which could not be queued or published are forgotten.
+type publishKeys struct {
+	path    string
+	maxKeys int
+
+	keys      map[string]struct{}
+	order     []string
+	pending   map[string]struct{}
+	fileLines int
+	mu        sync.Mutex
+}
+
+func newPublishKeys(filePath string, maxKeys int) *publishKeys {
+	pk := &publishKeys{
+		path:    filePath,
+		maxKeys: maxKeys,
+		keys:    make(map[string]struct{}),
+		pending: make(map[string]struct{}),
+	}
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		log.WithError(err).Warn("failed to read the publish keys")
+	}
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	pk.fileLines = len(lines)
+	if len(lines) > maxKeys {
+		lines = lines[len(lines)-maxKeys:]
+	}
+	for _, key := range lines {
+		pk.addUnsafe(key)
+	}
+	return pk
+}
+
+// Add adds the key and tells if it was not known before.
+func (pk *publishKeys) Add(key string) bool {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+	if _, ok := pk.keys[key]; ok {
+		return false
+	}
+	pk.addUnsafe(key)
+	pk.pending[key] = struct{}{}
+	return true
+}
+
+func (pk *publishKeys) addUnsafe(key string) {
+	pk.keys[key] = struct{}{}
+	pk.order = append(pk.order, key)
+	if len(pk.order) > pk.maxKeys {
+		delete(pk.keys, pk.order[0])
+		delete(pk.pending, pk.order[0])
+		pk.order = pk.order[1:]
+	}
+}
+
+// Forget forgets the pending keys so that the same alerts can be added again.
+func (pk *publishKeys) Forget(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+
+	var forgotten int
+	for _, key := range keys {
+		if _, ok := pk.pending[key]; !ok {
+			continue
+		}
+		delete(pk.pending, key)
+		delete(pk.keys, key)
+		forgotten++
+	}
+	if forgotten == 0 {
+		return
+	}
+	order := make([]string, 0, len(pk.order)-forgotten)
+	for _, key := range pk.order {
+		if _, ok := pk.keys[key]; ok {
+			order = append(order, key)
+		}
+	}
+	pk.order = order
+}
+
+// Persist appends the keys to the file. The file is rewritten with the latest keys when most of
+// the keys in it are forgotten.
+func (pk *publishKeys) Persist(keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+
+	for _, key := range keys {
+		delete(pk.pending, key)
+	}
+	if pk.fileLines+len(keys) > 2*pk.maxKeys {
+		return pk.rewriteUnsafe()
+	}
+	file, err := os.OpenFile(pk.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open the publish keys: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(strings.Join(keys, "\n") + "\n"); err != nil {
+		return fmt.Errorf("failed to write the publish keys: %v", err)
+	}
+	pk.fileLines += len(keys)
+	return nil
+}
+
+// rewriteUnsafe writes the latest keys except the pending ones to a new file.
+func (pk *publishKeys) rewriteUnsafe() error {
+	var buf bytes.Buffer
+	var count int
+	for _, key := range pk.order {
+		if _, ok := pk.pending[key]; ok {
+			continue
+		}
+		buf.WriteString(key + "\n")
+		count++
+	}
+	tmpPath := pk.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write the publish keys: %v", err)
+	}
+	if err := os.Rename(tmpPath, pk.path); err != nil {
+		return fmt.Errorf("failed to replace the publish keys: %v", err)
+	}
+	pk.fileLines = count
+	return nil
+}
+
+// batchPublishKeys returns the publish keys of the alerts in the batch.
+func batchPublishKeys(batch *protocol.AlertBatch) (keys []string) {
+	forEachAgentAlerts(batch, func(agentAlerts *protocol.AgentAlerts) {
+		for _, alert := range agentAlerts.Alerts {
+			if alert == nil || alert.Alert == nil {
+				continue
+			}
+			if key := clients.AlertPublishKey(alert.Alert); len(key) > 0 {
+				keys = append(keys, key)
+			}
+		}
+	})
+	return
+}
+
+// sortBatch orders the results and the alerts in the batch so that the same alerts make up the same
+// batch regardless of the order which the bots responded in.
+func sortBatch(batch *protocol.AlertBatch) {
+	sort.SliceStable(batch.Results, func(i, j int) bool {
+		return batch.Results[i].Block.GetBlockNumber() < batch.Results[j].Block.GetBlockNumber()
+	})
+	for _, blockRes := range batch.Results {
+		sort.SliceStable(blockRes.Transactions, func(i, j int) bool {
+			return blockRes.Transactions[i].GetTransaction().GetTransaction().GetHash() <
+				blockRes.Transactions[j].GetTransaction().GetTransaction().GetHash()
+		})
+		sortAgentAlerts(blockRes.Results)
+		for _, txRes := range blockRes.Transactions {
+			sortAgentAlerts(txRes.Results)
+		}
+	}
+	sort.SliceStable(batch.CombinationAlerts, func(i, j int) bool {
+		return batch.CombinationAlerts[i].GetAlertEvent().GetAlert().GetHash() <
+			batch.CombinationAlerts[j].GetAlertEvent().GetAlert().GetHash()
+	})
+	for _, combinationRes := range batch.CombinationAlerts {
+		sortAgentAlerts(combinationRes.Results)
+	}
+	sortAgentAlerts(batch.PrivateAlerts)
+}
+
+func sortAgentAlerts(results []*protocol.AgentAlerts) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].AgentManifest < results[j].AgentManifest
+	})
+	for _, agentAlerts := range results {
+		alerts := agentAlerts.Alerts
+		sort.SliceStable(alerts, func(i, j int) bool {
+			return alertSortKey(alerts[i]) < alertSortKey(alerts[j])
+		})
+	}
+}
+
+func alertSortKey(alert *protocol.SignedAlert) string {
+	if alert == nil || alert.Alert == nil {
+		return ""
+	}
+	if key := clients.AlertPublishKey(alert.Alert); len(key) > 0 {
+		return key
+	}
+	return alert.Alert.Id
+}
+
+func forEachAgentAlerts(batch *protocol.AlertBatch, handler func(*protocol.AgentAlerts)) {
+	for _, blockRes := range batch.Results {
+		for _, agentAlerts := range blockRes.Results {
+			handler(agentAlerts)
+		}
+		for _, txRes := range blockRes.Transactions {
+			for _, agentAlerts := range txRes.Results {
+				handler(agentAlerts)
+			}
+		}
+	}
+	for _, combinationRes := range batch.CombinationAlerts {
+		for _, agentAlerts := range combinationRes.Results {
+			handler(agentAlerts)
+		}
+	}
+	for _, agentAlerts := range batch.PrivateAlerts {
+		handler(agentAlerts)
+	}
+}
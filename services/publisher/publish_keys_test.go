@@ -0,0 +1,129 @@
+package publisher
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/stretchr/testify/require"
+)
+
+func testKeyedAlert(evaluationID, alertID string) *protocol.SignedAlert {
+	return &protocol.SignedAlert{
+		Alert: &protocol.Alert{
+			Id:       alertID,
+			Metadata: map[string]string{clients.AlertEvaluationID: evaluationID},
+		},
+	}
+}
+
+func TestPublishKeys(t *testing.T) {
+	r := require.New(t)
+
+	filePath := path.Join(t.TempDir(), publishKeysFileName)
+	pk := newPublishKeys(filePath, 3)
+	r.True(pk.Add("1"))
+	r.False(pk.Add("1"))
+	r.True(pk.Add("2"))
+
+	// only the persisted keys are remembered after a restart
+	r.NoError(pk.Persist([]string{"1"}))
+	pk = newPublishKeys(filePath, 3)
+	r.False(pk.Add("1"))
+	r.True(pk.Add("2"))
+
+	// the oldest keys are forgotten
+	r.True(pk.Add("3"))
+	r.True(pk.Add("4"))
+	r.True(pk.Add("1"))
+}
+
+func TestPublishKeys_Rewrite(t *testing.T) {
+	r := require.New(t)
+
+	filePath := path.Join(t.TempDir(), publishKeysFileName)
+	pk := newPublishKeys(filePath, 2)
+	for _, key := range []string{"1", "2", "3", "4"} {
+		r.True(pk.Add(key))
+		r.NoError(pk.Persist([]string{key}))
+	}
+	r.Equal(4, pk.fileLines)
+
+	// the file is rewritten with the latest keys except the pending ones
+	r.True(pk.Add("5"))
+	r.True(pk.Add("6"))
+	r.NoError(pk.Persist([]string{"5"}))
+	r.Equal(1, pk.fileLines)
+
+	pk = newPublishKeys(filePath, 2)
+	r.False(pk.Add("5"))
+	r.True(pk.Add("6"))
+	r.True(pk.Add("4"))
+}
+
+func TestPublisher_UpdatePublishKeys(t *testing.T) {
+	r := require.New(t)
+
+	filePath := path.Join(t.TempDir(), publishKeysFileName)
+	pub := &Publisher{publishKeys: newPublishKeys(filePath, 10)}
+	r.True(pub.publishKeys.Add("1"))
+
+	alert := testKeyedAlert("evaluation1", "alert1")
+	key := clients.AlertPublishKey(alert.Alert)
+	batch := &protocol.AlertBatch{
+		PrivateAlerts: []*protocol.AgentAlerts{{Alerts: []*protocol.SignedAlert{alert}}},
+	}
+	r.True(pub.publishKeys.Add(key))
+
+	// the alert of the retried evaluation is published after the batch fails
+	pub.updatePublishKeys(batch, errors.New("failed to publish"))
+	r.True(pub.publishKeys.Add(key))
+	r.False(pub.publishKeys.Add("1"))
+
+	// and it is skipped after the batch is published
+	pub.updatePublishKeys(batch, nil)
+	r.False(pub.publishKeys.Add(key))
+	pub = &Publisher{publishKeys: newPublishKeys(filePath, 10)}
+	r.False(pub.publishKeys.Add(key))
+	r.True(pub.publishKeys.Add("1"))
+}
+
+func TestSortBatch(t *testing.T) {
+	r := require.New(t)
+
+	batch := &protocol.AlertBatch{
+		Results: []*protocol.BlockResults{
+			{
+				Block: &protocol.Block{BlockNumber: 2},
+				Results: []*protocol.AgentAlerts{
+					{AgentManifest: "b", Alerts: []*protocol.SignedAlert{testKeyedAlert("0x2", "0xa"), testKeyedAlert("0x1", "0xb")}},
+					{AgentManifest: "a"},
+				},
+			},
+			{
+				Block: &protocol.Block{BlockNumber: 1},
+				Transactions: []*protocol.TransactionResults{
+					{
+						Transaction: &protocol.TransactionEvent{Transaction: &protocol.TransactionEvent_EthTransaction{Hash: "0xtx2"}},
+					},
+					{
+						Transaction: &protocol.TransactionEvent{Transaction: &protocol.TransactionEvent_EthTransaction{Hash: "0xtx1"}},
+						Results: []*protocol.AgentAlerts{
+							{AgentManifest: "a", Alerts: []*protocol.SignedAlert{testKeyedAlert("0x3", "0xc")}},
+						},
+					},
+				},
+			},
+		},
+	}
+	sortBatch(batch)
+
+	r.EqualValues(1, batch.Results[0].Block.BlockNumber)
+	r.Equal("0xtx1", batch.Results[0].Transactions[0].Transaction.Transaction.Hash)
+	r.Equal("a", batch.Results[1].Results[0].AgentManifest)
+	r.Equal("0xb", batch.Results[1].Results[1].Alerts[0].Alert.Id)
+
+	r.Equal([]string{"0x3/0xc", "0x1/0xb", "0x2/0xa"}, batchPublishKeys(batch))
+}
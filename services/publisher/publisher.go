@@ -9,10 +9,11 @@ import (
 	"math/big"
 	"os"
 	"path"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/forta-network/forta-core-go/clients/health"
@@ -23,17 +24,19 @@ import (
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-core-go/protocol/transform"
 	"github.com/forta-network/forta-core-go/release"
-	"github.com/forta-network/forta-core-go/security"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/alertapi"
 	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/clients/storagegrpc"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components/metrics"
 	"github.com/forta-network/forta-node/services/publisher/webhooklog"
 	"github.com/forta-network/forta-node/services/storage"
 	"github.com/forta-network/forta-node/store"
+	"github.com/golang/protobuf/proto"
 	ipfsapi "github.com/ipfs/go-ipfs-api"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
@@ -44,6 +47,9 @@ const (
 	defaultBatchLimit      = 500
 	defaultBatchBufferSize = 100
 
+	defaultQueueMetricsInterval = time.Second * 15
+	minBatchRetryInterval       = time.Second
+
 	fastReportInterval = time.Minute
 	slowReportInterval = time.Minute * 15
 )
@@ -57,6 +63,10 @@ type Publisher struct {
 	ipfs              ipfs.Client
 	storage           protocol.StorageClient
 	metricsAggregator *AgentMetricsAggregator
+	metricsBuffer     *metricsBuffer
+	batchQueue        *batchQueue
+	publishKeys       *publishKeys
+	findingSink       *findingSink
 	messageClient     clients.MessageClient
 	alertClient       clients.AlertAPIClient
 	localAlertClient  LocalAlertClient
@@ -73,15 +83,20 @@ type Publisher struct {
 	skipPublish   bool
 	batchInterval time.Duration
 	batchLimit    int
+	batchMaxBytes int
 	latestChainID uint64
 	notifCh       chan *protocol.NotifyRequest
 	batchCh       chan *protocol.AlertBatch
+	flushCh       chan struct{}
+	publishing    int32 // set while a batch is being published
 
 	lastBatchPublish        health.TimeTracker
 	lastBatchPublishAttempt health.TimeTracker
 	lastBatchSkip           health.TimeTracker
 	lastBatchSkipReason     health.MessageTracker
 	lastBatchPublishErr     health.ErrorTracker
+	lastBatchQueueErr       health.ErrorTracker
+	lastAttestationErr      health.ErrorTracker
 	lastMetricsFlush        health.TimeTracker
 
 	// these help following single ticker and keep send intervals on track
@@ -123,13 +138,16 @@ type IPFS interface {
 
 type PublisherConfig struct {
 	ChainID         int
-	Key             *keystore.Key
+	Signer          signer.Signer
 	PublisherConfig config.PublisherConfig
 	ReleaseSummary  *release.ReleaseSummary
 	Config          config.Config
 }
 
 func (pub *Publisher) Notify(ctx context.Context, req *protocol.NotifyRequest) (*protocol.NotifyResponse, error) {
+	if pub.findingSink != nil {
+		pub.findingSink.Add(req)
+	}
 	pub.notifCh <- req
 	return &protocol.NotifyResponse{}, nil
 }
@@ -139,6 +157,12 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 	if _, skip := pub.shouldSkipPublishing(batch); !skip {
 		var flushed bool
 		batch.Metrics, flushed = pub.metricsAggregator.TryFlush()
+		// retry the metrics which could not be published before
+		if pub.metricsBuffer != nil {
+			buffered, dropped := pub.metricsBuffer.Take(pub.cfg.PublisherConfig.MetricsBuffer.FlushBuckets)
+			pub.reportDroppedMetrics(dropped)
+			batch.Metrics = mergeMetrics(buffered, batch.Metrics)
+		}
 		// detect the active bots from metrics
 		pub.lifecycleMetrics.StatusActive(metrics.FindActiveBotsFromMetrics(batch.Metrics))
 		if flushed {
@@ -149,6 +173,8 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		}
 	}
 
+	sortBatch(batch)
+
 	// always add the latest known results
 	pub.latestInspectionResultsMu.RLock()
 	batch.InspectionResults = pub.latestInspectionResults
@@ -176,7 +202,7 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		batch.LatestBlockInput = batch.BlockEnd
 	}
 
-	signedBatch, err := security.SignBatch(pub.cfg.Key, batch)
+	signedBatch, err := signer.SignBatch(pub.cfg.Signer, batch)
 	if err != nil {
 		return false, fmt.Errorf("failed to build envelope: %v", err)
 	}
@@ -214,8 +240,8 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 	pub.lastBatchReadyMu.RUnlock()
 
 	if pub.cfg.Config.LocalModeConfig.Enable {
-		scannerJwt, err := security.CreateScannerJWT(
-			pub.cfg.Key, map[string]interface{}{
+		scannerJwt, err := signer.CreateScannerJWT(
+			pub.cfg.Signer, map[string]interface{}{
 				"localMode": "true",
 			},
 		)
@@ -254,14 +280,50 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		return false, fmt.Errorf("failed to write last batch ref: %v", err)
 	}
 
+	qb := &queuedBatch{
+		Request: &domain.AlertBatchRequest{
+			Scanner:     pub.cfg.Signer.Address().Hex(),
+			ChainID:     int64(batch.ChainId),
+			BlockStart:  int64(batch.BlockStart),
+			BlockEnd:    int64(batch.BlockEnd),
+			AlertCount:  int64(batch.AlertCount),
+			MaxSeverity: int64(batch.MaxSeverity),
+			Ref:         cid,
+			SignedBatch: signedBatch,
+		},
+		ScannerVersion:   batch.ScannerVersion,
+		LatestBlockInput: batch.LatestBlockInput,
+		Metrics:          len(batch.Metrics),
+		Bytes:            buf.Len(),
+	}
+
+	// the queue retries publishing the batch until it succeeds
+	if pub.batchQueue != nil {
+		added, dropped, err := pub.batchQueue.Push(qb)
+		if err != nil {
+			return false, fmt.Errorf("failed to queue the batch: %v", err)
+		}
+		pub.reportDroppedBatches(dropped)
+		if !added {
+			log.WithField("ref", cid).Info("batch is already queued or published - skipping")
+		}
+		return false, nil
+	}
+
+	return pub.postBatch(qb)
+}
+
+// postBatch sends the batch to the alerts API.
+func (pub *Publisher) postBatch(qb *queuedBatch) (published bool, err error) {
+	req := qb.Request
 	logger := log.WithFields(
 		log.Fields{
-			"blockStart":  batch.BlockStart,
-			"blockEnd":    batch.BlockEnd,
-			"alertCount":  batch.AlertCount,
-			"maxSeverity": batch.MaxSeverity.String(),
-			"ref":         cid,
-			"metrics":     len(batch.Metrics),
+			"blockStart":  req.BlockStart,
+			"blockEnd":    req.BlockEnd,
+			"alertCount":  req.AlertCount,
+			"maxSeverity": protocol.Finding_Severity(req.MaxSeverity).String(),
+			"ref":         req.Ref,
+			"metrics":     qb.Metrics,
 		},
 	)
 
@@ -271,16 +333,16 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		lastReceipt = lr
 	}
 
-	signedBatchSummary, err := security.SignBatchSummary(
-		pub.cfg.Key, &protocol.BatchSummary{
-			Batch:            cid,
-			ChainId:          batch.ChainId,
-			BlockStart:       batch.BlockStart,
-			BlockEnd:         batch.BlockEnd,
-			AlertCount:       batch.AlertCount,
-			ScannerVersion:   batch.ScannerVersion,
+	signedBatchSummary, err := signer.SignBatchSummary(
+		pub.cfg.Signer, &protocol.BatchSummary{
+			Batch:            req.Ref,
+			ChainId:          uint64(req.ChainID),
+			BlockStart:       uint64(req.BlockStart),
+			BlockEnd:         uint64(req.BlockEnd),
+			AlertCount:       uint32(req.AlertCount),
+			ScannerVersion:   qb.ScannerVersion,
 			PreviousReceipt:  lastReceipt,
-			LatestBlockInput: batch.LatestBlockInput,
+			LatestBlockInput: qb.LatestBlockInput,
 			Timestamp:        time.Now().UTC().Format(time.RFC3339),
 		},
 	)
@@ -289,9 +351,9 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		return false, err
 	}
 
-	scannerJwt, err := security.CreateScannerJWT(
-		pub.cfg.Key, map[string]interface{}{
-			"batch": cid,
+	scannerJwt, err := signer.CreateScannerJWT(
+		pub.cfg.Signer, map[string]interface{}{
+			"batch": req.Ref,
 		},
 	)
 
@@ -300,23 +362,19 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		return false, err
 	}
 
-	scannerAddr := pub.cfg.Key.Address.Hex()
-	resp, err := pub.alertClient.PostBatch(&domain.AlertBatchRequest{
-		Scanner:            scannerAddr,
-		ChainID:            int64(batch.ChainId),
-		BlockStart:         int64(batch.BlockStart),
-		BlockEnd:           int64(batch.BlockEnd),
-		AlertCount:         int64(batch.AlertCount),
-		MaxSeverity:        int64(batch.MaxSeverity),
-		Ref:                cid,
-		SignedBatch:        signedBatch,
-		SignedBatchSummary: signedBatchSummary,
-	}, scannerJwt)
+	scannerAddr := req.Scanner
+	postReq := *req
+	postReq.SignedBatchSummary = signedBatchSummary
+	startTime := time.Now()
+	resp, err := pub.alertClient.PostBatch(&postReq, scannerJwt)
 
 	if err != nil {
 		logger.WithError(err).Error("alert while sending batch")
 		return false, fmt.Errorf("failed to send the alert tx: %v", err)
 	}
+	metrics.SendAgentMetrics(pub.messageClient, metrics.GetBatchPublishMetrics(
+		time.Now(), int(req.AlertCount), qb.Bytes, time.Since(startTime),
+	))
 
 	if resp.SignedReceipt != nil {
 		// store off receipt id
@@ -462,15 +520,107 @@ func (pub *Publisher) handleInspectionResults(payload *protocol.InspectionResult
 
 func (pub *Publisher) publishBatches() {
 	for batch := range pub.batchCh {
+		atomic.StoreInt32(&pub.publishing, 1)
 		pub.lastBatchPublishAttempt.Set()
 		published, err := pub.publishNextBatch(batch)
 		if published {
 			pub.lastBatchPublish.Set()
 		}
+		pub.updatePublishKeys(batch, err)
 		pub.lastBatchPublishErr.Set(err)
 		if err != nil {
 			log.Errorf("failed to publish alert batch: %v", err)
 		}
+		if !published && err != nil {
+			pub.bufferMetrics(batch.Metrics)
+		}
+		atomic.StoreInt32(&pub.publishing, 0)
+	}
+}
+
+// updatePublishKeys persists the publish keys of the batch after it is queued or published so that the
+// alerts are not published again. The keys of a batch which failed are forgotten so that the alerts
+// of the retried evaluations are published.
+func (pub *Publisher) updatePublishKeys(batch *protocol.AlertBatch, publishErr error) {
+	if pub.publishKeys == nil {
+		return
+	}
+	keys := batchPublishKeys(batch)
+	if publishErr != nil {
+		pub.publishKeys.Forget(keys)
+		return
+	}
+	if err := pub.publishKeys.Persist(keys); err != nil {
+		log.WithError(err).Warn("failed to persist the publish keys")
+	}
+}
+
+// bufferMetrics retains the metrics of a batch which could not be published.
+func (pub *Publisher) bufferMetrics(allMetrics []*protocol.AgentMetrics) {
+	if pub.metricsBuffer == nil || len(allMetrics) == 0 {
+		return
+	}
+	log.WithField("buckets", len(allMetrics)).Info("buffering the metrics of the unpublished batch")
+	pub.reportDroppedMetrics(pub.metricsBuffer.Add(allMetrics))
+}
+
+func (pub *Publisher) reportDroppedMetrics(dropped int) {
+	if dropped == 0 {
+		return
+	}
+	log.WithField("buckets", dropped).Warn("dropped buffered metrics")
+	metrics.SendAgentMetrics(pub.messageClient, metrics.GetMetricsBufferMetrics(time.Now(), pub.metricsBuffer.Len(), dropped))
+}
+
+func (pub *Publisher) reportDroppedBatches(dropped int) {
+	if dropped == 0 {
+		return
+	}
+	log.WithField("batches", dropped).Warn("dropped queued batches")
+	metrics.SendAgentMetrics(pub.messageClient, metrics.GetBatchQueueMetrics(
+		time.Now(), pub.batchQueue.Len(), pub.batchQueue.OldestAge(), dropped,
+	))
+}
+
+// sendQueuedBatches publishes the queued batches in order and backs off exponentially
+// while the API is unreachable.
+func (pub *Publisher) sendQueuedBatches() {
+	maxBackoff := time.Duration(pub.cfg.PublisherConfig.Queue.MaxBackoffSeconds) * time.Second
+	backoff := minBatchRetryInterval
+	for {
+		qb, dropped := pub.batchQueue.Next()
+		pub.reportDroppedBatches(dropped)
+		if qb == nil {
+			select {
+			case <-pub.ctx.Done():
+				return
+			case <-pub.batchQueue.Ready():
+			}
+			continue
+		}
+
+		published, err := pub.postBatch(qb)
+		pub.lastBatchQueueErr.Set(err)
+		if published {
+			pub.lastBatchPublish.Set()
+			pub.batchQueue.Done(qb)
+			backoff = minBatchRetryInterval
+			continue
+		}
+
+		log.WithError(err).WithFields(log.Fields{
+			"ref":     qb.Request.Ref,
+			"backoff": backoff,
+		}).Warn("failed to publish the queued batch - retrying")
+		select {
+		case <-pub.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
 	}
 }
 
@@ -716,22 +866,38 @@ func (pub *Publisher) prepareLatestBatch() {
 
 	var (
 		timedOut  bool
+		flushed   bool
 		batchTime time.Time
 		i         int
+		size      int
 	)
-	for i < pub.batchLimit {
+	// flush early if the alert limit or the size limit is reached
+	for i < pub.batchLimit && (pub.batchMaxBytes == 0 || size < pub.batchMaxBytes) {
 		select {
 		case notif := <-pub.notifCh:
 			alert := notif.SignedAlert
 			hasAlert := alert != nil
 			if hasAlert {
 				log.WithField("alertId", alert.Alert.Id).Debug("publisher received alert")
+				// the attestation should match what the bot evaluated
+				err := clients.VerifyAttestation(notif)
+				if err != nil {
+					log.WithError(err).WithField("alertId", alert.Alert.Id).Warn("alert attestation mismatch")
+				}
+				pub.lastAttestationErr.Set(err)
+
+				// the retried and the repeated evaluations produce the same publish keys
+				if key := clients.AlertPublishKey(alert.Alert); len(key) > 0 && pub.publishKeys != nil && !pub.publishKeys.Add(key) {
+					log.WithField("publishKey", key).Info("alert is already published - skipping")
+					continue
+				}
 			}
 
 			// Notifications with empty alerts shouldn't be taken into account while limiting the batch.
 			// Otherwise, we create too many batches very quickly.
 			if hasAlert {
 				i++
+				size += proto.Size(alert)
 			}
 
 			var blockNum string
@@ -762,9 +928,12 @@ func (pub *Publisher) prepareLatestBatch() {
 			batch.AppendAlert(notif)
 
 		case batchTime, timedOut = <-pub.batchTicker.C:
+
+		case <-pub.flushCh:
+			flushed = true
 		}
 
-		if timedOut {
+		if timedOut || flushed {
 			break
 		}
 	}
@@ -780,17 +949,70 @@ func (pub *Publisher) prepareLatestBatch() {
 	pub.batchCh <- (*protocol.AlertBatch)(batch)
 }
 
+// publishQueueMetrics reports how many batches and notifications are waiting in the queues.
+func (pub *Publisher) publishQueueMetrics() {
+	ticker := time.NewTicker(defaultQueueMetricsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-pub.ctx.Done():
+			return
+		case t := <-ticker.C:
+			metrics.SendAgentMetrics(pub.messageClient, metrics.GetPublisherQueueMetrics(t, len(pub.batchCh), len(pub.notifCh)))
+			if pub.metricsBuffer != nil {
+				metrics.SendAgentMetrics(pub.messageClient, metrics.GetMetricsBufferMetrics(t, pub.metricsBuffer.Len(), 0))
+			}
+			if pub.batchQueue != nil {
+				metrics.SendAgentMetrics(pub.messageClient, metrics.GetBatchQueueMetrics(t, pub.batchQueue.Len(), pub.batchQueue.OldestAge(), 0))
+			}
+		}
+	}
+}
+
 func (pub *Publisher) Start() error {
 	go pub.prepareBatches()
 	go pub.publishBatches()
+	go pub.publishQueueMetrics()
+	if pub.batchQueue != nil {
+		go pub.sendQueuedBatches()
+	}
+	if pub.findingSink != nil {
+		go pub.findingSink.run()
+	}
 	pub.registerMessageHandlers()
 	return nil
 }
 
+// ShutdownStage implements the services.StagedService interface.
+func (pub *Publisher) ShutdownStage() services.ShutdownStage {
+	return services.StagePublish
+}
+
+// Drain publishes the notifications which were received before the shutdown without waiting
+// for the batch interval. The batches which fail to publish stay in the queue if it is enabled.
+func (pub *Publisher) Drain(ctx context.Context) error {
+	// the latest batch takes the remaining notifications
+	if err := services.WaitIdle(ctx, func() bool {
+		return len(pub.notifCh) == 0
+	}); err != nil {
+		return err
+	}
+	select {
+	case pub.flushCh <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return services.WaitIdle(ctx, func() bool {
+		return len(pub.batchCh) == 0 && atomic.LoadInt32(&pub.publishing) == 0
+	})
+}
+
 func (pub *Publisher) Stop() error {
 	if pub.server != nil {
 		pub.server.Stop()
 	}
+	// keep the metrics which were not flushed yet for after the restart
+	pub.bufferMetrics(pub.metricsAggregator.ForceFlush())
 	return nil
 }
 
@@ -800,7 +1022,7 @@ func (pub *Publisher) Name() string {
 
 // Health implements the health.Reporter interface.
 func (pub *Publisher) Health() health.Reports {
-	return health.Reports{
+	reports := health.Reports{
 		pub.lastBatchPublish.GetReport("event.batch-publish.time"),
 		pub.lastBatchPublishAttempt.GetReport("event.batch-publish-attempt.time"),
 		pub.lastBatchPublishErr.GetReport("event.batch-publish.error"),
@@ -811,14 +1033,34 @@ func (pub *Publisher) Health() health.Reports {
 		},
 		pub.lastBatchSkipReason.GetReport("event.batch-skip.reason"),
 		pub.lastMetricsFlush.GetReport("event.metrics-flush.time"),
+		pub.lastAttestationErr.GetReport("event.attestation.error"),
 	}
+	if pub.batchQueue != nil {
+		reports = append(reports,
+			&health.Report{
+				Name:    "event.batch-queue.depth",
+				Status:  health.StatusInfo,
+				Details: strconv.Itoa(pub.batchQueue.Len()),
+			},
+			&health.Report{
+				Name:    "event.batch-queue.oldest-age",
+				Status:  health.StatusInfo,
+				Details: pub.batchQueue.OldestAge().Round(time.Second).String(),
+			},
+			pub.lastBatchQueueErr.GetReport("event.batch-queue.error"),
+		)
+	}
+	if pub.findingSink != nil {
+		reports = append(reports, pub.findingSink.Health()...)
+	}
+	return reports
 }
 
 func NewPublisher(ctx context.Context, cfg config.Config) (*Publisher, error) {
-	msgClient := messaging.NewClient("metrics", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
+	msgClient := messaging.NewClient("metrics", cfg.NatsURL())
 	lifecycleMetrics := metrics.NewLifecycleClient(msgClient)
 
-	key, err := config.LoadKeyInContainer(cfg)
+	scannerSigner, err := config.LoadSignerInContainer(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -830,7 +1072,7 @@ func NewPublisher(ctx context.Context, cfg config.Config) (*Publisher, error) {
 		releaseSummary = release.MakeSummaryFromReleaseInfo(releaseInfo)
 	}
 
-	apiClient := alertapi.NewClient(cfg.Publish.APIURL)
+	apiClient := alertapi.NewClient(cfg.Publish.APIURL, cfg.Publish.Batch.Compression)
 
 	var storageClient protocol.StorageClient
 	if !cfg.LocalModeConfig.Enable && cfg.AdvancedConfig.IPFSExperiment {
@@ -842,7 +1084,7 @@ func NewPublisher(ctx context.Context, cfg config.Config) (*Publisher, error) {
 
 	return initPublisher(ctx, msgClient, lifecycleMetrics, apiClient, storageClient, PublisherConfig{
 		ChainID:         cfg.ChainID,
-		Key:             key,
+		Signer:          scannerSigner,
 		PublisherConfig: cfg.Publish,
 		ReleaseSummary:  releaseSummary,
 		Config:          cfg,
@@ -890,25 +1132,58 @@ func initPublisher(
 		}
 	}
 
+	var buffer *metricsBuffer
+	if !cfg.PublisherConfig.MetricsBuffer.Disable {
+		buffer = newMetricsBuffer(path.Join(cfg.Config.FortaDir, metricsBufferFileName), cfg.PublisherConfig.MetricsBuffer)
+	}
+
+	// the local mode alerts are sent directly
+	var queue *batchQueue
+	if !cfg.PublisherConfig.Queue.Disable && !cfg.Config.LocalModeConfig.Enable {
+		queue, err = newBatchQueue(path.Join(cfg.Config.FortaDir, batchQueueDirName), cfg.PublisherConfig.Queue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var keys *publishKeys
+	if !cfg.PublisherConfig.PublishKeys.Disable {
+		keys = newPublishKeys(path.Join(cfg.Config.FortaDir, publishKeysFileName), cfg.PublisherConfig.PublishKeys.MaxKeys)
+	}
+
+	var sink *findingSink
+	if cfg.Config.FindingSink.Enable {
+		sink, err = newFindingSink(ctx, cfg.ChainID, cfg.Signer, cfg.Config.FindingSink)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &Publisher{
 		ctx:               ctx,
 		cfg:               cfg,
 		ipfs:              ipfsClient,
 		storage:           storageClient,
 		metricsAggregator: NewMetricsAggregator(time.Duration(*cfg.PublisherConfig.Batch.MetricsBucketIntervalSeconds) * time.Second),
+		metricsBuffer:     buffer,
+		batchQueue:        queue,
+		publishKeys:       keys,
+		findingSink:       sink,
 		messageClient:     mc,
 		alertClient:       alertClient,
 		localAlertClient:  localAlertClient,
 		lifecycleMetrics:  lifecycleMetrics,
-		batchRefStore:     store.NewFileStringStore(path.Join(cfg.Config.FortaDir, ".last-batch")),
-		lastReceiptStore:  store.NewFileStringStore(path.Join(cfg.Config.FortaDir, ".last-receipt")),
+		batchRefStore:     store.NewFileStringStore(path.Join(cfg.Config.FortaDir, config.DefaultLastBatchFileName)),
+		lastReceiptStore:  store.NewFileStringStore(path.Join(cfg.Config.FortaDir, config.DefaultLastReceiptFileName)),
 
 		skipEmpty:     cfg.PublisherConfig.Batch.SkipEmpty,
 		skipPublish:   cfg.PublisherConfig.SkipPublish,
 		batchInterval: batchInterval,
 		batchLimit:    batchLimit,
+		batchMaxBytes: cfg.PublisherConfig.Batch.MaxBytes,
 		notifCh:       make(chan *protocol.NotifyRequest, defaultBatchLimit),
 		batchCh:       make(chan *protocol.AlertBatch, defaultBatchBufferSize),
+		flushCh:       make(chan struct{}),
 
 		batchTicker: time.NewTicker(batchInterval),
 	}, nil
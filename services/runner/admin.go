@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+// handleSupervisorAdmin forwards the admin requests from the local CLI to the supervisor admin server.
+func (runner *Runner) handleSupervisorAdmin(w http.ResponseWriter, r *http.Request) {
+	if !isLoopbackAddr(r.RemoteAddr) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	supervisorContainer, err := runner.globalClient.GetContainerByName(r.Context(), config.DockerSupervisorContainerName)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get the supervisor container: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+	for _, port := range supervisorContainer.Ports {
+		if strconv.Itoa(int(port.PrivatePort)) == config.DefaultSupervisorAdminPort {
+			httputil.NewSingleHostReverseProxy(&url.URL{
+				Scheme: "http",
+				Host:   fmt.Sprintf("127.0.0.1:%d", port.PublicPort),
+			}).ServeHTTP(w, r)
+			return
+		}
+	}
+	http.Error(w, "supervisor admin port is not published", http.StatusServiceUnavailable)
+}
+
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -2,12 +2,76 @@ package runner
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/forta-network/forta-node/profiling"
+	"github.com/forta-network/forta-node/services/scanner"
+	"github.com/forta-network/forta-node/services/supervisor"
+	log "github.com/sirupsen/logrus"
 )
 
+// startHealthServer serves the health reports together with the liveness and readiness
+// endpoints which container orchestrators and watchdogs can use. It also forwards the
+// admin requests of the local CLI to the supervisor.
+func (runner *Runner) startHealthServer() {
+	mux := http.NewServeMux()
+	health.Handle(mux, runner.checkHealth)
+	healthutils.HandleNodeStatus(mux, runner.checkHealth)
+	healthutils.HandleHistory(mux, runner.healthHistory)
+	mux.HandleFunc(supervisor.PathReplay, runner.handleSupervisorAdmin)
+	mux.HandleFunc(supervisor.PathBotErrors, runner.handleSupervisorAdmin)
+	mux.HandleFunc(supervisor.PathBotStats, runner.handleSupervisorAdmin)
+	mux.HandleFunc(supervisor.PathBots, runner.handleSupervisorAdmin)
+	mux.HandleFunc(supervisor.PathBotInspect, runner.handleSupervisorAdmin)
+	mux.HandleFunc(supervisor.PathBotRestart, runner.handleSupervisorAdmin)
+	mux.HandleFunc(supervisor.PathBotStop, runner.handleSupervisorAdmin)
+	mux.HandleFunc(supervisor.PathBotLogs, runner.handleSupervisorAdmin)
+	mux.HandleFunc(supervisor.PathMetrics, runner.handleSupervisorAdmin)
+	mux.HandleFunc(supervisor.PathLogLevels, runner.handleSupervisorAdmin)
+	mux.HandleFunc(supervisor.PathConfigReload, runner.handleSupervisorAdmin)
+	mux.HandleFunc(scanner.PathBackfill, runner.handleSupervisorAdmin)
+	mux.HandleFunc(profiling.PathPprof, runner.handleSupervisorAdmin)
+	mux.HandleFunc(profiling.PathExpvar, runner.handleSupervisorAdmin)
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%s", config.DefaultHealthPort),
+		Handler: mux,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil {
+			healthutils.DefaultHealthServerErrHandler(err)
+		}
+	}()
+	go func() {
+		<-runner.ctx.Done()
+		server.Close()
+	}()
+}
+
+// recordHealthHistory periodically checks the health of the containers and records the transitions.
+func (runner *Runner) recordHealthHistory() {
+	ticker := time.NewTicker(time.Duration(runner.cfg.HealthHistory.CheckIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-runner.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, transition := range runner.healthHistory.Record(runner.checkHealth()) {
+			log.WithFields(log.Fields{
+				"name": transition.Name,
+				"from": transition.From,
+				"to":   transition.To,
+			}).Info("health status changed")
+		}
+	}
+}
+
 func (runner *Runner) checkHealth() (allReports health.Reports) {
 	containers, err := runner.globalClient.GetFortaServiceContainers(runner.ctx)
 	if err != nil {
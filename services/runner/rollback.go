@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const updateHealthCheckInterval = time.Second * 15
+
+// shouldWatchUpdate tells if the node should be rolled back when it fails to become ready after
+// switching to the new supervisor. There is nothing to roll back to before the first supervisor.
+func (runner *Runner) shouldWatchUpdate(latestRefs store.ImageRefs) bool {
+	return !runner.cfg.AutoUpdate.DisableRollback &&
+		len(runner.currentSupervisorImg) > 0 &&
+		latestRefs.Supervisor != runner.currentSupervisorImg
+}
+
+// watchUpdate waits for the node to become ready after an update and rolls back to the
+// previous images if the node does not become ready within the rollback window.
+func (runner *Runner) watchUpdate(previousRefs, updatedRefs store.ImageRefs) {
+	window := time.Duration(runner.cfg.AutoUpdate.RollbackWindowSeconds) * time.Second
+	logger := log.WithFields(log.Fields{
+		"supervisor": updatedRefs.Supervisor,
+		"window":     window,
+	})
+	logger.Info("watching the node health after the update")
+
+	deadline := time.NewTimer(window)
+	defer deadline.Stop()
+	ticker := time.NewTicker(updateHealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-runner.ctx.Done():
+			return
+
+		case <-ticker.C:
+			if healthutils.NodeStatusFromReports(runner.checkHealth()).Ready {
+				logger.Info("node is ready after the update")
+				return
+			}
+
+		case <-deadline.C:
+			runner.rollback(previousRefs, updatedRefs)
+			return
+		}
+	}
+}
+
+func (runner *Runner) rollback(previousRefs, updatedRefs store.ImageRefs) {
+	runner.containerMu.Lock()
+	defer runner.containerMu.Unlock()
+
+	logger := log.WithFields(log.Fields{
+		"supervisor":         previousRefs.Supervisor,
+		"updater":            previousRefs.Updater,
+		"rejectedSupervisor": updatedRefs.Supervisor,
+	})
+
+	// a newer update or a rollback might have replaced the containers already
+	if runner.currentSupervisorImg != updatedRefs.Supervisor {
+		logger.Info("update is superseded - not rolling back")
+		return
+	}
+
+	logger.Warn("node did not become ready after the update - rolling back")
+	runner.rejectedSupervisor = updatedRefs.Supervisor
+	runner.replaceContainers(logger, previousRefs)
+	runner.currentReleaseInfo = previousRefs.ReleaseInfo
+}
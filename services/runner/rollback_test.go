@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldWatchUpdate(t *testing.T) {
+	r := require.New(t)
+
+	runner := &Runner{}
+	r.False(runner.shouldWatchUpdate(store.ImageRefs{Supervisor: "new"}))
+
+	runner.currentSupervisorImg = "old"
+	r.True(runner.shouldWatchUpdate(store.ImageRefs{Supervisor: "new"}))
+	r.False(runner.shouldWatchUpdate(store.ImageRefs{Supervisor: "old"}))
+
+	runner.cfg = config.Config{AutoUpdate: config.AutoUpdateConfig{DisableRollback: true}}
+	r.False(runner.shouldWatchUpdate(store.ImageRefs{Supervisor: "new"}))
+}
+
+func TestRollback_Superseded(t *testing.T) {
+	r := require.New(t)
+
+	runner := &Runner{ctx: context.Background(), currentSupervisorImg: "newer"}
+	runner.rollback(store.ImageRefs{Supervisor: "old"}, store.ImageRefs{Supervisor: "new"})
+
+	r.Equal("newer", runner.currentSupervisorImg)
+	r.Empty(runner.rejectedSupervisor)
+}
+
+func TestUpdateContainers_Rejected(t *testing.T) {
+	r := require.New(t)
+
+	runner := &Runner{ctx: context.Background(), currentSupervisorImg: "old", rejectedSupervisor: "new"}
+	runner.updateContainers(store.ImageRefs{Supervisor: "new"})
+
+	r.Equal("old", runner.currentSupervisorImg)
+}
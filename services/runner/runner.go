@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"fmt"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
@@ -10,10 +11,12 @@ import (
 
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-core-go/release"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/crashreport"
 	"github.com/forta-network/forta-node/healthutils"
 	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/store"
@@ -37,9 +40,13 @@ type Runner struct {
 	supervisorContainer  *docker.Container
 	currentUpdaterImg    string
 	currentSupervisorImg string
+	currentReleaseInfo   *release.ReleaseInfo
+	rejectedSupervisor   string
 	containerMu          sync.RWMutex // protects above refs and containers
 
-	healthClient health.HealthClient
+	healthClient  health.HealthClient
+	healthHistory *healthutils.History
+	crashReports  *crashreport.Reporter
 }
 
 // EthereumClient is useful for checking the JSON-RPC API.
@@ -59,6 +66,10 @@ func NewRunner(ctx context.Context, cfg config.Config,
 		dockerClient: runnerDockerClient,
 		globalClient: globalDockerClient,
 		healthClient: health.NewClient(),
+		healthHistory: healthutils.NewHistory(
+			path.Join(cfg.FortaDir, config.DefaultHealthHistoryFileName), cfg.HealthHistory.MaxTransitions,
+		),
+		crashReports: crashreport.NewReporter(cfg),
 	}
 }
 
@@ -73,7 +84,7 @@ func (runner *Runner) Start() error {
 		return fmt.Errorf("failed to nuke leftover containers at start: %v", err)
 	}
 
-	health.StartServer(runner.ctx, "", healthutils.DefaultHealthServerErrHandler, runner.checkHealth)
+	runner.startHealthServer()
 
 	if runner.cfg.AutoUpdate.Disable {
 		runner.startEmbeddedSupervisor()
@@ -83,6 +94,8 @@ func (runner *Runner) Start() error {
 	}
 
 	go runner.keepContainersAlive()
+	go runner.recordHealthHistory()
+	go runner.notifySystemd()
 
 	return nil
 }
@@ -133,6 +146,11 @@ func (runner *Runner) doStartUpCheck() error {
 	return nil
 }
 
+// hostFortaDir returns the forta dir path which the Docker daemon can mount to the containers.
+func (runner *Runner) hostFortaDir() string {
+	return runner.cfg.Dev.DockerDesktop.HostPath(runner.cfg.FortaDir)
+}
+
 func (runner *Runner) fixTestRpcUrl(rawurl string) string {
 	return strings.ReplaceAll(rawurl, "host.docker.internal", "localhost")
 }
@@ -210,6 +228,38 @@ func (runner *Runner) updateContainers(latestRefs store.ImageRefs) {
 		})
 	}
 	logger.Info("detected new images")
+	if latestRefs.Supervisor == runner.rejectedSupervisor {
+		logger.Warn("ignoring the release which was rolled back")
+		return
+	}
+
+	// the health before the update tells if the new version can be blamed for failing
+	var (
+		previousRefs *store.ImageRefs
+		snapshot     *healthutils.NodeStatus
+	)
+	if runner.shouldWatchUpdate(latestRefs) {
+		previousRefs = &store.ImageRefs{
+			Supervisor:  runner.currentSupervisorImg,
+			Updater:     runner.currentUpdaterImg,
+			ReleaseInfo: runner.currentReleaseInfo,
+		}
+		snapshot = healthutils.NodeStatusFromReports(runner.checkHealth())
+	}
+
+	runner.replaceContainers(logger, latestRefs)
+	runner.currentReleaseInfo = latestRefs.ReleaseInfo
+
+	switch {
+	case snapshot == nil:
+	case snapshot.Ready:
+		go runner.watchUpdate(*previousRefs, latestRefs)
+	default:
+		logger.WithField("reasons", snapshot.NotReadyReasons).Info("node was not ready before the update - not watching for rollback")
+	}
+}
+
+func (runner *Runner) replaceContainers(logger *log.Entry, latestRefs store.ImageRefs) {
 	if latestRefs.Updater != runner.currentUpdaterImg {
 		if err := runner.replaceUpdater(logger, latestRefs); err != nil {
 			logger.WithError(err).Panic("error replacing updater")
@@ -276,6 +326,12 @@ func (runner *Runner) replaceUpdater(logger *log.Entry, imageRefs store.ImageRef
 
 func (runner *Runner) replaceSupervisor(logger *log.Entry, imageRefs store.ImageRefs) error {
 	logger.Info("replacing supervisor")
+	// the running supervisor leaves the service containers to the new one
+	if runner.supervisorContainer != nil && runner.cfg.AdvancedConfig.BlueGreenUpgrades {
+		if err := store.MarkUpgradeHandoff(runner.cfg.FortaDir); err != nil {
+			logger.WithError(err).Warn("failed to mark the upgrade handoff")
+		}
+	}
 	err := runner.removeContainer(runner.supervisorContainer)
 	if err != nil {
 		return err
@@ -294,12 +350,12 @@ func (runner *Runner) startUpdater(logger *log.Entry, latestRefs store.ImageRefs
 		Name:  config.DockerUpdaterContainerName,
 		Image: updaterRef,
 		Cmd:   []string{config.DefaultFortaNodeBinaryPath, "updater"},
-		Env: map[string]string{
+		Env: runner.cfg.ContainerEnv(map[string]string{
 			config.EnvDevelopment: strconv.FormatBool(runner.cfg.Development),
 			config.EnvReleaseInfo: latestRefs.ReleaseInfo.String(),
-		},
+		}),
 		Volumes: map[string]string{
-			runner.cfg.FortaDir: config.DefaultContainerFortaDirPath,
+			runner.hostFortaDir(): config.DefaultContainerFortaDirPath,
 		},
 		Ports: map[string]string{
 			config.DefaultContainerPort: config.DefaultContainerPort,
@@ -332,18 +388,19 @@ func (runner *Runner) startSupervisor(logger *log.Entry, latestRefs store.ImageR
 		Name:  config.DockerSupervisorContainerName,
 		Image: supervisorRef,
 		Cmd:   []string{config.DefaultFortaNodeBinaryPath, "supervisor"},
-		Env: map[string]string{
+		Env: runner.cfg.ContainerEnv(map[string]string{
 			// supervisor needs to know and mount the forta dir on the host os
-			config.EnvHostFortaDir: runner.cfg.FortaDir,
+			config.EnvHostFortaDir: runner.hostFortaDir(),
 			config.EnvReleaseInfo:  latestRefs.ReleaseInfo.String(),
-		},
+		}),
 		Volumes: map[string]string{
-			// give access to host docker
+			// give access to host docker, which is the socket in the VM with Docker Desktop
 			"/var/run/docker.sock": "/var/run/docker.sock",
-			runner.cfg.FortaDir:    config.DefaultContainerFortaDirPath,
+			runner.hostFortaDir():  config.DefaultContainerFortaDirPath,
 		},
 		Ports: map[string]string{
-			"": config.DefaultHealthPort, // random host port
+			"":           config.DefaultHealthPort,          // random host port
+			"127.0.0.1:": config.DefaultSupervisorAdminPort, // random local host port
 		},
 		Files: map[string][]byte{
 			"passphrase": []byte(runner.cfg.Passphrase),
@@ -396,6 +453,9 @@ func (runner *Runner) doKeepContainersAlive() error {
 				services.TriggerExit(0)
 				return nil
 			}
+			runner.crashReports.CaptureContainer(
+				runner.ctx, runner.dockerClient, runner.supervisorContainer.Name, container.ID, containerDetails.State.StartedAt,
+			)
 			runner.dockerClient.StartContainer(runner.ctx, runner.supervisorContainer.Config)
 		}
 	}
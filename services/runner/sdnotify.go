@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/daemon"
+	"github.com/forta-network/forta-node/healthutils"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultSystemdNotifyInterval = time.Second * 10
+
+// notifySystemd tells systemd that the node is ready and keeps the watchdog from restarting
+// the node while it is live. It does nothing if the node is not run by a systemd unit.
+func (runner *Runner) notifySystemd() {
+	if len(os.Getenv("NOTIFY_SOCKET")) == 0 {
+		return
+	}
+	watchdogInterval, err := daemon.SdWatchdogEnabled(false)
+	if err != nil {
+		log.WithError(err).Warn("failed to check the systemd watchdog")
+	}
+	interval := defaultSystemdNotifyInterval
+	if watchdogInterval > 0 {
+		// ping twice as often as the watchdog expects
+		interval = watchdogInterval / 2
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var notifiedReady bool
+	for {
+		select {
+		case <-runner.ctx.Done():
+			daemon.SdNotify(false, daemon.SdNotifyStopping)
+			return
+		case <-ticker.C:
+		}
+
+		status := healthutils.NodeStatusFromReports(runner.checkHealth())
+		states := []string{sdStatus(status)}
+		if status.Ready && !notifiedReady {
+			states = append(states, daemon.SdNotifyReady)
+			notifiedReady = true
+		}
+		if status.Live && watchdogInterval > 0 {
+			states = append(states, daemon.SdNotifyWatchdog)
+		}
+		if _, err := daemon.SdNotify(false, strings.Join(states, "\n")); err != nil {
+			log.WithError(err).Warn("failed to notify systemd")
+		}
+	}
+}
+
+func sdStatus(status *healthutils.NodeStatus) string {
+	switch {
+	case status.Ready:
+		return "STATUS=ready"
+	case len(status.NotReadyReasons) > 0:
+		return fmt.Sprintf("STATUS=not ready: %s", status.NotReadyReasons[0])
+	default:
+		return "STATUS=not ready"
+	}
+}
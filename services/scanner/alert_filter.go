@@ -0,0 +1,192 @@
+package scanner
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/ratelimiter"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// Dedup key fields
+const (
+	AlertKeyBot      = "bot"
+	AlertKeyAlertID  = "alertId"
+	AlertKeyEntity   = "entity"
+	AlertKeyName     = "name"
+	AlertKeySeverity = "severity"
+)
+
+// AlertFilter suppresses the duplicate alerts and the alerts which exceed the bot quotas
+// before they are signed and published.
+type AlertFilter struct {
+	cfg         config.AlertFilterConfig
+	sender      clients.AlertSender
+	msgClient   clients.MessageClient
+	rateLimiter ratelimiter.RateLimiter
+	window      time.Duration
+
+	seen      map[string]time.Time
+	lastPrune time.Time
+	mu        sync.Mutex
+
+	duplicates    health.MessageTracker
+	quotaExceeded health.MessageTracker
+}
+
+var _ clients.AlertSender = &AlertFilter{}
+
+// NewAlertFilter creates a new alert filter which forwards the alerts to the given sender.
+func NewAlertFilter(sender clients.AlertSender, msgClient clients.MessageClient, cfg config.AlertFilterConfig) *AlertFilter {
+	// the bots without a quota are not limited if only the per-bot quotas are configured
+	var rateLimiter ratelimiter.RateLimiter
+	switch {
+	case cfg.Quota != nil:
+		rateLimiter = ratelimiter.NewRateLimiter(cfg.Quota.Rate, cfg.Quota.Burst)
+	case len(cfg.BotQuotas) > 0:
+		rateLimiter = ratelimiter.NewRateLimiter(math.Inf(1), 1)
+	}
+
+	return &AlertFilter{
+		cfg:         cfg,
+		sender:      sender,
+		msgClient:   msgClient,
+		rateLimiter: rateLimiter,
+		window:      time.Duration(cfg.Dedup.WindowSeconds) * time.Second,
+		seen:        make(map[string]time.Time),
+		lastPrune:   time.Now(),
+	}
+}
+
+// SignAlertAndNotify forwards the alert unless it is a duplicate or the bot exceeded its quota.
+func (af *AlertFilter) SignAlertAndNotify(rt *clients.AgentRoundTrip, alert *protocol.Alert, chainID, blockNumber string, ts *domain.TrackingTimestamps) error {
+	botID := rt.AgentConfig.ID
+	logger := log.WithFields(log.Fields{
+		"bot":   botID,
+		"alert": alert.Id,
+	})
+
+	if af.isDuplicate(botID, alert) {
+		logger.Debug("duplicate alert - suppressing")
+		af.duplicates.Set(botID)
+		metrics.SendAgentMetrics(af.msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(botID, metrics.MetricFindingsDuplicate, 1),
+		})
+		return nil
+	}
+
+	if af.exceedsQuota(botID) {
+		logger.Debug("bot alert quota exceeded - suppressing")
+		af.quotaExceeded.Set(botID)
+		metrics.SendAgentMetrics(af.msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(botID, metrics.MetricFindingsQuotaExceeded, 1),
+		})
+		return nil
+	}
+
+	return af.sender.SignAlertAndNotify(rt, alert, chainID, blockNumber, ts)
+}
+
+// NotifyWithoutAlert forwards the notification.
+func (af *AlertFilter) NotifyWithoutAlert(rt *clients.AgentRoundTrip, ts *domain.TrackingTimestamps) error {
+	return af.sender.NotifyWithoutAlert(rt, ts)
+}
+
+// isDuplicate tells if an alert with the same key was seen within the dedup window.
+func (af *AlertFilter) isDuplicate(botID string, alert *protocol.Alert) bool {
+	if !af.cfg.Dedup.Enable || alert.Finding == nil {
+		return false
+	}
+	key := af.dedupKey(botID, alert.Finding)
+	now := time.Now()
+
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	if now.Sub(af.lastPrune) > af.window {
+		for seenKey, expiresAt := range af.seen {
+			if now.After(expiresAt) {
+				delete(af.seen, seenKey)
+			}
+		}
+		af.lastPrune = now
+	}
+
+	if expiresAt, ok := af.seen[key]; ok && now.Before(expiresAt) {
+		return true
+	}
+	af.seen[key] = now.Add(af.window)
+	return false
+}
+
+func (af *AlertFilter) dedupKey(botID string, finding *protocol.Finding) string {
+	var parts []string
+	for _, field := range af.cfg.Dedup.KeyFields {
+		switch field {
+		case AlertKeyBot:
+			parts = append(parts, strings.ToLower(botID))
+		case AlertKeyAlertID:
+			parts = append(parts, finding.AlertId)
+		case AlertKeyEntity:
+			parts = append(parts, findingEntity(finding))
+		case AlertKeyName:
+			parts = append(parts, finding.Name)
+		case AlertKeySeverity:
+			parts = append(parts, finding.Severity.String())
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// findingEntity returns the entities of the finding labels or the addresses if there are no labels.
+func findingEntity(finding *protocol.Finding) string {
+	var entities []string
+	for _, label := range finding.Labels {
+		if label != nil && len(label.Entity) > 0 {
+			entities = append(entities, strings.ToLower(label.Entity))
+		}
+	}
+	if len(entities) == 0 {
+		for _, addr := range finding.Addresses {
+			entities = append(entities, strings.ToLower(addr))
+		}
+	}
+	sort.Strings(entities)
+	return strings.Join(entities, ",")
+}
+
+// exceedsQuota tells if the bot emitted too many alerts.
+func (af *AlertFilter) exceedsQuota(botID string) bool {
+	if af.rateLimiter == nil {
+		return false
+	}
+	for limitedBotID, quota := range af.cfg.BotQuotas {
+		if strings.EqualFold(limitedBotID, botID) {
+			af.rateLimiter.SetClientLimit(botID, quota.Rate, quota.Burst)
+			break
+		}
+	}
+	return af.rateLimiter.ExceedsLimit(botID)
+}
+
+// Name returns the name of the service.
+func (af *AlertFilter) Name() string {
+	return "alert-filter"
+}
+
+// Health implements the health.Reporter interface.
+func (af *AlertFilter) Health() health.Reports {
+	return health.Reports{
+		af.duplicates.GetReport("event.alert-filter.duplicate"),
+		af.quotaExceeded.GetReport("event.alert-filter.quota-exceeded"),
+	}
+}
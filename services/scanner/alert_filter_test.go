@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testAlertSender struct {
+	alerts []*protocol.Alert
+}
+
+func (as *testAlertSender) SignAlertAndNotify(rt *clients.AgentRoundTrip, alert *protocol.Alert, chainID, blockNumber string, ts *domain.TrackingTimestamps) error {
+	as.alerts = append(as.alerts, alert)
+	return nil
+}
+
+func (as *testAlertSender) NotifyWithoutAlert(rt *clients.AgentRoundTrip, ts *domain.TrackingTimestamps) error {
+	return nil
+}
+
+func testFilteredAlert(alertID string, addresses ...string) *protocol.Alert {
+	return &protocol.Alert{
+		Id:      "0xalert",
+		Finding: &protocol.Finding{AlertId: alertID, Addresses: addresses},
+	}
+}
+
+func testRoundTrip(botID string) *clients.AgentRoundTrip {
+	return &clients.AgentRoundTrip{AgentConfig: config.AgentConfig{ID: botID}}
+}
+
+func TestAlertFilter_Dedup(t *testing.T) {
+	r := require.New(t)
+
+	msgClient := mock_clients.NewMockMessageClient(gomock.NewController(t))
+	sender := &testAlertSender{}
+	af := NewAlertFilter(sender, msgClient, config.AlertFilterConfig{
+		Dedup: config.AlertDedupConfig{
+			Enable:        true,
+			KeyFields:     []string{AlertKeyBot, AlertKeyAlertID, AlertKeyEntity},
+			WindowSeconds: 60,
+		},
+	})
+
+	// the duplicates are suppressed and counted
+	msgClient.EXPECT().PublishProto(gomock.Any(), gomock.Any()).Times(2)
+
+	r.NoError(af.SignAlertAndNotify(testRoundTrip("0xbot"), testFilteredAlert("ALERT-1", "0xa", "0xb"), "1", "1", nil))
+	r.NoError(af.SignAlertAndNotify(testRoundTrip("0xbot"), testFilteredAlert("ALERT-1", "0xB", "0xA"), "1", "1", nil))
+	r.NoError(af.SignAlertAndNotify(testRoundTrip("0xBOT"), testFilteredAlert("ALERT-1", "0xa", "0xb"), "1", "1", nil))
+	r.NoError(af.SignAlertAndNotify(testRoundTrip("0xbot"), testFilteredAlert("ALERT-1", "0xc"), "1", "1", nil))
+	r.NoError(af.SignAlertAndNotify(testRoundTrip("0xbot"), testFilteredAlert("ALERT-2", "0xa", "0xb"), "1", "1", nil))
+	r.NoError(af.SignAlertAndNotify(testRoundTrip("0xother"), testFilteredAlert("ALERT-1", "0xa", "0xb"), "1", "1", nil))
+	r.Len(sender.alerts, 4)
+}
+
+func TestAlertFilter_Quota(t *testing.T) {
+	r := require.New(t)
+
+	msgClient := mock_clients.NewMockMessageClient(gomock.NewController(t))
+	sender := &testAlertSender{}
+	af := NewAlertFilter(sender, msgClient, config.AlertFilterConfig{
+		BotQuotas: map[string]config.RateLimitConfig{
+			"0xLIMITED": {Rate: 0.001, Burst: 1},
+		},
+	})
+
+	// only the bot with a quota is limited
+	msgClient.EXPECT().PublishProto(gomock.Any(), gomock.Any()).Times(2)
+
+	for i := 0; i < 3; i++ {
+		r.NoError(af.SignAlertAndNotify(testRoundTrip("0xlimited"), testFilteredAlert("ALERT-1"), "1", "1", nil))
+		r.NoError(af.SignAlertAndNotify(testRoundTrip("0xbot"), testFilteredAlert("ALERT-1"), "1", "1", nil))
+	}
+	r.Len(sender.alerts, 4)
+}
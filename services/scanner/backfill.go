@@ -0,0 +1,359 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// PathBackfill is the admin endpoint which starts, shows and cancels the backfill.
+const PathBackfill = "/backfill"
+
+const (
+	defaultBackfillBlocksPerSecond = 2
+	backfillRequestIDPrefix        = "backfill-"
+)
+
+// Backfill states
+const (
+	BackfillStateRunning   = "running"
+	BackfillStateDone      = "done"
+	BackfillStateFailed    = "failed"
+	BackfillStateCancelled = "cancelled"
+)
+
+// BackfillRequest selects the historical block range to scan through the running bots.
+type BackfillRequest struct {
+	StartBlock      uint64  `json:"startBlock"`
+	EndBlock        uint64  `json:"endBlock"`
+	BlocksPerSecond float64 `json:"blocksPerSecond,omitempty"`
+	// NoPublish keeps the findings local instead of publishing them to the network.
+	NoPublish bool `json:"noPublish,omitempty"`
+}
+
+// Validate validates the backfill request and sets the defaults.
+func (req *BackfillRequest) Validate() error {
+	if req.StartBlock == 0 || req.EndBlock == 0 {
+		return errors.New("the start and the end blocks are required")
+	}
+	if req.EndBlock < req.StartBlock {
+		return errors.New("the end block is before the start block")
+	}
+	if req.BlocksPerSecond < 0 {
+		return errors.New("the rate should be positive")
+	}
+	if req.BlocksPerSecond == 0 {
+		req.BlocksPerSecond = defaultBackfillBlocksPerSecond
+	}
+	return nil
+}
+
+// BackfillStatus is the progress of a backfill.
+type BackfillStatus struct {
+	ID              string           `json:"id"`
+	State           string           `json:"state"`
+	Request         *BackfillRequest `json:"request"`
+	CurrentBlock    uint64           `json:"currentBlock,omitempty"`
+	ProcessedBlocks uint64           `json:"processedBlocks"`
+	TotalBlocks     uint64           `json:"totalBlocks"`
+	Transactions    uint64           `json:"transactions"`
+	Findings        uint64           `json:"findings"`
+	StartedAt       time.Time        `json:"startedAt"`
+	FinishedAt      *time.Time       `json:"finishedAt,omitempty"`
+	Error           string           `json:"error,omitempty"`
+}
+
+// BackfillServiceConfig contains the dependencies of the backfill service.
+type BackfillServiceConfig struct {
+	EthClient   ethereum.Client
+	TraceClient ethereum.Client
+	Sender      botio.Sender
+	ChainID     *big.Int
+	Tracing     bool
+}
+
+// BackfillService scans a historical block range through the running bots at a limited
+// rate. It uses a separate block feed so that the live scanning is not affected.
+type BackfillService struct {
+	ctx    context.Context
+	cfg    BackfillServiceConfig
+	server *http.Server
+
+	status *BackfillStatus
+	cancel context.CancelFunc
+	mu     sync.Mutex
+}
+
+// NewBackfillService creates a new backfill service.
+func NewBackfillService(ctx context.Context, cfg BackfillServiceConfig) *BackfillService {
+	return &BackfillService{
+		ctx: ctx,
+		cfg: cfg,
+	}
+}
+
+// Start starts serving the backfill API.
+func (bs *BackfillService) Start() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathBackfill, bs.handleBackfill)
+	bs.server = &http.Server{
+		Addr:    ":" + config.DefaultScannerAdminPort,
+		Handler: mux,
+	}
+	utils.GoListenAndServe(bs.server)
+	return nil
+}
+
+// ShutdownStage implements the services.StagedService interface.
+func (bs *BackfillService) ShutdownStage() services.ShutdownStage {
+	return services.StageIntake
+}
+
+// Stop stops the service.
+func (bs *BackfillService) Stop() error {
+	bs.mu.Lock()
+	if bs.cancel != nil {
+		bs.cancel()
+	}
+	bs.mu.Unlock()
+	if bs.server != nil {
+		return bs.server.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (bs *BackfillService) Name() string {
+	return "backfill"
+}
+
+// Status returns the progress of the last backfill.
+func (bs *BackfillService) Status() *BackfillStatus {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.status == nil {
+		return nil
+	}
+	status := *bs.status
+	return &status
+}
+
+// StartBackfill starts scanning the block range if there is no other backfill running.
+func (bs *BackfillService) StartBackfill(req *BackfillRequest) (*BackfillStatus, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.status != nil && bs.status.State == BackfillStateRunning {
+		return nil, fmt.Errorf("backfill %s is already running", bs.status.ID)
+	}
+
+	ctx, cancel := context.WithCancel(bs.ctx)
+	rateLimit := time.NewTicker(time.Duration(float64(time.Second) / req.BlocksPerSecond))
+	blockFeed, err := feeds.NewBlockFeed(ctx, bs.cfg.EthClient, bs.cfg.TraceClient, feeds.BlockFeedConfig{
+		ChainID:   bs.cfg.ChainID,
+		Tracing:   bs.cfg.Tracing,
+		RateLimit: rateLimit,
+		Start:     new(big.Int).SetUint64(req.StartBlock),
+		End:       new(big.Int).SetUint64(req.EndBlock),
+	})
+	if err != nil {
+		cancel()
+		rateLimit.Stop()
+		return nil, fmt.Errorf("failed to create the block feed: %v", err)
+	}
+	txFeed, err := feeds.NewTransactionFeed(ctx, bs.cfg.EthClient, blockFeed, nil, 10)
+	if err != nil {
+		cancel()
+		rateLimit.Stop()
+		return nil, fmt.Errorf("failed to create the tx feed: %v", err)
+	}
+
+	bs.status = &BackfillStatus{
+		ID:          uuid.Must(uuid.NewUUID()).String(),
+		State:       BackfillStateRunning,
+		Request:     req,
+		TotalBlocks: req.EndBlock - req.StartBlock + 1,
+		StartedAt:   time.Now().UTC(),
+	}
+	bs.cancel = cancel
+	jobID := bs.status.ID
+
+	go func() {
+		defer rateLimit.Stop()
+		err := txFeed.ForEachTransaction(
+			func(evt *domain.BlockEvent) error {
+				return bs.sendBlock(ctx, jobID, evt)
+			},
+			func(evt *domain.TransactionEvent) error {
+				return bs.sendTx(ctx, jobID, evt)
+			},
+		)
+		bs.finish(jobID, err)
+	}()
+	blockFeed.Start()
+
+	log.WithFields(log.Fields{
+		"backfill":   jobID,
+		"startBlock": req.StartBlock,
+		"endBlock":   req.EndBlock,
+		"noPublish":  req.NoPublish,
+	}).Info("started backfill")
+	status := *bs.status
+	return &status, nil
+}
+
+// CancelBackfill cancels the running backfill.
+func (bs *BackfillService) CancelBackfill() error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.status == nil || bs.status.State != BackfillStateRunning {
+		return errors.New("no backfill is running")
+	}
+	bs.status.State = BackfillStateCancelled
+	bs.cancel()
+	return nil
+}
+
+func (bs *BackfillService) finish(jobID string, err error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.status == nil || bs.status.ID != jobID {
+		return
+	}
+	now := time.Now().UTC()
+	bs.status.FinishedAt = &now
+	switch {
+	case bs.status.State == BackfillStateCancelled:
+	case err != nil && err != context.Canceled:
+		bs.status.State = BackfillStateFailed
+		bs.status.Error = err.Error()
+	default:
+		bs.status.State = BackfillStateDone
+	}
+	log.WithFields(log.Fields{
+		"backfill": jobID,
+		"state":    bs.status.State,
+	}).Info("backfill finished")
+}
+
+func (bs *BackfillService) sendBlock(ctx context.Context, jobID string, evt *domain.BlockEvent) error {
+	blockEvt, err := evt.ToMessage()
+	if err != nil {
+		log.WithError(err).Error("error converting backfill block event to message (skipping)")
+		return nil
+	}
+	blockNum, _ := parseHexUint(evt.Block.Number)
+	bs.update(jobID, func(status *BackfillStatus) {
+		status.CurrentBlock = blockNum
+		status.ProcessedBlocks++
+	})
+	bs.cfg.Sender.SendEvaluateBlockRequest(ctx, &protocol.EvaluateBlockRequest{
+		RequestId: backfillRequestID(jobID),
+		Event:     blockEvt,
+	})
+	return nil
+}
+
+func (bs *BackfillService) sendTx(ctx context.Context, jobID string, evt *domain.TransactionEvent) error {
+	txEvt, err := evt.ToMessage()
+	if err != nil {
+		log.WithError(err).Error("error converting backfill tx event to message (skipping)")
+		return nil
+	}
+	bs.update(jobID, func(status *BackfillStatus) {
+		status.Transactions++
+	})
+	bs.cfg.Sender.SendEvaluateTxRequest(ctx, &protocol.EvaluateTxRequest{
+		RequestId: backfillRequestID(jobID),
+		Event:     txEvt,
+	})
+	return nil
+}
+
+func (bs *BackfillService) update(jobID string, updateFn func(status *BackfillStatus)) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.status != nil && bs.status.ID == jobID {
+		updateFn(bs.status)
+	}
+}
+
+func backfillRequestID(jobID string) string {
+	return fmt.Sprintf("%s%s-%s", backfillRequestIDPrefix, jobID, uuid.Must(uuid.NewUUID()))
+}
+
+// ShouldPublish counts the findings of the backfill results and tells if the findings
+// should be published to the network. The live scanning results are always published.
+func (bs *BackfillService) ShouldPublish(requestID string, findings int) bool {
+	if bs == nil || !strings.HasPrefix(requestID, backfillRequestIDPrefix) {
+		return true
+	}
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.status == nil || !strings.HasPrefix(requestID, backfillRequestIDPrefix+bs.status.ID+"-") {
+		// a result of an earlier backfill
+		return false
+	}
+	bs.status.Findings += uint64(findings)
+	return !bs.status.Request.NoPublish
+}
+
+func (bs *BackfillService) handleBackfill(w http.ResponseWriter, r *http.Request) {
+	var (
+		status *BackfillStatus
+		err    error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		status = bs.Status()
+		if status == nil {
+			http.Error(w, "no backfill yet", http.StatusNotFound)
+			return
+		}
+
+	case http.MethodPost:
+		var req BackfillRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode the backfill request: %v", err), http.StatusBadRequest)
+			return
+		}
+		status, err = bs.StartBackfill(&req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+	case http.MethodDelete:
+		if err := bs.CancelBackfill(); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		status = bs.Status()
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
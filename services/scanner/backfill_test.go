@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillRequestValidate(t *testing.T) {
+	r := require.New(t)
+
+	r.Error((&BackfillRequest{StartBlock: 10}).Validate())
+	r.Error((&BackfillRequest{StartBlock: 10, EndBlock: 9}).Validate())
+	r.Error((&BackfillRequest{StartBlock: 10, EndBlock: 20, BlocksPerSecond: -1}).Validate())
+
+	req := &BackfillRequest{StartBlock: 10, EndBlock: 10}
+	r.NoError(req.Validate())
+	r.Equal(float64(defaultBackfillBlocksPerSecond), req.BlocksPerSecond)
+}
+
+func TestBackfillShouldPublish(t *testing.T) {
+	r := require.New(t)
+
+	// the live scanning results are published with or without the backfill
+	var noBackfill *BackfillService
+	r.True(noBackfill.ShouldPublish("some-request", 1))
+
+	bs := &BackfillService{
+		status: &BackfillStatus{
+			ID:      "job1",
+			State:   BackfillStateRunning,
+			Request: &BackfillRequest{StartBlock: 1, EndBlock: 2, NoPublish: true},
+		},
+	}
+	r.True(bs.ShouldPublish("some-request", 1))
+	r.False(bs.ShouldPublish(backfillRequestID("job1"), 2))
+	r.False(bs.ShouldPublish(backfillRequestID("job1"), 1))
+	r.EqualValues(3, bs.Status().Findings)
+
+	// the late results of an earlier backfill are not published
+	r.False(bs.ShouldPublish(backfillRequestID("job0"), 1))
+	r.EqualValues(3, bs.Status().Findings)
+
+	bs.status.Request.NoPublish = false
+	r.True(bs.ShouldPublish(backfillRequestID("job1"), 1))
+}
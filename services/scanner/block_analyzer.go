@@ -9,13 +9,17 @@ import (
 	"github.com/forta-network/forta-core-go/protocol/alerthash"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/tracing"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/clients"
@@ -29,12 +33,15 @@ type BlockAnalyzerService struct {
 
 	lastInputActivity  health.TimeTracker
 	lastOutputActivity health.TimeTracker
+	handlingResult     int32
 }
 
 type BlockAnalyzerServiceConfig struct {
 	BlockChannel <-chan *domain.BlockEvent
 	AlertSender  clients.AlertSender
 	MsgClient    clients.MessageClient
+	// Backfill is optional and decides if the backfill findings are published.
+	Backfill *BackfillService
 	components.BotProcessing
 }
 
@@ -105,7 +112,11 @@ func (t *BlockAnalyzerService) createBloomFilter(finding *protocol.Finding) (blo
 func (t *BlockAnalyzerService) Start() error {
 	// Gear 2: receive result from agent
 	go func() {
-		for result := range t.cfg.Results.Block {
+		for {
+			result, ok := receiveResult(t.cfg.Results.Block, &t.handlingResult)
+			if !ok {
+				return
+			}
 			ts := time.Now().UTC()
 
 			m := jsonpb.Marshaler{}
@@ -120,6 +131,14 @@ func (t *BlockAnalyzerService) Start() error {
 				AgentConfig:       result.AgentConfig,
 				EvalBlockRequest:  result.Request,
 				EvalBlockResponse: result.Response,
+				SpanContext:       result.SpanContext,
+				EvaluationID:      result.EvaluationID,
+			}
+
+			if !t.cfg.Backfill.ShouldPublish(result.Request.RequestId, len(result.Response.Findings)) {
+				t.publishMetrics(result)
+				t.lastOutputActivity.Set()
+				continue
 			}
 
 			if len(result.Response.Findings) == 0 {
@@ -159,12 +178,19 @@ func (t *BlockAnalyzerService) Start() error {
 				continue
 			}
 
+			if block.Timestamps != nil {
+				now := time.Now()
+				metrics.SendAgentMetrics(t.cfg.MsgClient, metrics.GetFeedMetrics(now, now.Sub(block.Timestamps.Block)))
+			}
+
 			// create a request
 			requestId := uuid.Must(uuid.NewUUID())
 			request := &protocol.EvaluateBlockRequest{RequestId: requestId.String(), Event: blockEvt}
 
 			// forward to the pool
-			t.cfg.RequestSender.SendEvaluateBlockRequest(request)
+			ctx, span := tracing.Tracer().Start(t.ctx, tracing.SpanBlockReceived, trace.WithAttributes(attribute.String("block.number", blockEvt.BlockNumber)))
+			t.cfg.RequestSender.SendEvaluateBlockRequest(ctx, request)
+			span.End()
 
 			t.lastInputActivity.Set()
 		}
@@ -173,6 +199,18 @@ func (t *BlockAnalyzerService) Start() error {
 	return nil
 }
 
+// ShutdownStage implements the services.StagedService interface.
+func (t *BlockAnalyzerService) ShutdownStage() services.ShutdownStage {
+	return services.StageEvaluations
+}
+
+// Drain implements the services.Drainer interface.
+func (t *BlockAnalyzerService) Drain(ctx context.Context) error {
+	return drainResults(ctx, t.cfg.RequestSender, func() int {
+		return len(t.cfg.Results.Block)
+	}, &t.handlingResult)
+}
+
 func (t *BlockAnalyzerService) Stop() error {
 	return nil
 }
@@ -0,0 +1,309 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxBlockData = 64
+
+	// errCodeNotFound tells the proxy that the data is not available locally.
+	errCodeNotFound = -32001
+)
+
+type blockDataRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type blockDataError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type blockDataResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *blockDataError `json:"error,omitempty"`
+}
+
+type logFilter struct {
+	BlockHash *string           `json:"blockHash"`
+	FromBlock *string           `json:"fromBlock"`
+	ToBlock   *string           `json:"toBlock"`
+	Address   json.RawMessage   `json:"address"`
+	Topics    []json.RawMessage `json:"topics"`
+}
+
+type blockData struct {
+	hash string
+	logs []domain.LogEntry
+}
+
+// BlockDataService keeps the logs of the recently scanned blocks in memory and serves them
+// over a small JSON-RPC API so that the JSON-RPC proxy can answer the bot requests
+// without going to the upstream. The blocks, the transactions and the receipts are not served
+// since the block feed decodes them into types which drop some of the upstream fields.
+type BlockDataService struct {
+	ctx       context.Context
+	feed      feeds.BlockFeed
+	maxBlocks int
+	server    *http.Server
+
+	blocks map[uint64]*blockData
+	byHash map[string]uint64
+	oldest uint64
+	latest uint64
+	mu     sync.RWMutex
+}
+
+// NewBlockDataService creates a new block data service.
+func NewBlockDataService(ctx context.Context, feed feeds.BlockFeed, cfg config.JsonRpcLocalDataConfig) *BlockDataService {
+	maxBlocks := cfg.MaxBlocks
+	if maxBlocks <= 0 {
+		maxBlocks = defaultMaxBlockData
+	}
+	return &BlockDataService{
+		ctx:       ctx,
+		feed:      feed,
+		maxBlocks: maxBlocks,
+		blocks:    make(map[uint64]*blockData),
+		byHash:    make(map[string]uint64),
+	}
+}
+
+// Start subscribes to the block feed and starts serving the block data.
+func (bds *BlockDataService) Start() error {
+	errCh := bds.feed.Subscribe(bds.handleBlock)
+	go func() {
+		if err := <-errCh; err != nil && err != context.Canceled && err != feeds.ErrEndBlockReached {
+			log.WithError(err).Warn("block data subscription ended")
+		}
+	}()
+
+	bds.server = &http.Server{
+		Addr:    ":" + config.DefaultBlockDataPort,
+		Handler: bds,
+	}
+	utils.GoListenAndServe(bds.server)
+	return nil
+}
+
+// Stop stops the service.
+func (bds *BlockDataService) Stop() error {
+	if bds.server != nil {
+		return bds.server.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (bds *BlockDataService) Name() string {
+	return "block-data"
+}
+
+// Health implements the health.Reporter interface.
+func (bds *BlockDataService) Health() health.Reports {
+	bds.mu.RLock()
+	defer bds.mu.RUnlock()
+	return health.Reports{
+		{
+			Name:    "blocks",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(len(bds.blocks)),
+		},
+	}
+}
+
+func (bds *BlockDataService) handleBlock(evt *domain.BlockEvent) error {
+	if evt.Block == nil {
+		return nil
+	}
+	num, err := parseHexUint(evt.Block.Number)
+	if err != nil {
+		log.WithError(err).Warn("failed to parse the block number - skipping block data")
+		return nil
+	}
+
+	bds.mu.Lock()
+	defer bds.mu.Unlock()
+
+	// the block can replace an older one after a reorg
+	bds.removeUnsafe(num)
+	hash := strings.ToLower(evt.Block.Hash)
+	bds.blocks[num] = &blockData{hash: hash, logs: evt.Logs}
+	bds.byHash[hash] = num
+	if num > bds.latest || len(bds.blocks) == 1 {
+		bds.latest = num
+	}
+	if bds.oldest == 0 || num < bds.oldest {
+		bds.oldest = num
+	}
+	for len(bds.blocks) > bds.maxBlocks && bds.oldest < bds.latest {
+		bds.removeUnsafe(bds.oldest)
+		bds.oldest++
+	}
+	return nil
+}
+
+func (bds *BlockDataService) removeUnsafe(num uint64) {
+	data, ok := bds.blocks[num]
+	if !ok {
+		return
+	}
+	delete(bds.blocks, num)
+	delete(bds.byHash, data.hash)
+}
+
+// ServeHTTP implements http.Handler.
+func (bds *BlockDataService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var rpcReq blockDataRequest
+	if err := json.NewDecoder(req.Body).Decode(&rpcReq); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp := &blockDataResponse{JSONRPC: "2.0", ID: rpcReq.ID}
+	result, ok := bds.lookup(rpcReq)
+	if ok {
+		resp.Result = result
+	} else {
+		resp.Error = &blockDataError{Code: errCodeNotFound, Message: "not found"}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.WithError(err).Error("failed to write block data response")
+	}
+}
+
+func (bds *BlockDataService) lookup(rpcReq blockDataRequest) (interface{}, bool) {
+	if rpcReq.Method != "eth_getLogs" {
+		return nil, false
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(rpcReq.Params, &params); err != nil || len(params) == 0 {
+		return nil, false
+	}
+	var filter logFilter
+	if err := json.Unmarshal(params[0], &filter); err != nil {
+		return nil, false
+	}
+
+	bds.mu.RLock()
+	defer bds.mu.RUnlock()
+
+	data, ok := bds.filterBlockUnsafe(filter)
+	if !ok {
+		return nil, false
+	}
+	return filterLogs(data.logs, filter)
+}
+
+// filterBlockUnsafe finds the block of a filter which refers to a single block.
+func (bds *BlockDataService) filterBlockUnsafe(filter logFilter) (*blockData, bool) {
+	if filter.BlockHash != nil {
+		num, ok := bds.byHash[strings.ToLower(*filter.BlockHash)]
+		if !ok {
+			return nil, false
+		}
+		return bds.blocks[num], true
+	}
+	if filter.FromBlock == nil || filter.ToBlock == nil {
+		return nil, false
+	}
+	from, err := parseHexUint(*filter.FromBlock)
+	if err != nil {
+		return nil, false
+	}
+	to, err := parseHexUint(*filter.ToBlock)
+	if err != nil || from != to {
+		return nil, false
+	}
+	data, ok := bds.blocks[from]
+	return data, ok
+}
+
+func filterLogs(logs []domain.LogEntry, filter logFilter) (interface{}, bool) {
+	addresses, ok := decodeStringOrList(filter.Address)
+	if !ok {
+		return nil, false
+	}
+	topics := make([][]string, len(filter.Topics))
+	for i, topic := range filter.Topics {
+		if topics[i], ok = decodeStringOrList(topic); !ok {
+			return nil, false
+		}
+	}
+
+	results := make([]domain.LogEntry, 0)
+	for _, logEntry := range logs {
+		if len(addresses) > 0 && (logEntry.Address == nil || !containsFold(addresses, *logEntry.Address)) {
+			continue
+		}
+		if matchesTopics(logEntry.Topics, topics) {
+			results = append(results, logEntry)
+		}
+	}
+	return results, true
+}
+
+// matchesTopics checks the topics by position. An empty position matches any topic.
+func matchesTopics(logTopics []*string, topics [][]string) bool {
+	for i, options := range topics {
+		if len(options) == 0 {
+			continue
+		}
+		if i >= len(logTopics) || logTopics[i] == nil || !containsFold(options, *logTopics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeStringOrList decodes a filter value which can be null, a string or a list of strings.
+func decodeStringOrList(raw json.RawMessage) ([]string, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, true
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []string{s}, true
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, false
+	}
+	return list, true
+}
+
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseHexUint(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, fmt.Errorf("not a hex number: %s", s)
+	}
+	return strconv.ParseUint(s[2:], 16, 64)
+}
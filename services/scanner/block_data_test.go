@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func doBlockDataRequest(r *require.Assertions, bds *BlockDataService, body string) map[string]json.RawMessage {
+	req := httptest.NewRequest("POST", "http://localhost:8555", bytes.NewBufferString(body))
+	recorder := httptest.NewRecorder()
+	bds.ServeHTTP(recorder, req)
+	var resp map[string]json.RawMessage
+	r.NoError(json.NewDecoder(recorder.Body).Decode(&resp))
+	return resp
+}
+
+func TestBlockDataService(t *testing.T) {
+	r := require.New(t)
+
+	bds := NewBlockDataService(context.Background(), nil, config.JsonRpcLocalDataConfig{MaxBlocks: 2})
+	for _, num := range []string{"0x1", "0x2", "0x3"} {
+		r.NoError(bds.handleBlock(&domain.BlockEvent{
+			Block: &domain.Block{
+				Number:       num,
+				Hash:         "0xb" + num[2:],
+				Transactions: []domain.Transaction{{Hash: "0xt" + num[2:]}},
+			},
+			Logs: []domain.LogEntry{
+				{Address: utils.StringPtr("0xA"), Topics: []*string{utils.StringPtr("0xT1")}},
+				{Address: utils.StringPtr("0xB"), Topics: []*string{utils.StringPtr("0xT2")}},
+			},
+		}))
+	}
+
+	// the oldest block is evicted
+	resp := doBlockDataRequest(r, bds, `{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"blockHash":"0xb1"}]}`)
+	r.Contains(string(resp["error"]), "-32001")
+
+	// the blocks and the transactions are left to the upstream since the feed types drop some fields
+	resp = doBlockDataRequest(r, bds, `{"jsonrpc":"2.0","id":1,"method":"eth_getBlockByNumber","params":["0x2",false]}`)
+	r.Contains(string(resp["error"]), "-32001")
+	resp = doBlockDataRequest(r, bds, `{"jsonrpc":"2.0","id":1,"method":"eth_getTransactionByHash","params":["0xt2"]}`)
+	r.Contains(string(resp["error"]), "-32001")
+
+	resp = doBlockDataRequest(r, bds, `{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"fromBlock":"0x2","toBlock":"0x2"}]}`)
+	var logs []domain.LogEntry
+	r.NoError(json.Unmarshal(resp["result"], &logs))
+	r.Len(logs, 2)
+
+	resp = doBlockDataRequest(r, bds, `{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"blockHash":"0xb3","address":"0xa","topics":[["0xt1","0xt3"]]}]}`)
+	r.NoError(json.Unmarshal(resp["result"], &logs))
+	r.Len(logs, 1)
+	r.Equal("0xA", *logs[0].Address)
+
+	// block ranges are left to the upstream
+	resp = doBlockDataRequest(r, bds, `{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"fromBlock":"0x2","toBlock":"0x3"}]}`)
+	r.Contains(string(resp["error"]), "-32001")
+}
@@ -0,0 +1,435 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/config"
+	"github.com/goccy/go-json"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	methodGetTransactionReceipt = "eth_getTransactionReceipt"
+
+	blockFeedRetryInterval = time.Second
+)
+
+// errReceiptNotAvailable is returned when the API does not have the receipts of a block yet.
+var errReceiptNotAvailable = errors.New("receipt is not available yet")
+
+type blockFeedClient interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error)
+	BlockNumber(ctx context.Context) (*big.Int, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+	GetLogs(ctx context.Context, q eth.FilterQuery) ([]types.Log, error)
+}
+
+type blockTracer interface {
+	TraceBlock(ctx context.Context, number *big.Int) ([]domain.Trace, error)
+}
+
+// batchCaller sends the batched JSON-RPC calls.
+type batchCaller interface {
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// blockFetch is a block which is being enriched.
+type blockFetch struct {
+	number uint64
+	done   chan struct{}
+	evt    *domain.BlockEvent
+	err    error
+}
+
+// ConcurrentBlockFeed enriches the next blocks concurrently within a window when the node is behind
+// the head and dispatches them in order. The logs are taken from the receipts which are fetched with
+// batched calls if the batching is enabled.
+type ConcurrentBlockFeed struct {
+	ctx         context.Context
+	client      blockFeedClient
+	traceClient blockTracer
+	batcher     batchCaller
+	cfg         feeds.BlockFeedConfig
+	enrichCfg   config.BlockEnrichmentConfig
+
+	start     *big.Int
+	end       *big.Int
+	rateLimit *time.Ticker
+	chainID   *big.Int
+	latest    uint64
+	started   bool
+
+	lastBlock health.MessageTracker
+
+	handlers   []feedHandler
+	handlersMu sync.RWMutex
+}
+
+type feedHandler struct {
+	Handler func(evt *domain.BlockEvent) error
+	ErrCh   chan<- error
+}
+
+// NewConcurrentBlockFeed creates a new block feed. The batch caller is needed only if the receipts are batched.
+func NewConcurrentBlockFeed(
+	ctx context.Context, client blockFeedClient, traceClient blockTracer, batcher batchCaller,
+	cfg feeds.BlockFeedConfig, enrichCfg config.BlockEnrichmentConfig,
+) (*ConcurrentBlockFeed, error) {
+	if cfg.Offset < 0 {
+		return nil, fmt.Errorf("offset cannot be below zero: offset=%d", cfg.Offset)
+	}
+	if enrichCfg.ReceiptBatchSize > 0 && batcher == nil {
+		return nil, errors.New("receipt batching needs a batch caller")
+	}
+	if enrichCfg.Window < 1 {
+		enrichCfg.Window = 1
+	}
+	return &ConcurrentBlockFeed{
+		ctx:         ctx,
+		client:      client,
+		traceClient: traceClient,
+		batcher:     batcher,
+		cfg:         cfg,
+		enrichCfg:   enrichCfg,
+		start:       cfg.Start,
+		end:         cfg.End,
+		rateLimit:   cfg.RateLimit,
+		chainID:     cfg.ChainID,
+	}, nil
+}
+
+// IsStarted tells if the feed is started.
+func (bf *ConcurrentBlockFeed) IsStarted() bool {
+	return bf.started
+}
+
+// Start starts the feed from the configured start block or the latest block.
+func (bf *ConcurrentBlockFeed) Start() {
+	if !bf.started {
+		go bf.loop()
+	}
+}
+
+// StartRange starts the feed for a specific range of blocks.
+func (bf *ConcurrentBlockFeed) StartRange(start int64, end int64, rate int64) {
+	if !bf.started {
+		if rate > 0 {
+			bf.rateLimit = time.NewTicker(time.Duration(rate) * time.Millisecond)
+		}
+		bf.start = big.NewInt(start)
+		bf.end = big.NewInt(end)
+		go bf.loop()
+	}
+}
+
+// Subscribe adds a block handler.
+func (bf *ConcurrentBlockFeed) Subscribe(handler func(evt *domain.BlockEvent) error) <-chan error {
+	bf.handlersMu.Lock()
+	defer bf.handlersMu.Unlock()
+
+	errCh := make(chan error)
+	bf.handlers = append(bf.handlers, feedHandler{
+		Handler: handler,
+		ErrCh:   errCh,
+	})
+	return errCh
+}
+
+// Name returns the name of the feed. It is the same as the default block feed so that the health
+// checks work the same.
+func (bf *ConcurrentBlockFeed) Name() string {
+	return "block-feed"
+}
+
+// Health implements the health.Reporter interface.
+func (bf *ConcurrentBlockFeed) Health() health.Reports {
+	return health.Reports{
+		bf.lastBlock.GetReport("last-block"),
+	}
+}
+
+func (bf *ConcurrentBlockFeed) initialize() error {
+	latest, err := bf.client.BlockNumber(bf.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the latest block number: %v", err)
+	}
+	bf.latest = latest.Uint64()
+	if bf.start == nil {
+		bf.start = latest
+	}
+	log.Infof("initialized block number %d", bf.start)
+
+	if bf.chainID == nil {
+		chainID, err := bf.client.ChainID(bf.ctx)
+		if err != nil {
+			return err
+		}
+		bf.chainID = chainID
+	}
+	log.Infof("initialized chainId %d", bf.chainID)
+	return nil
+}
+
+func (bf *ConcurrentBlockFeed) loop() {
+	if err := bf.initialize(); err != nil {
+		log.WithError(err).Panic("failed to initialize")
+	}
+
+	bf.started = true
+	defer func() {
+		bf.started = false
+	}()
+	err := bf.forEachBlock()
+	if err == nil {
+		return
+	}
+	if err != feeds.ErrEndBlockReached {
+		log.WithError(err).Warn("failed while processing blocks")
+	}
+	bf.handlersMu.RLock()
+	defer bf.handlersMu.RUnlock()
+	for _, handler := range bf.handlers {
+		handler.ErrCh <- err
+	}
+}
+
+// analyzedBlock returns the block to analyze for the chain block number.
+func (bf *ConcurrentBlockFeed) analyzedBlock(chainBlock uint64) uint64 {
+	if chainBlock < uint64(bf.cfg.Offset) {
+		return 0
+	}
+	return chainBlock - uint64(bf.cfg.Offset)
+}
+
+func (bf *ConcurrentBlockFeed) forEachBlock() error {
+	next := bf.analyzedBlock(bf.start.Uint64())
+	nextFetch := next
+	fetches := make(map[uint64]*blockFetch)
+
+	for {
+		if bf.ctx.Err() != nil {
+			return bf.ctx.Err()
+		}
+		if bf.end != nil && next > bf.end.Uint64() {
+			log.WithField("blockToAnalyze", next).Info("end block reached - exiting")
+			return feeds.ErrEndBlockReached
+		}
+
+		// enrich the next blocks which are available within the window
+		for len(fetches) < bf.enrichCfg.Window && nextFetch <= bf.analyzedBlock(bf.latest) &&
+			(bf.end == nil || nextFetch <= bf.end.Uint64()) {
+			fetches[nextFetch] = bf.fetch(nextFetch)
+			nextFetch++
+		}
+
+		fetch, ok := fetches[next]
+		if !ok {
+			// wait for the next block on the chain
+			time.Sleep(blockFeedRetryInterval)
+			if err := bf.refreshLatest(); err != nil {
+				log.WithError(err).Warn("failed to get the latest block number")
+			}
+			continue
+		}
+
+		select {
+		case <-bf.ctx.Done():
+			return bf.ctx.Err()
+		case <-fetch.done:
+		}
+		logger := log.WithField("blockToAnalyze", next)
+		if fetch.err != nil {
+			logger.WithError(fetch.err).Error("error getting block - will retry")
+			time.Sleep(blockFeedRetryInterval)
+			fetches[next] = bf.fetch(next)
+			continue
+		}
+		delete(fetches, next)
+
+		if bf.rateLimit != nil {
+			<-bf.rateLimit.C
+		}
+
+		block := fetch.evt.Block
+		if tooOld, age := isBlockTooOld(block, bf.cfg.SkipBlocksOlderThan); tooOld {
+			logger.WithField("age", age).Warnf("block is older than %v - setting current block num to latest", bf.cfg.SkipBlocksOlderThan)
+			if err := bf.refreshLatest(); err != nil {
+				logger.WithError(err).Error("failed to get latest block number")
+				continue
+			}
+			// the blocks in the window are dropped
+			next = bf.analyzedBlock(bf.latest)
+			nextFetch = next
+			fetches = make(map[uint64]*blockFetch)
+			continue
+		}
+
+		bf.lastBlock.Set(fmt.Sprintf("%d", next))
+		fetch.evt.Timestamps.Feed = time.Now().UTC()
+		bf.handlersMu.RLock()
+		handlers := bf.handlers
+		bf.handlersMu.RUnlock()
+		for _, handler := range handlers {
+			if err := handler.Handler(fetch.evt); err != nil {
+				return err
+			}
+		}
+		next++
+	}
+}
+
+func (bf *ConcurrentBlockFeed) refreshLatest() error {
+	latest, err := bf.client.BlockNumber(bf.ctx)
+	if err != nil {
+		return err
+	}
+	if latest.Uint64() > bf.latest {
+		bf.latest = latest.Uint64()
+	}
+	return nil
+}
+
+// fetch enriches the block in the background.
+func (bf *ConcurrentBlockFeed) fetch(number uint64) *blockFetch {
+	fetch := &blockFetch{number: number, done: make(chan struct{})}
+	go func() {
+		defer close(fetch.done)
+		fetch.evt, fetch.err = bf.enrichBlock(new(big.Int).SetUint64(number))
+	}()
+	return fetch
+}
+
+// enrichBlock gets the block with the logs and the traces.
+func (bf *ConcurrentBlockFeed) enrichBlock(number *big.Int) (*domain.BlockEvent, error) {
+	block, err := bf.client.BlockByNumber(bf.ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the block: %v", err)
+	}
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	logger := log.WithFields(log.Fields{
+		"blockToAnalyze": number.Uint64(),
+		"blockHash":      block.Hash,
+	})
+
+	var traces []domain.Trace
+	if bf.cfg.Tracing {
+		traces, err = bf.traceClient.TraceBlock(bf.ctx, number)
+		if err != nil {
+			logger.WithError(err).Error("error tracing block")
+		}
+	}
+	if len(traces) > 0 && block.Hash != utils.String(traces[0].BlockHash) {
+		logger.WithFields(log.Fields{
+			"traceBlockHash": utils.String(traces[0].BlockHash),
+		}).Warn("trace block hash != ethereum block hash, ignoring traces")
+		traces = nil
+	}
+
+	var logs []domain.LogEntry
+	if bf.enrichCfg.ReceiptBatchSize > 0 {
+		logs, err = bf.logsFromReceipts(block)
+	} else {
+		logs, err = bf.logsForBlock(number)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the logs: %v", err)
+	}
+
+	blockTs, err := block.GetTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the block timestamp: %v", err)
+	}
+	return &domain.BlockEvent{
+		EventType: domain.EventTypeBlock,
+		Block:     block,
+		ChainID:   bf.chainID,
+		Traces:    traces,
+		Logs:      logs,
+		Timestamps: &domain.TrackingTimestamps{
+			Block: *blockTs,
+		},
+	}, nil
+}
+
+// logsFromReceipts fetches the receipts of the block transactions in batches and returns the logs in order.
+func (bf *ConcurrentBlockFeed) logsFromReceipts(block *domain.Block) ([]domain.LogEntry, error) {
+	receipts := make([]*domain.TransactionReceipt, len(block.Transactions))
+	for start := 0; start < len(block.Transactions); start += bf.enrichCfg.ReceiptBatchSize {
+		end := start + bf.enrichCfg.ReceiptBatchSize
+		if end > len(block.Transactions) {
+			end = len(block.Transactions)
+		}
+		batch := make([]rpc.BatchElem, 0, end-start)
+		for i := start; i < end; i++ {
+			batch = append(batch, rpc.BatchElem{
+				Method: methodGetTransactionReceipt,
+				Args:   []interface{}{block.Transactions[i].Hash},
+				Result: &receipts[i],
+			})
+		}
+		if err := bf.batcher.BatchCallContext(bf.ctx, batch); err != nil {
+			return nil, err
+		}
+		for _, elem := range batch {
+			if elem.Error != nil {
+				return nil, elem.Error
+			}
+		}
+	}
+
+	var logs []domain.LogEntry
+	for i, receipt := range receipts {
+		// the receipts from another block are possible during a reorg
+		if receipt == nil || utils.String(receipt.BlockHash) != block.Hash {
+			return nil, fmt.Errorf("%w: %s", errReceiptNotAvailable, block.Transactions[i].Hash)
+		}
+		logs = append(logs, receipt.Logs...)
+	}
+	return logs, nil
+}
+
+// logsForBlock gets the logs of the block with a single call.
+func (bf *ConcurrentBlockFeed) logsForBlock(number *big.Int) ([]domain.LogEntry, error) {
+	logs, err := bf.client.GetLogs(bf.ctx, eth.FilterQuery{
+		FromBlock: number,
+		ToBlock:   number,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var logEntries []domain.LogEntry
+	b, err := json.Marshal(logs)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &logEntries); err != nil {
+		return nil, err
+	}
+	return logEntries, nil
+}
+
+func isBlockTooOld(block *domain.Block, maxAge *time.Duration) (bool, *time.Duration) {
+	if maxAge == nil {
+		return false, nil
+	}
+	age, err := block.Age()
+	if err != nil || age == nil {
+		log.WithField("blockHex", block.Number).WithError(err).Error("error getting age of block")
+		return false, age
+	}
+	return *age > *maxAge, age
+}
@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+type testChain struct {
+	latest   uint64
+	batches  int
+	failOnce map[uint64]bool
+	mu       sync.Mutex
+}
+
+func (tc *testChain) block(number uint64) *domain.Block {
+	return &domain.Block{
+		Number:    utils.BigIntToHex(new(big.Int).SetUint64(number)),
+		Hash:      fmt.Sprintf("0xb%d", number),
+		Timestamp: utils.BigIntToHex(big.NewInt(time.Now().Unix())),
+		Transactions: []domain.Transaction{
+			{Hash: fmt.Sprintf("0xt%d-1", number)},
+			{Hash: fmt.Sprintf("0xt%d-2", number)},
+			{Hash: fmt.Sprintf("0xt%d-3", number)},
+		},
+	}
+}
+
+func (tc *testChain) BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error) {
+	// finish the blocks in random order
+	time.Sleep(time.Duration(rand.Intn(10)) * time.Millisecond)
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.failOnce[number.Uint64()] {
+		delete(tc.failOnce, number.Uint64())
+		return nil, errors.New("failed")
+	}
+	return tc.block(number.Uint64()), nil
+}
+
+func (tc *testChain) BlockNumber(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).SetUint64(tc.latest), nil
+}
+
+func (tc *testChain) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (tc *testChain) GetLogs(ctx context.Context, q eth.FilterQuery) ([]types.Log, error) {
+	return nil, errors.New("logs should be taken from the receipts")
+}
+
+func (tc *testChain) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	tc.mu.Lock()
+	tc.batches++
+	tc.mu.Unlock()
+	for _, elem := range b {
+		txHash := elem.Args[0].(string)
+		var number int
+		fmt.Sscanf(txHash, "0xt%d-", &number)
+		blockHash := fmt.Sprintf("0xb%d", number)
+		*elem.Result.(**domain.TransactionReceipt) = &domain.TransactionReceipt{
+			BlockHash: &blockHash,
+			Logs:      []domain.LogEntry{{TransactionHash: &txHash}},
+		}
+	}
+	return nil
+}
+
+func TestConcurrentBlockFeed(t *testing.T) {
+	r := require.New(t)
+
+	chain := &testChain{latest: 30, failOnce: map[uint64]bool{12: true}}
+	feed, err := NewConcurrentBlockFeed(context.Background(), chain, nil, chain, feeds.BlockFeedConfig{
+		Start:  big.NewInt(10),
+		End:    big.NewInt(25),
+		Offset: 0,
+	}, config.BlockEnrichmentConfig{Window: 8, ReceiptBatchSize: 2})
+	r.NoError(err)
+
+	var dispatched []uint64
+	errCh := feed.Subscribe(func(evt *domain.BlockEvent) error {
+		number := utils.HexToInt64(evt.Block.Number)
+		dispatched = append(dispatched, uint64(number))
+		r.Len(evt.Logs, 3)
+		r.Equal(fmt.Sprintf("0xt%d-1", number), *evt.Logs[0].TransactionHash)
+		return nil
+	})
+	feed.Start()
+	r.Equal(feeds.ErrEndBlockReached, <-errCh)
+
+	// the blocks are dispatched in order after the failed block is retried
+	r.Len(dispatched, 16)
+	for i, number := range dispatched {
+		r.EqualValues(10+i, number)
+	}
+	// two batches per block
+	r.Equal(32, chain.batches)
+}
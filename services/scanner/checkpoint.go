@@ -0,0 +1,238 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCheckpointSaveInterval = time.Second * 10
+	gapRecoveryBlocksPerSecond    = 10
+)
+
+// CheckpointerConfig configures the checkpointer.
+type CheckpointerConfig struct {
+	ChainID      int
+	Path         string
+	MaxGapBlocks int
+	SaveInterval time.Duration
+}
+
+// Checkpointer persists the last scanned block per chain and backfills the blocks which were
+// missed since the last run or skipped by the block feed after the API failures.
+type Checkpointer struct {
+	ctx       context.Context
+	feed      feeds.BlockFeed
+	backfill  *BackfillService
+	msgClient clients.MessageClient
+	cfg       CheckpointerConfig
+
+	lastBlock uint64
+	saved     uint64
+	gaps      int
+	lastGap   uint64
+	mu        sync.Mutex
+}
+
+// NewCheckpointer creates a new checkpointer.
+func NewCheckpointer(
+	ctx context.Context, feed feeds.BlockFeed, backfill *BackfillService, msgClient clients.MessageClient, cfg CheckpointerConfig,
+) *Checkpointer {
+	if cfg.SaveInterval == 0 {
+		cfg.SaveInterval = defaultCheckpointSaveInterval
+	}
+	return &Checkpointer{
+		ctx:       ctx,
+		feed:      feed,
+		backfill:  backfill,
+		msgClient: msgClient,
+		cfg:       cfg,
+	}
+}
+
+// Start loads the checkpoint and starts tracking the blocks.
+func (cp *Checkpointer) Start() error {
+	checkpoints, err := loadCheckpoints(cp.cfg.Path)
+	if err != nil {
+		log.WithError(err).Warn("failed to load the checkpoint - gaps since the last run will not be recovered")
+	}
+	cp.lastBlock = checkpoints[strconv.Itoa(cp.cfg.ChainID)]
+	cp.saved = cp.lastBlock
+
+	errCh := cp.feed.Subscribe(cp.handleBlock)
+	go func() {
+		if err := <-errCh; err != nil && err != context.Canceled && err != feeds.ErrEndBlockReached {
+			log.WithError(err).Warn("checkpointer subscription ended")
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(cp.cfg.SaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cp.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := cp.save(); err != nil {
+					log.WithError(err).Warn("failed to save the checkpoint")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop saves the last checkpoint.
+func (cp *Checkpointer) Stop() error {
+	return cp.save()
+}
+
+// Name returns the name of the service.
+func (cp *Checkpointer) Name() string {
+	return "checkpointer"
+}
+
+// Health implements the health.Reporter interface.
+func (cp *Checkpointer) Health() health.Reports {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return health.Reports{
+		{
+			Name:    "checkpoint.block",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(cp.saved, 10),
+		},
+		{
+			Name:    "checkpoint.gaps",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(cp.gaps),
+		},
+		{
+			Name:    "checkpoint.gap.last",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(cp.lastGap, 10),
+		},
+	}
+}
+
+func (cp *Checkpointer) handleBlock(evt *domain.BlockEvent) error {
+	if evt.Block == nil {
+		return nil
+	}
+	num, err := parseHexUint(evt.Block.Number)
+	if err != nil {
+		return nil
+	}
+
+	cp.mu.Lock()
+	prev := cp.lastBlock
+	if num > cp.lastBlock {
+		cp.lastBlock = num
+	}
+	cp.mu.Unlock()
+
+	if prev > 0 && num > prev+1 {
+		cp.recoverGap(prev+1, num-1)
+	}
+	return nil
+}
+
+// recoverGap backfills the most recent blocks of the gap up to the max gap blocks.
+func (cp *Checkpointer) recoverGap(start, end uint64) {
+	gap := end - start + 1
+	var skipped uint64
+	if gap > uint64(cp.cfg.MaxGapBlocks) {
+		skipped = gap - uint64(cp.cfg.MaxGapBlocks)
+		start += skipped
+	}
+
+	cp.mu.Lock()
+	cp.gaps++
+	cp.lastGap = gap
+	cp.mu.Unlock()
+	metrics.SendAgentMetrics(cp.msgClient, metrics.GetFeedGapMetrics(time.Now().UTC(), gap, skipped))
+
+	logger := log.WithFields(log.Fields{
+		"gap":     gap,
+		"skipped": skipped,
+		"start":   start,
+		"end":     end,
+	})
+	if skipped > 0 {
+		logger.Warn("block gap is larger than the max gap blocks - skipping the oldest blocks")
+	}
+	if start > end || cp.backfill == nil {
+		return
+	}
+	if _, err := cp.backfill.StartBackfill(&BackfillRequest{
+		StartBlock:      start,
+		EndBlock:        end,
+		BlocksPerSecond: gapRecoveryBlocksPerSecond,
+	}); err != nil {
+		logger.WithError(err).Warn("failed to backfill the block gap")
+		return
+	}
+	logger.Info("backfilling the block gap")
+}
+
+func (cp *Checkpointer) save() error {
+	cp.mu.Lock()
+	lastBlock := cp.lastBlock
+	changed := lastBlock != cp.saved
+	cp.mu.Unlock()
+	if !changed {
+		return nil
+	}
+
+	checkpoints, err := loadCheckpoints(cp.cfg.Path)
+	if err != nil {
+		checkpoints = make(map[string]uint64)
+	}
+	checkpoints[strconv.Itoa(cp.cfg.ChainID)] = lastBlock
+	b, err := json.Marshal(checkpoints)
+	if err != nil {
+		return err
+	}
+	tmpPath := cp.cfg.Path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, cp.cfg.Path); err != nil {
+		return err
+	}
+
+	cp.mu.Lock()
+	cp.saved = lastBlock
+	cp.mu.Unlock()
+	return nil
+}
+
+// loadCheckpoints reads the last scanned blocks by the chain IDs.
+func loadCheckpoints(path string) (map[string]uint64, error) {
+	checkpoints := make(map[string]uint64)
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return checkpoints, err
+	}
+	if err := json.Unmarshal(b, &checkpoints); err != nil {
+		return make(map[string]uint64), fmt.Errorf("invalid checkpoint file: %v", err)
+	}
+	return checkpoints, nil
+}
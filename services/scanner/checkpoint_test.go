@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointer(t *testing.T) {
+	r := require.New(t)
+
+	msgClient := mock_clients.NewMockMessageClient(gomock.NewController(t))
+	checkpointPath := path.Join(t.TempDir(), "checkpoint.json")
+	cp := NewCheckpointer(context.Background(), nil, nil, msgClient, CheckpointerConfig{
+		ChainID:      137,
+		Path:         checkpointPath,
+		MaxGapBlocks: 5,
+	})
+
+	r.NoError(cp.handleBlock(&domain.BlockEvent{Block: &domain.Block{Number: "0x10"}}))
+	r.NoError(cp.handleBlock(&domain.BlockEvent{Block: &domain.Block{Number: "0x11"}}))
+
+	// missed 0x12-0x1b
+	var sent *protocol.AgentMetricList
+	msgClient.EXPECT().PublishProto(messaging.SubjectMetricAgent, gomock.Any()).Do(
+		func(subject string, payload *protocol.AgentMetricList) {
+			sent = payload
+		},
+	)
+	r.NoError(cp.handleBlock(&domain.BlockEvent{Block: &domain.Block{Number: "0x1c"}}))
+	r.NotNil(sent)
+	values := make(map[string]float64)
+	for _, metric := range sent.Metrics {
+		values[metric.Name] = metric.Value
+	}
+	r.Equal(float64(10), values[metrics.MetricFeedBlockGap])
+	r.Equal(float64(5), values[metrics.MetricFeedBlockGapSkipped])
+	r.Equal("1", cp.Health()[1].Details)
+
+	// the checkpoint is kept per chain
+	r.NoError(cp.save())
+	checkpoints, err := loadCheckpoints(checkpointPath)
+	r.NoError(err)
+	r.Equal(uint64(0x1c), checkpoints["137"])
+}
@@ -12,13 +12,17 @@ import (
 	"github.com/forta-network/forta-core-go/protocol/alerthash"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/tracing"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/clients"
@@ -32,6 +36,7 @@ type CombinerAlertAnalyzerService struct {
 
 	lastInputActivity  health.TimeTracker
 	lastOutputActivity health.TimeTracker
+	handlingResult     int32
 }
 
 type CombinerAlertAnalyzerServiceConfig struct {
@@ -98,7 +103,11 @@ func (aas *CombinerAlertAnalyzerService) createBloomFilter(finding *protocol.Fin
 func (aas *CombinerAlertAnalyzerService) Start() error {
 	// Gear 2: receive result from agent
 	go func() {
-		for result := range aas.cfg.Results.CombinationAlert {
+		for {
+			result, ok := receiveResult(aas.cfg.Results.CombinationAlert, &aas.handlingResult)
+			if !ok {
+				return
+			}
 			ts := time.Now().UTC()
 
 			m := jsonpb.Marshaler{}
@@ -113,6 +122,8 @@ func (aas *CombinerAlertAnalyzerService) Start() error {
 				AgentConfig:       result.AgentConfig,
 				EvalAlertRequest:  result.Request,
 				EvalAlertResponse: result.Response,
+				SpanContext:       result.SpanContext,
+				EvaluationID:      result.EvaluationID,
 			}
 
 			if len(result.Response.Findings) == 0 {
@@ -169,7 +180,12 @@ func (aas *CombinerAlertAnalyzerService) Start() error {
 			request := &protocol.EvaluateAlertRequest{RequestId: requestId.String(), Event: alertEvtMsg, TargetBotId: alertEvt.Subscriber.BotID}
 
 			// forward to the pool
-			aas.cfg.RequestSender.SendEvaluateAlertRequest(request)
+			ctx, span := tracing.Tracer().Start(aas.ctx, tracing.SpanAlertReceived, trace.WithAttributes(
+				attribute.String("alert.hash", alertEvtMsg.Alert.Hash),
+				attribute.String("bot.id", alertEvt.Subscriber.BotID),
+			))
+			aas.cfg.RequestSender.SendEvaluateAlertRequest(ctx, request)
+			span.End()
 
 			aas.lastInputActivity.Set()
 		}
@@ -178,6 +194,18 @@ func (aas *CombinerAlertAnalyzerService) Start() error {
 	return nil
 }
 
+// ShutdownStage implements the services.StagedService interface.
+func (aas *CombinerAlertAnalyzerService) ShutdownStage() services.ShutdownStage {
+	return services.StageEvaluations
+}
+
+// Drain implements the services.Drainer interface.
+func (aas *CombinerAlertAnalyzerService) Drain(ctx context.Context) error {
+	return drainResults(ctx, aas.cfg.RequestSender, func() int {
+		return len(aas.cfg.Results.CombinationAlert)
+	}, &aas.handlingResult)
+}
+
 func (aas *CombinerAlertAnalyzerService) Stop() error {
 	return nil
 }
@@ -2,12 +2,19 @@ package scanner
 
 import (
 	"context"
+	"math"
+	"strings"
 
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/clients/ratelimiter"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/services/components/metrics"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -18,6 +25,7 @@ type CombinerAlertStreamService struct {
 	alertOutput chan *domain.AlertEvent
 	alertFeed   feeds.AlertFeed
 	msgClient   clients.MessageClient
+	rateLimiter ratelimiter.RateLimiter
 
 	subscribeChan     chan string
 	unSubscribeChan   chan string
@@ -27,6 +35,10 @@ type CombinerAlertStreamService struct {
 type CombinerAlertStreamServiceConfig struct {
 	Start uint64
 	End   uint64
+	// RateLimit limits the alerts delivered to each subscriber bot.
+	RateLimit *config.RateLimitConfig
+	// BotRateLimits override the rate limit for specific subscriber bots.
+	BotRateLimits map[string]config.RateLimitConfig
 }
 
 func (t *CombinerAlertStreamService) registerMessageHandlers() {
@@ -55,11 +67,38 @@ func (t *CombinerAlertStreamService) handleAlert(evt *domain.AlertEvent) error {
 		},
 	).Debug("streaming new alert event")
 
+	if t.exceedsRateLimit(evt.Subscriber.BotID) {
+		log.WithFields(
+			log.Fields{
+				"subscriber": evt.Subscriber.BotID,
+				"alert":      evt.Event.Alert.Hash,
+			},
+		).Debug("alert rate limit exceeded - dropping alert event")
+		metrics.SendAgentMetrics(t.msgClient, []*protocol.AgentMetric{
+			metrics.CreateAgentMetric(evt.Subscriber.BotID, metrics.MetricCombinerThrottled, 1),
+		})
+		return nil
+	}
+
 	t.alertOutput <- evt
 	t.lastAlertActivity.Set()
 	return nil
 }
 
+// exceedsRateLimit tells if the subscriber bot received too many alerts.
+func (t *CombinerAlertStreamService) exceedsRateLimit(botID string) bool {
+	if t.rateLimiter == nil {
+		return false
+	}
+	for limitedBotID, rateLimit := range t.cfg.BotRateLimits {
+		if strings.EqualFold(limitedBotID, botID) {
+			t.rateLimiter.SetClientLimit(botID, rateLimit.Rate, rateLimit.Burst)
+			break
+		}
+	}
+	return t.rateLimiter.ExceedsLimit(botID)
+}
+
 func (t *CombinerAlertStreamService) Start() error {
 	t.registerMessageHandlers()
 	go func() {
@@ -69,6 +108,11 @@ func (t *CombinerAlertStreamService) Start() error {
 	return nil
 }
 
+// ShutdownStage implements the services.StagedService interface.
+func (t *CombinerAlertStreamService) ShutdownStage() services.ShutdownStage {
+	return services.StageIntake
+}
+
 func (t *CombinerAlertStreamService) Stop() error {
 	if t.alertOutput != nil {
 		// drain and close block channel
@@ -134,10 +178,20 @@ func (t *CombinerAlertStreamService) handleMessageUnsubscribe(payload messaging.
 func NewCombinerAlertStreamService(ctx context.Context, alertFeed feeds.AlertFeed, msgClient clients.MessageClient, cfg CombinerAlertStreamServiceConfig) (*CombinerAlertStreamService, error) {
 	alertOutput := make(chan *domain.AlertEvent)
 
+	// the bots without a limit are not limited if only the per-bot limits are configured
+	var rateLimiter ratelimiter.RateLimiter
+	switch {
+	case cfg.RateLimit != nil:
+		rateLimiter = ratelimiter.NewRateLimiter(cfg.RateLimit.Rate, cfg.RateLimit.Burst)
+	case len(cfg.BotRateLimits) > 0:
+		rateLimiter = ratelimiter.NewRateLimiter(math.Inf(1), 1)
+	}
+
 	return &CombinerAlertStreamService{
 		cfg:         cfg,
 		ctx:         ctx,
 		msgClient:   msgClient,
+		rateLimiter: rateLimiter,
 		alertOutput: alertOutput,
 		alertFeed:   alertFeed,
 	}, nil
@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testAlertEvent(subscriberBotID string) *domain.AlertEvent {
+	return &domain.AlertEvent{
+		Event: &protocol.AlertEvent{
+			Alert: &protocol.AlertEvent_Alert{
+				Hash: "0xalert",
+				Source: &protocol.AlertEvent_Alert_Source{
+					Bot: &protocol.AlertEvent_Alert_Bot{Id: "0xsource"},
+				},
+			},
+		},
+		Subscriber: &domain.Subscriber{BotID: subscriberBotID},
+	}
+}
+
+func TestCombinerAlertStreamService_RateLimit(t *testing.T) {
+	r := require.New(t)
+
+	msgClient := mock_clients.NewMockMessageClient(gomock.NewController(t))
+	stream, err := NewCombinerAlertStreamService(
+		context.Background(), nil, msgClient, CombinerAlertStreamServiceConfig{
+			RateLimit: &config.RateLimitConfig{Rate: 0.001, Burst: 2},
+			BotRateLimits: map[string]config.RateLimitConfig{
+				"0xLIMITED": {Rate: 0.001, Burst: 1},
+			},
+		},
+	)
+	r.NoError(err)
+	stream.alertOutput = make(chan *domain.AlertEvent, 10)
+
+	// the throttled alerts are dropped and counted
+	msgClient.EXPECT().PublishProto(gomock.Any(), gomock.Any()).Times(2)
+
+	for i := 0; i < 3; i++ {
+		r.NoError(stream.handleAlert(testAlertEvent("0xbot")))
+	}
+	for i := 0; i < 2; i++ {
+		r.NoError(stream.handleAlert(testAlertEvent("0xlimited")))
+	}
+	r.Len(stream.alertOutput, 3)
+}
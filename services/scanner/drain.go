@@ -0,0 +1,31 @@
+package scanner
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/services/components/botio"
+)
+
+// receiveResult receives the next bot result and marks the analyzer as handling it until the
+// next call.
+func receiveResult[R any](results <-chan *R, handling *int32) (*R, bool) {
+	atomic.StoreInt32(handling, 0)
+	result, ok := <-results
+	if ok {
+		atomic.StoreInt32(handling, 1)
+	}
+	return result, ok
+}
+
+// drainResults waits for the bots to finish the evaluations and for the analyzer to send the
+// results to the publisher.
+func drainResults(ctx context.Context, sender botio.Sender, pendingResults func() int, handling *int32) error {
+	if err := sender.Drain(ctx); err != nil {
+		return err
+	}
+	return services.WaitIdle(ctx, func() bool {
+		return pendingResults() == 0 && atomic.LoadInt32(handling) == 0
+	})
+}
@@ -0,0 +1,291 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const mempoolSeenCacheSize = 100000
+
+var errMempoolNotSupported = errors.New("the api does not support the pending txs")
+
+type rpcCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// MempoolFeedConfig configures the mempool feed.
+type MempoolFeedConfig struct {
+	ChainID *big.Int
+	config.MempoolConfig
+}
+
+// MempoolFeed gets the pending txs from the API and sends them to the bots which opt in
+// from their manifests. The websocket APIs are subscribed with newPendingTransactions and
+// the others are polled with a pending tx filter. The pending txs over the rate limit are dropped.
+type MempoolFeed struct {
+	ctx       context.Context
+	blockFeed feeds.BlockFeed
+	sender    botio.Sender
+	cfg       MempoolFeedConfig
+
+	client   rpcCaller
+	wsClient *rpc.Client
+	limiter  *rate.Limiter
+	seen     interface{ ExistsAndAdd(string) bool }
+
+	latestBlock uint64
+	sent        uint64
+	dropped     uint64
+	lastTx      health.TimeTracker
+	apiErr      health.ErrorTracker
+	mu          sync.Mutex
+}
+
+// NewMempoolFeed creates a new mempool feed.
+func NewMempoolFeed(ctx context.Context, blockFeed feeds.BlockFeed, sender botio.Sender, cfg MempoolFeedConfig) *MempoolFeed {
+	return &MempoolFeed{
+		ctx:       ctx,
+		blockFeed: blockFeed,
+		sender:    sender,
+		cfg:       cfg,
+		limiter:   rate.NewLimiter(rate.Limit(cfg.MaxTxsPerSecond), cfg.MaxTxsPerSecond),
+		seen:      utils.NewCache(mempoolSeenCacheSize),
+	}
+}
+
+// Start dials the API and starts sending the pending txs.
+func (mf *MempoolFeed) Start() error {
+	client, err := rpc.DialContext(mf.ctx, mf.cfg.JsonRpc.Url)
+	if err != nil {
+		return fmt.Errorf("failed to dial the mempool api: %v", err)
+	}
+	for k, v := range mf.cfg.JsonRpc.Headers {
+		client.SetHeader(k, v)
+	}
+	mf.client = client
+	if strings.HasPrefix(mf.cfg.JsonRpc.Url, "ws") {
+		mf.wsClient = client
+	}
+
+	// the pending txs are sent as a part of the next block
+	errCh := mf.blockFeed.Subscribe(mf.handleBlock)
+	go func() {
+		if err := <-errCh; err != nil && err != context.Canceled && err != feeds.ErrEndBlockReached {
+			log.WithError(err).Warn("mempool feed block subscription ended")
+		}
+	}()
+
+	go func() {
+		err := mf.run()
+		if err != nil && err != context.Canceled {
+			log.WithError(err).Warn("mempool feed stopped")
+			mf.apiErr.Set(err)
+		}
+	}()
+	return nil
+}
+
+// ShutdownStage implements the services.StagedService interface.
+func (mf *MempoolFeed) ShutdownStage() services.ShutdownStage {
+	return services.StageIntake
+}
+
+// Stop stops the service.
+func (mf *MempoolFeed) Stop() error {
+	if client, ok := mf.client.(*rpc.Client); ok {
+		client.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (mf *MempoolFeed) Name() string {
+	return "mempool-feed"
+}
+
+// Health implements the health.Reporter interface.
+func (mf *MempoolFeed) Health() health.Reports {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+	return health.Reports{
+		mf.apiErr.GetReport("mempool.api"),
+		mf.lastTx.GetReport("mempool.event.time"),
+		{
+			Name:    "mempool.txs.sent",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(mf.sent, 10),
+		},
+		{
+			Name:    "mempool.txs.dropped",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(mf.dropped, 10),
+		},
+	}
+}
+
+func (mf *MempoolFeed) handleBlock(evt *domain.BlockEvent) error {
+	if evt.Block == nil {
+		return nil
+	}
+	num, err := parseHexUint(evt.Block.Number)
+	if err != nil {
+		return nil
+	}
+	mf.mu.Lock()
+	if num > mf.latestBlock {
+		mf.latestBlock = num
+	}
+	mf.mu.Unlock()
+	return nil
+}
+
+func (mf *MempoolFeed) run() error {
+	if mf.wsClient != nil {
+		return mf.subscribe()
+	}
+	return mf.poll()
+}
+
+func (mf *MempoolFeed) subscribe() error {
+	hashes := make(chan string, 1000)
+	sub, err := mf.wsClient.EthSubscribe(mf.ctx, hashes, "newPendingTransactions")
+	if err != nil {
+		return mempoolAPIError(err)
+	}
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case <-mf.ctx.Done():
+			return mf.ctx.Err()
+		case err := <-sub.Err():
+			return err
+		case hash := <-hashes:
+			mf.handlePendingTx(hash)
+		}
+	}
+}
+
+func (mf *MempoolFeed) poll() error {
+	var filterID string
+	ticker := time.NewTicker(time.Duration(mf.cfg.PollIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-mf.ctx.Done():
+			return mf.ctx.Err()
+		case <-ticker.C:
+		}
+
+		if len(filterID) == 0 {
+			err := mf.client.CallContext(mf.ctx, &filterID, "eth_newPendingTransactionFilter")
+			if err = mempoolAPIError(err); errors.Is(err, errMempoolNotSupported) {
+				return err
+			}
+			if err != nil {
+				log.WithError(err).Warn("failed to create the pending tx filter")
+				mf.apiErr.Set(err)
+				continue
+			}
+		}
+
+		var hashes []string
+		if err := mf.client.CallContext(mf.ctx, &hashes, "eth_getFilterChanges", filterID); err != nil {
+			// the filters expire if they are not polled for a while
+			log.WithError(err).Warn("failed to get the pending txs - recreating the filter")
+			mf.apiErr.Set(err)
+			filterID = ""
+			continue
+		}
+		mf.apiErr.Set(nil)
+		for _, hash := range hashes {
+			mf.handlePendingTx(hash)
+		}
+	}
+}
+
+func (mf *MempoolFeed) handlePendingTx(hash string) {
+	if mf.seen.ExistsAndAdd(hash) {
+		return
+	}
+	if !mf.limiter.Allow() {
+		mf.mu.Lock()
+		mf.dropped++
+		mf.mu.Unlock()
+		return
+	}
+
+	var tx *domain.Transaction
+	if err := mf.client.CallContext(mf.ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		log.WithError(err).WithField("tx", hash).Debug("failed to get the pending tx")
+		return
+	}
+	// dropped or already mined
+	if tx == nil || len(tx.BlockNumber) > 0 {
+		return
+	}
+
+	mf.mu.Lock()
+	pendingBlock := mf.latestBlock + 1
+	mf.mu.Unlock()
+	now := time.Now().UTC()
+	txEvt, err := (&domain.TransactionEvent{
+		BlockEvt: &domain.BlockEvent{
+			EventType: domain.EventTypeBlock,
+			ChainID:   mf.cfg.ChainID,
+			Block: &domain.Block{
+				Number:    hexutil.EncodeUint64(pendingBlock),
+				Timestamp: hexutil.EncodeUint64(uint64(now.Unix())),
+			},
+		},
+		Transaction: tx,
+		Timestamps: &domain.TrackingTimestamps{
+			Feed: now,
+		},
+	}).ToMessage()
+	if err != nil {
+		log.WithError(err).Error("error converting pending tx to message (skipping)")
+		return
+	}
+	txEvt.Type = botio.TxEventTypePending
+	// the pending txs do not have receipts yet
+	txEvt.Receipt = nil
+
+	requestID := uuid.Must(uuid.NewUUID())
+	mf.sender.SendEvaluateTxRequest(mf.ctx, &protocol.EvaluateTxRequest{RequestId: requestID.String(), Event: txEvt})
+
+	mf.mu.Lock()
+	mf.sent++
+	mf.mu.Unlock()
+	mf.lastTx.Set()
+}
+
+func mempoolAPIError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if strings.Contains(strings.ToLower(err.Error()), "method not found") ||
+		strings.Contains(strings.ToLower(err.Error()), "not supported") {
+		return fmt.Errorf("%w: %v", errMempoolNotSupported, err)
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/botio"
+	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testRPCCaller map[string]string
+
+func (c testRPCCaller) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return json.Unmarshal([]byte(c[args[0].(string)]), result)
+}
+
+func TestMempoolFeed(t *testing.T) {
+	r := require.New(t)
+
+	sender := mock_botio.NewMockSender(gomock.NewController(t))
+	mf := NewMempoolFeed(context.Background(), nil, sender, MempoolFeedConfig{
+		ChainID: big.NewInt(1),
+		MempoolConfig: config.MempoolConfig{
+			MaxTxsPerSecond: 2,
+		},
+	})
+	mf.client = testRPCCaller{
+		"0x1": `{"hash":"0x1","from":"0xabc","nonce":"0x0","blockNumber":null}`,
+		"0x2": `{"hash":"0x2","from":"0xabc","nonce":"0x1","blockNumber":"0x5"}`,
+		"0x3": `{"hash":"0x3","from":"0xabc","nonce":"0x2","blockNumber":null}`,
+	}
+	r.NoError(mf.handleBlock(&domain.BlockEvent{Block: &domain.Block{Number: "0x5"}}))
+
+	var sent *protocol.EvaluateTxRequest
+	sender.EXPECT().SendEvaluateTxRequest(gomock.Any(), gomock.Any()).Do(
+		func(ctx context.Context, req *protocol.EvaluateTxRequest) {
+			sent = req
+		},
+	).Times(1)
+	mf.handlePendingTx("0x1")
+	r.NotNil(sent)
+	r.Equal(botio.TxEventTypePending, sent.Event.Type)
+	r.Equal("0x6", sent.Event.Block.BlockNumber)
+	r.Equal("0x1", sent.Event.Transaction.Hash)
+	r.Nil(sent.Event.Receipt)
+
+	// already seen
+	mf.handlePendingTx("0x1")
+	// already mined
+	mf.handlePendingTx("0x2")
+	// over the rate limit
+	mf.handlePendingTx("0x3")
+	r.Equal("1", mf.Health()[2].Details)
+	r.Equal("1", mf.Health()[3].Details)
+}
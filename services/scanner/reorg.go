@@ -0,0 +1,186 @@
+package scanner
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/services/components/botio"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+type blockGetter interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error)
+}
+
+// ReorgTrackerConfig configures the reorg tracker.
+type ReorgTrackerConfig struct {
+	ChainID  *big.Int
+	MaxDepth int
+}
+
+// ReorgTracker keeps the hashes of the recently scanned blocks and detects the reorgs from
+// the parent hashes of the new blocks. The canonical versions of the replaced blocks are sent
+// as reorg events to the bots which opt in from their manifests.
+type ReorgTracker struct {
+	ctx    context.Context
+	feed   feeds.BlockFeed
+	client blockGetter
+	sender botio.Sender
+	cfg    ReorgTrackerConfig
+
+	hashes    map[uint64]string
+	lastDepth int
+	reorgs    int
+	lastReorg health.TimeTracker
+	mu        sync.Mutex
+}
+
+// NewReorgTracker creates a new reorg tracker.
+func NewReorgTracker(ctx context.Context, feed feeds.BlockFeed, client blockGetter, sender botio.Sender, cfg ReorgTrackerConfig) *ReorgTracker {
+	return &ReorgTracker{
+		ctx:    ctx,
+		feed:   feed,
+		client: client,
+		sender: sender,
+		cfg:    cfg,
+		hashes: make(map[uint64]string),
+	}
+}
+
+// Start subscribes to the block feed.
+func (rt *ReorgTracker) Start() error {
+	errCh := rt.feed.Subscribe(rt.handleBlock)
+	go func() {
+		if err := <-errCh; err != nil && err != context.Canceled && err != feeds.ErrEndBlockReached {
+			log.WithError(err).Warn("reorg tracker subscription ended")
+		}
+	}()
+	return nil
+}
+
+// ShutdownStage implements the services.StagedService interface.
+func (rt *ReorgTracker) ShutdownStage() services.ShutdownStage {
+	return services.StageIntake
+}
+
+// Stop stops the service.
+func (rt *ReorgTracker) Stop() error {
+	return nil
+}
+
+// Name returns the name of the service.
+func (rt *ReorgTracker) Name() string {
+	return "reorg-tracker"
+}
+
+// Health implements the health.Reporter interface.
+func (rt *ReorgTracker) Health() health.Reports {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return health.Reports{
+		rt.lastReorg.GetReport("reorg.time"),
+		{
+			Name:    "reorg.count",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(rt.reorgs),
+		},
+		{
+			Name:    "reorg.depth",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(rt.lastDepth),
+		},
+	}
+}
+
+func (rt *ReorgTracker) handleBlock(evt *domain.BlockEvent) error {
+	if evt.Block == nil {
+		return nil
+	}
+	num, err := parseHexUint(evt.Block.Number)
+	if err != nil {
+		log.WithError(err).Warn("failed to parse the block number - skipping reorg check")
+		return nil
+	}
+
+	replaced := rt.track(num, evt.Block)
+	for _, block := range replaced {
+		rt.sendReorgEvent(block)
+	}
+	return nil
+}
+
+// track records the block and returns the canonical versions of the replaced blocks in order.
+func (rt *ReorgTracker) track(num uint64, block *domain.Block) (replaced []*domain.Block) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	parentHash, ok := rt.hashes[num-1]
+	if num > 0 && ok && !strings.EqualFold(parentHash, block.ParentHash) {
+		replaced = rt.findReplacedUnsafe(num, block.ParentHash)
+		rt.reorgs++
+		rt.lastDepth = len(replaced)
+		rt.lastReorg.Set()
+		log.WithFields(log.Fields{
+			"block": num,
+			"depth": len(replaced),
+		}).Warn("detected reorg")
+	}
+
+	rt.hashes[num] = block.Hash
+	for tracked := range rt.hashes {
+		if tracked+uint64(rt.cfg.MaxDepth) <= num {
+			delete(rt.hashes, tracked)
+		}
+	}
+	return
+}
+
+// findReplacedUnsafe walks back from the parent of the new block until the tracked hash
+// matches the canonical chain.
+func (rt *ReorgTracker) findReplacedUnsafe(num uint64, parentHash string) (replaced []*domain.Block) {
+	expectedHash := parentHash
+	for n := num - 1; n > 0 && len(replaced) < rt.cfg.MaxDepth; n-- {
+		trackedHash, ok := rt.hashes[n]
+		if !ok || strings.EqualFold(trackedHash, expectedHash) {
+			break
+		}
+		block, err := rt.client.BlockByNumber(rt.ctx, big.NewInt(0).SetUint64(n))
+		if err != nil {
+			log.WithError(err).WithField("block", n).Warn("failed to get the canonical block after reorg")
+			break
+		}
+		replaced = append([]*domain.Block{block}, replaced...)
+		rt.hashes[n] = block.Hash
+		expectedHash = block.ParentHash
+	}
+	return
+}
+
+func (rt *ReorgTracker) sendReorgEvent(block *domain.Block) {
+	blockEvt, err := (&domain.BlockEvent{
+		EventType: domain.EventTypeBlock,
+		ChainID:   rt.cfg.ChainID,
+		Block:     block,
+		Timestamps: &domain.TrackingTimestamps{
+			Feed: time.Now().UTC(),
+		},
+	}).ToMessage()
+	if err != nil {
+		log.WithError(err).Error("error converting reorg event to message (skipping)")
+		return
+	}
+	blockEvt.Type = protocol.BlockEvent_REORG
+
+	requestID := uuid.Must(uuid.NewUUID())
+	rt.sender.SendEvaluateBlockRequest(rt.ctx, &protocol.EvaluateBlockRequest{RequestId: requestID.String(), Event: blockEvt})
+}
@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	mock_botio "github.com/forta-network/forta-node/services/components/botio/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testBlockGetter map[uint64]*domain.Block
+
+func (bg testBlockGetter) BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error) {
+	return bg[number.Uint64()], nil
+}
+
+func TestReorgTracker(t *testing.T) {
+	r := require.New(t)
+
+	sender := mock_botio.NewMockSender(gomock.NewController(t))
+	canonical := testBlockGetter{
+		2: {Number: "0x2", Hash: "0xb2'", ParentHash: "0xb1"},
+		3: {Number: "0x3", Hash: "0xb3'", ParentHash: "0xb2'"},
+	}
+	rt := NewReorgTracker(context.Background(), nil, canonical, sender, ReorgTrackerConfig{
+		ChainID:  big.NewInt(1),
+		MaxDepth: 10,
+	})
+
+	for _, block := range []*domain.Block{
+		{Number: "0x1", Hash: "0xb1", ParentHash: "0xb0"},
+		{Number: "0x2", Hash: "0xb2", ParentHash: "0xb1"},
+		{Number: "0x3", Hash: "0xb3", ParentHash: "0xb2"},
+	} {
+		r.NoError(rt.handleBlock(&domain.BlockEvent{Block: block}))
+	}
+
+	// the new block is on top of the replaced blocks 2 and 3
+	var sent []*protocol.EvaluateBlockRequest
+	sender.EXPECT().SendEvaluateBlockRequest(gomock.Any(), gomock.Any()).Do(
+		func(ctx context.Context, req *protocol.EvaluateBlockRequest) {
+			sent = append(sent, req)
+		},
+	).Times(2)
+	r.NoError(rt.handleBlock(&domain.BlockEvent{Block: &domain.Block{Number: "0x4", Hash: "0xb4", ParentHash: "0xb3'"}}))
+
+	r.Len(sent, 2)
+	r.Equal(protocol.BlockEvent_REORG, sent[0].Event.Type)
+	r.Equal("0xb2'", sent[0].Event.BlockHash)
+	r.Equal("0xb3'", sent[1].Event.BlockHash)
+	r.Equal("1", rt.Health()[1].Details)
+	r.Equal("2", rt.Health()[2].Details)
+
+	// no reorg
+	r.NoError(rt.handleBlock(&domain.BlockEvent{Block: &domain.Block{Number: "0x5", Hash: "0xb5", ParentHash: "0xb4"}}))
+}
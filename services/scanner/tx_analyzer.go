@@ -7,9 +7,11 @@ import (
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/protocol/alerthash"
 	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components"
 	"github.com/forta-network/forta-node/services/components/botio/botreq"
 	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/tracing"
 
 	"github.com/forta-network/forta-core-go/domain"
 	"github.com/forta-network/forta-core-go/protocol"
@@ -18,6 +20,8 @@ import (
 
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TxAnalyzerService reads TX info, calls agents, and emits results
@@ -27,12 +31,15 @@ type TxAnalyzerService struct {
 
 	lastInputActivity  health.TimeTracker
 	lastOutputActivity health.TimeTracker
+	handlingResult     int32
 }
 
 type TxAnalyzerServiceConfig struct {
 	TxChannel   <-chan *domain.TransactionEvent
 	AlertSender clients.AlertSender
 	MsgClient   clients.MessageClient
+	// Backfill is optional and decides if the backfill findings are published.
+	Backfill *BackfillService
 	components.BotProcessing
 }
 
@@ -109,13 +116,25 @@ func (t *TxAnalyzerService) createBloomFilter(finding *protocol.Finding, event *
 
 func (t *TxAnalyzerService) Start() error {
 	go func() {
-		for result := range t.cfg.BotProcessing.Results.Tx {
+		for {
+			result, ok := receiveResult(t.cfg.BotProcessing.Results.Tx, &t.handlingResult)
+			if !ok {
+				return
+			}
 			ts := time.Now().UTC()
 
 			rt := &clients.AgentRoundTrip{
 				AgentConfig:    result.AgentConfig,
 				EvalTxRequest:  result.Request,
 				EvalTxResponse: result.Response,
+				SpanContext:    result.SpanContext,
+				EvaluationID:   result.EvaluationID,
+			}
+
+			if !t.cfg.Backfill.ShouldPublish(result.Request.RequestId, len(result.Response.Findings)) {
+				t.publishMetrics(result)
+				t.lastOutputActivity.Set()
+				continue
 			}
 
 			if len(result.Response.Findings) == 0 {
@@ -161,7 +180,12 @@ func (t *TxAnalyzerService) Start() error {
 			request := &protocol.EvaluateTxRequest{RequestId: requestId.String(), Event: msg}
 
 			// forward to the pool
-			t.cfg.RequestSender.SendEvaluateTxRequest(request)
+			ctx, span := tracing.Tracer().Start(t.ctx, tracing.SpanTxReceived, trace.WithAttributes(
+				attribute.String("tx.hash", msg.Transaction.Hash),
+				attribute.String("block.number", msg.Block.BlockNumber),
+			))
+			t.cfg.RequestSender.SendEvaluateTxRequest(ctx, request)
+			span.End()
 
 			t.lastInputActivity.Set()
 		}
@@ -170,6 +194,18 @@ func (t *TxAnalyzerService) Start() error {
 	return nil
 }
 
+// ShutdownStage implements the services.StagedService interface.
+func (t *TxAnalyzerService) ShutdownStage() services.ShutdownStage {
+	return services.StageEvaluations
+}
+
+// Drain implements the services.Drainer interface.
+func (t *TxAnalyzerService) Drain(ctx context.Context) error {
+	return drainResults(ctx, t.cfg.RequestSender, func() int {
+		return len(t.cfg.BotProcessing.Results.Tx)
+	}, &t.handlingResult)
+}
+
 func (t *TxAnalyzerService) Stop() error {
 	return nil
 }
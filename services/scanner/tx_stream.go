@@ -9,6 +9,7 @@ import (
 	"github.com/forta-network/forta-core-go/ethereum"
 	"github.com/forta-network/forta-core-go/feeds"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -74,6 +75,11 @@ func (t *TxStreamService) Start() error {
 	return nil
 }
 
+// ShutdownStage implements the services.StagedService interface.
+func (t *TxStreamService) ShutdownStage() services.ShutdownStage {
+	return services.StageIntake
+}
+
 func (t *TxStreamService) Stop() error {
 	if t.txOutput != nil {
 		// drain and close tx channel
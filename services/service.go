@@ -12,7 +12,10 @@ import (
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/forta-network/forta-node/clients/faults"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/crashreport"
+	"github.com/forta-network/forta-node/logging"
 )
 
 const (
@@ -66,17 +69,28 @@ func ContainerMain(name string, getServices func(ctx context.Context, cfg config
 		return
 	}
 
-	lvl, err := log.ParseLevel(cfg.Log.Level)
-	if err != nil {
-		logger.WithError(err).Error("could not initialize log level")
+	if err := logging.Init(cfg, name); err != nil {
+		logger.WithError(err).Error("could not initialize logging")
 		return
 	}
-	log.SetLevel(lvl)
-	log.SetFormatter(&log.JSONFormatter{})
 	logger.Info("starting")
 	defer logger.Info("exiting")
 
-	ctx, cancel := InitMainContext()
+	// all containers should find the bots by the same names
+	if err := config.SetContainerNaming(cfg.ContainerNaming); err != nil {
+		logger.WithError(err).Error("invalid container naming config")
+		return
+	}
+
+	// the clients check the faults when they are created
+	faults.Configure(cfg.FaultInjection)
+
+	// the panics are reported by the supervisor from the container logs
+	if crashReporter := crashreport.NewReporter(cfg); crashReporter != nil {
+		log.AddHook(crashReporter.FatalHook(name))
+	}
+
+	ctx, cancel := InitMainContext(cfg.Shutdown)
 	defer cancel()
 
 	serviceList, err := getServices(ctx, cfg)
@@ -105,9 +119,11 @@ func IsGracefulShutdown() bool {
 	return gracefulShutdown
 }
 
-func InitMainContext() (context.Context, context.CancelFunc) {
+// InitMainContext creates the main context which is canceled when the process receives a signal
+// and attaches the lifecycle controller which stops the services after that.
+func InitMainContext(shutdownCfg config.ShutdownConfig) (context.Context, context.CancelFunc) {
 	execIDCtx := initExecID(context.Background())
-	ctx, cancel := context.WithCancel(execIDCtx)
+	ctx, cancel := context.WithCancel(withLifecycle(execIDCtx, NewLifecycle(execIDCtx, shutdownCfg)))
 	signal.Notify(sigc,
 		syscall.SIGHUP,
 		syscall.SIGINT,
@@ -175,13 +191,12 @@ func StartServices(ctx context.Context, cancelMainCtx context.CancelFunc, logger
 	<-ctx.Done()
 	logger.WithError(ctx.Err()).Info("context is done")
 
-	// stop all services
-	for _, service := range services {
-		serviceLogger := logger.WithField("service", service.Name())
-		serviceLogger.Info("stopping service")
-		err := service.Stop()
-		serviceLogger.WithError(err).Info("stopped service")
+	// stop all services stage by stage
+	lc := getLifecycle(ctx)
+	if lc == nil {
+		lc = NewLifecycle(context.Background(), config.ShutdownConfig{})
 	}
+	lc.Shutdown(logger, services)
 
 	if exitTriggered {
 		return ErrExitTriggered
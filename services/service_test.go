@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/forta-network/forta-node/config"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/sync/errgroup"
@@ -39,7 +40,7 @@ func (t *TestService) Name() string {
 
 func TestSigIntSignalCancelsService(t *testing.T) {
 	sigc = make(chan os.Signal, 1)
-	ctx, cancel := InitMainContext()
+	ctx, cancel := InitMainContext(config.ShutdownConfig{})
 
 	go func() {
 		time.Sleep(1 * time.Second)
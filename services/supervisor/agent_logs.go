@@ -6,8 +6,8 @@ import (
 	"time"
 
 	"github.com/forta-network/forta-core-go/clients/agentlogs"
-	"github.com/forta-network/forta-core-go/security"
 	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/clients/signer"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -75,7 +75,7 @@ func (sup *SupervisorService) doSyncAgentLogs() error {
 	}
 
 	if len(sendLogs) > 0 {
-		scannerJwt, err := security.CreateScannerJWT(sup.config.Key, map[string]interface{}{
+		scannerJwt, err := signer.CreateScannerJWT(sup.config.Signer, map[string]interface{}{
 			"access": "agent_logs",
 		})
 		if err != nil {
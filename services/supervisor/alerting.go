@@ -0,0 +1,35 @@
+package supervisor
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/services/components/registry"
+)
+
+func registryLastSynced(botRegistry registry.BotRegistry) func() time.Time {
+	return func() time.Time {
+		if botRegistry == nil {
+			return time.Time{}
+		}
+		return botRegistry.LastSynced()
+	}
+}
+
+// alertingReport reports the alerting rules which are firing.
+func alertingReport(firing map[string]string) *health.Report {
+	report := &health.Report{Name: "alerting.firing", Status: health.StatusOK}
+	if len(firing) == 0 {
+		return report
+	}
+	var rules []string
+	for rule := range firing {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	report.Status = health.StatusLagging
+	report.Details = strings.Join(rules, ", ")
+	return report
+}
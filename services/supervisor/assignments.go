@@ -0,0 +1,65 @@
+package supervisor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain/registry"
+	"github.com/forta-network/forta-core-go/domain/registry/regmsg"
+	registrylistener "github.com/forta-network/forta-core-go/registry"
+	log "github.com/sirupsen/logrus"
+)
+
+const assignmentListenerRetryInterval = time.Second * 30
+
+// assignmentRefreshDelay lets the registry endpoints catch up with the new block
+// before the assignments are reloaded.
+var assignmentRefreshDelay = time.Second * 5
+
+// listenToAssignments follows the dispatch contract events so that the bot assignment changes
+// are applied without waiting for the next refresh. Polling still catches anything missed here.
+func (sup *SupervisorService) listenToAssignments() {
+	logger := log.WithField("component", "assignment-listener")
+	for {
+		listener, err := registrylistener.NewListener(sup.ctx, registrylistener.ListenerConfig{
+			Name:           "assignment-listener",
+			JsonRpcURL:     sup.config.Config.Registry.JsonRpc.Url,
+			ENSAddress:     sup.config.Config.ENSConfig.ContractAddress,
+			ContractFilter: &registrylistener.ContractFilter{DispatchRegistry: true},
+			Handlers: registrylistener.Handlers{
+				DispatchHandlers: regmsg.Handlers(sup.handleDispatchMessage),
+			},
+		})
+		if err == nil {
+			err = listener.Listen()
+		}
+		if sup.ctx.Err() != nil {
+			return
+		}
+		logger.WithError(err).Warn("assignment listener failed - retrying")
+
+		select {
+		case <-sup.ctx.Done():
+			return
+		case <-time.After(assignmentListenerRetryInterval):
+		}
+	}
+}
+
+func (sup *SupervisorService) handleDispatchMessage(ctx context.Context, logger *log.Entry, msg *registry.DispatchMessage) error {
+	if !strings.EqualFold(msg.ScannerID, sup.config.Signer.Address().Hex()) {
+		return nil
+	}
+	logger.WithField("action", msg.Action).Info("detected assignment change")
+	time.AfterFunc(assignmentRefreshDelay, sup.triggerBotRefresh)
+	return nil
+}
+
+// triggerBotRefresh makes the bot refresh loop run without waiting for the next interval.
+func (sup *SupervisorService) triggerBotRefresh() {
+	select {
+	case sup.botRefreshCh <- struct{}{}:
+	default: // already triggered
+	}
+}
@@ -0,0 +1,45 @@
+package supervisor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/forta-network/forta-core-go/domain/registry"
+	"github.com/forta-network/forta-node/clients/signer"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDispatchMessage(t *testing.T) {
+	r := require.New(t)
+
+	assignmentRefreshDelay = 0
+	scannerAddr := common.HexToAddress("0x1")
+	sup := &SupervisorService{
+		config:       SupervisorServiceConfig{Signer: signer.NewKeySigner(&keystore.Key{Address: scannerAddr})},
+		botRefreshCh: make(chan struct{}, 1),
+	}
+	logger := log.WithField("test", "dispatch")
+
+	// other scanners are ignored
+	r.NoError(sup.handleDispatchMessage(context.Background(), logger, &registry.DispatchMessage{
+		ScannerID: common.HexToAddress("0x2").Hex(),
+	}))
+	select {
+	case <-sup.botRefreshCh:
+		r.FailNow("should not trigger a refresh")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	r.NoError(sup.handleDispatchMessage(context.Background(), logger, &registry.DispatchMessage{
+		ScannerID: scannerAddr.Hex(),
+	}))
+	select {
+	case <-sup.botRefreshCh:
+	case <-time.After(time.Second):
+		r.FailNow("should trigger a refresh")
+	}
+}
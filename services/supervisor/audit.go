@@ -0,0 +1,73 @@
+package supervisor
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/forta-network/forta-node/audit"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const maxAuditedBodySize = 4096
+
+// auditStatusRecorder keeps the status code of the response.
+type auditStatusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *auditStatusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// auditAdminRequests records the admin API requests which change the node. The reads are not recorded.
+func (sup *SupervisorService) auditAdminRequests(h http.Handler) http.Handler {
+	if sup.auditLog == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			h.ServeHTTP(w, r)
+			return
+		}
+		body, _ := ioutil.ReadAll(io.LimitReader(r.Body, maxAuditedBodySize))
+		r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+
+		rec := &auditStatusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		details := map[string]string{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"query":  r.URL.RawQuery,
+			"remote": r.RemoteAddr,
+			"status": strconv.Itoa(rec.status),
+			"body":   string(body),
+		}
+		sup.recordAudit(audit.ActionAdminRequest, r.URL.Query().Get("botId"), details)
+	})
+}
+
+// recordConfigReload records the result of a config reload.
+func (sup *SupervisorService) recordConfigReload(report *config.ReloadReport) {
+	details := map[string]string{
+		"reloaded":        strings.Join(report.Reloaded, ","),
+		"requiresRestart": strings.Join(report.RequiresRestart, ","),
+	}
+	if len(report.Error) > 0 {
+		details["error"] = report.Error
+	}
+	sup.recordAudit(audit.ActionConfigReload, "", details)
+}
+
+func (sup *SupervisorService) recordAudit(action, botID string, details map[string]string) {
+	if err := sup.auditLog.Record(action, botID, details); err != nil {
+		log.WithError(err).WithField("action", action).Error("failed to record to the audit log")
+	}
+}
@@ -0,0 +1,19 @@
+package supervisor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+// handleBackfill forwards the backfill requests to the scanner which runs the backfill
+// through the same bots as the live scanning.
+func (sup *SupervisorService) handleBackfill(w http.ResponseWriter, r *http.Request) {
+	httputil.NewSingleHostReverseProxy(&url.URL{
+		Scheme: "http",
+		Host:   fmt.Sprintf("%s:%s", config.DockerScannerContainerName, config.DefaultScannerAdminPort),
+	}).ServeHTTP(w, r)
+}
@@ -0,0 +1,295 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/lifecycle"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// Bot admin endpoints
+const (
+	PathBots        = "/bots"
+	PathBotInspect  = "/bots/inspect"
+	PathBotRestart  = "/bots/restart"
+	PathBotStop     = "/bots/stop"
+	PathBotLogs     = "/bots/logs"
+	defaultBotLogs  = 100
+	maxBotLogLength = 1024 * 1024
+)
+
+// BotInfo contains the state of a running bot.
+type BotInfo struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name,omitempty"`
+	Image       string  `json:"image"`
+	ImageDigest string  `json:"imageDigest"`
+	ShardID     *uint   `json:"shardId,omitempty"`
+	Shards      uint    `json:"shards,omitempty"`
+	Container   string  `json:"container"`
+	State       string  `json:"state"`
+	StartedAt   string  `json:"startedAt,omitempty"`
+	Uptime      string  `json:"uptime,omitempty"`
+	Restarts    int     `json:"restarts"`
+	CPUPercent  float64 `json:"cpuPercent"`
+	MemoryBytes uint64  `json:"memoryBytes"`
+	MemoryLimit uint64  `json:"memoryLimit"`
+
+	LastError *BotError `json:"lastError,omitempty"`
+	// the details below are only included when a bot is inspected
+	Errors []BotError          `json:"errors,omitempty"`
+	Stats  []*metrics.BotStats `json:"stats,omitempty"`
+	Config *config.AgentConfig `json:"config,omitempty"`
+}
+
+// BotActionRequest selects the bot to restart or stop.
+type BotActionRequest struct {
+	BotID string `json:"botId"`
+}
+
+// botRestarts counts the restarts of each bot since the supervisor started.
+type botRestarts struct {
+	counts map[string]int
+	mu     sync.Mutex
+}
+
+func (br *botRestarts) Add(botID string) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if br.counts == nil {
+		br.counts = make(map[string]int)
+	}
+	br.counts[strings.ToLower(botID)]++
+}
+
+func (br *botRestarts) Get(botID string) int {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	return br.counts[strings.ToLower(botID)]
+}
+
+// containerResources retains the latest resource usage of the containers by name.
+type containerResources struct {
+	resources map[string]*docker.ContainerResources
+	mu        sync.RWMutex
+}
+
+func (cr *containerResources) Set(resources map[string]*docker.ContainerResources) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.resources = resources
+}
+
+func (cr *containerResources) Get(containerName string) (*docker.ContainerResources, bool) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	resources, ok := cr.resources[containerName]
+	return resources, ok
+}
+
+func (sup *SupervisorService) handleBots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	bots, err := sup.getBots(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bots)
+}
+
+func (sup *SupervisorService) handleBotInspect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	botID := r.URL.Query().Get("botId")
+	if len(botID) == 0 {
+		http.Error(w, "bot id is required", http.StatusBadRequest)
+		return
+	}
+	bots, err := sup.getBots(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, bot := range bots {
+		if strings.EqualFold(bot.ID, botID) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(bot)
+			return
+		}
+	}
+	http.Error(w, lifecycle.ErrBotNotFound.Error(), http.StatusNotFound)
+}
+
+func (sup *SupervisorService) handleBotRestart(w http.ResponseWriter, r *http.Request) {
+	sup.handleBotAction(w, r, sup.botLifecycle.BotManager.RestartBot)
+}
+
+func (sup *SupervisorService) handleBotStop(w http.ResponseWriter, r *http.Request) {
+	sup.handleBotAction(w, r, sup.botLifecycle.BotManager.StopBot)
+}
+
+func (sup *SupervisorService) handleBotAction(
+	w http.ResponseWriter, r *http.Request, action func(ctx context.Context, botID string) error,
+) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req BotActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode the request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.BotID) == 0 {
+		http.Error(w, "bot id is required", http.StatusBadRequest)
+		return
+	}
+	err := action(r.Context(), req.BotID)
+	if errors.Is(err, lifecycle.ErrBotNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.WithError(err).WithField("bot", req.BotID).Warn("failed to complete the bot action")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (sup *SupervisorService) handleBotLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	botID := r.URL.Query().Get("botId")
+	if len(botID) == 0 {
+		http.Error(w, "bot id is required", http.StatusBadRequest)
+		return
+	}
+	tail := defaultBotLogs
+	if tailStr := r.URL.Query().Get("tail"); len(tailStr) > 0 {
+		var err error
+		tail, err = strconv.Atoi(tailStr)
+		if err != nil || tail <= 0 {
+			http.Error(w, "tail should be a positive number", http.StatusBadRequest)
+			return
+		}
+	}
+	container, found, err := sup.findBotContainer(r, botID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, lifecycle.ErrBotNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	logs, err := sup.client.GetContainerLogs(r.Context(), container.ID, strconv.Itoa(tail), maxBotLogLength)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get the bot logs: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(logs))
+}
+
+func (sup *SupervisorService) findBotContainer(r *http.Request, botID string) (*types.Container, bool, error) {
+	botContainers, err := sup.botLifecycle.BotClient.LoadBotContainers(r.Context())
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load the bot containers: %v", err)
+	}
+	for _, container := range botContainers {
+		if strings.EqualFold(container.Labels[docker.LabelFortaBotID], botID) {
+			container := container
+			return &container, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// getBots collects the state of the running bots from the bot configs, the containers, the
+// resource usage and the errors.
+func (sup *SupervisorService) getBots(r *http.Request, details bool) ([]*BotInfo, error) {
+	botContainers, err := sup.botLifecycle.BotClient.LoadBotContainers(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the bot containers: %v", err)
+	}
+	containersByName := make(map[string]types.Container)
+	for _, container := range botContainers {
+		containersByName[docker.GetContainerName(container)] = container
+	}
+
+	bots := []*BotInfo{}
+	for _, botConfig := range sup.botLifecycle.BotManager.RunningBots() {
+		botConfig := botConfig
+		bot := &BotInfo{
+			ID:          botConfig.ID,
+			Name:        botConfig.Name,
+			Image:       botConfig.Image,
+			ImageDigest: botConfig.ImageHash(),
+			Container:   botConfig.ContainerName(),
+			State:       "missing",
+			Restarts:    sup.botRestarts.Get(botConfig.ID),
+		}
+		if botConfig.ShardConfig != nil {
+			bot.ShardID = &botConfig.ShardConfig.ShardID
+			bot.Shards = botConfig.ShardConfig.Shards
+		}
+		if container, ok := containersByName[bot.Container]; ok {
+			bot.State = container.State
+			sup.setBotUptime(r, bot, container.ID)
+		}
+		if resources, ok := sup.resources.Get(bot.Container); ok {
+			bot.CPUPercent = resources.CPUPercent
+			bot.MemoryBytes = resources.MemoryBytes
+			bot.MemoryLimit = resources.MemoryLimit
+		}
+		botErrs := sup.botErrors.Get(botConfig.ID)
+		if len(botErrs) > 0 {
+			bot.LastError = &botErrs[len(botErrs)-1]
+		}
+		if details {
+			bot.Errors = botErrs
+			bot.Stats = sup.botStats.BotStats(botConfig.ID)
+			bot.Config = &botConfig
+		}
+		bots = append(bots, bot)
+	}
+	return bots, nil
+}
+
+func (sup *SupervisorService) setBotUptime(r *http.Request, bot *BotInfo, containerID string) {
+	if bot.State != "running" {
+		return
+	}
+	containerJSON, err := sup.client.InspectContainer(r.Context(), containerID)
+	if err != nil || containerJSON.State == nil {
+		log.WithError(err).WithField("bot", bot.ID).Debug("failed to inspect the bot container")
+		return
+	}
+	startedAt, err := time.Parse(time.RFC3339Nano, containerJSON.State.StartedAt)
+	if err != nil {
+		return
+	}
+	bot.StartedAt = startedAt.UTC().Format(time.RFC3339)
+	bot.Uptime = time.Since(startedAt).Round(time.Second).String()
+}
@@ -0,0 +1,98 @@
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/docker"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	mock_containers "github.com/forta-network/forta-node/services/components/containers/mocks"
+	"github.com/forta-network/forta-node/services/components/lifecycle"
+	mock_lifecycle "github.com/forta-network/forta-node/services/components/lifecycle/mocks"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleBots(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	dockerClient := mock_clients.NewMockDockerClient(ctrl)
+	botClient := mock_containers.NewMockBotClient(ctrl)
+	botManager := mock_lifecycle.NewMockBotLifecycleManager(ctrl)
+
+	sup := &SupervisorService{
+		ctx:       context.Background(),
+		client:    dockerClient,
+		botErrors: newBotErrors(defaultBotErrorsPerBot),
+	}
+	sup.botLifecycle.BotClient = botClient
+	sup.botLifecycle.BotManager = botManager
+
+	digest := strings.Repeat("a", 64)
+	botConfig := config.AgentConfig{
+		ID:          "0xbot",
+		Name:        "bot-name",
+		Image:       "bafybeibot@sha256:" + digest,
+		ShardConfig: &config.ShardConfig{ShardID: 1, Shards: 2},
+	}
+	sup.resources.Set(map[string]*docker.ContainerResources{
+		botConfig.ContainerName(): {CPUPercent: 1.5, MemoryBytes: 100},
+	})
+	r.NoError(sup.handleAgentMetrics(&protocol.AgentMetricList{
+		Metrics: []*protocol.AgentMetric{{AgentId: "0xBOT", Name: metrics.MetricActionRestart}},
+	}))
+
+	botManager.EXPECT().RunningBots().Return([]config.AgentConfig{botConfig})
+	botClient.EXPECT().LoadBotContainers(gomock.Any()).Return([]types.Container{
+		{ID: "container-id", Names: []string{"/" + botConfig.ContainerName()}, State: "exited"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	sup.handleBots(w, httptest.NewRequest(http.MethodGet, PathBots, nil))
+	r.Equal(http.StatusOK, w.Code)
+
+	var bots []*BotInfo
+	r.NoError(json.Unmarshal(w.Body.Bytes(), &bots))
+	r.Len(bots, 1)
+	r.Equal("bot-name", bots[0].Name)
+	r.Equal(digest, bots[0].ImageDigest)
+	r.Equal(uint(1), *bots[0].ShardID)
+	r.Equal("exited", bots[0].State)
+	r.Equal(1, bots[0].Restarts)
+	r.Equal(1.5, bots[0].CPUPercent)
+	r.Equal(uint64(100), bots[0].MemoryBytes)
+}
+
+func TestHandleBotStop(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	botManager := mock_lifecycle.NewMockBotLifecycleManager(ctrl)
+
+	sup := &SupervisorService{ctx: context.Background()}
+	sup.botLifecycle.BotManager = botManager
+
+	botManager.EXPECT().StopBot(gomock.Any(), "0xbot").Return(nil)
+	w := httptest.NewRecorder()
+	sup.handleBotStop(w, httptest.NewRequest(http.MethodPost, PathBotStop, bytes.NewBufferString(`{"botId":"0xbot"}`)))
+	r.Equal(http.StatusOK, w.Code)
+
+	botManager.EXPECT().StopBot(gomock.Any(), "0xother").Return(lifecycle.ErrBotNotFound)
+	w = httptest.NewRecorder()
+	sup.handleBotStop(w, httptest.NewRequest(http.MethodPost, PathBotStop, bytes.NewBufferString(`{"botId":"0xother"}`)))
+	r.Equal(http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	sup.handleBotStop(w, httptest.NewRequest(http.MethodPost, PathBotStop, bytes.NewBufferString(`{}`)))
+	r.Equal(http.StatusBadRequest, w.Code)
+}
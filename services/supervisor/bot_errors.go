@@ -0,0 +1,161 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// PathBotErrors is the admin endpoint which serves the last evaluation errors of the bots.
+const PathBotErrors = "/bots/errors"
+
+const (
+	botEvaluationErrorPrefix = "agent.error.evaluate."
+	botStderrMetricName      = "stderr"
+
+	defaultBotErrorsPerBot       = 20
+	defaultBotStderrTailLines    = 20
+	maxBotStderrLength           = 2048
+	defaultBotStderrCaptureDelay = time.Minute
+)
+
+// BotError is an evaluation error of a bot.
+type BotError struct {
+	Timestamp string `json:"timestamp"`
+	Name      string `json:"name"`
+	Details   string `json:"details"`
+	Stderr    string `json:"stderr,omitempty"`
+}
+
+// botErrors retains the last evaluation errors of each bot.
+type botErrors struct {
+	maxPerBot    int
+	errors       map[string][]*BotError
+	lastCaptures map[string]time.Time
+	mu           sync.Mutex
+}
+
+func newBotErrors(maxPerBot int) *botErrors {
+	return &botErrors{
+		maxPerBot:    maxPerBot,
+		errors:       make(map[string][]*BotError),
+		lastCaptures: make(map[string]time.Time),
+	}
+}
+
+// Add adds the error and tells if the stderr of the bot should be captured.
+func (be *botErrors) Add(botID string, botErr *BotError) (shouldCapture bool) {
+	botID = strings.ToLower(botID)
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	errs := append(be.errors[botID], botErr)
+	if len(errs) > be.maxPerBot {
+		errs = errs[len(errs)-be.maxPerBot:]
+	}
+	be.errors[botID] = errs
+	if time.Since(be.lastCaptures[botID]) < defaultBotStderrCaptureDelay {
+		return false
+	}
+	be.lastCaptures[botID] = time.Now()
+	return true
+}
+
+// SetStderr attaches the captured stderr to the error.
+func (be *botErrors) SetStderr(botErr *BotError, stderr string) {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	botErr.Stderr = stderr
+}
+
+// Get returns a copy of the errors of the bot.
+func (be *botErrors) Get(botID string) []BotError {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	errs := make([]BotError, 0, len(be.errors[strings.ToLower(botID)]))
+	for _, botErr := range be.errors[strings.ToLower(botID)] {
+		errs = append(errs, *botErr)
+	}
+	return errs
+}
+
+func (sup *SupervisorService) handleAgentMetrics(payload *protocol.AgentMetricList) error {
+	if sup.prometheus != nil {
+		sup.prometheus.HandleAgentMetrics(payload)
+	}
+	if sup.botStats != nil {
+		sup.botStats.HandleAgentMetrics(payload)
+	}
+	if sup.alerting != nil {
+		sup.alerting.HandleAgentMetrics(payload)
+	}
+	for _, metric := range payload.Metrics {
+		if metric.Name == metrics.MetricActionRestart {
+			sup.botRestarts.Add(metric.AgentId)
+			continue
+		}
+		if !strings.HasPrefix(metric.Name, botEvaluationErrorPrefix) {
+			continue
+		}
+		botErr := &BotError{
+			Timestamp: metric.Timestamp,
+			Name:      metric.Name,
+			Details:   metric.Details,
+		}
+		if sup.botErrors.Add(metric.AgentId, botErr) {
+			go sup.captureBotStderr(metric.AgentId, botErr)
+		}
+	}
+	return nil
+}
+
+// captureBotStderr attaches the stderr tail of the bot container to the error and
+// publishes it as a bot error metric.
+func (sup *SupervisorService) captureBotStderr(botID string, botErr *BotError) {
+	logger := log.WithField("bot", botID)
+	botContainers, err := sup.botLifecycle.BotClient.LoadBotContainers(sup.ctx)
+	if err != nil {
+		logger.WithError(err).Warn("failed to load the bot containers to capture stderr")
+		return
+	}
+	for _, container := range botContainers {
+		if !strings.EqualFold(container.Labels[docker.LabelFortaBotID], botID) {
+			continue
+		}
+		stderr, err := sup.client.GetContainerStderrLogs(
+			sup.ctx, container.ID, strconv.Itoa(defaultBotStderrTailLines), maxBotStderrLength,
+		)
+		if err != nil {
+			logger.WithError(err).Warn("failed to get the bot container stderr")
+			return
+		}
+		if len(stderr) == 0 {
+			return
+		}
+		sup.botErrors.SetStderr(botErr, stderr)
+		metrics.NewLifecycleClient(sup.msgClient).BotError(botStderrMetricName, errors.New(stderr), botID)
+		return
+	}
+}
+
+func (sup *SupervisorService) handleBotErrors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	botID := r.URL.Query().Get("botId")
+	if len(botID) == 0 {
+		http.Error(w, "bot id is required", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sup.botErrors.Get(botID))
+}
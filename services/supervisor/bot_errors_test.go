@@ -0,0 +1,73 @@
+package supervisor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/docker"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	mock_containers "github.com/forta-network/forta-node/services/components/containers/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotErrors(t *testing.T) {
+	r := require.New(t)
+
+	be := newBotErrors(2)
+	r.True(be.Add("0xBOT", &BotError{Name: "1"}))
+	r.False(be.Add("0xbot", &BotError{Name: "2"}))
+	r.False(be.Add("0xbot", &BotError{Name: "3"}))
+
+	// only the last errors are retained
+	r.Equal([]BotError{{Name: "2"}, {Name: "3"}}, be.Get("0xBot"))
+	r.Empty(be.Get("0xother"))
+}
+
+func TestHandleAgentMetrics(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	dockerClient := mock_clients.NewMockDockerClient(ctrl)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+	botClient := mock_containers.NewMockBotClient(ctrl)
+
+	sup := &SupervisorService{
+		ctx:       context.Background(),
+		client:    dockerClient,
+		msgClient: msgClient,
+		botErrors: newBotErrors(defaultBotErrorsPerBot),
+	}
+	sup.botLifecycle.BotClient = botClient
+
+	botClient.EXPECT().LoadBotContainers(gomock.Any()).Return([]types.Container{
+		{ID: "container-id", Labels: map[string]string{docker.LabelFortaBotID: "0xbot"}},
+	}, nil)
+	dockerClient.EXPECT().GetContainerStderrLogs(gomock.Any(), "container-id", gomock.Any(), maxBotStderrLength).
+		Return("panic: something", nil)
+	published := make(chan struct{})
+	msgClient.EXPECT().PublishProto(gomock.Any(), gomock.Any()).Do(func(interface{}, interface{}) {
+		close(published)
+	})
+
+	r.NoError(sup.handleAgentMetrics(&protocol.AgentMetricList{
+		Metrics: []*protocol.AgentMetric{
+			{AgentId: "0xbot", Name: "agent.status.running"},
+			{AgentId: "0xbot", Name: "agent.error.evaluate.tx", Details: "code=Internal message=panic"},
+		},
+	}))
+
+	select {
+	case <-published:
+	case <-time.After(time.Second * 5):
+		r.FailNow("stderr was not published")
+	}
+	r.Equal([]BotError{{
+		Name:    "agent.error.evaluate.tx",
+		Details: "code=Internal message=panic",
+		Stderr:  "panic: something",
+	}}, sup.botErrors.Get("0xbot"))
+}
@@ -0,0 +1,40 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// PathBotStats is the admin endpoint which serves the rolling success rate, latency and error
+// statistics of the bots.
+const PathBotStats = "/bots/stats"
+
+const defaultBotStatsInterval = time.Minute
+
+// exportBotStats periodically updates the bot stats in the Prometheus metrics.
+func (sup *SupervisorService) exportBotStats() {
+	ticker := time.NewTicker(defaultBotStatsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sup.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		sup.prometheus.SetBotStats(sup.botStats.Stats())
+	}
+}
+
+func (sup *SupervisorService) handleBotStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if botID := r.URL.Query().Get("botId"); len(botID) > 0 {
+		json.NewEncoder(w).Encode(sup.botStats.BotStats(botID))
+		return
+	}
+	json.NewEncoder(w).Encode(sup.botStats.Stats())
+}
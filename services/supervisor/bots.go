@@ -1,12 +1,16 @@
 package supervisor
 
 import (
+	"math/rand"
 	"time"
 
+	"github.com/forta-network/forta-node/config"
 	log "github.com/sirupsen/logrus"
 )
 
-// refreshBotContainers refreshes bot containers every 15 seconds.
+const defaultBotRefreshInterval = time.Minute
+
+// refreshBotContainers refreshes bot containers at the registry check interval.
 // This allows us to blast the latest assignment list very often
 // and keep bot containers and clients in order.
 func (sup *SupervisorService) refreshBotContainers() {
@@ -16,12 +20,32 @@ func (sup *SupervisorService) refreshBotContainers() {
 		case <-sup.ctx.Done():
 			return
 
-		case <-time.After(time.Minute):
+		case <-time.After(botRefreshDelay(sup.registryConfig())):
+			sup.doRefreshBotContainers()
+
+		case <-sup.botRefreshCh:
 			sup.doRefreshBotContainers()
 		}
 	}
 }
 
+// botRefreshDelay adds a random jitter to the check interval so that the nodes do not
+// hit the registry at the same time. The jitter never exceeds the interval.
+func botRefreshDelay(cfg config.RegistryConfig) time.Duration {
+	interval := time.Duration(cfg.CheckIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultBotRefreshInterval
+	}
+	jitter := time.Duration(cfg.CheckJitterSeconds) * time.Second
+	if jitter > interval {
+		jitter = interval
+	}
+	if jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int63n(int64(jitter)))
+}
+
 func (sup *SupervisorService) doRefreshBotContainers() {
 	if err := sup.botLifecycle.BotManager.ManageBots(sup.ctx); err != nil {
 		log.WithError(err).Error("error while managing bots")
@@ -37,4 +61,26 @@ func (sup *SupervisorService) doRefreshBotContainers() {
 	if err := sup.botLifecycle.BotManager.ExitInactiveBots(sup.ctx); err != nil {
 		log.WithError(err).Error("error while exiting inactive bots")
 	}
+	// the launched bots are capped right away
+	sup.enforceBotResourceBudget()
+}
+
+// prefetchBotImages pulls the images of the upcoming bots in the background so that the bot
+// refreshes do not wait for them.
+func (sup *SupervisorService) prefetchBotImages() {
+	prefetcher := sup.botLifecycle.BotImagePrefetcher
+	interval := time.Duration(sup.config.Config.BotPrefetch.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultBotRefreshInterval
+	}
+	for {
+		if err := prefetcher.PrefetchBotImages(sup.ctx); err != nil {
+			log.WithError(err).Warn("error while prefetching bot images")
+		}
+		select {
+		case <-sup.ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
 }
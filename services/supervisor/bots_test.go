@@ -3,9 +3,12 @@ package supervisor
 import (
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/forta-network/forta-node/config"
 	mock_lifecycle "github.com/forta-network/forta-node/services/components/lifecycle/mocks"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBotManagement(t *testing.T) {
@@ -31,3 +34,19 @@ func TestBotManagement(t *testing.T) {
 	supervisor.doRefreshBotContainers()
 	supervisor.doRefreshBotContainers()
 }
+
+func TestBotRefreshDelay(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(defaultBotRefreshInterval, botRefreshDelay(config.RegistryConfig{}))
+
+	for i := 0; i < 10; i++ {
+		delay := botRefreshDelay(config.RegistryConfig{CheckIntervalSeconds: 60, CheckJitterSeconds: 10})
+		r.GreaterOrEqual(delay, time.Minute)
+		r.Less(delay, time.Minute+time.Second*10)
+	}
+
+	// the jitter does not exceed the interval
+	delay := botRefreshDelay(config.RegistryConfig{CheckIntervalSeconds: 1, CheckJitterSeconds: 10})
+	r.Less(delay, time.Second*2)
+}
@@ -0,0 +1,123 @@
+package supervisor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// botCPUShare is the CPU usage of a bot compared to its share of the CPUs.
+type botCPUShare struct {
+	BotID        string
+	CPUPercent   float64
+	SharePercent float64
+	// Checks is how many resource checks in a row the bot is over its share.
+	Checks int
+}
+
+// botCPUWatchdog detects the bots which keep using more CPU than the share from their weights.
+type botCPUWatchdog struct {
+	cfg       config.ResourcesConfig
+	cpuCount  int
+	overShare map[string]*botCPUShare
+	mu        sync.RWMutex
+}
+
+func newBotCPUWatchdog(cfg config.ResourcesConfig, cpuCount int) *botCPUWatchdog {
+	return &botCPUWatchdog{
+		cfg:       cfg,
+		cpuCount:  cpuCount,
+		overShare: make(map[string]*botCPUShare),
+	}
+}
+
+// Check compares the latest CPU usage of the running bots with their shares and returns the bots
+// which are over their share for enough checks in a row.
+func (w *botCPUWatchdog) Check(botConfigs []config.AgentConfig, resources map[string]*docker.ContainerResources) (reported []*botCPUShare) {
+	var totalWeight int64
+	for _, botConfig := range botConfigs {
+		totalWeight += config.GetBotCPUWeight(w.cfg, botConfig)
+	}
+	if totalWeight == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	overShare := make(map[string]*botCPUShare)
+	for _, botConfig := range botConfigs {
+		botResources, ok := resources[botConfig.ContainerName()]
+		if !ok {
+			continue
+		}
+		weight := config.GetBotCPUWeight(w.cfg, botConfig)
+		sharePercent := float64(weight) / float64(totalWeight) * float64(w.cpuCount) * 100
+		if botResources.CPUPercent <= sharePercent*w.cfg.CPUFairness.OverShareRatio {
+			continue
+		}
+		share := &botCPUShare{
+			BotID:        botConfig.ID,
+			CPUPercent:   botResources.CPUPercent,
+			SharePercent: sharePercent,
+			Checks:       1,
+		}
+		if prev, ok := w.overShare[botConfig.ID]; ok {
+			share.Checks = prev.Checks + 1
+		}
+		overShare[botConfig.ID] = share
+		if share.Checks >= w.cfg.CPUFairness.OverShareChecks {
+			reported = append(reported, share)
+		}
+	}
+	w.overShare = overShare
+	return
+}
+
+// Health implements the health.Reporter interface.
+func (w *botCPUWatchdog) Health() health.Reports {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var botIDs []string
+	for botID, share := range w.overShare {
+		if share.Checks >= w.cfg.CPUFairness.OverShareChecks {
+			botIDs = append(botIDs, botID)
+		}
+	}
+	if len(botIDs) == 0 {
+		return health.Reports{
+			{Name: "bots.cpu.over-share", Status: health.StatusOK},
+		}
+	}
+	sort.Strings(botIDs)
+	return health.Reports{
+		{Name: "bots.cpu.over-share", Status: health.StatusLagging, Details: strings.Join(botIDs, ",")},
+	}
+}
+
+// checkBotCPUShares reports the bots which keep using more than their CPU share.
+func (sup *SupervisorService) checkBotCPUShares(resources map[string]*docker.ContainerResources) {
+	if sup.cpuWatchdog == nil || sup.botLifecycle.BotManager == nil {
+		return
+	}
+	now := time.Now()
+	for _, share := range sup.cpuWatchdog.Check(sup.botLifecycle.BotManager.RunningBots(), resources) {
+		if share.Checks == sup.config.Config.ResourcesConfig.CPUFairness.OverShareChecks {
+			log.WithFields(log.Fields{
+				"bot":          share.BotID,
+				"cpuPercent":   fmt.Sprintf("%.1f", share.CPUPercent),
+				"sharePercent": fmt.Sprintf("%.1f", share.SharePercent),
+			}).Warn("bot is using more than its cpu share")
+		}
+		metrics.SendAgentMetrics(sup.msgClient, metrics.GetBotCPUShareMetrics(share.BotID, now, share.CPUPercent, share.SharePercent))
+	}
+}
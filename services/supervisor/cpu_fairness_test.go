@@ -0,0 +1,48 @@
+package supervisor
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotCPUWatchdog(t *testing.T) {
+	r := require.New(t)
+
+	cfg := config.ResourcesConfig{
+		CPUFairness: config.BotCPUFairnessConfig{
+			Enable:          true,
+			BotPriorities:   map[string]int{"0xbot1": 10},
+			OverShareRatio:  2,
+			OverShareChecks: 2,
+		},
+	}
+	bot1 := config.AgentConfig{ID: "0xbot1"}
+	bot2 := config.AgentConfig{ID: "0xbot2"}
+	botConfigs := []config.AgentConfig{bot1, bot2}
+	w := newBotCPUWatchdog(cfg, 1)
+
+	// the first bot has 2/3 and the second bot has 1/3 of the cpu
+	resources := map[string]*docker.ContainerResources{
+		bot1.ContainerName(): {CPUPercent: 100},
+		bot2.ContainerName(): {CPUPercent: 70},
+	}
+	r.Empty(w.Check(botConfigs, resources))
+	r.Equal(health.StatusOK, w.Health()[0].Status)
+
+	reported := w.Check(botConfigs, resources)
+	r.Len(reported, 1)
+	r.Equal(bot2.ID, reported[0].BotID)
+	r.Equal(2, reported[0].Checks)
+	r.InDelta(33.3, reported[0].SharePercent, 0.1)
+	r.Equal(health.StatusLagging, w.Health()[0].Status)
+	r.Equal(bot2.ID, w.Health()[0].Details)
+
+	// the bot is not reported after it uses less than its share
+	resources[bot2.ContainerName()].CPUPercent = 30
+	r.Empty(w.Check(botConfigs, resources))
+	r.Equal(health.StatusOK, w.Health()[0].Status)
+}
@@ -89,6 +89,7 @@ func (sup *SupervisorService) ensureUp(knownContainer *Container, foundContainer
 			services.TriggerExit(0)
 			return nil
 		}
+		sup.crashReports.CaptureContainer(sup.ctx, sup.client, knownContainer.Name, foundContainer.ID, containerDetails.State.StartedAt)
 
 		logger.Warn("starting exited container")
 		_, err = sup.client.StartContainer(sup.ctx, knownContainer.Config)
@@ -0,0 +1,78 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// PathLogLevels is the admin endpoint which shows and changes the log levels of the components.
+const PathLogLevels = "/log/levels"
+
+func (sup *SupervisorService) handleLogLevels(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sup.logLevels())
+
+	case http.MethodPut:
+		var req messaging.LogLevelPayload
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode the log level request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if !logging.IsComponent(req.Component) {
+			http.Error(w, fmt.Sprintf("unknown component: %s", req.Component), http.StatusBadRequest)
+			return
+		}
+		lvl, err := log.ParseLevel(req.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Level = lvl.String()
+
+		if err := sup.setLogLevel(req); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// setLogLevel changes the level here if the component is in the supervisor and lets the other containers know.
+func (sup *SupervisorService) setLogLevel(req messaging.LogLevelPayload) error {
+	if err := logging.HandleLevelChange(req); err != nil {
+		return err
+	}
+	sup.msgClient.Publish(messaging.SubjectLogLevel, req)
+
+	sup.logLevelsMu.Lock()
+	sup.remoteLogLevels[req.Component] = req.Level
+	sup.logLevelsMu.Unlock()
+	return nil
+}
+
+// logLevels returns the configured levels of the components, updated with the runtime changes.
+func (sup *SupervisorService) logLevels() map[string]string {
+	levels := make(map[string]string)
+	for _, component := range logging.Components {
+		levels[component] = sup.config.Config.Log.ComponentConfig(component).Level
+	}
+	sup.logLevelsMu.Lock()
+	for component, level := range sup.remoteLogLevels {
+		levels[component] = level
+	}
+	sup.logLevelsMu.Unlock()
+	for component, level := range logging.Levels() {
+		levels[component] = level
+	}
+	return levels
+}
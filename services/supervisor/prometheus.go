@@ -0,0 +1,47 @@
+package supervisor
+
+import (
+	"time"
+
+	"github.com/forta-network/forta-node/clients/docker"
+	log "github.com/sirupsen/logrus"
+)
+
+// PathMetrics is the admin endpoint which serves the node metrics in the Prometheus format.
+const PathMetrics = "/metrics"
+
+const defaultContainerResourcesInterval = time.Minute
+
+// collectContainerResources periodically updates the resource usage of the running containers.
+func (sup *SupervisorService) collectContainerResources() {
+	ticker := time.NewTicker(defaultContainerResourcesInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sup.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		containers, err := sup.client.GetContainers(sup.ctx)
+		if err != nil {
+			log.WithError(err).Warn("failed to get the containers to collect the resource usage")
+			continue
+		}
+		resources := make(map[string]*docker.ContainerResources)
+		for _, container := range containers {
+			if container.State != "running" {
+				continue
+			}
+			containerResources, err := sup.client.GetContainerResources(sup.ctx, container.ID)
+			if err != nil {
+				log.WithError(err).WithField("container", container.Names[0][1:]).Debug("failed to get the container resources")
+				continue
+			}
+			resources[container.Names[0][1:]] = containerResources
+		}
+		sup.prometheus.SetContainerResources(resources)
+		sup.resources.Set(resources)
+		sup.checkBotCPUShares(resources)
+	}
+}
@@ -0,0 +1,373 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/inspect"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/components/alerting"
+	log "github.com/sirupsen/logrus"
+)
+
+const readinessDialTimeout = time.Second * 5
+
+// Readiness checks
+const (
+	ReadinessCheckRPC          = "rpc"
+	ReadinessCheckTrace        = "trace"
+	ReadinessCheckConnectivity = "connectivity"
+	ReadinessCheckContainers   = "containers"
+	ReadinessCheckDisk         = "disk"
+	ReadinessCheckMemory       = "memory"
+)
+
+// readinessWeights tell how much each check counts in the total score. The RPC quality
+// matters the most because the node can't scan anything without it.
+var readinessWeights = map[string]float64{
+	ReadinessCheckRPC:          3,
+	ReadinessCheckTrace:        1,
+	ReadinessCheckConnectivity: 2,
+	ReadinessCheckContainers:   2,
+	ReadinessCheckDisk:         1,
+	ReadinessCheckMemory:       1,
+}
+
+// readinessCheck is the score of a check between 0 and 1.
+type readinessCheck struct {
+	Name    string
+	Score   float64
+	Details string
+}
+
+// readinessInfo is the result of the latest self-inspection.
+type readinessInfo struct {
+	Score  float64
+	Checks []*readinessCheck
+}
+
+func (sup *SupervisorService) readinessEnabled() bool {
+	return !sup.config.Config.Readiness.Disable
+}
+
+func (sup *SupervisorService) readinessCheckInterval() time.Duration {
+	return time.Duration(sup.config.Config.Readiness.CheckIntervalSeconds) * time.Second
+}
+
+func (sup *SupervisorService) checkReadiness() {
+	ticker := time.NewTicker(sup.readinessCheckInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sup.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info := sup.doCheckReadiness()
+		sup.lastReadinessCheck.Set()
+		sup.readinessMu.Lock()
+		sup.readiness = info
+		sup.readinessMu.Unlock()
+
+		checkScores := make(map[string]float64)
+		for _, check := range info.Checks {
+			checkScores[check.Name] = check.Score
+		}
+		sup.prometheus.SetReadiness(info.Score, checkScores)
+		log.WithFields(log.Fields{
+			"component": "readiness",
+			"score":     info.Score,
+		}).Debug("checked the readiness")
+	}
+}
+
+func (sup *SupervisorService) doCheckReadiness() *readinessInfo {
+	cfg := sup.config.Config
+
+	sup.readinessMu.RLock()
+	inspection := sup.latestInspection
+	sup.readinessMu.RUnlock()
+	var indicators map[string]float64
+	if inspection != nil {
+		indicators = inspection.Indicators
+	}
+
+	var checks []*readinessCheck
+	if check := rpcReadiness(indicators, uint64(cfg.ChainID)); check != nil {
+		checks = append(checks, check)
+	}
+	if cfg.Trace.Enabled {
+		if check := traceReadiness(indicators); check != nil {
+			checks = append(checks, check)
+		}
+	}
+	checks = append(checks, sup.connectivityReadiness())
+	// there are no service containers to check when the orchestrator runs them
+	if !cfg.Orchestrator.Enable {
+		if check, err := sup.containersReadiness(); err != nil {
+			log.WithError(err).Warn("failed to check the readiness of the containers")
+		} else {
+			checks = append(checks, check)
+		}
+	}
+	if free, err := alerting.DiskFree(cfg.FortaDir); err != nil {
+		log.WithError(err).Warn("failed to check the free disk space")
+	} else {
+		checks = append(checks, diskReadiness(free, cfg.Readiness.MinDiskSpaceGB))
+	}
+	if check := memoryReadiness(indicators, cfg.Readiness.MinMemoryPercent); check != nil {
+		checks = append(checks, check)
+	}
+
+	return &readinessInfo{Score: readinessScore(checks), Checks: checks}
+}
+
+// readinessScore is the weighted average of the check scores.
+func readinessScore(checks []*readinessCheck) float64 {
+	var total, weights float64
+	for _, check := range checks {
+		weight := readinessWeights[check.Name]
+		total += check.Score * weight
+		weights += weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return total / weights
+}
+
+// rpcReadiness scores the scan and the proxy APIs from the latest inspection.
+func rpcReadiness(indicators map[string]float64, chainID uint64) *readinessCheck {
+	if indicators == nil {
+		return nil
+	}
+	var failed []string
+	checkIndicator := func(name string, ok bool) {
+		if !ok {
+			failed = append(failed, name)
+		}
+	}
+	checkIndicator(inspect.IndicatorScanAPIAccessible, indicators[inspect.IndicatorScanAPIAccessible] == inspect.ResultSuccess)
+	checkIndicator(inspect.IndicatorScanAPIChainID, indicators[inspect.IndicatorScanAPIChainID] == float64(chainID))
+	checkIndicator(inspect.IndicatorProxyAPIAccessible, indicators[inspect.IndicatorProxyAPIAccessible] == inspect.ResultSuccess)
+	checkIndicator(inspect.IndicatorProxyAPIChainID, indicators[inspect.IndicatorProxyAPIChainID] == float64(chainID))
+	// the earliest supported block is reported if the history is supported
+	checkIndicator(inspect.IndicatorProxyAPIHistorySupport, indicators[inspect.IndicatorProxyAPIHistorySupport] >= 0)
+	return indicatorsReadiness(ReadinessCheckRPC, 5, failed)
+}
+
+// traceReadiness scores the trace API from the latest inspection.
+func traceReadiness(indicators map[string]float64) *readinessCheck {
+	if indicators == nil {
+		return nil
+	}
+	var failed []string
+	for _, name := range []string{inspect.IndicatorTraceAccessible, inspect.IndicatorTraceSupported} {
+		if indicators[name] != inspect.ResultSuccess {
+			failed = append(failed, name)
+		}
+	}
+	return indicatorsReadiness(ReadinessCheckTrace, 2, failed)
+}
+
+func indicatorsReadiness(name string, total int, failed []string) *readinessCheck {
+	check := &readinessCheck{
+		Name:    name,
+		Score:   float64(total-len(failed)) / float64(total),
+		Details: fmt.Sprintf("%d/%d indicators ok", total-len(failed), total),
+	}
+	if len(failed) > 0 {
+		check.Details += fmt.Sprintf(" (failed: %s)", strings.Join(failed, ", "))
+	}
+	return check
+}
+
+// connectivityTargets returns the addresses of the external services which the node depends on.
+func connectivityTargets(cfg config.Config) []string {
+	var targets []string
+	seen := make(map[string]bool)
+	addTarget := func(target string) {
+		if len(target) == 0 || seen[target] {
+			return
+		}
+		seen[target] = true
+		targets = append(targets, target)
+	}
+	for _, rawURL := range []string{cfg.Registry.JsonRpc.Url, cfg.Registry.IPFS.GatewayURL} {
+		addTarget(urlTarget(rawURL))
+	}
+	if !cfg.Publish.SkipPublish {
+		addTarget(urlTarget(cfg.Publish.APIURL))
+	}
+	for _, target := range cfg.Readiness.ConnectivityTargets {
+		addTarget(target)
+	}
+	return targets
+}
+
+// urlTarget returns the host and the port of an external URL. The node containers are skipped.
+func urlTarget(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || len(u.Hostname()) == 0 || !strings.Contains(u.Hostname(), ".") {
+		return ""
+	}
+	port := u.Port()
+	if len(port) == 0 {
+		port = "80"
+		if u.Scheme == "https" || u.Scheme == "wss" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+func (sup *SupervisorService) connectivityReadiness() *readinessCheck {
+	targets := connectivityTargets(sup.config.Config)
+	var unreachable []string
+	dialer := &net.Dialer{Timeout: readinessDialTimeout}
+	for _, target := range targets {
+		ctx, cancel := context.WithTimeout(sup.ctx, readinessDialTimeout)
+		conn, err := dialer.DialContext(ctx, "tcp", target)
+		cancel()
+		if err != nil {
+			unreachable = append(unreachable, target)
+			continue
+		}
+		conn.Close()
+	}
+	return reachabilityReadiness(len(targets), unreachable)
+}
+
+func reachabilityReadiness(total int, unreachable []string) *readinessCheck {
+	check := &readinessCheck{Name: ReadinessCheckConnectivity, Score: 1, Details: "no targets"}
+	if total == 0 {
+		return check
+	}
+	check.Score = float64(total-len(unreachable)) / float64(total)
+	check.Details = fmt.Sprintf("%d/%d targets reachable", total-len(unreachable), total)
+	if len(unreachable) > 0 {
+		check.Details += fmt.Sprintf(" (unreachable: %s)", strings.Join(unreachable, ", "))
+	}
+	return check
+}
+
+// containersReadiness scores the ratio of the running service containers.
+func (sup *SupervisorService) containersReadiness() (*readinessCheck, error) {
+	containers, err := sup.client.GetContainers(sup.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the containers: %v", err)
+	}
+	running := make(map[string]bool)
+	for _, container := range containers {
+		running[container.ID] = container.State == "running"
+	}
+
+	var total int
+	var notRunning []string
+	sup.mu.RLock()
+	for _, container := range sup.containers {
+		if container.IsAgent {
+			continue
+		}
+		total++
+		if !running[container.ID] {
+			notRunning = append(notRunning, container.Name)
+		}
+	}
+	sup.mu.RUnlock()
+	if total < config.DockerSupervisorManagedContainers {
+		total = config.DockerSupervisorManagedContainers
+	}
+
+	runningCount := total - len(notRunning)
+	if runningCount < 0 {
+		runningCount = 0
+	}
+	check := &readinessCheck{
+		Name:    ReadinessCheckContainers,
+		Score:   float64(runningCount) / float64(total),
+		Details: fmt.Sprintf("%d/%d running", runningCount, total),
+	}
+	if len(notRunning) > 0 {
+		check.Details += fmt.Sprintf(" (not running: %s)", strings.Join(notRunning, ", "))
+	}
+	return check, nil
+}
+
+// diskReadiness drops the score linearly while the free space goes below the minimum.
+func diskReadiness(free uint64, minDiskSpaceGB float64) *readinessCheck {
+	freeGB := float64(free) / (1 << 30)
+	return &readinessCheck{
+		Name:    ReadinessCheckDisk,
+		Score:   headroomScore(freeGB, minDiskSpaceGB),
+		Details: fmt.Sprintf("%.2f GiB free (min=%.2f GiB)", freeGB, minDiskSpaceGB),
+	}
+}
+
+// memoryReadiness drops the score linearly while the available memory percentage goes below
+// the minimum.
+func memoryReadiness(indicators map[string]float64, minMemoryPercent float64) *readinessCheck {
+	total := indicators[inspect.IndicatorResourcesMemoryTotal]
+	available := indicators[inspect.IndicatorResourcesMemoryAvailable]
+	if total <= 0 || available < 0 {
+		return nil
+	}
+	availablePercent := available / total * 100
+	return &readinessCheck{
+		Name:    ReadinessCheckMemory,
+		Score:   headroomScore(availablePercent, minMemoryPercent),
+		Details: fmt.Sprintf("%.1f%% available (min=%.1f%%)", availablePercent, minMemoryPercent),
+	}
+}
+
+func headroomScore(value, min float64) float64 {
+	if value >= min {
+		return 1
+	}
+	return value / min
+}
+
+// storeInspectionResults keeps the latest inspection for the readiness checks.
+func (sup *SupervisorService) storeInspectionResults(results *protocol.InspectionResults) {
+	sup.readinessMu.Lock()
+	sup.latestInspection = results
+	sup.readinessMu.Unlock()
+}
+
+func (sup *SupervisorService) readinessHealthReports() health.Reports {
+	sup.readinessMu.RLock()
+	reports := readinessReports(sup.readiness, sup.config.Config.Readiness.MinScore)
+	sup.readinessMu.RUnlock()
+
+	checkReport := &health.Report{Name: "event.readiness-check.time"}
+	checkReport.Details, checkReport.Status = sup.lastReadinessCheck.Check(sup.readinessCheckInterval() * 2)
+	return append(reports, checkReport)
+}
+
+// readinessReports reports the score and marks it as lagging if it is below the minimum.
+func readinessReports(info *readinessInfo, minScore float64) health.Reports {
+	if info == nil {
+		return nil
+	}
+	scoreReport := &health.Report{Name: "readiness.score", Status: health.StatusOK, Details: fmt.Sprintf("%.2f", info.Score)}
+	if info.Score < minScore {
+		scoreReport.Status = health.StatusLagging
+		scoreReport.Details += fmt.Sprintf(" - below %.2f", minScore)
+	}
+	reports := health.Reports{scoreReport}
+	for _, check := range info.Checks {
+		reports = append(reports, &health.Report{
+			Name:    fmt.Sprintf("readiness.%s", check.Name),
+			Status:  health.StatusInfo,
+			Details: fmt.Sprintf("%.2f: %s", check.Score, check.Details),
+		})
+	}
+	return reports
+}
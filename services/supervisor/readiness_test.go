@@ -0,0 +1,104 @@
+package supervisor
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/inspect"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessScore(t *testing.T) {
+	r := require.New(t)
+
+	r.Zero(readinessScore(nil))
+	r.Equal(1.0, readinessScore([]*readinessCheck{
+		{Name: ReadinessCheckRPC, Score: 1},
+		{Name: ReadinessCheckDisk, Score: 1},
+	}))
+	// rpc weighs 3 and disk weighs 1
+	r.InDelta(0.875, readinessScore([]*readinessCheck{
+		{Name: ReadinessCheckRPC, Score: 1},
+		{Name: ReadinessCheckDisk, Score: 0.5},
+	}), 0.0001)
+}
+
+func TestRPCReadiness(t *testing.T) {
+	r := require.New(t)
+
+	r.Nil(rpcReadiness(nil, 1))
+
+	check := rpcReadiness(map[string]float64{
+		inspect.IndicatorScanAPIAccessible:      inspect.ResultSuccess,
+		inspect.IndicatorScanAPIChainID:         1,
+		inspect.IndicatorProxyAPIAccessible:     inspect.ResultSuccess,
+		inspect.IndicatorProxyAPIChainID:        137,
+		inspect.IndicatorProxyAPIHistorySupport: 0,
+	}, 1)
+	r.Equal(0.8, check.Score)
+	r.Equal("4/5 indicators ok (failed: proxy-api.chain-id)", check.Details)
+
+	check = traceReadiness(map[string]float64{
+		inspect.IndicatorTraceAccessible: inspect.ResultSuccess,
+		inspect.IndicatorTraceSupported:  inspect.ResultFailure,
+	})
+	r.Equal(0.5, check.Score)
+}
+
+func TestHeadroomReadiness(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(1.0, diskReadiness(20<<30, 10).Score)
+	r.Equal(0.5, diskReadiness(5<<30, 10).Score)
+
+	r.Nil(memoryReadiness(map[string]float64{}, 20))
+	r.Nil(memoryReadiness(map[string]float64{
+		inspect.IndicatorResourcesMemoryTotal:     16e9,
+		inspect.IndicatorResourcesMemoryAvailable: inspect.ResultUnknown,
+	}, 20))
+	check := memoryReadiness(map[string]float64{
+		inspect.IndicatorResourcesMemoryTotal:     16e9,
+		inspect.IndicatorResourcesMemoryAvailable: 1.6e9,
+	}, 20)
+	r.Equal(0.5, check.Score)
+	r.Equal("10.0% available (min=20.0%)", check.Details)
+}
+
+func TestConnectivityTargets(t *testing.T) {
+	r := require.New(t)
+
+	var cfg config.Config
+	cfg.Registry.JsonRpc.Url = "https://polygon-rpc.com"
+	cfg.Registry.IPFS.GatewayURL = "http://ipfs.forta.network:8080"
+	cfg.Publish.APIURL = "http://forta-public-api:8535"
+	cfg.Readiness.ConnectivityTargets = []string{"example.com:22", "polygon-rpc.com:443"}
+	r.Equal([]string{
+		"polygon-rpc.com:443", "ipfs.forta.network:8080", "example.com:22",
+	}, connectivityTargets(cfg))
+
+	check := reachabilityReadiness(4, []string{"example.com:22"})
+	r.Equal(0.75, check.Score)
+	r.Equal("3/4 targets reachable (unreachable: example.com:22)", check.Details)
+	r.Equal(1.0, reachabilityReadiness(0, nil).Score)
+}
+
+func TestReadinessReports(t *testing.T) {
+	r := require.New(t)
+
+	r.Nil(readinessReports(nil, 0.8))
+
+	reports := readinessReports(&readinessInfo{
+		Score: 0.7,
+		Checks: []*readinessCheck{
+			{Name: ReadinessCheckDisk, Score: 0.5, Details: "5.00 GiB free (min=10.00 GiB)"},
+		},
+	}, 0.8)
+	score, ok := reports.NameContains("readiness.score")
+	r.True(ok)
+	r.Equal(health.StatusLagging, score.Status)
+	r.Equal("0.70 - below 0.80", score.Details)
+	disk, ok := reports.NameContains("readiness.disk")
+	r.True(ok)
+	r.Equal("0.50: 5.00 GiB free (min=10.00 GiB)", disk.Details)
+}
@@ -0,0 +1,190 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// PathConfigReload is the admin endpoint which reloads the config and shows the last reload report.
+const PathConfigReload = "/config/reload"
+
+const defaultConfigWatchInterval = time.Second * 10
+
+// watchConfig reloads the config when the config file changes or a SIGHUP is received.
+func (sup *SupervisorService) watchConfig() {
+	cfg, err := config.GetConfigForContainer()
+	if err != nil {
+		log.WithError(err).Warn("failed to read the config - not watching the config file")
+		return
+	}
+	sup.reloadMu.Lock()
+	sup.loadedConfig = &cfg
+	sup.reloadMu.Unlock()
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	ticker := time.NewTicker(defaultConfigWatchInterval)
+	defer ticker.Stop()
+	lastModified := configModTime()
+	for {
+		select {
+		case <-sup.ctx.Done():
+			return
+
+		case <-sighup:
+			log.Info("received SIGHUP - reloading the config")
+
+		case <-ticker.C:
+			modified := configModTime()
+			if modified.Equal(lastModified) {
+				continue
+			}
+			lastModified = modified
+			log.Info("config file changed - reloading the config")
+		}
+		sup.reloadConfig()
+	}
+}
+
+// configModTime returns the latest modification time of the config files.
+func configModTime() (modTime time.Time) {
+	for _, configPath := range []string{config.DefaultContainerConfigPath, config.DefaultContainerWrappedConfigPath} {
+		info, err := os.Stat(configPath)
+		if err == nil && info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return
+}
+
+// reloadConfig applies the safe changes and reports the ones which need a restart. The changes
+// which need a restart are kept in the reports until the node is restarted.
+func (sup *SupervisorService) reloadConfig() *config.ReloadReport {
+	sup.reloadMu.Lock()
+	defer sup.reloadMu.Unlock()
+
+	if sup.loadedConfig == nil {
+		return &config.ReloadReport{Time: time.Now().UTC(), Error: errors.New("config is not loaded yet").Error()}
+	}
+	newCfg, err := config.GetConfigForContainer()
+	if err == nil {
+		err = config.Validate(newCfg)
+	}
+	if err != nil {
+		log.WithError(err).Error("failed to reload the config - keeping the current config")
+		report := &config.ReloadReport{Time: time.Now().UTC(), Error: err.Error()}
+		if sup.lastReload != nil {
+			report.RequiresRestart = sup.lastReload.RequiresRestart
+		}
+		sup.lastReload = report
+		sup.recordConfigReload(report)
+		return report
+	}
+
+	report := config.CheckReload(*sup.loadedConfig, newCfg)
+	if !report.HasChanges() {
+		return report
+	}
+	if len(report.Reloaded) > 0 {
+		sup.applyConfig(newCfg, report)
+		sup.msgClient.Publish(messaging.SubjectConfigReload, report)
+	}
+	if len(report.RequiresRestart) > 0 {
+		log.WithField("fields", report.RequiresRestart).Warn("config changes need a restart to take effect")
+	}
+	if sup.lastReload != nil {
+		report.RequiresRestart = appendMissing(sup.lastReload.RequiresRestart, report.RequiresRestart)
+	}
+	log.WithField("fields", report.Reloaded).Info("reloaded the config")
+	sup.recordConfigReload(report)
+
+	sup.loadedConfig = &newCfg
+	sup.lastReload = report
+	return report
+}
+
+// applyConfig applies the reloaded fields which are used by the supervisor.
+func (sup *SupervisorService) applyConfig(newCfg config.Config, report *config.ReloadReport) {
+	if report.IsReloaded("log") {
+		for _, component := range logging.Components {
+			oldLevel := sup.loadedConfig.Log.ComponentConfig(component).Level
+			newLevel := newCfg.Log.ComponentConfig(component).Level
+			if oldLevel == newLevel {
+				continue
+			}
+			req := messaging.LogLevelPayload{Component: component, Level: newLevel}
+			if err := sup.setLogLevel(req); err != nil {
+				log.WithError(err).WithField("component", component).Error("failed to reload the log level")
+			}
+		}
+	}
+	if report.IsReloaded("registry") {
+		sup.configMu.Lock()
+		sup.config.Config.Registry.CheckIntervalSeconds = newCfg.Registry.CheckIntervalSeconds
+		sup.config.Config.Registry.CheckJitterSeconds = newCfg.Registry.CheckJitterSeconds
+		sup.configMu.Unlock()
+	}
+	if report.IsReloaded("botPolicy") {
+		if policySetter, ok := sup.botLifecycleConfig.BotRegistry.(interface{ SetBotPolicy(config.BotPolicyConfig) }); ok {
+			policySetter.SetBotPolicy(newCfg.BotPolicy)
+			log.Info("reloaded the bot policy")
+		}
+	}
+}
+
+func (sup *SupervisorService) registryConfig() config.RegistryConfig {
+	sup.configMu.RLock()
+	defer sup.configMu.RUnlock()
+	return sup.config.Config.Registry
+}
+
+func appendMissing(fields []string, newFields []string) []string {
+	all := append([]string{}, fields...)
+	for _, newField := range newFields {
+		var found bool
+		for _, field := range fields {
+			if field == newField {
+				found = true
+				break
+			}
+		}
+		if !found {
+			all = append(all, newField)
+		}
+	}
+	return all
+}
+
+func (sup *SupervisorService) handleConfigReload(w http.ResponseWriter, r *http.Request) {
+	var report *config.ReloadReport
+	switch r.Method {
+	case http.MethodGet:
+		sup.reloadMu.Lock()
+		report = sup.lastReload
+		sup.reloadMu.Unlock()
+		if report == nil {
+			report = &config.ReloadReport{}
+		}
+
+	case http.MethodPost:
+		report = sup.reloadConfig()
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
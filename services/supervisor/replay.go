@@ -0,0 +1,324 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/clients/traces"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/profiling"
+	"github.com/forta-network/forta-node/services/scanner"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// PathReplay is the admin endpoint which replays a block or a tx to a bot.
+const PathReplay = "/replay"
+
+const defaultReplayTimeout = time.Minute * 2
+
+var errBotNotRunning = errors.New("bot is not running")
+
+// ReplayRequest selects the bot and the historical block or tx to send to it.
+type ReplayRequest struct {
+	BotID       string `json:"botId"`
+	TxHash      string `json:"txHash,omitempty"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+}
+
+// Validate validates the replay request.
+func (req *ReplayRequest) Validate() error {
+	if len(req.BotID) == 0 {
+		return errors.New("bot id is required")
+	}
+	if (len(req.TxHash) > 0) == (req.BlockNumber > 0) {
+		return errors.New("either the tx hash or the block number is required")
+	}
+	return nil
+}
+
+// ReplayResponse contains the findings of the bot for the replayed block or tx.
+type ReplayResponse struct {
+	BotID       string              `json:"botId"`
+	BlockNumber string              `json:"blockNumber"`
+	TxHash      string              `json:"txHash,omitempty"`
+	Findings    []*protocol.Finding `json:"findings"`
+}
+
+func (sup *SupervisorService) startAdminServer() {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathReplay, sup.handleReplay)
+	mux.HandleFunc(PathBotErrors, sup.handleBotErrors)
+	mux.HandleFunc(PathBotStats, sup.handleBotStats)
+	mux.HandleFunc(PathBots, sup.handleBots)
+	mux.HandleFunc(PathBotInspect, sup.handleBotInspect)
+	mux.HandleFunc(PathBotRestart, sup.handleBotRestart)
+	mux.HandleFunc(PathBotStop, sup.handleBotStop)
+	mux.HandleFunc(PathBotLogs, sup.handleBotLogs)
+	mux.Handle(PathMetrics, sup.prometheus.Handler())
+	mux.HandleFunc(PathLogLevels, sup.handleLogLevels)
+	mux.HandleFunc(PathConfigReload, sup.handleConfigReload)
+	mux.HandleFunc(scanner.PathBackfill, sup.handleBackfill)
+	profiling.Handle(mux)
+	sup.adminServer = &http.Server{
+		Addr:    fmt.Sprintf(":%s", config.DefaultSupervisorAdminPort),
+		Handler: sup.auditAdminRequests(mux),
+	}
+	utils.GoListenAndServe(sup.adminServer)
+}
+
+func (sup *SupervisorService) handleReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req ReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode the replay request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), defaultReplayTimeout)
+	defer cancel()
+	resp, err := sup.replay(ctx, &req)
+	if errors.Is(err, errBotNotRunning) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.WithError(err).WithField("bot", req.BotID).Warn("failed to replay")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// replay fetches the block or the tx and synchronously runs it through the selected bot.
+func (sup *SupervisorService) replay(ctx context.Context, req *ReplayRequest) (*ReplayResponse, error) {
+	botContainerName, err := sup.findRunningBotContainer(ctx, req.BotID)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := sup.config.Config
+	ethClient, err := ethereum.NewStreamEthClient(ctx, "chain", utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the chain client: %v", err)
+	}
+	defer ethClient.Close()
+
+	var traceClient ethereum.Client
+	if cfg.Trace.Enabled {
+		traceURL := cfg.Trace.JsonRpc.Url
+		if len(traceURL) == 0 {
+			traceURL = cfg.Scan.JsonRpc.Url
+		}
+		streamClient, err := ethereum.NewStreamEthClient(ctx, "trace", utils.ConvertToDockerHostURL(traceURL))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the trace client: %v", err)
+		}
+		client, err := traces.NewClient(ctx, streamClient, utils.ConvertToDockerHostURL(traceURL), cfg.Trace)
+		if err != nil {
+			streamClient.Close()
+			return nil, fmt.Errorf("failed to create the trace client: %v", err)
+		}
+		defer client.Close()
+		traceClient = client
+	}
+
+	blockEvt, tx, err := fetchReplayEvent(ctx, ethClient, traceClient, big.NewInt(int64(cfg.ChainID)), req)
+	if err != nil {
+		return nil, err
+	}
+
+	// only one replay at a time so that the supervisor is not detached from a bot network in use
+	sup.replayMu.Lock()
+	defer sup.replayMu.Unlock()
+
+	supervisorContainer, err := sup.globalClient.GetContainerByName(ctx, config.DockerSupervisorContainerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the supervisor container: %v", err)
+	}
+	// the bot network is named after the bot container
+	if err := sup.client.AttachNetwork(ctx, supervisorContainer.ID, botContainerName); err != nil {
+		return nil, fmt.Errorf("failed to attach the supervisor to the bot network: %v", err)
+	}
+	defer func() {
+		if err := sup.client.DetachNetwork(context.Background(), supervisorContainer.ID, botContainerName); err != nil {
+			log.WithError(err).WithField("bot", req.BotID).Warn("failed to detach the supervisor from the bot network")
+		}
+	}()
+
+	conn, err := grpc.DialContext(
+		ctx, fmt.Sprintf("%s:%s", botContainerName, config.AgentGrpcPort),
+		grpc.WithInsecure(), grpc.WithBlock(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the bot: %v", err)
+	}
+	defer conn.Close()
+
+	findings, err := evaluateReplayEvent(ctx, protocol.NewAgentClient(conn), blockEvt, tx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ReplayResponse{
+		BotID:       req.BotID,
+		BlockNumber: blockEvt.Block.Number,
+		Findings:    findings,
+	}
+	if tx != nil {
+		resp.TxHash = tx.Hash
+	}
+	return resp, nil
+}
+
+func (sup *SupervisorService) findRunningBotContainer(ctx context.Context, botID string) (string, error) {
+	botContainers, err := sup.botLifecycle.BotClient.LoadBotContainers(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load the bot containers: %v", err)
+	}
+	for _, container := range botContainers {
+		if container.State == "running" && strings.EqualFold(container.Labels[docker.LabelFortaBotID], botID) {
+			return container.Names[0][1:], nil
+		}
+	}
+	return "", errBotNotRunning
+}
+
+// fetchReplayEvent fetches the block data in the same shape as the block feed. The tx is
+// nil if a block is replayed.
+func fetchReplayEvent(
+	ctx context.Context, ethClient, traceClient ethereum.Client, chainID *big.Int, req *ReplayRequest,
+) (*domain.BlockEvent, *domain.Transaction, error) {
+	blockNum := new(big.Int).SetUint64(req.BlockNumber)
+	if len(req.TxHash) > 0 {
+		receipt, err := ethClient.TransactionReceipt(ctx, req.TxHash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get the tx receipt: %v", err)
+		}
+		if receipt.BlockNumber == nil {
+			return nil, nil, fmt.Errorf("tx is not included in a block yet")
+		}
+		blockNum, err = hexutil.DecodeBig(*receipt.BlockNumber)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode the block number of the tx: %v", err)
+		}
+	}
+
+	block, err := ethClient.BlockByNumber(ctx, blockNum)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the block: %v", err)
+	}
+	blockTs, err := block.GetTimestamp()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the block timestamp: %v", err)
+	}
+
+	var tx *domain.Transaction
+	if len(req.TxHash) > 0 {
+		for i := range block.Transactions {
+			if strings.EqualFold(block.Transactions[i].Hash, req.TxHash) {
+				tx = &block.Transactions[i]
+				break
+			}
+		}
+		if tx == nil {
+			return nil, nil, fmt.Errorf("tx was not found in block %s", block.Number)
+		}
+	}
+
+	var traces []domain.Trace
+	if traceClient != nil {
+		traces, err = traceClient.TraceBlock(ctx, blockNum)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to trace the block: %v", err)
+		}
+	}
+
+	logs, err := ethClient.GetLogs(ctx, eth.FilterQuery{
+		FromBlock: blockNum,
+		ToBlock:   blockNum,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the block logs: %v", err)
+	}
+	// converts from types.Log to domain.LogEntry like the block feed
+	var logEntries []domain.LogEntry
+	b, err := json.Marshal(logs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(b, &logEntries); err != nil {
+		return nil, nil, err
+	}
+
+	return &domain.BlockEvent{
+		EventType: domain.EventTypeBlock,
+		Block:     block,
+		ChainID:   chainID,
+		Traces:    traces,
+		Logs:      logEntries,
+		Timestamps: &domain.TrackingTimestamps{
+			Block: *blockTs,
+			Feed:  time.Now().UTC(),
+		},
+	}, tx, nil
+}
+
+func evaluateReplayEvent(
+	ctx context.Context, agentClient protocol.AgentClient, blockEvt *domain.BlockEvent, tx *domain.Transaction,
+) ([]*protocol.Finding, error) {
+	requestID := uuid.Must(uuid.NewUUID()).String()
+
+	if tx != nil {
+		txEvt, err := (&domain.TransactionEvent{
+			BlockEvt:    blockEvt,
+			Transaction: tx,
+			Timestamps:  blockEvt.Timestamps,
+		}).ToMessage()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the tx event: %v", err)
+		}
+		resp, err := agentClient.EvaluateTx(ctx, &protocol.EvaluateTxRequest{RequestId: requestID, Event: txEvt})
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate the tx: %v", err)
+		}
+		if resp.Status == protocol.ResponseStatus_ERROR {
+			return nil, fmt.Errorf("bot failed to evaluate the tx: %v", resp.Errors)
+		}
+		return resp.Findings, nil
+	}
+
+	blockMsg, err := blockEvt.ToMessage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the block event: %v", err)
+	}
+	resp, err := agentClient.EvaluateBlock(ctx, &protocol.EvaluateBlockRequest{RequestId: requestID, Event: blockMsg})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate the block: %v", err)
+	}
+	if resp.Status == protocol.ResponseStatus_ERROR {
+		return nil, fmt.Errorf("bot failed to evaluate the block: %v", resp.Errors)
+	}
+	return resp.Findings, nil
+}
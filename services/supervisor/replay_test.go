@@ -0,0 +1,75 @@
+package supervisor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/forta-network/forta-core-go/domain"
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testReplayTxHash  = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	testReplayBlockNo = "0x64"
+)
+
+func TestReplayRequest_Validate(t *testing.T) {
+	r := require.New(t)
+
+	r.Error((&ReplayRequest{TxHash: testReplayTxHash}).Validate())
+	r.Error((&ReplayRequest{BotID: "0xbot"}).Validate())
+	r.Error((&ReplayRequest{BotID: "0xbot", TxHash: testReplayTxHash, BlockNumber: 100}).Validate())
+	r.NoError((&ReplayRequest{BotID: "0xbot", TxHash: testReplayTxHash}).Validate())
+	r.NoError((&ReplayRequest{BotID: "0xbot", BlockNumber: 100}).Validate())
+}
+
+func TestFetchReplayEvent(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+	traceClient := mock_ethereum.NewMockClient(ctrl)
+
+	blockNum := big.NewInt(100)
+	block := &domain.Block{
+		Hash:      "0xblock",
+		Number:    testReplayBlockNo,
+		Timestamp: "0x1",
+		Transactions: []domain.Transaction{
+			{Hash: "0xother"},
+			{Hash: testReplayTxHash},
+		},
+	}
+	ethClient.EXPECT().TransactionReceipt(gomock.Any(), testReplayTxHash).
+		Return(&domain.TransactionReceipt{BlockNumber: utils.StringPtr(testReplayBlockNo)}, nil)
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), blockNum).Return(block, nil)
+	traceClient.EXPECT().TraceBlock(gomock.Any(), blockNum).Return([]domain.Trace{{}}, nil)
+	ethClient.EXPECT().GetLogs(gomock.Any(), gomock.Any()).Return([]types.Log{{Index: 1}}, nil)
+
+	blockEvt, tx, err := fetchReplayEvent(
+		context.Background(), ethClient, traceClient, big.NewInt(1),
+		&ReplayRequest{BotID: "0xbot", TxHash: testReplayTxHash},
+	)
+	r.NoError(err)
+	r.Equal(testReplayTxHash, tx.Hash)
+	r.Equal(block, blockEvt.Block)
+	r.Len(blockEvt.Traces, 1)
+	r.Len(blockEvt.Logs, 1)
+
+	// the block is replayed without a tx
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), blockNum).Return(block, nil)
+	ethClient.EXPECT().GetLogs(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	blockEvt, tx, err = fetchReplayEvent(
+		context.Background(), ethClient, nil, big.NewInt(1),
+		&ReplayRequest{BotID: "0xbot", BlockNumber: 100},
+	)
+	r.NoError(err)
+	r.Nil(tx)
+	r.Nil(blockEvt.Traces)
+}
@@ -0,0 +1,103 @@
+package supervisor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// botResourceBudget is the latest state of capping the bot limits by the resource reservation.
+type botResourceBudget struct {
+	host           *docker.HostResources
+	limits         *config.BotResourceLimits
+	containerCount int
+	// applied are the limits which were last applied to the bot containers by container ID.
+	applied map[string]config.BotResourceLimits
+	lastErr health.ErrorTracker
+	mu      sync.RWMutex
+}
+
+// enforceBotResourceBudget caps the limits of the running bot containers so that the bots together
+// can not use the resources reserved for the node services and the supervisor.
+func (sup *SupervisorService) enforceBotResourceBudget() {
+	resourcesCfg := sup.config.Config.ResourcesConfig
+	if !resourcesCfg.Reservation.Enable {
+		return
+	}
+	err := sup.doEnforceBotResourceBudget(resourcesCfg)
+	sup.botBudget.lastErr.Set(err)
+	if err != nil {
+		log.WithError(err).Error("failed to enforce the bot resource budget")
+	}
+}
+
+func (sup *SupervisorService) doEnforceBotResourceBudget(resourcesCfg config.ResourcesConfig) error {
+	budget := &sup.botBudget
+	if budget.host == nil {
+		host, err := sup.client.GetHostResources(sup.ctx)
+		if err != nil {
+			return err
+		}
+		budget.host = host
+	}
+
+	containers, err := sup.client.GetContainers(sup.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the containers: %v", err)
+	}
+	// the bot dependencies have the bot id label too
+	var botContainers []types.Container
+	for _, container := range containers {
+		if container.State == "running" && len(container.Labels[docker.LabelFortaBotID]) > 0 {
+			botContainers = append(botContainers, container)
+		}
+	}
+
+	limits, err := config.CapBotResourceLimits(resourcesCfg, budget.host.CPUs, budget.host.MemoryBytes, len(botContainers))
+	if err != nil {
+		return err
+	}
+	budget.mu.Lock()
+	budget.limits = limits
+	budget.containerCount = len(botContainers)
+	budget.mu.Unlock()
+
+	applied := make(map[string]config.BotResourceLimits)
+	for _, container := range botContainers {
+		if prev, ok := budget.applied[container.ID]; ok && prev == *limits {
+			applied[container.ID] = prev
+			continue
+		}
+		if err := sup.client.UpdateContainerResources(sup.ctx, container.ID, limits.CPUQuota, limits.Memory); err != nil {
+			log.WithError(err).WithField("container", container.Names[0][1:]).Warn("failed to update the bot container limits")
+			continue
+		}
+		applied[container.ID] = *limits
+	}
+	budget.applied = applied
+	return nil
+}
+
+func (sup *SupervisorService) botBudgetHealthReports() health.Reports {
+	budget := &sup.botBudget
+	budget.mu.RLock()
+	defer budget.mu.RUnlock()
+
+	reports := health.Reports{budget.lastErr.GetReport("resources.bot-budget.error")}
+	if budget.limits != nil {
+		reports = append(reports, &health.Report{
+			Name:   "resources.bot-budget",
+			Status: health.StatusInfo,
+			Details: fmt.Sprintf(
+				"containers=%d, cpusPerContainer=%.2f, memoryMbPerContainer=%d",
+				budget.containerCount, float64(budget.limits.CPUQuota)/100000, budget.limits.Memory/1024/1024,
+			),
+		})
+	}
+	return reports
+}
@@ -0,0 +1,57 @@
+package supervisor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/docker"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforceBotResourceBudget(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	dockerClient := mock_clients.NewMockDockerClient(ctrl)
+
+	sup := &SupervisorService{
+		ctx:    context.Background(),
+		client: dockerClient,
+	}
+	sup.config.Config.ResourcesConfig.Reservation = config.ResourceReservationConfig{
+		Enable:    true,
+		CPUs:      1,
+		MemoryMiB: 1024,
+	}
+	const gib = 1024 * 1024 * 1024
+	containers := docker.ContainerList{
+		{ID: "bot1", Names: []string{"/forta-agent-bot1"}, State: "running", Labels: map[string]string{docker.LabelFortaBotID: "0xbot1"}},
+		{ID: "dep1", Names: []string{"/forta-agent-bot1-dep"}, State: "running", Labels: map[string]string{docker.LabelFortaBotID: "0xbot1"}},
+		{ID: "bot2", Names: []string{"/forta-agent-bot2"}, State: "exited", Labels: map[string]string{docker.LabelFortaBotID: "0xbot2"}},
+		{ID: "scanner", Names: []string{"/forta-scanner"}, State: "running"},
+	}
+
+	dockerClient.EXPECT().GetHostResources(gomock.Any()).Return(&docker.HostResources{CPUs: 1.5, MemoryBytes: 16 * gib}, nil)
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(containers, nil)
+	dockerClient.EXPECT().UpdateContainerResources(gomock.Any(), "bot1", config.CPUsToMicroseconds(0.2), config.MiBToBytes(10000))
+	dockerClient.EXPECT().UpdateContainerResources(gomock.Any(), "dep1", config.CPUsToMicroseconds(0.2), config.MiBToBytes(10000))
+	sup.enforceBotResourceBudget()
+
+	// the same limits are not applied again
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(containers, nil)
+	sup.enforceBotResourceBudget()
+
+	// the limits are lowered when another bot is running
+	containers[2].State = "running"
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(containers, nil)
+	for _, id := range []string{"bot1", "dep1", "bot2"} {
+		dockerClient.EXPECT().UpdateContainerResources(gomock.Any(), id, config.CPUsToMicroseconds(0.5/3), config.MiBToBytes(10000))
+	}
+	sup.enforceBotResourceBudget()
+
+	reports := sup.botBudgetHealthReports()
+	r.Len(reports, 2)
+	r.Equal("containers=3, cpusPerContainer=0.17, memoryMbPerContainer=1000", reports[1].Details)
+}
@@ -0,0 +1,72 @@
+package supervisor
+
+import (
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// startScannerInstances runs a supervisor for each additional scanner on the same host. Each one runs
+// the service containers, the networks and the bots of its scanner by using the config and the key
+// in its own Forta dir. The env overrides of this node are not passed to them.
+func (sup *SupervisorService) startScannerInstances(image string, releaseInfo *release.ReleaseInfo) error {
+	// only the main supervisor runs the other scanners
+	if len(config.ContainerNamingInstance()) > 0 {
+		if len(sup.config.Config.Scanners) > 0 {
+			log.Warn("ignoring the additional scanners in the config of an additional scanner")
+		}
+		return nil
+	}
+	for _, scanner := range sup.config.Config.Scanners {
+		containerName := config.ServiceContainerName(scanner.Instance, "supervisor")
+		logger := log.WithFields(log.Fields{
+			"instance":  scanner.Instance,
+			"container": containerName,
+		})
+
+		// replace the old supervisor so that the scanner runs the same version
+		if container, err := sup.globalClient.GetContainerByName(sup.ctx, containerName); err == nil {
+			if err := sup.client.RemoveContainer(sup.ctx, container.ID); err != nil {
+				return fmt.Errorf("failed to remove the old supervisor of scanner '%s': %v", scanner.Instance, err)
+			}
+			if err := sup.client.WaitContainerPrune(sup.ctx, container.ID); err != nil {
+				return fmt.Errorf("failed while waiting removal of the old supervisor of scanner '%s': %v", scanner.Instance, err)
+			}
+		}
+
+		supervisorContainer, err := sup.client.StartContainer(sup.ctx, docker.ContainerConfig{
+			Name:  containerName,
+			Image: image,
+			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "supervisor"},
+			Env: map[string]string{
+				config.EnvHostFortaDir:            scanner.FortaDir,
+				config.EnvReleaseInfo:             releaseInfo.String(),
+				config.EnvContainerNamingInstance: scanner.Instance,
+			},
+			Volumes: map[string]string{
+				// give access to host docker
+				"/var/run/docker.sock": "/var/run/docker.sock",
+				scanner.FortaDir:       config.DefaultContainerFortaDirPath,
+			},
+			Ports: map[string]string{
+				"":           config.DefaultHealthPort,          // random host port
+				"127.0.0.1:": config.DefaultSupervisorAdminPort, // random local host port
+			},
+			Files: map[string][]byte{
+				"passphrase": []byte(scanner.Passphrase),
+			},
+			DialHost:    true,
+			MaxLogFiles: sup.maxLogFiles,
+			MaxLogSize:  sup.maxLogSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start the supervisor of scanner '%s': %v", scanner.Instance, err)
+		}
+		sup.addContainerUnsafe(supervisorContainer)
+		logger.Info("started the supervisor of the additional scanner")
+	}
+	return nil
+}
@@ -3,40 +3,61 @@ package supervisor
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/docker/docker/api/types"
 	"github.com/forta-network/forta-core-go/clients/agentlogs"
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/manifest"
 	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/registry"
 	"github.com/forta-network/forta-core-go/release"
-	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/audit"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/docker"
 	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/clients/notifier"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/crashreport"
 	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components"
+	"github.com/forta-network/forta-node/services/components/alerting"
 	"github.com/forta-network/forta-node/services/components/containers"
+	"github.com/forta-network/forta-node/services/components/metrics"
+	"github.com/forta-network/forta-node/store"
 	"github.com/ipfs/go-cid"
 	log "github.com/sirupsen/logrus"
 )
 
-var knownServiceContainerNames = []string{
-	config.DockerScannerContainerName,
-	config.DockerInspectorContainerName,
-	config.DockerJSONRPCProxyContainerName,
-	config.DockerJWTProviderContainerName,
-	config.DockerPublicAPIProxyContainerName,
-	config.DockerNatsContainerName,
-	config.DockerIpfsContainerName,
-	config.DockerStorageContainerName,
+// knownServiceContainerNames returns the names of the service containers of this node instance.
+func knownServiceContainerNames() []string {
+	return []string{
+		config.DockerScannerContainerName,
+		config.DockerInspectorContainerName,
+		config.DockerJSONRPCProxyContainerName,
+		config.DockerJWTProviderContainerName,
+		config.DockerPublicAPIProxyContainerName,
+		config.DockerNatsContainerName,
+		config.DockerIpfsContainerName,
+		config.DockerStorageContainerName,
+	}
+}
+
+// serviceContainerNames returns the names of the service containers which the supervisor manages.
+func (sup *SupervisorService) serviceContainerNames() []string {
+	// the orchestrator manages the service containers
+	if sup.config.Config.Orchestrator.Enable {
+		return nil
+	}
+	return knownServiceContainerNames()
 }
 
 // SupervisorService manages the scanner node's service and agent containers.
@@ -66,6 +87,9 @@ type SupervisorService struct {
 	containers           []*Container
 	mu                   sync.RWMutex
 
+	prevContainers       map[string]*types.Container
+	checkContainerHealth func(containerName string) error
+
 	lastRun                         health.TimeTracker
 	lastStop                        health.TimeTracker
 	lastTelemetryRequest            health.TimeTracker
@@ -80,12 +104,45 @@ type SupervisorService struct {
 	sendAgentLogs func(agents agentlogs.Agents, authToken string) error
 	prevAgentLogs agentlogs.Agents
 	inspectionCh  chan *protocol.InspectionResults
+
+	adminServer  *http.Server
+	replayMu     sync.Mutex
+	botErrors    *botErrors
+	botRefreshCh chan struct{}
+	prometheus   *metrics.PrometheusExporter
+	botStats     *metrics.BotStatsTracker
+	botRestarts  botRestarts
+	resources    containerResources
+	cpuWatchdog  *botCPUWatchdog
+	botBudget    botResourceBudget
+	alerting     *alerting.Engine
+	auditLog     *audit.Log
+	crashReports *crashreport.Reporter
+
+	stakeRegistry     registry.Client
+	stakeInfo         *stakeInfo
+	stakeInfoMu       sync.RWMutex
+	lastStakeCheck    health.TimeTracker
+	lastStakeCheckErr health.ErrorTracker
+
+	readiness          *readinessInfo
+	latestInspection   *protocol.InspectionResults
+	readinessMu        sync.RWMutex
+	lastReadinessCheck health.TimeTracker
+
+	remoteLogLevels map[string]string
+	logLevelsMu     sync.Mutex
+
+	loadedConfig *config.Config
+	lastReload   *config.ReloadReport
+	reloadMu     sync.Mutex
+	configMu     sync.RWMutex
 }
 
 type SupervisorServiceConfig struct {
 	Config             config.Config
 	Passphrase         string
-	Key                *keystore.Key
+	Signer             signer.Signer
 	BotLifecycleConfig components.BotLifecycleConfig
 }
 
@@ -103,6 +160,24 @@ func (sup *SupervisorService) Start() error {
 
 	go sup.healthCheck()
 	go sup.refreshBotContainers()
+	if sup.botLifecycle.BotImagePrefetcher != nil {
+		go sup.prefetchBotImages()
+	}
+	registryCfg := sup.config.Config.Registry
+	if registryCfg.AssignmentEvents && !registryCfg.SnapshotMode && !sup.config.Config.LocalModeConfig.Enable {
+		go sup.listenToAssignments()
+	}
+	go sup.collectContainerResources()
+	go sup.exportBotStats()
+	if sup.stakeInfoEnabled() {
+		go sup.checkStakeInfo()
+	}
+	if sup.readinessEnabled() {
+		go sup.checkReadiness()
+	}
+	sup.alerting.Start()
+	go sup.watchConfig()
+	sup.startAdminServer()
 
 	return nil
 }
@@ -125,7 +200,7 @@ func (sup *SupervisorService) start() error {
 	}
 
 	hostFortaDir := os.Getenv(config.EnvHostFortaDir)
-	if len(hostFortaDir) == 0 {
+	if len(hostFortaDir) == 0 && !sup.config.Config.Orchestrator.Enable {
 		return fmt.Errorf("supervisor needs to know $%s to mount to the other containers it runs", config.EnvHostFortaDir)
 	}
 	releaseInfo := release.ReleaseInfoFromString(os.Getenv(config.EnvReleaseInfo))
@@ -143,6 +218,16 @@ func (sup *SupervisorService) start() error {
 	if err := sup.removeOldContainers(); err != nil {
 		return err
 	}
+	// the previous supervisor has already left the service containers to this one
+	if err := store.ClearUpgradeHandoff(sup.config.Config.FortaDir); err != nil {
+		log.WithError(err).Warn("failed to clear the upgrade handoff")
+	}
+
+	// the orchestrator runs the service containers and the supervisor only manages the bots
+	if sup.config.Config.Orchestrator.Enable {
+		log.WithField("natsUrl", sup.config.Config.NatsURL()).Info("running with an external orchestrator")
+		return sup.initMessaging()
+	}
 
 	if err := sup.ensureNodeImages(); err != nil {
 		return err
@@ -212,14 +297,19 @@ func (sup *SupervisorService) start() error {
 	}
 
 	// start nats, wait for it and connect from the supervisor
+	natsPorts := map[string]string{
+		"4222": "4222",
+		"6222": "6222",
+		"8222": "8222",
+	}
+	// only one of the scanners on the same host can publish the ports
+	if len(config.ContainerNamingInstance()) > 0 {
+		natsPorts = nil
+	}
 	natsContainer, err := sup.client.StartContainer(sup.ctx, docker.ContainerConfig{
-		Name:  config.DockerNatsContainerName,
-		Image: "nats:2.3.2",
-		Ports: map[string]string{
-			"4222": "4222",
-			"6222": "6222",
-			"8222": "8222",
-		},
+		Name:        config.DockerNatsContainerName,
+		Image:       "nats:2.3.2",
+		Ports:       natsPorts,
 		NetworkID:   natsNetworkID,
 		MaxLogFiles: sup.maxLogFiles,
 		MaxLogSize:  sup.maxLogSize,
@@ -232,32 +322,19 @@ func (sup *SupervisorService) start() error {
 	if err := sup.client.WaitContainerStart(sup.ctx, natsContainer.ID); err != nil {
 		return fmt.Errorf("failed while waiting for nats to start: %v", err)
 	}
-	// in tests, this is already set to a mock client
-	if sup.msgClient == nil {
-		sup.msgClient = messaging.NewClient("supervisor", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
-	}
-	sup.botLifecycleConfig.MessageClient = sup.msgClient // we are able to set this dependency only here
-	sup.botLifecycle, err = components.GetBotLifecycleComponents(sup.ctx, sup.botLifecycleConfig)
-	if err != nil {
-		return fmt.Errorf("failed to get bot lifecycle components: %v", err)
-	}
-
-	shouldDisableAgentLogs := sup.config.Config.AgentLogsConfig.Disable || sup.config.Config.LocalModeConfig.Enable
-	if !shouldDisableAgentLogs {
-		go sup.syncAgentLogs()
+	if err := sup.initMessaging(); err != nil {
+		return err
 	}
 
-	sup.registerMessageHandlers()
-
 	if sup.config.Config.AdvancedConfig.IPFSExperiment {
 		sup.storageContainer, err = sup.client.StartContainer(
 			sup.ctx, docker.ContainerConfig{
 				Name:  config.DockerStorageContainerName,
 				Image: commonNodeImage,
 				Cmd:   []string{config.DefaultFortaNodeBinaryPath, "storage"},
-				Env: map[string]string{
+				Env: sup.config.Config.ContainerEnv(map[string]string{
 					config.EnvReleaseInfo: releaseInfo.String(),
-				},
+				}),
 				Volumes: map[string]string{
 					// give access to host docker
 					"/var/run/docker.sock": "/var/run/docker.sock",
@@ -285,19 +362,24 @@ func (sup *SupervisorService) start() error {
 		}
 	}
 
-	sup.jsonRpcContainer, err = sup.client.StartContainer(
-		sup.ctx, docker.ContainerConfig{
+	jsonRpcPorts := map[string]string{
+		"": config.DefaultHealthPort, // random host port
+	}
+	if sup.config.Config.JsonRpcProxy.ServerTLS != nil {
+		jsonRpcPorts[config.DefaultJSONRPCProxyTLSPort] = config.DefaultJSONRPCProxyTLSPort
+	}
+	sup.jsonRpcContainer, err = sup.startServiceContainer(
+		docker.ContainerConfig{
 			Name:  config.DockerJSONRPCProxyContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "json-rpc"},
+			Env:   sup.config.Config.ContainerEnv(nil),
 			Volumes: map[string]string{
 				// give access to host docker
 				"/var/run/docker.sock": "/var/run/docker.sock",
 				hostFortaDir:           config.DefaultContainerFortaDirPath,
 			},
-			Ports: map[string]string{
-				"": config.DefaultHealthPort, // random host port
-			},
+			Ports:          jsonRpcPorts,
 			DialHost:       true,
 			NetworkID:      nodeNetworkID,
 			LinkNetworkIDs: []string{natsNetworkID},
@@ -310,11 +392,12 @@ func (sup *SupervisorService) start() error {
 	}
 	sup.addContainerUnsafe(sup.jsonRpcContainer)
 
-	sup.publicAPIContainer, err = sup.client.StartContainer(
-		sup.ctx, docker.ContainerConfig{
+	sup.publicAPIContainer, err = sup.startServiceContainer(
+		docker.ContainerConfig{
 			Name:  config.DockerPublicAPIProxyContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "public-api"},
+			Env:   sup.config.Config.ContainerEnv(nil),
 			Volumes: map[string]string{
 				// give access to host docker
 				"/var/run/docker.sock": "/var/run/docker.sock",
@@ -354,6 +437,7 @@ func (sup *SupervisorService) start() error {
 			Name:  config.DockerInspectorContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "inspector"},
+			Env:   sup.config.Config.ContainerEnv(nil),
 			Volumes: map[string]string{
 				hostFortaDir: config.DefaultContainerFortaDirPath,
 			},
@@ -387,14 +471,14 @@ func (sup *SupervisorService) start() error {
 		log.Info("inspection to completed")
 	}
 
-	sup.scannerContainer, err = sup.client.StartContainer(
-		sup.ctx, docker.ContainerConfig{
+	sup.scannerContainer, err = sup.startServiceContainer(
+		docker.ContainerConfig{
 			Name:  config.DockerScannerContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "scanner"},
-			Env: map[string]string{
+			Env: sup.config.Config.ContainerEnv(map[string]string{
 				config.EnvReleaseInfo: releaseInfo.String(),
-			},
+			}),
 			Volumes: map[string]string{
 				hostFortaDir: config.DefaultContainerFortaDirPath,
 			},
@@ -421,9 +505,9 @@ func (sup *SupervisorService) start() error {
 			Name:  config.DockerJWTProviderContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "jwt-provider"},
-			Env: map[string]string{
+			Env: sup.config.Config.ContainerEnv(map[string]string{
 				config.EnvReleaseInfo: releaseInfo.String(),
-			},
+			}),
 			Volumes: map[string]string{
 				// give access to host docker
 				"/var/run/docker.sock": "/var/run/docker.sock",
@@ -447,6 +531,27 @@ func (sup *SupervisorService) start() error {
 	}
 	sup.addContainerUnsafe(sup.jwtProviderContainer)
 
+	return sup.startScannerInstances(commonNodeImage, releaseInfo)
+}
+
+// initMessaging connects to nats and initializes the components which depend on it.
+func (sup *SupervisorService) initMessaging() (err error) {
+	// in tests, this is already set to a mock client
+	if sup.msgClient == nil {
+		sup.msgClient = messaging.NewClient("supervisor", sup.config.Config.NatsURL())
+	}
+	sup.botLifecycleConfig.MessageClient = sup.msgClient // we are able to set this dependency only here
+	sup.botLifecycle, err = components.GetBotLifecycleComponents(sup.ctx, sup.botLifecycleConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get bot lifecycle components: %v", err)
+	}
+
+	shouldDisableAgentLogs := sup.config.Config.AgentLogsConfig.Disable || sup.config.Config.LocalModeConfig.Enable
+	if !shouldDisableAgentLogs {
+		go sup.syncAgentLogs()
+	}
+
+	sup.registerMessageHandlers()
 	return nil
 }
 
@@ -468,6 +573,7 @@ func (sup *SupervisorService) registerMessageHandlers() {
 	if *sup.config.Config.InspectionConfig.InspectAtStartup {
 		sup.msgClient.Subscribe(messaging.SubjectInspectionDone, messaging.InspectionResultsHandler(sup.handleInspectionResults))
 	}
+	sup.msgClient.Subscribe(messaging.SubjectMetricAgent, messaging.AgentMetricHandler(sup.handleAgentMetrics))
 }
 
 func manageIpfsDir(cfg config.Config) error {
@@ -523,18 +629,37 @@ func (sup *SupervisorService) removeOldContainers() error {
 	var containersToRemove []*containerDefinition
 
 	// gather old service containers
-	for _, containerName := range knownServiceContainerNames {
+	for _, containerName := range sup.serviceContainerNames() {
 		container, err := sup.client.GetContainerByName(sup.ctx, containerName)
 		if err != nil {
 			log.WithError(err).WithField("containerName", containerName).Info("did not find old service container - ignoring")
 			continue
 		}
+		if sup.keepPrevContainer(containerName, container) {
+			continue
+		}
 		containersToRemove = append(containersToRemove, &containerDefinition{
 			ID:   container.ID,
 			Name: containerName,
 		})
 	}
 
+	// gather leftovers from interrupted blue/green upgrades
+	if sup.blueGreenEnabled() {
+		for _, containerName := range blueGreenContainerNames() {
+			for _, leftoverName := range []string{containerName + nextContainerSuffix, containerName + prevContainerSuffix} {
+				container, err := sup.client.GetContainerByName(sup.ctx, leftoverName)
+				if err != nil {
+					continue
+				}
+				containersToRemove = append(containersToRemove, &containerDefinition{
+					ID:   container.ID,
+					Name: leftoverName,
+				})
+			}
+		}
+	}
+
 	// gather old agents
 	containerList, err := sup.client.GetContainers(sup.ctx)
 	if err != nil {
@@ -549,9 +674,13 @@ func (sup *SupervisorService) removeOldContainers() error {
 		if !strings.Contains(containerName, "forta-agent-") {
 			continue
 		}
+		// the other scanners on the same host manage their own bots
+		if !containers.HasSameLabelValue(&container, docker.LabelFortaNodeInstance, config.ContainerNamingInstance()) {
+			continue
+		}
 		if !containers.HasSameLabelValue(
 			&container,
-			docker.LabelFortaSupervisorStrategyVersion, containers.LabelValueStrategyVersion,
+			docker.LabelFortaSupervisorStrategyVersion, containers.StrategyVersion(sup.config.Config.BotEgress),
 		) {
 			logger.Info("bot container is old - need to remove")
 			containersToRemove = append(containersToRemove, &containerDefinition{
@@ -635,7 +764,7 @@ func (sup *SupervisorService) doSyncTelemetryDataToCustomHandler() error {
 }
 
 func (sup *SupervisorService) doSyncTelemetryData(destUrl string) error {
-	scannerJwt, err := security.CreateScannerJWT(sup.config.Key, map[string]interface{}{
+	scannerJwt, err := signer.CreateScannerJWT(sup.config.Signer, map[string]interface{}{
 		"access": "telemetry",
 	})
 	if err != nil {
@@ -679,24 +808,72 @@ func (sup *SupervisorService) Stop() error {
 	// we don't want tear downs to be aborted by the closed service context
 	ctx := context.Background()
 
-	if !services.IsGracefulShutdown() {
+	if sup.adminServer != nil {
+		sup.adminServer.Close()
+	}
+
+	// the scanner drains the evaluations and publishes the findings before the proxy
+	// and the bots are stopped
+	shutdownCfg := sup.config.Config.Shutdown
+	stopped := make(map[string]bool)
+	for _, stage := range []struct {
+		name    string
+		timeout time.Duration
+	}{
+		{name: config.DockerScannerContainerName, timeout: shutdownCfg.DrainTimeout()},
+		{name: config.DockerJSONRPCProxyContainerName, timeout: time.Duration(shutdownCfg.ProxyTimeoutSeconds) * time.Second},
+	} {
+		for _, cnt := range sup.containers {
+			if cnt.Name == stage.name {
+				sup.stopContainer(ctx, cnt, stage.timeout)
+				stopped[cnt.ID] = true
+			}
+		}
+	}
+
+	if shutdownCfg.ShouldTearDownBots(services.IsGracefulShutdown()) {
 		sup.botLifecycle.BotManager.TearDownRunningBots(ctx)
 	}
 
 	for _, cnt := range sup.containers {
-		err := sup.client.InterruptContainer(ctx, cnt.Container.ID)
-		logger := log.WithFields(log.Fields{
-			"id": cnt.ID,
-		})
-		if err != nil {
-			logger.WithError(err).Error("error stopping container")
-		} else {
-			logger.Info("requested to stop container")
+		if !stopped[cnt.ID] {
+			sup.stopContainer(ctx, cnt, 0)
 		}
 	}
 	return nil
 }
 
+// StopTimeout implements the services.StopTimeouter interface. The stop waits for the scanner
+// to drain and for the proxy before the bots and the rest of the containers are stopped.
+func (sup *SupervisorService) StopTimeout() time.Duration {
+	shutdownCfg := sup.config.Config.Shutdown
+	return shutdownCfg.DrainTimeout() + time.Duration(shutdownCfg.ProxyTimeoutSeconds+shutdownCfg.TeardownTimeoutSeconds)*time.Second
+}
+
+// stopContainer interrupts the container and waits for it to exit if there is a timeout.
+func (sup *SupervisorService) stopContainer(ctx context.Context, cnt *Container, timeout time.Duration) {
+	// the next supervisor replaces these after the new ones are healthy
+	if sup.blueGreenEnabled() && isBlueGreenContainer(cnt.Name) && store.IsUpgradeHandoffPending(sup.config.Config.FortaDir) {
+		return
+	}
+	logger := log.WithFields(log.Fields{
+		"id": cnt.ID,
+	})
+	if err := sup.client.InterruptContainer(ctx, cnt.Container.ID); err != nil {
+		logger.WithError(err).Error("error stopping container")
+		return
+	}
+	logger.Info("requested to stop container")
+	if timeout == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := sup.client.WaitContainerExit(ctx, cnt.Container.ID); err != nil {
+		logger.WithError(err).Warn("container did not exit in time")
+	}
+}
+
 func (sup *SupervisorService) Name() string {
 	return "supervisor"
 }
@@ -711,17 +888,12 @@ func (sup *SupervisorService) Health() health.Reports {
 		containersStatus = health.StatusFailing
 	}
 
-	return health.Reports{
+	reports := health.Reports{
 		&health.Report{
 			Name:    "local-mode",
 			Status:  health.StatusInfo,
 			Details: strconv.FormatBool(sup.config.Config.LocalModeConfig.Enable),
 		},
-		&health.Report{
-			Name:    "containers.managed",
-			Status:  containersStatus,
-			Details: strconv.Itoa(len(sup.containers)),
-		},
 		&health.Report{
 			Name:    "event.run-agent.time",
 			Status:  health.StatusInfo,
@@ -739,10 +911,43 @@ func (sup *SupervisorService) Health() health.Reports {
 		sup.lastAgentLogsRequest.GetReport("event.agent-logs-sync.time"),
 		sup.lastAgentLogsRequestError.GetReport("event.agent-logs-sync.error"),
 	}
+	// there are no service containers to count when the orchestrator runs them
+	if !sup.config.Config.Orchestrator.Enable {
+		reports = append(reports, &health.Report{
+			Name:    "containers.managed",
+			Status:  containersStatus,
+			Details: strconv.Itoa(len(sup.containers)),
+		})
+	}
+	if sup.alerting != nil {
+		reports = append(reports, alertingReport(sup.alerting.Firing()))
+	}
+	if sup.stakeInfoEnabled() {
+		reports = append(reports, sup.stakeHealthReports()...)
+	}
+	if sup.readinessEnabled() {
+		reports = append(reports, sup.readinessHealthReports()...)
+	}
+	// the bot manager is created after connecting to nats
+	if reporter, ok := sup.botLifecycle.BotManager.(health.Reporter); ok {
+		reports = append(reports, reporter.Health()...)
+	}
+	if sup.botLifecycle.BotImagePrefetcher != nil {
+		reports = append(reports, sup.botLifecycle.BotImagePrefetcher.Health()...)
+	}
+	if sup.cpuWatchdog != nil {
+		reports = append(reports, sup.cpuWatchdog.Health()...)
+	}
+	if sup.config.Config.ResourcesConfig.Reservation.Enable {
+		reports = append(reports, sup.botBudgetHealthReports()...)
+	}
+	return reports
 }
 
 // handleInspectionResults listen for inspections.
 func (sup *SupervisorService) handleInspectionResults(payload *protocol.InspectionResults) error {
+	sup.storeInspectionResults(payload)
+
 	// do a non-blocking write because messages are consumed only at startup
 	select {
 	case sup.inspectionCh <- payload:
@@ -767,15 +972,51 @@ func NewSupervisorService(ctx context.Context, cfg SupervisorServiceConfig) (*Su
 		return nil, fmt.Errorf("failed to create the release client: %v", err)
 	}
 
+	alertingEngine := alerting.NewEngine(
+		ctx, cfg.Config.Alerting, notifier.FromConfig(cfg.Config.Alerting),
+		registryLastSynced(cfg.BotLifecycleConfig.BotRegistry),
+		func() (uint64, error) {
+			return alerting.DiskFree(cfg.Config.FortaDir)
+		},
+	)
+
+	// the bots get the same share of the cpus if they have no weights
+	var cpuWatchdog *botCPUWatchdog
+	if cfg.Config.ResourcesConfig.CPUFairness.Enable {
+		cpuWatchdog = newBotCPUWatchdog(cfg.Config.ResourcesConfig, runtime.NumCPU())
+	}
+
+	// the audit log is nil if it is disabled
+	var auditLog *audit.Log
+	if !cfg.Config.AuditLog.Disable {
+		auditLog, err = audit.Open(
+			cfg.Config.AuditLog.FilePath(cfg.Config.FortaDir), cfg.Config.AuditLog.MaxSizeMB, cfg.Config.AuditLog.MaxBackups,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the audit log: %v", err)
+		}
+		cfg.BotLifecycleConfig.AuditLog = auditLog
+	}
+
 	return &SupervisorService{
-		ctx:                ctx,
-		client:             dockerClient,
-		globalClient:       globalClient,
-		releaseClient:      releaseClient,
-		botLifecycleConfig: cfg.BotLifecycleConfig,
-		config:             cfg,
-		healthClient:       health.NewClient(),
-		sendAgentLogs:      agentlogs.NewClient(cfg.Config.AgentLogsConfig.URL).SendLogs,
-		inspectionCh:       make(chan *protocol.InspectionResults),
+		ctx:                  ctx,
+		client:               dockerClient,
+		globalClient:         globalClient,
+		releaseClient:        releaseClient,
+		botLifecycleConfig:   cfg.BotLifecycleConfig,
+		config:               cfg,
+		healthClient:         health.NewClient(),
+		sendAgentLogs:        agentlogs.NewClient(cfg.Config.AgentLogsConfig.URL).SendLogs,
+		inspectionCh:         make(chan *protocol.InspectionResults),
+		checkContainerHealth: checkContainerHealth,
+		botErrors:            newBotErrors(defaultBotErrorsPerBot),
+		botRefreshCh:         make(chan struct{}, 1),
+		prometheus:           metrics.NewPrometheusExporter(),
+		botStats:             metrics.NewBotStatsTracker(cfg.Config.BotStats.Windows()),
+		cpuWatchdog:          cpuWatchdog,
+		alerting:             alertingEngine,
+		auditLog:             auditLog,
+		crashReports:         crashreport.NewReporter(cfg.Config),
+		remoteLogLevels:      make(map[string]string),
 	}, nil
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/forta-network/forta-core-go/clients/agentlogs"
@@ -19,10 +20,14 @@ import (
 
 	"github.com/forta-network/forta-node/clients/docker"
 	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/components/containers"
 	mock_containers "github.com/forta-network/forta-node/services/components/containers/mocks"
+	mock_lifecycle "github.com/forta-network/forta-node/services/components/lifecycle/mocks"
 	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -124,7 +129,7 @@ func (s *Suite) SetupTest() {
 		msgClient:     s.msgClient,
 		releaseClient: s.releaseClient,
 	}
-	supervisor.config.Key = key
+	supervisor.config.Signer = signer.NewKeySigner(key)
 	supervisor.config.Config.TelemetryConfig.Disable = true
 	supervisor.config.Config.Log.Level = "debug"
 	supervisor.config.Config.ChainID = 1
@@ -137,7 +142,7 @@ func (s *Suite) SetupTest() {
 }
 
 func (s *Suite) initialContainerCheck() {
-	for _, containerName := range knownServiceContainerNames {
+	for _, containerName := range knownServiceContainerNames() {
 		s.dockerClient.EXPECT().GetContainerByName(s.supervisor.ctx, containerName).Return(&types.Container{ID: testGenericContainerID}, nil)
 	}
 
@@ -161,17 +166,17 @@ func (s *Suite) initialContainerCheck() {
 	)
 
 	// supervisor-managed containers
-	for i := 0; i < len(knownServiceContainerNames)+1; i++ {
+	for i := 0; i < len(knownServiceContainerNames())+1; i++ {
 		s.dockerClient.EXPECT().RemoveContainer(s.supervisor.ctx, testGenericContainerID).Return(nil)
 		s.dockerClient.EXPECT().WaitContainerPrune(s.supervisor.ctx, testGenericContainerID).Return(nil)
 	}
-	for i := 0; i < len(knownServiceContainerNames)+1; i++ {
+	for i := 0; i < len(knownServiceContainerNames())+1; i++ {
 		s.dockerClient.EXPECT().RemoveNetworkByName(s.supervisor.ctx, gomock.Any()).Return(nil)
 	}
 }
 
 func (s *Suite) TestStartServices() {
-	s.msgClient.EXPECT().Subscribe(messaging.SubjectMetricAgent, gomock.Any())
+	s.msgClient.EXPECT().Subscribe(messaging.SubjectMetricAgent, gomock.Any()).Times(2) // bot monitor and bot errors
 
 	s.releaseClient.EXPECT().GetReleaseManifest(gomock.Any()).Return(&release.ReleaseManifest{}, nil).AnyTimes()
 
@@ -251,6 +256,43 @@ func (s *Suite) TestStartServices() {
 	s.r.NoError(s.supervisor.start())
 }
 
+func (s *Suite) TestStop() {
+	botManager := mock_lifecycle.NewMockBotLifecycleManager(gomock.NewController(s.T()))
+	s.supervisor.botLifecycle.BotManager = botManager
+	s.supervisor.config.Config.Shutdown = config.ShutdownConfig{
+		IntakeTimeoutSeconds:      1,
+		EvaluationsTimeoutSeconds: 1,
+		PublishTimeoutSeconds:     1,
+		ProxyTimeoutSeconds:       1,
+		TeardownTimeoutSeconds:    1,
+	}
+	s.supervisor.containers = []*Container{
+		{Container: docker.Container{Name: config.DockerNatsContainerName, ID: testGenericContainerID}},
+		{Container: docker.Container{Name: config.DockerJSONRPCProxyContainerName, ID: testProxyContainerID}},
+		{Container: docker.Container{Name: config.DockerScannerContainerName, ID: testScannerContainerID}},
+	}
+
+	// the scanner drains before the proxy and the bots are stopped
+	gomock.InOrder(
+		s.dockerClient.EXPECT().InterruptContainer(gomock.Any(), testScannerContainerID).Return(nil),
+		s.dockerClient.EXPECT().WaitContainerExit(gomock.Any(), testScannerContainerID).DoAndReturn(
+			func(ctx context.Context, id string) error {
+				time.Sleep(time.Millisecond * 1500)
+				return nil
+			},
+		),
+		s.dockerClient.EXPECT().InterruptContainer(gomock.Any(), testProxyContainerID).Return(nil),
+		s.dockerClient.EXPECT().WaitContainerExit(gomock.Any(), testProxyContainerID).Return(nil),
+		botManager.EXPECT().TearDownRunningBots(gomock.Any()),
+		s.dockerClient.EXPECT().InterruptContainer(gomock.Any(), testGenericContainerID).Return(nil),
+	)
+
+	// the stop takes longer than the teardown stage but the bots are still torn down
+	s.r.Equal(time.Second*5, s.supervisor.StopTimeout())
+	lc := services.NewLifecycle(context.Background(), s.supervisor.config.Config.Shutdown)
+	lc.Shutdown(log.NewEntry(log.StandardLogger()), []services.Service{s.supervisor})
+}
+
 func (s *Suite) TestDoSyncAgentLogs() {
 	s.botClient.EXPECT().LoadBotContainers(gomock.Any()).Return([]types.Container{{
 		Labels: map[string]string{
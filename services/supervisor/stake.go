@@ -0,0 +1,245 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/registry"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const slaRequestTimeout = time.Second * 30
+
+// stakeInfo is the latest state of the node which limits the bot assignments.
+type stakeInfo struct {
+	Registered     bool
+	Enabled        bool
+	AllocatedStake *big.Int
+	MinStake       *big.Int
+	MaxStake       *big.Int
+	AssignedBots   int64
+	RewardEpoch    uint32
+	Reward         *big.Int
+	SLAScore       *float64
+}
+
+// slaResponse is the response of the SLA API.
+type slaResponse struct {
+	Statistics struct {
+		Avg float64 `json:"avg"`
+	} `json:"statistics"`
+}
+
+// stakeInfoEnabled tells if the stake info should be checked. The registry is not used in
+// the local mode and the snapshot mode.
+func (sup *SupervisorService) stakeInfoEnabled() bool {
+	cfg := sup.config.Config
+	return !cfg.StakeInfo.Disable && !cfg.Registry.SnapshotMode && !cfg.LocalModeConfig.Enable
+}
+
+func (sup *SupervisorService) stakeCheckInterval() time.Duration {
+	return time.Duration(sup.config.Config.StakeInfo.CheckIntervalSeconds) * time.Second
+}
+
+func (sup *SupervisorService) checkStakeInfo() {
+	logger := log.WithField("component", "stake-info")
+	ticker := time.NewTicker(sup.stakeCheckInterval())
+	defer ticker.Stop()
+	for {
+		info, err := sup.doCheckStakeInfo()
+		sup.lastStakeCheck.Set()
+		sup.lastStakeCheckErr.Set(err)
+		if err != nil {
+			logger.WithError(err).Warn("failed to check the stake info")
+		} else {
+			sup.stakeInfoMu.Lock()
+			sup.stakeInfo = info
+			sup.stakeInfoMu.Unlock()
+		}
+
+		select {
+		case <-sup.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (sup *SupervisorService) doCheckStakeInfo() (*stakeInfo, error) {
+	cfg := sup.config.Config
+	if sup.stakeRegistry == nil {
+		registryClient, err := store.GetRegistryClient(sup.ctx, cfg, registry.ClientConfig{
+			JsonRpcUrl: cfg.Registry.JsonRpc.Url,
+			ENSAddress: cfg.ENSConfig.ContractAddress,
+			Name:       "stake-info",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the registry client: %v", err)
+		}
+		sup.stakeRegistry = registryClient
+	}
+	registryClient := sup.stakeRegistry
+	scannerAddress := sup.config.Signer.Address().Hex()
+
+	info := &stakeInfo{}
+	scanner, err := registryClient.GetScanner(scannerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the scanner: %v", err)
+	}
+	if scanner == nil {
+		return info, nil
+	}
+	info.Registered = true
+	info.Enabled = scanner.Enabled
+
+	poolID, ok := new(big.Int).SetString(scanner.PoolID, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid pool id: %s", scanner.PoolID)
+	}
+	info.AllocatedStake, err = registryClient.GetAllocatedStakePerManaged(nil, poolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the allocated stake: %v", err)
+	}
+	contracts := registryClient.Contracts()
+	if contracts.ScannerPoolReg != nil {
+		threshold, err := contracts.ScannerPoolReg.GetManagedStakeThreshold(nil, big.NewInt(scanner.ChainID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the stake threshold: %v", err)
+		}
+		info.MinStake = threshold.Min
+		info.MaxStake = threshold.Max
+	}
+	assignments, err := registryClient.GetAssignmentHash(scannerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the assignments: %v", err)
+	}
+	info.AssignedBots = assignments.AgentLength
+
+	// the rewards of an epoch are distributed after it ends
+	if contracts.RewardsDistributor != nil {
+		currEpoch, err := contracts.RewardsDistributor.GetCurrentEpochNumber(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the current epoch: %v", err)
+		}
+		if currEpoch > 0 {
+			info.RewardEpoch = currEpoch - 1
+			info.Reward, err = contracts.RewardsDistributor.AvailableReward(
+				nil, registry.SubjectTypeScannerPool, poolID, big.NewInt(int64(info.RewardEpoch)), common.HexToAddress(scanner.Owner),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the rewards: %v", err)
+			}
+		}
+	}
+
+	// the score is nice to have so the other values are still reported without it
+	if len(cfg.StakeInfo.SLAAPIURL) > 0 {
+		score, err := getSLAScore(sup.ctx, cfg.StakeInfo.SLAAPIURL, scannerAddress)
+		if err != nil {
+			log.WithError(err).Warn("failed to get the sla score")
+		} else {
+			info.SLAScore = &score
+		}
+	}
+	return info, nil
+}
+
+func getSLAScore(ctx context.Context, slaAPIURL, scannerAddress string) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, slaRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodGet, fmt.Sprintf("%s/%s", strings.TrimSuffix(slaAPIURL, "/"), strings.ToLower(scannerAddress)), nil,
+	)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("sla api responded with status %d", resp.StatusCode)
+	}
+	var slaResp slaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&slaResp); err != nil {
+		return 0, fmt.Errorf("failed to decode the sla response: %v", err)
+	}
+	return slaResp.Statistics.Avg, nil
+}
+
+func (sup *SupervisorService) stakeHealthReports() health.Reports {
+	sup.stakeInfoMu.RLock()
+	reports := stakeReports(sup.stakeInfo, sup.config.Config.StakeInfo.MinSLAScore)
+	sup.stakeInfoMu.RUnlock()
+
+	checkReport := &health.Report{Name: "event.stake-check.time"}
+	checkReport.Details, checkReport.Status = sup.lastStakeCheck.Check(sup.stakeCheckInterval() * 2)
+	return append(reports, checkReport, sup.lastStakeCheckErr.GetReport("event.stake-check.error"))
+}
+
+// stakeReports reports the stake info and marks the values which limit the assignments as lagging.
+func stakeReports(info *stakeInfo, minSLAScore float64) health.Reports {
+	if info == nil {
+		return nil
+	}
+	if !info.Registered {
+		return health.Reports{
+			{Name: "stake.registered", Status: health.StatusLagging, Details: "scanner is not registered"},
+		}
+	}
+
+	enabledReport := &health.Report{Name: "stake.enabled", Status: health.StatusOK, Details: "true"}
+	if !info.Enabled {
+		enabledReport.Status = health.StatusLagging
+		enabledReport.Details = "false"
+	}
+
+	stakeReport := &health.Report{Name: "stake.allocated", Status: health.StatusOK, Details: formatFORT(info.AllocatedStake)}
+	if info.MinStake != nil && info.MaxStake != nil {
+		stakeReport.Details = fmt.Sprintf("%s (min=%s, max=%s)", formatFORT(info.AllocatedStake), formatFORT(info.MinStake), formatFORT(info.MaxStake))
+		if info.AllocatedStake.Cmp(info.MinStake) < 0 {
+			stakeReport.Status = health.StatusLagging
+			stakeReport.Details += " - below the minimum stake"
+		}
+	}
+
+	reports := health.Reports{
+		enabledReport,
+		stakeReport,
+		{Name: "stake.assigned-bots", Status: health.StatusInfo, Details: fmt.Sprint(info.AssignedBots)},
+	}
+	if info.Reward != nil {
+		reports = append(reports, &health.Report{
+			Name:    "rewards.last-epoch",
+			Status:  health.StatusInfo,
+			Details: fmt.Sprintf("epoch %d: %s", info.RewardEpoch, formatFORT(info.Reward)),
+		})
+	}
+	if info.SLAScore != nil {
+		slaReport := &health.Report{Name: "sla.score", Status: health.StatusOK, Details: fmt.Sprintf("%.2f", *info.SLAScore)}
+		if *info.SLAScore < minSLAScore {
+			slaReport.Status = health.StatusLagging
+			slaReport.Details += fmt.Sprintf(" - below %.2f", minSLAScore)
+		}
+		reports = append(reports, slaReport)
+	}
+	return reports
+}
+
+func formatFORT(amount *big.Int) string {
+	if amount == nil {
+		return "unknown"
+	}
+	fort, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), big.NewFloat(params.Ether)).Float64()
+	return fmt.Sprintf("%.2f FORT", fort)
+}
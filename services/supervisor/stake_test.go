@@ -0,0 +1,86 @@
+package supervisor
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/stretchr/testify/require"
+)
+
+func fort(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(params.Ether))
+}
+
+func TestStakeReports(t *testing.T) {
+	r := require.New(t)
+
+	r.Nil(stakeReports(nil, 0.75))
+
+	reports := stakeReports(&stakeInfo{}, 0.75)
+	r.Len(reports, 1)
+	r.Equal(health.StatusLagging, reports[0].Status)
+
+	slaScore := 0.9
+	reports = stakeReports(&stakeInfo{
+		Registered:     true,
+		Enabled:        true,
+		AllocatedStake: fort(1000),
+		MinStake:       fort(500),
+		MaxStake:       fort(3000),
+		AssignedBots:   12,
+		RewardEpoch:    41,
+		Reward:         fort(25),
+		SLAScore:       &slaScore,
+	}, 0.75)
+	stake, ok := reports.NameContains("stake.allocated")
+	r.True(ok)
+	r.Equal(health.StatusOK, stake.Status)
+	r.Equal("1000.00 FORT (min=500.00 FORT, max=3000.00 FORT)", stake.Details)
+	rewards, ok := reports.NameContains("rewards.last-epoch")
+	r.True(ok)
+	r.Equal("epoch 41: 25.00 FORT", rewards.Details)
+	sla, ok := reports.NameContains("sla.score")
+	r.True(ok)
+	r.Equal(health.StatusOK, sla.Status)
+
+	// low stake and poor sla limit the assignments
+	slaScore = 0.5
+	reports = stakeReports(&stakeInfo{
+		Registered:     true,
+		Enabled:        true,
+		AllocatedStake: fort(100),
+		MinStake:       fort(500),
+		MaxStake:       fort(3000),
+		SLAScore:       &slaScore,
+	}, 0.75)
+	stake, _ = reports.NameContains("stake.allocated")
+	r.Equal(health.StatusLagging, stake.Status)
+	sla, _ = reports.NameContains("sla.score")
+	r.Equal(health.StatusLagging, sla.Status)
+	r.Equal("0.50 - below 0.75", sla.Details)
+}
+
+func TestGetSLAScore(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/stats/sla/scanner/0x000000000000000000000000000000000000000a" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"statistics":{"avg":0.93}}`))
+	}))
+	defer server.Close()
+
+	score, err := getSLAScore(context.Background(), server.URL+"/stats/sla/scanner/", "0x000000000000000000000000000000000000000A")
+	r.NoError(err)
+	r.Equal(0.93, score)
+
+	_, err = getSLAScore(context.Background(), server.URL+"/wrong", "0x000000000000000000000000000000000000000A")
+	r.Error(err)
+}
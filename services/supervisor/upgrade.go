@@ -0,0 +1,186 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/utils/httpclient"
+	"github.com/forta-network/forta-node/clients/docker"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// blueGreenContainerNames returns the service containers which are upgraded by starting the
+// new container next to the old one instead of stopping the old one first.
+func blueGreenContainerNames() []string {
+	return []string{
+		config.DockerScannerContainerName,
+		config.DockerJSONRPCProxyContainerName,
+		config.DockerPublicAPIProxyContainerName,
+	}
+}
+
+const (
+	nextContainerSuffix       = "-next"
+	prevContainerSuffix       = "-prev"
+	defaultHealthWaitInterval = time.Second * 5
+)
+
+// errNotHealthy is returned when the new container does not become healthy.
+var errNotHealthy = errors.New("container did not become healthy")
+
+func isBlueGreenContainer(name string) bool {
+	for _, bgName := range blueGreenContainerNames() {
+		if bgName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (sup *SupervisorService) blueGreenEnabled() bool {
+	// the orchestrator handles the upgrades of the service containers
+	return sup.config.Config.AdvancedConfig.BlueGreenUpgrades && !sup.config.Config.Orchestrator.Enable
+}
+
+// keepPrevContainer decides if the given old container should be kept running until
+// the new version is healthy.
+func (sup *SupervisorService) keepPrevContainer(containerName string, container *types.Container) bool {
+	if !sup.blueGreenEnabled() || !isBlueGreenContainer(containerName) {
+		return false
+	}
+	if container.State != "running" {
+		return false
+	}
+	if sup.prevContainers == nil {
+		sup.prevContainers = make(map[string]*types.Container)
+	}
+	sup.prevContainers[containerName] = container
+	log.WithFields(log.Fields{
+		"containerName": containerName,
+		"containerId":   container.ID,
+	}).Info("keeping old service container until the new one is healthy")
+	return true
+}
+
+// startServiceContainer starts a service container. If there is a previous container running
+// with the same name, the new container is started next to it and the previous container is
+// removed only after the new one reports healthy.
+func (sup *SupervisorService) startServiceContainer(cntCfg docker.ContainerConfig) (*docker.Container, error) {
+	prevContainer, ok := sup.prevContainers[cntCfg.Name]
+	if !ok {
+		return sup.client.StartContainer(sup.ctx, cntCfg)
+	}
+	delete(sup.prevContainers, cntCfg.Name)
+
+	logger := log.WithFields(log.Fields{
+		"containerName": cntCfg.Name,
+		"prevContainer": prevContainer.ID,
+	})
+
+	nextCfg := cntCfg
+	nextCfg.Name = cntCfg.Name + nextContainerSuffix
+	nextContainer, err := sup.client.StartContainer(sup.ctx, nextCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the next '%s' container: %v", cntCfg.Name, err)
+	}
+	logger = logger.WithField("nextContainer", nextContainer.ID)
+
+	if err := sup.waitContainerHealthy(nextCfg.Name, nextContainer.ID); err != nil {
+		logger.WithError(err).Warn("next container is not healthy - replacing the old container without overlap")
+		if err := sup.removeContainer(nextContainer.ID); err != nil {
+			return nil, err
+		}
+		if err := sup.removeContainer(prevContainer.ID); err != nil {
+			return nil, err
+		}
+		return sup.client.StartContainer(sup.ctx, cntCfg)
+	}
+
+	// switch the names so that the other containers start resolving the name to the new container
+	if err := sup.client.RenameContainer(sup.ctx, prevContainer.ID, cntCfg.Name+prevContainerSuffix); err != nil {
+		return nil, fmt.Errorf("failed to rename the previous '%s' container: %v", cntCfg.Name, err)
+	}
+	if err := sup.client.RenameContainer(sup.ctx, nextContainer.ID, cntCfg.Name); err != nil {
+		return nil, fmt.Errorf("failed to rename the next '%s' container: %v", cntCfg.Name, err)
+	}
+	logger.Info("switched to the new container")
+
+	if err := sup.client.InterruptContainer(sup.ctx, prevContainer.ID); err != nil {
+		logger.WithError(err).Warn("failed to interrupt the previous container")
+	}
+	if err := sup.client.WaitContainerExit(sup.ctx, prevContainer.ID); err != nil {
+		logger.WithError(err).Warn("failed while waiting for the previous container to exit")
+	}
+	if err := sup.removeContainer(prevContainer.ID); err != nil {
+		return nil, err
+	}
+
+	// keep the original config so that restarts use the right name
+	nextContainer.Name = cntCfg.Name
+	nextContainer.Config = cntCfg
+	return nextContainer, nil
+}
+
+func (sup *SupervisorService) removeContainer(containerID string) error {
+	if err := sup.client.RemoveContainer(sup.ctx, containerID); err != nil {
+		return fmt.Errorf("failed to remove container: %v", err)
+	}
+	if err := sup.client.WaitContainerPrune(sup.ctx, containerID); err != nil {
+		return fmt.Errorf("failed while waiting for container removal: %v", err)
+	}
+	return nil
+}
+
+func (sup *SupervisorService) waitContainerHealthy(containerName, containerID string) error {
+	if err := sup.client.WaitContainerStart(sup.ctx, containerID); err != nil {
+		return err
+	}
+
+	timeout := time.Duration(sup.config.Config.AdvancedConfig.BlueGreenHealthTimeoutSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(sup.ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(defaultHealthWaitInterval)
+	defer ticker.Stop()
+	for {
+		err := sup.checkContainerHealth(containerName)
+		if err == nil {
+			return nil
+		}
+		log.WithError(err).WithField("containerName", containerName).Info("waiting for the container to become healthy")
+		select {
+		case <-ctx.Done():
+			return errNotHealthy
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkContainerHealth checks the health API of the container through the node network.
+func checkContainerHealth(containerName string) error {
+	resp, err := httpclient.Default.Get(fmt.Sprintf("http://%s:%s/health", containerName, config.DefaultHealthPort))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health api responded with status code %d", resp.StatusCode)
+	}
+	var reports health.Reports
+	if err := json.NewDecoder(resp.Body).Decode(&reports); err != nil {
+		return fmt.Errorf("failed to decode health reports: %v", err)
+	}
+	for _, report := range reports {
+		if report.Status == health.StatusDown {
+			return fmt.Errorf("'%s' is down: %s", report.Name, report.Details)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/clients/docker"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPrevContainerID = "test-prev-container-id"
+	testNextContainerID = "test-next-container-id"
+)
+
+func newTestBlueGreenSupervisor(t *testing.T, healthErr error) (*SupervisorService, *mock_clients.MockDockerClient) {
+	ctrl := gomock.NewController(t)
+	dockerClient := mock_clients.NewMockDockerClient(ctrl)
+	sup := &SupervisorService{
+		ctx:    context.Background(),
+		client: dockerClient,
+		checkContainerHealth: func(containerName string) error {
+			return healthErr
+		},
+	}
+	sup.config.Config.AdvancedConfig.BlueGreenUpgrades = true
+	sup.config.Config.AdvancedConfig.BlueGreenHealthTimeoutSeconds = 0
+	sup.keepPrevContainer(config.DockerScannerContainerName, &types.Container{ID: testPrevContainerID, State: "running"})
+	return sup, dockerClient
+}
+
+func TestStartServiceContainer_BlueGreen(t *testing.T) {
+	r := require.New(t)
+
+	sup, dockerClient := newTestBlueGreenSupervisor(t, nil)
+	cntCfg := docker.ContainerConfig{Name: config.DockerScannerContainerName}
+	nextCfg := docker.ContainerConfig{Name: config.DockerScannerContainerName + nextContainerSuffix}
+
+	gomock.InOrder(
+		dockerClient.EXPECT().StartContainer(sup.ctx, nextCfg).Return(&docker.Container{ID: testNextContainerID, Name: nextCfg.Name, Config: nextCfg}, nil),
+		dockerClient.EXPECT().WaitContainerStart(sup.ctx, testNextContainerID).Return(nil),
+		dockerClient.EXPECT().RenameContainer(sup.ctx, testPrevContainerID, config.DockerScannerContainerName+prevContainerSuffix).Return(nil),
+		dockerClient.EXPECT().RenameContainer(sup.ctx, testNextContainerID, config.DockerScannerContainerName).Return(nil),
+		dockerClient.EXPECT().InterruptContainer(sup.ctx, testPrevContainerID).Return(nil),
+		dockerClient.EXPECT().WaitContainerExit(sup.ctx, testPrevContainerID).Return(nil),
+		dockerClient.EXPECT().RemoveContainer(sup.ctx, testPrevContainerID).Return(nil),
+		dockerClient.EXPECT().WaitContainerPrune(sup.ctx, testPrevContainerID).Return(nil),
+	)
+
+	container, err := sup.startServiceContainer(cntCfg)
+	r.NoError(err)
+	r.Equal(testNextContainerID, container.ID)
+	r.Equal(config.DockerScannerContainerName, container.Name)
+	r.Equal(config.DockerScannerContainerName, container.Config.Name)
+	r.Empty(sup.prevContainers)
+}
+
+func TestStartServiceContainer_NotHealthy(t *testing.T) {
+	r := require.New(t)
+
+	sup, dockerClient := newTestBlueGreenSupervisor(t, errors.New("not healthy"))
+	cntCfg := docker.ContainerConfig{Name: config.DockerScannerContainerName}
+	nextCfg := docker.ContainerConfig{Name: config.DockerScannerContainerName + nextContainerSuffix}
+
+	gomock.InOrder(
+		dockerClient.EXPECT().StartContainer(sup.ctx, nextCfg).Return(&docker.Container{ID: testNextContainerID}, nil),
+		dockerClient.EXPECT().WaitContainerStart(sup.ctx, testNextContainerID).Return(nil),
+		dockerClient.EXPECT().RemoveContainer(sup.ctx, testNextContainerID).Return(nil),
+		dockerClient.EXPECT().WaitContainerPrune(sup.ctx, testNextContainerID).Return(nil),
+		dockerClient.EXPECT().RemoveContainer(sup.ctx, testPrevContainerID).Return(nil),
+		dockerClient.EXPECT().WaitContainerPrune(sup.ctx, testPrevContainerID).Return(nil),
+		dockerClient.EXPECT().StartContainer(sup.ctx, cntCfg).Return(&docker.Container{ID: testScannerContainerID}, nil),
+	)
+
+	container, err := sup.startServiceContainer(cntCfg)
+	r.NoError(err)
+	r.Equal(testScannerContainerID, container.ID)
+}
+
+func TestStartServiceContainer_NoPrevContainer(t *testing.T) {
+	r := require.New(t)
+
+	sup, dockerClient := newTestBlueGreenSupervisor(t, nil)
+	cntCfg := docker.ContainerConfig{Name: config.DockerJSONRPCProxyContainerName}
+
+	dockerClient.EXPECT().StartContainer(sup.ctx, cntCfg).Return(&docker.Container{ID: testProxyContainerID}, nil)
+
+	container, err := sup.startServiceContainer(cntCfg)
+	r.NoError(err)
+	r.Equal(testProxyContainerID, container.ID)
+}
+
+func TestStopContainer_UpgradeHandoff(t *testing.T) {
+	r := require.New(t)
+
+	sup, dockerClient := newTestBlueGreenSupervisor(t, nil)
+	sup.config.Config.FortaDir = t.TempDir()
+	cnt := &Container{Container: docker.Container{ID: testScannerContainerID, Name: config.DockerScannerContainerName}}
+
+	// not upgrading: the container is stopped
+	dockerClient.EXPECT().InterruptContainer(sup.ctx, testScannerContainerID).Return(nil)
+	sup.stopContainer(sup.ctx, cnt, 0)
+
+	// upgrading: the next supervisor replaces the container
+	r.NoError(store.MarkUpgradeHandoff(sup.config.Config.FortaDir))
+	sup.stopContainer(sup.ctx, cnt, 0)
+}
@@ -40,6 +40,10 @@ type UpdaterService struct {
 
 	updateDelay         time.Duration
 	updateCheckInterval time.Duration
+	pinnedVersion       string
+	rolloutBucket       int
+
+	now func() time.Time
 
 	errCounter *nodeutils.ErrorCounter
 
@@ -47,11 +51,15 @@ type UpdaterService struct {
 	lastErr            health.ErrorTracker
 	latestVersion      health.MessageTracker
 	latestIsPrerelease health.MessageTracker
+	pendingVersion     health.MessageTracker
 }
 
-// NewUpdaterService creates a new updater service.
+// NewUpdaterService creates a new updater service. The releases with a different version than the
+// pinned version are ignored. A release is served only after its staged rollout reaches
+// the rollout bucket (0-99) of this node. A negative bucket disables the staged rollout.
 func NewUpdaterService(ctx context.Context, svs store.ScannerReleaseStore,
 	port string, updateDelaySeconds, updateCheckIntervalSeconds int,
+	pinnedVersion string, rolloutBucket int,
 ) *UpdaterService {
 	if updateCheckIntervalSeconds == 0 {
 		updateCheckIntervalSeconds = defaultUpdateCheckIntervalSeconds
@@ -63,6 +71,9 @@ func NewUpdaterService(ctx context.Context, svs store.ScannerReleaseStore,
 		srs:                 svs,
 		updateDelay:         time.Duration(updateDelaySeconds) * time.Second,
 		updateCheckInterval: time.Duration(updateCheckIntervalSeconds) * time.Second,
+		pinnedVersion:       pinnedVersion,
+		rolloutBucket:       rolloutBucket,
+		now:                 time.Now,
 		errCounter: nodeutils.NewErrorCounter(uint(maxConsecutiveUpdateErrors), func(err error) bool {
 			return err != nil // all non-nil errors are critical errors
 		}),
@@ -150,6 +161,29 @@ func (updater *UpdaterService) updateLatestReleaseWithDelay(delay time.Duration)
 		return nil
 	}
 
+	version := latest.ReleaseManifest.Release.Version
+	logger := log.WithFields(log.Fields{
+		"release": latest.Reference,
+		"version": version,
+	})
+	if len(updater.pinnedVersion) > 0 && version != updater.pinnedVersion {
+		logger.WithField("pinnedVersion", updater.pinnedVersion).Info("ignoring the release which is not the pinned version")
+		return nil
+	}
+	// the pinned version is chosen by the operator so it does not need to wait for the rollout
+	if len(updater.pinnedVersion) == 0 && updater.rolloutBucket >= 0 {
+		percentage := RolloutPercentage(latest.ReleaseManifest, updater.now())
+		if updater.rolloutBucket >= percentage {
+			logger.WithFields(log.Fields{
+				"percentage": percentage,
+				"bucket":     updater.rolloutBucket,
+			}).Info("release is not rolled out to this node yet")
+			updater.pendingVersion.Set(version)
+			return nil
+		}
+	}
+	updater.pendingVersion.Set("")
+
 	// so that all scanners don't update simultaneously, this waits a period of time
 	if delay > 0 {
 		log.WithFields(log.Fields{
@@ -247,5 +281,28 @@ func (updater *UpdaterService) Health() health.Reports {
 		updater.lastErr.GetReport("event.checked.error"),
 		updater.latestVersion.GetReport("latest.version"),
 		updater.latestIsPrerelease.GetReport("latest.is-prerelease"),
+		updater.pendingVersion.GetReport("pending.version"),
+	}
+}
+
+// RolloutPercentage returns the percentage of the nodes which the release is rolled out to. The rollout
+// starts at the release timestamp and reaches all nodes after the auto-update hours of the release.
+func RolloutPercentage(rm release.ReleaseManifest, now time.Time) int {
+	releasedAt, err := time.Parse(time.RFC3339, rm.Release.Timestamp)
+	if err != nil {
+		return 100 // no way to stage the rollout
+	}
+	rolloutHours := rm.Release.Config.AutoUpdateInHours
+	if rolloutHours <= 0 {
+		rolloutHours = release.DefaultAutoUpdateHours
+	}
+	elapsed := now.Sub(releasedAt)
+	if elapsed <= 0 {
+		return 0
+	}
+	percentage := int(elapsed * 100 / (time.Duration(rolloutHours) * time.Hour))
+	if percentage > 100 {
+		return 100
 	}
+	return percentage
 }
@@ -2,9 +2,12 @@ package updater
 
 import (
 	"context"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/release"
 	"github.com/forta-network/forta-node/store"
 	mock_store "github.com/forta-network/forta-node/store/mocks"
-	"testing"
 
 	"github.com/stretchr/testify/require"
 
@@ -21,7 +24,7 @@ func TestUpdaterService_UpdateLatestRelease(t *testing.T) {
 
 	svs := mock_store.NewMockScannerReleaseStore(gomock.NewController(t))
 	updater := NewUpdaterService(
-		context.Background(), svs, "8080", testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
+		context.Background(), svs, "8080", testUpdateDelaySeconds, testUpdateCheckIntervalSeconds, "", -1,
 	)
 
 	svs.EXPECT().GetRelease(gomock.Any()).Return(&store.ScannerRelease{
@@ -37,7 +40,7 @@ func TestUpdaterService_UpdateLatestReleaseNotCached(t *testing.T) {
 
 	svs := mock_store.NewMockScannerReleaseStore(gomock.NewController(t))
 	updater := NewUpdaterService(
-		context.Background(), svs, "8080", testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
+		context.Background(), svs, "8080", testUpdateDelaySeconds, testUpdateCheckIntervalSeconds, "", -1,
 	)
 
 	svs.EXPECT().GetRelease(gomock.Any()).Return(&store.ScannerRelease{
@@ -60,7 +63,7 @@ func TestUpdaterService_UpdateLatestReleaseAbort(t *testing.T) {
 
 	svs := mock_store.NewMockScannerReleaseStore(gomock.NewController(t))
 	updater := NewUpdaterService(
-		context.Background(), svs, "8080", testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
+		context.Background(), svs, "8080", testUpdateDelaySeconds, testUpdateCheckIntervalSeconds, "", -1,
 	)
 
 	initalLatestRef := updater.latestReference
@@ -78,3 +81,76 @@ func TestUpdaterService_UpdateLatestReleaseAbort(t *testing.T) {
 	// update should be ineffective and be aborted
 	r.Equal(initalLatestRef, updater.latestReference)
 }
+
+func testReleaseWithTimestamp(reference, version string, timestamp time.Time) *store.ScannerRelease {
+	return &store.ScannerRelease{
+		Reference: reference,
+		ReleaseManifest: release.ReleaseManifest{
+			Release: release.Release{
+				Version:   version,
+				Timestamp: timestamp.Format(time.RFC3339),
+				Config: release.ReleaseConfig{
+					AutoUpdateInHours: 10,
+				},
+			},
+		},
+	}
+}
+
+func TestUpdaterService_UpdateLatestReleaseStaged(t *testing.T) {
+	r := require.New(t)
+
+	svs := mock_store.NewMockScannerReleaseStore(gomock.NewController(t))
+	updater := NewUpdaterService(
+		context.Background(), svs, "8080", 0, testUpdateCheckIntervalSeconds, "", 50,
+	)
+	releasedAt := time.Now().Truncate(time.Second)
+
+	// 30% of the nodes after 3 hours
+	updater.now = func() time.Time { return releasedAt.Add(time.Hour * 3) }
+	svs.EXPECT().GetRelease(gomock.Any()).Return(testReleaseWithTimestamp("reference1", "v1", releasedAt), nil).Times(1)
+	r.NoError(updater.updateLatestReleaseWithDelay(0))
+	r.Empty(updater.latestReference)
+
+	// 60% of the nodes after 6 hours
+	updater.now = func() time.Time { return releasedAt.Add(time.Hour * 6) }
+	svs.EXPECT().GetRelease(gomock.Any()).Return(testReleaseWithTimestamp("reference1", "v1", releasedAt), nil).Times(1)
+	r.NoError(updater.updateLatestReleaseWithDelay(0))
+	r.Equal("reference1", updater.latestReference)
+}
+
+func TestUpdaterService_UpdateLatestReleasePinned(t *testing.T) {
+	r := require.New(t)
+
+	svs := mock_store.NewMockScannerReleaseStore(gomock.NewController(t))
+	updater := NewUpdaterService(
+		context.Background(), svs, "8080", 0, testUpdateCheckIntervalSeconds, "v1", 99,
+	)
+	releasedAt := time.Now().Truncate(time.Second)
+
+	// the pinned version does not wait for the rollout
+	svs.EXPECT().GetRelease(gomock.Any()).Return(testReleaseWithTimestamp("reference1", "v1", releasedAt), nil).Times(1)
+	r.NoError(updater.updateLatestReleaseWithDelay(0))
+	r.Equal("reference1", updater.latestReference)
+
+	svs.EXPECT().GetRelease(gomock.Any()).Return(testReleaseWithTimestamp("reference2", "v2", releasedAt), nil).Times(1)
+	r.NoError(updater.updateLatestReleaseWithDelay(0))
+	r.Equal("reference1", updater.latestReference)
+}
+
+func TestRolloutPercentage(t *testing.T) {
+	r := require.New(t)
+
+	releasedAt := time.Now().Truncate(time.Second)
+	rm := testReleaseWithTimestamp("", "", releasedAt).ReleaseManifest
+
+	r.Equal(0, RolloutPercentage(rm, releasedAt.Add(-time.Hour)))
+	r.Equal(25, RolloutPercentage(rm, releasedAt.Add(time.Hour*5/2)))
+	r.Equal(100, RolloutPercentage(rm, releasedAt.Add(time.Hour*11)))
+
+	rm.Release.Config.AutoUpdateInHours = 0
+	r.Equal(50, RolloutPercentage(rm, releasedAt.Add(time.Hour*12)))
+
+	rm.Release.Timestamp = ""
+	r.Equal(100, RolloutPercentage(rm, releasedAt))
+}
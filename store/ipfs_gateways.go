@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/faults"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Gateway ordering
+const (
+	GatewayOrderSequential = "sequential"
+	GatewayOrderRandom     = "random"
+)
+
+// publicIPFSGateways are tried after the configured gateways if the failover is enabled.
+var publicIPFSGateways = []string{
+	"https://cloudflare-ipfs.com",
+	"https://ipfs.io",
+}
+
+const (
+	defaultGatewayTimeout = time.Second * 10
+	defaultGatewayBackoff = time.Second
+	maxGatewayBackoff     = time.Second * 30
+	maxManifestSize       = 10 * 1024 * 1024
+)
+
+var errGatewayNotFound = errors.New("not found")
+
+// gatewayPool fetches the files from the first gateway which serves them.
+type gatewayPool struct {
+	gateways       []string
+	order          string
+	timeout        time.Duration
+	retries        int
+	initialBackoff time.Duration
+	httpClient     *http.Client
+
+	served     map[string]int
+	failed     map[string]int
+	lastServed health.MessageTracker
+	mu         sync.Mutex
+}
+
+func newGatewayPool(cfg config.IPFSConfig) *gatewayPool {
+	gateways := append([]string{cfg.GatewayURL}, cfg.GatewayURLs...)
+	if cfg.PublicGatewayFailover {
+		gateways = append(gateways, publicIPFSGateways...)
+	}
+	timeout := time.Duration(cfg.GatewayTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultGatewayTimeout
+	}
+	return &gatewayPool{
+		gateways:       uniqueStrings(gateways),
+		order:          cfg.GatewayOrder,
+		timeout:        timeout,
+		retries:        cfg.GatewayRetries,
+		initialBackoff: defaultGatewayBackoff,
+		httpClient:     &http.Client{Transport: faults.Transport(faults.IPFSNotFound, nil)},
+		served:         make(map[string]int),
+		failed:         make(map[string]int),
+	}
+}
+
+func uniqueStrings(values []string) (unique []string) {
+	seen := make(map[string]bool)
+	for _, value := range values {
+		if len(value) == 0 || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return
+}
+
+// GetBytes tries the gateways in order and retries all of them with exponential backoff.
+func (gp *gatewayPool) GetBytes(ctx context.Context, reference string) ([]byte, error) {
+	logger := log.WithField("reference", reference)
+	backoff := gp.initialBackoff
+	var err error
+	for attempt := 0; attempt <= gp.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > maxGatewayBackoff {
+				backoff = maxGatewayBackoff
+			}
+		}
+		for _, gateway := range gp.orderedGateways() {
+			var b []byte
+			b, err = gp.getFromGateway(ctx, gateway, reference)
+			if err == nil {
+				gp.setServed(gateway, reference)
+				logger.WithField("gateway", gatewayHost(gateway)).Debug("fetched file from ipfs gateway")
+				return b, nil
+			}
+			gp.setFailed(gateway)
+			logger.WithError(err).WithField("gateway", gatewayHost(gateway)).Warn("failed to fetch file from ipfs gateway")
+		}
+	}
+	return nil, fmt.Errorf("failed to fetch '%s' from all ipfs gateways: %v", reference, err)
+}
+
+func (gp *gatewayPool) orderedGateways() []string {
+	gateways := make([]string, len(gp.gateways))
+	copy(gateways, gp.gateways)
+	if gp.order == GatewayOrderRandom {
+		rand.Shuffle(len(gateways), func(i, j int) {
+			gateways[i], gateways[j] = gateways[j], gateways[i]
+		})
+	}
+	return gateways
+}
+
+func (gp *gatewayPool) getFromGateway(ctx context.Context, gateway, reference string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, gp.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/ipfs/%s", gateway, reference), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := gp.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest:
+		return nil, errGatewayNotFound
+	default:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+}
+
+func (gp *gatewayPool) setServed(gateway, reference string) {
+	gp.mu.Lock()
+	gp.served[gateway]++
+	gp.mu.Unlock()
+	gp.lastServed.Set(fmt.Sprintf("%s from %s", reference, gatewayHost(gateway)))
+}
+
+func (gp *gatewayPool) setFailed(gateway string) {
+	gp.mu.Lock()
+	gp.failed[gateway]++
+	gp.mu.Unlock()
+}
+
+// gatewayHost avoids reporting the credentials or the paths in the gateway URLs.
+func gatewayHost(gateway string) string {
+	u, err := url.Parse(gateway)
+	if err != nil {
+		return "invalid"
+	}
+	return u.Host
+}
+
+// Health implements the health.Reporter interface.
+func (gp *gatewayPool) Health() (reports health.Reports) {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+	for _, gateway := range gp.gateways {
+		host := gatewayHost(gateway)
+		reports = append(reports,
+			&health.Report{
+				Name:    fmt.Sprintf("ipfs.gateway.%s.served", host),
+				Status:  health.StatusInfo,
+				Details: strconv.Itoa(gp.served[gateway]),
+			},
+			&health.Report{
+				Name:    fmt.Sprintf("ipfs.gateway.%s.failed", host),
+				Status:  health.StatusInfo,
+				Details: strconv.Itoa(gp.failed[gateway]),
+			},
+		)
+	}
+	return append(reports, gp.lastServed.GetReport("ipfs.gateway.last-served"))
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifestRef = "QmTestManifest"
+
+func TestGatewayPool_Failover(t *testing.T) {
+	r := require.New(t)
+
+	failingGateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failingGateway.Close()
+	workingGateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("/ipfs/"+testManifestRef, req.URL.Path)
+		w.Write([]byte("manifest"))
+	}))
+	defer workingGateway.Close()
+
+	gp := newGatewayPool(config.IPFSConfig{
+		GatewayURL:  failingGateway.URL,
+		GatewayURLs: []string{workingGateway.URL, failingGateway.URL},
+	})
+	r.Len(gp.gateways, 2)
+
+	b, err := gp.GetBytes(context.Background(), testManifestRef)
+	r.NoError(err)
+	r.Equal("manifest", string(b))
+	r.Equal(1, gp.failed[failingGateway.URL])
+	r.Equal(1, gp.served[workingGateway.URL])
+
+	report, ok := gp.Health().GetByName("ipfs.gateway.last-served")
+	r.True(ok)
+	r.Contains(report.Details, testManifestRef)
+}
+
+func TestGatewayPool_Retry(t *testing.T) {
+	r := require.New(t)
+
+	var requests int32
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("manifest"))
+	}))
+	defer gateway.Close()
+
+	gp := newGatewayPool(config.IPFSConfig{GatewayURL: gateway.URL, GatewayRetries: 2})
+	gp.initialBackoff = time.Millisecond
+
+	b, err := gp.GetBytes(context.Background(), testManifestRef)
+	r.NoError(err)
+	r.Equal("manifest", string(b))
+	r.EqualValues(3, requests)
+
+	// fails after the retries
+	atomic.StoreInt32(&requests, 0)
+	gp.retries = 0
+	_, err = gp.GetBytes(context.Background(), testManifestRef)
+	r.Error(err)
+}
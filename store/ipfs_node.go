@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/ipfsclient"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultLocalNodeTimeout = time.Second * 5
+	defaultPinTimeout       = time.Minute
+)
+
+// ipfsNodeClient is the subset of the IPFS API used for the manifests.
+type ipfsNodeClient interface {
+	CatWithContext(ctx context.Context, path string) (io.ReadCloser, error)
+	PinWithContext(ctx context.Context, path string) error
+	UnpinWithContext(ctx context.Context, path string) error
+}
+
+// localIPFSNode fetches the files through the API of a local IPFS daemon and keeps
+// the manifests of the assigned bots pinned.
+type localIPFSNode struct {
+	client       ipfsNodeClient
+	timeout      time.Duration
+	pinEnabled   bool
+	pinned       map[string]bool
+	mu           sync.Mutex
+	lastErr      health.ErrorTracker
+	lastPinErr   health.ErrorTracker
+	pinnedCount  health.NumberTracker
+	lastPinCheck health.TimeTracker
+}
+
+func newLocalIPFSNode(cfg config.IPFSConfig) *localIPFSNode {
+	if len(cfg.LocalNodeAPIURL) == 0 {
+		return nil
+	}
+	return &localIPFSNode{
+		client:     ipfsclient.New(cfg.LocalNodeAPIURL),
+		timeout:    defaultLocalNodeTimeout,
+		pinEnabled: cfg.PinManifests,
+		pinned:     make(map[string]bool),
+	}
+}
+
+// GetBytes gets the file from the local node.
+func (node *localIPFSNode) GetBytes(ctx context.Context, reference string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, node.timeout)
+	defer cancel()
+
+	r, err := node.client.CatWithContext(ctx, reference)
+	if err != nil {
+		node.lastErr.Set(err)
+		return nil, fmt.Errorf("failed to get '%s' from the local ipfs node: %v", reference, err)
+	}
+	defer r.Close()
+	b, err := io.ReadAll(io.LimitReader(r, maxManifestSize))
+	node.lastErr.Set(err)
+	return b, err
+}
+
+// PinManifests pins the given manifests and unpins the ones which were pinned before
+// but are not in the list anymore.
+func (node *localIPFSNode) PinManifests(ctx context.Context, references []string) {
+	if !node.pinEnabled {
+		return
+	}
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, defaultPinTimeout)
+	defer cancel()
+
+	var lastErr error
+	keep := make(map[string]bool)
+	for _, reference := range references {
+		keep[reference] = true
+		if node.pinned[reference] {
+			continue
+		}
+		if err := node.client.PinWithContext(ctx, reference); err != nil {
+			log.WithError(err).WithField("reference", reference).Warn("failed to pin manifest")
+			lastErr = err
+			continue
+		}
+		node.pinned[reference] = true
+	}
+	for reference := range node.pinned {
+		if keep[reference] {
+			continue
+		}
+		if err := node.client.UnpinWithContext(ctx, reference); err != nil {
+			log.WithError(err).WithField("reference", reference).Warn("failed to unpin manifest")
+			lastErr = err
+			continue
+		}
+		delete(node.pinned, reference)
+	}
+
+	node.lastPinErr.Set(lastErr)
+	node.pinnedCount.Set(float64(len(node.pinned)))
+	node.lastPinCheck.Set()
+}
+
+// Health implements the health.Reporter interface.
+func (node *localIPFSNode) Health() health.Reports {
+	reports := health.Reports{
+		node.lastErr.GetReport("ipfs.local-node.last-error"),
+	}
+	if node.pinEnabled {
+		reports = append(reports,
+			node.lastPinErr.GetReport("ipfs.local-node.pin.last-error"),
+			node.pinnedCount.GetReport("ipfs.local-node.pin.count"),
+			node.lastPinCheck.GetReport("ipfs.local-node.pin.last-check"),
+		)
+	}
+	return reports
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+type testIPFSNodeClient struct {
+	files    map[string]string
+	pinned   map[string]bool
+	failPins bool
+}
+
+func (client *testIPFSNodeClient) CatWithContext(ctx context.Context, path string) (io.ReadCloser, error) {
+	content, ok := client.files[path]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (client *testIPFSNodeClient) PinWithContext(ctx context.Context, path string) error {
+	if client.failPins {
+		return errors.New("failed to pin")
+	}
+	client.pinned[path] = true
+	return nil
+}
+
+func (client *testIPFSNodeClient) UnpinWithContext(ctx context.Context, path string) error {
+	delete(client.pinned, path)
+	return nil
+}
+
+func TestLocalIPFSNode_PinManifests(t *testing.T) {
+	r := require.New(t)
+
+	client := &testIPFSNodeClient{pinned: make(map[string]bool)}
+	node := newLocalIPFSNode(config.IPFSConfig{LocalNodeAPIURL: "http://localhost:5001", PinManifests: true})
+	node.client = client
+
+	node.PinManifests(context.Background(), []string{"Qm1", "Qm2"})
+	r.Equal(map[string]bool{"Qm1": true, "Qm2": true}, client.pinned)
+
+	// the unassigned manifest is unpinned
+	node.PinManifests(context.Background(), []string{"Qm2", "Qm3"})
+	r.Equal(map[string]bool{"Qm2": true, "Qm3": true}, client.pinned)
+
+	// the failed pins are retried next time
+	client.failPins = true
+	node.PinManifests(context.Background(), []string{"Qm2", "Qm3", "Qm4"})
+	r.False(node.pinned["Qm4"])
+	report, ok := node.Health().GetByName("ipfs.local-node.pin.last-error")
+	r.True(ok)
+	r.Contains(report.Details, "failed to pin")
+
+	client.failPins = false
+	node.PinManifests(context.Background(), []string{"Qm2", "Qm3", "Qm4"})
+	r.True(client.pinned["Qm4"])
+}
+
+func TestManifestClient_LocalNodeFallback(t *testing.T) {
+	r := require.New(t)
+
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"manifest":{"agentId":"gateway"}}`))
+	}))
+	defer gateway.Close()
+
+	mc, err := NewManifestClient(config.IPFSConfig{
+		GatewayURL:      gateway.URL,
+		LocalNodeAPIURL: "http://localhost:5001",
+	}, "")
+	r.NoError(err)
+	mc.node.client = &testIPFSNodeClient{
+		files: map[string]string{"Qm1": `{"manifest":{"agentId":"local"}}`},
+	}
+
+	botManifest, err := mc.GetBotManifest(context.Background(), "Qm1")
+	r.NoError(err)
+	r.Equal("local", *botManifest.Manifest.AgentID)
+
+	// falls back to the gateway when the local node does not have it
+	botManifest, err = mc.GetBotManifest(context.Background(), "Qm2")
+	r.NoError(err)
+	r.Equal("gateway", *botManifest.Manifest.AgentID)
+}
@@ -0,0 +1,397 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/manifest"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxBotDependencies    = 3
+	maxBotTxFilterEntries = 1000
+	maxBotEgressHosts     = 20
+)
+
+var (
+	botDependencyNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+	botEgressHostRegexp     = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+)
+
+// BotManifest is a bot manifest with the fields which are only meaningful to the node.
+type BotManifest struct {
+	*manifest.SignedAgentManifest
+	Dependencies     []config.BotDependency
+	JsonRpcRateLimit *config.RateLimitConfig
+	RequestLimits    *config.BotRequestLimits
+	// TraceContext tells if the bot wants to receive the trace context in the gRPC metadata.
+	TraceContext bool
+	// ReorgEvents tells if the bot wants to receive the canonical blocks after a reorg.
+	ReorgEvents bool
+	// MempoolEvents tells if the bot wants to receive the pending txs.
+	MempoolEvents bool
+	// TxFilter selects the txs which the bot wants to receive.
+	TxFilter *config.BotTxFilter
+	// BestEffort tells if the bot can shed load first when the node is overloaded.
+	BestEffort bool
+	// EgressHosts are the external hosts which the bot connects to.
+	EgressHosts []string
+	// Capabilities are the capability flags declared by the bot.
+	Capabilities *config.BotCapabilities
+
+	// rawManifest is the signed part of the manifest as it was fetched.
+	rawManifest json.RawMessage
+}
+
+// botManifestExtensions is used for decoding the node-specific manifest fields.
+type botManifestExtensions struct {
+	Manifest struct {
+		Dependencies     []config.BotDependency   `json:"dependencies"`
+		JsonRpcRateLimit *config.RateLimitConfig  `json:"jsonRpcRateLimit"`
+		RequestLimits    *config.BotRequestLimits `json:"requestLimits"`
+		TraceContext     bool                     `json:"traceContext"`
+		ReorgEvents      bool                     `json:"reorgEvents"`
+		MempoolEvents    bool                     `json:"mempoolEvents"`
+		TxFilter         *config.BotTxFilter      `json:"txFilter"`
+		BestEffort       bool                     `json:"bestEffort"`
+		EgressHosts      []string                 `json:"egressHosts"`
+		// Capabilities are decoded separately so that the unknown or invalid flags do not
+		// invalidate the manifest.
+		Capabilities json.RawMessage `json:"capabilities"`
+	} `json:"manifest"`
+}
+
+// parseBotCapabilities reads the known capability flags and ignores the rest.
+func parseBotCapabilities(raw json.RawMessage) *config.BotCapabilities {
+	if len(raw) == 0 {
+		return nil
+	}
+	var flags map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &flags); err != nil {
+		log.WithError(err).Warn("ignoring invalid bot capabilities")
+		return nil
+	}
+	if flags == nil {
+		return nil
+	}
+	var (
+		capabilities config.BotCapabilities
+		err          error
+	)
+	for name, value := range flags {
+		switch name {
+		case "traces":
+			err = json.Unmarshal(value, &capabilities.Traces)
+		case "mempool":
+			err = json.Unmarshal(value, &capabilities.Mempool)
+		case "healthChecks":
+			err = json.Unmarshal(value, &capabilities.HealthChecks)
+		case "sharding":
+			err = json.Unmarshal(value, &capabilities.Sharding)
+		case "maxBlockLag":
+			err = json.Unmarshal(value, &capabilities.MaxBlockLag)
+		case "payloadRefs":
+			err = json.Unmarshal(value, &capabilities.PayloadRefs)
+		default:
+			log.WithField("capability", name).Debug("ignoring unknown bot capability")
+			continue
+		}
+		if err != nil {
+			log.WithError(err).WithField("capability", name).Warn("ignoring invalid bot capability")
+		}
+	}
+	return &capabilities
+}
+
+type botManifestClient interface {
+	GetBotManifest(ctx context.Context, reference string) (*BotManifest, error)
+}
+
+type manifestClient struct {
+	cache    *manifestCache
+	node     *localIPFSNode
+	gateways *gatewayPool
+}
+
+// NewManifestClient creates a new manifest client which can also decode the node-specific manifest fields.
+// The manifests are fetched from the local IPFS node if configured and then from the first available gateway.
+// The fetched manifests are kept in the cache dir if it is not empty.
+func NewManifestClient(cfg config.IPFSConfig, cacheDir string) (*manifestClient, error) {
+	if len(cfg.GatewayURL) == 0 {
+		return nil, errors.New("no ipfs gateway url")
+	}
+	cache, err := newManifestCache(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	return &manifestClient{
+		cache:    cache,
+		node:     newLocalIPFSNode(cfg),
+		gateways: newGatewayPool(cfg),
+	}, nil
+}
+
+// GetAgentManifest implements the manifest.Client interface.
+func (c *manifestClient) GetAgentManifest(ctx context.Context, reference string) (*manifest.SignedAgentManifest, error) {
+	botManifest, err := c.GetBotManifest(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	return botManifest.SignedAgentManifest, nil
+}
+
+// GetBotManifest gets the manifest together with the node-specific fields.
+func (c *manifestClient) GetBotManifest(ctx context.Context, reference string) (*BotManifest, error) {
+	b, err := c.getBytes(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	var signedManifest manifest.SignedAgentManifest
+	if err := json.Unmarshal(b, &signedManifest); err != nil {
+		return nil, err
+	}
+	var extensions botManifestExtensions
+	if err := json.Unmarshal(b, &extensions); err != nil {
+		return nil, err
+	}
+	var signedPart struct {
+		Manifest json.RawMessage `json:"manifest"`
+	}
+	if err := json.Unmarshal(b, &signedPart); err != nil {
+		return nil, err
+	}
+	return &BotManifest{
+		SignedAgentManifest: &signedManifest,
+		Dependencies:        extensions.Manifest.Dependencies,
+		JsonRpcRateLimit:    extensions.Manifest.JsonRpcRateLimit,
+		RequestLimits:       extensions.Manifest.RequestLimits,
+		TraceContext:        extensions.Manifest.TraceContext,
+		ReorgEvents:         extensions.Manifest.ReorgEvents,
+		MempoolEvents:       extensions.Manifest.MempoolEvents,
+		TxFilter:            extensions.Manifest.TxFilter,
+		BestEffort:          extensions.Manifest.BestEffort,
+		EgressHosts:         extensions.Manifest.EgressHosts,
+		Capabilities:        parseBotCapabilities(extensions.Manifest.Capabilities),
+		rawManifest:         signedPart.Manifest,
+	}, nil
+}
+
+func (c *manifestClient) getBytes(ctx context.Context, reference string) ([]byte, error) {
+	if c.cache != nil {
+		if b, ok := c.cache.Get(reference); ok {
+			return b, nil
+		}
+	}
+	b, err := c.fetchBytes(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	if c.cache != nil {
+		if err := c.cache.Put(reference, b); err != nil {
+			log.WithError(err).WithField("reference", reference).Warn("failed to cache manifest")
+		}
+	}
+	return b, nil
+}
+
+func (c *manifestClient) fetchBytes(ctx context.Context, reference string) ([]byte, error) {
+	if c.node != nil {
+		b, err := c.node.GetBytes(ctx, reference)
+		if err == nil {
+			return b, nil
+		}
+		log.WithError(err).Warn("falling back to the ipfs gateways")
+	}
+	return c.gateways.GetBytes(ctx, reference)
+}
+
+// PinManifests keeps only the given manifests pinned on the local IPFS node.
+func (c *manifestClient) PinManifests(ctx context.Context, references []string) {
+	if c.node != nil {
+		c.node.PinManifests(ctx, references)
+	}
+}
+
+// Health implements the health.Reporter interface.
+func (c *manifestClient) Health() health.Reports {
+	reports := c.gateways.Health()
+	if c.node != nil {
+		reports = append(reports, c.node.Health()...)
+	}
+	return reports
+}
+
+// getBotManifest gets the extended manifest if the client supports it.
+func getBotManifest(ctx context.Context, mc manifest.Client, reference string) (*BotManifest, error) {
+	if bmc, ok := mc.(botManifestClient); ok {
+		return bmc.GetBotManifest(ctx, reference)
+	}
+	signedManifest, err := mc.GetAgentManifest(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	return &BotManifest{SignedAgentManifest: signedManifest}, nil
+}
+
+// validateBotManifest checks the required fields and the signature of the manifest.
+func validateBotManifest(cfg config.Config, botManifest *BotManifest, owner string) error {
+	if err := botManifest.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidBot, err)
+	}
+	if !cfg.Registry.VerifyManifestSignatures {
+		return nil
+	}
+	if err := verifyManifestSignature(botManifest, owner); err != nil {
+		return fmt.Errorf("%w: invalid manifest signature: %v", errInvalidBot, err)
+	}
+	return nil
+}
+
+// manifestChainIDs returns the chains declared in the manifest. The bots are filtered by
+// these after loading so that the chain mismatches are visible.
+func manifestChainIDs(botManifest *BotManifest) []int {
+	var chainIDs []int
+	for _, chainID := range botManifest.Manifest.ChainIDs {
+		chainIDs = append(chainIDs, int(chainID))
+	}
+	return chainIDs
+}
+
+func manifestName(botManifest *BotManifest) string {
+	if botManifest.Manifest.Name == nil {
+		return ""
+	}
+	return *botManifest.Manifest.Name
+}
+
+// verifyManifestSignature checks that the manifest was signed by the owner. The developer tools sign
+// the keccak256 hash of the manifest JSON with the Ethereum signature format.
+func verifyManifestSignature(botManifest *BotManifest, owner string) error {
+	if len(botManifest.Signature) == 0 {
+		return errors.New("no signature")
+	}
+	if len(owner) == 0 {
+		return errors.New("no owner")
+	}
+	rawManifest := botManifest.rawManifest
+	if len(rawManifest) == 0 {
+		b, err := json.Marshal(botManifest.Manifest)
+		if err != nil {
+			return err
+		}
+		rawManifest = b
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, rawManifest); err != nil {
+		return err
+	}
+	sig, err := security.DecodeEthereumSignature(botManifest.Signature)
+	if err != nil {
+		return err
+	}
+	pubKey, err := crypto.SigToPub(crypto.Keccak256(compacted.Bytes()), sig)
+	if err != nil {
+		return err
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); !strings.EqualFold(signer.Hex(), owner) {
+		return fmt.Errorf("signer %s is not the owner %s", signer.Hex(), owner)
+	}
+	return nil
+}
+
+// validateBotDependencies validates the dependencies and returns them with the full image references.
+func validateBotDependencies(cfg config.Config, deps []config.BotDependency) ([]config.BotDependency, error) {
+	if len(deps) > maxBotDependencies {
+		return nil, fmt.Errorf("%w: too many dependencies (max %d)", errInvalidBot, maxBotDependencies)
+	}
+	seen := make(map[string]bool)
+	var validated []config.BotDependency
+	for _, dep := range deps {
+		if !botDependencyNameRegexp.MatchString(dep.Name) || seen[dep.Name] {
+			return nil, fmt.Errorf("%w: invalid dependency name '%s'", errInvalidBot, dep.Name)
+		}
+		seen[dep.Name] = true
+		image, err := utils.ValidateDiscoImageRef(cfg.Registry.ContainerRegistry, dep.Image)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid dependency image reference '%s': %v", errInvalidBot, dep.Image, err)
+		}
+		dep.Image = image
+		validated = append(validated, dep)
+	}
+	return validated, nil
+}
+
+// validateBotRateLimit validates the JSON-RPC rate limit requested by the bot.
+func validateBotRateLimit(rateLimit *config.RateLimitConfig) error {
+	if rateLimit == nil {
+		return nil
+	}
+	if rateLimit.Rate <= 0 || rateLimit.Burst < 1 {
+		return fmt.Errorf("%w: invalid json-rpc rate limit", errInvalidBot)
+	}
+	return nil
+}
+
+// validateBotTxFilter validates the tx filter and returns it with the lowercase values.
+func validateBotTxFilter(filter *config.BotTxFilter) (*config.BotTxFilter, error) {
+	if filter == nil || (len(filter.Addresses) == 0 && len(filter.Topics) == 0) {
+		return nil, nil
+	}
+	if len(filter.Addresses)+len(filter.Topics) > maxBotTxFilterEntries {
+		return nil, fmt.Errorf("%w: too many tx filter entries (max %d)", errInvalidBot, maxBotTxFilterEntries)
+	}
+	validated := &config.BotTxFilter{}
+	for _, address := range filter.Addresses {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("%w: invalid tx filter address '%s'", errInvalidBot, address)
+		}
+		validated.Addresses = append(validated.Addresses, strings.ToLower(address))
+	}
+	for _, topic := range filter.Topics {
+		if b, err := hexutil.Decode(topic); err != nil || len(b) != common.HashLength {
+			return nil, fmt.Errorf("%w: invalid tx filter topic '%s'", errInvalidBot, topic)
+		}
+		validated.Topics = append(validated.Topics, strings.ToLower(topic))
+	}
+	return validated, nil
+}
+
+// validateBotEgressHosts validates the egress hosts and returns them in lowercase. The hosts are domain
+// names so that the bots can not ask for the internal addresses.
+func validateBotEgressHosts(hosts []string) ([]string, error) {
+	if len(hosts) > maxBotEgressHosts {
+		return nil, fmt.Errorf("%w: too many egress hosts (max %d)", errInvalidBot, maxBotEgressHosts)
+	}
+	var validated []string
+	for _, host := range hosts {
+		host = strings.ToLower(host)
+		if !botEgressHostRegexp.MatchString(host) {
+			return nil, fmt.Errorf("%w: invalid egress host '%s'", errInvalidBot, host)
+		}
+		validated = append(validated, host)
+	}
+	return validated, nil
+}
+
+func validateBotRequestLimits(limits *config.BotRequestLimits) error {
+	if limits == nil {
+		return nil
+	}
+	if limits.TimeoutSeconds < 0 || limits.MaxInFlight < 0 || limits.QueueDepth < 0 {
+		return fmt.Errorf("%w: invalid request limits", errInvalidBot)
+	}
+	return nil
+}
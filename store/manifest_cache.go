@@ -0,0 +1,62 @@
+package store
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/ipfs/go-cid"
+)
+
+// manifestCache keeps the fetched manifests on the disk. The files never need to be
+// invalidated since the content of a CID is immutable.
+type manifestCache struct {
+	dir string
+}
+
+func newManifestCache(dir string) (*manifestCache, error) {
+	if len(dir) == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the manifest cache dir: %v", err)
+	}
+	return &manifestCache{dir: dir}, nil
+}
+
+// filePath makes sure that the reference is a valid CID before using it as a file name.
+func (mc *manifestCache) filePath(reference string) (string, bool) {
+	c, err := cid.Parse(reference)
+	if err != nil {
+		return "", false
+	}
+	return path.Join(mc.dir, c.String()), true
+}
+
+// Get returns the cached manifest if it exists.
+func (mc *manifestCache) Get(reference string) ([]byte, bool) {
+	filePath, ok := mc.filePath(reference)
+	if !ok {
+		return nil, false
+	}
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// Put writes the manifest to the cache.
+func (mc *manifestCache) Put(reference string, b []byte) error {
+	filePath, ok := mc.filePath(reference)
+	if !ok {
+		return fmt.Errorf("invalid manifest reference: %s", reference)
+	}
+	// write to a temporary file first so that a crash does not leave a corrupt file behind
+	tmpPath := filePath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0600); err != nil {
+		return fmt.Errorf("failed to write the manifest to the cache: %v", err)
+	}
+	return os.Rename(tmpPath, filePath)
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifestCID = "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"
+
+func TestManifestCache(t *testing.T) {
+	r := require.New(t)
+
+	var requests int
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.Write([]byte(`{"manifest":{"agentId":"0xbot"}}`))
+	}))
+	defer gateway.Close()
+
+	cacheDir := t.TempDir()
+	mc, err := NewManifestClient(config.IPFSConfig{GatewayURL: gateway.URL}, cacheDir)
+	r.NoError(err)
+	botManifest, err := mc.GetBotManifest(context.Background(), testManifestCID)
+	r.NoError(err)
+	r.Equal("0xbot", *botManifest.Manifest.AgentID)
+	r.Equal(1, requests)
+
+	// a new client finds the manifest in the cache
+	mc, err = NewManifestClient(config.IPFSConfig{GatewayURL: gateway.URL}, cacheDir)
+	r.NoError(err)
+	botManifest, err = mc.GetBotManifest(context.Background(), testManifestCID)
+	r.NoError(err)
+	r.Equal("0xbot", *botManifest.Manifest.AgentID)
+	r.Equal(1, requests)
+
+	// invalid references are not cached
+	r.Error(mc.cache.Put("../foo", []byte("bar")))
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDependencyImageRef = "bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu@sha256:e0e9efb6699b02750f6a9668084d37314f1de3a80da7e19c1d40da73ee57dd45"
+
+func Test_validateBotDependencies(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Registry.ContainerRegistry = "disco.forta.network"
+
+	tests := []struct {
+		name    string
+		deps    []config.BotDependency
+		invalid bool
+	}{
+		{
+			name: "valid",
+			deps: []config.BotDependency{{Name: "redis", Image: testDependencyImageRef}},
+		},
+		{
+			name:    "bad name",
+			deps:    []config.BotDependency{{Name: "Redis_1", Image: testDependencyImageRef}},
+			invalid: true,
+		},
+		{
+			name: "duplicate name",
+			deps: []config.BotDependency{
+				{Name: "redis", Image: testDependencyImageRef},
+				{Name: "redis", Image: testDependencyImageRef},
+			},
+			invalid: true,
+		},
+		{
+			name:    "non-disco image",
+			deps:    []config.BotDependency{{Name: "redis", Image: "redis:7"}},
+			invalid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, err := validateBotDependencies(cfg, tt.deps)
+			if tt.invalid {
+				assert.True(t, errors.Is(err, errInvalidBot))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "disco.forta.network/"+testDependencyImageRef, deps[0].Image)
+		})
+	}
+}
+
+func Test_validateBotTxFilter(t *testing.T) {
+	r := require.New(t)
+
+	filter, err := validateBotTxFilter(&config.BotTxFilter{})
+	r.NoError(err)
+	r.Nil(filter)
+
+	filter, err = validateBotTxFilter(&config.BotTxFilter{
+		Addresses: []string{"0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"},
+		Topics:    []string{"0xDDF252AD1BE2C89B69C2B068FC378DAA952BA7F163C4A11628F55A4DF523B3EF"},
+	})
+	r.NoError(err)
+	r.Equal([]string{"0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"}, filter.Addresses)
+	r.Equal([]string{"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"}, filter.Topics)
+
+	_, err = validateBotTxFilter(&config.BotTxFilter{Addresses: []string{"0x123"}})
+	r.ErrorIs(err, errInvalidBot)
+	_, err = validateBotTxFilter(&config.BotTxFilter{Topics: []string{"0x123"}})
+	r.ErrorIs(err, errInvalidBot)
+}
+
+func Test_validateBotEgressHosts(t *testing.T) {
+	r := require.New(t)
+
+	hosts, err := validateBotEgressHosts([]string{"API.CoinGecko.com", "*.etherscan.io"})
+	r.NoError(err)
+	r.Equal([]string{"api.coingecko.com", "*.etherscan.io"}, hosts)
+
+	for _, host := range []string{"10.0.0.1", "localhost", "api.example.com:443", "*", "*.com.", "a.*.example.com", "forta-json-rpc"} {
+		_, err = validateBotEgressHosts([]string{host})
+		r.ErrorIs(err, errInvalidBot, host)
+	}
+}
+
+func Test_parseBotCapabilities(t *testing.T) {
+	r := require.New(t)
+
+	r.Nil(parseBotCapabilities(nil))
+	r.Nil(parseBotCapabilities([]byte(`null`)))
+	r.Nil(parseBotCapabilities([]byte(`["traces"]`)))
+
+	// the unknown and the invalid flags are ignored
+	capabilities := parseBotCapabilities([]byte(`{
+		"traces": true,
+		"mempool": "yes",
+		"healthChecks": true,
+		"sharding": false,
+		"maxBlockLag": 10,
+		"payloadRefs": true,
+		"gpu": true
+	}`))
+	r.Equal(&config.BotCapabilities{
+		Traces:       true,
+		HealthChecks: true,
+		MaxBlockLag:  10,
+		PayloadRefs:  true,
+	}, capabilities)
+}
+
+func Test_validateBotManifest(t *testing.T) {
+	r := require.New(t)
+
+	key, err := crypto.GenerateKey()
+	r.NoError(err)
+	owner := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	rawManifest := []byte(`{"agentId":"0xbot","imageReference":"` + testDependencyImageRef + `","chainIds":[1,137]}`)
+	sig, err := crypto.Sign(crypto.Keccak256(rawManifest), key)
+	r.NoError(err)
+	sigHex, err := security.EncodeEthereumSignature(sig)
+	r.NoError(err)
+
+	b := []byte(`{"manifest":` + string(rawManifest) + `,"signature":"` + sigHex + `"}`)
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write(b)
+	}))
+	defer gateway.Close()
+	mc, err := NewManifestClient(config.IPFSConfig{GatewayURL: gateway.URL}, "")
+	r.NoError(err)
+	botManifest, err := mc.GetBotManifest(context.Background(), "Qm1")
+	r.NoError(err)
+
+	cfg := config.Config{ChainID: 1}
+	cfg.Registry.VerifyManifestSignatures = true
+	r.NoError(validateBotManifest(cfg, botManifest, strings.ToLower(owner)))
+
+	// signed by someone else
+	r.ErrorIs(validateBotManifest(cfg, botManifest, "0x0000000000000000000000000000000000000001"), errInvalidBot)
+
+	// the chains are not validated here but the bots are filtered by them later
+	cfg.ChainID = 10
+	r.NoError(validateBotManifest(cfg, botManifest, owner))
+	r.Equal([]int{1, 137}, manifestChainIDs(botManifest))
+
+	// missing image
+	botManifest.Manifest.ImageReference = nil
+	r.ErrorIs(validateBotManifest(config.Config{}, botManifest, owner), errInvalidBot)
+}
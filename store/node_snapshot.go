@@ -0,0 +1,289 @@
+package store
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Node snapshot bundle contents
+const (
+	NodeSnapshotManifestFileName = "node-snapshot.json"
+	NodeSnapshotVersion          = 1
+
+	nodeSnapshotRestoreDirName = ".snapshot-restore"
+)
+
+// NodeSnapshotManifest describes the node state in a snapshot bundle. The files are listed with
+// their paths relative to the Forta dir and their SHA-256 hashes.
+type NodeSnapshotManifest struct {
+	Version        int               `json:"version"`
+	ScannerAddress string            `json:"scannerAddress,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	KeysIncluded   bool              `json:"keysIncluded"`
+	Files          map[string]string `json:"files"`
+}
+
+// NodeSnapshotOptions selects the node state to bundle.
+type NodeSnapshotOptions struct {
+	// Paths are the files and the dirs relative to the Forta dir. The missing ones are skipped.
+	Paths []string
+	// KeyDirs are the key dirs relative to the Forta dir. The keys are re-encrypted with the new
+	// passphrase if it is not empty.
+	KeyDirs       []string
+	Passphrase    string
+	NewPassphrase string
+}
+
+// CreateNodeSnapshot writes the node state from the Forta dir as a gzipped tar bundle. The manifest is
+// the last entry of the bundle.
+func CreateNodeSnapshot(fortaDir string, w io.Writer, opts NodeSnapshotOptions) (*NodeSnapshotManifest, error) {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	manifest := &NodeSnapshotManifest{
+		Version:      NodeSnapshotVersion,
+		CreatedAt:    time.Now().UTC(),
+		KeysIncluded: len(opts.KeyDirs) > 0,
+		Files:        make(map[string]string),
+	}
+
+	addFile := func(relPath string, b []byte) error {
+		if err := writeTarFile(tw, relPath, b); err != nil {
+			return fmt.Errorf("failed to add %s to the snapshot: %v", relPath, err)
+		}
+		sum := sha256.Sum256(b)
+		manifest.Files[relPath] = hex.EncodeToString(sum[:])
+		return nil
+	}
+
+	for _, statePath := range opts.Paths {
+		err := walkFiles(fortaDir, statePath, func(relPath string, b []byte) error {
+			return addFile(relPath, b)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, keyDir := range opts.KeyDirs {
+		err := walkFiles(fortaDir, keyDir, func(relPath string, b []byte) error {
+			address, b, err := reencryptKey(b, opts.Passphrase, opts.NewPassphrase)
+			if err != nil {
+				return fmt.Errorf("failed to re-encrypt %s: %v", relPath, err)
+			}
+			// the first key dir is the scanner key dir
+			if len(manifest.ScannerAddress) == 0 && keyDir == opts.KeyDirs[0] {
+				manifest.ScannerAddress = address.Hex()
+			}
+			return addFile(relPath, b)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the snapshot manifest: %v", err)
+	}
+	if err := writeTarFile(tw, NodeSnapshotManifestFileName, b); err != nil {
+		return nil, fmt.Errorf("failed to add the snapshot manifest: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close the snapshot: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close the snapshot: %v", err)
+	}
+	return manifest, nil
+}
+
+// RestoreNodeSnapshot verifies the bundle and moves the node state into the Forta dir. The existing
+// files are replaced only if overwrite is true. Nothing is changed in the Forta dir if the bundle is
+// invalid or some of the files exist.
+func RestoreNodeSnapshot(fortaDir string, r io.Reader, overwrite bool) (*NodeSnapshotManifest, error) {
+	restoreDir := path.Join(fortaDir, nodeSnapshotRestoreDirName)
+	if err := os.RemoveAll(restoreDir); err != nil {
+		return nil, fmt.Errorf("failed to clean up the restore dir: %v", err)
+	}
+	if err := os.MkdirAll(restoreDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the restore dir: %v", err)
+	}
+	defer os.RemoveAll(restoreDir)
+
+	manifest, hashes, err := extractNodeSnapshot(restoreDir, r)
+	if err != nil {
+		return nil, err
+	}
+	for relPath, hash := range manifest.Files {
+		if hashes[relPath] != hash {
+			return nil, fmt.Errorf("snapshot file %s is missing or does not match the manifest", relPath)
+		}
+	}
+	if len(hashes) != len(manifest.Files) {
+		return nil, errors.New("snapshot has files which are not in the manifest")
+	}
+
+	// move the top level files and dirs in place
+	entries, err := ioutil.ReadDir(restoreDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the restore dir: %v", err)
+	}
+	var conflicts []string
+	for _, entry := range entries {
+		if _, err := os.Stat(path.Join(fortaDir, entry.Name())); err == nil {
+			conflicts = append(conflicts, entry.Name())
+		}
+	}
+	if len(conflicts) > 0 && !overwrite {
+		return nil, fmt.Errorf("snapshot would replace the existing files: %s", strings.Join(conflicts, ", "))
+	}
+	for _, entry := range entries {
+		dstPath := path.Join(fortaDir, entry.Name())
+		if err := os.RemoveAll(dstPath); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %v", dstPath, err)
+		}
+		if err := os.Rename(path.Join(restoreDir, entry.Name()), dstPath); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %v", dstPath, err)
+		}
+	}
+	return manifest, nil
+}
+
+func extractNodeSnapshot(restoreDir string, r io.Reader) (*NodeSnapshotManifest, map[string]string, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the snapshot: %v", err)
+	}
+	tr := tar.NewReader(gr)
+	var manifest *NodeSnapshotManifest
+	hashes := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read the snapshot: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		relPath := path.Clean(header.Name)
+		if path.IsAbs(relPath) || relPath == ".." || strings.HasPrefix(relPath, "../") {
+			return nil, nil, fmt.Errorf("invalid file path in the snapshot: %s", header.Name)
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s from the snapshot: %v", relPath, err)
+		}
+		if relPath == NodeSnapshotManifestFileName {
+			if err := json.Unmarshal(b, &manifest); err != nil {
+				return nil, nil, fmt.Errorf("failed to decode the snapshot manifest: %v", err)
+			}
+			continue
+		}
+		filePath := path.Join(restoreDir, relPath)
+		if err := os.MkdirAll(path.Dir(filePath), 0700); err != nil {
+			return nil, nil, fmt.Errorf("failed to create the dir of %s: %v", relPath, err)
+		}
+		if err := ioutil.WriteFile(filePath, b, os.FileMode(header.Mode).Perm()); err != nil {
+			return nil, nil, fmt.Errorf("failed to write %s: %v", relPath, err)
+		}
+		sum := sha256.Sum256(b)
+		hashes[relPath] = hex.EncodeToString(sum[:])
+	}
+	if manifest == nil {
+		return nil, nil, errors.New("snapshot has no manifest")
+	}
+	if manifest.Version != NodeSnapshotVersion {
+		return nil, nil, fmt.Errorf("unsupported snapshot version %d", manifest.Version)
+	}
+	return manifest, hashes, nil
+}
+
+// walkFiles reads the regular files under the path in a stable order.
+func walkFiles(fortaDir, statePath string, handler func(relPath string, b []byte) error) error {
+	root := path.Join(fortaDir, statePath)
+	if _, err := os.Stat(root); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	var filePaths []string
+	err := filepath.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			filePaths = append(filePaths, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", root, err)
+	}
+	sort.Strings(filePaths)
+	for _, filePath := range filePaths {
+		relPath, err := filepath.Rel(fortaDir, filePath)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", filePath, err)
+		}
+		if err := handler(filepath.ToSlash(relPath), b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, b []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(b)),
+		ModTime: time.Now().UTC(),
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(b)
+	return err
+}
+
+// reencryptKey decrypts the key file and encrypts it with the new passphrase. The key file is kept
+// as is if the new passphrase is empty.
+func reencryptKey(b []byte, passphrase, newPassphrase string) (common.Address, []byte, error) {
+	if len(newPassphrase) == 0 {
+		var keyFile struct {
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal(b, &keyFile); err != nil {
+			return common.Address{}, nil, fmt.Errorf("invalid key file: %v", err)
+		}
+		return common.HexToAddress(keyFile.Address), b, nil
+	}
+	key, err := keystore.DecryptKey(b, passphrase)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to decrypt the key: %v", err)
+	}
+	b, err = keystore.EncryptKey(key, newPassphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to encrypt the key: %v", err)
+	}
+	return key.Address, b, nil
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeSnapshot(t *testing.T) {
+	r := require.New(t)
+
+	srcDir := t.TempDir()
+	r.NoError(ioutil.WriteFile(path.Join(srcDir, "config.yml"), []byte("chainId: 137"), 0600))
+	r.NoError(os.MkdirAll(path.Join(srcDir, ".batch-queue"), 0700))
+	r.NoError(ioutil.WriteFile(path.Join(srcDir, ".batch-queue", "1.json"), []byte("{}"), 0600))
+	ks := keystore.NewKeyStore(path.Join(srcDir, ".keys"), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount("Forta123")
+	r.NoError(err)
+
+	var buf bytes.Buffer
+	manifest, err := CreateNodeSnapshot(srcDir, &buf, NodeSnapshotOptions{
+		Paths:         []string{"config.yml", ".batch-queue", ".missing"},
+		KeyDirs:       []string{".keys"},
+		Passphrase:    "Forta123",
+		NewPassphrase: "Forta456",
+	})
+	r.NoError(err)
+	r.Equal(account.Address.Hex(), manifest.ScannerAddress)
+	r.Len(manifest.Files, 3)
+	bundle := buf.Bytes()
+
+	dstDir := t.TempDir()
+	restored, err := RestoreNodeSnapshot(dstDir, bytes.NewReader(bundle), false)
+	r.NoError(err)
+	r.Equal(manifest.Files, restored.Files)
+	b, err := ioutil.ReadFile(path.Join(dstDir, ".batch-queue", "1.json"))
+	r.NoError(err)
+	r.Equal("{}", string(b))
+
+	// the key is encrypted with the new passphrase
+	key, err := security.LoadKeyWithPassphrase(path.Join(dstDir, ".keys"), "Forta456")
+	r.NoError(err)
+	r.Equal(account.Address, key.Address)
+
+	// the existing files are not replaced unless it is forced
+	r.NoError(ioutil.WriteFile(path.Join(dstDir, "config.yml"), []byte("chainId: 1"), 0600))
+	_, err = RestoreNodeSnapshot(dstDir, bytes.NewReader(bundle), false)
+	r.Error(err)
+	_, err = RestoreNodeSnapshot(dstDir, bytes.NewReader(bundle), true)
+	r.NoError(err)
+	b, err = ioutil.ReadFile(path.Join(dstDir, "config.yml"))
+	r.NoError(err)
+	r.Equal("chainId: 137", string(b))
+}
+
+func TestNodeSnapshot_Invalid(t *testing.T) {
+	r := require.New(t)
+
+	srcDir := t.TempDir()
+	r.NoError(ioutil.WriteFile(path.Join(srcDir, "config.yml"), []byte("chainId: 137"), 0600))
+
+	var buf bytes.Buffer
+	_, err := CreateNodeSnapshot(srcDir, &buf, NodeSnapshotOptions{Paths: []string{"config.yml"}})
+	r.NoError(err)
+
+	// a truncated bundle has no manifest
+	dstDir := t.TempDir()
+	_, err = RestoreNodeSnapshot(dstDir, bytes.NewReader(buf.Bytes()[:buf.Len()/2]), false)
+	r.Error(err)
+	_, err = os.Stat(path.Join(dstDir, "config.yml"))
+	r.True(os.IsNotExist(err))
+}
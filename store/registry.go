@@ -12,6 +12,7 @@ import (
 	"github.com/ipfs/go-cid"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/ens"
 	"github.com/forta-network/forta-core-go/manifest"
 	"github.com/forta-network/forta-core-go/registry"
@@ -24,8 +25,9 @@ var (
 )
 
 const (
-	keyDefaultChainSetting = "default"
-	minShardCount          = 1
+	keyDefaultChainSetting  = "default"
+	minShardCount           = 1
+	defaultFullSyncInterval = 5 * time.Minute
 )
 
 type RegistryStore interface {
@@ -43,6 +45,7 @@ type registryStore struct {
 	lastCompletedVersion string
 	loadedBots           []config.AgentConfig
 	invalidAssignments   []*registry.Assignment
+	rejectedBots         map[string]error
 	mu                   sync.Mutex
 }
 
@@ -56,7 +59,8 @@ func (rs *registryStore) GetAgentsIfChanged(scanner string) ([]config.AgentConfi
 		return nil, false, err
 	}
 
-	shouldUpdate := rs.lastCompletedVersion != hash.Hash || time.Since(rs.lastUpdate) > 5*time.Minute
+	// the assignment hash works like an etag: reload only if it changed or the full sync is due
+	shouldUpdate := rs.lastCompletedVersion != hash.Hash || time.Since(rs.lastUpdate) > rs.fullSyncInterval()
 	if !shouldUpdate {
 		return nil, false, nil
 	}
@@ -105,6 +109,7 @@ func (rs *registryStore) GetAgentsIfChanged(scanner string) ([]config.AgentConfi
 
 		case errors.Is(err, errInvalidBot):
 			invalidAssignments = append(invalidAssignments, assignment) // remember for next time
+			rs.rejectBot(assignment.AgentID, err)
 			logger.WithError(err).Warn("invalid bot - skipping")
 		default:
 			failedLoadingAny = true
@@ -126,6 +131,9 @@ func (rs *registryStore) GetAgentsIfChanged(scanner string) ([]config.AgentConfi
 	rs.invalidAssignments = invalidAssignments
 	rs.lastUpdate = time.Now()
 
+	// pinning can take a while so it should not delay the bot updates
+	go pinBotManifests(rs.ctx, rs.mc, loadedBots)
+
 	if failedLoadingAny {
 		log.Warn("failed loading some of the bots - keeping the previous list version")
 	} else {
@@ -135,6 +143,29 @@ func (rs *registryStore) GetAgentsIfChanged(scanner string) ([]config.AgentConfi
 	return loadedBots, true, nil
 }
 
+func (rs *registryStore) fullSyncInterval() time.Duration {
+	if rs.cfg.Registry.FullSyncIntervalSeconds <= 0 {
+		return defaultFullSyncInterval
+	}
+	return time.Duration(rs.cfg.Registry.FullSyncIntervalSeconds) * time.Second
+}
+
+func (rs *registryStore) rejectBot(botID string, err error) {
+	if rs.rejectedBots == nil {
+		rs.rejectedBots = make(map[string]error)
+	}
+	rs.rejectedBots[botID] = err
+}
+
+// TakeRejectedBots returns the bots which were rejected since the last call.
+func (rs *registryStore) TakeRejectedBots() map[string]error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rejectedBots := rs.rejectedBots
+	rs.rejectedBots = nil
+	return rejectedBots
+}
+
 func (rs *registryStore) FindAgentGlobally(agentID string) (*config.AgentConfig, error) {
 	agt, err := rs.rc.GetAgent(agentID)
 	if err != nil {
@@ -180,9 +211,9 @@ func loadBot(ctx context.Context, cfg config.Config, mc manifest.Client, agentID
 		return nil, fmt.Errorf("%w: invalid bot cid '%s'", errInvalidBot, ref)
 	}
 
-	var agentData *manifest.SignedAgentManifest
+	var agentData *BotManifest
 	for i := 0; i < 10; i++ {
-		agentData, err = mc.GetAgentManifest(ctx, ref)
+		agentData, err = getBotManifest(ctx, mc, ref)
 		if err == nil {
 			break
 		}
@@ -192,8 +223,8 @@ func loadBot(ctx context.Context, cfg config.Config, mc manifest.Client, agentID
 		return nil, fmt.Errorf("failed to load the bot manifest: %v", err)
 	}
 
-	if agentData.Manifest.ImageReference == nil {
-		return nil, fmt.Errorf("%w: invalid bot image reference, it is nil", errInvalidBot)
+	if err := validateBotManifest(cfg, agentData, owner); err != nil {
+		return nil, err
 	}
 
 	image, err := utils.ValidateDiscoImageRef(cfg.Registry.ContainerRegistry, *agentData.Manifest.ImageReference)
@@ -201,12 +232,43 @@ func loadBot(ctx context.Context, cfg config.Config, mc manifest.Client, agentID
 		return nil, fmt.Errorf("%w: invalid bot image reference '%s': %v", errInvalidBot, *agentData.Manifest.ImageReference, err)
 	}
 
+	dependencies, err := validateBotDependencies(cfg, agentData.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateBotRateLimit(agentData.JsonRpcRateLimit); err != nil {
+		return nil, err
+	}
+	if err := validateBotRequestLimits(agentData.RequestLimits); err != nil {
+		return nil, err
+	}
+	txFilter, err := validateBotTxFilter(agentData.TxFilter)
+	if err != nil {
+		return nil, err
+	}
+	egressHosts, err := validateBotEgressHosts(agentData.EgressHosts)
+	if err != nil {
+		return nil, err
+	}
+
 	return &config.AgentConfig{
-		ID:       agentID,
-		Image:    image,
-		Manifest: ref,
-		ChainID:  cfg.ChainID,
-		Owner:    owner,
+		ID:               agentID,
+		Image:            image,
+		Manifest:         ref,
+		Name:             manifestName(agentData),
+		ChainID:          cfg.ChainID,
+		Owner:            owner,
+		Dependencies:     dependencies,
+		ChainIDs:         manifestChainIDs(agentData),
+		TraceContext:     agentData.TraceContext,
+		ReorgEvents:      agentData.ReorgEvents,
+		MempoolEvents:    agentData.MempoolEvents || (agentData.Capabilities != nil && agentData.Capabilities.Mempool),
+		TxFilter:         txFilter,
+		BestEffort:       agentData.BestEffort,
+		EgressHosts:      egressHosts,
+		JsonRpcRateLimit: agentData.JsonRpcRateLimit,
+		RequestLimits:    agentData.RequestLimits,
+		Capabilities:     agentData.Capabilities,
 	}, nil
 }
 
@@ -218,9 +280,9 @@ func loadAssignment(ctx context.Context, cfg config.Config, mc manifest.Client,
 		return nil, fmt.Errorf("%w: invalid bot cid '%s'", errInvalidBot, ref)
 	}
 
-	var agentData *manifest.SignedAgentManifest
+	var agentData *BotManifest
 	for i := 0; i < 10; i++ {
-		agentData, err = mc.GetAgentManifest(ctx, ref)
+		agentData, err = getBotManifest(ctx, mc, ref)
 		if err == nil {
 			break
 		}
@@ -229,8 +291,8 @@ func loadAssignment(ctx context.Context, cfg config.Config, mc manifest.Client,
 		return nil, fmt.Errorf("failed to load the bot manifest: %v", err)
 	}
 
-	if agentData.Manifest.ImageReference == nil {
-		return nil, fmt.Errorf("%w: invalid bot image reference, it is nil", errInvalidBot)
+	if err := validateBotManifest(cfg, agentData, assignment.AgentOwner); err != nil {
+		return nil, err
 	}
 
 	image, err := utils.ValidateDiscoImageRef(cfg.Registry.ContainerRegistry, *agentData.Manifest.ImageReference)
@@ -238,20 +300,78 @@ func loadAssignment(ctx context.Context, cfg config.Config, mc manifest.Client,
 		return nil, fmt.Errorf("%w: invalid bot image reference '%s': %v", errInvalidBot, *agentData.Manifest.ImageReference, err)
 	}
 
-	shardConfig := populateShardConfig(assignment, agentData, cfg.ChainID)
+	dependencies, err := validateBotDependencies(cfg, agentData.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateBotRateLimit(agentData.JsonRpcRateLimit); err != nil {
+		return nil, err
+	}
+	if err := validateBotRequestLimits(agentData.RequestLimits); err != nil {
+		return nil, err
+	}
+	txFilter, err := validateBotTxFilter(agentData.TxFilter)
+	if err != nil {
+		return nil, err
+	}
+	egressHosts, err := validateBotEgressHosts(agentData.EgressHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	shardConfig := populateShardConfig(assignment, agentData.SignedAgentManifest, cfg.ChainID)
 
 	return &config.AgentConfig{
-		ID:          assignment.AgentID,
-		Image:       image,
-		Manifest:    ref,
-		ChainID:     cfg.ChainID,
-		Owner:       assignment.AgentOwner,
-		ShardConfig: shardConfig,
+		ID:               assignment.AgentID,
+		Image:            image,
+		Manifest:         ref,
+		Name:             manifestName(agentData),
+		ChainID:          cfg.ChainID,
+		Owner:            assignment.AgentOwner,
+		ShardConfig:      shardConfig,
+		Dependencies:     dependencies,
+		ChainIDs:         manifestChainIDs(agentData),
+		TraceContext:     agentData.TraceContext,
+		ReorgEvents:      agentData.ReorgEvents,
+		MempoolEvents:    agentData.MempoolEvents || (agentData.Capabilities != nil && agentData.Capabilities.Mempool),
+		TxFilter:         txFilter,
+		BestEffort:       agentData.BestEffort,
+		EgressHosts:      egressHosts,
+		JsonRpcRateLimit: agentData.JsonRpcRateLimit,
+		RequestLimits:    agentData.RequestLimits,
+		Capabilities:     agentData.Capabilities,
 	}, nil
 }
 
+// Health implements the health.Reporter interface.
+func (rs *registryStore) Health() health.Reports {
+	return manifestClientHealth(rs.mc)
+}
+
+func manifestClientHealth(mc manifest.Client) health.Reports {
+	if reporter, ok := mc.(interface{ Health() health.Reports }); ok {
+		return reporter.Health()
+	}
+	return nil
+}
+
+// pinBotManifests pins the manifests of the bots if the manifest client supports it.
+func pinBotManifests(ctx context.Context, mc manifest.Client, bots []config.AgentConfig) {
+	pinner, ok := mc.(interface {
+		PinManifests(ctx context.Context, references []string)
+	})
+	if !ok {
+		return
+	}
+	var references []string
+	for _, bot := range bots {
+		references = append(references, bot.Manifest)
+	}
+	pinner.PinManifests(ctx, references)
+}
+
 func NewRegistryStore(ctx context.Context, cfg config.Config) (*registryStore, error) {
-	mc, err := manifest.NewClient(cfg.Registry.IPFS.GatewayURL)
+	mc, err := NewManifestClient(cfg.Registry.IPFS, cfg.Registry.ManifestCacheDir)
 	if err != nil {
 		return nil, err
 	}
@@ -436,8 +556,13 @@ func (rs *privateRegistryStore) makePrivateModeAgentConfig(
 	}
 }
 
+// Health implements the health.Reporter interface.
+func (rs *privateRegistryStore) Health() health.Reports {
+	return manifestClientHealth(rs.mc)
+}
+
 func NewPrivateRegistryStore(ctx context.Context, cfg config.Config) (*privateRegistryStore, error) {
-	mc, err := manifest.NewClient(cfg.Registry.IPFS.GatewayURL)
+	mc, err := NewManifestClient(cfg.Registry.IPFS, cfg.Registry.ManifestCacheDir)
 	if err != nil {
 		return nil, err
 	}
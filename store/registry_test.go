@@ -244,7 +244,6 @@ func TestGetAgentsIfChanged(t *testing.T) {
 					lastUpdate:           time.Now().Add(-2 * time.Hour),
 				}
 
-
 				// Set up the expectations for the mock objects
 				mockRegistryClient.EXPECT().GetAssignmentHash(scanner).Return(&registry.AssignmentHash{}, tt.registryClientErr).MaxTimes(1)
 				mockRegistryClient.EXPECT().GetAssignmentList(gomock.Any(), gomock.Any(), scanner).Return(tt.assignmentList, tt.registryClientErr).MaxTimes(1)
@@ -269,4 +268,4 @@ func TestGetAgentsIfChanged(t *testing.T) {
 			},
 		)
 	}
-}
\ No newline at end of file
+}
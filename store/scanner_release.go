@@ -128,13 +128,13 @@ func NewScannerReleaseStore(ctx context.Context, cfg config.Config) (ScannerRele
 	}
 
 	lookup := registryClient.GetScannerNodeVersion
-	if cfg.AutoUpdate.TrackPrereleases {
+	if cfg.AutoUpdate.TracksPrereleases() {
 		lookup = registryClient.GetScannerNodePrereleaseVersion
 	}
 	return &lookupVersionStore{
 		rc:           releaseClient,
 		lookup:       lookup,
-		isPrerelease: cfg.AutoUpdate.TrackPrereleases,
+		isPrerelease: cfg.AutoUpdate.TracksPrereleases(),
 		mux:          sync.Mutex{},
 	}, nil
 }
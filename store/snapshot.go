@@ -0,0 +1,131 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Registry snapshot bundle contents
+const (
+	SnapshotAssignmentsFileName = "assignments.json"
+	SnapshotManifestsDirName    = "manifests"
+	SnapshotImagesFileName      = "images.tar"
+)
+
+// Snapshot is the assignment list of a scanner at the time of the export.
+type Snapshot struct {
+	ScannerAddress string               `json:"scannerAddress"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	Bots           []config.AgentConfig `json:"bots"`
+}
+
+// WriteSnapshot writes the snapshot assignments to the bundle dir.
+func WriteSnapshot(dir string, snapshot *Snapshot) error {
+	b, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the snapshot: %v", err)
+	}
+	// write to a temporary file first so that a running node never reads a partial file
+	filePath := path.Join(dir, SnapshotAssignmentsFileName)
+	tmpPath := filePath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0600); err != nil {
+		return fmt.Errorf("failed to write the snapshot: %v", err)
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
+// ReadSnapshot reads the snapshot assignments from the bundle dir.
+func ReadSnapshot(dir string) (*Snapshot, error) {
+	b, err := ioutil.ReadFile(path.Join(dir, SnapshotAssignmentsFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the snapshot: %v", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode the snapshot: %v", err)
+	}
+	return &snapshot, nil
+}
+
+// SnapshotImageRef converts a digest reference to a tag reference because the repo digests
+// are lost when the images are loaded from an archive.
+//
+// e.g. disco.forta.network/bafybei...@sha256:abc... -> disco.forta.network/bafybei...:sha256-abc...
+func SnapshotImageRef(ref string) string {
+	parts := strings.SplitN(ref, "@", 2)
+	if len(parts) != 2 {
+		return ref
+	}
+	return fmt.Sprintf("%s:%s", parts[0], strings.Replace(parts[1], ":", "-", 1))
+}
+
+// snapshotRegistryStore serves the bots from the imported snapshot and picks up
+// a newly imported one on the next check.
+type snapshotRegistryStore struct {
+	dir string
+
+	lastModTime time.Time
+	lastLoaded  health.TimeTracker
+	createdAt   health.MessageTracker
+	mu          sync.Mutex
+}
+
+func (rs *snapshotRegistryStore) GetAgentsIfChanged(scanner string) ([]config.AgentConfig, bool, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	info, err := os.Stat(path.Join(rs.dir, SnapshotAssignmentsFileName))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check the snapshot: %v", err)
+	}
+	if info.ModTime().Equal(rs.lastModTime) {
+		return nil, false, nil
+	}
+
+	snapshot, err := ReadSnapshot(rs.dir)
+	if err != nil {
+		return nil, false, err
+	}
+	if !strings.EqualFold(snapshot.ScannerAddress, scanner) {
+		log.WithFields(log.Fields{
+			"snapshotScanner": snapshot.ScannerAddress,
+			"scanner":         scanner,
+		}).Warn("registry snapshot was exported for a different scanner")
+	}
+
+	rs.lastModTime = info.ModTime()
+	rs.lastLoaded.Set()
+	rs.createdAt.Set(snapshot.CreatedAt.UTC().Format(time.RFC3339))
+	return snapshot.Bots, true, nil
+}
+
+func (rs *snapshotRegistryStore) FindAgentGlobally(agentID string) (*config.AgentConfig, error) {
+	return nil, errors.New("feature not available (registry snapshot)")
+}
+
+// Health implements the health.Reporter interface.
+func (rs *snapshotRegistryStore) Health() health.Reports {
+	return health.Reports{
+		rs.lastLoaded.GetReport("registry.snapshot.last-loaded"),
+		rs.createdAt.GetReport("registry.snapshot.created-at"),
+	}
+}
+
+// NewSnapshotRegistryStore creates a registry store which reads from the imported snapshot.
+func NewSnapshotRegistryStore(cfg config.Config) (*snapshotRegistryStore, error) {
+	if _, err := ReadSnapshot(cfg.Registry.SnapshotDir); err != nil {
+		return nil, fmt.Errorf("snapshot mode is enabled but no valid snapshot was imported: %v", err)
+	}
+	return &snapshotRegistryStore{dir: cfg.Registry.SnapshotDir}, nil
+}
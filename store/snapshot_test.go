@@ -0,0 +1,66 @@
+package store
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+const testSnapshotScanner = "0x222244861C15A8F2A05fbD15E6Ea2Dd8B2D2c0aE"
+
+func TestSnapshotImageRef(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(
+		"disco.forta.network/bafybei:sha256-abcd",
+		SnapshotImageRef("disco.forta.network/bafybei@sha256:abcd"),
+	)
+	r.Equal("forta-network/bot:latest", SnapshotImageRef("forta-network/bot:latest"))
+}
+
+func TestSnapshotRegistryStore(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	cfg := config.Config{}
+	cfg.Registry.SnapshotDir = dir
+
+	_, err := NewSnapshotRegistryStore(cfg)
+	r.Error(err)
+
+	r.NoError(WriteSnapshot(dir, &Snapshot{
+		ScannerAddress: testSnapshotScanner,
+		CreatedAt:      time.Now(),
+		Bots:           []config.AgentConfig{{ID: "0x1", Image: "bot:sha256-1"}},
+	}))
+	rs, err := NewSnapshotRegistryStore(cfg)
+	r.NoError(err)
+
+	bots, changed, err := rs.GetAgentsIfChanged(testSnapshotScanner)
+	r.NoError(err)
+	r.True(changed)
+	r.Len(bots, 1)
+	r.Equal("0x1", bots[0].ID)
+
+	_, changed, err = rs.GetAgentsIfChanged(testSnapshotScanner)
+	r.NoError(err)
+	r.False(changed)
+
+	// a newly imported snapshot is picked up
+	r.NoError(WriteSnapshot(dir, &Snapshot{
+		ScannerAddress: testSnapshotScanner,
+		CreatedAt:      time.Now(),
+		Bots:           []config.AgentConfig{{ID: "0x1"}, {ID: "0x2"}},
+	}))
+	later := time.Now().Add(time.Second)
+	r.NoError(os.Chtimes(path.Join(dir, SnapshotAssignmentsFileName), later, later))
+
+	bots, changed, err = rs.GetAgentsIfChanged(testSnapshotScanner)
+	r.NoError(err)
+	r.True(changed)
+	r.Len(bots, 2)
+}
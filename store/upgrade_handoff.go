@@ -0,0 +1,46 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+// upgradeHandoffMaxAge limits how long a marker left behind by a failed upgrade is honored.
+const upgradeHandoffMaxAge = 5 * time.Minute
+
+func upgradeHandoffPath(fortaDir string) string {
+	return path.Join(fortaDir, config.DefaultUpgradeHandoffFileName)
+}
+
+// MarkUpgradeHandoff marks that the running supervisor is being replaced by a new one
+// so that it leaves the blue/green service containers running.
+func MarkUpgradeHandoff(fortaDir string) error {
+	return ioutil.WriteFile(upgradeHandoffPath(fortaDir), []byte(time.Now().UTC().Format(time.RFC3339)), 0644)
+}
+
+// ClearUpgradeHandoff removes the handoff marker after the new supervisor takes over.
+func ClearUpgradeHandoff(fortaDir string) error {
+	err := os.Remove(upgradeHandoffPath(fortaDir))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsUpgradeHandoffPending tells if the running supervisor is being replaced by a new one.
+func IsUpgradeHandoffPending(fortaDir string) bool {
+	b, err := ioutil.ReadFile(upgradeHandoffPath(fortaDir))
+	if err != nil {
+		return false
+	}
+	markedAt, err := time.Parse(time.RFC3339, strings.TrimSpace(string(b)))
+	if err != nil {
+		return false
+	}
+	return time.Since(markedAt) < upgradeHandoffMaxAge
+}
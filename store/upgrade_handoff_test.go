@@ -0,0 +1,30 @@
+package store
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeHandoff(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	r.False(IsUpgradeHandoffPending(dir))
+
+	r.NoError(MarkUpgradeHandoff(dir))
+	r.True(IsUpgradeHandoffPending(dir))
+
+	r.NoError(ClearUpgradeHandoff(dir))
+	r.False(IsUpgradeHandoffPending(dir))
+	r.NoError(ClearUpgradeHandoff(dir))
+
+	// a marker left behind by a failed upgrade expires
+	staleTime := time.Now().Add(-upgradeHandoffMaxAge).UTC().Format(time.RFC3339)
+	r.NoError(ioutil.WriteFile(path.Join(dir, config.DefaultUpgradeHandoffFileName), []byte(staleTime), 0644))
+	r.False(IsUpgradeHandoffPending(dir))
+}
@@ -91,6 +91,23 @@ var (
 	maxStakeAmount, _ = big.NewInt(0).SetString("1000000000000000000000", 10)
 )
 
+// faultInjectionCfg is added to the public mode node config when the tests are run with E2E_FAULTS=1
+// so that the tests check that the node recovers from the client failures.
+const faultInjectionCfg = `
+faultInjection:
+  enable: true
+  seed: 1
+  dockerTimeout:
+    probability: 0.05
+    delayMs: 1000
+  ipfsNotFound:
+    probability: 0.2
+  rpcRateLimit:
+    probability: 0.1
+  grpcReset:
+    probability: 0.05
+`
+
 type Suite struct {
 	ctx context.Context
 	r   *require.Assertions
@@ -231,6 +248,9 @@ func (s *Suite) SetupTest() {
 	b, err := os.ReadFile(".forta/config-template.yml")
 	s.r.NoError(err)
 	publicModeCfg := strings.Replace(string(b), "MULTICALL_ADDRESS", s.multicallAddr.Hex(), -1)
+	if os.Getenv("E2E_FAULTS") == "1" {
+		publicModeCfg += faultInjectionCfg
+	}
 	s.r.NoError(os.WriteFile(".forta/config.yml", []byte(publicModeCfg), 0777))
 
 	// deploy mock contract with release and bot info
@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const tracerName = "github.com/forta-network/forta-node"
+
+// Span names
+const (
+	SpanBlockReceived     = "block.received"
+	SpanTxReceived        = "tx.received"
+	SpanAlertReceived     = "alert.received"
+	SpanBotEvaluate       = "bot.evaluate"
+	SpanFindingValidation = "finding.validation"
+	SpanPublish           = "publish"
+)
+
+var propagator = propagation.TraceContext{}
+
+// Tracer returns the tracer of the node. The spans are not recorded unless the tracing is enabled.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Service sets up the exporting of the spans and flushes them on stop.
+type Service struct {
+	ctx         context.Context
+	cfg         config.TracingConfig
+	serviceName string
+	provider    *sdktrace.TracerProvider
+}
+
+// NewService creates a new tracing service. It should be started before the services which create spans.
+func NewService(ctx context.Context, cfg config.TracingConfig, serviceName string) *Service {
+	return &Service{
+		ctx:         ctx,
+		cfg:         cfg,
+		serviceName: serviceName,
+	}
+}
+
+// Start sets the global tracer provider which exports to the configured OTLP endpoint.
+func (svc *Service) Start() error {
+	if !svc.cfg.Enable {
+		return nil
+	}
+	if len(svc.cfg.OTLPEndpoint) == 0 {
+		return errors.New("tracing is enabled but no otlp endpoint was configured")
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(svc.cfg.OTLPEndpoint)}
+	if svc.cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	if len(svc.cfg.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(svc.cfg.Headers))
+	}
+	exporter, err := otlptracegrpc.New(svc.ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create the otlp exporter: %v", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String(svc.serviceName),
+		semconv.ServiceVersionKey.String(config.GetBuildReleaseInfo().Manifest.Release.Version),
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create the tracing resource: %v", err)
+	}
+	svc.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(svc.cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(svc.provider)
+	log.WithField("endpoint", svc.cfg.OTLPEndpoint).Info("exporting traces")
+	return nil
+}
+
+// Stop flushes the remaining spans.
+func (svc *Service) Stop() error {
+	if svc.provider == nil {
+		return nil
+	}
+	return svc.provider.Shutdown(context.Background())
+}
+
+// Name returns the name of the service.
+func (svc *Service) Name() string {
+	return "tracing"
+}
+
+// SetError marks the span as failed if there is an error.
+func SetError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// InjectGRPC adds the trace context of the current span to the outgoing gRPC metadata.
+func InjectGRPC(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	propagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// metadataCarrier adapts the gRPC metadata to the propagation.TextMapCarrier interface.
+type metadataCarrier metadata.MD
+
+func (mc metadataCarrier) Get(key string) string {
+	values := metadata.MD(mc).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (mc metadataCarrier) Set(key, value string) {
+	metadata.MD(mc).Set(key, value)
+}
+
+func (mc metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for key := range mc {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestInjectGRPC(t *testing.T) {
+	r := require.New(t)
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "foo", "bar")
+	ctx = InjectGRPC(trace.ContextWithSpanContext(ctx, spanContext))
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	r.True(ok)
+	r.Equal([]string{"bar"}, md.Get("foo"))
+	r.Equal(
+		[]string{"00-01020300000000000000000000000000-0405060000000000-01"},
+		md.Get("traceparent"),
+	)
+
+	// extracting on the bot side gives the same span context
+	extracted := trace.SpanContextFromContext(propagator.Extract(context.Background(), metadataCarrier(md)))
+	r.Equal(spanContext.TraceID(), extracted.TraceID())
+	r.Equal(spanContext.SpanID(), extracted.SpanID())
+}
+
+func TestInjectGRPC_NoSpan(t *testing.T) {
+	r := require.New(t)
+
+	md, _ := metadata.FromOutgoingContext(InjectGRPC(context.Background()))
+	r.Empty(md.Get("traceparent"))
+}
+
+func TestService_Disabled(t *testing.T) {
+	r := require.New(t)
+
+	svc := NewService(context.Background(), config.TracingConfig{}, "scanner")
+	r.NoError(svc.Start())
+	r.NoError(svc.Stop())
+
+	svc = NewService(context.Background(), config.TracingConfig{Enable: true}, "scanner")
+	r.Error(svc.Start())
+}